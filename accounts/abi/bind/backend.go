@@ -143,6 +143,12 @@ type DeployBackend interface {
 	CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error)
 }
 
+// AcceptanceBackend wraps the operations needed by WaitAccepted.
+type AcceptanceBackend interface {
+	DeployBackend
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
 // ContractBackend defines the methods needed to work with contracts on a read-write basis.
 type ContractBackend interface {
 	ContractCaller
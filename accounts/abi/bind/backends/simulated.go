@@ -401,6 +401,9 @@ func (b *SimulatedBackend) HeaderByNumber(ctx context.Context, block *big.Int) (
 	if block == nil || block.Cmp(b.acceptedBlock.Number()) == 0 {
 		return b.blockchain.CurrentHeader(), nil
 	}
+	if block.IsInt64() && rpc.BlockNumber(block.Int64()).IsAccepted() {
+		return b.blockchain.LastAcceptedBlock().Header(), nil
+	}
 
 	return b.blockchain.GetHeaderByNumber(uint64(block.Int64())), nil
 }
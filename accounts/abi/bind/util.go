@@ -29,12 +29,14 @@ package bind
 import (
 	"context"
 	"errors"
+	"math/big"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/shubhamdubey02/coreth/core/types"
 	"github.com/shubhamdubey02/coreth/interfaces"
+	"github.com/shubhamdubey02/coreth/rpc"
 )
 
 // WaitMined waits for tx to be mined on the blockchain.
@@ -65,6 +67,129 @@ func WaitMined(ctx context.Context, b DeployBackend, tx *types.Transaction) (*ty
 	}
 }
 
+// WaitAcceptedOpts is the collection of options to fine tune waiting for the
+// acceptance of a transaction.
+type WaitAcceptedOpts struct {
+	// Confirmations is the number of blocks that must be accepted on top of
+	// the block including the transaction.
+	Confirmations uint64
+	// OnAbandoned is called with the receipt of the transaction when the
+	// block including it is abandoned before it is accepted. Waiting then
+	// continues for the transaction to be included in another block.
+	OnAbandoned func(receipt *types.Receipt)
+	// PollInterval is the time between queries of the backend, which
+	// defaults to one second.
+	PollInterval time.Duration
+}
+
+// WaitAccepted waits for tx to be included in an accepted block, unlike
+// WaitMined which returns as soon as tx is included in a preferred block that
+// may still be abandoned. It stops waiting when the context is canceled.
+func WaitAccepted(ctx context.Context, b AcceptanceBackend, tx *types.Transaction, opts *WaitAcceptedOpts) (*types.Receipt, error) {
+	if opts == nil {
+		opts = new(WaitAcceptedOpts)
+	}
+	pollInterval := opts.PollInterval
+	if pollInterval == 0 {
+		pollInterval = time.Second
+	}
+	queryTicker := time.NewTicker(pollInterval)
+	defer queryTicker.Stop()
+
+	var (
+		logger    = log.New("hash", tx.Hash())
+		included  *types.Receipt // receipt of the block last known to include tx
+		abandoned common.Hash    // hash of the block last reported abandoned
+	)
+	abandon := func() {
+		logger.Debug("Block including transaction abandoned", "block", included.BlockHash, "number", included.BlockNumber)
+		if opts.OnAbandoned != nil {
+			opts.OnAbandoned(included)
+		}
+		abandoned = included.BlockHash
+		included = nil
+	}
+	for {
+		receipt, accepted, err := checkAccepted(ctx, b, tx.Hash(), opts.Confirmations)
+		switch {
+		case err != nil:
+			logger.Trace("Acceptance check failed", "err", err)
+		case receipt == nil:
+			logger.Trace("Transaction not yet mined")
+			if included != nil {
+				abandon()
+			}
+		default:
+			if included != nil && included.BlockHash != receipt.BlockHash {
+				abandon()
+			}
+			switch accepted {
+			case acceptancePending:
+				logger.Trace("Transaction not yet accepted", "block", receipt.BlockHash, "number", receipt.BlockNumber)
+				included = receipt
+			case acceptanceConfirmed:
+				return receipt, nil
+			case acceptanceAbandoned:
+				// The backend may keep serving the receipt of the abandoned
+				// block, which is only reported once.
+				if receipt.BlockHash != abandoned {
+					included = receipt
+					abandon()
+				}
+			}
+		}
+
+		// Wait for the next round.
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-queryTicker.C:
+		}
+	}
+}
+
+type acceptance int
+
+const (
+	acceptancePending acceptance = iota
+	acceptanceConfirmed
+	acceptanceAbandoned
+)
+
+// checkAccepted returns the receipt of [txHash], or nil if it is not mined,
+// and whether the block including it is accepted with [confirmations] blocks
+// accepted on top of it.
+func checkAccepted(ctx context.Context, b AcceptanceBackend, txHash common.Hash, confirmations uint64) (*types.Receipt, acceptance, error) {
+	receipt, err := b.TransactionReceipt(ctx, txHash)
+	if errors.Is(err, interfaces.NotFound) {
+		return nil, acceptancePending, nil
+	}
+	if err != nil {
+		return nil, acceptancePending, err
+	}
+	lastAccepted, err := b.HeaderByNumber(ctx, big.NewInt(int64(rpc.FinalizedBlockNumber)))
+	if err != nil {
+		return nil, acceptancePending, err
+	}
+	if lastAccepted.Number.Cmp(receipt.BlockNumber) < 0 {
+		return receipt, acceptancePending, nil
+	}
+	// The block at the height of the receipt is accepted, so the receipt is
+	// abandoned unless it is from that block.
+	header, err := b.HeaderByNumber(ctx, receipt.BlockNumber)
+	if err != nil {
+		return nil, acceptancePending, err
+	}
+	if header.Hash() != receipt.BlockHash {
+		return receipt, acceptanceAbandoned, nil
+	}
+	depth := new(big.Int).Sub(lastAccepted.Number, receipt.BlockNumber)
+	if depth.Cmp(new(big.Int).SetUint64(confirmations)) < 0 {
+		return receipt, acceptancePending, nil
+	}
+	return receipt, acceptanceConfirmed, nil
+}
+
 // WaitDeployed waits for a contract deployment transaction and returns the on-chain
 // contract address when it is mined. It stops waiting when ctx is canceled.
 func WaitDeployed(ctx context.Context, b DeployBackend, tx *types.Transaction) (common.Address, error) {
@@ -30,6 +30,7 @@ import (
 	"context"
 	"errors"
 	"math/big"
+	"sync"
 	"testing"
 	"time"
 
@@ -39,6 +40,7 @@ import (
 	"github.com/shubhamdubey02/coreth/accounts/abi/bind/backends"
 	"github.com/shubhamdubey02/coreth/core"
 	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/interfaces"
 )
 
 var testKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
@@ -153,3 +155,148 @@ func TestWaitDeployedCornerCases(t *testing.T) {
 	backend.SendTransaction(ctx, tx)
 	cancel()
 }
+
+func TestWaitAccepted(t *testing.T) {
+	t.Parallel()
+	backend := backends.NewSimulatedBackend(
+		core.GenesisAlloc{
+			crypto.PubkeyToAddress(testKey.PublicKey): {Balance: big.NewInt(1000000000000000000)},
+		},
+		10000000,
+	)
+	defer backend.Close()
+
+	head, _ := backend.HeaderByNumber(context.Background(), nil) // Should be child's, good enough
+	gasPrice := new(big.Int).Add(head.BaseFee, big.NewInt(1))
+
+	tx := types.NewTransaction(0, common.HexToAddress("0x01"), big.NewInt(1), 21000, gasPrice, nil)
+	tx, _ = types.SignTx(tx, types.HomesteadSigner{}, testKey)
+	if err := backend.SendTransaction(context.Background(), tx); err != nil {
+		t.Fatalf("Failed to send transaction: %s", err)
+	}
+	blockHash := backend.Commit(true)
+
+	var (
+		receipt  *types.Receipt
+		err      error
+		accepted = make(chan struct{})
+	)
+	go func() {
+		receipt, err = bind.WaitAccepted(context.Background(), backend, tx, &bind.WaitAcceptedOpts{
+			Confirmations: 1,
+			PollInterval:  10 * time.Millisecond,
+		})
+		close(accepted)
+	}()
+
+	// The transaction is accepted, but not yet confirmed.
+	select {
+	case <-accepted:
+		t.Fatal("returned before the confirmation was accepted")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	backend.Commit(true)
+	select {
+	case <-accepted:
+		if err != nil {
+			t.Fatalf("Failed to wait for acceptance: %s", err)
+		}
+		if receipt.BlockHash != blockHash {
+			t.Errorf("block hash mismatch: have %s, want %s", receipt.BlockHash, blockHash)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout")
+	}
+}
+
+// reorgBackend serves a receipt from a block that may differ from the
+// accepted block at its height.
+type reorgBackend struct {
+	lock         sync.Mutex
+	receipt      *types.Receipt
+	headers      map[uint64]*types.Header
+	lastAccepted *types.Header
+}
+
+func (b *reorgBackend) TransactionReceipt(context.Context, common.Hash) (*types.Receipt, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.receipt == nil {
+		return nil, interfaces.NotFound
+	}
+	return b.receipt, nil
+}
+
+func (b *reorgBackend) CodeAt(context.Context, common.Address, *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
+func (b *reorgBackend) HeaderByNumber(_ context.Context, number *big.Int) (*types.Header, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if number.Sign() < 0 {
+		return b.lastAccepted, nil
+	}
+	return b.headers[number.Uint64()], nil
+}
+
+func (b *reorgBackend) include(header *types.Header) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.receipt = &types.Receipt{BlockHash: header.Hash(), BlockNumber: header.Number}
+}
+
+func TestWaitAcceptedAbandoned(t *testing.T) {
+	t.Parallel()
+	var (
+		abandonedHeader = &types.Header{Number: big.NewInt(1), Time: 1}
+		acceptedHeader  = &types.Header{Number: big.NewInt(1), Time: 2}
+		backend         = &reorgBackend{
+			headers:      map[uint64]*types.Header{1: acceptedHeader},
+			lastAccepted: acceptedHeader,
+		}
+		tx        = types.NewTransaction(0, common.HexToAddress("0x01"), big.NewInt(0), 21000, big.NewInt(1), nil)
+		abandoned = make(chan *types.Receipt, 2)
+		accepted  = make(chan *types.Receipt)
+	)
+	backend.include(abandonedHeader)
+
+	go func() {
+		receipt, err := bind.WaitAccepted(context.Background(), backend, tx, &bind.WaitAcceptedOpts{
+			OnAbandoned:  func(receipt *types.Receipt) { abandoned <- receipt },
+			PollInterval: 10 * time.Millisecond,
+		})
+		if err != nil {
+			t.Errorf("Failed to wait for acceptance: %s", err)
+		}
+		accepted <- receipt
+	}()
+
+	select {
+	case receipt := <-abandoned:
+		if receipt.BlockHash != abandonedHeader.Hash() {
+			t.Errorf("abandoned block hash mismatch: have %s, want %s", receipt.BlockHash, abandonedHeader.Hash())
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for abandonment")
+	}
+	// The abandoned receipt is only reported once.
+	time.Sleep(50 * time.Millisecond)
+	backend.include(acceptedHeader)
+
+	select {
+	case receipt := <-accepted:
+		if receipt.BlockHash != acceptedHeader.Hash() {
+			t.Errorf("accepted block hash mismatch: have %s, want %s", receipt.BlockHash, acceptedHeader.Hash())
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for acceptance")
+	}
+	if len(abandoned) != 0 {
+		t.Errorf("abandoned block reported %d more times", len(abandoned))
+	}
+}
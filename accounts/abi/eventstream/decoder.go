@@ -0,0 +1,245 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package eventstream decodes the logs received from a subscription into the
+// events declared by a contract ABI, for services which do not use abigen
+// bindings.
+package eventstream
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shubhamdubey02/coreth/accounts/abi"
+	"github.com/shubhamdubey02/coreth/core/types"
+)
+
+var (
+	errUnknownEvent      = errors.New("unknown event")
+	errNoEventSignature  = errors.New("no event signature")
+	errSignatureMismatch = errors.New("event signature mismatch")
+)
+
+// Event is a log decoded into the arguments of the event it was emitted by.
+type Event struct {
+	Name string
+	// Args holds the indexed and non-indexed arguments of the event by name.
+	// Indexed arguments of dynamic types hold the hash of their value.
+	Args map[string]interface{}
+	Log  types.Log
+}
+
+// DecodeError reports a log which could not be decoded.
+type DecodeError struct {
+	Log types.Log
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("failed to decode log %d of tx %s: %s", e.Log.Index, e.Log.TxHash, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Decoder decodes logs into the events of an ABI, matching each log to its
+// event by its first topic. Anonymous events have no such topic and are not
+// decoded.
+type Decoder struct {
+	abi    abi.ABI
+	events map[common.Hash]abi.Event
+}
+
+// NewDecoder returns a decoder of the events of [contractABI].
+func NewDecoder(contractABI abi.ABI) *Decoder {
+	d := &Decoder{
+		abi:    contractABI,
+		events: make(map[common.Hash]abi.Event, len(contractABI.Events)),
+	}
+	for _, event := range contractABI.Events {
+		if !event.Anonymous {
+			d.events[event.ID] = event
+		}
+	}
+	return d
+}
+
+// Topics returns the topics matching the logs of the events [names], or of
+// all the events of the ABI if none are given, to filter a subscription.
+func (d *Decoder) Topics(names ...string) ([][]common.Hash, error) {
+	var ids []common.Hash
+	if len(names) == 0 {
+		for id := range d.events {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool {
+			return bytes.Compare(ids[i][:], ids[j][:]) < 0
+		})
+	}
+	for _, name := range names {
+		event, ok := d.abi.Events[name]
+		if !ok || event.Anonymous {
+			return nil, fmt.Errorf("%w: %s", errUnknownEvent, name)
+		}
+		ids = append(ids, event.ID)
+	}
+	return [][]common.Hash{ids}, nil
+}
+
+// Match returns the event which emitted [log], and whether it is an event of
+// the ABI.
+func (d *Decoder) Match(log types.Log) (abi.Event, bool) {
+	if len(log.Topics) == 0 {
+		return abi.Event{}, false
+	}
+	event, ok := d.events[log.Topics[0]]
+	return event, ok
+}
+
+// Decode decodes [log] into the arguments of the event which emitted it.
+func (d *Decoder) Decode(log types.Log) (*Event, error) {
+	event, ok := d.Match(log)
+	if !ok {
+		return nil, errUnknownEvent
+	}
+	args := make(map[string]interface{}, len(event.Inputs))
+	if len(log.Data) > 0 {
+		if err := d.abi.UnpackIntoMap(args, event.Name, log.Data); err != nil {
+			return nil, err
+		}
+	}
+	if err := abi.ParseTopicsIntoMap(args, indexed(event), log.Topics[1:]); err != nil {
+		return nil, err
+	}
+	return &Event{Name: event.Name, Args: args, Log: log}, nil
+}
+
+// DecodeInto decodes [log] emitted by the event [name] into [out], a pointer
+// to a struct whose fields are the arguments of the event as in abigen
+// bindings. If the struct has a Raw field of type [types.Log], it is set to
+// [log].
+func (d *Decoder) DecodeInto(out interface{}, name string, log types.Log) error {
+	event, ok := d.abi.Events[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", errUnknownEvent, name)
+	}
+	if len(log.Topics) == 0 {
+		return errNoEventSignature
+	}
+	if log.Topics[0] != event.ID {
+		return errSignatureMismatch
+	}
+	if len(log.Data) > 0 {
+		if err := d.abi.UnpackIntoInterface(out, name, log.Data); err != nil {
+			return err
+		}
+	}
+	if err := abi.ParseTopics(out, indexed(event), log.Topics[1:]); err != nil {
+		return err
+	}
+	if raw := reflect.ValueOf(out).Elem().FieldByName("Raw"); raw.IsValid() && raw.Type() == reflect.TypeOf(log) && raw.CanSet() {
+		raw.Set(reflect.ValueOf(log))
+	}
+	return nil
+}
+
+// indexed returns the indexed arguments of [event].
+func indexed(event abi.Event) abi.Arguments {
+	var args abi.Arguments
+	for _, arg := range event.Inputs {
+		if arg.Indexed {
+			args = append(args, arg)
+		}
+	}
+	return args
+}
+
+// Stream decodes the logs received from [logs] into events until [logs] is
+// closed or [ctx] is done, at which point both returned channels are closed.
+// Logs not emitted by an event of the ABI are skipped, and logs which fail to
+// decode are reported as a [*DecodeError]. Both channels must be drained.
+func (d *Decoder) Stream(ctx context.Context, logs <-chan types.Log) (<-chan *Event, <-chan error) {
+	return stream(ctx, logs, func(log types.Log) (*Event, bool, error) {
+		if _, ok := d.Match(log); !ok {
+			return nil, false, nil
+		}
+		event, err := d.Decode(log)
+		return event, true, err
+	})
+}
+
+// Watch decodes the logs of the event [name] received from [logs] into values
+// of type T, a struct whose fields are the arguments of the event as in
+// abigen bindings, until [logs] is closed or [ctx] is done. Logs of other
+// events are skipped, and logs which fail to decode are reported as a
+// [*DecodeError]. Both returned channels are closed when decoding stops, and
+// must be drained.
+func Watch[T any](ctx context.Context, d *Decoder, name string, logs <-chan types.Log) (<-chan *T, <-chan error, error) {
+	event, ok := d.abi.Events[name]
+	if !ok || event.Anonymous {
+		return nil, nil, fmt.Errorf("%w: %s", errUnknownEvent, name)
+	}
+	if reflect.TypeOf((*T)(nil)).Elem().Kind() != reflect.Struct {
+		return nil, nil, fmt.Errorf("events of %s must be decoded into a struct", name)
+	}
+	events, errs := stream(ctx, logs, func(log types.Log) (*T, bool, error) {
+		if len(log.Topics) == 0 || log.Topics[0] != event.ID {
+			return nil, false, nil
+		}
+		out := new(T)
+		if err := d.DecodeInto(out, name, log); err != nil {
+			return nil, true, err
+		}
+		return out, true, nil
+	})
+	return events, errs, nil
+}
+
+// stream decodes the logs received from [logs] with [decode], which returns
+// whether the log is to be decoded, until [logs] is closed or [ctx] is done.
+func stream[T any](ctx context.Context, logs <-chan types.Log, decode func(types.Log) (T, bool, error)) (<-chan T, <-chan error) {
+	var (
+		events = make(chan T)
+		errs   = make(chan error)
+	)
+	go func() {
+		defer close(events)
+		defer close(errs)
+
+		for {
+			var log types.Log
+			select {
+			case l, ok := <-logs:
+				if !ok {
+					return
+				}
+				log = l
+			case <-ctx.Done():
+				return
+			}
+
+			event, matched, err := decode(log)
+			switch {
+			case err != nil:
+				select {
+				case errs <- &DecodeError{Log: log, Err: err}:
+				case <-ctx.Done():
+					return
+				}
+			case matched:
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events, errs
+}
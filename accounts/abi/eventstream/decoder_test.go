@@ -0,0 +1,154 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package eventstream
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shubhamdubey02/coreth/accounts/abi"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+const testABI = `[
+	{"type":"event","name":"Transfer","inputs":[{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"value","type":"uint256","indexed":false}]},
+	{"type":"event","name":"Note","inputs":[{"name":"text","type":"string","indexed":false}]},
+	{"type":"event","name":"Hidden","anonymous":true,"inputs":[{"name":"value","type":"uint256","indexed":false}]}
+]`
+
+type transferEvent struct {
+	From  common.Address
+	To    common.Address
+	Value *big.Int
+	Raw   types.Log
+}
+
+var (
+	alice = common.HexToAddress("0xa11ce")
+	bob   = common.HexToAddress("0xb0b")
+)
+
+func newTestDecoder(t *testing.T) (abi.ABI, *Decoder) {
+	parsed, err := abi.JSON(strings.NewReader(testABI))
+	require.NoError(t, err)
+	return parsed, NewDecoder(parsed)
+}
+
+func packLog(t *testing.T, contractABI abi.ABI, index uint, name string, args ...interface{}) types.Log {
+	topics, data, err := contractABI.PackEvent(name, args...)
+	require.NoError(t, err)
+	return types.Log{Topics: topics, Data: data, Index: index}
+}
+
+// collect returns the events and errors received until both channels are
+// closed.
+func collect[T any](events <-chan T, errs <-chan error) ([]T, []error) {
+	var (
+		decoded []T
+		failed  []error
+	)
+	for events != nil || errs != nil {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			decoded = append(decoded, event)
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			failed = append(failed, err)
+		}
+	}
+	return decoded, failed
+}
+
+func TestDecode(t *testing.T) {
+	contractABI, decoder := newTestDecoder(t)
+
+	event, err := decoder.Decode(packLog(t, contractABI, 0, "Transfer", alice, bob, big.NewInt(5)))
+	require.NoError(t, err)
+	require.Equal(t, "Transfer", event.Name)
+	require.Equal(t, map[string]interface{}{"from": alice, "to": bob, "value": big.NewInt(5)}, event.Args)
+
+	_, err = decoder.Decode(packLog(t, contractABI, 0, "Hidden", big.NewInt(1)))
+	require.ErrorIs(t, err, errUnknownEvent)
+
+	var transfer transferEvent
+	log := packLog(t, contractABI, 3, "Transfer", alice, bob, big.NewInt(7))
+	require.NoError(t, decoder.DecodeInto(&transfer, "Transfer", log))
+	require.Equal(t, transferEvent{From: alice, To: bob, Value: big.NewInt(7), Raw: log}, transfer)
+	require.ErrorIs(t, decoder.DecodeInto(&transfer, "Note", log), errSignatureMismatch)
+
+	topics, err := decoder.Topics("Transfer")
+	require.NoError(t, err)
+	require.Equal(t, [][]common.Hash{{contractABI.Events["Transfer"].ID}}, topics)
+	topics, err = decoder.Topics()
+	require.NoError(t, err)
+	require.Len(t, topics[0], 2)
+	_, err = decoder.Topics("Hidden")
+	require.ErrorIs(t, err, errUnknownEvent)
+}
+
+func TestStream(t *testing.T) {
+	contractABI, decoder := newTestDecoder(t)
+
+	malformed := packLog(t, contractABI, 2, "Note", "truncated")
+	malformed.Data = malformed.Data[:32]
+	logs := make(chan types.Log, 4)
+	logs <- packLog(t, contractABI, 0, "Transfer", alice, bob, big.NewInt(1))
+	logs <- types.Log{Topics: []common.Hash{{0x01}}, Index: 1}
+	logs <- malformed
+	logs <- packLog(t, contractABI, 3, "Note", "hello")
+	close(logs)
+
+	events, errs := collect(decoder.Stream(context.Background(), logs))
+	require.Len(t, events, 2)
+	require.Equal(t, "Transfer", events[0].Name)
+	require.Equal(t, map[string]interface{}{"text": "hello"}, events[1].Args)
+	require.Len(t, errs, 1)
+	var decodeErr *DecodeError
+	require.ErrorAs(t, errs[0], &decodeErr)
+	require.Equal(t, uint(2), decodeErr.Log.Index)
+}
+
+func TestWatch(t *testing.T) {
+	contractABI, decoder := newTestDecoder(t)
+
+	logs := make(chan types.Log, 3)
+	logs <- packLog(t, contractABI, 0, "Transfer", alice, bob, big.NewInt(1))
+	logs <- packLog(t, contractABI, 1, "Note", "skipped")
+	logs <- packLog(t, contractABI, 2, "Transfer", bob, alice, big.NewInt(2))
+	close(logs)
+
+	transfers, errs, err := Watch[transferEvent](context.Background(), decoder, "Transfer", logs)
+	require.NoError(t, err)
+	events, failed := collect(transfers, errs)
+	require.Empty(t, failed)
+	require.Len(t, events, 2)
+	require.Equal(t, bob, events[1].From)
+	require.Equal(t, big.NewInt(2), events[1].Value)
+	require.Equal(t, uint(2), events[1].Raw.Index)
+
+	_, _, err = Watch[transferEvent](context.Background(), decoder, "Hidden", logs)
+	require.ErrorIs(t, err, errUnknownEvent)
+}
+
+func TestStreamCancel(t *testing.T) {
+	_, decoder := newTestDecoder(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, errs := decoder.Stream(ctx, make(chan types.Log))
+	cancel()
+	decoded, failed := collect(events, errs)
+	require.Empty(t, decoded)
+	require.Empty(t, failed)
+}
@@ -29,6 +29,7 @@ var (
 	errBlockGasCostTooLarge   = errors.New("block gas cost is not uint64")
 	errBaseFeeNil             = errors.New("base fee is nil")
 	errExtDataGasUsedNil      = errors.New("extDataGasUsed is nil")
+	errZeroGasUsed            = errors.New("gas used must be non-zero")
 	errExtDataGasUsedTooLarge = errors.New("extDataGasUsed is not uint64")
 )
 
@@ -45,6 +46,10 @@ type (
 	ConsensusCallbacks struct {
 		OnFinalizeAndAssemble OnFinalizeAndAssembleCallbackType
 		OnExtraStateChange    OnExtraStateChangeType
+		// OnReplayExtraStateChange applies the extra state change of an
+		// accepted block when re-executing it to regenerate historical state.
+		// Unlike OnExtraStateChange, it must not verify or record the block.
+		OnReplayExtraStateChange OnExtraStateChangeType
 	}
 
 	DummyEngine struct {
@@ -81,6 +86,19 @@ func NewFakerWithCallbacks(cb ConsensusCallbacks) *DummyEngine {
 	}
 }
 
+// ReplayEngine returns an engine re-executing accepted blocks with the
+// OnReplayExtraStateChange callback of [self], or [self] if it has none.
+func (self *DummyEngine) ReplayEngine() *DummyEngine {
+	if self.cb.OnReplayExtraStateChange == nil {
+		return self
+	}
+	return &DummyEngine{
+		cb:            ConsensusCallbacks{OnExtraStateChange: self.cb.OnReplayExtraStateChange},
+		clock:         self.clock,
+		consensusMode: self.consensusMode,
+	}
+}
+
 func NewFakerWithMode(cb ConsensusCallbacks, mode Mode) *DummyEngine {
 	return &DummyEngine{
 		cb:            cb,
@@ -171,18 +189,7 @@ func (self *DummyEngine) verifyHeaderGasFields(config *params.ChainConfig, heade
 	}
 
 	// Enforce BlockGasCost constraints
-	blockGasCostStep := ApricotPhase4BlockGasCostStep
-	if config.IsApricotPhase5(header.Time) {
-		blockGasCostStep = ApricotPhase5BlockGasCostStep
-	}
-	expectedBlockGasCost := calcBlockGasCost(
-		ApricotPhase4TargetBlockRate,
-		ApricotPhase4MinBlockGasCost,
-		ApricotPhase4MaxBlockGasCost,
-		blockGasCostStep,
-		parent.BlockGasCost,
-		parent.Time, header.Time,
-	)
+	expectedBlockGasCost := BlockGasCost(config, parent, header.Time)
 	if header.BlockGasCost == nil {
 		return errBlockGasCostNil
 	}
@@ -393,20 +400,9 @@ func (self *DummyEngine) Finalize(chain consensus.ChainHeaderReader, block *type
 		if blockExtDataGasUsed := block.ExtDataGasUsed(); blockExtDataGasUsed == nil || !blockExtDataGasUsed.IsUint64() || blockExtDataGasUsed.Cmp(extDataGasUsed) != 0 {
 			return fmt.Errorf("invalid extDataGasUsed: have %d, want %d", blockExtDataGasUsed, extDataGasUsed)
 		}
-		blockGasCostStep := ApricotPhase4BlockGasCostStep
-		if chain.Config().IsApricotPhase5(block.Time()) {
-			blockGasCostStep = ApricotPhase5BlockGasCostStep
-		}
 		// Calculate the expected blockGasCost for this block.
 		// Note: this is a deterministic transtion that defines an exact block fee for this block.
-		blockGasCost := calcBlockGasCost(
-			ApricotPhase4TargetBlockRate,
-			ApricotPhase4MinBlockGasCost,
-			ApricotPhase4MaxBlockGasCost,
-			blockGasCostStep,
-			parent.BlockGasCost,
-			parent.Time, block.Time(),
-		)
+		blockGasCost := BlockGasCost(chain.Config(), parent, block.Time())
 		// Verify the BlockGasCost set in the header matches the calculated value.
 		if blockBlockGasCost := block.BlockGasCost(); blockBlockGasCost == nil || !blockBlockGasCost.IsUint64() || blockBlockGasCost.Cmp(blockGasCost) != 0 {
 			return fmt.Errorf("invalid blockGasCost: have %d, want %d", blockBlockGasCost, blockGasCost)
@@ -445,19 +441,8 @@ func (self *DummyEngine) FinalizeAndAssemble(chain consensus.ChainHeaderReader,
 		if header.ExtDataGasUsed == nil {
 			header.ExtDataGasUsed = new(big.Int).Set(common.Big0)
 		}
-		blockGasCostStep := ApricotPhase4BlockGasCostStep
-		if chain.Config().IsApricotPhase5(header.Time) {
-			blockGasCostStep = ApricotPhase5BlockGasCostStep
-		}
 		// Calculate the required block gas cost for this block.
-		header.BlockGasCost = calcBlockGasCost(
-			ApricotPhase4TargetBlockRate,
-			ApricotPhase4MinBlockGasCost,
-			ApricotPhase4MaxBlockGasCost,
-			blockGasCostStep,
-			parent.BlockGasCost,
-			parent.Time, header.Time,
-		)
+		header.BlockGasCost = BlockGasCost(chain.Config(), parent, header.Time)
 		// Verify that this block covers the block fee.
 		if err := self.verifyBlockFee(
 			header.BaseFee,
@@ -200,6 +200,36 @@ func EstimateNextBaseFee(config *params.ChainConfig, parent *types.Header, times
 	return CalcBaseFee(config, parent, timestamp)
 }
 
+// ProjectBaseFee projects the base fee [blocks] blocks after a block with
+// [baseFee] at [timestamp], assuming that the gas consumed within the rollup
+// window of each of these blocks is twice the target, which raises the base
+// fee by 1/BaseFeeChangeDenominator per block.
+// Warning: This function should only be used in estimation and should not be used when calculating the canonical
+// base fee for a subsequent block.
+func ProjectBaseFee(config *params.ChainConfig, timestamp uint64, baseFee *big.Int, blocks uint64) *big.Int {
+	var (
+		projected                = new(big.Int).Set(baseFee)
+		baseFeeChangeDenominator = ApricotPhase4BaseFeeChangeDenominator
+		maxBaseFee               = ApricotPhase3MaxBaseFee
+	)
+	switch {
+	case config.IsApricotPhase5(timestamp):
+		baseFeeChangeDenominator = ApricotPhase5BaseFeeChangeDenominator
+		maxBaseFee = nil
+	case config.IsApricotPhase4(timestamp):
+		maxBaseFee = ApricotPhase4MaxBaseFee
+	}
+	delta := new(big.Int)
+	for i := uint64(0); i < blocks; i++ {
+		delta.Div(projected, baseFeeChangeDenominator)
+		projected.Add(projected, math.BigMax(delta, common.Big1))
+		if maxBaseFee != nil && projected.Cmp(maxBaseFee) >= 0 {
+			return new(big.Int).Set(maxBaseFee)
+		}
+	}
+	return projected
+}
+
 // selectBigWithinBounds returns [value] if it is within the bounds:
 // lowerBound <= value <= upperBound or the bound at either end if [value]
 // is outside of the defined boundaries.
@@ -284,6 +314,26 @@ func updateLongWindow(window []byte, start uint64, gasConsumed uint64) {
 	binary.BigEndian.PutUint64(window[start:], totalGasConsumed)
 }
 
+// BlockGasCost returns the block gas cost required of a block built on
+// [parent] at [timestamp], or nil prior to Apricot Phase 4.
+func BlockGasCost(config *params.ChainConfig, parent *types.Header, timestamp uint64) *big.Int {
+	if !config.IsApricotPhase4(timestamp) {
+		return nil
+	}
+	blockGasCostStep := ApricotPhase4BlockGasCostStep
+	if config.IsApricotPhase5(timestamp) {
+		blockGasCostStep = ApricotPhase5BlockGasCostStep
+	}
+	return calcBlockGasCost(
+		ApricotPhase4TargetBlockRate,
+		ApricotPhase4MinBlockGasCost,
+		ApricotPhase4MaxBlockGasCost,
+		blockGasCostStep,
+		parent.BlockGasCost,
+		parent.Time, timestamp,
+	)
+}
+
 // calcBlockGasCost calculates the required block gas cost. If [parentTime]
 // > [currentTime], the timeElapsed will be treated as 0.
 func calcBlockGasCost(
@@ -355,3 +405,34 @@ func MinRequiredTip(config *params.ChainConfig, header *types.Header) (*big.Int,
 	)
 	return new(big.Int).Div(requiredBlockFee, blockGasUsage), nil
 }
+
+// EstimateRequiredTip returns the minimum tip per unit of gas that a block
+// built on [parent] at [timestamp] must pay to cover its block fee, assuming
+// the block uses [gasUsed] gas and every unit of gas pays the same tip. Blocks
+// whose transactions pay a lower tip are rejected by the block builder.
+// If [timestamp] is less than the timestamp of [parent], then it uses the same
+// timestamp as parent.
+//
+// This function will return nil prior to Apricot Phase 4.
+func EstimateRequiredTip(config *params.ChainConfig, parent *types.Header, timestamp uint64, gasUsed uint64) (*big.Int, error) {
+	if timestamp < parent.Time {
+		timestamp = parent.Time
+	}
+	blockGasCost := BlockGasCost(config, parent, timestamp)
+	if blockGasCost == nil {
+		return nil, nil
+	}
+	if gasUsed == 0 {
+		return nil, errZeroGasUsed
+	}
+	_, baseFee, err := CalcBaseFee(config, parent, timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	// requiredTip = ceil(blockGasCost * baseFee / gasUsed)
+	requiredBlockFee := new(big.Int).Mul(blockGasCost, baseFee)
+	gas := new(big.Int).SetUint64(gasUsed)
+	requiredTip := new(big.Int).Add(requiredBlockFee, new(big.Int).Sub(gas, common.Big1))
+	return requiredTip.Div(requiredTip, gas), nil
+}
@@ -8,6 +8,7 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/shubhamdubey02/coreth/core/types"
@@ -537,3 +538,78 @@ func TestCalcBlockGasCost(t *testing.T) {
 		})
 	}
 }
+
+func TestEstimateRequiredTip(t *testing.T) {
+	parent := &types.Header{
+		Number:       big.NewInt(1),
+		Time:         10,
+		Extra:        make([]byte, params.DynamicFeeExtraDataSize),
+		BaseFee:      big.NewInt(params.ApricotPhase4MinBaseFee),
+		BlockGasCost: big.NewInt(100_000),
+	}
+
+	// Pre-AP4 blocks have no block gas cost to cover.
+	tip, err := EstimateRequiredTip(params.TestApricotPhase3Config, parent, 12, 21_000)
+	assert.NoError(t, err)
+	assert.Nil(t, tip)
+
+	_, err = EstimateRequiredTip(params.TestApricotPhase4Config, parent, 12, 0)
+	assert.ErrorIs(t, err, errZeroGasUsed)
+
+	for _, timestamp := range []uint64{5, 10, 12, 40} {
+		blockGasCost := BlockGasCost(params.TestApricotPhase4Config, parent, max(timestamp, parent.Time))
+		_, baseFee, err := CalcBaseFee(params.TestApricotPhase4Config, parent, max(timestamp, parent.Time))
+		assert.NoError(t, err)
+		requiredBlockFee := new(big.Int).Mul(blockGasCost, baseFee)
+
+		gasUsed := uint64(21_000)
+		tip, err := EstimateRequiredTip(params.TestApricotPhase4Config, parent, timestamp, gasUsed)
+		assert.NoError(t, err)
+		// The tip covers the block fee, and is the lowest tip that does.
+		paid := new(big.Int).Mul(tip, new(big.Int).SetUint64(gasUsed))
+		assert.True(t, paid.Cmp(requiredBlockFee) >= 0)
+		paid.Sub(paid, new(big.Int).SetUint64(gasUsed))
+		assert.True(t, paid.Cmp(requiredBlockFee) < 0)
+	}
+}
+
+func TestProjectBaseFee(t *testing.T) {
+	tests := map[string]struct {
+		config   *params.ChainConfig
+		baseFee  *big.Int
+		blocks   uint64
+		expected *big.Int
+	}{
+		"no blocks": {
+			config:   params.TestApricotPhase5Config,
+			baseFee:  big.NewInt(36_000),
+			expected: big.NewInt(36_000),
+		},
+		"ap5 increase": {
+			config:   params.TestApricotPhase5Config,
+			baseFee:  big.NewInt(36_000),
+			blocks:   2,
+			expected: big.NewInt(38_027),
+		},
+		"minimum increase": {
+			config:   params.TestApricotPhase5Config,
+			baseFee:  big.NewInt(1),
+			blocks:   3,
+			expected: big.NewInt(4),
+		},
+		"ap4 max base fee": {
+			config:   params.TestApricotPhase4Config,
+			baseFee:  new(big.Int).Sub(ApricotPhase4MaxBaseFee, common.Big1),
+			blocks:   1,
+			expected: ApricotPhase4MaxBaseFee,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			projected := ProjectBaseFee(test.config, 0, test.baseFee, test.blocks)
+			if projected.Cmp(test.expected) != 0 {
+				t.Fatalf("Expected (%d), found (%d)", test.expected, projected)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/shubhamdubey02/coreth/core/rawdb"
+	"github.com/shubhamdubey02/coreth/core/types"
+)
+
+var (
+	errAcceptedConsumerExists   = errors.New("accepted block consumer already registered")
+	errAcceptedConsumerClosed   = errors.New("accepted block consumer closed")
+	errAcceptedBlockUnavailable = errors.New("accepted block unavailable")
+	errAckUndelivered           = errors.New("cannot acknowledge undelivered block")
+)
+
+// acceptedBus tracks the consumers registered with [BlockChain.NewAcceptedConsumer].
+//
+// The bus does not keep per-consumer copies of accepted blocks: a consumer's
+// backlog is the range of accepted blocks between its offset and the acceptor
+// tip, which is read back from the chain on demand. Deleting the bodies of
+// accepted blocks is held behind [BlockChain.MinAcceptedConsumerOffset] so
+// that the backlog stays readable. The acceptor only signals consumers that the
+// tip moved, so a slow consumer never blocks the acceptor.
+type acceptedBus struct {
+	lock      sync.Mutex
+	consumers map[string]*AcceptedConsumer
+}
+
+func newAcceptedBus() *acceptedBus {
+	return &acceptedBus{consumers: make(map[string]*AcceptedConsumer)}
+}
+
+// notify wakes up every consumer waiting for a new accepted block.
+func (b *acceptedBus) notify() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	for _, c := range b.consumers {
+		select {
+		case c.notify <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// acceptorTipHeight returns the height of the last block processed by the
+// acceptor.
+func (bc *BlockChain) acceptorTipHeight() uint64 {
+	bc.acceptorTipLock.Lock()
+	defer bc.acceptorTipLock.Unlock()
+
+	return bc.acceptorTip.NumberU64()
+}
+
+// MinAcceptedConsumerOffset returns the lowest offset of the registered
+// consumers, or false if no consumer is registered. The blocks above it have
+// not been processed by every consumer.
+func (bc *BlockChain) MinAcceptedConsumerOffset() (uint64, bool) {
+	bc.acceptedBus.lock.Lock()
+	defer bc.acceptedBus.lock.Unlock()
+
+	var (
+		offset uint64
+		found  bool
+	)
+	for _, c := range bc.acceptedBus.consumers {
+		if o := c.Offset(); !found || o < offset {
+			offset, found = o, true
+		}
+	}
+	return offset, found
+}
+
+// AcceptedConsumer delivers every accepted block, in order, to a single named
+// in-process consumer. The height of the last block acknowledged by the
+// consumer is persisted, so a consumer recreated with the same name (e.g.
+// after a restart) resumes from the first block it did not acknowledge.
+// Blocks delivered but not acknowledged before a restart are delivered again.
+type AcceptedConsumer struct {
+	name string
+	bc   *BlockChain
+
+	lock   sync.Mutex
+	offset uint64 // height of the last acknowledged block
+	next   uint64 // height of the next block to deliver
+
+	notify chan struct{}
+	closed chan struct{}
+	once   sync.Once
+}
+
+// NewAcceptedConsumer registers the consumer [name]. If the consumer has no
+// persisted offset, delivery starts after the last accepted block.
+func (bc *BlockChain) NewAcceptedConsumer(name string) (*AcceptedConsumer, error) {
+	bc.acceptedBus.lock.Lock()
+	defer bc.acceptedBus.lock.Unlock()
+
+	if _, exists := bc.acceptedBus.consumers[name]; exists {
+		return nil, fmt.Errorf("%w: %s", errAcceptedConsumerExists, name)
+	}
+	offset, err := rawdb.ReadAcceptedConsumerOffset(bc.db, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read offset of accepted block consumer %s: %w", name, err)
+	}
+	if offset == nil {
+		height := bc.acceptorTipHeight()
+		if err := rawdb.WriteAcceptedConsumerOffset(bc.db, name, height); err != nil {
+			return nil, fmt.Errorf("failed to write offset of accepted block consumer %s: %w", name, err)
+		}
+		offset = &height
+	}
+	c := &AcceptedConsumer{
+		name:   name,
+		bc:     bc,
+		offset: *offset,
+		next:   *offset + 1,
+		notify: make(chan struct{}, 1),
+		closed: make(chan struct{}),
+	}
+	bc.acceptedBus.consumers[name] = c
+	log.Info("Registered accepted block consumer", "name", name, "offset", c.offset)
+	return c, nil
+}
+
+// Next returns the next accepted block, waiting for one to be accepted if the
+// consumer has caught up with the acceptor.
+func (c *AcceptedConsumer) Next(ctx context.Context) (*types.Block, error) {
+	for {
+		block, err := c.tryNext()
+		if block != nil || err != nil {
+			return block, err
+		}
+
+		select {
+		case <-c.notify:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-c.closed:
+			return nil, errAcceptedConsumerClosed
+		case <-c.bc.quit:
+			return nil, errAcceptedConsumerClosed
+		}
+	}
+}
+
+// tryNext returns the next accepted block, or nil if there is none yet.
+func (c *AcceptedConsumer) tryNext() (*types.Block, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	// Only deliver blocks processed by the acceptor, whose indices and logs
+	// have been written, rather than every block marked as accepted.
+	if c.next > c.bc.acceptorTipHeight() {
+		return nil, nil
+	}
+	block := c.bc.GetBlockByNumber(c.next)
+	if block == nil {
+		return nil, fmt.Errorf("%w: height %d (consumer: %s)", errAcceptedBlockUnavailable, c.next, c.name)
+	}
+	c.next++
+	return block, nil
+}
+
+// Ack records that every block up to and including [height] has been
+// processed, so they will not be delivered again after a restart.
+func (c *AcceptedConsumer) Ack(height uint64) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if height >= c.next {
+		return fmt.Errorf("%w: height %d, next %d", errAckUndelivered, height, c.next)
+	}
+	if height <= c.offset {
+		return nil
+	}
+	if err := rawdb.WriteAcceptedConsumerOffset(c.bc.db, c.name, height); err != nil {
+		return err
+	}
+	c.offset = height
+	return nil
+}
+
+// Offset returns the height of the last acknowledged block.
+func (c *AcceptedConsumer) Offset() uint64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.offset
+}
+
+// Close unregisters the consumer. Its offset is kept so that a consumer with
+// the same name resumes from it.
+func (c *AcceptedConsumer) Close() {
+	c.once.Do(func() {
+		close(c.closed)
+
+		bus := c.bc.acceptedBus
+		bus.lock.Lock()
+		delete(bus.consumers, c.name)
+		bus.lock.Unlock()
+	})
+}
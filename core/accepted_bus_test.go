@@ -0,0 +1,77 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shubhamdubey02/coreth/consensus/dummy"
+	"github.com/shubhamdubey02/coreth/core/rawdb"
+	"github.com/shubhamdubey02/coreth/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcceptedConsumerResume(t *testing.T) {
+	require := require.New(t)
+
+	gspec := &Genesis{Config: params.TestChainConfig}
+	_, blocks, _, err := GenerateChainWithGenesis(gspec, dummy.NewFakerWithCallbacks(TestCallbacks), 6, 10, func(int, *BlockGen) {})
+	require.NoError(err)
+
+	db := rawdb.NewMemoryDatabase()
+	chain, err := createBlockChain(db, DefaultCacheConfig, gspec, common.Hash{})
+	require.NoError(err)
+	defer chain.Stop()
+
+	_, err = chain.InsertChain(blocks)
+	require.NoError(err)
+	accept := func(from, to int) {
+		for _, block := range blocks[from:to] {
+			require.NoError(chain.Accept(block))
+		}
+		chain.DrainAcceptorQueue()
+	}
+	accept(0, 2)
+
+	// A new consumer starts after the last accepted block.
+	consumer, err := chain.NewAcceptedConsumer("test")
+	require.NoError(err)
+	require.Equal(uint64(2), consumer.Offset())
+	_, err = chain.NewAcceptedConsumer("test")
+	require.ErrorIs(err, errAcceptedConsumerExists)
+
+	// Blocks are delivered in order once accepted.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	_, err = consumer.Next(ctx)
+	cancel()
+	require.ErrorIs(err, context.DeadlineExceeded)
+
+	accept(2, 4)
+	for _, expected := range blocks[2:4] {
+		block, err := consumer.Next(context.Background())
+		require.NoError(err)
+		require.Equal(expected.Hash(), block.Hash())
+	}
+	require.ErrorIs(consumer.Ack(5), errAckUndelivered)
+	require.NoError(consumer.Ack(3))
+	consumer.Close()
+	_, err = consumer.Next(context.Background())
+	require.ErrorIs(err, errAcceptedConsumerClosed)
+
+	// Blocks accepted while the consumer was closed, and the delivered but
+	// unacknowledged block, are delivered after it is recreated.
+	accept(4, 6)
+	consumer, err = chain.NewAcceptedConsumer("test")
+	require.NoError(err)
+	defer consumer.Close()
+	require.Equal(uint64(3), consumer.Offset())
+	for _, expected := range blocks[3:6] {
+		block, err := consumer.Next(context.Background())
+		require.NoError(err)
+		require.Equal(expected.Hash(), block.Hash())
+	}
+}
@@ -153,6 +153,9 @@ type CacheConfig struct {
 	TrieDirtyLimit                  int     // Memory limit (MB) at which to block on insert and force a flush of dirty trie nodes to disk
 	TrieDirtyCommitTarget           int     // Memory limit (MB) to target for the dirties cache before invoking commit
 	TriePrefetcherParallelism       int     // Max concurrent disk reads trie prefetcher should perform at once
+	TriePrefetcherMaxPendingTasks   int     // Max keys queued per trie by the prefetcher (0 = unbounded)
+	TriePrefetcherAdaptive          bool    // Whether to scale prefetcher parallelism based on measured read latency
+	TriePrefetcherMaxParallelism    int     // Upper bound on prefetcher parallelism in adaptive mode
 	CommitInterval                  uint64  // Commit the trie every [CommitInterval] blocks.
 	Pruning                         bool    // Whether to disable trie write caching and GC altogether (archive node)
 	AcceptorQueueLimit              int     // Blocks to queue before blocking during acceptance
@@ -166,8 +169,12 @@ type CacheConfig struct {
 	AcceptedCacheSize               int     // Depth of accepted headers cache and accepted logs cache at the accepted tip
 	TxLookupLimit                   uint64  // Number of recent blocks for which to maintain transaction lookup indices
 	SkipTxIndexing                  bool    // Whether to skip transaction indexing
+	ReceiptCosts                    bool    // Whether to store and serve the fee breakdown of receipts
 	StateHistory                    uint64  // Number of blocks from head whose state histories are reserved.
 	StateScheme                     string  // Scheme used to store ethereum states and merkle tree nodes on top
+	ReceiptsVerification            bool    // Whether blocks are inserted with receipts verified against their headers instead of being executed, keeping no state
+
+	CacheTuner *CacheTunerConfig // If non-nil, resizes the trie clean and snapshot caches when accepted blocks lag behind
 
 	SnapshotNoBuild bool // Whether the background generation is allowed
 	SnapshotWait    bool // Wait for snapshot construction on startup. TODO(karalabe): This is a dirty hack for testing, nuke it
@@ -308,6 +315,10 @@ type BlockChain struct {
 	acceptorTip     *types.Block
 	acceptorTipLock sync.Mutex
 
+	// [acceptedBus] tracks consumers that are signalled once the acceptor tip
+	// has been updated.
+	acceptedBus *acceptedBus
+
 	// [flattenLock] prevents the [acceptor] from flattening snapshots while
 	// a block is being verified.
 	flattenLock sync.Mutex
@@ -317,6 +328,10 @@ type BlockChain struct {
 
 	// [txIndexTailLock] is used to synchronize the updating of the tx index tail.
 	txIndexTailLock sync.Mutex
+
+	// [prefetcherTuner] holds the live-tunable trie prefetcher settings used
+	// for block processing and block building.
+	prefetcherTuner *state.PrefetcherTuner
 }
 
 // NewBlockChain returns a fully initialised block chain using information
@@ -329,6 +344,11 @@ func NewBlockChain(
 	if cacheConfig == nil {
 		return nil, errCacheConfigNotSpecified
 	}
+	if cacheConfig.CacheTuner != nil {
+		if err := cacheConfig.CacheTuner.Verify(); err != nil {
+			return nil, err
+		}
+	}
 	// Open trie database with provided config
 	triedb := trie.NewDatabase(db, cacheConfig.triedbConfig())
 
@@ -365,9 +385,25 @@ func NewBlockChain(
 		senderCacher:      NewTxSenderCacher(runtime.NumCPU()),
 		acceptorQueue:     make(chan *types.Block, cacheConfig.AcceptorQueueLimit),
 		quit:              make(chan struct{}),
+		acceptedBus:       newAcceptedBus(),
 		acceptedLogsCache: NewFIFOCache[common.Hash, [][]*types.Log](cacheConfig.AcceptedCacheSize),
 	}
+	// Fee breakdowns are only computed when they are stored.
+	bc.vmConfig.ReceiptCosts = cacheConfig.ReceiptCosts
 	bc.stateCache = state.NewDatabaseWithNodeDB(bc.db, bc.triedb)
+	bc.prefetcherTuner = state.NewPrefetcherTuner(cacheConfig.TriePrefetcherParallelism)
+	if cacheConfig.TriePrefetcherAdaptive || cacheConfig.TriePrefetcherMaxPendingTasks != 0 {
+		settings := bc.prefetcherTuner.Settings()
+		settings.MaxPendingTasks = cacheConfig.TriePrefetcherMaxPendingTasks
+		if cacheConfig.TriePrefetcherAdaptive {
+			settings.Adaptive = true
+			settings.MinParallelism = 1
+			settings.MaxParallelism = cacheConfig.TriePrefetcherMaxParallelism
+		}
+		if err := bc.prefetcherTuner.SetSettings(settings); err != nil {
+			return nil, err
+		}
+	}
 	bc.validator = NewBlockValidator(chainConfig, bc, engine)
 	bc.processor = NewStateProcessor(chainConfig, bc, engine)
 
@@ -387,6 +423,7 @@ func NewBlockChain(
 
 	// Re-generate current block state if it is missing
 	if err := bc.loadLastState(lastAcceptedHash); err != nil {
+		bc.senderCacher.Shutdown()
 		return nil, err
 	}
 
@@ -399,7 +436,7 @@ func NewBlockChain(
 
 	// Make sure the state associated with the block is available
 	head := bc.CurrentBlock()
-	if !bc.HasState(head.Root) {
+	if !bc.cacheConfig.ReceiptsVerification && !bc.HasState(head.Root) {
 		return nil, fmt.Errorf("head state missing %d:%s", head.Number, head.Hash())
 	}
 
@@ -451,6 +488,21 @@ func NewBlockChain(
 			bc.maintainTxIndex(headCh)
 		}()
 	}
+
+	// Start resizing the caches based on the lag of accepted blocks if required.
+	if cacheConfig.CacheTuner != nil {
+		bc.wg.Add(1)
+		var (
+			headCh = make(chan ChainEvent, 1) // Buffered to avoid locking up the event feed
+			sub    = bc.SubscribeChainAcceptedEvent(headCh)
+		)
+		go func() {
+			defer bc.wg.Done()
+			defer sub.Unsubscribe()
+
+			bc.tuneCaches(headCh)
+		}()
+	}
 	return bc, nil
 }
 
@@ -537,6 +589,7 @@ func (bc *BlockChain) maintainTxIndex(headCh <-chan ChainEvent) {
 // writeBlockAcceptedIndices writes any indices that must be persisted for accepted block.
 // This includes the following:
 // - transaction lookup indices
+// - storage expiry access indices
 // - updating the acceptor tip index
 func (bc *BlockChain) writeBlockAcceptedIndices(b *types.Block) error {
 	batch := bc.db.NewBatch()
@@ -553,12 +606,34 @@ func (bc *BlockChain) batchBlockAcceptedIndices(batch ethdb.Batch, b *types.Bloc
 	if !bc.cacheConfig.SkipTxIndexing {
 		rawdb.WriteTxLookupEntriesByBlock(batch, b)
 	}
+	if bc.chainConfig.IsStorageExpiry(b.Time()) {
+		bc.indexStorageAccesses(batch, b)
+	}
 	if err := rawdb.WriteAcceptorTip(batch, b.Hash()); err != nil {
 		return fmt.Errorf("%w: failed to write acceptor tip key", err)
 	}
+	if err := bc.batchCommitJournal(batch, b); err != nil {
+		return fmt.Errorf("%w: failed to write commit journal", err)
+	}
 	return nil
 }
 
+// batchCommitJournal records [b] in the commit journal if its state is only
+// held in memory, or truncates the journal once the state of [b] is committed
+// to disk every [CommitInterval] blocks. After a crash, [reprocessState]
+// re-executes exactly the journaled blocks on top of the last committed state.
+//
+// Archival nodes commit the state of every block and do not journal them.
+func (bc *BlockChain) batchCommitJournal(batch ethdb.Batch, b *types.Block) error {
+	if !bc.cacheConfig.Pruning || bc.cacheConfig.ReceiptsVerification {
+		return nil
+	}
+	if b.NumberU64()%bc.cacheConfig.CommitInterval == 0 {
+		return rawdb.DeleteCommitJournal(bc.db, batch)
+	}
+	return rawdb.WriteCommitJournalEntry(batch, b.NumberU64(), b.Hash(), b.Root())
+}
+
 // flattenSnapshot attempts to flatten a block of [hash] to disk.
 func (bc *BlockChain) flattenSnapshot(postAbortWork func() error, hash common.Hash) error {
 	// If snapshots are not initialized, perform [postAbortWork] immediately.
@@ -633,10 +708,12 @@ func (bc *BlockChain) startAcceptor() {
 		start := time.Now()
 		acceptorQueueGauge.Dec(1)
 
-		if err := bc.flattenSnapshot(func() error {
-			return bc.stateManager.AcceptTrie(next)
-		}, next.Hash()); err != nil {
-			log.Crit("unable to flatten snapshot from acceptor", "blockHash", next.Hash(), "err", err)
+		if !bc.cacheConfig.ReceiptsVerification {
+			if err := bc.flattenSnapshot(func() error {
+				return bc.stateManager.AcceptTrie(next)
+			}, next.Hash()); err != nil {
+				log.Crit("unable to flatten snapshot from acceptor", "blockHash", next.Hash(), "err", err)
+			}
 		}
 
 		// Update last processed and transaction lookup index
@@ -654,6 +731,7 @@ func (bc *BlockChain) startAcceptor() {
 		bc.acceptorTipLock.Lock()
 		bc.acceptorTip = next
 		bc.acceptorTipLock.Unlock()
+		bc.acceptedBus.notify()
 
 		// Update accepted feeds
 		flattenedLogs := types.FlattenLogs(logs)
@@ -787,6 +865,11 @@ func (bc *BlockChain) loadLastState(lastAcceptedHash common.Hash) error {
 		return fmt.Errorf("failed to set preference to last accepted block while loading last state: %w", err)
 	}
 
+	// Chains verifying receipts instead of executing blocks keep no state.
+	if bc.cacheConfig.ReceiptsVerification {
+		return nil
+	}
+
 	// reprocessState is necessary to ensure that the last accepted state is
 	// available. The state may not be available if it was not committed due
 	// to an unclean shutdown.
@@ -1020,6 +1103,9 @@ func (bc *BlockChain) Stop() {
 
 	// Ensure that the entirety of the state snapshot is journaled to disk.
 	if bc.snaps != nil {
+		if err := bc.snaps.Journal(); err != nil {
+			log.Error("Failed to journal state snapshot", "err", err)
+		}
 		bc.snaps.Release()
 	}
 	if bc.triedb.Scheme() == rawdb.PathScheme {
@@ -1148,8 +1234,10 @@ func (bc *BlockChain) Reject(block *types.Block) error {
 	defer bc.chainmu.Unlock()
 
 	// Reject Trie
-	if err := bc.stateManager.RejectTrie(block); err != nil {
-		return fmt.Errorf("unable to reject trie: %w", err)
+	if !bc.cacheConfig.ReceiptsVerification {
+		if err := bc.stateManager.RejectTrie(block); err != nil {
+			return fmt.Errorf("unable to reject trie: %w", err)
+		}
 	}
 
 	if bc.snaps != nil {
@@ -1168,6 +1256,13 @@ func (bc *BlockChain) Reject(block *types.Block) error {
 	return nil
 }
 
+// WriteBlobSidecars stores the blob sidecars of the blob transactions of the
+// block with the given hash and number, in transaction order. They are deleted
+// with the block if it is rejected.
+func (bc *BlockChain) WriteBlobSidecars(hash common.Hash, number uint64, sidecars []*types.BlobTxSidecar) {
+	rawdb.WriteBlobSidecars(bc.db, hash, number, sidecars)
+}
+
 // writeKnownBlock updates the head block flag with a known block
 // and introduces chain reorg if necessary.
 func (bc *BlockChain) writeKnownBlock(block *types.Block) error {
@@ -1229,7 +1324,13 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 	blockBatch := bc.db.NewBatch()
 	rawdb.WriteBlock(blockBatch, block)
 	rawdb.WriteReceipts(blockBatch, block.Hash(), block.NumberU64(), receipts)
+	if bc.cacheConfig.ReceiptCosts {
+		rawdb.WriteReceiptCosts(blockBatch, block.Hash(), block.NumberU64(), receipts)
+	}
 	rawdb.WritePreimages(blockBatch, state.Preimages())
+	if accesses := state.StorageAccesses(); accesses != nil {
+		rawdb.WriteBlockStorageAccesses(blockBatch, block.Hash(), block.NumberU64(), storageAccessesList(accesses))
+	}
 	if err := blockBatch.Write(); err != nil {
 		log.Crit("Failed to write block into disk", "err", err)
 	}
@@ -1393,7 +1494,7 @@ func (bc *BlockChain) insertBlock(block *types.Block, writes bool) error {
 	blockStateInitTimer.Inc(time.Since(substart).Milliseconds())
 
 	// Enable prefetching to pull in trie node paths while processing transactions
-	statedb.StartPrefetcher("chain", bc.cacheConfig.TriePrefetcherParallelism)
+	statedb.StartTunedPrefetcher("chain", bc.prefetcherTuner)
 	activeState = statedb
 
 	// Process block using the parent state as reference point
@@ -1862,28 +1963,39 @@ func (bc *BlockChain) reprocessState(current *types.Block, reexec uint64) error
 		}
 	}
 
-	for i := 0; i < int(reexec); i++ {
-		// TODO: handle canceled context
+	// Start re-processing from the last committed state recorded by the commit
+	// journal if it is available, and otherwise search for it among the
+	// [reexec] ancestors of [current].
+	base, journal, err := bc.commitJournalBase(current)
+	if err != nil {
+		return err
+	}
+	if base != nil {
+		current = base
+	} else {
+		for i := 0; i < int(reexec); i++ {
+			// TODO: handle canceled context
 
-		if current.NumberU64() == 0 {
-			return errors.New("genesis state is missing")
-		}
-		parent := bc.GetBlock(current.ParentHash(), current.NumberU64()-1)
-		if parent == nil {
-			return fmt.Errorf("missing block %s:%d", current.ParentHash().Hex(), current.NumberU64()-1)
-		}
-		current = parent
-		_, err = bc.stateCache.OpenTrie(current.Root())
-		if err == nil {
-			break
+			if current.NumberU64() == 0 {
+				return errors.New("genesis state is missing")
+			}
+			parent := bc.GetBlock(current.ParentHash(), current.NumberU64()-1)
+			if parent == nil {
+				return fmt.Errorf("missing block %s:%d", current.ParentHash().Hex(), current.NumberU64()-1)
+			}
+			current = parent
+			_, err = bc.stateCache.OpenTrie(current.Root())
+			if err == nil {
+				break
+			}
 		}
-	}
-	if err != nil {
-		switch err.(type) {
-		case *trie.MissingNodeError:
-			return fmt.Errorf("required historical state unavailable (reexec=%d)", reexec)
-		default:
-			return err
+		if err != nil {
+			switch err.(type) {
+			case *trie.MissingNodeError:
+				return fmt.Errorf("required historical state unavailable (reexec=%d)", reexec)
+			default:
+				return err
+			}
 		}
 	}
 
@@ -1912,6 +2024,10 @@ func (bc *BlockChain) reprocessState(current *types.Block, reexec uint64) error
 		if current = bc.GetBlockByNumber(next); current == nil {
 			return fmt.Errorf("failed to retrieve block %d while re-generating state", next)
 		}
+		entry, journaled := journal[next]
+		if journaled && entry.Hash != current.Hash() {
+			return fmt.Errorf("block %s:%d does not match commit journal entry %s", current.Hash().Hex(), next, entry.Hash.Hex())
+		}
 
 		// Initialize snapshot if required (prevents full snapshot re-generation in
 		// the case of unclean shutdown)
@@ -1932,6 +2048,9 @@ func (bc *BlockChain) reprocessState(current *types.Block, reexec uint64) error
 		if err != nil {
 			return err
 		}
+		if journaled && root != entry.Root {
+			return fmt.Errorf("re-processed root %s of block %s:%d does not match commit journal root %s", root.Hex(), current.Hash().Hex(), next, entry.Root.Hex())
+		}
 
 		// Flatten snapshot if initialized, holding a reference to the state root until the next block
 		// is processed.
@@ -1956,10 +2075,61 @@ func (bc *BlockChain) reprocessState(current *types.Block, reexec uint64) error
 
 	_, nodes, imgs := triedb.Size()
 	log.Info("Historical state regenerated", "block", current.NumberU64(), "elapsed", time.Since(start), "nodes", nodes, "preimages", imgs)
-	if previousRoot != (common.Hash{}) {
-		return triedb.Commit(previousRoot, true)
+	if previousRoot == (common.Hash{}) {
+		return nil
 	}
-	return nil
+	if err := triedb.Commit(previousRoot, true); err != nil {
+		return err
+	}
+	// The state of the last accepted block is committed, so the journaled
+	// blocks will not be re-processed again.
+	batch := bc.db.NewBatch()
+	if err := rawdb.DeleteCommitJournal(bc.db, batch); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+// commitJournalBase returns the most recent ancestor of the acceptor tip [tip],
+// or [tip] itself, whose state is on disk, along with the journaled blocks
+// that must be re-processed on top of it by height. The commit journal is used
+// if it ends at [tip], in which case the state of the block preceding the
+// journaled blocks must be on disk. Otherwise, nil is returned if the state of
+// [tip] is not on disk.
+func (bc *BlockChain) commitJournalBase(tip *types.Block) (*types.Block, map[uint64]rawdb.CommitJournalEntry, error) {
+	entries, err := rawdb.ReadCommitJournal(bc.db)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: unable to read commit journal", err)
+	}
+	if len(entries) == 0 || entries[len(entries)-1].Hash != tip.Hash() {
+		if bc.HasState(tip.Root()) {
+			return tip, nil, nil
+		}
+		return nil, nil, nil
+	}
+
+	// Walk back the contiguous journaled blocks until one whose state is on
+	// disk, which is the case of blocks committed by a clean shutdown.
+	var (
+		journal = make(map[uint64]rawdb.CommitJournalEntry)
+		next    = tip.NumberU64() + 1
+	)
+	for i := len(entries) - 1; i >= 0 && entries[i].Number+1 == next; i-- {
+		if bc.HasState(entries[i].Root) {
+			break
+		}
+		journal[entries[i].Number] = entries[i]
+		next = entries[i].Number
+	}
+	base := bc.GetBlockByNumber(next - 1)
+	if base == nil {
+		return nil, nil, fmt.Errorf("missing block %d preceding the commit journal", next-1)
+	}
+	if !bc.HasState(base.Root()) {
+		return nil, nil, fmt.Errorf("state of block %s:%d preceding the commit journal is missing", base.Hash().Hex(), base.NumberU64())
+	}
+	log.Info("Recovering state from commit journal", "committed", base.NumberU64(), "journaled", len(journal), "tip", tip.NumberU64())
+	return base, journal, nil
 }
 
 func (bc *BlockChain) protectTrieIndex() error {
@@ -2197,6 +2367,12 @@ func (bc *BlockChain) CacheConfig() *CacheConfig {
 	return bc.cacheConfig
 }
 
+// PrefetcherTuner returns the trie prefetcher settings shared by block
+// processing and block building.
+func (bc *BlockChain) PrefetcherTuner() *state.PrefetcherTuner {
+	return bc.prefetcherTuner
+}
+
 func (bc *BlockChain) setTxIndexTail(newTail uint64) error {
 	bc.txIndexTailLock.Lock()
 	defer bc.txIndexTailLock.Unlock()
@@ -188,10 +188,34 @@ func (bc *BlockChain) GetReceiptsByHash(hash common.Hash) types.Receipts {
 	if receipts == nil {
 		return nil
 	}
+	if bc.cacheConfig.ReceiptCosts {
+		if costs := rawdb.ReadReceiptCosts(bc.db, hash, *number); len(costs) == len(receipts) {
+			for i, receipt := range receipts {
+				receipt.Costs = costs[i]
+			}
+		}
+	}
 	bc.receiptsCache.Add(hash, receipts)
 	return receipts
 }
 
+// GetBlobSidecars retrieves the blob sidecars of the blob transactions of the
+// block with the given hash, in transaction order. Returns nil if they are not
+// stored.
+func (bc *BlockChain) GetBlobSidecars(hash common.Hash) []*types.BlobTxSidecar {
+	number := rawdb.ReadHeaderNumber(bc.db, hash)
+	if number == nil {
+		return nil
+	}
+	return rawdb.ReadBlobSidecars(bc.db, hash, *number)
+}
+
+// HasBlobSidecars returns true if the blob sidecars of the block with the
+// given hash and number are stored.
+func (bc *BlockChain) HasBlobSidecars(hash common.Hash, number uint64) bool {
+	return rawdb.HasBlobSidecars(bc.db, hash, number)
+}
+
 // GetCanonicalHash returns the canonical hash for a given block number
 func (bc *BlockChain) GetCanonicalHash(number uint64) common.Hash {
 	return bc.hc.GetCanonicalHash(number)
@@ -1330,3 +1330,111 @@ func createAndInsertChain(db ethdb.Database, cacheConfig *CacheConfig, gspec *Ge
 
 	return chain, nil
 }
+
+func TestReceiptCosts(t *testing.T) {
+	require := require.New(t)
+	var (
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		addr2   = common.Address{0x02}
+		gspec   = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  GenesisAlloc{addr1: {Balance: big.NewInt(params.Ether)}},
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	_, blocks, _, err := GenerateChainWithGenesis(gspec, dummy.NewFakerWithCallbacks(TestCallbacks), 1, 10, func(i int, b *BlockGen) {
+		tx, err := types.SignNewTx(key1, signer, &types.DynamicFeeTx{
+			ChainID:   gspec.Config.ChainID,
+			Nonce:     b.TxNonce(addr1),
+			To:        &addr2,
+			Gas:       params.TxGas,
+			GasFeeCap: newGwei(225),
+			GasTipCap: big.NewInt(2),
+		})
+		require.NoError(err)
+		b.AddTx(tx)
+	})
+	require.NoError(err)
+
+	for _, enabled := range []bool{false, true} {
+		conf := *DefaultCacheConfig
+		conf.ReceiptCosts = enabled
+		db := rawdb.NewMemoryDatabase()
+		chain, err := createAndInsertChain(db, &conf, gspec, blocks, common.Hash{}, nil)
+		require.NoError(err)
+
+		block := blocks[0]
+		receipts := chain.GetReceiptsByHash(block.Hash())
+		require.Len(receipts, 1)
+		if !enabled {
+			require.Nil(receipts[0].Costs)
+			require.Nil(rawdb.ReadReceiptCosts(db, block.Hash(), block.NumberU64()))
+			chain.Stop()
+			continue
+		}
+
+		gasUsed := new(big.Int).SetUint64(receipts[0].GasUsed)
+		tip := block.Transactions()[0].EffectiveGasTipValue(block.BaseFee())
+		require.Equal(new(big.Int).Mul(gasUsed, block.BaseFee()), receipts[0].Costs.BaseFeeBurned)
+		require.Equal(new(big.Int).Mul(gasUsed, tip), receipts[0].Costs.TipPaid)
+		require.Zero(receipts[0].Costs.GasRefunded)
+		chain.Stop()
+	}
+}
+
+func TestCommitJournalRecovery(t *testing.T) {
+	require := require.New(t)
+	var (
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		addr2   = common.Address{0x02}
+		gspec   = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  GenesisAlloc{addr1: {Balance: big.NewInt(params.Ether)}},
+		}
+		signer = types.LatestSigner(gspec.Config)
+		db     = rawdb.NewMemoryDatabase()
+	)
+	_, blocks, _, err := GenerateChainWithGenesis(gspec, dummy.NewFakerWithCallbacks(TestCallbacks), 10, 10, func(i int, b *BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(b.TxNonce(addr1), addr2, big.NewInt(10000), params.TxGas, newGwei(225), nil), signer, key1)
+		require.NoError(err)
+		b.AddTx(tx)
+	})
+	require.NoError(err)
+
+	conf := *pruningConfig
+	conf.CommitInterval = 4
+	conf.SnapshotLimit = 0
+	chain, err := createAndInsertChain(db, &conf, gspec, blocks, common.Hash{}, nil)
+	require.NoError(err)
+	// Crash without committing the state of the blocks accepted since the
+	// commit at height 8.
+	chain.stopAcceptor()
+	chain.senderCacher.Shutdown()
+
+	journal, err := rawdb.ReadCommitJournal(db)
+	require.NoError(err)
+	require.Equal([]rawdb.CommitJournalEntry{
+		{Number: 9, Hash: blocks[8].Hash(), Root: blocks[8].Root()},
+		{Number: 10, Hash: blocks[9].Hash(), Root: blocks[9].Root()},
+	}, journal)
+
+	// Recovery fails if re-executing the journaled blocks diverges.
+	tip := blocks[len(blocks)-1]
+	require.NoError(rawdb.WriteCommitJournalEntry(db, 10, tip.Hash(), common.Hash{0x01}))
+	_, err = createBlockChain(db, &conf, gspec, tip.Hash())
+	require.ErrorContains(err, "does not match commit journal")
+
+	require.NoError(rawdb.WriteCommitJournalEntry(db, 10, tip.Hash(), tip.Root()))
+	chain, err = createBlockChain(db, &conf, gspec, tip.Hash())
+	require.NoError(err)
+	defer chain.Stop()
+
+	state, err := chain.StateAt(tip.Root())
+	require.NoError(err)
+	require.Equal(big.NewInt(100000), state.GetBalance(addr2))
+	journal, err = rawdb.ReadCommitJournal(db)
+	require.NoError(err)
+	require.Empty(journal)
+}
@@ -0,0 +1,55 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/bitutil"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/shubhamdubey02/coreth/core/rawdb"
+	"github.com/shubhamdubey02/coreth/core/types"
+)
+
+// RepairBloomBits regenerates the bloom bits of [section], of [size] blocks,
+// from the headers of the canonical chain and compares them to the stored
+// bloom bits, which serve log filters. If [repair] is set, the section is
+// rewritten when they differ. It returns whether the stored bloom bits were
+// consistent.
+func RepairBloomBits(db ethdb.Database, size, section uint64, repair bool) (bool, error) {
+	indexer := &BloomIndexer{db: db, size: size}
+	if err := indexer.Reset(context.Background(), section, common.Hash{}); err != nil {
+		return false, err
+	}
+	for number := section * size; number < (section+1)*size; number++ {
+		hash := rawdb.ReadCanonicalHash(db, number)
+		header := rawdb.ReadHeader(db, hash, number)
+		if header == nil {
+			return false, fmt.Errorf("missing canonical header at height %d", number)
+		}
+		if err := indexer.Process(context.Background(), header); err != nil {
+			return false, err
+		}
+	}
+
+	consistent := true
+	for i := 0; i < types.BloomBitLength; i++ {
+		bits, err := indexer.gen.Bitset(uint(i))
+		if err != nil {
+			return false, err
+		}
+		stored, err := rawdb.ReadBloomBits(db, uint(i), section, indexer.head)
+		if err != nil || !bytes.Equal(stored, bitutil.CompressBytes(bits)) {
+			consistent = false
+			break
+		}
+	}
+	if consistent || !repair {
+		return consistent, nil
+	}
+	return false, indexer.Commit()
+}
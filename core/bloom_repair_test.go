@@ -0,0 +1,56 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shubhamdubey02/coreth/core/rawdb"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepairBloomBits(t *testing.T) {
+	require := require.New(t)
+
+	const size = 8
+	db := rawdb.NewMemoryDatabase()
+	for i := int64(0); i < size; i++ {
+		header := &types.Header{
+			Number: big.NewInt(i),
+			Bloom:  types.BytesToBloom(common.LeftPadBytes([]byte{byte(i + 1)}, types.BloomByteLength)),
+		}
+		rawdb.WriteHeader(db, header)
+		rawdb.WriteCanonicalHash(db, header.Hash(), header.Number.Uint64())
+	}
+
+	// Sections that were never indexed are inconsistent, and are only
+	// written if repaired.
+	consistent, err := RepairBloomBits(db, size, 0, false)
+	require.NoError(err)
+	require.False(consistent)
+	consistent, err = RepairBloomBits(db, size, 0, false)
+	require.NoError(err)
+	require.False(consistent)
+
+	consistent, err = RepairBloomBits(db, size, 0, true)
+	require.NoError(err)
+	require.False(consistent)
+	consistent, err = RepairBloomBits(db, size, 0, false)
+	require.NoError(err)
+	require.True(consistent)
+
+	// Corrupted bits are detected.
+	head := rawdb.ReadCanonicalHash(db, size-1)
+	rawdb.WriteBloomBits(db, types.BloomBitLength-1, 0, head, []byte{0xff})
+	consistent, err = RepairBloomBits(db, size, 0, false)
+	require.NoError(err)
+	require.False(consistent)
+
+	// Sections with missing headers cannot be checked.
+	_, err = RepairBloomBits(db, size, 1, false)
+	require.ErrorContains(err, "missing canonical header")
+}
@@ -0,0 +1,194 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/shubhamdubey02/coreth/metrics"
+)
+
+var (
+	cacheTunerLagGauge      = metrics.NewRegisteredGauge("chain/cachetuner/lag", nil)
+	cacheTunerTrieGauge     = metrics.NewRegisteredGauge("chain/cachetuner/trie", nil)
+	cacheTunerSnapshotGauge = metrics.NewRegisteredGauge("chain/cachetuner/snapshot", nil)
+
+	errInvalidCacheTunerConfig = errors.New("invalid cache tuner config")
+)
+
+// CacheTunerConfig configures the resizing of the trie clean cache and of the
+// snapshot cache based on how far accepted blocks lag behind the wall clock.
+//
+// The lag of a block is the time between its timestamp and its acceptance. A
+// window during which every accepted block lagged by more than LagThreshold
+// counts as lagging, and a window during which every accepted block lagged by
+// less than half of LagThreshold counts as caught up. After Windows
+// consecutive lagging windows the caches are doubled, and after Windows
+// consecutive caught up windows they are halved, within the configured bounds
+// (in MB). A resized cache keeps serving the entries of the previous one
+// while it fills up.
+type CacheTunerConfig struct {
+	LagThreshold      time.Duration
+	Window            time.Duration
+	Windows           int
+	MinTrieCleanLimit int
+	MaxTrieCleanLimit int
+	MinSnapshotLimit  int
+	MaxSnapshotLimit  int
+}
+
+// Verify returns an error if [c] is not a valid config.
+func (c *CacheTunerConfig) Verify() error {
+	if c.LagThreshold <= 0 {
+		return fmt.Errorf("%w: lag threshold (%s) must be positive", errInvalidCacheTunerConfig, c.LagThreshold)
+	}
+	if c.Window <= 0 {
+		return fmt.Errorf("%w: window (%s) must be positive", errInvalidCacheTunerConfig, c.Window)
+	}
+	if c.Windows < 1 {
+		return fmt.Errorf("%w: windows (%d) must be positive", errInvalidCacheTunerConfig, c.Windows)
+	}
+	if c.MinTrieCleanLimit < 1 || c.MinTrieCleanLimit > c.MaxTrieCleanLimit {
+		return fmt.Errorf("%w: trie clean limit range [%d, %d] is invalid", errInvalidCacheTunerConfig, c.MinTrieCleanLimit, c.MaxTrieCleanLimit)
+	}
+	if c.MinSnapshotLimit < 1 || c.MinSnapshotLimit > c.MaxSnapshotLimit {
+		return fmt.Errorf("%w: snapshot limit range [%d, %d] is invalid", errInvalidCacheTunerConfig, c.MinSnapshotLimit, c.MaxSnapshotLimit)
+	}
+	return nil
+}
+
+// cacheTuner tracks the lag of accepted blocks and decides when the caches
+// are resized.
+type cacheTuner struct {
+	config *CacheTunerConfig
+
+	// Current cache sizes (MB), 0 if the cache is not resized
+	trieLimit     int
+	snapshotLimit int
+
+	// Lag measured during the current window
+	samples        int
+	minLag, maxLag time.Duration
+
+	lagging  int // Consecutive lagging windows
+	caughtUp int // Consecutive caught up windows
+}
+
+func newCacheTuner(config *CacheTunerConfig, trieLimit, snapshotLimit int) *cacheTuner {
+	cacheTunerTrieGauge.Update(int64(trieLimit))
+	cacheTunerSnapshotGauge.Update(int64(snapshotLimit))
+	return &cacheTuner{
+		config:        config,
+		trieLimit:     trieLimit,
+		snapshotLimit: snapshotLimit,
+	}
+}
+
+// observe records the lag of an accepted block.
+func (t *cacheTuner) observe(lag time.Duration) {
+	cacheTunerLagGauge.Update(int64(lag))
+	if t.samples == 0 || lag < t.minLag {
+		t.minLag = lag
+	}
+	if t.samples == 0 || lag > t.maxLag {
+		t.maxLag = lag
+	}
+	t.samples++
+}
+
+// endWindow classifies the window which just ended and returns the new sizes
+// of the caches, and whether they changed. Windows without accepted blocks
+// are ignored.
+func (t *cacheTuner) endWindow() (int, int, bool) {
+	if t.samples == 0 {
+		return t.trieLimit, t.snapshotLimit, false
+	}
+	switch {
+	case t.minLag > t.config.LagThreshold:
+		t.lagging, t.caughtUp = t.lagging+1, 0
+	case t.maxLag < t.config.LagThreshold/2:
+		t.lagging, t.caughtUp = 0, t.caughtUp+1
+	default:
+		t.lagging, t.caughtUp = 0, 0
+	}
+	t.samples = 0
+
+	trieLimit, snapshotLimit := t.trieLimit, t.snapshotLimit
+	switch {
+	case t.lagging >= t.config.Windows:
+		trieLimit = clampLimit(trieLimit*2, t.config.MinTrieCleanLimit, t.config.MaxTrieCleanLimit)
+		snapshotLimit = clampLimit(snapshotLimit*2, t.config.MinSnapshotLimit, t.config.MaxSnapshotLimit)
+	case t.caughtUp >= t.config.Windows:
+		trieLimit = clampLimit(trieLimit/2, t.config.MinTrieCleanLimit, t.config.MaxTrieCleanLimit)
+		snapshotLimit = clampLimit(snapshotLimit/2, t.config.MinSnapshotLimit, t.config.MaxSnapshotLimit)
+	default:
+		return trieLimit, snapshotLimit, false
+	}
+	if t.trieLimit == 0 {
+		trieLimit = 0
+	}
+	if t.snapshotLimit == 0 {
+		snapshotLimit = 0
+	}
+	t.lagging, t.caughtUp = 0, 0
+	if trieLimit == t.trieLimit && snapshotLimit == t.snapshotLimit {
+		return trieLimit, snapshotLimit, false
+	}
+	t.trieLimit, t.snapshotLimit = trieLimit, snapshotLimit
+	cacheTunerTrieGauge.Update(int64(trieLimit))
+	cacheTunerSnapshotGauge.Update(int64(snapshotLimit))
+	return trieLimit, snapshotLimit, true
+}
+
+func clampLimit(limit, min, max int) int {
+	if limit < min {
+		return min
+	}
+	if limit > max {
+		return max
+	}
+	return limit
+}
+
+// tuneCaches resizes the trie clean cache and the snapshot cache based on the
+// lag of the blocks received from [headCh] until the chain is stopped.
+func (bc *BlockChain) tuneCaches(headCh <-chan ChainEvent) {
+	config := bc.cacheConfig.CacheTuner
+	tuner := newCacheTuner(config, bc.cacheConfig.TrieCleanLimit, bc.cacheConfig.SnapshotLimit)
+
+	ticker := time.NewTicker(config.Window)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case head := <-headCh:
+			tuner.observe(time.Since(time.Unix(int64(head.Block.Time()), 0)))
+		case <-ticker.C:
+			var (
+				lag                               = tuner.maxLag
+				prevTrie, prevSnapshot            = tuner.trieLimit, tuner.snapshotLimit
+				trieLimit, snapshotLimit, resized = tuner.endWindow()
+			)
+			if !resized {
+				continue
+			}
+			if trieLimit != prevTrie {
+				if err := bc.triedb.ResizeCleanCache(trieLimit * 1024 * 1024); err != nil {
+					log.Warn("Trie clean cache will not be resized", "err", err)
+					tuner.trieLimit, trieLimit = 0, 0
+				}
+			}
+			if snaps := bc.snaps; snaps != nil && snapshotLimit != prevSnapshot {
+				snaps.ResizeCache(snapshotLimit * 1024 * 1024)
+			}
+			log.Info("Resized caches for block processing lag", "lag", common.PrettyDuration(lag), "threshold", config.LagThreshold, "trieClean", trieLimit, "snapshot", snapshotLimit)
+		case <-bc.quit:
+			return
+		}
+	}
+}
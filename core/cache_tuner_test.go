@@ -0,0 +1,103 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheTunerConfigVerify(t *testing.T) {
+	require := require.New(t)
+
+	config := CacheTunerConfig{
+		LagThreshold:      time.Second,
+		Window:            time.Minute,
+		Windows:           2,
+		MinTrieCleanLimit: 64,
+		MaxTrieCleanLimit: 512,
+		MinSnapshotLimit:  64,
+		MaxSnapshotLimit:  256,
+	}
+	require.NoError(config.Verify())
+
+	invalid := config
+	invalid.Windows = 0
+	require.ErrorIs(invalid.Verify(), errInvalidCacheTunerConfig)
+	invalid = config
+	invalid.MinTrieCleanLimit = 1024
+	require.ErrorIs(invalid.Verify(), errInvalidCacheTunerConfig)
+	invalid = config
+	invalid.LagThreshold = 0
+	require.ErrorIs(invalid.Verify(), errInvalidCacheTunerConfig)
+}
+
+func TestCacheTuner(t *testing.T) {
+	require := require.New(t)
+
+	tuner := newCacheTuner(&CacheTunerConfig{
+		LagThreshold:      10 * time.Second,
+		Window:            time.Minute,
+		Windows:           2,
+		MinTrieCleanLimit: 64,
+		MaxTrieCleanLimit: 512,
+		MinSnapshotLimit:  64,
+		MaxSnapshotLimit:  256,
+	}, 128, 128)
+	window := func(lags ...time.Duration) (int, int, bool) {
+		for _, lag := range lags {
+			tuner.observe(lag)
+		}
+		return tuner.endWindow()
+	}
+
+	// Windows without accepted blocks are ignored.
+	_, _, resized := window()
+	require.False(resized)
+
+	// The caches grow after consecutive windows lagging behind, but a single
+	// block keeping up interrupts the lag.
+	_, _, resized = window(20*time.Second, 30*time.Second)
+	require.False(resized)
+	_, _, resized = window(20*time.Second, time.Second)
+	require.False(resized)
+	_, _, resized = window(20 * time.Second)
+	require.False(resized)
+	trieLimit, snapshotLimit, resized := window(20 * time.Second)
+	require.True(resized)
+	require.Equal(256, trieLimit)
+	require.Equal(256, snapshotLimit)
+
+	// The caches do not grow beyond their bounds.
+	window(time.Minute)
+	trieLimit, snapshotLimit, resized = window(time.Minute)
+	require.True(resized)
+	require.Equal(512, trieLimit)
+	require.Equal(256, snapshotLimit)
+	window(time.Minute)
+	_, _, resized = window(time.Minute)
+	require.False(resized)
+
+	// The caches shrink once caught up, down to their bounds.
+	window(time.Second)
+	trieLimit, snapshotLimit, resized = window(time.Second, 2*time.Second)
+	require.True(resized)
+	require.Equal(256, trieLimit)
+	require.Equal(128, snapshotLimit)
+	for i := 0; i < 6; i++ {
+		window(time.Second)
+	}
+	trieLimit, snapshotLimit, _ = tuner.endWindow()
+	require.Equal(64, trieLimit)
+	require.Equal(64, snapshotLimit)
+
+	// Lag between half of the threshold and the threshold keeps the caches.
+	window(time.Second)
+	_, _, resized = window(7 * time.Second)
+	require.False(resized)
+	_, _, resized = window(time.Second)
+	require.False(resized)
+}
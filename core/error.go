@@ -87,6 +87,10 @@ var (
 	// current network configuration.
 	ErrTxTypeNotSupported = types.ErrTxTypeNotSupported
 
+	// ErrInvalidCustomTx is returned if a transaction of a custom type fails
+	// the validation rules registered for the type.
+	ErrInvalidCustomTx = errors.New("invalid custom transaction")
+
 	// ErrTipAboveFeeCap is a sanity error to ensure no one is able to specify a
 	// transaction with a tip higher than the total fee cap.
 	ErrTipAboveFeeCap = errors.New("max priority fee per gas higher than max fee per gas")
@@ -0,0 +1,82 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/shubhamdubey02/coreth/accounts/abi"
+	"github.com/shubhamdubey02/coreth/core/vm"
+)
+
+// GasSponsorCallGas is the gas available to the static call of a gas sponsor
+// contract. It is not charged to the sender or the sponsor.
+const GasSponsorCallGas = 100_000
+
+// gasSponsorABI is the interface of a gas sponsor contract:
+//
+//	function isSponsored(address from, address to, uint256 value, uint256 gas, uint256 gasFeeCap, bytes data) view returns (bool)
+//
+// The zero address is passed as [to] for contract creations.
+var gasSponsorABI = mustParseABI(`[{
+	"type": "function",
+	"name": "isSponsored",
+	"stateMutability": "view",
+	"inputs": [
+		{"name": "from", "type": "address"},
+		{"name": "to", "type": "address"},
+		{"name": "value", "type": "uint256"},
+		{"name": "gas", "type": "uint256"},
+		{"name": "gasFeeCap", "type": "uint256"},
+		{"name": "data", "type": "bytes"}
+	],
+	"outputs": [{"name": "", "type": "bool"}]
+}]`)
+
+func mustParseABI(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// IsGasSponsored returns whether the contract at [sponsor] pays the gas of
+// [msg], by statically calling its isSponsored view function with [evm]. The
+// call is not traced and any change it makes to the state is reverted.
+func IsGasSponsored(evm *vm.EVM, sponsor common.Address, msg *Message) bool {
+	var to common.Address
+	if msg.To != nil {
+		to = *msg.To
+	}
+	input, err := gasSponsorABI.Pack("isSponsored", msg.From, to, msg.Value, new(big.Int).SetUint64(msg.GasLimit), msg.GasFeeCap, msg.Data)
+	if err != nil {
+		log.Debug("Failed to pack gas sponsor call", "from", msg.From, "err", err)
+		return false
+	}
+
+	tracer := evm.Config.Tracer
+	evm.Config.Tracer = nil
+	snapshot := evm.StateDB.Snapshot()
+	defer func() {
+		evm.StateDB.RevertToSnapshot(snapshot)
+		evm.Config.Tracer = tracer
+	}()
+
+	ret, _, err := evm.StaticCall(vm.AccountRef(msg.From), sponsor, input, GasSponsorCallGas)
+	if err != nil {
+		log.Debug("Gas sponsor call failed", "from", msg.From, "sponsor", sponsor, "err", err)
+		return false
+	}
+	out, err := gasSponsorABI.Unpack("isSponsored", ret)
+	if err != nil {
+		log.Debug("Failed to unpack gas sponsor call", "from", msg.From, "sponsor", sponsor, "err", err)
+		return false
+	}
+	sponsored, _ := out[0].(bool)
+	return sponsored
+}
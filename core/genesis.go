@@ -33,6 +33,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -217,9 +218,18 @@ func SetupGenesisBlock(
 	if storedcfg == nil {
 		log.Warn("Found genesis block without chain config")
 		rawdb.WriteChainConfig(db, stored, newcfg)
+		rawdb.WriteUpgradeConfig(db, stored, newcfg.UpgradeConfig)
 		return newcfg, stored, nil
 	}
-	storedData, _ := json.Marshal(storedcfg)
+	// The upgrades are not part of the stored chain config. Configs stored
+	// before their upgrades were are assumed to have the supplied upgrades.
+	storedUpgrades := rawdb.ReadUpgradeConfig(db, stored)
+	if storedUpgrades != nil {
+		storedcfg.UpgradeConfig = *storedUpgrades
+	} else {
+		storedcfg.UpgradeConfig = newcfg.UpgradeConfig
+	}
+	storedData, _ := json.Marshal(storedcfg.ToWithUpgradesJSON())
 	// Check config compatibility and write the config. Compatibility errors
 	// are returned to the caller unless we're already at block zero.
 	// we use last accepted block for cfg compatibility check. Note this allows
@@ -239,16 +249,43 @@ func SetupGenesisBlock(
 	} else {
 		compatErr := storedcfg.CheckCompatible(newcfg, height, timestamp)
 		if compatErr != nil && ((height != 0 && compatErr.RewindToBlock != 0) || (timestamp != 0 && compatErr.RewindToTime != 0)) {
+			log.Error("Supplied chain config is incompatible with the stored config", "height", height, "timestamp", timestamp, "err", compatErr)
+			logConfigChanges(log.Error, storedcfg, newcfg)
 			return newcfg, stored, compatErr
 		}
 	}
-	// Don't overwrite if the old is identical to the new
-	if newData, _ := json.Marshal(newcfg); !bytes.Equal(storedData, newData) {
+	// Don't overwrite if the old is identical to the new, but keep the old
+	// one for audits otherwise.
+	if newData, _ := json.Marshal(newcfg.ToWithUpgradesJSON()); !bytes.Equal(storedData, newData) {
+		log.Info("Updating stored chain config", "height", height, "timestamp", timestamp)
+		logConfigChanges(log.Info, storedcfg, newcfg)
+		rawdb.WriteChainConfigHistory(db, stored, &rawdb.ChainConfigHistoryEntry{
+			ReplacedAt: uint64(time.Now().Unix()),
+			Height:     height,
+			Timestamp:  timestamp,
+			Config:     storedcfg.ToWithUpgradesJSON(),
+		})
 		rawdb.WriteChainConfig(db, stored, newcfg)
+		rawdb.WriteUpgradeConfig(db, stored, newcfg.UpgradeConfig)
+	} else if storedUpgrades == nil {
+		rawdb.WriteUpgradeConfig(db, stored, newcfg.UpgradeConfig)
 	}
 	return newcfg, stored, nil
 }
 
+// logConfigChanges logs each field of the chain config whose value differs
+// between [storedcfg] and [newcfg] with [logFn].
+func logConfigChanges(logFn func(msg string, ctx ...interface{}), storedcfg, newcfg *params.ChainConfig) {
+	changes, err := storedcfg.Diff(newcfg)
+	if err != nil {
+		log.Warn("Failed to diff chain configs", "err", err)
+		return
+	}
+	for _, change := range changes {
+		logFn("Chain config change", "field", change.Field, "stored", change.Old, "supplied", change.New)
+	}
+}
+
 // IsVerkle indicates whether the state is already stored in a verkle
 // tree at genesis time.
 func (g *Genesis) IsVerkle() bool {
@@ -376,6 +413,7 @@ func (g *Genesis) Commit(db ethdb.Database, triedb *trie.Database) (*types.Block
 	rawdb.WriteHeadBlockHash(db, block.Hash())
 	rawdb.WriteHeadHeaderHash(db, block.Hash())
 	rawdb.WriteChainConfig(db, block.Hash(), config)
+	rawdb.WriteUpgradeConfig(db, block.Hash(), config.UpgradeConfig)
 	return block, nil
 }
 
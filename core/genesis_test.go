@@ -336,3 +336,38 @@ func TestVerkleGenesisCommit(t *testing.T) {
 		t.Fatal("could not find node")
 	}
 }
+
+func TestSetupGenesisBlockConfigHistory(t *testing.T) {
+	require := require.New(t)
+	db := rawdb.NewMemoryDatabase()
+	tdb := trie.NewDatabase(db, nil)
+	customg := Genesis{
+		Config: params.TestApricotPhase1Config,
+		Alloc:  GenesisAlloc{{1}: {Balance: big.NewInt(1)}},
+	}
+	genesis := customg.MustCommit(db, tdb)
+
+	// Supplying the stored config does not replace it.
+	_, _, err := setupGenesisBlock(db, tdb, &customg, genesis.Hash())
+	require.NoError(err)
+	history, err := rawdb.ReadChainConfigHistory(db, genesis.Hash())
+	require.NoError(err)
+	require.Empty(history)
+
+	// Scheduling upgrades records the replaced config.
+	config := *params.TestApricotPhase1Config
+	config.ApricotPhase2BlockTimestamp = utils.NewUint64(51)
+	config.TxTypeUpgrades = []params.TxTypeUpgrade{{TxType: 0x10, BlockTimestamp: 100}}
+	updatedg := customg
+	updatedg.Config = &config
+	_, _, err = setupGenesisBlock(db, tdb, &updatedg, genesis.Hash())
+	require.NoError(err)
+	history, err = rawdb.ReadChainConfigHistory(db, genesis.Hash())
+	require.NoError(err)
+	require.Len(history, 1)
+	require.Zero(history[0].Height)
+	require.Nil(history[0].Config.ApricotPhase2BlockTimestamp)
+	require.Empty(history[0].Config.UpgradeConfig.TxTypeUpgrades)
+	require.Equal(config.ApricotPhase2BlockTimestamp, rawdb.ReadChainConfig(db, genesis.Hash()).ApricotPhase2BlockTimestamp)
+	require.Equal(config.TxTypeUpgrades, rawdb.ReadUpgradeConfig(db, genesis.Hash()).TxTypeUpgrades)
+}
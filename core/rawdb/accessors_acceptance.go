@@ -0,0 +1,53 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rawdb
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// BlockAcceptance records the acceptance of a block by consensus.
+type BlockAcceptance struct {
+	// Time is the unix time at which the block was accepted, in milliseconds.
+	Time uint64
+	// PChainHeight is the P-Chain height of the proposervm block wrapping the
+	// block, or 0 if the block was not verified with its context.
+	PChainHeight uint64
+}
+
+// ReadBlockAcceptance retrieves the acceptance record of a block, or nil if it
+// was not stored.
+func ReadBlockAcceptance(db ethdb.KeyValueReader, hash common.Hash, number uint64) *BlockAcceptance {
+	data, _ := db.Get(blockAcceptanceKey(number, hash))
+	if len(data) == 0 {
+		return nil
+	}
+	acceptance := new(BlockAcceptance)
+	if err := rlp.DecodeBytes(data, acceptance); err != nil {
+		log.Error("Invalid block acceptance RLP", "hash", hash, "err", err)
+		return nil
+	}
+	return acceptance
+}
+
+// WriteBlockAcceptance stores the acceptance record of a block.
+func WriteBlockAcceptance(db ethdb.KeyValueWriter, hash common.Hash, number uint64, acceptance *BlockAcceptance) {
+	data, err := rlp.EncodeToBytes(acceptance)
+	if err != nil {
+		log.Crit("Failed to encode block acceptance", "err", err)
+	}
+	if err := db.Put(blockAcceptanceKey(number, hash), data); err != nil {
+		log.Crit("Failed to store block acceptance", "err", err)
+	}
+}
+
+// DeleteBlockAcceptance removes the acceptance record of a block.
+func DeleteBlockAcceptance(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
+	if err := db.Delete(blockAcceptanceKey(number, hash)); err != nil {
+		log.Crit("Failed to delete block acceptance", "err", err)
+	}
+}
@@ -0,0 +1,52 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rawdb
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/shubhamdubey02/coreth/core/types"
+)
+
+// HasBlobSidecars verifies the existence of the blob sidecars of a block.
+func HasBlobSidecars(db ethdb.KeyValueReader, hash common.Hash, number uint64) bool {
+	has, _ := db.Has(blobSidecarsKey(number, hash))
+	return has
+}
+
+// ReadBlobSidecars retrieves the blob sidecars of the blob transactions of a
+// block, in transaction order. Returns nil if they were not stored.
+func ReadBlobSidecars(db ethdb.KeyValueReader, hash common.Hash, number uint64) []*types.BlobTxSidecar {
+	data, _ := db.Get(blobSidecarsKey(number, hash))
+	if len(data) == 0 {
+		return nil
+	}
+	var sidecars []*types.BlobTxSidecar
+	if err := rlp.DecodeBytes(data, &sidecars); err != nil {
+		log.Error("Invalid blob sidecars RLP", "hash", hash, "err", err)
+		return nil
+	}
+	return sidecars
+}
+
+// WriteBlobSidecars stores the blob sidecars of the blob transactions of a
+// block, in transaction order.
+func WriteBlobSidecars(db ethdb.KeyValueWriter, hash common.Hash, number uint64, sidecars []*types.BlobTxSidecar) {
+	data, err := rlp.EncodeToBytes(sidecars)
+	if err != nil {
+		log.Crit("Failed to encode blob sidecars", "err", err)
+	}
+	if err := db.Put(blobSidecarsKey(number, hash), data); err != nil {
+		log.Crit("Failed to store blob sidecars", "err", err)
+	}
+}
+
+// DeleteBlobSidecars removes the blob sidecars of a block.
+func DeleteBlobSidecars(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
+	if err := db.Delete(blobSidecarsKey(number, hash)); err != nil {
+		log.Crit("Failed to delete blob sidecars", "err", err)
+	}
+}
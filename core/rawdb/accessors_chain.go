@@ -534,6 +534,10 @@ func WriteBlock(db ethdb.KeyValueWriter, block *types.Block) {
 // DeleteBlock removes all block data associated with a hash.
 func DeleteBlock(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
 	DeleteReceipts(db, hash, number)
+	DeleteReceiptCosts(db, hash, number)
+	DeleteBlobSidecars(db, hash, number)
+	DeleteBlockAcceptance(db, hash, number)
+	DeleteBlockStorageAccesses(db, hash, number)
 	DeleteHeader(db, hash, number)
 	DeleteBody(db, hash, number)
 }
@@ -542,6 +546,10 @@ func DeleteBlock(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
 // the hash to number mapping.
 func DeleteBlockWithoutNumber(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
 	DeleteReceipts(db, hash, number)
+	DeleteReceiptCosts(db, hash, number)
+	DeleteBlobSidecars(db, hash, number)
+	DeleteBlockAcceptance(db, hash, number)
+	DeleteBlockStorageAccesses(db, hash, number)
 	deleteHeaderWithoutNumber(db, hash, number)
 	DeleteBody(db, hash, number)
 }
@@ -0,0 +1,80 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rawdb
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// DurableFilter is a named log filter whose cursor is persisted, so that its
+// client can resume polling after a disconnect.
+type DurableFilter struct {
+	Addresses []common.Address
+	Topics    [][]common.Hash
+	// Cursor is the number of the last accepted block whose logs were
+	// returned.
+	Cursor uint64
+	// ToBlock is the last block whose logs are returned if HasToBlock is set.
+	ToBlock    uint64
+	HasToBlock bool
+	// LastPoll is the unix time at which the filter was last polled, in
+	// seconds.
+	LastPoll uint64
+}
+
+// ReadDurableFilter retrieves the durable filter named [name], or nil if it
+// was not stored.
+func ReadDurableFilter(db ethdb.KeyValueReader, name string) *DurableFilter {
+	data, _ := db.Get(durableFilterKey(name))
+	if len(data) == 0 {
+		return nil
+	}
+	filter := new(DurableFilter)
+	if err := rlp.DecodeBytes(data, filter); err != nil {
+		log.Error("Invalid durable filter RLP", "name", name, "err", err)
+		return nil
+	}
+	return filter
+}
+
+// WriteDurableFilter stores the durable filter named [name].
+func WriteDurableFilter(db ethdb.KeyValueWriter, name string, filter *DurableFilter) {
+	data, err := rlp.EncodeToBytes(filter)
+	if err != nil {
+		log.Crit("Failed to encode durable filter", "err", err)
+	}
+	if err := db.Put(durableFilterKey(name), data); err != nil {
+		log.Crit("Failed to store durable filter", "err", err)
+	}
+}
+
+// DeleteDurableFilter removes the durable filter named [name].
+func DeleteDurableFilter(db ethdb.KeyValueWriter, name string) {
+	if err := db.Delete(durableFilterKey(name)); err != nil {
+		log.Crit("Failed to delete durable filter", "err", err)
+	}
+}
+
+// IterateDurableFilters calls [fn] with each stored durable filter until [fn]
+// returns false.
+func IterateDurableFilters(db ethdb.Iteratee, fn func(name string, filter *DurableFilter) bool) error {
+	it := db.NewIterator(durableFilterPrefix, nil)
+	defer it.Release()
+
+	for it.Next() {
+		name := string(it.Key()[len(durableFilterPrefix):])
+		filter := new(DurableFilter)
+		if err := rlp.DecodeBytes(it.Value(), filter); err != nil {
+			log.Error("Invalid durable filter RLP", "name", name, "err", err)
+			continue
+		}
+		if !fn(name, filter) {
+			break
+		}
+	}
+	return it.Error()
+}
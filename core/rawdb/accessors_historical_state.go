@@ -0,0 +1,50 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rawdb
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// HistoricalStateNamespace returns the prefix of the keys under which the trie
+// nodes and code of the cached historical state [id] are stored.
+func HistoricalStateNamespace(id uint64) []byte {
+	return historicalStateNamespace(id)
+}
+
+// DeleteHistoricalState removes the trie nodes and code of the cached
+// historical state [id].
+func DeleteHistoricalState(db ethdb.KeyValueStore, id uint64) error {
+	return deletePrefix(db, historicalStateNamespace(id))
+}
+
+// DeleteHistoricalStates removes the trie nodes and code of every cached
+// historical state.
+func DeleteHistoricalStates(db ethdb.KeyValueStore) error {
+	return deletePrefix(db, historicalStatePrefix)
+}
+
+// deletePrefix removes all keys in db that begin with prefix.
+func deletePrefix(db ethdb.KeyValueStore, prefix []byte) error {
+	it := db.NewIterator(prefix, nil)
+	defer it.Release()
+
+	batch := db.NewBatch()
+	for it.Next() {
+		if err := batch.Delete(common.CopyBytes(it.Key())); err != nil {
+			return err
+		}
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	return batch.Write()
+}
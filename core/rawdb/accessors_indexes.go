@@ -169,6 +169,14 @@ func WriteBloomBits(db ethdb.KeyValueWriter, bit uint, section uint64, head comm
 	}
 }
 
+// DeleteBloomBits removes the compressed bloom bits vector belonging to the
+// given section and bit index.
+func DeleteBloomBits(db ethdb.KeyValueWriter, bit uint, section uint64, head common.Hash) {
+	if err := db.Delete(bloomBitsKey(bit, section, head)); err != nil {
+		log.Crit("Failed to delete bloom bits", "err", err)
+	}
+}
+
 // DeleteBloombits removes all compressed bloom bits vector belonging to the
 // given section range and bit index.
 func DeleteBloombits(db ethdb.Database, bit uint, from uint64, to uint64) {
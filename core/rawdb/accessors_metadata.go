@@ -27,7 +27,9 @@
 package rawdb
 
 import (
+	"encoding/binary"
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/shubhamdubey02/coreth/params"
@@ -91,6 +93,77 @@ func WriteChainConfig(db ethdb.KeyValueWriter, hash common.Hash, cfg *params.Cha
 	}
 }
 
+// ReadUpgradeConfig retrieves the upgrades of the chain config based on the
+// given genesis hash, or nil if they were not stored.
+func ReadUpgradeConfig(db ethdb.KeyValueReader, hash common.Hash) *params.UpgradeConfig {
+	data, _ := db.Get(upgradeConfigKey(hash))
+	if len(data) == 0 {
+		return nil
+	}
+	var upgrades params.UpgradeConfig
+	if err := json.Unmarshal(data, &upgrades); err != nil {
+		log.Error("Invalid upgrade config JSON", "hash", hash, "err", err)
+		return nil
+	}
+	return &upgrades
+}
+
+// WriteUpgradeConfig writes the upgrades of the chain config, which are not
+// part of its JSON encoding, to the database.
+func WriteUpgradeConfig(db ethdb.KeyValueWriter, hash common.Hash, upgrades params.UpgradeConfig) {
+	data, err := json.Marshal(upgrades)
+	if err != nil {
+		log.Crit("Failed to JSON encode upgrade config", "err", err)
+	}
+	if err := db.Put(upgradeConfigKey(hash), data); err != nil {
+		log.Crit("Failed to store upgrade config", "err", err)
+	}
+}
+
+// ChainConfigHistoryEntry is a chain config which was replaced by a compatible
+// config on startup, kept to audit the configs a chain was run with.
+type ChainConfigHistoryEntry struct {
+	// ReplacedAt is the unix time at which the config was replaced, in
+	// seconds.
+	ReplacedAt uint64 `json:"replacedAt"`
+	// Height and Timestamp are those of the last accepted block when the
+	// config was replaced.
+	Height    uint64                              `json:"height"`
+	Timestamp uint64                              `json:"timestamp"`
+	Config    *params.ChainConfigWithUpgradesJSON `json:"config"`
+}
+
+// WriteChainConfigHistory records the chain config replaced as described by
+// [entry], based on the given genesis hash.
+func WriteChainConfigHistory(db ethdb.KeyValueWriter, hash common.Hash, entry *ChainConfigHistoryEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		log.Crit("Failed to JSON encode chain config history", "err", err)
+	}
+	if err := db.Put(configHistoryKey(hash, entry.ReplacedAt), data); err != nil {
+		log.Crit("Failed to store chain config history", "err", err)
+	}
+}
+
+// ReadChainConfigHistory retrieves the chain configs replaced on startup based
+// on the given genesis hash, oldest first.
+func ReadChainConfigHistory(db ethdb.Iteratee, hash common.Hash) ([]*ChainConfigHistoryEntry, error) {
+	prefix := append(common.CopyBytes(configHistoryPrefix), hash.Bytes()...)
+	it := db.NewIterator(prefix, nil)
+	defer it.Release()
+
+	var history []*ChainConfigHistoryEntry
+	for it.Next() {
+		entry := new(ChainConfigHistoryEntry)
+		if err := json.Unmarshal(it.Value(), entry); err != nil {
+			log.Error("Invalid chain config history JSON", "key", common.Bytes2Hex(it.Key()), "err", err)
+			continue
+		}
+		history = append(history, entry)
+	}
+	return history, it.Error()
+}
+
 // crashList is a list of unclean-shutdown-markers, for rlp-encoding to the
 // database
 type crashList struct {
@@ -276,3 +349,120 @@ func ReadAcceptorTip(db ethdb.KeyValueReader) (common.Hash, error) {
 	}
 	return common.BytesToHash(h), nil
 }
+
+// WriteAcceptedConsumerOffset writes the height of the last accepted block
+// processed by the consumer [name].
+func WriteAcceptedConsumerOffset(db ethdb.KeyValueWriter, name string, height uint64) error {
+	return db.Put(append(acceptedConsumerOffsetPrefix, name...), encodeBlockNumber(height))
+}
+
+// ReadAcceptedConsumerOffset reads the height of the last accepted block
+// processed by the consumer [name]. If the consumer has never recorded an
+// offset, nil is returned.
+func ReadAcceptedConsumerOffset(db ethdb.KeyValueReader, name string) (*uint64, error) {
+	key := append(acceptedConsumerOffsetPrefix, name...)
+	has, err := db.Has(key)
+	if !has || err != nil {
+		return nil, err
+	}
+	data, err := db.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != 8 {
+		return nil, fmt.Errorf("invalid accepted consumer offset length %d for %q", len(data), name)
+	}
+	height := binary.BigEndian.Uint64(data)
+	return &height, nil
+}
+
+// DeleteAcceptedConsumerOffset deletes the offset of the consumer [name].
+func DeleteAcceptedConsumerOffset(db ethdb.KeyValueWriter, name string) error {
+	return db.Delete(append(acceptedConsumerOffsetPrefix, name...))
+}
+
+// Retention classes whose tails bound the logs served by log filters.
+const (
+	RetentionReceipts  = "receipts"
+	RetentionLogsIndex = "logs-index"
+)
+
+// WriteRetentionTail writes the first height whose data of [class] is
+// retained, the data of lower heights having been deleted.
+func WriteRetentionTail(db ethdb.KeyValueWriter, class string, height uint64) error {
+	return db.Put(append(common.CopyBytes(retentionTailPrefix), class...), encodeBlockNumber(height))
+}
+
+// ReadRetentionTail reads the first height whose data of [class] is retained.
+// If no data of [class] has been deleted, nil is returned.
+func ReadRetentionTail(db ethdb.KeyValueReader, class string) (*uint64, error) {
+	key := append(common.CopyBytes(retentionTailPrefix), class...)
+	has, err := db.Has(key)
+	if !has || err != nil {
+		return nil, err
+	}
+	data, err := db.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != 8 {
+		return nil, fmt.Errorf("invalid retention tail length %d for %q", len(data), class)
+	}
+	height := binary.BigEndian.Uint64(data)
+	return &height, nil
+}
+
+// CommitJournalEntry is an accepted block whose state has not been committed
+// to disk, and must be re-executed to recover its state after a crash.
+type CommitJournalEntry struct {
+	Number uint64
+	Hash   common.Hash
+	Root   common.Hash
+}
+
+// WriteCommitJournalEntry appends the accepted block [number] of [hash] and
+// state [root] to the commit journal.
+func WriteCommitJournalEntry(db ethdb.KeyValueWriter, number uint64, hash common.Hash, root common.Hash) error {
+	return db.Put(commitJournalKey(number), append(hash.Bytes(), root.Bytes()...))
+}
+
+// ReadCommitJournal reads the entries of the commit journal by ascending
+// height.
+func ReadCommitJournal(db ethdb.Iteratee) ([]CommitJournalEntry, error) {
+	it := db.NewIterator(commitJournalPrefix, nil)
+	defer it.Release()
+
+	var entries []CommitJournalEntry
+	for it.Next() {
+		key, value := it.Key(), it.Value()
+		if len(key) != len(commitJournalPrefix)+8 {
+			continue
+		}
+		if len(value) != 2*common.HashLength {
+			return nil, fmt.Errorf("invalid commit journal entry length %d", len(value))
+		}
+		entries = append(entries, CommitJournalEntry{
+			Number: binary.BigEndian.Uint64(key[len(commitJournalPrefix):]),
+			Hash:   common.BytesToHash(value[:common.HashLength]),
+			Root:   common.BytesToHash(value[common.HashLength:]),
+		})
+	}
+	return entries, it.Error()
+}
+
+// DeleteCommitJournal deletes the entries of the commit journal read from
+// [db] with [batch], once the state of the last accepted block is committed.
+func DeleteCommitJournal(db ethdb.Iteratee, batch ethdb.KeyValueWriter) error {
+	it := db.NewIterator(commitJournalPrefix, nil)
+	defer it.Release()
+
+	for it.Next() {
+		if len(it.Key()) != len(commitJournalPrefix)+8 {
+			continue
+		}
+		if err := batch.Delete(common.CopyBytes(it.Key())); err != nil {
+			return err
+		}
+	}
+	return it.Error()
+}
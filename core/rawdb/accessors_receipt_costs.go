@@ -0,0 +1,54 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rawdb
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/shubhamdubey02/coreth/core/types"
+)
+
+// ReadReceiptCosts retrieves the fee breakdowns of the receipts belonging to a
+// block, in transaction order. Returns nil if they were not stored.
+func ReadReceiptCosts(db ethdb.KeyValueReader, hash common.Hash, number uint64) []*types.ReceiptCosts {
+	data, _ := db.Get(receiptCostsKey(number, hash))
+	if len(data) == 0 {
+		return nil
+	}
+	var costs []*types.ReceiptCosts
+	if err := rlp.DecodeBytes(data, &costs); err != nil {
+		log.Error("Invalid receipt costs RLP", "hash", hash, "err", err)
+		return nil
+	}
+	return costs
+}
+
+// WriteReceiptCosts stores the fee breakdowns of [receipts] belonging to a
+// block. Receipts without a breakdown are stored as zero costs.
+func WriteReceiptCosts(db ethdb.KeyValueWriter, hash common.Hash, number uint64, receipts types.Receipts) {
+	costs := make([]*types.ReceiptCosts, len(receipts))
+	for i, receipt := range receipts {
+		costs[i] = receipt.Costs
+		if costs[i] == nil {
+			costs[i] = &types.ReceiptCosts{}
+		}
+	}
+	data, err := rlp.EncodeToBytes(costs)
+	if err != nil {
+		log.Crit("Failed to encode receipt costs", "err", err)
+	}
+	if err := db.Put(receiptCostsKey(number, hash), data); err != nil {
+		log.Crit("Failed to store receipt costs", "err", err)
+	}
+}
+
+// DeleteReceiptCosts removes the fee breakdowns of the receipts belonging to a
+// block.
+func DeleteReceiptCosts(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
+	if err := db.Delete(receiptCostsKey(number, hash)); err != nil {
+		log.Crit("Failed to delete receipt costs", "err", err)
+	}
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 // ReadSnapshotRoot retrieves the root of the block whose state is contained in
@@ -153,3 +154,57 @@ func WriteSnapshotGenerator(db ethdb.KeyValueWriter, generator []byte) {
 		log.Crit("Failed to store snapshot generator", "err", err)
 	}
 }
+
+// ReadSnapshotJournal retrieves the serialized in-memory diff layers saved at
+// the last shutdown.
+func ReadSnapshotJournal(db ethdb.KeyValueReader) []byte {
+	data, _ := db.Get(snapshotJournalKey)
+	return data
+}
+
+// WriteSnapshotJournal stores the serialized in-memory diff layers to save at
+// shutdown. Unlike most accessors it returns the error instead of exiting, as
+// the journal is written while shutting down, possibly after the database was
+// closed.
+func WriteSnapshotJournal(db ethdb.KeyValueWriter, journal []byte) error {
+	return db.Put(snapshotJournalKey, journal)
+}
+
+// DeleteSnapshotJournal deletes the serialized in-memory diff layers saved at
+// the last shutdown.
+func DeleteSnapshotJournal(db ethdb.KeyValueWriter) error {
+	return db.Delete(snapshotJournalKey)
+}
+
+// ReadSnapshotStorageWipes retrieves the hashes of the destructed accounts
+// whose snapshot storage is not fully deleted yet.
+func ReadSnapshotStorageWipes(db ethdb.KeyValueReader) []common.Hash {
+	data, _ := db.Get(snapshotStorageWipesKey)
+	if len(data) == 0 {
+		return nil
+	}
+	var accounts []common.Hash
+	if err := rlp.DecodeBytes(data, &accounts); err != nil {
+		log.Error("Invalid snapshot storage wipes", "err", err)
+		return nil
+	}
+	return accounts
+}
+
+// WriteSnapshotStorageWipes stores the hashes of the destructed accounts whose
+// snapshot storage is not fully deleted yet, or deletes them if there are none.
+func WriteSnapshotStorageWipes(db ethdb.KeyValueWriter, accounts []common.Hash) {
+	if len(accounts) == 0 {
+		if err := db.Delete(snapshotStorageWipesKey); err != nil {
+			log.Crit("Failed to remove snapshot storage wipes", "err", err)
+		}
+		return
+	}
+	data, err := rlp.EncodeToBytes(accounts)
+	if err != nil {
+		log.Crit("Failed to encode snapshot storage wipes", "err", err)
+	}
+	if err := db.Put(snapshotStorageWipesKey, data); err != nil {
+		log.Crit("Failed to store snapshot storage wipes", "err", err)
+	}
+}
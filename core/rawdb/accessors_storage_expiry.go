@@ -0,0 +1,104 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rawdb
+
+import (
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// SlotAccesses lists the storage slots of an account accessed by a block.
+type SlotAccesses struct {
+	Address common.Address
+	Slots   []common.Hash
+}
+
+// ReadBlockStorageAccesses retrieves the storage slots accessed by a block
+// which was not indexed yet. Returns nil if they were not stored.
+func ReadBlockStorageAccesses(db ethdb.KeyValueReader, hash common.Hash, number uint64) []SlotAccesses {
+	data, _ := db.Get(blockStorageAccessesKey(number, hash))
+	if len(data) == 0 {
+		return nil
+	}
+	var accesses []SlotAccesses
+	if err := rlp.DecodeBytes(data, &accesses); err != nil {
+		log.Error("Invalid block storage accesses RLP", "hash", hash, "err", err)
+		return nil
+	}
+	return accesses
+}
+
+// WriteBlockStorageAccesses stores the storage slots accessed by a block until
+// they are indexed once the block is accepted.
+func WriteBlockStorageAccesses(db ethdb.KeyValueWriter, hash common.Hash, number uint64, accesses []SlotAccesses) {
+	data, err := rlp.EncodeToBytes(accesses)
+	if err != nil {
+		log.Crit("Failed to encode block storage accesses", "err", err)
+	}
+	if err := db.Put(blockStorageAccessesKey(number, hash), data); err != nil {
+		log.Crit("Failed to store block storage accesses", "err", err)
+	}
+}
+
+// DeleteBlockStorageAccesses removes the storage slots accessed by a block.
+func DeleteBlockStorageAccesses(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
+	if err := db.Delete(blockStorageAccessesKey(number, hash)); err != nil {
+		log.Crit("Failed to delete block storage accesses", "err", err)
+	}
+}
+
+// WriteStorageAccess records that [slot] of [addr] was accessed during
+// storage expiry [epoch].
+func WriteStorageAccess(db ethdb.KeyValueWriter, addr common.Address, slot common.Hash, epoch uint64) {
+	if err := db.Put(slotAccessKey(addr, slot, epoch), nil); err != nil {
+		log.Crit("Failed to store slot access index", "err", err)
+	}
+	if err := db.Put(epochAccessKey(epoch, addr, slot), nil); err != nil {
+		log.Crit("Failed to store epoch access index", "err", err)
+	}
+}
+
+// HasStorageAccess returns whether [slot] of [addr] was accessed during a
+// storage expiry epoch in [from, to].
+func HasStorageAccess(db ethdb.Iteratee, addr common.Address, slot common.Hash, from, to uint64) (bool, error) {
+	prefix := append(append(common.CopyBytes(slotAccessPrefix), addr.Bytes()...), slot.Bytes()...)
+	it := db.NewIterator(prefix, encodeBlockNumber(from))
+	defer it.Release()
+
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(prefix)+8 {
+			continue
+		}
+		return binary.BigEndian.Uint64(key[len(prefix):]) <= to, nil
+	}
+	return false, it.Error()
+}
+
+// IterateEpochStorageAccesses calls [fn] with each storage slot accessed
+// during storage expiry [epoch], until [fn] returns false.
+func IterateEpochStorageAccesses(db ethdb.Iteratee, epoch uint64, fn func(addr common.Address, slot common.Hash) bool) error {
+	prefix := append(common.CopyBytes(epochAccessPrefix), encodeBlockNumber(epoch)...)
+	it := db.NewIterator(prefix, nil)
+	defer it.Release()
+
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(prefix)+common.AddressLength+common.HashLength {
+			continue
+		}
+		var (
+			addr = common.BytesToAddress(key[len(prefix) : len(prefix)+common.AddressLength])
+			slot = common.BytesToHash(key[len(prefix)+common.AddressLength:])
+		)
+		if !fn(addr, slot) {
+			break
+		}
+	}
+	return it.Error()
+}
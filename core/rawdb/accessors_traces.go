@@ -0,0 +1,91 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rawdb
+
+import (
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ReadTraceIndexTail retrieves the number of the first block whose traces have
+// been indexed. Returns nil if the trace index was never started.
+func ReadTraceIndexTail(db ethdb.KeyValueReader) *uint64 {
+	data, _ := db.Get(traceIndexTailKey)
+	if len(data) != 8 {
+		return nil
+	}
+	number := binary.BigEndian.Uint64(data)
+	return &number
+}
+
+// WriteTraceIndexTail stores the number of the first block whose traces have
+// been indexed.
+func WriteTraceIndexTail(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Put(traceIndexTailKey, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store the trace index tail", "err", err)
+	}
+}
+
+// ReadBlockTraces retrieves the JSON encoded flat call traces of the accepted
+// block [number]. Returns nil if the block was not indexed.
+func ReadBlockTraces(db ethdb.KeyValueReader, number uint64) []byte {
+	data, _ := db.Get(blockTracesKey(number))
+	return data
+}
+
+// WriteBlockTraces stores the JSON encoded flat call traces of the accepted
+// block [number].
+func WriteBlockTraces(db ethdb.KeyValueWriter, number uint64, traces []byte) {
+	if err := db.Put(blockTracesKey(number), traces); err != nil {
+		log.Crit("Failed to store block traces", "err", err)
+	}
+}
+
+// DeleteBlockTraces removes the flat call traces of the accepted block
+// [number].
+func DeleteBlockTraces(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Delete(blockTracesKey(number)); err != nil {
+		log.Crit("Failed to delete block traces", "err", err)
+	}
+}
+
+// WriteTraceAddressIndex records that the traces of the accepted block
+// [number] involve [addr].
+func WriteTraceAddressIndex(db ethdb.KeyValueWriter, addr common.Address, number uint64) {
+	if err := db.Put(traceAddressKey(addr, number), nil); err != nil {
+		log.Crit("Failed to store trace address index", "err", err)
+	}
+}
+
+// DeleteTraceAddressIndex removes the record that the traces of the accepted
+// block [number] involve [addr].
+func DeleteTraceAddressIndex(db ethdb.KeyValueWriter, addr common.Address, number uint64) {
+	if err := db.Delete(traceAddressKey(addr, number)); err != nil {
+		log.Crit("Failed to delete trace address index", "err", err)
+	}
+}
+
+// IterateTraceAddressIndex calls [fn] with the number of each block in
+// [from, to] whose traces involve [addr], in ascending order, until [fn]
+// returns false.
+func IterateTraceAddressIndex(db ethdb.Iteratee, addr common.Address, from, to uint64, fn func(number uint64) bool) error {
+	prefix := append(common.CopyBytes(traceAddressPrefix), addr.Bytes()...)
+	it := db.NewIterator(prefix, encodeBlockNumber(from))
+	defer it.Release()
+
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(prefix)+8 {
+			continue
+		}
+		number := binary.BigEndian.Uint64(key[len(prefix):])
+		if number > to || !fn(number) {
+			break
+		}
+	}
+	return it.Error()
+}
@@ -0,0 +1,186 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rawdb
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// maxChainIntegrityErrors is the maximum number of errors reported by
+// [VerifyCanonicalChain].
+const maxChainIntegrityErrors = 100
+
+// VerifyCanonicalChain checks that the canonical hashes from [start] to [end]
+// (inclusive) form a chain of headers, each with its body and receipts stored.
+// At most [maxChainIntegrityErrors] errors are returned.
+func VerifyCanonicalChain(db ethdb.Reader, start, end uint64) []error {
+	var (
+		errs       []error
+		parentHash common.Hash
+	)
+	if start > 0 {
+		parentHash = ReadCanonicalHash(db, start-1)
+	}
+	for number := start; number <= end && len(errs) < maxChainIntegrityErrors; number++ {
+		hash := ReadCanonicalHash(db, number)
+		if hash == (common.Hash{}) {
+			errs = append(errs, fmt.Errorf("missing canonical hash at height %d", number))
+			parentHash = common.Hash{}
+			continue
+		}
+		header := ReadHeader(db, hash, number)
+		switch {
+		case header == nil:
+			errs = append(errs, fmt.Errorf("missing header %s at height %d", hash, number))
+		case number > 0 && parentHash != (common.Hash{}) && header.ParentHash != parentHash:
+			errs = append(errs, fmt.Errorf("header %s at height %d has parent %s, expected %s", hash, number, header.ParentHash, parentHash))
+		}
+		if !HasBody(db, hash, number) {
+			errs = append(errs, fmt.Errorf("missing body %s at height %d", hash, number))
+		}
+		if !HasReceipts(db, hash, number) {
+			errs = append(errs, fmt.Errorf("missing receipts %s at height %d", hash, number))
+		}
+		parentHash = hash
+	}
+	return errs
+}
+
+// OrphanedBlockData counts the block data that is not part of the canonical
+// chain.
+type OrphanedBlockData struct {
+	Headers  uint64 `json:"headers"`
+	Bodies   uint64 `json:"bodies"`
+	Receipts uint64 `json:"receipts"`
+}
+
+// FindOrphanedBlockData counts the headers, bodies and receipts at or below
+// [lastAccepted] that are not part of the canonical chain. Block data above
+// [lastAccepted] may belong to blocks that are still processing, so it is
+// not counted.
+func FindOrphanedBlockData(db ethdb.Database, lastAccepted uint64) (*OrphanedBlockData, error) {
+	orphaned := &OrphanedBlockData{}
+	for _, family := range []struct {
+		prefix []byte
+		count  *uint64
+	}{
+		{headerPrefix, &orphaned.Headers},
+		{blockBodyPrefix, &orphaned.Bodies},
+		{blockReceiptsPrefix, &orphaned.Receipts},
+	} {
+		keyLen := len(family.prefix) + 8 + common.HashLength
+		it := db.NewIterator(family.prefix, nil)
+		for it.Next() {
+			key := it.Key()
+			if len(key) != keyLen {
+				continue
+			}
+			number := binary.BigEndian.Uint64(key[len(family.prefix):])
+			if number > lastAccepted {
+				continue
+			}
+			hash := common.BytesToHash(key[len(family.prefix)+8:])
+			if ReadCanonicalHash(db, number) != hash {
+				*family.count++
+			}
+		}
+		err := it.Error()
+		it.Release()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return orphaned, nil
+}
+
+// TxIndexReport describes the transaction lookup entries of a range of the
+// canonical chain checked by [RepairTxIndex].
+type TxIndexReport struct {
+	Blocks       uint64 `json:"blocks"`
+	Transactions uint64 `json:"transactions"`
+	// Missing is the number of transactions without a lookup entry.
+	Missing uint64 `json:"missing"`
+	// Mismatched is the number of transactions whose lookup entry points at
+	// another block.
+	Mismatched uint64 `json:"mismatched"`
+	// Repaired is the number of lookup entries rewritten.
+	Repaired uint64 `json:"repaired"`
+	// Errors lists the blocks whose transactions could not be checked, up to
+	// [maxChainIntegrityErrors].
+	Errors []string `json:"errors"`
+}
+
+// RepairTxIndex checks that every transaction of the canonical blocks from
+// [start] to [end] (inclusive) has a lookup entry pointing at its block. If
+// [repair] is set, missing and mismatched entries are rewritten.
+func RepairTxIndex(db ethdb.Database, start, end uint64, repair bool) (*TxIndexReport, error) {
+	var (
+		report = &TxIndexReport{}
+		batch  = db.NewBatch()
+		hashes []common.Hash
+	)
+	for number := start; number <= end; number++ {
+		hash := ReadCanonicalHash(db, number)
+		if hash == (common.Hash{}) {
+			report.addError(fmt.Errorf("missing canonical hash at height %d", number))
+			continue
+		}
+		body := ReadBody(db, hash, number)
+		if body == nil {
+			report.addError(fmt.Errorf("missing body %s at height %d", hash, number))
+			continue
+		}
+		report.Blocks++
+		hashes = hashes[:0]
+		for _, tx := range body.Transactions {
+			report.Transactions++
+			switch entry := ReadTxLookupEntry(db, tx.Hash()); {
+			case entry == nil && number == 0 && hasTxLookupEntry(db, tx.Hash()):
+				// Entries of the genesis block encode height 0 as empty bytes,
+				// which ReadTxLookupEntry does not tell apart from a missing
+				// entry.
+				continue
+			case entry == nil:
+				report.Missing++
+			case *entry != number:
+				report.Mismatched++
+			default:
+				continue
+			}
+			hashes = append(hashes, tx.Hash())
+		}
+		if !repair || len(hashes) == 0 {
+			continue
+		}
+		WriteTxLookupEntries(batch, number, hashes)
+		report.Repaired += uint64(len(hashes))
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return nil, err
+			}
+			batch.Reset()
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (r *TxIndexReport) addError(err error) {
+	if len(r.Errors) < maxChainIntegrityErrors {
+		r.Errors = append(r.Errors, err.Error())
+	}
+}
+
+// hasTxLookupEntry returns whether a lookup entry of [hash] is stored,
+// including an empty one.
+func hasTxLookupEntry(db ethdb.KeyValueReader, hash common.Hash) bool {
+	ok, _ := db.Has(txLookupKey(hash))
+	return ok
+}
@@ -0,0 +1,124 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rawdb
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shubhamdubey02/coreth/core/types"
+)
+
+func TestVerifyCanonicalChain(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	var (
+		parent common.Hash
+		blocks []*types.Block
+	)
+	for i := int64(0); i < 5; i++ {
+		block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(i), ParentHash: parent})
+		WriteBlock(db, block)
+		WriteReceipts(db, block.Hash(), block.NumberU64(), nil)
+		WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		blocks = append(blocks, block)
+		parent = block.Hash()
+	}
+	// A block that is not part of the canonical chain.
+	side := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(2), ParentHash: blocks[1].Hash(), Extra: []byte("side")})
+	WriteBlock(db, side)
+	WriteReceipts(db, side.Hash(), side.NumberU64(), nil)
+
+	if errs := VerifyCanonicalChain(db, 0, 4); len(errs) != 0 {
+		t.Fatalf("unexpected errors verifying intact chain: %v", errs)
+	}
+	orphaned, err := FindOrphanedBlockData(db, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *orphaned != (OrphanedBlockData{Headers: 1, Bodies: 1, Receipts: 1}) {
+		t.Fatalf("unexpected orphaned data: %+v", orphaned)
+	}
+	// Data above the last accepted height may still be processing.
+	if orphaned, err = FindOrphanedBlockData(db, 1); err != nil {
+		t.Fatal(err)
+	} else if *orphaned != (OrphanedBlockData{}) {
+		t.Fatalf("unexpected orphaned data above last accepted: %+v", orphaned)
+	}
+
+	// Break the chain by pointing height 2 at the side block and removing a body.
+	WriteCanonicalHash(db, side.Hash(), 2)
+	DeleteBody(db, blocks[3].Hash(), 3)
+	if errs := VerifyCanonicalChain(db, 0, 4); len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %v", errs)
+	}
+	if errs := VerifyCanonicalChain(db, 0, 2); len(errs) != 0 {
+		t.Fatalf("unexpected errors verifying chain up to the side block: %v", errs)
+	}
+}
+
+func TestRepairTxIndex(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	var blocks []*types.Block
+	for i := int64(0); i < 3; i++ {
+		tx := types.NewTransaction(uint64(i), common.Address{0x01}, big.NewInt(i), 21000, big.NewInt(1), nil)
+		block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(i)}).WithBody([]*types.Transaction{tx}, nil)
+		WriteBlock(db, block)
+		WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		WriteTxLookupEntriesByBlock(db, block)
+		blocks = append(blocks, block)
+	}
+	report, err := RepairTxIndex(db, 0, 2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Blocks != 3 || report.Transactions != 3 || report.Missing != 0 || report.Mismatched != 0 {
+		t.Fatalf("unexpected report for intact index: %+v", report)
+	}
+
+	// Remove one entry and point another at the wrong block.
+	DeleteTxLookupEntry(db, blocks[0].Transactions()[0].Hash())
+	WriteTxLookupEntries(db, 2, []common.Hash{blocks[1].Transactions()[0].Hash()})
+	if report, err = RepairTxIndex(db, 0, 2, false); err != nil {
+		t.Fatal(err)
+	}
+	if report.Missing != 1 || report.Mismatched != 1 || report.Repaired != 0 {
+		t.Fatalf("unexpected report for broken index: %+v", report)
+	}
+	if hasTxLookupEntry(db, blocks[0].Transactions()[0].Hash()) {
+		t.Fatal("index repaired without repair")
+	}
+
+	if report, err = RepairTxIndex(db, 0, 2, true); err != nil {
+		t.Fatal(err)
+	}
+	if report.Repaired != 2 {
+		t.Fatalf("expected 2 repaired entries, got %+v", report)
+	}
+	for _, block := range blocks {
+		hash := block.Transactions()[0].Hash()
+		if block.NumberU64() == 0 {
+			// The genesis entry is stored as empty bytes.
+			if !hasTxLookupEntry(db, hash) {
+				t.Fatal("lookup entry of block 0 not repaired")
+			}
+			continue
+		}
+		entry := ReadTxLookupEntry(db, hash)
+		if entry == nil || *entry != block.NumberU64() {
+			t.Fatalf("lookup entry of block %d not repaired: %v", block.NumberU64(), entry)
+		}
+	}
+
+	// Blocks without bodies are reported.
+	DeleteBody(db, blocks[2].Hash(), 2)
+	if report, err = RepairTxIndex(db, 0, 2, false); err != nil {
+		t.Fatal(err)
+	}
+	if report.Blocks != 2 || len(report.Errors) != 1 {
+		t.Fatalf("expected missing body to be reported, got %+v", report)
+	}
+}
@@ -0,0 +1,266 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rawdb
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	compactionDebtGauge       = metrics.NewRegisteredGauge("db/compaction/debt", nil)
+	compactionRunsCounter     = metrics.NewRegisteredCounter("db/compaction/runs", nil)
+	compactionFailuresCounter = metrics.NewRegisteredCounter("db/compaction/failures", nil)
+	compactionTimer           = metrics.NewRegisteredTimer("db/compaction/duration", nil)
+)
+
+// compactionRanges maps the name of each key range the [CompactionScheduler]
+// may compact to the prefix of its keys. The names of the block data ranges
+// match the classes of the retention policy deleting them.
+var compactionRanges = map[string][]byte{
+	"headers":          headerPrefix,
+	"bodies":           blockBodyPrefix,
+	"receipts":         blockReceiptsPrefix,
+	"tx-index":         txLookupPrefix,
+	"logs-index":       bloomBitsPrefix,
+	"blob-sidecars":    blobSidecarsPrefix,
+	"traces":           blockTracesPrefix,
+	"snapshot-account": SnapshotAccountPrefix,
+	"snapshot-storage": SnapshotStoragePrefix,
+}
+
+// CompactionRangeNames returns the sorted names of the key ranges accepted
+// by [NewCompactionScheduler].
+func CompactionRangeNames() []string {
+	names := make([]string, 0, len(compactionRanges))
+	for name := range compactionRanges {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// prefixLimit returns the first key following every key starting with
+// [prefix], or nil if there is none.
+func prefixLimit(prefix []byte) []byte {
+	limit := make([]byte, len(prefix))
+	copy(limit, prefix)
+	for i := len(limit) - 1; i >= 0; i-- {
+		if limit[i] < 0xff {
+			limit[i]++
+			return limit[:i+1]
+		}
+	}
+	return nil
+}
+
+// QuietWindow is a daily window of low traffic, in UTC.
+type QuietWindow struct {
+	// Start and End are the offsets of the window from midnight. The window
+	// wraps around midnight if End is not after Start.
+	Start, End time.Duration
+}
+
+// ParseQuietWindow parses a window formatted as "HH:MM-HH:MM".
+func ParseQuietWindow(s string) (QuietWindow, error) {
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return QuietWindow{}, fmt.Errorf("quiet window %q is not formatted as HH:MM-HH:MM", s)
+	}
+	var (
+		w   QuietWindow
+		err error
+	)
+	if w.Start, err = parseTimeOfDay(from); err != nil {
+		return QuietWindow{}, fmt.Errorf("invalid start of quiet window %q: %w", s, err)
+	}
+	if w.End, err = parseTimeOfDay(to); err != nil {
+		return QuietWindow{}, fmt.Errorf("invalid end of quiet window %q: %w", s, err)
+	}
+	if w.Start == w.End {
+		return QuietWindow{}, fmt.Errorf("quiet window %q is empty", s)
+	}
+	return w, nil
+}
+
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// opened returns when the occurrence of the window containing [t] opened,
+// or false if [t] is outside of the window.
+func (w QuietWindow) opened(t time.Time) (time.Time, bool) {
+	t = t.UTC()
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	offset := t.Sub(midnight)
+	switch {
+	case w.Start < w.End:
+		return midnight.Add(w.Start), offset >= w.Start && offset < w.End
+	case offset >= w.Start:
+		return midnight.Add(w.Start), true
+	case offset < w.End:
+		// The window opened the day before.
+		return midnight.AddDate(0, 0, -1).Add(w.Start), true
+	default:
+		return time.Time{}, false
+	}
+}
+
+// CompactionScheduler compacts key ranges of the database once during each
+// quiet window, and as soon as the deletions recorded in a range since its
+// last compaction reach the deletion threshold. This keeps the tombstones
+// left by pruning from slowing down reads until the backend gets to them.
+type CompactionScheduler struct {
+	db        ethdb.Compacter
+	windows   []QuietWindow
+	scheduled []string
+	threshold uint64
+	now       func() time.Time
+
+	lock sync.Mutex
+	// debt is the number of deletions recorded in each range since it was
+	// last compacted.
+	debt map[string]uint64
+	// compacted is when each range was last compacted.
+	compacted map[string]time.Time
+}
+
+// NewCompactionScheduler returns a scheduler compacting the [scheduled]
+// ranges of [db] during each of the quiet [windows], and any range once
+// [threshold] deletions are recorded in it. A zero threshold disables
+// compaction after deletions.
+func NewCompactionScheduler(db ethdb.Compacter, windows []QuietWindow, scheduled []string, threshold uint64) (*CompactionScheduler, error) {
+	for _, name := range scheduled {
+		if _, ok := compactionRanges[name]; !ok {
+			return nil, fmt.Errorf("unknown compaction range %q", name)
+		}
+	}
+	return &CompactionScheduler{
+		db:        db,
+		windows:   windows,
+		scheduled: scheduled,
+		threshold: threshold,
+		now:       time.Now,
+		debt:      make(map[string]uint64),
+		compacted: make(map[string]time.Time),
+	}, nil
+}
+
+// RecordDeletions adds [count] deletions to the debt of the range [name].
+// It is a no-op on a nil scheduler and for unknown ranges.
+func (s *CompactionScheduler) RecordDeletions(name string, count uint64) {
+	if s == nil {
+		return
+	}
+	if _, ok := compactionRanges[name]; !ok {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.debt[name] += count
+	compactionDebtGauge.Inc(int64(count))
+}
+
+// Debt returns the number of deletions recorded in each range since it was
+// last compacted.
+func (s *CompactionScheduler) Debt() map[string]uint64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	debt := make(map[string]uint64, len(s.debt))
+	for name, count := range s.debt {
+		debt[name] = count
+	}
+	return debt
+}
+
+// due returns the sorted names of the ranges to compact now.
+func (s *CompactionScheduler) due() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	due := make(map[string]struct{})
+	if s.threshold > 0 {
+		for name, count := range s.debt {
+			if count >= s.threshold {
+				due[name] = struct{}{}
+			}
+		}
+	}
+	now := s.now()
+	for _, w := range s.windows {
+		opened, ok := w.opened(now)
+		if !ok {
+			continue
+		}
+		for _, name := range s.scheduled {
+			if s.compacted[name].Before(opened) {
+				due[name] = struct{}{}
+			}
+		}
+	}
+	names := make([]string, 0, len(due))
+	for name := range due {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Run compacts the ranges that are due. It returns early once [quit] is
+// closed, leaving the remaining ranges to the next run.
+func (s *CompactionScheduler) Run(quit <-chan struct{}) {
+	for _, name := range s.due() {
+		select {
+		case <-quit:
+			return
+		default:
+		}
+		s.compact(name)
+	}
+}
+
+// compact compacts the range [name], clearing the deletions recorded in it
+// before compaction started.
+func (s *CompactionScheduler) compact(name string) {
+	s.lock.Lock()
+	debt := s.debt[name]
+	s.lock.Unlock()
+
+	var (
+		prefix = compactionRanges[name]
+		start  = time.Now()
+	)
+	if err := s.db.Compact(prefix, prefixLimit(prefix)); err != nil {
+		compactionFailuresCounter.Inc(1)
+		log.Error("Failed to compact database range", "range", name, "err", err)
+		return
+	}
+	elapsed := time.Since(start)
+	compactionRunsCounter.Inc(1)
+	compactionTimer.Update(elapsed)
+
+	s.lock.Lock()
+	s.debt[name] -= debt
+	if s.debt[name] == 0 {
+		delete(s.debt, name)
+	}
+	s.compacted[name] = s.now()
+	s.lock.Unlock()
+	compactionDebtGauge.Dec(int64(debt))
+
+	log.Info("Compacted database range", "range", name, "deletions", debt, "elapsed", elapsed)
+}
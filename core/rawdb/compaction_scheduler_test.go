@@ -0,0 +1,96 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rawdb
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type recordingCompacter struct {
+	ranges [][2]string
+}
+
+func (c *recordingCompacter) Compact(start []byte, limit []byte) error {
+	c.ranges = append(c.ranges, [2]string{string(start), string(limit)})
+	return nil
+}
+
+func TestParseQuietWindow(t *testing.T) {
+	for _, s := range []string{"", "22:00", "22:00-22:00", "25:00-01:00", "aa:00-01:00"} {
+		if _, err := ParseQuietWindow(s); err == nil {
+			t.Errorf("expected error parsing %q", s)
+		}
+	}
+	w, err := ParseQuietWindow("22:30-04:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		at     time.Duration
+		opened time.Time
+		ok     bool
+	}{
+		{at: 21 * time.Hour},
+		{at: 22*time.Hour + 30*time.Minute, opened: day.Add(22*time.Hour + 30*time.Minute), ok: true},
+		{at: 3 * time.Hour, opened: day.Add(-time.Hour - 30*time.Minute), ok: true},
+		{at: 4 * time.Hour},
+	} {
+		opened, ok := w.opened(day.Add(tt.at))
+		if ok != tt.ok || (ok && !opened.Equal(tt.opened)) {
+			t.Errorf("at %s: got (%s, %t), want (%s, %t)", tt.at, opened, ok, tt.opened, tt.ok)
+		}
+	}
+}
+
+func TestCompactionScheduler(t *testing.T) {
+	var (
+		db  = &recordingCompacter{}
+		now = time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	)
+	w, err := ParseQuietWindow("02:00-04:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewCompactionScheduler(db, []QuietWindow{w}, []string{"receipts"}, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.now = func() time.Time { return now }
+
+	// Deletions below the threshold are left to the quiet window.
+	s.RecordDeletions("bodies", 99)
+	s.Run(nil)
+	if len(db.ranges) != 0 {
+		t.Fatalf("unexpected compactions %v", db.ranges)
+	}
+	s.RecordDeletions("bodies", 1)
+	s.Run(nil)
+	if want := [][2]string{{"b", "c"}}; !reflect.DeepEqual(db.ranges, want) {
+		t.Fatalf("got compactions %v, want %v", db.ranges, want)
+	}
+	if debt := s.Debt(); len(debt) != 0 {
+		t.Fatalf("unexpected debt %v", debt)
+	}
+
+	// Scheduled ranges are compacted once per quiet window.
+	db.ranges = nil
+	now = now.Add(15 * time.Hour)
+	s.Run(nil)
+	s.Run(nil)
+	if want := [][2]string{{"r", "s"}}; !reflect.DeepEqual(db.ranges, want) {
+		t.Fatalf("got compactions %v, want %v", db.ranges, want)
+	}
+	now = now.Add(24 * time.Hour)
+	s.Run(nil)
+	if len(db.ranges) != 2 {
+		t.Fatalf("expected a compaction in the next quiet window, got %v", db.ranges)
+	}
+
+	if _, err := NewCompactionScheduler(db, nil, []string{"unknown"}, 0); err == nil {
+		t.Fatal("expected error for unknown range")
+	}
+}
@@ -272,9 +272,47 @@ func (s *stat) Count() string {
 	return s.count.String()
 }
 
+// DatabaseStat is the size and number of entries of a category of data.
+type DatabaseStat struct {
+	Database string             `json:"database"`
+	Category string             `json:"category"`
+	Size     common.StorageSize `json:"size"`
+	Count    uint64             `json:"count"`
+}
+
+// DatabaseInspection is the result of [InspectDatabaseStats].
+type DatabaseInspection struct {
+	Stats       []DatabaseStat     `json:"stats"`
+	Unaccounted DatabaseStat       `json:"unaccounted"`
+	Total       common.StorageSize `json:"total"`
+}
+
 // InspectDatabase traverses the entire database and checks the size
 // of all different categories of data.
 func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
+	inspection, err := InspectDatabaseStats(db, keyPrefix, keyStart)
+	if err != nil {
+		return err
+	}
+	stats := make([][]string, 0, len(inspection.Stats))
+	for _, stat := range inspection.Stats {
+		stats = append(stats, []string{stat.Database, stat.Category, stat.Size.String(), counter(stat.Count).String()})
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Database", "Category", "Size", "Items"})
+	table.SetFooter([]string{"", "Total", inspection.Total.String(), " "})
+	table.AppendBulk(stats)
+	table.Render()
+
+	if unaccounted := inspection.Unaccounted; unaccounted.Size > 0 {
+		log.Error("Database contains unaccounted data", "size", unaccounted.Size, "count", unaccounted.Count)
+	}
+	return nil
+}
+
+// InspectDatabaseStats traverses the entire database and returns the size of
+// all different categories of data.
+func InspectDatabaseStats(db ethdb.Database, keyPrefix, keyStart []byte) (*DatabaseInspection, error) {
 	it := db.NewIterator(keyPrefix, keyStart)
 	defer it.Release()
 
@@ -287,6 +325,9 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		headers         stat
 		bodies          stat
 		receipts        stat
+		receiptCosts    stat
+		blobSidecars    stat
+		storageExpiry   stat
 		numHashPairings stat
 		hashNumPairings stat
 		legacyTries     stat
@@ -332,6 +373,14 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 			bodies.Add(size)
 		case bytes.HasPrefix(key, blockReceiptsPrefix) && len(key) == (len(blockReceiptsPrefix)+8+common.HashLength):
 			receipts.Add(size)
+		case bytes.HasPrefix(key, receiptCostsPrefix) && len(key) == (len(receiptCostsPrefix)+8+common.HashLength):
+			receiptCosts.Add(size)
+		case bytes.HasPrefix(key, blobSidecarsPrefix) && len(key) == (len(blobSidecarsPrefix)+8+common.HashLength):
+			blobSidecars.Add(size)
+		case bytes.HasPrefix(key, blockStorageAccessesPrefix) && len(key) == (len(blockStorageAccessesPrefix)+8+common.HashLength),
+			bytes.HasPrefix(key, slotAccessPrefix) && len(key) == (len(slotAccessPrefix)+common.AddressLength+common.HashLength+8),
+			bytes.HasPrefix(key, epochAccessPrefix) && len(key) == (len(epochAccessPrefix)+8+common.AddressLength+common.HashLength):
+			storageExpiry.Add(size)
 		case bytes.HasPrefix(key, headerPrefix) && bytes.HasSuffix(key, headerHashSuffix):
 			numHashPairings.Add(size)
 		case bytes.HasPrefix(key, headerNumberPrefix) && len(key) == (len(headerNumberPrefix)+common.HashLength):
@@ -356,6 +405,10 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 			preimages.Add(size)
 		case bytes.HasPrefix(key, configPrefix) && len(key) == (len(configPrefix)+common.HashLength):
 			metadata.Add(size)
+		case bytes.HasPrefix(key, upgradeConfigPrefix) && len(key) == (len(upgradeConfigPrefix)+common.HashLength):
+			metadata.Add(size)
+		case bytes.HasPrefix(key, configHistoryPrefix) && len(key) == (len(configHistoryPrefix)+common.HashLength+8):
+			metadata.Add(size)
 		case bytes.HasPrefix(key, bloomBitsPrefix) && len(key) == (len(bloomBitsPrefix)+10+common.HashLength):
 			bloomBits.Add(size)
 		case bytes.HasPrefix(key, BloomBitsIndexPrefix):
@@ -372,9 +425,10 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 			var accounted bool
 			for _, meta := range [][]byte{
 				databaseVersionKey, headHeaderKey, headBlockKey,
-				snapshotRootKey, snapshotBlockHashKey, snapshotGeneratorKey,
+				snapshotRootKey, snapshotBlockHashKey, snapshotGeneratorKey, snapshotJournalKey, snapshotStorageWipesKey,
 				uncleanShutdownKey, syncRootKey, txIndexTailKey,
 				persistentStateIDKey, trieJournalKey,
+				offlinePruningKey, populateMissingTriesKey, pruningDisabledKey, acceptorTipKey,
 			} {
 				if bytes.Equal(key, meta) {
 					metadata.Add(size)
@@ -392,42 +446,42 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 			logged = time.Now()
 		}
 	}
-	// Display the database statistic.
-	stats := [][]string{
-		{"Key-Value store", "Headers", headers.Size(), headers.Count()},
-		{"Key-Value store", "Bodies", bodies.Size(), bodies.Count()},
-		{"Key-Value store", "Receipt lists", receipts.Size(), receipts.Count()},
-		{"Key-Value store", "Block number->hash", numHashPairings.Size(), numHashPairings.Count()},
-		{"Key-Value store", "Block hash->number", hashNumPairings.Size(), hashNumPairings.Count()},
-		{"Key-Value store", "Transaction index", txLookups.Size(), txLookups.Count()},
-		{"Key-Value store", "Bloombit index", bloomBits.Size(), bloomBits.Count()},
-		{"Key-Value store", "Contract codes", codes.Size(), codes.Count()},
-		{"Key-Value store", "Hash trie nodes", legacyTries.Size(), legacyTries.Count()},
-		{"Key-Value store", "Path trie state lookups", stateLookups.Size(), stateLookups.Count()},
-		{"Key-Value store", "Path trie account nodes", accountTries.Size(), accountTries.Count()},
-		{"Key-Value store", "Path trie storage nodes", storageTries.Size(), storageTries.Count()},
-		{"Key-Value store", "Trie preimages", preimages.Size(), preimages.Count()},
-		{"Key-Value store", "Account snapshot", accountSnaps.Size(), accountSnaps.Count()},
-		{"Key-Value store", "Storage snapshot", storageSnaps.Size(), storageSnaps.Count()},
-		{"Key-Value store", "Clique snapshots", cliqueSnaps.Size(), cliqueSnaps.Count()},
-		{"Key-Value store", "Singleton metadata", metadata.Size(), metadata.Count()},
-		{"Light client", "CHT trie nodes", chtTrieNodes.Size(), chtTrieNodes.Count()},
-		{"Light client", "Bloom trie nodes", bloomTrieNodes.Size(), bloomTrieNodes.Count()},
-		{"State sync", "Trie segments", syncSegments.Size(), syncSegments.Count()},
-		{"State sync", "Storage tries to fetch", syncProgress.Size(), syncProgress.Count()},
-		{"State sync", "Code to fetch", codeToFetch.Size(), codeToFetch.Count()},
-		{"State sync", "Block numbers synced to", syncPerformed.Size(), syncPerformed.Count()},
+	if err := it.Error(); err != nil {
+		return nil, err
 	}
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Database", "Category", "Size", "Items"})
-	table.SetFooter([]string{"", "Total", total.String(), " "})
-	table.AppendBulk(stats)
-	table.Render()
-
-	if unaccounted.size > 0 {
-		log.Error("Database contains unaccounted data", "size", unaccounted.size, "count", unaccounted.count)
+	stats := []DatabaseStat{
+		{"Key-Value store", "Headers", headers.size, uint64(headers.count)},
+		{"Key-Value store", "Bodies", bodies.size, uint64(bodies.count)},
+		{"Key-Value store", "Receipt lists", receipts.size, uint64(receipts.count)},
+		{"Key-Value store", "Receipt costs", receiptCosts.size, uint64(receiptCosts.count)},
+		{"Key-Value store", "Blob sidecars", blobSidecars.size, uint64(blobSidecars.count)},
+		{"Key-Value store", "Storage expiry index", storageExpiry.size, uint64(storageExpiry.count)},
+		{"Key-Value store", "Block number->hash", numHashPairings.size, uint64(numHashPairings.count)},
+		{"Key-Value store", "Block hash->number", hashNumPairings.size, uint64(hashNumPairings.count)},
+		{"Key-Value store", "Transaction index", txLookups.size, uint64(txLookups.count)},
+		{"Key-Value store", "Bloombit index", bloomBits.size, uint64(bloomBits.count)},
+		{"Key-Value store", "Contract codes", codes.size, uint64(codes.count)},
+		{"Key-Value store", "Hash trie nodes", legacyTries.size, uint64(legacyTries.count)},
+		{"Key-Value store", "Path trie state lookups", stateLookups.size, uint64(stateLookups.count)},
+		{"Key-Value store", "Path trie account nodes", accountTries.size, uint64(accountTries.count)},
+		{"Key-Value store", "Path trie storage nodes", storageTries.size, uint64(storageTries.count)},
+		{"Key-Value store", "Trie preimages", preimages.size, uint64(preimages.count)},
+		{"Key-Value store", "Account snapshot", accountSnaps.size, uint64(accountSnaps.count)},
+		{"Key-Value store", "Storage snapshot", storageSnaps.size, uint64(storageSnaps.count)},
+		{"Key-Value store", "Clique snapshots", cliqueSnaps.size, uint64(cliqueSnaps.count)},
+		{"Key-Value store", "Singleton metadata", metadata.size, uint64(metadata.count)},
+		{"Light client", "CHT trie nodes", chtTrieNodes.size, uint64(chtTrieNodes.count)},
+		{"Light client", "Bloom trie nodes", bloomTrieNodes.size, uint64(bloomTrieNodes.count)},
+		{"State sync", "Trie segments", syncSegments.size, uint64(syncSegments.count)},
+		{"State sync", "Storage tries to fetch", syncProgress.size, uint64(syncProgress.count)},
+		{"State sync", "Code to fetch", codeToFetch.size, uint64(codeToFetch.count)},
+		{"State sync", "Block numbers synced to", syncPerformed.size, uint64(syncPerformed.count)},
 	}
-	return nil
+	return &DatabaseInspection{
+		Stats:       stats,
+		Unaccounted: DatabaseStat{Category: "Unaccounted", Size: unaccounted.size, Count: uint64(unaccounted.count)},
+		Total:       total,
+	}, nil
 }
 
 // ClearPrefix removes all keys in db that begin with prefix and match an
@@ -60,6 +60,13 @@ var (
 	// snapshotGeneratorKey tracks the snapshot generation marker across restarts.
 	snapshotGeneratorKey = []byte("SnapshotGenerator")
 
+	// snapshotJournalKey tracks the in-memory diff layers across restarts.
+	snapshotJournalKey = []byte("SnapshotJournal")
+
+	// snapshotStorageWipesKey tracks the destructed accounts whose snapshot
+	// storage is not fully deleted yet.
+	snapshotStorageWipesKey = []byte("SnapshotStorageWipes")
+
 	// trieJournalKey tracks the in-memory trie node layers across restarts.
 	trieJournalKey = []byte("TrieJournal")
 
@@ -82,13 +89,41 @@ var (
 	// acceptorTipKey tracks the tip of the last accepted block that has been fully processed.
 	acceptorTipKey = []byte("AcceptorTipKey")
 
+	// acceptedConsumerOffsetPrefix + consumer name -> height of the last accepted block processed by the consumer
+	acceptedConsumerOffsetPrefix = []byte("AcceptedConsumerOffset")
+
+	// retentionTailPrefix + data class -> first height whose data of the class is retained
+	retentionTailPrefix = []byte("RetentionTail")
+
+	// traceIndexTailKey tracks the first block whose traces have been indexed.
+	traceIndexTailKey = []byte("TraceIndexTail")
+
+	// commitJournalPrefix + num (uint64 big endian) -> hash and state root of an accepted block whose state has not been committed to disk
+	commitJournalPrefix = []byte("CommitJournal")
+
+	// receiptCostsPrefix + num (uint64 big endian) + hash -> block receipt cost breakdowns
+	receiptCostsPrefix = []byte("ReceiptCosts")
+
 	// Data item prefixes (use single byte to avoid mixing data types, avoid `i`, used for indexes).
 	headerPrefix       = []byte("h") // headerPrefix + num (uint64 big endian) + hash -> header
 	headerHashSuffix   = []byte("n") // headerPrefix + num (uint64 big endian) + headerHashSuffix -> hash
 	headerNumberPrefix = []byte("H") // headerNumberPrefix + hash -> num (uint64 big endian)
 
-	blockBodyPrefix     = []byte("b") // blockBodyPrefix + num (uint64 big endian) + hash -> block body
-	blockReceiptsPrefix = []byte("r") // blockReceiptsPrefix + num (uint64 big endian) + hash -> block receipts
+	blockBodyPrefix     = []byte("b")  // blockBodyPrefix + num (uint64 big endian) + hash -> block body
+	blockReceiptsPrefix = []byte("r")  // blockReceiptsPrefix + num (uint64 big endian) + hash -> block receipts
+	blockTracesPrefix   = []byte("bt") // blockTracesPrefix + num (uint64 big endian) -> flat call traces of the accepted block
+	traceAddressPrefix  = []byte("ta") // traceAddressPrefix + address + num (uint64 big endian) -> empty value for each block tracing the address
+	blobSidecarsPrefix  = []byte("bs") // blobSidecarsPrefix + num (uint64 big endian) + hash -> blob sidecars of the block
+
+	historicalStatePrefix = []byte("hs") // historicalStatePrefix + id (uint64 big endian) + key -> trie node or code of a cached historical state
+
+	blockAcceptancePrefix = []byte("ba") // blockAcceptancePrefix + num (uint64 big endian) + hash -> acceptance record of the block
+
+	durableFilterPrefix = []byte("df") // durableFilterPrefix + name -> criteria and cursor of the durable log filter
+
+	blockStorageAccessesPrefix = []byte("eb") // blockStorageAccessesPrefix + num (uint64 big endian) + hash -> storage slots accessed by the block
+	slotAccessPrefix           = []byte("ea") // slotAccessPrefix + address + slot + epoch (uint64 big endian) -> empty value for each epoch accessing the slot
+	epochAccessPrefix          = []byte("ee") // epochAccessPrefix + epoch (uint64 big endian) + address + slot -> empty value for each slot accessed in the epoch
 
 	txLookupPrefix        = []byte("l") // txLookupPrefix + hash -> transaction/receipt lookup metadata
 	bloomBitsPrefix       = []byte("B") // bloomBitsPrefix + bit (uint16 big endian) + section (uint64 big endian) + hash -> bloom bits
@@ -104,6 +139,9 @@ var (
 	PreimagePrefix = []byte("secure-key-")      // PreimagePrefix + hash -> preimage
 	configPrefix   = []byte("ethereum-config-") // config prefix for the db
 
+	upgradeConfigPrefix = []byte("upgrade-config-")       // upgradeConfigPrefix + hash -> upgrades of the chain config
+	configHistoryPrefix = []byte("chain-config-history-") // configHistoryPrefix + hash + time (uint64 big endian) -> chain config replaced at the time
+
 	// BloomBitsIndexPrefix is the data table of a chain indexer to track its progress
 	BloomBitsIndexPrefix = []byte("iB")
 
@@ -171,6 +209,61 @@ func blockReceiptsKey(number uint64, hash common.Hash) []byte {
 	return append(append(blockReceiptsPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
 }
 
+// receiptCostsKey = receiptCostsPrefix + num (uint64 big endian) + hash
+func receiptCostsKey(number uint64, hash common.Hash) []byte {
+	return append(append(receiptCostsPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
+}
+
+// blockAcceptanceKey = blockAcceptancePrefix + num (uint64 big endian) + hash
+func blockAcceptanceKey(number uint64, hash common.Hash) []byte {
+	return append(append(blockAcceptancePrefix, encodeBlockNumber(number)...), hash.Bytes()...)
+}
+
+// blockTracesKey = blockTracesPrefix + num (uint64 big endian)
+func blockTracesKey(number uint64) []byte {
+	return append(blockTracesPrefix, encodeBlockNumber(number)...)
+}
+
+// historicalStateNamespace = historicalStatePrefix + id (uint64 big endian)
+func historicalStateNamespace(id uint64) []byte {
+	return append(common.CopyBytes(historicalStatePrefix), encodeBlockNumber(id)...)
+}
+
+// commitJournalKey = commitJournalPrefix + num (uint64 big endian)
+func commitJournalKey(number uint64) []byte {
+	return append(commitJournalPrefix, encodeBlockNumber(number)...)
+}
+
+// traceAddressKey = traceAddressPrefix + address + num (uint64 big endian)
+func traceAddressKey(addr common.Address, number uint64) []byte {
+	return append(append(traceAddressPrefix, addr.Bytes()...), encodeBlockNumber(number)...)
+}
+
+// durableFilterKey = durableFilterPrefix + name
+func durableFilterKey(name string) []byte {
+	return append(common.CopyBytes(durableFilterPrefix), name...)
+}
+
+// blobSidecarsKey = blobSidecarsPrefix + num (uint64 big endian) + hash
+func blobSidecarsKey(number uint64, hash common.Hash) []byte {
+	return append(append(blobSidecarsPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
+}
+
+// blockStorageAccessesKey = blockStorageAccessesPrefix + num (uint64 big endian) + hash
+func blockStorageAccessesKey(number uint64, hash common.Hash) []byte {
+	return append(append(blockStorageAccessesPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
+}
+
+// slotAccessKey = slotAccessPrefix + address + slot + epoch (uint64 big endian)
+func slotAccessKey(addr common.Address, slot common.Hash, epoch uint64) []byte {
+	return append(append(append(slotAccessPrefix, addr.Bytes()...), slot.Bytes()...), encodeBlockNumber(epoch)...)
+}
+
+// epochAccessKey = epochAccessPrefix + epoch (uint64 big endian) + address + slot
+func epochAccessKey(epoch uint64, addr common.Address, slot common.Hash) []byte {
+	return append(append(append(epochAccessPrefix, encodeBlockNumber(epoch)...), addr.Bytes()...), slot.Bytes()...)
+}
+
 // txLookupKey = txLookupPrefix + hash
 func txLookupKey(hash common.Hash) []byte {
 	return append(txLookupPrefix, hash.Bytes()...)
@@ -229,6 +322,16 @@ func configKey(hash common.Hash) []byte {
 	return append(configPrefix, hash.Bytes()...)
 }
 
+// upgradeConfigKey = upgradeConfigPrefix + hash
+func upgradeConfigKey(hash common.Hash) []byte {
+	return append(common.CopyBytes(upgradeConfigPrefix), hash.Bytes()...)
+}
+
+// configHistoryKey = configHistoryPrefix + hash + time (uint64 big endian)
+func configHistoryKey(hash common.Hash, time uint64) []byte {
+	return append(append(common.CopyBytes(configHistoryPrefix), hash.Bytes()...), encodeBlockNumber(time)...)
+}
+
 // stateIDKey = stateIDPrefix + root (32 bytes)
 func stateIDKey(root common.Hash) []byte {
 	return append(stateIDPrefix, root.Bytes()...)
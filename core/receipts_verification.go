@@ -0,0 +1,104 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/shubhamdubey02/coreth/consensus"
+	"github.com/shubhamdubey02/coreth/consensus/misc/eip4844"
+	"github.com/shubhamdubey02/coreth/core/rawdb"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/trie"
+)
+
+var errReceiptsVerificationDisabled = errors.New("receipts verification is not enabled")
+
+// ValidateReceipts validates [receipts] downloaded for [block] against the
+// roots of its header, in place of the receipts generated by executing it.
+func ValidateReceipts(block *types.Block, receipts types.Receipts) error {
+	if len(receipts) != len(block.Transactions()) {
+		return fmt.Errorf("invalid number of receipts (transactions: %d receipts: %d)", len(block.Transactions()), len(receipts))
+	}
+	header := block.Header()
+	var gasUsed uint64
+	if len(receipts) > 0 {
+		gasUsed = receipts[len(receipts)-1].CumulativeGasUsed
+	}
+	if gasUsed != header.GasUsed {
+		return fmt.Errorf("invalid gas used (remote: %d receipts: %d)", header.GasUsed, gasUsed)
+	}
+	if bloom := types.CreateBloom(receipts); bloom != header.Bloom {
+		return fmt.Errorf("invalid bloom (remote: %x  receipts: %x)", header.Bloom, bloom)
+	}
+	if hash := types.DeriveSha(receipts, trie.NewStackTrie(nil)); hash != header.ReceiptHash {
+		return fmt.Errorf("invalid receipt root hash (remote: %x receipts: %x)", header.ReceiptHash, hash)
+	}
+	return nil
+}
+
+// InsertBlockWithReceipts inserts [block] without executing it, storing the
+// [receipts] downloaded for it once they are verified against its header.
+// It is only supported by chains configured with
+// [CacheConfig.ReceiptsVerification], which keep no state and trust the
+// state roots of the headers they verify.
+func (bc *BlockChain) InsertBlockWithReceipts(block *types.Block, receipts types.Receipts) error {
+	if !bc.cacheConfig.ReceiptsVerification {
+		return errReceiptsVerificationDisabled
+	}
+	bc.blockProcFeed.Send(true)
+	defer bc.blockProcFeed.Send(false)
+
+	bc.chainmu.Lock()
+	defer bc.chainmu.Unlock()
+
+	header := block.Header()
+	if !bc.HasBlock(block.ParentHash(), block.NumberU64()-1) {
+		return consensus.ErrUnknownAncestor
+	}
+	if err := bc.engine.VerifyHeader(bc, header); err != nil {
+		return err
+	}
+	if hash := types.CalcUncleHash(block.Uncles()); hash != header.UncleHash {
+		return fmt.Errorf("uncle root hash mismatch (header value %x, calculated %x)", header.UncleHash, hash)
+	}
+	if hash := types.DeriveSha(block.Transactions(), trie.NewStackTrie(nil)); hash != header.TxHash {
+		return fmt.Errorf("transaction root hash mismatch (header value %x, calculated %x)", header.TxHash, hash)
+	}
+	if err := ValidateReceipts(block, receipts); err != nil {
+		bc.reportBlock(block, receipts, err)
+		return err
+	}
+
+	var blobGasPrice *big.Int
+	if excessBlobGas := block.ExcessBlobGas(); excessBlobGas != nil {
+		blobGasPrice = eip4844.CalcBlobFee(*excessBlobGas)
+	}
+	if err := receipts.DeriveFields(bc.chainConfig, block.Hash(), block.NumberU64(), block.Time(), block.BaseFee(), blobGasPrice, block.Transactions()); err != nil {
+		return fmt.Errorf("failed to derive receipt fields: %w", err)
+	}
+
+	// Write the block and its receipts atomically, as writeBlockWithState
+	// does for executed blocks.
+	batch := bc.db.NewBatch()
+	rawdb.WriteBlock(batch, block)
+	rawdb.WriteReceipts(batch, block.Hash(), block.NumberU64(), receipts)
+	if err := batch.Write(); err != nil {
+		return fmt.Errorf("failed to write block into disk: %w", err)
+	}
+
+	var logs []*types.Log
+	for _, receipt := range receipts {
+		logs = append(logs, receipt.Logs...)
+	}
+	if bc.newTip(block) {
+		bc.writeCanonicalBlockWithLogs(block, logs)
+	} else {
+		bc.chainSideFeed.Send(ChainSideEvent{Block: block})
+	}
+	blockInsertCount.Inc(1)
+	return nil
+}
@@ -0,0 +1,81 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/shubhamdubey02/coreth/core/rawdb"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInsertBlockWithReceipts(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		gspec  = &Genesis{
+			Config: &params.ChainConfig{HomesteadBlock: new(big.Int)},
+			Alloc:  GenesisAlloc{addr: {Balance: big.NewInt(1000000)}},
+		}
+		signer = types.HomesteadSigner{}
+	)
+	config := *pruningConfig
+	config.SnapshotLimit = 0
+	config.ReceiptsVerification = true
+	chainDB := rawdb.NewMemoryDatabase()
+	blockchain, err := createBlockChain(chainDB, &config, gspec, common.Hash{})
+	require.NoError(err)
+	defer blockchain.Stop()
+
+	_, blocks, receipts, err := GenerateChainWithGenesis(gspec, blockchain.engine, 3, 10, func(i int, gen *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr), common.Address{0x01}, big.NewInt(10000), params.TxGas, nil, nil), signer, key)
+		gen.AddTx(tx)
+	})
+	require.NoError(err)
+
+	// Blocks are only inserted on top of known parents.
+	require.Error(blockchain.InsertBlockWithReceipts(blocks[1], receipts[1]))
+
+	// Receipts not matching the header are rejected.
+	tampered := types.Receipts{{
+		Status:            types.ReceiptStatusFailed,
+		CumulativeGasUsed: receipts[0][0].CumulativeGasUsed,
+		Logs:              []*types.Log{},
+	}}
+	require.ErrorContains(blockchain.InsertBlockWithReceipts(blocks[0], tampered), "invalid receipt root hash")
+	require.ErrorContains(blockchain.InsertBlockWithReceipts(blocks[0], nil), "invalid number of receipts")
+
+	for i, block := range blocks {
+		require.NoError(blockchain.InsertBlockWithReceipts(block, receipts[i]))
+		require.NoError(blockchain.Accept(block))
+	}
+	blockchain.DrainAcceptorQueue()
+
+	last := blocks[len(blocks)-1]
+	require.Equal(last.Hash(), blockchain.LastAcceptedBlock().Hash())
+	require.False(blockchain.HasState(last.Root()))
+	stored := blockchain.GetReceiptsByHash(last.Hash())
+	require.Len(stored, 1)
+	require.Equal(receipts[len(blocks)-1][0].TxHash, stored[0].TxHash)
+
+	// The chain restarts without the state of its last accepted block.
+	blockchain.Stop()
+	blockchain, err = createBlockChain(chainDB, &config, gspec, last.Hash())
+	require.NoError(err)
+	defer blockchain.Stop()
+	require.Equal(last.Hash(), blockchain.LastAcceptedBlock().Hash())
+
+	// Chains executing blocks do not insert blocks with receipts.
+	executing, err := createBlockChain(rawdb.NewMemoryDatabase(), pruningConfig, gspec, common.Hash{})
+	require.NoError(err)
+	defer executing.Stop()
+	require.ErrorIs(executing.InsertBlockWithReceipts(blocks[0], receipts[0]), errReceiptsVerificationDisabled)
+}
@@ -35,6 +35,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/shubhamdubey02/coreth/core/state/snapshot"
 	"github.com/shubhamdubey02/coreth/core/types"
 	"github.com/shubhamdubey02/coreth/trie"
 )
@@ -232,6 +233,117 @@ func (s *StateDB) RawDump(opts *DumpConfig) Dump {
 	return *dump
 }
 
+// DumpSnapshotToCollector is like DumpToCollector, but iterates the flat
+// snapshot in [snaps] rather than the trie, which is much faster for large
+// states. An error is returned if there is no complete snapshot for the state
+// root or if it becomes stale during iteration, so the accounts passed to [c]
+// are never mixed from different roots. [c] may hold a partial result when an
+// error is returned.
+func (s *StateDB) DumpSnapshotToCollector(snaps *snapshot.Tree, c DumpCollector, conf *DumpConfig) (nextKey []byte, err error) {
+	// Sanitize the input to allow nil configs
+	if conf == nil {
+		conf = new(DumpConfig)
+	}
+	root := s.originalRoot
+	seek := common.BytesToHash(common.RightPadBytes(conf.Start, common.HashLength))
+	it, err := snaps.AccountIterator(root, seek, false)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Release()
+
+	var (
+		missingPreimages int
+		accounts         uint64
+	)
+	c.OnRoot(root)
+	for it.Next() {
+		data, err := types.FullAccount(it.Account())
+		if err != nil {
+			return nil, err
+		}
+		var (
+			account = DumpAccount{
+				Balance:     data.Balance.String(),
+				Nonce:       data.Nonce,
+				Root:        data.Root[:],
+				CodeHash:    data.CodeHash,
+				IsMultiCoin: data.IsMultiCoin,
+				AddressHash: it.Hash().Bytes(),
+			}
+			address   *common.Address
+			addr      common.Address
+			addrBytes = s.trie.GetKey(it.Hash().Bytes())
+		)
+		if addrBytes == nil {
+			missingPreimages++
+			if conf.OnlyWithAddresses {
+				continue
+			}
+		} else {
+			addr = common.BytesToAddress(addrBytes)
+			address = &addr
+			account.Address = address
+		}
+		if !conf.SkipCode {
+			account.Code = newObject(s, addr, data).Code()
+		}
+		if !conf.SkipStorage {
+			if account.Storage, err = s.dumpSnapshotStorage(snaps, root, it.Hash()); err != nil {
+				return nil, err
+			}
+		}
+		c.OnAccount(address, account)
+		accounts++
+		if conf.Max > 0 && accounts >= conf.Max {
+			if it.Next() {
+				nextKey = it.Hash().Bytes()
+			}
+			break
+		}
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	if missingPreimages > 0 {
+		log.Warn("Dump incomplete due to missing preimages", "missing", missingPreimages)
+	}
+	return nextKey, nil
+}
+
+// dumpSnapshotStorage returns the storage of [accountHash] in the snapshot of [root].
+func (s *StateDB) dumpSnapshotStorage(snaps *snapshot.Tree, root, accountHash common.Hash) (map[common.Hash]string, error) {
+	it, err := snaps.StorageIterator(root, accountHash, common.Hash{}, false)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Release()
+
+	storage := make(map[common.Hash]string)
+	for it.Next() {
+		_, content, _, err := rlp.Split(it.Slot())
+		if err != nil {
+			return nil, err
+		}
+		storage[common.BytesToHash(s.trie.GetKey(it.Hash().Bytes()))] = common.Bytes2Hex(content)
+	}
+	return storage, it.Error()
+}
+
+// RawDumpSnapshot is like RawDump, but iterates the flat snapshot in [snaps].
+// See DumpSnapshotToCollector.
+func (s *StateDB) RawDumpSnapshot(snaps *snapshot.Tree, opts *DumpConfig) (Dump, error) {
+	dump := &Dump{
+		Accounts: make(map[string]DumpAccount),
+	}
+	next, err := s.DumpSnapshotToCollector(snaps, dump, opts)
+	if err != nil {
+		return Dump{}, err
+	}
+	dump.Next = next
+	return *dump, nil
+}
+
 // Dump returns a JSON string representing the entire state as a single json-object
 func (s *StateDB) Dump(opts *DumpConfig) []byte {
 	dump := s.RawDump(opts)
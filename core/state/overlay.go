@@ -0,0 +1,57 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var errOverlayCommit = errors.New("cannot commit an overlay state")
+
+// NewOverlay returns a StateDB that layers hypothetical changes over [base]
+// without modifying it, for what-if execution such as call simulation and gas
+// estimation.
+//
+// Accounts are copied from [base] the first time the overlay reads them, so
+// the cost of an overlay is proportional to the accounts it touches rather than
+// to the changes pending in [base], as with [StateDB.Copy]. Accounts that
+// [base] never loaded are read from the same database and snapshot as [base].
+// The destruction of an account in [base] is carried over with the account
+// when it is copied, so that the storage of an account recreated or overridden
+// in [base] is not read from the database. Accounts destructed or recreated in
+// the overlay are tracked by the overlay alone.
+// Logs, refunds and the access list of [base] are not carried over.
+//
+// [base] must not be modified or committed while the overlay is in use, and
+// the overlay itself cannot be committed.
+func NewOverlay(base *StateDB) (*StateDB, error) {
+	overlay, err := NewWithSnapshot(base.originalRoot, base.db, base.snap)
+	if err != nil {
+		return nil, err
+	}
+	overlay.base = base
+	return overlay, nil
+}
+
+// IsOverlay returns whether the state was created by [NewOverlay].
+func (s *StateDB) IsOverlay() bool {
+	return s.base != nil
+}
+
+// baseObject returns a copy of the live object for [addr] in the nearest base
+// of the overlay that has loaded it, or nil if none has. If that base
+// destructed [addr], the overlay records the destruction.
+func (s *StateDB) baseObject(addr common.Address) *stateObject {
+	for base := s.base; base != nil; base = base.base {
+		if obj := base.stateObjects[addr]; obj != nil {
+			if prev, destructed := base.stateObjectsDestruct[addr]; destructed {
+				s.stateObjectsDestruct[addr] = prev
+			}
+			return obj.deepCopy(s)
+		}
+	}
+	return nil
+}
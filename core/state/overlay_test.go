@@ -0,0 +1,168 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shubhamdubey02/coreth/core/rawdb"
+	"github.com/shubhamdubey02/coreth/core/types"
+)
+
+func TestOverlay(t *testing.T) {
+	var (
+		committed = common.Address{1}
+		pending   = common.Address{2}
+		destroyed = common.Address{3}
+		untouched = common.Address{4}
+		slot      = common.Hash{1}
+	)
+	db := NewDatabase(rawdb.NewMemoryDatabase())
+	genesis, _ := New(types.EmptyRootHash, db, nil)
+	for _, addr := range []common.Address{committed, pending, destroyed, untouched} {
+		genesis.SetBalance(addr, big.NewInt(1))
+		genesis.SetState(addr, slot, common.Hash{1})
+	}
+	root, err := genesis.Commit(0, false, false)
+	if err != nil {
+		t.Fatalf("failed to commit genesis: %v", err)
+	}
+
+	// The base has uncommitted changes, as a state with overrides applied would.
+	base, _ := New(root, db, nil)
+	base.GetBalance(committed)
+	base.SetBalance(pending, big.NewInt(2))
+	base.SetState(pending, slot, common.Hash{2})
+	base.SelfDestruct(destroyed)
+	base.Finalise(true)
+
+	overlay, err := NewOverlay(base)
+	if err != nil {
+		t.Fatalf("failed to create overlay: %v", err)
+	}
+	if !overlay.IsOverlay() || base.IsOverlay() {
+		t.Fatal("unexpected overlay flags")
+	}
+	if have := overlay.GetBalance(pending); have.Cmp(big.NewInt(2)) != 0 {
+		t.Fatalf("pending balance mismatch: have %v, want 2", have)
+	}
+	if have := overlay.GetState(pending, slot); have != (common.Hash{2}) {
+		t.Fatalf("pending storage mismatch: have %x, want %x", have, common.Hash{2})
+	}
+	if have := overlay.GetCommittedStateAP1(pending, slot); have != (common.Hash{2}) {
+		t.Fatalf("pending committed storage mismatch: have %x, want %x", have, common.Hash{2})
+	}
+	if overlay.Exist(destroyed) {
+		t.Fatal("account destroyed in the base exists in the overlay")
+	}
+	if have := overlay.GetState(untouched, slot); have != (common.Hash{1}) {
+		t.Fatalf("committed storage mismatch: have %x, want %x", have, common.Hash{1})
+	}
+
+	// Hypothetical changes are visible in the overlay and its copies only.
+	overlay.SetBalance(committed, big.NewInt(10))
+	overlay.SetCode(pending, []byte{0xfe})
+	overlay.SetState(untouched, slot, common.Hash{3})
+	overlay.SetBalance(destroyed, big.NewInt(5))
+	overlay.Finalise(true)
+	cpy := overlay.Copy()
+	for _, state := range []*StateDB{overlay, cpy} {
+		if have := state.GetBalance(committed); have.Cmp(big.NewInt(10)) != 0 {
+			t.Fatalf("overlay balance mismatch: have %v, want 10", have)
+		}
+		if have := state.GetBalance(pending); have.Cmp(big.NewInt(2)) != 0 {
+			t.Fatalf("overlay pending balance mismatch: have %v, want 2", have)
+		}
+		if have := state.GetState(untouched, slot); have != (common.Hash{3}) {
+			t.Fatalf("overlay storage mismatch: have %x, want %x", have, common.Hash{3})
+		}
+	}
+	if have := base.GetBalance(committed); have.Cmp(big.NewInt(1)) != 0 {
+		t.Fatalf("base balance modified: have %v, want 1", have)
+	}
+	if have := base.GetCode(pending); len(have) != 0 {
+		t.Fatalf("base code modified: have %x", have)
+	}
+	if have := base.GetState(untouched, slot); have != (common.Hash{1}) {
+		t.Fatalf("base storage modified: have %x, want %x", have, common.Hash{1})
+	}
+	if base.Exist(destroyed) {
+		t.Fatal("base account resurrected by overlay")
+	}
+
+	if _, err := overlay.Commit(1, false, false); !errors.Is(err, errOverlayCommit) {
+		t.Fatalf("overlay commit error mismatch: have %v, want %v", err, errOverlayCommit)
+	}
+	if _, err := cpy.Commit(1, false, false); !errors.Is(err, errOverlayCommit) {
+		t.Fatalf("overlay copy commit error mismatch: have %v, want %v", err, errOverlayCommit)
+	}
+}
+
+// Tests that the storage of accounts destructed and recreated in the base, or
+// whose storage is overridden in the base, is not read from the database by
+// the overlay.
+func TestOverlayDestructRecreate(t *testing.T) {
+	var (
+		recreated  = common.Address{1}
+		overridden = common.Address{2}
+		slot       = common.Hash{1}
+		other      = common.Hash{2}
+	)
+	db := NewDatabase(rawdb.NewMemoryDatabase())
+	genesis, _ := New(types.EmptyRootHash, db, nil)
+	for _, addr := range []common.Address{recreated, overridden} {
+		genesis.SetBalance(addr, big.NewInt(1))
+		genesis.SetState(addr, slot, common.Hash{1})
+	}
+	root, err := genesis.Commit(0, false, false)
+	if err != nil {
+		t.Fatalf("failed to commit genesis: %v", err)
+	}
+
+	base, _ := New(root, db, nil)
+	base.SelfDestruct(recreated)
+	base.Finalise(true)
+	base.CreateAccount(recreated)
+	base.SetBalance(recreated, big.NewInt(1))
+	base.SetState(recreated, other, common.Hash{2})
+	base.SetStorage(overridden, map[common.Hash]common.Hash{other: {2}})
+	base.Finalise(true)
+
+	overlay, err := NewOverlay(base)
+	if err != nil {
+		t.Fatalf("failed to create overlay: %v", err)
+	}
+	for _, addr := range []common.Address{recreated, overridden} {
+		if have := overlay.GetState(addr, slot); have != (common.Hash{}) {
+			t.Fatalf("%x: storage of previous incarnation visible: have %x", addr, have)
+		}
+		if have := overlay.GetCommittedStateAP1(addr, slot); have != (common.Hash{}) {
+			t.Fatalf("%x: committed storage of previous incarnation visible: have %x", addr, have)
+		}
+		if have := overlay.GetState(addr, other); have != (common.Hash{2}) {
+			t.Fatalf("%x: storage mismatch: have %x, want %x", addr, have, common.Hash{2})
+		}
+	}
+
+	// Recreating an account in the overlay keeps its new storage, and leaves
+	// the base untouched.
+	overlay.SelfDestruct(overridden)
+	overlay.Finalise(true)
+	overlay.CreateAccount(overridden)
+	overlay.SetBalance(overridden, big.NewInt(1))
+	overlay.SetState(overridden, slot, common.Hash{3})
+	overlay.Finalise(true)
+	if have := overlay.GetState(overridden, slot); have != (common.Hash{3}) {
+		t.Fatalf("recreated storage mismatch: have %x, want %x", have, common.Hash{3})
+	}
+	if have := overlay.GetState(overridden, other); have != (common.Hash{}) {
+		t.Fatalf("storage of overlay-destructed account visible: have %x", have)
+	}
+	if have := base.GetState(overridden, other); have != (common.Hash{2}) {
+		t.Fatalf("base storage modified: have %x, want %x", have, common.Hash{2})
+	}
+}
@@ -56,6 +56,8 @@ type diskLayer struct {
 
 	genStats *generatorStats // Stats for snapshot generation (generation aborted/finished if non-nil)
 
+	wipes map[common.Hash]struct{} // Destructed accounts whose storage is not fully deleted yet
+
 	created      time.Time // Time at which disk layer was created
 	logged       time.Time // Time at which last logged generation progress
 	abortStarted time.Time // Time as which disk layer started to be aborted
@@ -173,6 +175,12 @@ func (dl *diskLayer) Storage(accountHash, storageHash common.Hash) ([]byte, erro
 	// If we're in the disk layer, all diff layers missed
 	snapshotDirtyStorageMissMeter.Mark(1)
 
+	// The storage of destructed accounts is empty, even if it is not fully
+	// deleted yet.
+	if _, ok := dl.wipes[accountHash]; ok {
+		return nil, nil
+	}
+
 	// Try to retrieve the storage slot from the memory cache
 	if blob, found := dl.cache.HasGet(nil, key); found {
 		snapshotCleanStorageHitMeter.Mark(1)
@@ -198,3 +206,17 @@ func (dl *diskLayer) Storage(accountHash, storageHash common.Hash) ([]byte, erro
 func (dl *diskLayer) Update(blockHash, blockRoot common.Hash, destructs map[common.Hash]struct{}, accounts map[common.Hash][]byte, storage map[common.Hash]map[common.Hash][]byte) *diffLayer {
 	return newDiffLayer(dl, blockHash, blockRoot, destructs, accounts, storage)
 }
+
+// loadStorageWipes returns the destructed accounts whose snapshot storage in
+// [db] is not fully deleted yet.
+func loadStorageWipes(db ethdb.KeyValueReader) map[common.Hash]struct{} {
+	accounts := rawdb.ReadSnapshotStorageWipes(db)
+	if len(accounts) == 0 {
+		return nil
+	}
+	wipes := make(map[common.Hash]struct{}, len(accounts))
+	for _, hash := range accounts {
+		wipes[hash] = struct{}{}
+	}
+	return wipes
+}
@@ -565,3 +565,93 @@ func TestDiskSeek(t *testing.T) {
 		}
 	}
 }
+
+// Tests that the storage of destructed accounts is wiped across flattens when
+// it is too large, and hidden from the disk layer until it is fully deleted.
+func TestDiskStorageWipes(t *testing.T) {
+	db := memorydb.New()
+
+	var (
+		con           = common.Hash{0x1}
+		conSlot       = common.Hash{0x10}
+		baseRoot      = randomHash()
+		baseBlockHash = randomHash()
+		nukeRoot      = randomHash()
+		nukeBlockHash = randomHash()
+		newRoot       = randomHash()
+		newBlockHash  = randomHash()
+		slots         = storageWipeLimit + 10
+	)
+	rawdb.WriteAccountSnapshot(db, con, con[:])
+	for i := 0; i < slots; i++ {
+		slot := common.Hash{byte(i >> 8), byte(i)}
+		rawdb.WriteStorageSnapshot(db, con, slot, slot[:])
+	}
+	rawdb.WriteSnapshotBlockHash(db, baseBlockHash)
+	rawdb.WriteSnapshotRoot(db, baseRoot)
+
+	snaps := NewTestTree(db, baseBlockHash, baseRoot)
+	snaps.verified = true // Bypass validation of junk data
+
+	// countSlots returns the number of slots of [con] left on disk.
+	countSlots := func() int {
+		it := rawdb.IterateStorageSnapshots(db, con)
+		defer it.Release()
+
+		var n int
+		for it.Next() {
+			n++
+		}
+		return n
+	}
+
+	// Destruct the contract, only part of its storage is deleted
+	if err := snaps.Update(nukeBlockHash, nukeRoot, baseBlockHash, map[common.Hash]struct{}{con: {}}, nil, nil); err != nil {
+		t.Fatalf("failed to update snapshot tree: %v", err)
+	}
+	if err := snaps.Flatten(nukeBlockHash); err != nil {
+		t.Fatalf("failed to flatten snapshot tree: %v", err)
+	}
+	if n := countSlots(); n != slots-storageWipeLimit {
+		t.Fatalf("slots left mismatch: have %d, want %d", n, slots-storageWipeLimit)
+	}
+	if wipes := rawdb.ReadSnapshotStorageWipes(db); len(wipes) != 1 || wipes[0] != con {
+		t.Fatalf("storage wipes mismatch: have %x, want [%x]", wipes, con)
+	}
+	base := snaps.Snapshot(nukeRoot)
+	for i := 0; i < slots; i++ {
+		if blob, err := base.Storage(con, common.Hash{byte(i >> 8), byte(i)}); err != nil || blob != nil {
+			t.Fatalf("wiped slot %d: have %x, %v, want nil", i, blob, err)
+		}
+	}
+	it, _ := base.(*diskLayer).StorageIterator(con, common.Hash{})
+	if it.Next() {
+		t.Fatalf("wiped storage iterated")
+	}
+	it.Release()
+
+	// Resurrect the contract, the rest of its storage is deleted first
+	if err := snaps.Update(newBlockHash, newRoot, nukeBlockHash, nil, map[common.Hash][]byte{
+		con: con[:],
+	}, map[common.Hash]map[common.Hash][]byte{
+		con: {conSlot: conSlot[:]},
+	}); err != nil {
+		t.Fatalf("failed to update snapshot tree: %v", err)
+	}
+	if err := snaps.Flatten(newBlockHash); err != nil {
+		t.Fatalf("failed to flatten snapshot tree: %v", err)
+	}
+	if n := countSlots(); n != 1 {
+		t.Fatalf("slots left mismatch: have %d, want 1", n)
+	}
+	if wipes := rawdb.ReadSnapshotStorageWipes(db); len(wipes) != 0 {
+		t.Fatalf("storage wipes left: %x", wipes)
+	}
+	base = snaps.Snapshot(newRoot)
+	if blob, err := base.Storage(con, conSlot); err != nil || !bytes.Equal(blob, conSlot[:]) {
+		t.Fatalf("resurrected slot: have %x, %v, want %x", blob, err, conSlot[:])
+	}
+	if blob, err := base.Storage(con, common.Hash{}); err != nil || blob != nil {
+		t.Fatalf("wiped slot: have %x, %v, want nil", blob, err)
+	}
+}
@@ -347,9 +347,12 @@ type diskStorageIterator struct {
 
 // StorageIterator creates a storage iterator over a disk layer.
 // If the whole storage is destructed, then all entries in the disk
-// layer are deleted already. So the "destructed" flag returned here
-// is always false.
+// layer are deleted already, or hidden until they are. So the
+// "destructed" flag returned here is always false.
 func (dl *diskLayer) StorageIterator(account common.Hash, seek common.Hash) (StorageIterator, bool) {
+	if _, ok := dl.wipes[account]; ok {
+		return &diskStorageIterator{layer: dl, account: account}, false
+	}
 	pos := common.TrimRightZeroes(seek[:])
 
 	// create prefix to be rawdb.SnapshotStoragePrefix + account[:]
@@ -53,25 +53,67 @@ type journalGenerator struct {
 	Storage  uint64
 }
 
+// journalVersion is the version of the diff layer journal. A journal of any
+// other version is discarded.
+const journalVersion uint64 = 0
+
+// journalDiffs is the journal of the diff layers on top of the disk layer of
+// [BlockHash] and [Root], saved at shutdown.
+type journalDiffs struct {
+	Version   uint64
+	BlockHash common.Hash
+	Root      common.Hash
+	Layers    []journalDiff // Parents precede their children
+}
+
+// journalDiff is a diff layer entry of the journal.
+type journalDiff struct {
+	BlockHash  common.Hash
+	ParentHash common.Hash
+	Root       common.Hash
+	Destructs  []common.Hash
+	Accounts   []journalAccount
+	Storage    []journalStorage
+}
+
+// journalAccount is an account entry inside a journalled diff layer.
+type journalAccount struct {
+	Hash common.Hash
+	Blob []byte
+}
+
+// journalStorage is an account's storage map inside a journalled diff layer.
+type journalStorage struct {
+	Hash common.Hash
+	Keys []common.Hash
+	Vals [][]byte
+}
+
 // loadSnapshot loads a pre-existing state snapshot backed by a key-value
-// store. If loading the snapshot from disk is successful, this function also
-// returns a boolean indicating whether or not the snapshot is fully generated.
-func loadSnapshot(diskdb ethdb.KeyValueStore, triedb *trie.Database, cache int, blockHash, root common.Hash, noBuild bool) (snapshot, bool, error) {
+// store, along with the diff layers journalled on top of it at the last
+// shutdown. If loading the snapshot from disk is successful, this function
+// also returns a boolean indicating whether or not the snapshot is fully
+// generated.
+//
+// The disk layer may precede the last accepted block [blockHash] if the
+// journal holds the diff layers linking the two, in which case the returned
+// diff layers must be flattened before the snapshot is used. Only the diff
+// layers of [blockHash], its ancestors and its descendants are returned,
+// ordered such that parents precede their children.
+func loadSnapshot(diskdb ethdb.KeyValueStore, triedb *trie.Database, cache int, blockHash, root common.Hash, noBuild bool) (*diskLayer, []*diffLayer, bool, error) {
 	// Retrieve the block number and hash of the snapshot, failing if no snapshot
 	// is present in the database (or crashed mid-update).
 	baseBlockHash := rawdb.ReadSnapshotBlockHash(diskdb)
 	if baseBlockHash == (common.Hash{}) {
-		return nil, false, errors.New("missing or corrupted snapshot, no snapshot block hash")
-	}
-	if baseBlockHash != blockHash {
-		return nil, false, fmt.Errorf("block hash stored on disk (%#x) does not match last accepted (%#x)", baseBlockHash, blockHash)
+		return nil, nil, false, errors.New("missing or corrupted snapshot, no snapshot block hash")
 	}
 	baseRoot := rawdb.ReadSnapshotRoot(diskdb)
 	if baseRoot == (common.Hash{}) {
-		return nil, false, errors.New("missing or corrupted snapshot, no snapshot root")
+		return nil, nil, false, errors.New("missing or corrupted snapshot, no snapshot root")
 	}
-	if baseRoot != root {
-		return nil, false, fmt.Errorf("root stored on disk (%#x) does not match last accepted (%#x)", baseRoot, root)
+	layers, err := loadJournal(diskdb, baseBlockHash, baseRoot, blockHash, root)
+	if err != nil {
+		return nil, nil, false, err
 	}
 
 	// Retrieve the disk layer generator. It must exist, no matter the
@@ -79,11 +121,11 @@ func loadSnapshot(diskdb ethdb.KeyValueStore, triedb *trie.Database, cache int,
 	// layer is invalid.
 	generatorBlob := rawdb.ReadSnapshotGenerator(diskdb)
 	if len(generatorBlob) == 0 {
-		return nil, false, errors.New("missing snapshot generator")
+		return nil, nil, false, errors.New("missing snapshot generator")
 	}
 	var generator journalGenerator
 	if err := rlp.DecodeBytes(generatorBlob, &generator); err != nil {
-		return nil, false, fmt.Errorf("failed to decode snapshot generator: %v", err)
+		return nil, nil, false, fmt.Errorf("failed to decode snapshot generator: %v", err)
 	}
 
 	// Instantiate snapshot as disk layer with last recorded block hash and root
@@ -93,6 +135,7 @@ func loadSnapshot(diskdb ethdb.KeyValueStore, triedb *trie.Database, cache int,
 		cache:     newMeteredSnapshotCache(cache * 1024 * 1024),
 		root:      baseRoot,
 		blockHash: baseBlockHash,
+		wipes:     loadStorageWipes(diskdb),
 		created:   time.Now(),
 	}
 
@@ -105,6 +148,7 @@ func loadSnapshot(diskdb ethdb.KeyValueStore, triedb *trie.Database, cache int,
 		if generator.Wiping {
 			log.Info("Resuming previous snapshot wipe")
 			wiper = WipeSnapshot(diskdb, false)
+			snapshot.wipes = nil
 		}
 		// Whether or not wiping was in progress, load any generator progress too
 		snapshot.genMarker = generator.Marker
@@ -133,7 +177,205 @@ func loadSnapshot(diskdb ethdb.KeyValueStore, triedb *trie.Database, cache int,
 		})
 	}
 
-	return snapshot, generator.Done, nil
+	// Restore the journalled diff layers on top of the disk layer
+	var (
+		diffs   = make([]*diffLayer, 0, len(layers))
+		parents = make(map[common.Hash]*diffLayer, len(layers))
+	)
+	for _, layer := range layers {
+		var diff *diffLayer
+		if parent, ok := parents[layer.ParentHash]; ok {
+			diff = newDiffLayer(parent, layer.BlockHash, layer.Root, layer.destructs(), layer.accounts(), layer.storage())
+		} else {
+			diff = newDiffLayer(snapshot, layer.BlockHash, layer.Root, layer.destructs(), layer.accounts(), layer.storage())
+		}
+		parents[layer.BlockHash] = diff
+		diffs = append(diffs, diff)
+	}
+	return snapshot, diffs, generator.Done, nil
+}
+
+// loadJournal returns the journalled diff layers on top of the disk layer of
+// [baseBlockHash] and [baseRoot] that are the layer of [blockHash] and [root],
+// its ancestors or its descendants. The journal is deleted once read, so that
+// it is never restored on top of a disk layer it was not written for.
+//
+// An error is returned if the disk layer does not match [blockHash] and the
+// journal does not link the two.
+func loadJournal(diskdb ethdb.KeyValueStore, baseBlockHash, baseRoot, blockHash, root common.Hash) ([]journalDiff, error) {
+	var journal journalDiffs
+	if blob := rawdb.ReadSnapshotJournal(diskdb); len(blob) > 0 {
+		if err := rawdb.DeleteSnapshotJournal(diskdb); err != nil {
+			return nil, fmt.Errorf("failed to delete snapshot journal: %w", err)
+		}
+		switch err := rlp.DecodeBytes(blob, &journal); {
+		case err != nil:
+			log.Warn("Failed to decode snapshot journal, discarding", "err", err)
+			journal = journalDiffs{}
+		case journal.Version != journalVersion:
+			log.Warn("Discarding snapshot journal of unknown version", "version", journal.Version, "expected", journalVersion)
+			journal = journalDiffs{}
+		case journal.BlockHash != baseBlockHash || journal.Root != baseRoot:
+			log.Warn("Discarding snapshot journal of a different disk layer", "hash", journal.BlockHash, "root", journal.Root, "diskHash", baseBlockHash, "diskRoot", baseRoot)
+			journal = journalDiffs{}
+		}
+	}
+	layers := make(map[common.Hash]journalDiff, len(journal.Layers))
+	for _, layer := range journal.Layers {
+		layers[layer.BlockHash] = layer
+	}
+
+	// Walk down from the last accepted block to the disk layer
+	ancestors := make(map[common.Hash]bool)
+	for hash := blockHash; hash != baseBlockHash; {
+		layer, ok := layers[hash]
+		if !ok || ancestors[hash] {
+			return nil, fmt.Errorf("block hash stored on disk (%#x) does not match last accepted (%#x)", baseBlockHash, blockHash)
+		}
+		ancestors[hash] = true
+		hash = layer.ParentHash
+	}
+	headRoot := baseRoot
+	if layer, ok := layers[blockHash]; ok {
+		headRoot = layer.Root
+	}
+	if headRoot != root {
+		return nil, fmt.Errorf("root stored on disk (%#x) does not match last accepted (%#x)", headRoot, root)
+	}
+
+	// Keep the layers linking the disk layer to the last accepted block, and
+	// those built on top of it.
+	var (
+		kept        []journalDiff
+		descendants = map[common.Hash]bool{blockHash: true}
+	)
+	for _, layer := range journal.Layers {
+		if descendants[layer.ParentHash] {
+			descendants[layer.BlockHash] = true
+		}
+		if ancestors[layer.BlockHash] || descendants[layer.BlockHash] {
+			kept = append(kept, layer)
+		}
+	}
+	if len(kept) > 0 {
+		log.Info("Loaded snapshot journal", "diskHash", baseBlockHash, "lastAccepted", blockHash, "accepted", len(ancestors), "layers", len(kept))
+	}
+	return kept, nil
+}
+
+// destructs returns the destructed accounts of the journalled diff layer.
+func (l *journalDiff) destructs() map[common.Hash]struct{} {
+	destructs := make(map[common.Hash]struct{}, len(l.Destructs))
+	for _, hash := range l.Destructs {
+		destructs[hash] = struct{}{}
+	}
+	return destructs
+}
+
+// accounts returns the accounts of the journalled diff layer.
+func (l *journalDiff) accounts() map[common.Hash][]byte {
+	accounts := make(map[common.Hash][]byte, len(l.Accounts))
+	for _, entry := range l.Accounts {
+		accounts[entry.Hash] = entry.Blob
+	}
+	return accounts
+}
+
+// storage returns the storage slots of the journalled diff layer, where an
+// empty value is a deleted slot.
+func (l *journalDiff) storage() map[common.Hash]map[common.Hash][]byte {
+	storage := make(map[common.Hash]map[common.Hash][]byte, len(l.Storage))
+	for _, entry := range l.Storage {
+		slots := make(map[common.Hash][]byte, len(entry.Keys))
+		for i, key := range entry.Keys {
+			if len(entry.Vals[i]) > 0 {
+				slots[key] = entry.Vals[i]
+			} else {
+				slots[key] = nil
+			}
+		}
+		storage[entry.Hash] = slots
+	}
+	return storage
+}
+
+// journal returns the journal entry of the diff layer.
+func (dl *diffLayer) journal() journalDiff {
+	dl.lock.RLock()
+	defer dl.lock.RUnlock()
+
+	entry := journalDiff{
+		BlockHash:  dl.blockHash,
+		ParentHash: dl.parent.BlockHash(),
+		Root:       dl.root,
+		Destructs:  make([]common.Hash, 0, len(dl.destructSet)),
+		Accounts:   make([]journalAccount, 0, len(dl.accountData)),
+		Storage:    make([]journalStorage, 0, len(dl.storageData)),
+	}
+	for hash := range dl.destructSet {
+		entry.Destructs = append(entry.Destructs, hash)
+	}
+	for hash, blob := range dl.accountData {
+		entry.Accounts = append(entry.Accounts, journalAccount{Hash: hash, Blob: blob})
+	}
+	for hash, slots := range dl.storageData {
+		storage := journalStorage{
+			Hash: hash,
+			Keys: make([]common.Hash, 0, len(slots)),
+			Vals: make([][]byte, 0, len(slots)),
+		}
+		for key, val := range slots {
+			storage.Keys = append(storage.Keys, key)
+			storage.Vals = append(storage.Vals, val)
+		}
+		entry.Storage = append(entry.Storage, storage)
+	}
+	return entry
+}
+
+// Journal persists the diff layers on top of the disk layer, so that they are
+// restored instead of lost by the next call to New. This lets a restart
+// resume from the layers of blocks that were processed, or accepted but not
+// yet flattened, instead of regenerating the snapshot.
+//
+// Journal should only be called at shutdown, once the snapshot is no longer
+// updated.
+func (t *Tree) Journal() error {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	base := t.disklayer()
+	if base == nil {
+		return errors.New("snapshot is missing a disk layer")
+	}
+	children := make(map[common.Hash][]*diffLayer)
+	for _, layer := range t.blockLayers {
+		if diff, ok := layer.(*diffLayer); ok {
+			parent := diff.Parent().BlockHash()
+			children[parent] = append(children[parent], diff)
+		}
+	}
+	journal := journalDiffs{
+		Version:   journalVersion,
+		BlockHash: base.blockHash,
+		Root:      base.root,
+	}
+	for queue := children[base.blockHash]; len(queue) > 0; queue = queue[1:] {
+		journal.Layers = append(journal.Layers, queue[0].journal())
+		queue = append(queue, children[queue[0].blockHash]...)
+	}
+	if len(journal.Layers) == 0 {
+		return rawdb.DeleteSnapshotJournal(t.diskdb)
+	}
+	blob, err := rlp.EncodeToBytes(journal)
+	if err != nil {
+		return err
+	}
+	if err := rawdb.WriteSnapshotJournal(t.diskdb, blob); err != nil {
+		return err
+	}
+	log.Info("Journalled snapshot diff layers", "diskHash", base.blockHash, "layers", len(journal.Layers), "size", common.StorageSize(len(blob)))
+	return nil
 }
 
 // ResetSnapshotGeneration writes a clean snapshot generator marker to [db]
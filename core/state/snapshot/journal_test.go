@@ -0,0 +1,65 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shubhamdubey02/coreth/core/rawdb"
+	"github.com/shubhamdubey02/coreth/trie"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournalDiffLayers(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		diskdb = rawdb.NewMemoryDatabase()
+		triedb = trie.NewDatabase(diskdb, nil)
+		config = Config{CacheSize: 1, NoBuild: true, SkipVerify: true}
+	)
+	rawdb.WriteSnapshotBlockHash(diskdb, common.HexToHash("0x01"))
+	rawdb.WriteSnapshotRoot(diskdb, common.HexToHash("0xff01"))
+	ResetSnapshotGeneration(diskdb)
+
+	snaps, err := New(config, diskdb, triedb, common.HexToHash("0x01"), common.HexToHash("0xff01"))
+	require.NoError(err)
+
+	// Build 0x01 <- 0x02 <- 0x03 and the sibling 0x01 <- 0x04
+	accounts := randomAccountSet("0xa1")
+	storage := randomStorageSet([]string{"0xa1"}, [][]string{{"0xb1"}}, [][]string{{"0xb2"}})
+	require.NoError(snaps.Update(common.HexToHash("0x02"), common.HexToHash("0xff02"), common.HexToHash("0x01"), nil, accounts, storage))
+	require.NoError(snaps.Update(common.HexToHash("0x03"), common.HexToHash("0xff03"), common.HexToHash("0x02"), map[common.Hash]struct{}{common.HexToHash("0xa2"): {}}, randomAccountSet("0xa3"), nil))
+	require.NoError(snaps.Update(common.HexToHash("0x04"), common.HexToHash("0xff04"), common.HexToHash("0x01"), nil, randomAccountSet("0xa4"), nil))
+	require.NoError(snaps.Journal())
+	snaps.Release()
+
+	// Restarting with 0x02 accepted flattens its layer and drops its sibling.
+	snaps, err = New(config, diskdb, triedb, common.HexToHash("0x02"), common.HexToHash("0xff02"))
+	require.NoError(err)
+	require.Equal(common.HexToHash("0x02"), rawdb.ReadSnapshotBlockHash(diskdb))
+	require.Equal(common.HexToHash("0xff02"), snaps.DiskRoot())
+	require.Equal(2, snaps.NumBlockLayers())
+	require.Nil(snaps.Snapshot(common.HexToHash("0xff04")))
+	require.Empty(rawdb.ReadSnapshotJournal(diskdb))
+
+	head := snaps.Snapshot(common.HexToHash("0xff03"))
+	require.NotNil(head)
+	blob, err := head.AccountRLP(common.HexToHash("0xa1"))
+	require.NoError(err)
+	require.Equal(accounts[common.HexToHash("0xa1")], blob)
+	slot, err := head.Storage(common.HexToHash("0xa1"), common.HexToHash("0xb1"))
+	require.NoError(err)
+	require.Equal(storage[common.HexToHash("0xa1")][common.HexToHash("0xb1")], slot)
+	slot, err = head.Storage(common.HexToHash("0xa1"), common.HexToHash("0xb2"))
+	require.NoError(err)
+	require.Empty(slot)
+
+	// Without a journal, a disk layer behind the last accepted block is
+	// rejected.
+	snaps.Release()
+	_, err = New(config, diskdb, triedb, common.HexToHash("0x03"), common.HexToHash("0xff03"))
+	require.ErrorContains(err, "does not match last accepted")
+}
@@ -41,6 +41,7 @@ import (
 	"github.com/shubhamdubey02/coreth/core/types"
 	"github.com/shubhamdubey02/coreth/metrics"
 	"github.com/shubhamdubey02/coreth/trie"
+	"golang.org/x/exp/maps"
 )
 
 const (
@@ -86,6 +87,7 @@ var (
 	snapshotFlushAccountSizeMeter = metrics.NewRegisteredMeter("state/snapshot/flush/account/size", nil)
 	snapshotFlushStorageItemMeter = metrics.NewRegisteredMeter("state/snapshot/flush/storage/item", nil)
 	snapshotFlushStorageSizeMeter = metrics.NewRegisteredMeter("state/snapshot/flush/storage/size", nil)
+	snapshotStorageWipesGauge     = metrics.NewRegisteredGauge("state/snapshot/flush/storage/wipes", nil)
 
 	snapshotBloomIndexTimer = metrics.NewRegisteredResettingTimer("state/snapshot/bloom/index", nil)
 	snapshotBloomErrorGauge = metrics.NewRegisteredGaugeFloat64("state/snapshot/bloom/error", nil)
@@ -220,7 +222,7 @@ func New(config Config, diskdb ethdb.KeyValueStore, triedb *trie.Database, block
 	}
 
 	// Attempt to load a previously persisted snapshot and rebuild one if failed
-	head, generated, err := loadSnapshot(diskdb, triedb, config.CacheSize, blockHash, root, config.NoBuild)
+	base, diffs, generated, err := loadSnapshot(diskdb, triedb, config.CacheSize, blockHash, root, config.NoBuild)
 	if err != nil {
 		log.Warn("Failed to load snapshot, regenerating", "err", err)
 		if !config.NoBuild {
@@ -239,12 +241,27 @@ func New(config Config, diskdb ethdb.KeyValueStore, triedb *trie.Database, block
 	// It is unnecessary to grab the lock here, since it was created within this function
 	// call, but we grab it nevertheless to follow the spec for insertSnap.
 	snap.lock.Lock()
-	defer snap.lock.Unlock()
-	for head != nil {
-		snap.insertSnap(head)
-		head = head.Parent()
+	snap.insertSnap(base)
+	for _, diff := range diffs {
+		snap.insertSnap(diff)
+	}
+	var accepted []common.Hash
+	for layer := snap.blockLayers[blockHash]; layer != snapshot(base); layer = layer.Parent() {
+		accepted = append(accepted, layer.BlockHash())
+	}
+	snap.lock.Unlock()
+
+	// Flatten the journalled layers of the blocks accepted after the disk
+	// layer, so that it matches the last accepted block.
+	for i := len(accepted) - 1; i >= 0; i-- {
+		if err := snap.Flatten(accepted[i]); err != nil {
+			return nil, fmt.Errorf("failed to flatten journalled snapshot layer %s: %w", accepted[i], err)
+		}
 	}
 
+	snap.lock.Lock()
+	defer snap.lock.Unlock()
+
 	// Verify any synchronously generated or loaded snapshot from disk
 	if !config.AsyncBuild || generated {
 		if err := snap.verifyIntegrity(snap.disklayer(), !config.AsyncBuild && !generated); err != nil {
@@ -617,35 +634,41 @@ func diffToDisk(bottom *diffLayer) (*diskLayer, bool, error) {
 	base.stale = true
 	base.lock.Unlock()
 
-	// Destroy all the destructed accounts from the database
+	// Destroy all the destructed accounts from the database. Their storage is
+	// wiped by key range, a bounded number of slots per flatten, and hidden
+	// from the disk layer until it is fully deleted.
+	wipes := make(map[common.Hash]struct{}, len(base.wipes)+len(bottom.destructSet))
+	for hash := range base.wipes {
+		wipes[hash] = struct{}{}
+	}
 	for hash := range bottom.destructSet {
 		// Skip any account not covered yet by the snapshot
 		if base.genMarker != nil && bytes.Compare(hash[:], base.genMarker) > 0 {
 			continue
 		}
-		// Remove all storage slots
 		rawdb.DeleteAccountSnapshot(batch, hash)
 		base.cache.Set(hash[:], nil)
-
-		it := rawdb.IterateStorageSnapshots(base.diskdb, hash)
-		for it.Next() {
-			key := it.Key()
-			batch.Delete(key)
-			base.cache.Del(key[1:])
-			snapshotFlushStorageItemMeter.Mark(1)
-
-			// Ensure we don't delete too much data blindly (contract can be
-			// huge). It's ok to flush, the root will go missing in case of a
-			// crash and we'll detect and regenerate the snapshot.
-			if batch.ValueSize() > 64*1024*1024 {
-				if err := batch.Write(); err != nil {
-					log.Crit("Failed to write storage deletions", "err", err)
-				}
-				batch.Reset()
-			}
+		wipes[hash] = struct{}{}
+	}
+	budget := storageWipeLimit
+	for hash := range wipes {
+		// The storage of an account written to by this layer must be fully
+		// deleted before the writes.
+		limit := budget
+		if _, ok := bottom.storageData[hash]; ok {
+			limit = -1
+		}
+		done, deleted := wipeStorage(base.diskdb, batch, base.cache, hash, limit)
+		if done {
+			delete(wipes, hash)
+		}
+		if budget -= deleted; budget < 0 {
+			budget = 0
 		}
-		it.Release()
 	}
+	rawdb.WriteSnapshotStorageWipes(batch, maps.Keys(wipes))
+	snapshotStorageWipesGauge.Update(int64(len(wipes)))
+
 	// Push all updated accounts into the database
 	for hash, data := range bottom.accountData {
 		// Skip any account not covered yet by the snapshot
@@ -717,6 +740,7 @@ func diffToDisk(bottom *diffLayer) (*diskLayer, bool, error) {
 		triedb:     base.triedb,
 		genMarker:  base.genMarker,
 		genPending: base.genPending,
+		wipes:      wipes,
 		created:    time.Now(),
 	}
 	// If snapshot generation hasn't finished yet, port over all the starts and
@@ -930,6 +954,41 @@ func (t *Tree) DiskRoot() common.Hash {
 	return t.diskRoot()
 }
 
+// CacheSize returns the memory held by the cache of the disk layer.
+func (t *Tree) CacheSize() common.StorageSize {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	disklayer := t.disklayer()
+	if disklayer == nil || disklayer.cache == nil {
+		return 0
+	}
+	return common.StorageSize(disklayer.cache.Size())
+}
+
+// ResetCache drops the accounts and storage slots cached by the disk layer,
+// which are read from disk again when next accessed.
+func (t *Tree) ResetCache() {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if disklayer := t.disklayer(); disklayer != nil && disklayer.cache != nil {
+		disklayer.cache.Reset()
+	}
+}
+
+// ResizeCache resizes the cache of the disk layer to [size] bytes, keeping
+// the previous entries readable while the cache fills up. The cache is shared with the disk layers created when diff
+// layers are flattened into it.
+func (t *Tree) ResizeCache(size int) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if disklayer := t.disklayer(); disklayer != nil && disklayer.cache != nil {
+		disklayer.cache.Resize(size)
+	}
+}
+
 // Size returns the memory usage of the diff layers above the disk layer and the
 // dirty nodes buffered in the disk layer. Currently, the implementation uses a
 // special diff layer (the first) as an aggregator simulating a dirty buffer, so
@@ -34,8 +34,15 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/shubhamdubey02/coreth/utils"
 )
 
+// storageWipeLimit is the number of storage slots of destructed accounts
+// deleted from the disk layer each time a diff layer is flattened into it,
+// bounding the latency of flattening a layer which destructed large
+// contracts. The remaining slots are deleted by later flattens.
+const storageWipeLimit = 10000
+
 // WipeSnapshot starts a goroutine to iterate over the entire key-value database
 // and delete all the data associated with the snapshot (accounts, storage,
 // metadata). After all is done, the snapshot range of the database is compacted
@@ -70,6 +77,7 @@ func wipeContent(db ethdb.KeyValueStore) error {
 	if err := wipeKeyRange(db, "storage", rawdb.SnapshotStoragePrefix, nil, nil, len(rawdb.SnapshotStoragePrefix)+2*common.HashLength, true); err != nil {
 		return err
 	}
+	rawdb.WriteSnapshotStorageWipes(db, nil)
 
 	return nil
 }
@@ -134,3 +142,35 @@ func wipeKeyRange(db ethdb.KeyValueStore, kind string, prefix []byte, origin []b
 	}
 	return nil
 }
+
+// wipeStorage deletes the snapshot storage of [account] into [batch], at most
+// [limit] slots unless it is negative, flushing [batch] if it grows too large.
+// It returns whether the storage is fully deleted and the number of deleted
+// slots.
+func wipeStorage(db ethdb.KeyValueStore, batch ethdb.Batch, cache *utils.MeteredCache, account common.Hash, limit int) (bool, int) {
+	it := rawdb.IterateStorageSnapshots(db, account)
+	defer it.Release()
+
+	var deleted int
+	for it.Next() {
+		if limit >= 0 && deleted >= limit {
+			return false, deleted
+		}
+		key := it.Key()
+		batch.Delete(key)
+		cache.Del(key[1:])
+		snapshotFlushStorageItemMeter.Mark(1)
+		deleted++
+
+		// Ensure we don't delete too much data blindly (contract can be
+		// huge). It's ok to flush, the root will go missing in case of a
+		// crash and we'll detect and regenerate the snapshot.
+		if batch.ValueSize() > 64*1024*1024 {
+			if err := batch.Write(); err != nil {
+				log.Crit("Failed to write storage deletions", "err", err)
+			}
+			batch.Reset()
+		}
+	}
+	return true, deleted
+}
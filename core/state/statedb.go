@@ -35,6 +35,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/shubhamdubey02/coreth/core/rawdb"
 	"github.com/shubhamdubey02/coreth/core/state/snapshot"
@@ -115,6 +116,10 @@ type StateDB struct {
 	// Preimages occurred seen by VM in the scope of block.
 	preimages map[common.Hash][]byte
 
+	// Storage slots accessed in the scope of block, nil unless tracked. See
+	// [StateDB.TrackStorageAccesses].
+	storageAccesses storageAccesses
+
 	// Per-transaction access list
 	accessList *accessList
 	// Ordered storage slots to be used in predicate verification as set in the tx access list.
@@ -149,6 +154,9 @@ type StateDB struct {
 	AccountDeleted int
 	StorageDeleted int
 
+	// base is the state this state is an overlay of, if any. See [NewOverlay].
+	base *StateDB
+
 	// Testing hooks
 	onCommit func(states *triestate.Set) // Hook invoked when commit is performed
 }
@@ -213,6 +221,18 @@ func (s *StateDB) StartPrefetcher(namespace string, maxConcurrency int) {
 	}
 }
 
+// StartTunedPrefetcher is like [StartPrefetcher] but takes its parallelism
+// and queue bound from [tuner], and reports measured read latency back to it.
+func (s *StateDB) StartTunedPrefetcher(namespace string, tuner *PrefetcherTuner) {
+	if s.prefetcher != nil {
+		s.prefetcher.close()
+		s.prefetcher = nil
+	}
+	if s.snap != nil {
+		s.prefetcher = newTunedTriePrefetcher(s.db, s.originalRoot, namespace, tuner)
+	}
+}
+
 // StopPrefetcher terminates a running prefetcher and reports any leftover stats
 // from the gathered metrics.
 func (s *StateDB) StopPrefetcher() {
@@ -370,6 +390,16 @@ func (s *StateDB) GetStorageRoot(addr common.Address) common.Hash {
 	return common.Hash{}
 }
 
+// HasDirtyStorage returns true if the storage of [addr] was modified since the
+// state was last hashed, so that it no longer matches its storage root.
+func (s *StateDB) HasDirtyStorage(addr common.Address) bool {
+	stateObject := s.getStateObject(addr)
+	if stateObject != nil {
+		return len(stateObject.dirtyStorage) > 0 || len(stateObject.pendingStorage) > 0
+	}
+	return false
+}
+
 // TxIndex returns the current transaction index set by Prepare.
 func (s *StateDB) TxIndex() int {
 	return s.txIndex
@@ -404,6 +434,7 @@ func (s *StateDB) GetState(addr common.Address, hash common.Hash) common.Hash {
 	stateObject := s.getStateObject(addr)
 	if stateObject != nil {
 		NormalizeStateKey(&hash)
+		s.storageAccesses.add(addr, hash)
 		return stateObject.GetState(hash)
 	}
 	return common.Hash{}
@@ -413,6 +444,11 @@ func (s *StateDB) GetState(addr common.Address, hash common.Hash) common.Hash {
 func (s *StateDB) GetCommittedState(addr common.Address, hash common.Hash) common.Hash {
 	stateObject := s.getStateObject(addr)
 	if stateObject != nil {
+		if s.storageAccesses != nil {
+			key := hash
+			NormalizeStateKey(&key)
+			s.storageAccesses.add(addr, key)
+		}
 		return stateObject.GetCommittedState(hash)
 	}
 	return common.Hash{}
@@ -423,6 +459,7 @@ func (s *StateDB) GetCommittedStateAP1(addr common.Address, hash common.Hash) co
 	stateObject := s.getStateObject(addr)
 	if stateObject != nil {
 		NormalizeStateKey(&hash)
+		s.storageAccesses.add(addr, hash)
 		return stateObject.GetCommittedState(hash)
 	}
 	return common.Hash{}
@@ -433,6 +470,35 @@ func (s *StateDB) Database() Database {
 	return s.db
 }
 
+// OriginalRoot returns the root of the state [s] was opened at, before any
+// changes were made.
+func (s *StateDB) OriginalRoot() common.Hash {
+	return s.originalRoot
+}
+
+// Prove writes the Merkle proof of the account [addr] to [proofDb], against
+// the root last returned by IntermediateRoot.
+func (s *StateDB) Prove(addr common.Address, proofDb ethdb.KeyValueWriter) error {
+	return s.trie.Prove(crypto.Keccak256(addr.Bytes()), proofDb)
+}
+
+// ProveStorage writes the Merkle proof of the slot [key] of [addr] to
+// [proofDb], against the storage root of [addr] as of the last call to
+// IntermediateRoot. Nothing is written if [addr] does not exist or has an
+// empty storage, the absence of the slot following from the account proof.
+func (s *StateDB) ProveStorage(addr common.Address, key common.Hash, proofDb ethdb.KeyValueWriter) error {
+	obj := s.getStateObject(addr)
+	if obj == nil || obj.Root() == types.EmptyRootHash {
+		return nil
+	}
+	tr, err := obj.getTrie()
+	if err != nil {
+		return err
+	}
+	NormalizeStateKey(&key)
+	return tr.Prove(crypto.Keccak256(key.Bytes()), proofDb)
+}
+
 func (s *StateDB) HasSelfDestructed(addr common.Address) bool {
 	stateObject := s.getStateObject(addr)
 	if stateObject != nil {
@@ -509,6 +575,7 @@ func (s *StateDB) SetState(addr common.Address, key, value common.Hash) {
 	stateObject := s.GetOrNewStateObject(addr)
 	if stateObject != nil {
 		NormalizeStateKey(&key)
+		s.storageAccesses.add(addr, key)
 		stateObject.SetState(key, value)
 	}
 }
@@ -659,6 +726,11 @@ func (s *StateDB) getDeletedStateObject(addr common.Address) *stateObject {
 	if obj := s.stateObjects[addr]; obj != nil {
 		return obj
 	}
+	// Overlays copy the live object of their base on first access
+	if obj := s.baseObject(addr); obj != nil {
+		s.setStateObject(obj)
+		return obj
+	}
 	// If no live objects are available, attempt to use snapshots
 	var data *types.StateAccount
 	if s.snap != nil {
@@ -821,6 +893,10 @@ func (s *StateDB) Copy() *StateDB {
 		// block mined by ourselves will cause gaps in the tree, and force the
 		// miner to operate trie-backed only.
 		snap: s.snap,
+
+		storageAccesses: s.storageAccesses.copy(),
+
+		base: s.base,
 	}
 	// Copy the dirty states, logs, and preimages
 	for addr := range s.journal.dirties {
@@ -1280,6 +1356,9 @@ func (s *StateDB) CommitWithSnap(block uint64, deleteEmptyObjects bool, snaps *s
 // The associated block number of the state transition is also provided
 // for more chain context.
 func (s *StateDB) commit(block uint64, deleteEmptyObjects bool, snaps *snapshot.Tree, blockHash, parentHash common.Hash, referenceRoot bool) (common.Hash, error) {
+	if s.base != nil {
+		return common.Hash{}, errOverlayCommit
+	}
 	// Short circuit in case any database failure occurred earlier.
 	if s.dbErr != nil {
 		return common.Hash{}, fmt.Errorf("commit aborted due to earlier error: %v", s.dbErr)
@@ -0,0 +1,55 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// storageAccesses is the set of storage slots accessed by each account, by
+// their normalized key. Accesses are not journaled: slots accessed by reverted
+// calls count as accessed. Multicoin balances are not recorded.
+type storageAccesses map[common.Address]map[common.Hash]struct{}
+
+// add records the access to [slot] of [addr]. It is a no-op on a nil set.
+func (a storageAccesses) add(addr common.Address, slot common.Hash) {
+	if a == nil {
+		return
+	}
+	slots, ok := a[addr]
+	if !ok {
+		slots = make(map[common.Hash]struct{})
+		a[addr] = slots
+	}
+	slots[slot] = struct{}{}
+}
+
+func (a storageAccesses) copy() storageAccesses {
+	if a == nil {
+		return nil
+	}
+	cpy := make(storageAccesses, len(a))
+	for addr, slots := range a {
+		cpy[addr] = make(map[common.Hash]struct{}, len(slots))
+		for slot := range slots {
+			cpy[addr][slot] = struct{}{}
+		}
+	}
+	return cpy
+}
+
+// TrackStorageAccesses starts recording the storage slots read or written
+// through the state, for storage expiry.
+func (s *StateDB) TrackStorageAccesses() {
+	if s.storageAccesses == nil {
+		s.storageAccesses = make(storageAccesses)
+	}
+}
+
+// StorageAccesses returns the storage slots of each account accessed since
+// [StateDB.TrackStorageAccesses] was called, or nil if accesses are not
+// tracked.
+func (s *StateDB) StorageAccesses() map[common.Address]map[common.Hash]struct{} {
+	return s.storageAccesses
+}
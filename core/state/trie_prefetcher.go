@@ -28,6 +28,7 @@ package state
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -50,8 +51,14 @@ type triePrefetcher struct {
 	fetches  map[string]Trie        // Partially or fully fetched tries. Only populated for inactive copies.
 	fetchers map[string]*subfetcher // Subfetchers for each trie
 
-	maxConcurrency int
-	workers        *utils.BoundedWorkers
+	maxConcurrency  int
+	maxPendingTasks int
+	workers         *utils.BoundedWorkers
+
+	// [tuner] receives the measured read latency on close. May be nil.
+	tuner       *PrefetcherTuner
+	reads       atomic.Int64
+	readLatency atomic.Int64 // nanoseconds
 
 	subfetcherWorkersMeter metrics.Meter
 	subfetcherWaitTimer    metrics.Counter
@@ -61,6 +68,8 @@ type triePrefetcher struct {
 	accountDupMeter   metrics.Meter
 	accountSkipMeter  metrics.Meter
 	accountWasteMeter metrics.Meter
+	accountHitMeter   metrics.Meter
+	accountMissMeter  metrics.Meter
 
 	storageFetchersMeter    metrics.Meter
 	storageLoadMeter        metrics.Meter
@@ -68,6 +77,8 @@ type triePrefetcher struct {
 	storageDupMeter         metrics.Meter
 	storageSkipMeter        metrics.Meter
 	storageWasteMeter       metrics.Meter
+	storageHitMeter         metrics.Meter
+	storageMissMeter        metrics.Meter
 }
 
 func newTriePrefetcher(db Database, root common.Hash, namespace string, maxConcurrency int) *triePrefetcher {
@@ -88,6 +99,8 @@ func newTriePrefetcher(db Database, root common.Hash, namespace string, maxConcu
 		accountDupMeter:   metrics.GetOrRegisterMeter(prefix+"/account/dup", nil),
 		accountSkipMeter:  metrics.GetOrRegisterMeter(prefix+"/account/skip", nil),
 		accountWasteMeter: metrics.GetOrRegisterMeter(prefix+"/account/waste", nil),
+		accountHitMeter:   metrics.GetOrRegisterMeter(prefix+"/account/hit", nil),
+		accountMissMeter:  metrics.GetOrRegisterMeter(prefix+"/account/miss", nil),
 
 		storageFetchersMeter:    metrics.GetOrRegisterMeter(prefix+"/storage/fetchers", nil),
 		storageLoadMeter:        metrics.GetOrRegisterMeter(prefix+"/storage/load", nil),
@@ -95,9 +108,20 @@ func newTriePrefetcher(db Database, root common.Hash, namespace string, maxConcu
 		storageDupMeter:         metrics.GetOrRegisterMeter(prefix+"/storage/dup", nil),
 		storageSkipMeter:        metrics.GetOrRegisterMeter(prefix+"/storage/skip", nil),
 		storageWasteMeter:       metrics.GetOrRegisterMeter(prefix+"/storage/waste", nil),
+		storageHitMeter:         metrics.GetOrRegisterMeter(prefix+"/storage/hit", nil),
+		storageMissMeter:        metrics.GetOrRegisterMeter(prefix+"/storage/miss", nil),
 	}
 }
 
+// newTunedTriePrefetcher returns a prefetcher configured by, and reporting
+// read latency to, [tuner].
+func newTunedTriePrefetcher(db Database, root common.Hash, namespace string, tuner *PrefetcherTuner) *triePrefetcher {
+	p := newTriePrefetcher(db, root, namespace, tuner.Parallelism())
+	p.maxPendingTasks = tuner.maxPendingTasks()
+	p.tuner = tuner
+	return p
+}
+
 // close iterates over all the subfetchers, aborts any that were left spinning
 // and reports the stats to the metrics subsystem.
 func (p *triePrefetcher) close() {
@@ -122,6 +146,10 @@ func (p *triePrefetcher) close() {
 				p.accountDupMeter.Mark(int64(fetcher.dups))
 				p.accountSkipMeter.Mark(int64(fetcher.skips()))
 
+				hits, misses := fetcher.hits()
+				p.accountHitMeter.Mark(hits)
+				p.accountMissMeter.Mark(misses)
+
 				for _, key := range fetcher.used {
 					delete(fetcher.seen, string(key))
 				}
@@ -136,6 +164,10 @@ func (p *triePrefetcher) close() {
 				p.storageDupMeter.Mark(int64(fetcher.dups))
 				p.storageSkipMeter.Mark(int64(fetcher.skips()))
 
+				hits, misses := fetcher.hits()
+				p.storageHitMeter.Mark(hits)
+				p.storageMissMeter.Mark(misses)
+
 				for _, key := range fetcher.used {
 					delete(fetcher.seen, string(key))
 				}
@@ -156,6 +188,9 @@ func (p *triePrefetcher) close() {
 	if metrics.Enabled {
 		p.subfetcherWorkersMeter.Mark(workersUsed)
 	}
+	if p.tuner != nil {
+		p.tuner.report(int(p.reads.Load()), time.Duration(p.readLatency.Load()))
+	}
 
 	// Clear out all fetchers (will crash on a second call, deliberate)
 	p.fetchers = nil
@@ -179,6 +214,8 @@ func (p *triePrefetcher) copy() *triePrefetcher {
 		accountDupMeter:   p.accountDupMeter,
 		accountSkipMeter:  p.accountSkipMeter,
 		accountWasteMeter: p.accountWasteMeter,
+		accountHitMeter:   p.accountHitMeter,
+		accountMissMeter:  p.accountMissMeter,
 
 		storageFetchersMeter:    p.storageFetchersMeter,
 		storageLoadMeter:        p.storageLoadMeter,
@@ -186,6 +223,8 @@ func (p *triePrefetcher) copy() *triePrefetcher {
 		storageDupMeter:         p.storageDupMeter,
 		storageSkipMeter:        p.storageSkipMeter,
 		storageWasteMeter:       p.storageWasteMeter,
+		storageHitMeter:         p.storageHitMeter,
+		storageMissMeter:        p.storageMissMeter,
 	}
 	// If the prefetcher is already a copy, duplicate the data
 	if p.fetches != nil {
@@ -374,6 +413,20 @@ func (sf *subfetcher) skips() int {
 	return sf.to.skipCount()
 }
 
+// hits returns the number of used keys that were scheduled for prefetching
+// (hits) and the number that were not (misses).
+func (sf *subfetcher) hits() (int64, int64) {
+	var hits, misses int64
+	for _, key := range sf.used {
+		if _, ok := sf.seen[string(key)]; ok {
+			hits++
+		} else {
+			misses++
+		}
+	}
+	return hits, misses
+}
+
 func (sf *subfetcher) copies() int {
 	if sf.to == nil {
 		// Unable to open trie
@@ -484,6 +537,17 @@ func (to *trieOrchestrator) enqueueTasks(tasks [][]byte) {
 		to.skips += len(tasks)
 		return
 	}
+	if limit := to.sf.p.maxPendingTasks; limit > 0 {
+		available := limit - len(to.pendingTasks)
+		if available <= 0 {
+			to.skips += len(tasks)
+			return
+		}
+		if len(tasks) > available {
+			to.skips += len(tasks) - available
+			tasks = tasks[:available]
+		}
+	}
 	to.processingTasks.Add(len(tasks))
 	to.pendingTasks = append(to.pendingTasks, tasks...)
 
@@ -553,12 +617,17 @@ func (to *trieOrchestrator) processTasks() {
 			fTask := tasks[i]
 			f := func() {
 				// Perform task
-				var err error
+				var (
+					err   error
+					start = time.Now()
+				)
 				if len(fTask) == common.AddressLength {
 					_, err = t.GetAccount(common.BytesToAddress(fTask))
 				} else {
 					_, err = t.GetStorage(to.sf.addr, fTask)
 				}
+				to.sf.p.reads.Add(1)
+				to.sf.p.readLatency.Add(int64(time.Since(start)))
 				if err != nil {
 					log.Error("Trie prefetcher failed fetching", "root", to.sf.root, "err", err)
 				}
@@ -0,0 +1,191 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/shubhamdubey02/coreth/metrics"
+)
+
+const (
+	// Adaptive mode adjusts parallelism after every [adaptiveWindow] prefetched
+	// items.
+	adaptiveWindow = 1024
+
+	defaultTargetReadLatency = 200 * time.Microsecond
+)
+
+var errInvalidPrefetcherSettings = errors.New("invalid prefetcher settings")
+
+// PrefetcherSettings are the live-tunable parameters of the trie prefetcher.
+type PrefetcherSettings struct {
+	// Parallelism is the maximum number of concurrent trie reads. In adaptive
+	// mode this is the starting point and is adjusted within
+	// [MinParallelism, MaxParallelism].
+	Parallelism int `json:"parallelism"`
+	// MaxPendingTasks bounds the number of queued keys per trie. Keys
+	// scheduled beyond this bound are skipped. Zero means unbounded.
+	MaxPendingTasks int `json:"maxPendingTasks"`
+
+	// Adaptive enables scaling the number of workers based on the measured
+	// trie read latency: reads slower than [TargetReadLatency] are likely
+	// disk-bound and benefit from more concurrency, while faster reads are
+	// served from cache and need fewer workers.
+	Adaptive          bool          `json:"adaptive"`
+	MinParallelism    int           `json:"minParallelism"`
+	MaxParallelism    int           `json:"maxParallelism"`
+	TargetReadLatency time.Duration `json:"targetReadLatency"`
+}
+
+// Verify returns an error if [s] is not a valid set of settings.
+func (s PrefetcherSettings) Verify() error {
+	if s.Parallelism < 1 {
+		return fmt.Errorf("%w: parallelism (%d) must be positive", errInvalidPrefetcherSettings, s.Parallelism)
+	}
+	if s.MaxPendingTasks < 0 {
+		return fmt.Errorf("%w: max pending tasks (%d) must not be negative", errInvalidPrefetcherSettings, s.MaxPendingTasks)
+	}
+	if !s.Adaptive {
+		return nil
+	}
+	if s.MinParallelism < 1 || s.MinParallelism > s.MaxParallelism {
+		return fmt.Errorf("%w: adaptive parallelism range [%d, %d] is invalid", errInvalidPrefetcherSettings, s.MinParallelism, s.MaxParallelism)
+	}
+	if s.TargetReadLatency <= 0 {
+		return fmt.Errorf("%w: target read latency (%s) must be positive", errInvalidPrefetcherSettings, s.TargetReadLatency)
+	}
+	return nil
+}
+
+// PrefetcherTuner holds the prefetcher settings shared by every prefetcher
+// started from it. Settings may be changed while the chain is running and
+// take effect for the next prefetcher started.
+type PrefetcherTuner struct {
+	lock     sync.Mutex
+	settings PrefetcherSettings
+	current  int // parallelism handed to the next prefetcher
+
+	// Measurements since the last adaptive adjustment
+	reads       int
+	readLatency time.Duration
+
+	parallelismGauge metrics.Gauge
+	latencyGauge     metrics.Gauge
+}
+
+// NewPrefetcherTuner returns a tuner with a fixed [parallelism] and no bound
+// on the pending task queue.
+func NewPrefetcherTuner(parallelism int) *PrefetcherTuner {
+	t := &PrefetcherTuner{
+		parallelismGauge: metrics.GetOrRegisterGauge(triePrefetchMetricsPrefix+"tuner/parallelism", nil),
+		latencyGauge:     metrics.GetOrRegisterGauge(triePrefetchMetricsPrefix+"tuner/latency", nil),
+	}
+	t.settings = PrefetcherSettings{Parallelism: parallelism}
+	t.setCurrent(parallelism)
+	return t
+}
+
+// Settings returns the current settings.
+func (t *PrefetcherTuner) Settings() PrefetcherSettings {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.settings
+}
+
+// SetSettings replaces the current settings.
+func (t *PrefetcherTuner) SetSettings(settings PrefetcherSettings) error {
+	if settings.Adaptive && settings.TargetReadLatency == 0 {
+		settings.TargetReadLatency = defaultTargetReadLatency
+	}
+	if err := settings.Verify(); err != nil {
+		return err
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.settings = settings
+	t.reads, t.readLatency = 0, 0
+	t.setCurrent(t.clamp(settings.Parallelism))
+	log.Info("Updated trie prefetcher settings", "parallelism", settings.Parallelism, "maxPendingTasks", settings.MaxPendingTasks, "adaptive", settings.Adaptive)
+	return nil
+}
+
+// Parallelism returns the parallelism the next prefetcher should use.
+func (t *PrefetcherTuner) Parallelism() int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.current
+}
+
+// maxPendingTasks returns the bound on queued keys per trie.
+func (t *PrefetcherTuner) maxPendingTasks() int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.settings.MaxPendingTasks
+}
+
+// report records that [reads] trie reads took [latency] in total. In adaptive
+// mode, the parallelism is scaled once enough reads have been measured.
+func (t *PrefetcherTuner) report(reads int, latency time.Duration) {
+	if reads == 0 {
+		return
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.reads += reads
+	t.readLatency += latency
+	if t.reads < adaptiveWindow {
+		return
+	}
+	avg := t.readLatency / time.Duration(t.reads)
+	t.reads, t.readLatency = 0, 0
+	t.latencyGauge.Update(int64(avg))
+	if !t.settings.Adaptive {
+		return
+	}
+
+	next := t.current
+	switch {
+	case avg > t.settings.TargetReadLatency:
+		next = t.current * 2
+	case avg < t.settings.TargetReadLatency/2:
+		next = t.current / 2
+	}
+	next = t.clamp(next)
+	if next != t.current {
+		log.Debug("Adjusting trie prefetcher parallelism", "from", t.current, "to", next, "avgReadLatency", avg)
+		t.setCurrent(next)
+	}
+}
+
+// clamp assumes the caller holds [t.lock].
+func (t *PrefetcherTuner) clamp(parallelism int) int {
+	if !t.settings.Adaptive {
+		return parallelism
+	}
+	if parallelism < t.settings.MinParallelism {
+		return t.settings.MinParallelism
+	}
+	if parallelism > t.settings.MaxParallelism {
+		return t.settings.MaxParallelism
+	}
+	return parallelism
+}
+
+// setCurrent assumes the caller holds [t.lock].
+func (t *PrefetcherTuner) setCurrent(parallelism int) {
+	t.current = parallelism
+	t.parallelismGauge.Update(int64(parallelism))
+}
@@ -0,0 +1,55 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrefetcherTunerAdaptive(t *testing.T) {
+	require := require.New(t)
+
+	tuner := NewPrefetcherTuner(8)
+	require.Equal(8, tuner.Parallelism())
+
+	// Measurements do not change parallelism unless adaptive mode is enabled.
+	tuner.report(adaptiveWindow, adaptiveWindow*time.Millisecond)
+	require.Equal(8, tuner.Parallelism())
+
+	require.ErrorIs(tuner.SetSettings(PrefetcherSettings{Parallelism: 0}), errInvalidPrefetcherSettings)
+	require.ErrorIs(tuner.SetSettings(PrefetcherSettings{Parallelism: 8, Adaptive: true, MinParallelism: 4, MaxParallelism: 2}), errInvalidPrefetcherSettings)
+	require.NoError(tuner.SetSettings(PrefetcherSettings{
+		Parallelism:       8,
+		Adaptive:          true,
+		MinParallelism:    2,
+		MaxParallelism:    16,
+		TargetReadLatency: time.Millisecond,
+	}))
+
+	// Slow reads scale up to the maximum.
+	tuner.report(adaptiveWindow/2, adaptiveWindow*time.Millisecond)
+	require.Equal(8, tuner.Parallelism()) // window not yet full
+	tuner.report(adaptiveWindow/2, adaptiveWindow*time.Millisecond)
+	require.Equal(16, tuner.Parallelism())
+	tuner.report(adaptiveWindow, 2*adaptiveWindow*time.Millisecond)
+	require.Equal(16, tuner.Parallelism())
+
+	// Reads near the target leave parallelism unchanged.
+	tuner.report(adaptiveWindow, adaptiveWindow*time.Millisecond)
+	require.Equal(16, tuner.Parallelism())
+
+	// Fast reads scale down to the minimum.
+	for i := 0; i < 4; i++ {
+		tuner.report(adaptiveWindow, adaptiveWindow*time.Microsecond)
+	}
+	require.Equal(2, tuner.Parallelism())
+
+	// Disabling adaptive mode restores the configured parallelism.
+	require.NoError(tuner.SetSettings(PrefetcherSettings{Parallelism: 4, MaxPendingTasks: 10}))
+	require.Equal(4, tuner.Parallelism())
+	require.Equal(10, tuner.maxPendingTasks())
+}
@@ -86,6 +86,12 @@ func (p *StateProcessor) Process(block *types.Block, parent *types.Header, state
 		log.Error("failed to configure precompiles processing block", "hash", block.Hash(), "number", block.NumberU64(), "timestamp", block.Time(), "err", err)
 		return nil, nil, 0, err
 	}
+	if p.config.IsStorageExpiry(block.Time()) {
+		if err := ApplyStorageExpiry(p.bc, parent, block.Time(), statedb); err != nil {
+			return nil, nil, 0, fmt.Errorf("could not expire storage: %w", err)
+		}
+		statedb.TrackStorageAccesses()
+	}
 
 	var (
 		context = NewEVMBlockContext(header, p.bc, nil)
@@ -147,6 +153,9 @@ func applyTransaction(msg *Message, config *params.ChainConfig, gp *GasPool, sta
 	}
 	receipt.TxHash = tx.Hash()
 	receipt.GasUsed = result.UsedGas
+	if evm.Config.ReceiptCosts {
+		receipt.Costs = types.NewReceiptCosts(result.UsedGas, result.RefundedGas, msg.GasPrice, evm.Context.BaseFee)
+	}
 
 	if tx.Type() == types.BlobTxType {
 		receipt.BlobGasUsed = uint64(len(tx.BlobHashes()) * params.BlobTxBlobGasPerBlob)
@@ -37,6 +37,7 @@ import (
 	"github.com/shubhamdubey02/coreth/core/types"
 	"github.com/shubhamdubey02/coreth/core/vm"
 	"github.com/shubhamdubey02/coreth/params"
+	"github.com/shubhamdubey02/coreth/precompile/contracts/txallowlist"
 	"github.com/shubhamdubey02/coreth/utils"
 	"github.com/shubhamdubey02/coreth/vmerrs"
 )
@@ -198,6 +199,11 @@ type Message struct {
 	BlobGasFeeCap *big.Int
 	BlobHashes    []common.Hash
 
+	// TxType is the type of the transaction the message is derived from, and
+	// CustomGas the intrinsic gas charged by the type if it is a custom type.
+	TxType    uint8
+	CustomGas uint64
+
 	// When SkipAccountChecks is true, the message nonce is not checked against the
 	// account nonce in state. It also disables checking that the sender is an EOA.
 	// This field will be set to true for operations like RPC eth_call.
@@ -219,12 +225,22 @@ func TransactionToMessage(tx *types.Transaction, s types.Signer, baseFee *big.In
 		SkipAccountChecks: false,
 		BlobHashes:        tx.BlobHashes(),
 		BlobGasFeeCap:     tx.BlobGasFeeCap(),
+		TxType:            tx.Type(),
+	}
+	// Blocks are held to the rules of custom transaction types enforced by
+	// the transaction pool.
+	if err := types.ValidateCustomTx(tx); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCustomTx, err)
+	}
+	customGas, err := types.CustomIntrinsicGas(tx)
+	if err != nil {
+		return nil, err
 	}
+	msg.CustomGas = customGas
 	// If baseFee provided, set gasPrice to effectiveGasPrice.
 	if baseFee != nil {
 		msg.GasPrice = cmath.BigMin(msg.GasPrice.Add(msg.GasTipCap, baseFee), msg.GasFeeCap)
 	}
-	var err error
 	msg.From, err = types.Sender(s, tx)
 	return msg, err
 }
@@ -267,6 +283,7 @@ type StateTransition struct {
 	msg          *Message
 	gasRemaining uint64
 	initialGas   uint64
+	payer        common.Address // pays for gas: the sender, or its gas sponsor
 	state        vm.StateDB
 	evm          *vm.EVM
 }
@@ -310,8 +327,13 @@ func (st *StateTransition) buyGas() error {
 			mgval.Add(mgval, blobFee)
 		}
 	}
+	st.payer = st.msg.From
 	if have, want := st.state.GetBalance(st.msg.From), balanceCheck; have.Cmp(want) < 0 {
-		return fmt.Errorf("%w: address %v have %v want %v", ErrInsufficientFunds, st.msg.From.Hex(), have, want)
+		sponsor, ok := st.gasSponsor(balanceCheck)
+		if !ok {
+			return fmt.Errorf("%w: address %v have %v want %v", ErrInsufficientFunds, st.msg.From.Hex(), have, want)
+		}
+		st.payer = sponsor
 	}
 	if err := st.gp.SubGas(st.msg.GasLimit); err != nil {
 		return err
@@ -319,13 +341,37 @@ func (st *StateTransition) buyGas() error {
 	st.gasRemaining += st.msg.GasLimit
 
 	st.initialGas = st.msg.GasLimit
-	st.state.SubBalance(st.msg.From, mgval)
+	st.state.SubBalance(st.payer, mgval)
 	return nil
 }
 
+// gasSponsor returns the contract paying for the gas of the message, if gas
+// sponsorship is active, the sender can pay the value of the message and the
+// sponsor contract can pay [balanceCheck] less the value and agrees to.
+// Messages carrying blobs are never sponsored.
+func (st *StateTransition) gasSponsor(balanceCheck *big.Int) (common.Address, bool) {
+	config := st.evm.ChainConfig()
+	if !config.IsGasSponsorship(st.evm.Context.Time) || st.msg.GasFeeCap == nil || len(st.msg.BlobHashes) != 0 {
+		return common.Address{}, false
+	}
+	sponsor := config.GasSponsorship.Contract
+	gasCheck := new(big.Int).Sub(balanceCheck, st.msg.Value)
+	if st.state.GetBalance(st.msg.From).Cmp(st.msg.Value) < 0 || st.state.GetBalance(sponsor).Cmp(gasCheck) < 0 {
+		return common.Address{}, false
+	}
+	if !IsGasSponsored(st.evm, sponsor, st.msg) {
+		return common.Address{}, false
+	}
+	return sponsor, true
+}
+
 func (st *StateTransition) preCheck() error {
 	// Only check transactions that are not fake
 	msg := st.msg
+	// Make sure custom transaction types are only included once enabled
+	if types.IsCustomTxType(msg.TxType) && !st.evm.ChainConfig().IsTxTypeEnabled(msg.TxType, st.evm.Context.Time) {
+		return fmt.Errorf("%w: type %d not enabled", ErrTxTypeNotSupported, msg.TxType)
+	}
 	if !msg.SkipAccountChecks {
 		// Make sure this transaction's nonce is correct.
 		stNonce := st.state.GetNonce(msg.From)
@@ -349,6 +395,11 @@ func (st *StateTransition) preCheck() error {
 		if vm.IsProhibited(msg.From) {
 			return fmt.Errorf("%w: address %v", vmerrs.ErrAddrProhibited, msg.From)
 		}
+		// Make sure the sender is allow listed if the tx allow list is enabled
+		if st.evm.ChainConfig().IsPrecompileEnabled(txallowlist.ContractAddress, st.evm.Context.Time) &&
+			!txallowlist.GetTxAllowListStatus(st.state, msg.From).IsEnabled() {
+			return fmt.Errorf("%w: address %v", vmerrs.ErrSenderNotAllowListed, msg.From)
+		}
 	}
 	// Make sure that transaction gasFeeCap is greater than the baseFee (post london)
 	if st.evm.ChainConfig().IsApricotPhase3(st.evm.Context.Time) {
@@ -450,6 +501,10 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 	if err != nil {
 		return nil, err
 	}
+	if gas+msg.CustomGas < gas {
+		return nil, ErrGasUintOverflow
+	}
+	gas += msg.CustomGas
 	if st.gasRemaining < gas {
 		return nil, fmt.Errorf("%w: have %d, want %d", ErrIntrinsicGas, st.gasRemaining, gas)
 	}
@@ -461,8 +516,8 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 	}
 
 	// Check whether the init code size has been exceeded.
-	if rules.IsDurango && contractCreation && len(msg.Data) > params.MaxInitCodeSize {
-		return nil, fmt.Errorf("%w: code size %v limit %v", vmerrs.ErrMaxInitCodeSizeExceeded, len(msg.Data), params.MaxInitCodeSize)
+	if rules.IsDurango && contractCreation && len(msg.Data) > rules.MaxInitCodeSize {
+		return nil, fmt.Errorf("%w: code size %v limit %v", vmerrs.ErrMaxInitCodeSizeExceeded, len(msg.Data), rules.MaxInitCodeSize)
 	}
 
 	// Execute the preparatory steps for state transition which includes:
@@ -506,7 +561,7 @@ func (st *StateTransition) refundGas(apricotPhase1 bool) uint64 {
 
 	// Return ETH for remaining gas, exchanged at the original rate.
 	remaining := new(big.Int).Mul(new(big.Int).SetUint64(st.gasRemaining), st.msg.GasPrice)
-	st.state.AddBalance(st.msg.From, remaining)
+	st.state.AddBalance(st.payer, remaining)
 
 	// Also return remaining gas to the block gas counter so it is
 	// available for the next transaction.
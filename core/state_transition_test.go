@@ -27,13 +27,17 @@
 package core
 
 import (
+	"bytes"
 	"encoding/hex"
+	"errors"
 	"math/big"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/crypto"
 	ethCrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/shubhamdubey02/coreth/consensus/dummy"
 	"github.com/shubhamdubey02/coreth/core/rawdb"
 	"github.com/shubhamdubey02/coreth/core/state"
@@ -263,3 +267,157 @@ func TestNativeAssetDirectEOACall(t *testing.T) {
 		})
 	}
 }
+
+const testCustomTxType = 0x7c
+
+var errTestCustomTxValue = errors.New("value must be zero")
+
+// testCustomTx is a custom transaction type with the fields of a dynamic fee
+// transaction.
+type testCustomTx struct{ tx types.DynamicFeeTx }
+
+func (t *testCustomTx) Copy() types.CustomTxData {
+	cpy := new(testCustomTx)
+	enc, _ := rlp.EncodeToBytes(&t.tx)
+	_ = rlp.DecodeBytes(enc, &cpy.tx)
+	return cpy
+}
+func (t *testCustomTx) ChainID() *big.Int            { return t.tx.ChainID }
+func (t *testCustomTx) AccessList() types.AccessList { return t.tx.AccessList }
+func (t *testCustomTx) Data() []byte                 { return t.tx.Data }
+func (t *testCustomTx) Gas() uint64                  { return t.tx.Gas }
+func (t *testCustomTx) GasPrice() *big.Int           { return t.tx.GasFeeCap }
+func (t *testCustomTx) GasTipCap() *big.Int          { return t.tx.GasTipCap }
+func (t *testCustomTx) GasFeeCap() *big.Int          { return t.tx.GasFeeCap }
+func (t *testCustomTx) Value() *big.Int              { return t.tx.Value }
+func (t *testCustomTx) Nonce() uint64                { return t.tx.Nonce }
+func (t *testCustomTx) To() *common.Address          { return t.tx.To }
+
+func (t *testCustomTx) RawSignatureValues() (v, r, s *big.Int) {
+	return t.tx.V, t.tx.R, t.tx.S
+}
+
+func (t *testCustomTx) SetSignatureValues(chainID, v, r, s *big.Int) {
+	t.tx.ChainID, t.tx.V, t.tx.R, t.tx.S = chainID, v, r, s
+}
+
+func (t *testCustomTx) EffectiveGasPrice(dst *big.Int, baseFee *big.Int) *big.Int {
+	if baseFee == nil {
+		return dst.Set(t.tx.GasFeeCap)
+	}
+	return dst.Set(math.BigMin(dst.Add(t.tx.GasTipCap, baseFee), t.tx.GasFeeCap))
+}
+
+func (t *testCustomTx) Encode(b *bytes.Buffer) error { return rlp.Encode(b, &t.tx) }
+func (t *testCustomTx) Decode(b []byte) error        { return rlp.DecodeBytes(b, &t.tx) }
+
+func init() {
+	err := types.RegisterTxType(types.CustomTxType{
+		Type: testCustomTxType,
+		New:  func() types.CustomTxData { return new(testCustomTx) },
+		Validate: func(data types.CustomTxData) error {
+			if data.Value().Sign() != 0 {
+				return errTestCustomTxValue
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// TestCustomTxValidation checks that the validation rules of custom
+// transaction types are enforced on the transactions of blocks, and not
+// only by the transaction pool.
+func TestCustomTxValidation(t *testing.T) {
+	require := require.New(t)
+
+	key, err := crypto.GenerateKey()
+	require.NoError(err)
+	signer := types.LatestSigner(params.TestChainConfig)
+	newTx := func(value int64) *types.Transaction {
+		tx, err := types.NewCustomTx(testCustomTxType, &testCustomTx{tx: types.DynamicFeeTx{
+			ChainID:   params.TestChainConfig.ChainID,
+			GasTipCap: big.NewInt(1),
+			GasFeeCap: big.NewInt(1),
+			Gas:       params.TxGas,
+			To:        &common.Address{0x01},
+			Value:     big.NewInt(value),
+		}})
+		require.NoError(err)
+		tx, err = types.SignTx(tx, signer, key)
+		require.NoError(err)
+		return tx
+	}
+
+	_, err = TransactionToMessage(newTx(0), signer, nil)
+	require.NoError(err)
+	_, err = TransactionToMessage(newTx(1), signer, nil)
+	require.ErrorIs(err, ErrInvalidCustomTx)
+}
+
+// TestGasSponsorship checks that the gas of a message the sender cannot afford
+// is charged to the gas sponsor contract if it agrees to pay it.
+func TestGasSponsorship(t *testing.T) {
+	var (
+		sender   = common.HexToAddress("0x1001")
+		sponsor  = common.HexToAddress("0x1002")
+		refuser  = common.HexToAddress("0x1003")
+		to       = common.HexToAddress("0x1004")
+		balance  = big.NewInt(1_000_000)
+		accepted = common.FromHex("0x600160005260206000f3") // returns true
+		refused  = common.FromHex("0x600060005260206000f3") // returns false
+	)
+	tests := []struct {
+		name     string
+		contract common.Address
+		value    int64
+		err      error
+	}{
+		{name: "sponsored", contract: sponsor, value: 1},
+		{name: "refused", contract: refuser, value: 1, err: ErrInsufficientFunds},
+		{name: "unaffordable value", contract: sponsor, value: 2, err: ErrInsufficientFunds},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			config := *params.TestChainConfig
+			config.GasSponsorship = &params.GasSponsorshipConfig{Contract: test.contract}
+			statedb, err := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+			require.NoError(err)
+			statedb.SetBalance(sender, big.NewInt(1))
+			statedb.SetCode(sponsor, accepted)
+			statedb.SetBalance(sponsor, balance)
+			statedb.SetCode(refuser, refused)
+			statedb.SetBalance(refuser, balance)
+
+			blockContext := vm.BlockContext{
+				CanTransfer: CanTransfer,
+				Transfer:    Transfer,
+				BlockNumber: big.NewInt(1),
+				BaseFee:     big.NewInt(1),
+				GasLimit:    params.TxGas,
+			}
+			msg := &Message{
+				From:      sender,
+				To:        &to,
+				Value:     big.NewInt(test.value),
+				GasLimit:  params.TxGas,
+				GasPrice:  big.NewInt(1),
+				GasFeeCap: big.NewInt(1),
+				GasTipCap: big.NewInt(0),
+			}
+			evm := vm.NewEVM(blockContext, NewEVMTxContext(msg), statedb, &config, vm.Config{})
+			_, err = ApplyMessage(evm, msg, new(GasPool).AddGas(params.TxGas))
+			require.ErrorIs(err, test.err)
+			if test.err != nil {
+				return
+			}
+			require.Zero(statedb.GetBalance(sender).Sign())
+			require.Equal(big.NewInt(1), statedb.GetBalance(to))
+			require.Equal(new(big.Int).Sub(balance, big.NewInt(int64(params.TxGas))), statedb.GetBalance(sponsor))
+		})
+	}
+}
@@ -0,0 +1,167 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/shubhamdubey02/coreth/core/rawdb"
+	"github.com/shubhamdubey02/coreth/core/state"
+	"github.com/shubhamdubey02/coreth/core/types"
+)
+
+// ApplyStorageExpiry clears the storage slots expiring in the transition from
+// [parent] to a block at [time], if it starts a new storage expiry epoch.
+//
+// A slot last accessed in epoch e expires at the start of epoch
+// e+ExpiryEpochs+1. Accesses are read from the index of accepted blocks, and
+// from the pending accesses of the ancestors of the block not indexed yet, so
+// that every node observes the same accesses regardless of its progress
+// accepting blocks.
+func ApplyStorageExpiry(bc *BlockChain, parent *types.Header, time uint64, statedb *state.StateDB) error {
+	config := bc.Config()
+	if !config.IsStorageExpiry(parent.Time) {
+		return nil
+	}
+	var (
+		expiry      = config.StorageExpiry
+		parentEpoch = expiry.Epoch(parent.Time)
+		epoch       = expiry.Epoch(time)
+	)
+	if epoch == parentEpoch || epoch <= expiry.ExpiryEpochs {
+		return nil
+	}
+	// Slots last accessed in epochs [from, to] expire with this block, the
+	// earlier epochs having expired with its ancestors.
+	var from uint64
+	if parentEpoch > expiry.ExpiryEpochs {
+		from = parentEpoch - expiry.ExpiryEpochs
+	}
+	to := epoch - expiry.ExpiryEpochs - 1
+
+	pending, err := bc.pendingStorageAccesses(parent)
+	if err != nil {
+		return err
+	}
+	var expired int
+	// expire clears [slot] of [addr] last accessed in [bucket], unless it was
+	// accessed in a later epoch by an indexed block.
+	expire := func(addr common.Address, slot common.Hash, bucket uint64) error {
+		if !statedb.Exist(addr) {
+			return nil
+		}
+		accessed, err := rawdb.HasStorageAccess(bc.db, addr, slot, bucket+1, parentEpoch)
+		if err != nil {
+			return fmt.Errorf("failed to read storage accesses of %s: %w", addr, err)
+		}
+		if !accessed {
+			statedb.SetState(addr, slot, common.Hash{})
+			expired++
+		}
+		return nil
+	}
+	for bucket := from; bucket <= to; bucket++ {
+		// The index of an epoch may be arbitrarily large, so it is streamed
+		// instead of being collected. Slots also accessed by pending blocks
+		// are expired below, if they were not accessed after [bucket].
+		var expireErr error
+		err := rawdb.IterateEpochStorageAccesses(bc.db, bucket, func(addr common.Address, slot common.Hash) bool {
+			if last, ok := pending[addr][slot]; ok && last >= bucket {
+				return true
+			}
+			expireErr = expire(addr, slot, bucket)
+			return expireErr == nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to iterate storage accesses of epoch %d: %w", bucket, err)
+		}
+		if expireErr != nil {
+			return expireErr
+		}
+		for addr, slots := range pending {
+			for slot, last := range slots {
+				if last != bucket {
+					continue
+				}
+				if err := expire(addr, slot, bucket); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if expired > 0 {
+		log.Debug("Expired storage slots", "epoch", epoch, "slots", expired)
+	}
+	return nil
+}
+
+// pendingStorageAccesses returns the last epoch each storage slot was accessed
+// in by the ancestors of the block following [parent] not indexed yet.
+func (bc *BlockChain) pendingStorageAccesses(parent *types.Header) (map[common.Address]map[common.Hash]uint64, error) {
+	// The acceptor tip is read before the index, which then covers at least
+	// the accepted blocks up to the tip. Blocks indexed after the tip was read
+	// are read from both.
+	tip, err := rawdb.ReadAcceptorTip(bc.db)
+	if err != nil {
+		return nil, err
+	}
+	var tipNumber uint64
+	if number := rawdb.ReadHeaderNumber(bc.db, tip); number != nil {
+		tipNumber = *number
+	}
+	var (
+		expiry  = bc.chainConfig.StorageExpiry
+		pending = make(map[common.Address]map[common.Hash]uint64)
+	)
+	for header := parent; header != nil && header.Number.Uint64() > tipNumber; header = bc.GetHeader(header.ParentHash, header.Number.Uint64()-1) {
+		if !bc.chainConfig.IsStorageExpiry(header.Time) {
+			break
+		}
+		epoch := expiry.Epoch(header.Time)
+		for _, accesses := range rawdb.ReadBlockStorageAccesses(bc.db, header.Hash(), header.Number.Uint64()) {
+			slots, ok := pending[accesses.Address]
+			if !ok {
+				slots = make(map[common.Hash]uint64, len(accesses.Slots))
+				pending[accesses.Address] = slots
+			}
+			for _, slot := range accesses.Slots {
+				slots[slot] = max(slots[slot], epoch)
+			}
+		}
+	}
+	return pending, nil
+}
+
+// storageAccessesList converts the storage slots accessed by a block to their
+// stored representation, in a deterministic order.
+func storageAccessesList(accesses map[common.Address]map[common.Hash]struct{}) []rawdb.SlotAccesses {
+	list := make([]rawdb.SlotAccesses, 0, len(accesses))
+	for addr, slots := range accesses {
+		entry := rawdb.SlotAccesses{Address: addr, Slots: make([]common.Hash, 0, len(slots))}
+		for slot := range slots {
+			entry.Slots = append(entry.Slots, slot)
+		}
+		slices.SortFunc(entry.Slots, func(a, b common.Hash) int { return a.Cmp(b) })
+		list = append(list, entry)
+	}
+	slices.SortFunc(list, func(a, b rawdb.SlotAccesses) int { return a.Address.Cmp(b.Address) })
+	return list
+}
+
+// indexStorageAccesses moves the pending storage slots accessed by the
+// accepted block [b] to the storage expiry index.
+func (bc *BlockChain) indexStorageAccesses(batch ethdb.Batch, b *types.Block) {
+	expiry := bc.chainConfig.StorageExpiry
+	epoch := expiry.Epoch(b.Time())
+	for _, accesses := range rawdb.ReadBlockStorageAccesses(bc.db, b.Hash(), b.NumberU64()) {
+		for _, slot := range accesses.Slots {
+			rawdb.WriteStorageAccess(batch, accesses.Address, slot, epoch)
+		}
+	}
+	rawdb.DeleteBlockStorageAccesses(batch, b.Hash(), b.NumberU64())
+}
@@ -1477,6 +1477,60 @@ func (pool *BlobPool) IteratePending(f func(tx *types.Transaction) bool) bool {
 
 func (p *BlobPool) SetMinFee(minFee *big.Int) {}
 
+// SetSponsor is a no-op, as blob transactions cannot be sponsored.
+func (p *BlobPool) SetSponsor(sponsor txpool.Sponsor) {}
+
+// IsSponsored returns false, as blob transactions cannot be sponsored.
+func (p *BlobPool) IsSponsored(hash common.Hash) bool { return false }
+
+// Remove drops a transaction from the pool, along with the transactions of the
+// same sender with higher nonces, as the pool does not allow nonce gaps. It
+// returns false if the pool does not hold the transaction.
+func (p *BlobPool) Remove(hash common.Hash) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, ok := p.lookup[hash]; !ok {
+		return false
+	}
+	for addr, txs := range p.index {
+		for i, tx := range txs {
+			if tx.hash != hash {
+				continue
+			}
+			// Drop the transaction and everything afterwards, no gaps allowed
+			ids := make([]uint64, 0, len(txs)-i)
+			for j, tx := range txs[i:] {
+				ids = append(ids, tx.id)
+
+				p.spent[addr] = new(uint256.Int).Sub(p.spent[addr], tx.costCap)
+				p.stored -= uint64(tx.size)
+				delete(p.lookup, tx.hash)
+				txs[i+j] = nil
+			}
+			if i > 0 {
+				p.index[addr] = txs[:i]
+				heap.Fix(p.evict, p.evict.index[addr])
+			} else {
+				delete(p.index, addr)
+				delete(p.spent, addr)
+
+				heap.Remove(p.evict, p.evict.index[addr])
+				p.reserve(addr, false)
+			}
+			log.Debug("Removing blob transaction", "from", addr, "hash", hash, "ids", ids)
+			for _, id := range ids {
+				if err := p.store.Delete(id); err != nil {
+					log.Error("Failed to delete removed transaction", "id", id, "err", err)
+				}
+			}
+			p.updateStorageMetrics()
+			return true
+		}
+	}
+	return false
+}
+
 // updateStorageMetrics retrieves a bunch of stats from the data store and pushes
 // them out as metrics.
 func (p *BlobPool) updateStorageMetrics() {
@@ -26,7 +26,11 @@
 
 package txpool
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/shubhamdubey02/coreth/core"
+)
 
 var (
 	// ErrAlreadyKnown is returned if the transactions is already contained
@@ -64,4 +68,8 @@ var (
 	// ErrFutureReplacePending is returned if a future transaction replaces a pending
 	// one. Future transactions should only be able to replace other future transactions.
 	ErrFutureReplacePending = errors.New("future transaction tries to replace pending")
+
+	// ErrInvalidCustomTx is returned if a transaction of a custom type fails
+	// the validation rules registered for the type.
+	ErrInvalidCustomTx = core.ErrInvalidCustomTx
 )
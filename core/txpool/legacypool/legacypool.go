@@ -149,6 +149,8 @@ type Config struct {
 	AccountQueue uint64 // Maximum number of non-executable transaction slots permitted per account
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
+	SponsoredSlots uint64 // Maximum number of transaction slots for all accounts whose gas is paid by a sponsor
+
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
 }
 
@@ -167,6 +169,8 @@ var DefaultConfig = Config{
 	AccountQueue: 64,
 	GlobalQueue:  1024,
 
+	SponsoredSlots: 256,
+
 	Lifetime: 10 * time.Minute,
 }
 
@@ -202,6 +206,10 @@ func (config *Config) sanitize() Config {
 		log.Warn("Sanitizing invalid txpool global queue", "provided", conf.GlobalQueue, "updated", DefaultConfig.GlobalQueue)
 		conf.GlobalQueue = DefaultConfig.GlobalQueue
 	}
+	if conf.SponsoredSlots < 1 {
+		log.Warn("Sanitizing invalid txpool sponsored slots", "provided", conf.SponsoredSlots, "updated", DefaultConfig.SponsoredSlots)
+		conf.SponsoredSlots = DefaultConfig.SponsoredSlots
+	}
 	if conf.Lifetime < 1 {
 		log.Warn("Sanitizing invalid txpool lifetime", "provided", conf.Lifetime, "updated", DefaultConfig.Lifetime)
 		conf.Lifetime = DefaultConfig.Lifetime
@@ -222,10 +230,14 @@ type LegacyPool struct {
 	chain       BlockChain
 	gasTip      atomic.Pointer[big.Int]
 	minimumFee  *big.Int
+	sponsor     txpool.Sponsor
 	txFeed      event.Feed
 	signer      types.Signer
 	mu          sync.RWMutex
 
+	sponsorCache map[common.Hash]bool     // Sponsor decisions made against the current state
+	sponsoredTxs map[common.Hash]struct{} // Pooled transactions whose gas is paid by the sponsor
+
 	// [currentStateLock] is required to allow concurrent access to address nonces
 	// and balances during reorgs and gossip handling.
 	currentStateLock sync.Mutex
@@ -306,7 +318,7 @@ func (pool *LegacyPool) Filter(tx *types.Transaction) bool {
 	case types.LegacyTxType, types.AccessListTxType, types.DynamicFeeTxType:
 		return true
 	default:
-		return false
+		return types.IsCustomTxType(tx.Type())
 	}
 }
 
@@ -487,6 +499,76 @@ func (pool *LegacyPool) SetMinFee(minFee *big.Int) {
 	pool.minimumFee = minFee
 }
 
+// SetSponsor sets the sponsor consulted to admit transactions whose gas is
+// not paid by their sender.
+func (pool *LegacyPool) SetSponsor(sponsor txpool.Sponsor) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	pool.sponsor = sponsor
+	pool.sponsorCache = make(map[common.Hash]bool)
+	pool.sponsoredTxs = make(map[common.Hash]struct{})
+}
+
+// IsSponsored returns true if the gas of the pooled transaction [hash] is
+// paid by the sponsor. Such transactions are not gossiped, as peers cannot
+// be expected to admit them.
+func (pool *LegacyPool) IsSponsored(hash common.Hash) bool {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	_, ok := pool.sponsoredTxs[hash]
+	return ok
+}
+
+// sponsored returns a callback checking whether the gas of transactions sent
+// by [addr] is paid by the sponsor, or nil if there is no sponsor. The pool
+// lock and the current state lock must be held.
+func (pool *LegacyPool) sponsored(addr common.Address) func(*types.Transaction) bool {
+	if pool.sponsor == nil {
+		return nil
+	}
+	return func(tx *types.Transaction) bool {
+		return pool.isSponsored(addr, tx)
+	}
+}
+
+// isSponsored returns true if the gas of [tx] sent by [addr] is paid by the
+// sponsor, and the transaction fits in the sponsored slots. The sponsor is
+// consulted at most once per transaction until the next reset. The pool lock
+// and the current state lock must be held.
+func (pool *LegacyPool) isSponsored(addr common.Address, tx *types.Transaction) bool {
+	hash := tx.Hash()
+	if sponsored, ok := pool.sponsorCache[hash]; ok {
+		return sponsored
+	}
+	_, pooled := pool.sponsoredTxs[hash]
+	sponsored := (pooled || uint64(len(pool.sponsoredTxs)) < pool.config.SponsoredSlots) &&
+		pool.sponsor.Sponsored(pool.currentState, pool.currentHead.Load(), addr, tx)
+	pool.sponsorCache[hash] = sponsored
+	if sponsored {
+		pool.sponsoredTxs[hash] = struct{}{}
+	} else {
+		delete(pool.sponsoredTxs, hash)
+	}
+	return sponsored
+}
+
+// resetSponsored discards the sponsor decisions made against the previous
+// state, and releases the sponsored slots of the transactions that left the
+// pool. The pool lock must be held.
+func (pool *LegacyPool) resetSponsored() {
+	if pool.sponsor == nil {
+		return
+	}
+	pool.sponsorCache = make(map[common.Hash]bool)
+	for hash := range pool.sponsoredTxs {
+		if pool.all.Get(hash) == nil {
+			delete(pool.sponsoredTxs, hash)
+		}
+	}
+}
+
 // Nonce returns the next nonce of an account, with all transactions executable
 // by the pool already applied on top.
 func (pool *LegacyPool) Nonce(addr common.Address) uint64 {
@@ -651,19 +733,21 @@ func (pool *LegacyPool) local() map[common.Address]types.Transactions {
 // This check is meant as an early check which only needs to be performed once,
 // and does not require the pool mutex to be held.
 func (pool *LegacyPool) validateTxBasics(tx *types.Transaction, local bool) error {
+	head := pool.currentHead.Load()
 	opts := &txpool.ValidationOptions{
 		Config: pool.chainconfig,
 		Accept: 0 |
 			1<<types.LegacyTxType |
 			1<<types.AccessListTxType |
 			1<<types.DynamicFeeTxType,
-		MaxSize: txMaxSize,
-		MinTip:  pool.gasTip.Load(),
+		AcceptCustom: true,
+		MaxSize:      pool.maxTxSize(head),
+		MinTip:       pool.gasTip.Load(),
 	}
 	if local {
 		opts.MinTip = new(big.Int)
 	}
-	if err := txpool.ValidateTransaction(tx, pool.currentHead.Load(), pool.signer, opts); err != nil {
+	if err := txpool.ValidateTransaction(tx, head, pool.signer, opts); err != nil {
 		return err
 	}
 	return nil
@@ -709,6 +793,9 @@ func (pool *LegacyPool) validateTx(tx *types.Transaction, local bool) error {
 			return nil
 		},
 	}
+	if pool.sponsor != nil {
+		opts.Sponsored = pool.isSponsored
+	}
 	if err := txpool.ValidateTransactionWithState(tx, pool.signer, opts); err != nil {
 		return err
 	}
@@ -1126,6 +1213,20 @@ func (pool *LegacyPool) Get(hash common.Hash) *types.Transaction {
 	return tx
 }
 
+// Remove drops a transaction from the pool, moving the transactions of the
+// same sender depending on it back to the future queue. It returns false if
+// the pool does not hold the transaction.
+func (pool *LegacyPool) Remove(hash common.Hash) bool {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if pool.all.Get(hash) == nil {
+		return false
+	}
+	pool.removeTx(hash, true, true)
+	return true
+}
+
 // get returns a transaction if it is contained in the pool and nil otherwise.
 func (pool *LegacyPool) get(hash common.Hash) *types.Transaction {
 	return pool.all.Get(hash)
@@ -1491,6 +1592,7 @@ func (pool *LegacyPool) reset(oldHead, newHead *types.Header) {
 	pool.currentState = statedb
 	pool.currentStateLock.Unlock()
 	pool.pendingNonces = newNoncer(statedb)
+	pool.resetSponsored()
 
 	// Inject any transactions discarded due to reorgs
 	log.Debug("Reinjecting stale transactions", "count", len(reinject))
@@ -1523,7 +1625,7 @@ func (pool *LegacyPool) promoteExecutables(accounts []common.Address) []*types.T
 		}
 		log.Trace("Removed old queued transactions", "count", len(forwards))
 		// Drop all transactions that are too costly (low balance or out of gas)
-		drops, _ := list.Filter(pool.currentState.GetBalance(addr), gasLimit)
+		drops, _ := list.Filter(pool.currentState.GetBalance(addr), gasLimit, pool.sponsored(addr))
 		for _, tx := range drops {
 			hash := tx.Hash()
 			pool.all.Remove(hash)
@@ -1727,7 +1829,7 @@ func (pool *LegacyPool) demoteUnexecutables() {
 			log.Trace("Removed old pending transaction", "hash", hash)
 		}
 		// Drop all transactions that are too costly (low balance or out of gas), and queue any invalids back for later
-		drops, invalids := list.Filter(pool.currentState.GetBalance(addr), gasLimit)
+		drops, invalids := list.Filter(pool.currentState.GetBalance(addr), gasLimit, pool.sponsored(addr))
 		for _, tx := range drops {
 			hash := tx.Hash()
 			log.Trace("Removed unpayable pending transaction", "hash", hash)
@@ -2080,6 +2182,12 @@ func (t *lookup) RemotesBelowTip(threshold *big.Int) types.Transactions {
 	return found
 }
 
+// maxTxSize returns the maximum size of a transaction, raised from txMaxSize
+// when the chain config allows initcode that would not fit in it.
+func (pool *LegacyPool) maxTxSize(head *types.Header) uint64 {
+	return max(txMaxSize, uint64(pool.chainconfig.MaxInitCodeSize(head.Time))+txSlotSize)
+}
+
 // numSlots calculates the number of slots needed for a single transaction.
 func numSlots(tx *types.Transaction) int {
 	return int((tx.Size() + txSlotSize - 1) / txSlotSize)
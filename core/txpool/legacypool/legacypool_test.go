@@ -380,6 +380,109 @@ func TestInvalidTransactions(t *testing.T) {
 	}
 }
 
+// testSponsor sponsors the transactions with the gas limits it is set up with.
+type testSponsor map[uint64]bool
+
+func (s testSponsor) Sponsored(_ *state.StateDB, _ *types.Header, _ common.Address, tx *types.Transaction) bool {
+	return s[tx.Gas()]
+}
+
+// Tests that transactions the transactor cannot pay the gas of are admitted
+// and kept across resets if they are sponsored.
+func TestSponsoredTransactions(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPool()
+	defer pool.Close()
+	pool.SetSponsor(testSponsor{100000: true})
+
+	account := crypto.PubkeyToAddress(key.PublicKey)
+
+	// The transactor must still pay the value of sponsored transactions
+	if err, want := pool.addRemoteSync(transaction(0, 100000, key)), core.ErrInsufficientFunds; !errors.Is(err, want) {
+		t.Errorf("want %v have %v", want, err)
+	}
+	testAddBalance(pool, account, big.NewInt(100))
+	if err := pool.addRemoteSync(transaction(0, 100000, key)); err != nil {
+		t.Errorf("sponsored transaction rejected: %v", err)
+	}
+	if err, want := pool.addRemoteSync(transaction(1, 200000, key)), core.ErrInsufficientFunds; !errors.Is(err, want) {
+		t.Errorf("want %v have %v", want, err)
+	}
+
+	<-pool.requestReset(nil, nil)
+	if pool.pending[account].Len() != 1 {
+		t.Errorf("pending transaction mismatch: have %d, want %d", pool.pending[account].Len(), 1)
+	}
+	// Sponsored transactions are dropped once the transactor cannot pay their value
+	testAddBalance(pool, account, big.NewInt(-1))
+	<-pool.requestReset(nil, nil)
+	if pool.pending[account] != nil {
+		t.Errorf("pending transaction mismatch: have %d, want %d", pool.pending[account].Len(), 0)
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// countingSponsor is a testSponsor counting the times it is consulted.
+type countingSponsor struct {
+	testSponsor
+	calls int
+}
+
+func (s *countingSponsor) Sponsored(statedb *state.StateDB, head *types.Header, from common.Address, tx *types.Transaction) bool {
+	s.calls++
+	return s.testSponsor.Sponsored(statedb, head, from, tx)
+}
+
+// Tests that the sponsor is consulted once per transaction, that sponsored
+// transactions are limited to the sponsored slots, and that they are tracked
+// so that they are not gossiped.
+func TestSponsoredTransactionsLimit(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPool()
+	defer pool.Close()
+	pool.config.SponsoredSlots = 1
+	sponsor := &countingSponsor{testSponsor: testSponsor{100000: true}}
+	pool.SetSponsor(sponsor)
+
+	other, _ := crypto.GenerateKey()
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(100))
+	testAddBalance(pool, crypto.PubkeyToAddress(other.PublicKey), big.NewInt(100))
+
+	tx := transaction(0, 100000, key)
+	if err := pool.addRemoteSync(tx); err != nil {
+		t.Fatalf("sponsored transaction rejected: %v", err)
+	}
+	if !pool.IsSponsored(tx.Hash()) {
+		t.Fatal("transaction not tracked as sponsored")
+	}
+	if sponsor.calls != 1 {
+		t.Fatalf("sponsor calls mismatch: have %d, want %d", sponsor.calls, 1)
+	}
+	// The sponsored slots are full, so the sponsor is not consulted
+	if err, want := pool.addRemoteSync(transaction(0, 100000, other)), core.ErrInsufficientFunds; !errors.Is(err, want) {
+		t.Errorf("want %v have %v", want, err)
+	}
+	if sponsor.calls != 1 {
+		t.Fatalf("sponsor calls mismatch: have %d, want %d", sponsor.calls, 1)
+	}
+	// Slots are released once the sponsored transactions leave the pool
+	pool.Remove(tx.Hash())
+	<-pool.requestReset(nil, nil)
+	if pool.IsSponsored(tx.Hash()) {
+		t.Fatal("removed transaction tracked as sponsored")
+	}
+	if err := pool.addRemoteSync(transaction(0, 100000, other)); err != nil {
+		t.Fatalf("sponsored transaction rejected: %v", err)
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 func TestQueue(t *testing.T) {
 	t.Parallel()
 
@@ -2697,3 +2800,45 @@ func BenchmarkMultiAccountBatchInsert(b *testing.B) {
 		pool.addRemotesSync([]*types.Transaction{tx})
 	}
 }
+
+// Tests that transactions can be removed through the main pool, and that the
+// transactions depending on them are moved back to the queue.
+func TestTxPoolRemove(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := newTestBlockChain(params.TestChainConfig, 10000000, statedb, new(event.Feed))
+
+	legacyPool := New(testTxPoolConfig, blockchain)
+	pool, err := txpool.New(new(big.Int).SetUint64(testTxPoolConfig.PriceLimit), blockchain, []txpool.SubPool{legacyPool})
+	if err != nil {
+		t.Fatalf("failed to create pool: %v", err)
+	}
+	defer pool.Close()
+	<-legacyPool.initDoneCh
+
+	key, _ := crypto.GenerateKey()
+	testAddBalance(legacyPool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+
+	txs := []*types.Transaction{transaction(0, 100000, key), transaction(1, 100000, key)}
+	for i, err := range pool.Add(txs, true, true) {
+		if err != nil {
+			t.Fatalf("failed to add transaction %d: %v", i, err)
+		}
+	}
+	if !pool.Remove(txs[0].Hash()) {
+		t.Fatalf("failed to remove pooled transaction")
+	}
+	if pool.Has(txs[0].Hash()) {
+		t.Fatalf("removed transaction still pooled")
+	}
+	if pending, queued := legacyPool.Stats(); pending != 0 || queued != 1 {
+		t.Fatalf("pool stats mismatch: have %d pending and %d queued, want 0 and 1", pending, queued)
+	}
+	if pool.Remove(txs[0].Hash()) {
+		t.Fatalf("removed transaction not held by the pool")
+	}
+	if err := validatePoolInternals(legacyPool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
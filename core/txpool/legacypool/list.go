@@ -359,13 +359,14 @@ func (l *list) Forward(threshold uint64) types.Transactions {
 // Filter removes all transactions from the list with a cost or gas limit higher
 // than the provided thresholds. Every removed transaction is returned for any
 // post-removal maintenance. Strict-mode invalidated transactions are also
-// returned.
+// returned. If [sponsored] is set, transactions above the cost threshold are
+// kept if their gas is sponsored and their value is within the threshold.
 //
 // This method uses the cached costcap and gascap to quickly decide if there's even
 // a point in calculating all the costs or if the balance covers all. If the threshold
 // is lower than the costgas cap, the caps will be reset to a new high after removing
 // the newly invalidated transactions.
-func (l *list) Filter(costLimit *big.Int, gasLimit uint64) (types.Transactions, types.Transactions) {
+func (l *list) Filter(costLimit *big.Int, gasLimit uint64, sponsored func(*types.Transaction) bool) (types.Transactions, types.Transactions) {
 	// If all transactions are below the threshold, short circuit
 	if l.costcap.Cmp(costLimit) <= 0 && l.gascap <= gasLimit {
 		return nil, nil
@@ -373,9 +374,16 @@ func (l *list) Filter(costLimit *big.Int, gasLimit uint64) (types.Transactions,
 	l.costcap = new(big.Int).Set(costLimit) // Lower the caps to the thresholds
 	l.gascap = gasLimit
 
-	// Filter out all the transactions above the account's funds
+	// Filter out all the transactions above the account's funds, unless their
+	// gas is paid by a sponsor
 	removed := l.txs.Filter(func(tx *types.Transaction) bool {
-		return tx.Gas() > gasLimit || tx.Cost().Cmp(costLimit) > 0
+		if tx.Gas() > gasLimit {
+			return true
+		}
+		if tx.Cost().Cmp(costLimit) <= 0 {
+			return false
+		}
+		return sponsored == nil || tx.Value().Cmp(costLimit) > 0 || !sponsored(tx)
 	})
 
 	if len(removed) == 0 {
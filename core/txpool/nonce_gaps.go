@@ -0,0 +1,102 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txpool
+
+import (
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/metrics"
+)
+
+const (
+	// nonceGapReportInterval is the interval at which the pool looks for
+	// accounts whose queued transactions are blocked by a nonce gap.
+	nonceGapReportInterval = time.Minute
+
+	// nonceGapReportThreshold is the number of queued transactions an
+	// account's nonce gap must block to be reported.
+	nonceGapReportThreshold = 16
+)
+
+var nonceGapsGauge = metrics.NewRegisteredGauge("txpool/noncegaps", nil)
+
+// NonceGap is a range of nonces missing from the transactions of an account,
+// which prevents its queued transactions from becoming executable.
+type NonceGap struct {
+	Address common.Address
+	First   uint64 // First missing nonce, the next nonce of the account
+	Last    uint64 // Last missing nonce
+	Blocked int    // Number of queued transactions blocked by the gap
+}
+
+// FindNonceGap returns the nonce gap of [addr], whose next nonce is [nonce]
+// and whose queued transactions sorted by nonce are [queued], or nil if the
+// first queued transaction is not blocked by a gap.
+func FindNonceGap(addr common.Address, nonce uint64, queued []*types.Transaction) *NonceGap {
+	for i, tx := range queued {
+		if tx.Nonce() < nonce {
+			continue
+		}
+		if tx.Nonce() == nonce {
+			return nil
+		}
+		return &NonceGap{
+			Address: addr,
+			First:   nonce,
+			Last:    tx.Nonce() - 1,
+			Blocked: len(queued) - i,
+		}
+	}
+	return nil
+}
+
+// NonceGaps returns the nonce gaps blocking at least [minBlocked] queued
+// transactions, by decreasing number of blocked transactions.
+func (p *TxPool) NonceGaps(minBlocked int) []*NonceGap {
+	_, queued := p.Content()
+
+	var gaps []*NonceGap
+	for addr, txs := range queued {
+		if len(txs) < minBlocked {
+			continue
+		}
+		if gap := FindNonceGap(addr, p.Nonce(addr), txs); gap != nil && gap.Blocked >= minBlocked {
+			gaps = append(gaps, gap)
+		}
+	}
+	sort.Slice(gaps, func(i, j int) bool {
+		if gaps[i].Blocked != gaps[j].Blocked {
+			return gaps[i].Blocked > gaps[j].Blocked
+		}
+		return gaps[i].Address.Cmp(gaps[j].Address) < 0
+	})
+	return gaps
+}
+
+// reportNonceGaps warns about the nonce gaps blocking many queued
+// transactions, once per gap. [reported] holds the first missing nonce of
+// the gaps already reported by account.
+func (p *TxPool) reportNonceGaps(reported map[common.Address]uint64) {
+	gaps := p.NonceGaps(nonceGapReportThreshold)
+	nonceGapsGauge.Update(int64(len(gaps)))
+
+	current := make(map[common.Address]uint64, len(gaps))
+	for _, gap := range gaps {
+		current[gap.Address] = gap.First
+		if first, ok := reported[gap.Address]; ok && first == gap.First {
+			continue
+		}
+		log.Warn("Nonce gap blocks queued transactions", "address", gap.Address, "first", gap.First, "last", gap.Last, "blocked", gap.Blocked)
+	}
+	for addr := range reported {
+		delete(reported, addr)
+	}
+	for addr, first := range current {
+		reported[addr] = first
+	}
+}
@@ -0,0 +1,59 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txpool
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shubhamdubey02/coreth/core/types"
+)
+
+func TestFindNonceGap(t *testing.T) {
+	addr := common.Address{0x01}
+	queued := func(nonces ...uint64) []*types.Transaction {
+		txs := make([]*types.Transaction, len(nonces))
+		for i, nonce := range nonces {
+			txs[i] = types.NewTransaction(nonce, addr, big.NewInt(0), 21000, big.NewInt(1), nil)
+		}
+		return txs
+	}
+	tests := []struct {
+		name   string
+		nonce  uint64
+		queued []*types.Transaction
+		want   *NonceGap
+	}{
+		{
+			name:  "no queued transactions",
+			nonce: 3,
+		},
+		{
+			name:   "executable",
+			nonce:  3,
+			queued: queued(3, 4),
+		},
+		{
+			name:   "gap",
+			nonce:  3,
+			queued: queued(6, 7, 9),
+			want:   &NonceGap{Address: addr, First: 3, Last: 5, Blocked: 3},
+		},
+		{
+			name:   "stale transactions",
+			nonce:  3,
+			queued: queued(1, 2, 5),
+			want:   &NonceGap{Address: addr, First: 3, Last: 4, Blocked: 1},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if have := FindNonceGap(addr, test.nonce, test.queued); !reflect.DeepEqual(have, test.want) {
+				t.Fatalf("have %+v, want %+v", have, test.want)
+			}
+		})
+	}
+}
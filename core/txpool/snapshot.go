@@ -0,0 +1,77 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txpool
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shubhamdubey02/coreth/core/types"
+)
+
+// SnapshotTx is a transaction exported from the pool together with the
+// metadata needed to re-insert it into another pool as it was first seen.
+type SnapshotTx struct {
+	Tx      *types.Transaction
+	Time    time.Time // Time the transaction was first seen by the exporting pool
+	Local   bool      // Whether the sender was treated as local by the exporting pool
+	Pending bool      // Whether the transaction was executable when exported
+}
+
+// Snapshot exports the pending and queued transactions of the pool, so that
+// they can be restored on a standby node with [TxPool.Restore].
+func (p *TxPool) Snapshot() []*SnapshotTx {
+	locals := make(map[common.Address]struct{})
+	for _, addr := range p.Locals() {
+		locals[addr] = struct{}{}
+	}
+	pending, queued := p.Content()
+
+	var snapshot []*SnapshotTx
+	export := func(content map[common.Address][]*types.Transaction, executable bool) {
+		for addr, txs := range content {
+			_, local := locals[addr]
+			for _, tx := range txs {
+				snapshot = append(snapshot, &SnapshotTx{
+					Tx:      tx,
+					Time:    tx.Time(),
+					Local:   local,
+					Pending: executable,
+				})
+			}
+		}
+	}
+	export(pending, true)
+	export(queued, false)
+	return snapshot
+}
+
+// Restore inserts the transactions of a snapshot exported by [TxPool.Snapshot]
+// into the pool, preserving their arrival time and locality. The pool decides
+// afresh whether each transaction is pending or queued against its own state.
+// The returned errors are in the order of [snapshot].
+func (p *TxPool) Restore(snapshot []*SnapshotTx) []error {
+	var (
+		locals, remotes       []*types.Transaction
+		localIdxs, remoteIdxs []int
+	)
+	for i, stx := range snapshot {
+		stx.Tx.SetTime(stx.Time)
+		if stx.Local {
+			locals = append(locals, stx.Tx)
+			localIdxs = append(localIdxs, i)
+		} else {
+			remotes = append(remotes, stx.Tx)
+			remoteIdxs = append(remoteIdxs, i)
+		}
+	}
+	errs := make([]error, len(snapshot))
+	for i, err := range p.Add(locals, true, true) {
+		errs[localIdxs[i]] = err
+	}
+	for i, err := range p.Add(remotes, false, true) {
+		errs[remoteIdxs[i]] = err
+	}
+	return errs
+}
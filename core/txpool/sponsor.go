@@ -0,0 +1,74 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txpool
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shubhamdubey02/coreth/core"
+	"github.com/shubhamdubey02/coreth/core/state"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/core/vm"
+	"github.com/shubhamdubey02/coreth/params"
+)
+
+// Sponsor admits transactions whose gas is paid by a sponsor rather than by
+// their sender, so that relays can use the public mempool on networks whose
+// execution charges the gas of such transactions to the sponsor (see
+// [params.GasSponsorshipConfig]). The sender of a sponsored transaction must
+// still be able to pay its value.
+//
+// Sponsored transactions take up a limited number of slots, and are never
+// gossiped, as they are only admitted by pools consulting the same sponsor.
+type Sponsor interface {
+	// Sponsored returns whether the gas of [tx] sent by [from] is paid by a
+	// sponsor, given the [state] at [head]. Any change made to [state] must
+	// be reverted. It is called with the pool lock held, at most once per
+	// transaction and head.
+	Sponsored(state *state.StateDB, head *types.Header, from common.Address, tx *types.Transaction) bool
+}
+
+// ContractSponsor is a [Sponsor] admitting the transactions that execution
+// charges to the gas sponsor contract of the chain config: those for which the
+// isSponsored view function of the contract returns true, while the contract
+// can pay for their gas.
+type ContractSponsor struct {
+	config *params.ChainConfig
+	chain  core.ChainContext
+}
+
+// NewContractSponsor returns a [ContractSponsor] calling the gas sponsor
+// contract of [config].
+func NewContractSponsor(config *params.ChainConfig, chain core.ChainContext) *ContractSponsor {
+	return &ContractSponsor{
+		config: config,
+		chain:  chain,
+	}
+}
+
+// Sponsored implements [Sponsor] by statically calling the sponsor contract,
+// if gas sponsorship is active for a block built on [head].
+func (s *ContractSponsor) Sponsored(statedb *state.StateDB, head *types.Header, from common.Address, tx *types.Transaction) bool {
+	if tx.Type() == types.BlobTxType || !s.config.IsGasSponsorship(head.Time) {
+		return false
+	}
+	sponsor := s.config.GasSponsorship.Contract
+	gas := new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas()), tx.GasFeeCap())
+	if statedb.GetBalance(sponsor).Cmp(gas) < 0 {
+		return false
+	}
+
+	msg := &core.Message{
+		To:        tx.To(),
+		From:      from,
+		Value:     tx.Value(),
+		GasLimit:  tx.Gas(),
+		GasFeeCap: tx.GasFeeCap(),
+		Data:      tx.Data(),
+	}
+	blockContext := core.NewEVMBlockContext(head, s.chain, &head.Coinbase)
+	evm := vm.NewEVM(blockContext, vm.TxContext{Origin: from, GasPrice: new(big.Int)}, statedb, s.config, vm.Config{NoBaseFee: true})
+	return core.IsGasSponsored(evm, sponsor, msg)
+}
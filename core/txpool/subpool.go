@@ -105,6 +105,14 @@ type SubPool interface {
 	SetGasTip(tip *big.Int)
 	SetMinFee(fee *big.Int)
 
+	// SetSponsor sets the sponsor consulted to admit transactions whose gas
+	// is not paid by their sender.
+	SetSponsor(sponsor Sponsor)
+
+	// IsSponsored returns true if the gas of the pooled transaction with the
+	// given hash is paid by the sponsor.
+	IsSponsored(hash common.Hash) bool
+
 	// Has returns an indicator whether subpool has a transaction cached with the
 	// given hash.
 	Has(hash common.Hash) bool
@@ -113,6 +121,10 @@ type SubPool interface {
 	// Get returns a transaction if it is contained in the pool, or nil otherwise.
 	Get(hash common.Hash) *types.Transaction
 
+	// Remove drops a transaction from the pool, returning false if the pool
+	// does not hold it.
+	Remove(hash common.Hash) bool
+
 	// Add enqueues a batch of transactions into the pool if they are valid. Due
 	// to the large transaction churn, add may postpone fully integrating the tx
 	// to a later point to batch multiple ones together.
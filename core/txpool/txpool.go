@@ -32,6 +32,7 @@ import (
 	"math/big"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/event"
@@ -214,6 +215,13 @@ func (p *TxPool) loop(head *types.Header, newHeadCh <-chan core.ChainHeadEvent)
 		resetBusy = make(chan struct{}, 1) // Allow 1 reset to run concurrently
 		resetDone = make(chan *types.Header)
 	)
+	// Periodically report the nonce gaps blocking many queued transactions
+	var (
+		gapTicker    = time.NewTicker(nonceGapReportInterval)
+		reportedGaps = make(map[common.Address]uint64)
+	)
+	defer gapTicker.Stop()
+
 	var errc chan error
 	for errc == nil {
 		// Something interesting might have happened, run a reset if there is
@@ -247,6 +255,9 @@ func (p *TxPool) loop(head *types.Header, newHeadCh <-chan core.ChainHeadEvent)
 			oldHead = head
 			<-resetBusy
 
+		case <-gapTicker.C:
+			p.reportNonceGaps(reportedGaps)
+
 		case errc = <-p.quit:
 			// Termination requested, break out on the next loop round
 		}
@@ -278,6 +289,26 @@ func (p *TxPool) SetMinFee(fee *big.Int) {
 	}
 }
 
+// SetSponsor sets the sponsor consulted by the subpools to admit transactions
+// whose gas is not paid by their sender.
+func (p *TxPool) SetSponsor(sponsor Sponsor) {
+	for _, subpool := range p.subpools {
+		subpool.SetSponsor(sponsor)
+	}
+}
+
+// IsSponsored returns true if the gas of the pooled transaction with the given
+// hash is paid by the sponsor. Sponsored transactions should not be gossiped,
+// as they are only admitted by the pools consulting the same sponsor.
+func (p *TxPool) IsSponsored(hash common.Hash) bool {
+	for _, subpool := range p.subpools {
+		if subpool.IsSponsored(hash) {
+			return true
+		}
+	}
+	return false
+}
+
 // Has returns an indicator whether the pool has a transaction cached with the
 // given hash.
 func (p *TxPool) Has(hash common.Hash) bool {
@@ -310,6 +341,17 @@ func (p *TxPool) Get(hash common.Hash) *types.Transaction {
 	return nil
 }
 
+// Remove drops a transaction from the pool, returning false if the pool does
+// not hold it.
+func (p *TxPool) Remove(hash common.Hash) bool {
+	for _, subpool := range p.subpools {
+		if subpool.Remove(hash) {
+			return true
+		}
+	}
+	return false
+}
+
 // Add enqueues a batch of transactions into the pool if they are valid. Due
 // to the large transaction churn, add may postpone fully integrating the tx
 // to a later point to batch multiple ones together.
@@ -0,0 +1,139 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package userops
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/shubhamdubey02/coreth/core"
+	"github.com/shubhamdubey02/coreth/core/state"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/core/vm"
+	"github.com/shubhamdubey02/coreth/vmerrs"
+)
+
+// handleOpsOverhead is the gas used by a handleOps transaction beyond the
+// gas of its user operations.
+const handleOpsOverhead = 100_000
+
+// Bundler bundles the pending user operations of a pool into a transaction
+// calling handleOps on the EntryPoint, signed by the key of the bundler. The
+// operations refund the gas of the transaction to the bundler, which is the
+// beneficiary of the bundle.
+//
+// The bundler account should be dedicated to bundling, as the nonce of the
+// bundle is the nonce of the account on the state the bundle is built on.
+type Bundler struct {
+	pool    *Pool
+	key     *ecdsa.PrivateKey
+	address common.Address
+	signer  types.Signer
+}
+
+// NewBundler returns a bundler of the user operations of [pool] signing
+// bundles with [key].
+func NewBundler(pool *Pool, key *ecdsa.PrivateKey) *Bundler {
+	return &Bundler{
+		pool:    pool,
+		key:     key,
+		address: crypto.PubkeyToAddress(key.PublicKey),
+		signer:  types.LatestSigner(pool.chainConfig),
+	}
+}
+
+// Address returns the address of the bundler.
+func (b *Bundler) Address() common.Address {
+	return b.address
+}
+
+// Bundle returns a transaction executing the pending user operations valid
+// on [statedb] at [header], or nil if there are none. User operations failing
+// in the simulation of the bundle are dropped from the pool.
+func (b *Bundler) Bundle(header *types.Header, statedb *state.StateDB, gasLimit uint64) *types.Transaction {
+	if header.BaseFee == nil || gasLimit <= handleOpsOverhead {
+		return nil
+	}
+	ops := b.pool.Pending(statedb, header, gasLimit-handleOpsOverhead)
+	for len(ops) > 0 {
+		values := make([]UserOperation, len(ops))
+		gas := uint64(handleOpsOverhead)
+		for i, op := range ops {
+			values[i] = *op
+			gas += op.Gas().Uint64()
+		}
+		input, err := entryPointABI.Pack("handleOps", values, b.address)
+		if err != nil {
+			log.Debug("Failed to pack user operation bundle", "err", err)
+			return nil
+		}
+		failed, err := b.simulate(statedb, header, input, gas)
+		if err == nil {
+			return b.sign(statedb, header, input, gas)
+		}
+		if failed < 0 || failed >= len(ops) {
+			log.Debug("Failed to simulate user operation bundle", "ops", len(ops), "err", err)
+			return nil
+		}
+		op := ops[failed]
+		log.Debug("Dropping user operation failing in bundle", "sender", op.Sender, "nonce", op.Nonce, "err", err)
+		b.pool.Drop(op.Hash(b.pool.config.EntryPoint, b.pool.chainConfig.ChainID))
+		ops = append(ops[:failed], ops[failed+1:]...)
+	}
+	return nil
+}
+
+// simulate executes the handleOps call [input] on [statedb], returning the
+// index of the user operation that failed if the call reverted with
+// FailedOp, or -1.
+func (b *Bundler) simulate(statedb *state.StateDB, header *types.Header, input []byte, gas uint64) (int, error) {
+	snapshot := statedb.Snapshot()
+	defer statedb.RevertToSnapshot(snapshot)
+
+	blockContext := core.NewEVMBlockContext(header, b.pool.chain, &header.Coinbase)
+	evm := vm.NewEVM(blockContext, vm.TxContext{Origin: b.address, GasPrice: header.BaseFee}, statedb, b.pool.chainConfig, vm.Config{})
+	ret, _, err := evm.Call(vm.AccountRef(b.address), b.pool.config.EntryPoint, input, gas, new(big.Int))
+	if err == nil {
+		return -1, nil
+	}
+	failedOp := entryPointABI.Errors["FailedOp"]
+	if !errors.Is(err, vmerrs.ErrExecutionReverted) || !hasSelector(ret, failedOp) {
+		return -1, err
+	}
+	values, unpackErr := failedOp.Inputs.Unpack(ret[4:])
+	if unpackErr != nil || len(values) != 2 {
+		return -1, err
+	}
+	index, _ := values[0].(*big.Int)
+	reason, _ := values[1].(string)
+	if index == nil || !index.IsInt64() {
+		return -1, err
+	}
+	return int(index.Int64()), errors.New(reason)
+}
+
+// sign returns the signed transaction calling handleOps with [input]. The
+// transaction pays no tip, as the user operations pay their priority fees to
+// the bundler.
+func (b *Bundler) sign(statedb *state.StateDB, header *types.Header, input []byte, gas uint64) *types.Transaction {
+	to := b.pool.config.EntryPoint
+	tx, err := types.SignNewTx(b.key, b.signer, &types.DynamicFeeTx{
+		ChainID:   b.pool.chainConfig.ChainID,
+		Nonce:     statedb.GetNonce(b.address),
+		GasTipCap: new(big.Int),
+		GasFeeCap: new(big.Int).Set(header.BaseFee),
+		Gas:       gas,
+		To:        &to,
+		Data:      input,
+	})
+	if err != nil {
+		log.Debug("Failed to sign user operation bundle", "err", err)
+		return nil
+	}
+	return tx
+}
@@ -0,0 +1,445 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package userops implements a pool of ERC-4337 (v0.6) user operations,
+// validated against a configured EntryPoint and bundled into the blocks built
+// by the node, so that a node serves as a bundler.
+package userops
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/shubhamdubey02/coreth/accounts/abi"
+	"github.com/shubhamdubey02/coreth/core"
+	"github.com/shubhamdubey02/coreth/core/state"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/core/vm"
+	"github.com/shubhamdubey02/coreth/params"
+	"github.com/shubhamdubey02/coreth/vmerrs"
+)
+
+const (
+	// minValidity is the minimum time a user operation must remain valid
+	// for to be admitted, so that it can be bundled before it expires.
+	minValidity = 30 * time.Second
+
+	// priceBump is the minimum percentage increase of both fees of a user
+	// operation replacing another one with the same sender and nonce.
+	priceBump = 10
+
+	// includedCacheSize is the number of included user operations kept to
+	// serve their receipts.
+	includedCacheSize = 4096
+)
+
+var (
+	ErrUnsupportedEntryPoint  = errors.New("unsupported entry point")
+	ErrPoolFull               = errors.New("user operation pool is full")
+	ErrSenderPending          = errors.New("sender has a pending user operation with another nonce")
+	ErrReplacementUnderpriced = errors.New("replacement user operation underpriced")
+	ErrValidationFailed       = errors.New("user operation validation failed")
+
+	errInvalidFees         = errors.New("max priority fee per gas exceeds max fee per gas")
+	errMissingAccount      = errors.New("sender has no code and no init code")
+	errSignatureFailed     = errors.New("invalid user operation signature")
+	errNotYetValid         = errors.New("user operation is not valid yet")
+	errExpiring            = errors.New("user operation expires too soon")
+	errAggregatorUnsupport = errors.New("signature aggregators are not supported")
+	errUnexpectedResult    = errors.New("unexpected simulateValidation result")
+)
+
+// Config configures the pool of user operations.
+type Config struct {
+	// EntryPoint is the address of the EntryPoint contract validating and
+	// executing the user operations.
+	EntryPoint common.Address
+	// MaxOps is the maximum number of pending user operations.
+	MaxOps int
+	// MaxBundleOps is the maximum number of user operations per bundle.
+	MaxBundleOps int
+}
+
+// Chain is the chain the user operations are validated against.
+type Chain interface {
+	core.ChainContext
+	CurrentBlock() *types.Header
+	StateAt(root common.Hash) (*state.StateDB, error)
+}
+
+// Receipt is the outcome of the execution of an included user operation,
+// reported by the UserOperationEvent of the EntryPoint.
+type Receipt struct {
+	UserOpHash      common.Hash    `json:"userOpHash"`
+	EntryPoint      common.Address `json:"entryPoint"`
+	Sender          common.Address `json:"sender"`
+	Nonce           *hexutil.Big   `json:"nonce"`
+	Paymaster       common.Address `json:"paymaster"`
+	ActualGasCost   *hexutil.Big   `json:"actualGasCost"`
+	ActualGasUsed   *hexutil.Big   `json:"actualGasUsed"`
+	Success         bool           `json:"success"`
+	TransactionHash common.Hash    `json:"transactionHash"`
+	BlockHash       common.Hash    `json:"blockHash"`
+	BlockNumber     hexutil.Uint64 `json:"blockNumber"`
+}
+
+// Included is a user operation included in an accepted block. Op is nil if
+// the operation was bundled by another node.
+type Included struct {
+	Op      *UserOperation
+	Receipt *Receipt
+}
+
+// validationResult is the result of the simulated validation of a user
+// operation.
+type validationResult struct {
+	ReturnInfo struct {
+		PreOpGas         *big.Int
+		Prefund          *big.Int
+		SigFailed        bool
+		ValidAfter       *big.Int
+		ValidUntil       *big.Int
+		PaymasterContext []byte
+	}
+	SenderInfo    stakeInfo
+	FactoryInfo   stakeInfo
+	PaymasterInfo stakeInfo
+}
+
+type stakeInfo struct {
+	Stake           *big.Int
+	UnstakeDelaySec *big.Int
+}
+
+// Pool holds the user operations submitted to the node until they are
+// included in an accepted block. A sender has at most one pending operation,
+// which may be replaced by an operation with the same nonce paying higher
+// fees.
+type Pool struct {
+	config      Config
+	chainConfig *params.ChainConfig
+	chain       Chain
+	now         func() time.Time
+	// simulate validates [op] on [statedb] at [header], reverting any change
+	// to [statedb]. It is replaced in tests.
+	simulate func(statedb *state.StateDB, header *types.Header, op *UserOperation) error
+
+	lock     sync.RWMutex
+	ops      map[common.Hash]*UserOperation
+	senders  map[common.Address]common.Hash
+	included lru.BasicLRU[common.Hash, *Included]
+}
+
+// New returns a pool of the user operations of the EntryPoint of [config].
+func New(config Config, chainConfig *params.ChainConfig, chain Chain) *Pool {
+	p := &Pool{
+		config:      config,
+		chainConfig: chainConfig,
+		chain:       chain,
+		now:         time.Now,
+		ops:         make(map[common.Hash]*UserOperation),
+		senders:     make(map[common.Address]common.Hash),
+		included:    lru.NewBasicLRU[common.Hash, *Included](includedCacheSize),
+	}
+	p.simulate = p.simulateValidation
+	return p
+}
+
+// EntryPoint returns the address of the EntryPoint of the pool.
+func (p *Pool) EntryPoint() common.Address {
+	return p.config.EntryPoint
+}
+
+// Add validates [op] for [entryPoint] against the current head and adds it to
+// the pool, returning its hash.
+func (p *Pool) Add(op *UserOperation, entryPoint common.Address) (common.Hash, error) {
+	if entryPoint != p.config.EntryPoint {
+		return common.Hash{}, fmt.Errorf("%w: %s", ErrUnsupportedEntryPoint, entryPoint)
+	}
+	if op.MaxPriorityFeePerGas.Cmp(op.MaxFeePerGas) > 0 {
+		return common.Hash{}, errInvalidFees
+	}
+	hash := op.Hash(p.config.EntryPoint, p.chainConfig.ChainID)
+	p.lock.RLock()
+	err := p.conflict(op)
+	p.lock.RUnlock()
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	head := p.chain.CurrentBlock()
+	statedb, err := p.chain.StateAt(head.Root)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if len(op.InitCode) == 0 && statedb.GetCodeSize(op.Sender) == 0 {
+		return common.Hash{}, errMissingAccount
+	}
+	if err := p.simulate(statedb, head, op); err != nil {
+		return common.Hash{}, err
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	// The pool may have changed during the simulation.
+	if err := p.conflict(op); err != nil {
+		return common.Hash{}, err
+	}
+	if replaced, ok := p.senders[op.Sender]; ok {
+		delete(p.ops, replaced)
+	}
+	p.ops[hash] = op
+	p.senders[op.Sender] = hash
+	log.Debug("Added user operation", "hash", hash, "sender", op.Sender, "nonce", op.Nonce)
+	return hash, nil
+}
+
+// conflict returns an error if [op] cannot be added to the pool, as the pool
+// is full or its sender has a pending operation it does not replace. The
+// lock must be held.
+func (p *Pool) conflict(op *UserOperation) error {
+	hash, ok := p.senders[op.Sender]
+	if !ok {
+		if len(p.ops) >= p.config.MaxOps {
+			return ErrPoolFull
+		}
+		return nil
+	}
+	pending := p.ops[hash]
+	if pending.Nonce.Cmp(op.Nonce) != 0 {
+		return ErrSenderPending
+	}
+	if !bumped(pending.MaxFeePerGas, op.MaxFeePerGas) || !bumped(pending.MaxPriorityFeePerGas, op.MaxPriorityFeePerGas) {
+		return ErrReplacementUnderpriced
+	}
+	return nil
+}
+
+// bumped returns whether [replacement] is at least [priceBump] percent more
+// than [old].
+func bumped(old, replacement *big.Int) bool {
+	threshold := new(big.Int).Mul(old, big.NewInt(100+priceBump))
+	return new(big.Int).Mul(replacement, big.NewInt(100)).Cmp(threshold) >= 0
+}
+
+// Get returns the pending user operation [hash], or nil if unknown.
+func (p *Pool) Get(hash common.Hash) *UserOperation {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	return p.ops[hash]
+}
+
+// Included returns the included user operation [hash], or nil if it was not
+// included recently.
+func (p *Pool) Included(hash common.Hash) *Included {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	included, _ := p.included.Get(hash)
+	return included
+}
+
+// Len returns the number of pending user operations.
+func (p *Pool) Len() int {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	return len(p.ops)
+}
+
+// Accept removes the user operations reported by the UserOperationEvents of
+// [logs] of an accepted block from the pool, and records their receipts.
+func (p *Pool) Accept(logs []*types.Log) {
+	event := entryPointABI.Events["UserOperationEvent"]
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for _, l := range logs {
+		if l.Address != p.config.EntryPoint || len(l.Topics) != 4 || l.Topics[0] != event.ID {
+			continue
+		}
+		values, err := event.Inputs.NonIndexed().Unpack(l.Data)
+		if err != nil || len(values) != 4 {
+			log.Debug("Failed to unpack UserOperationEvent", "tx", l.TxHash, "err", err)
+			continue
+		}
+		nonce, _ := values[0].(*big.Int)
+		success, _ := values[1].(bool)
+		gasCost, _ := values[2].(*big.Int)
+		gasUsed, _ := values[3].(*big.Int)
+
+		hash := l.Topics[1]
+		op := p.ops[hash]
+		if op != nil {
+			delete(p.ops, hash)
+			delete(p.senders, op.Sender)
+		}
+		p.included.Add(hash, &Included{
+			Op: op,
+			Receipt: &Receipt{
+				UserOpHash:      hash,
+				EntryPoint:      p.config.EntryPoint,
+				Sender:          common.BytesToAddress(l.Topics[2].Bytes()),
+				Nonce:           (*hexutil.Big)(nonce),
+				Paymaster:       common.BytesToAddress(l.Topics[3].Bytes()),
+				ActualGasCost:   (*hexutil.Big)(gasCost),
+				ActualGasUsed:   (*hexutil.Big)(gasUsed),
+				Success:         success,
+				TransactionHash: l.TxHash,
+				BlockHash:       l.BlockHash,
+				BlockNumber:     hexutil.Uint64(l.BlockNumber),
+			},
+		})
+	}
+}
+
+// Pending returns up to [MaxBundleOps] pending user operations paying at
+// least [baseFee], by descending priority fee, that are still valid on
+// [statedb] at [header] and fit in [gasLimit]. Operations that are no longer
+// valid are dropped from the pool.
+func (p *Pool) Pending(statedb *state.StateDB, header *types.Header, gasLimit uint64) []*UserOperation {
+	type candidate struct {
+		hash common.Hash
+		op   *UserOperation
+	}
+	p.lock.RLock()
+	candidates := make([]candidate, 0, len(p.ops))
+	for hash, op := range p.ops {
+		if header.BaseFee == nil || op.MaxFeePerGas.Cmp(header.BaseFee) >= 0 {
+			candidates = append(candidates, candidate{hash, op})
+		}
+	}
+	p.lock.RUnlock()
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if cmp := candidates[i].op.MaxPriorityFeePerGas.Cmp(candidates[j].op.MaxPriorityFeePerGas); cmp != 0 {
+			return cmp > 0
+		}
+		return bytes.Compare(candidates[i].hash[:], candidates[j].hash[:]) < 0
+	})
+
+	var (
+		ops     []*UserOperation
+		invalid []common.Hash
+		gas     = new(big.Int)
+		limit   = new(big.Int).SetUint64(gasLimit)
+	)
+	for _, c := range candidates {
+		if len(ops) >= p.config.MaxBundleOps {
+			break
+		}
+		if next := new(big.Int).Add(gas, c.op.Gas()); next.Cmp(limit) > 0 {
+			continue
+		}
+		if err := p.simulate(statedb, header, c.op); err != nil {
+			log.Debug("Dropping invalid user operation", "hash", c.hash, "sender", c.op.Sender, "err", err)
+			invalid = append(invalid, c.hash)
+			continue
+		}
+		gas.Add(gas, c.op.Gas())
+		ops = append(ops, c.op)
+	}
+	p.Drop(invalid...)
+	return ops
+}
+
+// Drop removes the user operations [hashes] from the pool.
+func (p *Pool) Drop(hashes ...common.Hash) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for _, hash := range hashes {
+		op, ok := p.ops[hash]
+		if !ok {
+			continue
+		}
+		delete(p.ops, hash)
+		if p.senders[op.Sender] == hash {
+			delete(p.senders, op.Sender)
+		}
+	}
+}
+
+// simulateValidation validates [op] by calling simulateValidation on the
+// EntryPoint, which always reverts with the result of the validation.
+func (p *Pool) simulateValidation(statedb *state.StateDB, header *types.Header, op *UserOperation) error {
+	input, err := entryPointABI.Pack("simulateValidation", *op)
+	if err != nil {
+		return err
+	}
+	snapshot := statedb.Snapshot()
+	defer statedb.RevertToSnapshot(snapshot)
+
+	blockContext := core.NewEVMBlockContext(header, p.chain, &header.Coinbase)
+	evm := vm.NewEVM(blockContext, vm.TxContext{GasPrice: new(big.Int)}, statedb, p.chainConfig, vm.Config{NoBaseFee: true})
+	ret, _, err := evm.Call(vm.AccountRef(common.Address{}), p.config.EntryPoint, input, header.GasLimit, new(big.Int))
+	if !errors.Is(err, vmerrs.ErrExecutionReverted) {
+		return fmt.Errorf("%w: %v", errUnexpectedResult, err)
+	}
+	result, err := decodeValidationResult(ret)
+	if err != nil {
+		return err
+	}
+	return p.checkValidity(result)
+}
+
+// decodeValidationResult decodes the revert data [ret] of simulateValidation.
+func decodeValidationResult(ret []byte) (*validationResult, error) {
+	switch {
+	case hasSelector(ret, entryPointABI.Errors["FailedOp"]):
+		values, err := entryPointABI.Errors["FailedOp"].Inputs.Unpack(ret[4:])
+		if err != nil || len(values) != 2 {
+			return nil, fmt.Errorf("%w: %v", ErrValidationFailed, err)
+		}
+		reason, _ := values[1].(string)
+		return nil, fmt.Errorf("%w: %s", ErrValidationFailed, reason)
+	case hasSelector(ret, entryPointABI.Errors["ValidationResultWithAggregation"]):
+		return nil, errAggregatorUnsupport
+	case hasSelector(ret, entryPointABI.Errors["ValidationResult"]):
+		inputs := entryPointABI.Errors["ValidationResult"].Inputs
+		values, err := inputs.Unpack(ret[4:])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errUnexpectedResult, err)
+		}
+		result := new(validationResult)
+		if err := inputs.Copy(result, values); err != nil {
+			return nil, fmt.Errorf("%w: %v", errUnexpectedResult, err)
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("%w: %x", errUnexpectedResult, ret)
+	}
+}
+
+// checkValidity returns an error if the signature of a validated user
+// operation failed, or if it cannot be bundled within its validity window.
+func (p *Pool) checkValidity(result *validationResult) error {
+	info := result.ReturnInfo
+	now := uint64(p.now().Unix())
+	switch {
+	case info.SigFailed:
+		return errSignatureFailed
+	case info.ValidAfter.Uint64() > now:
+		return fmt.Errorf("%w: valid after %d", errNotYetValid, info.ValidAfter)
+	case info.ValidUntil.Sign() != 0 && info.ValidUntil.Uint64() < now+uint64(minValidity.Seconds()):
+		return fmt.Errorf("%w: valid until %d", errExpiring, info.ValidUntil)
+	}
+	return nil
+}
+
+// hasSelector returns whether [ret] encodes [abiErr].
+func hasSelector(ret []byte, abiErr abi.Error) bool {
+	return len(ret) >= 4 && bytes.Equal(ret[:4], abiErr.ID[:4])
+}
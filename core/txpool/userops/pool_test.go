@@ -0,0 +1,223 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package userops
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/shubhamdubey02/coreth/consensus"
+	"github.com/shubhamdubey02/coreth/core/rawdb"
+	"github.com/shubhamdubey02/coreth/core/state"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/params"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testEntryPoint = common.Address{0xe0}
+	testSender     = common.Address{0x5e}
+)
+
+// testChain is a chain whose head state holds the code of [testSender].
+type testChain struct {
+	db   state.Database
+	head *types.Header
+}
+
+func newTestChain(t *testing.T) *testChain {
+	db := state.NewDatabase(rawdb.NewMemoryDatabase())
+	statedb, err := state.New(types.EmptyRootHash, db, nil)
+	require.NoError(t, err)
+	statedb.SetCode(testSender, []byte{0x00})
+	root, err := statedb.Commit(0, false, false)
+	require.NoError(t, err)
+	return &testChain{
+		db:   db,
+		head: &types.Header{Number: common.Big0, Root: root, GasLimit: 8_000_000, BaseFee: big.NewInt(25)},
+	}
+}
+
+func (c *testChain) Engine() consensus.Engine                    { return nil }
+func (c *testChain) GetHeader(common.Hash, uint64) *types.Header { return nil }
+func (c *testChain) CurrentBlock() *types.Header                 { return c.head }
+func (c *testChain) StateAt(root common.Hash) (*state.StateDB, error) {
+	return state.New(root, c.db, nil)
+}
+
+func newTestOp(nonce int64, maxFee int64) *UserOperation {
+	return &UserOperation{
+		Sender:               testSender,
+		Nonce:                big.NewInt(nonce),
+		CallData:             []byte{0x01},
+		CallGasLimit:         big.NewInt(50_000),
+		VerificationGasLimit: big.NewInt(100_000),
+		PreVerificationGas:   big.NewInt(21_000),
+		MaxFeePerGas:         big.NewInt(maxFee),
+		MaxPriorityFeePerGas: big.NewInt(1),
+		Signature:            []byte{0xaa},
+	}
+}
+
+func TestUserOperationJSON(t *testing.T) {
+	require := require.New(t)
+
+	op := newTestOp(1, 100)
+	encoded, err := json.Marshal(op)
+	require.NoError(err)
+	var decoded UserOperation
+	require.NoError(json.Unmarshal(encoded, &decoded))
+	require.Equal(op.Hash(testEntryPoint, common.Big1), decoded.Hash(testEntryPoint, common.Big1))
+
+	// Numeric fields are required.
+	err = json.Unmarshal([]byte(`{"sender": "0x000000000000000000000000000000000000005e"}`), &decoded)
+	require.ErrorIs(err, errMissingUserOpField)
+}
+
+func TestDecodeValidationResult(t *testing.T) {
+	require := require.New(t)
+
+	var result validationResult
+	result.ReturnInfo.PreOpGas = big.NewInt(1)
+	result.ReturnInfo.Prefund = big.NewInt(2)
+	result.ReturnInfo.ValidAfter = big.NewInt(3)
+	result.ReturnInfo.ValidUntil = big.NewInt(4)
+	result.ReturnInfo.PaymasterContext = []byte{}
+	stake := stakeInfo{Stake: new(big.Int), UnstakeDelaySec: new(big.Int)}
+	result.SenderInfo, result.FactoryInfo, result.PaymasterInfo = stake, stake, stake
+
+	validation := entryPointABI.Errors["ValidationResult"]
+	data, err := validation.Inputs.Pack(result.ReturnInfo, result.SenderInfo, result.FactoryInfo, result.PaymasterInfo)
+	require.NoError(err)
+	decoded, err := decodeValidationResult(append(common.CopyBytes(validation.ID[:4]), data...))
+	require.NoError(err)
+	require.Equal(result.ReturnInfo.ValidAfter, decoded.ReturnInfo.ValidAfter)
+	require.Equal(result.ReturnInfo.ValidUntil, decoded.ReturnInfo.ValidUntil)
+
+	failedOp := entryPointABI.Errors["FailedOp"]
+	data, err = failedOp.Inputs.Pack(common.Big0, "AA21 didn't pay prefund")
+	require.NoError(err)
+	_, err = decodeValidationResult(append(common.CopyBytes(failedOp.ID[:4]), data...))
+	require.ErrorIs(err, ErrValidationFailed)
+	require.ErrorContains(err, "AA21")
+
+	_, err = decodeValidationResult([]byte{0x01, 0x02, 0x03, 0x04})
+	require.ErrorIs(err, errUnexpectedResult)
+
+	// The validity window of the operation must cover the next bundles.
+	pool := &Pool{now: func() time.Time { return time.Unix(100, 0) }}
+	result.ReturnInfo.ValidAfter = big.NewInt(0)
+	result.ReturnInfo.ValidUntil = big.NewInt(0)
+	require.NoError(pool.checkValidity(&result))
+	result.ReturnInfo.ValidUntil = big.NewInt(110)
+	require.ErrorIs(pool.checkValidity(&result), errExpiring)
+	result.ReturnInfo.ValidUntil = big.NewInt(0)
+	result.ReturnInfo.ValidAfter = big.NewInt(200)
+	require.ErrorIs(pool.checkValidity(&result), errNotYetValid)
+	result.ReturnInfo.ValidAfter = big.NewInt(0)
+	result.ReturnInfo.SigFailed = true
+	require.ErrorIs(pool.checkValidity(&result), errSignatureFailed)
+}
+
+func TestPool(t *testing.T) {
+	require := require.New(t)
+
+	chain := newTestChain(t)
+	pool := New(Config{EntryPoint: testEntryPoint, MaxOps: 1, MaxBundleOps: 4}, params.TestChainConfig, chain)
+	pool.simulate = func(*state.StateDB, *types.Header, *UserOperation) error { return nil }
+
+	_, err := pool.Add(newTestOp(0, 100), common.Address{0x01})
+	require.ErrorIs(err, ErrUnsupportedEntryPoint)
+
+	op := newTestOp(0, 100)
+	hash, err := pool.Add(op, testEntryPoint)
+	require.NoError(err)
+	require.Equal(op.Hash(testEntryPoint, params.TestChainConfig.ChainID), hash)
+	require.Equal(op, pool.Get(hash))
+
+	// A sender has a single pending operation, which is only replaced by
+	// one paying higher fees.
+	_, err = pool.Add(newTestOp(1, 200), testEntryPoint)
+	require.ErrorIs(err, ErrSenderPending)
+	_, err = pool.Add(newTestOp(0, 105), testEntryPoint)
+	require.ErrorIs(err, ErrReplacementUnderpriced)
+	replacement := newTestOp(0, 110)
+	replacement.MaxPriorityFeePerGas = big.NewInt(2)
+	replacementHash, err := pool.Add(replacement, testEntryPoint)
+	require.NoError(err)
+	require.Nil(pool.Get(hash))
+	require.Equal(1, pool.Len())
+
+	other := newTestOp(0, 100)
+	other.Sender = common.Address{0x01}
+	other.InitCode = []byte{0x01}
+	_, err = pool.Add(other, testEntryPoint)
+	require.ErrorIs(err, ErrPoolFull)
+
+	statedb, err := chain.StateAt(chain.head.Root)
+	require.NoError(err)
+	require.Equal([]*UserOperation{replacement}, pool.Pending(statedb, chain.head, chain.head.GasLimit))
+	require.Empty(pool.Pending(statedb, chain.head, 1000))
+
+	// Accepted operations are removed and their receipts recorded.
+	event := entryPointABI.Events["UserOperationEvent"]
+	data, err := event.Inputs.NonIndexed().Pack(common.Big0, true, big.NewInt(1000), big.NewInt(40))
+	require.NoError(err)
+	pool.Accept([]*types.Log{{
+		Address:     testEntryPoint,
+		Topics:      []common.Hash{event.ID, replacementHash, common.BytesToHash(testSender.Bytes()), {}},
+		Data:        data,
+		TxHash:      common.Hash{0x0a},
+		BlockHash:   common.Hash{0x0b},
+		BlockNumber: 1,
+	}})
+	require.Zero(pool.Len())
+	included := pool.Included(replacementHash)
+	require.NotNil(included)
+	require.Equal(replacement, included.Op)
+	require.True(included.Receipt.Success)
+	require.Equal(testSender, included.Receipt.Sender)
+	require.EqualValues(40, included.Receipt.ActualGasUsed.ToInt().Int64())
+	require.Equal(common.Hash{0x0a}, included.Receipt.TransactionHash)
+}
+
+// revertCode returns code reverting with [data].
+func revertCode(data []byte) []byte {
+	size := []byte{byte(len(data) >> 8), byte(len(data))}
+	code := []byte{0x61, size[0], size[1], 0x60, 0x0e, 0x60, 0x00, 0x39} // CODECOPY(0, 14, len)
+	code = append(code, 0x61, size[0], size[1], 0x60, 0x00, 0xfd)        // REVERT(0, len)
+	return append(code, data...)
+}
+
+func TestEntryPointRevert(t *testing.T) {
+	require := require.New(t)
+
+	chain := newTestChain(t)
+	statedb, err := chain.StateAt(chain.head.Root)
+	require.NoError(err)
+	header := types.CopyHeader(chain.head)
+	header.Difficulty = common.Big0
+
+	failedOp := entryPointABI.Errors["FailedOp"]
+	data, err := failedOp.Inputs.Pack(common.Big1, "AA21 didn't pay prefund")
+	require.NoError(err)
+	statedb.SetCode(testEntryPoint, revertCode(append(common.CopyBytes(failedOp.ID[:4]), data...)))
+
+	// The reverted validation is decoded.
+	pool := New(Config{EntryPoint: testEntryPoint, MaxOps: 1, MaxBundleOps: 4}, params.TestChainConfig, chain)
+	err = pool.simulateValidation(statedb, header, newTestOp(0, 100))
+	require.ErrorIs(err, ErrValidationFailed)
+	require.ErrorContains(err, "AA21")
+
+	// The operation failing in a reverted bundle is identified.
+	key, err := crypto.GenerateKey()
+	require.NoError(err)
+	failed, err := NewBundler(pool, key).simulate(statedb, header, []byte{0x01}, 1_000_000)
+	require.Equal(1, failed)
+	require.ErrorContains(err, "AA21")
+}
@@ -0,0 +1,254 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package userops
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/shubhamdubey02/coreth/accounts/abi"
+)
+
+// userOpComponents are the ABI components of a user operation.
+const userOpComponents = `[
+	{"name": "sender", "type": "address"},
+	{"name": "nonce", "type": "uint256"},
+	{"name": "initCode", "type": "bytes"},
+	{"name": "callData", "type": "bytes"},
+	{"name": "callGasLimit", "type": "uint256"},
+	{"name": "verificationGasLimit", "type": "uint256"},
+	{"name": "preVerificationGas", "type": "uint256"},
+	{"name": "maxFeePerGas", "type": "uint256"},
+	{"name": "maxPriorityFeePerGas", "type": "uint256"},
+	{"name": "paymasterAndData", "type": "bytes"},
+	{"name": "signature", "type": "bytes"}
+]`
+
+// stakeInfoComponents are the ABI components of the stake of an entity.
+const stakeInfoComponents = `[
+	{"name": "stake", "type": "uint256"},
+	{"name": "unstakeDelaySec", "type": "uint256"}
+]`
+
+// entryPointABI is the subset of the interface of an ERC-4337 (v0.6)
+// EntryPoint used by the pool.
+var entryPointABI = mustParseABI(`[{
+	"type": "function",
+	"name": "handleOps",
+	"inputs": [
+		{"name": "ops", "type": "tuple[]", "components": ` + userOpComponents + `},
+		{"name": "beneficiary", "type": "address"}
+	],
+	"outputs": []
+}, {
+	"type": "function",
+	"name": "simulateValidation",
+	"inputs": [{"name": "userOp", "type": "tuple", "components": ` + userOpComponents + `}],
+	"outputs": []
+}, {
+	"type": "error",
+	"name": "FailedOp",
+	"inputs": [
+		{"name": "opIndex", "type": "uint256"},
+		{"name": "reason", "type": "string"}
+	]
+}, {
+	"type": "error",
+	"name": "ValidationResult",
+	"inputs": [
+		{"name": "returnInfo", "type": "tuple", "components": [
+			{"name": "preOpGas", "type": "uint256"},
+			{"name": "prefund", "type": "uint256"},
+			{"name": "sigFailed", "type": "bool"},
+			{"name": "validAfter", "type": "uint48"},
+			{"name": "validUntil", "type": "uint48"},
+			{"name": "paymasterContext", "type": "bytes"}
+		]},
+		{"name": "senderInfo", "type": "tuple", "components": ` + stakeInfoComponents + `},
+		{"name": "factoryInfo", "type": "tuple", "components": ` + stakeInfoComponents + `},
+		{"name": "paymasterInfo", "type": "tuple", "components": ` + stakeInfoComponents + `}
+	]
+}, {
+	"type": "error",
+	"name": "ValidationResultWithAggregation",
+	"inputs": [
+		{"name": "returnInfo", "type": "tuple", "components": [
+			{"name": "preOpGas", "type": "uint256"},
+			{"name": "prefund", "type": "uint256"},
+			{"name": "sigFailed", "type": "bool"},
+			{"name": "validAfter", "type": "uint48"},
+			{"name": "validUntil", "type": "uint48"},
+			{"name": "paymasterContext", "type": "bytes"}
+		]},
+		{"name": "senderInfo", "type": "tuple", "components": ` + stakeInfoComponents + `},
+		{"name": "factoryInfo", "type": "tuple", "components": ` + stakeInfoComponents + `},
+		{"name": "paymasterInfo", "type": "tuple", "components": ` + stakeInfoComponents + `},
+		{"name": "aggregatorInfo", "type": "tuple", "components": [
+			{"name": "aggregator", "type": "address"},
+			{"name": "stakeInfo", "type": "tuple", "components": ` + stakeInfoComponents + `}
+		]}
+	]
+}, {
+	"type": "event",
+	"name": "UserOperationEvent",
+	"anonymous": false,
+	"inputs": [
+		{"name": "userOpHash", "type": "bytes32", "indexed": true},
+		{"name": "sender", "type": "address", "indexed": true},
+		{"name": "paymaster", "type": "address", "indexed": true},
+		{"name": "nonce", "type": "uint256", "indexed": false},
+		{"name": "success", "type": "bool", "indexed": false},
+		{"name": "actualGasCost", "type": "uint256", "indexed": false},
+		{"name": "actualGasUsed", "type": "uint256", "indexed": false}
+	]
+}]`)
+
+func mustParseABI(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+var errMissingUserOpField = errors.New("missing user operation field")
+
+// UserOperation is an ERC-4337 (v0.6) user operation, a transaction of a
+// smart contract account executed by the EntryPoint.
+type UserOperation struct {
+	Sender               common.Address
+	Nonce                *big.Int
+	InitCode             []byte
+	CallData             []byte
+	CallGasLimit         *big.Int
+	VerificationGasLimit *big.Int
+	PreVerificationGas   *big.Int
+	MaxFeePerGas         *big.Int
+	MaxPriorityFeePerGas *big.Int
+	PaymasterAndData     []byte
+	Signature            []byte
+}
+
+// rpcUserOperation is the JSON encoding of a user operation.
+type rpcUserOperation struct {
+	Sender               common.Address `json:"sender"`
+	Nonce                *hexutil.Big   `json:"nonce"`
+	InitCode             hexutil.Bytes  `json:"initCode"`
+	CallData             hexutil.Bytes  `json:"callData"`
+	CallGasLimit         *hexutil.Big   `json:"callGasLimit"`
+	VerificationGasLimit *hexutil.Big   `json:"verificationGasLimit"`
+	PreVerificationGas   *hexutil.Big   `json:"preVerificationGas"`
+	MaxFeePerGas         *hexutil.Big   `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big   `json:"maxPriorityFeePerGas"`
+	PaymasterAndData     hexutil.Bytes  `json:"paymasterAndData"`
+	Signature            hexutil.Bytes  `json:"signature"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (op *UserOperation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(rpcUserOperation{
+		Sender:               op.Sender,
+		Nonce:                (*hexutil.Big)(op.Nonce),
+		InitCode:             op.InitCode,
+		CallData:             op.CallData,
+		CallGasLimit:         (*hexutil.Big)(op.CallGasLimit),
+		VerificationGasLimit: (*hexutil.Big)(op.VerificationGasLimit),
+		PreVerificationGas:   (*hexutil.Big)(op.PreVerificationGas),
+		MaxFeePerGas:         (*hexutil.Big)(op.MaxFeePerGas),
+		MaxPriorityFeePerGas: (*hexutil.Big)(op.MaxPriorityFeePerGas),
+		PaymasterAndData:     op.PaymasterAndData,
+		Signature:            op.Signature,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler. Every numeric field is
+// required, the byte fields default to empty.
+func (op *UserOperation) UnmarshalJSON(input []byte) error {
+	var dec rpcUserOperation
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	for name, field := range map[string]*hexutil.Big{
+		"nonce":                dec.Nonce,
+		"callGasLimit":         dec.CallGasLimit,
+		"verificationGasLimit": dec.VerificationGasLimit,
+		"preVerificationGas":   dec.PreVerificationGas,
+		"maxFeePerGas":         dec.MaxFeePerGas,
+		"maxPriorityFeePerGas": dec.MaxPriorityFeePerGas,
+	} {
+		if field == nil {
+			return fmt.Errorf("%w: %s", errMissingUserOpField, name)
+		}
+	}
+	*op = UserOperation{
+		Sender:               dec.Sender,
+		Nonce:                dec.Nonce.ToInt(),
+		InitCode:             dec.InitCode,
+		CallData:             dec.CallData,
+		CallGasLimit:         dec.CallGasLimit.ToInt(),
+		VerificationGasLimit: dec.VerificationGasLimit.ToInt(),
+		PreVerificationGas:   dec.PreVerificationGas.ToInt(),
+		MaxFeePerGas:         dec.MaxFeePerGas.ToInt(),
+		MaxPriorityFeePerGas: dec.MaxPriorityFeePerGas.ToInt(),
+		PaymasterAndData:     dec.PaymasterAndData,
+		Signature:            dec.Signature,
+	}
+	return nil
+}
+
+// Hash returns the hash of the user operation for [entryPoint] on the chain
+// [chainID], which is the hash its account signs and the EntryPoint reports
+// in its events.
+func (op *UserOperation) Hash(entryPoint common.Address, chainID *big.Int) common.Hash {
+	packed := concatWords(
+		op.Sender.Bytes(),
+		op.Nonce.Bytes(),
+		crypto.Keccak256(op.InitCode),
+		crypto.Keccak256(op.CallData),
+		op.CallGasLimit.Bytes(),
+		op.VerificationGasLimit.Bytes(),
+		op.PreVerificationGas.Bytes(),
+		op.MaxFeePerGas.Bytes(),
+		op.MaxPriorityFeePerGas.Bytes(),
+		crypto.Keccak256(op.PaymasterAndData),
+	)
+	return crypto.Keccak256Hash(concatWords(crypto.Keccak256(packed), entryPoint.Bytes(), chainID.Bytes()))
+}
+
+// Gas returns the gas the EntryPoint may use to execute the user operation.
+// The verification gas is used up to three times if the operation has a
+// paymaster, to validate it and to call its post-operation hook.
+func (op *UserOperation) Gas() *big.Int {
+	verification := new(big.Int).Set(op.VerificationGasLimit)
+	if len(op.PaymasterAndData) > 0 {
+		verification.Mul(verification, big.NewInt(3))
+	}
+	gas := new(big.Int).Add(op.CallGasLimit, op.PreVerificationGas)
+	return gas.Add(gas, verification)
+}
+
+// Paymaster returns the paymaster of the user operation, or the zero address
+// if the sender pays for it.
+func (op *UserOperation) Paymaster() common.Address {
+	if len(op.PaymasterAndData) < common.AddressLength {
+		return common.Address{}
+	}
+	return common.BytesToAddress(op.PaymasterAndData[:common.AddressLength])
+}
+
+// concatWords concatenates [words] left padded to 32 bytes, which is the ABI
+// encoding of static values.
+func concatWords(words ...[]byte) []byte {
+	encoded := make([]byte, 0, 32*len(words))
+	for _, word := range words {
+		encoded = append(encoded, common.LeftPadBytes(word, 32)...)
+	}
+	return encoded
+}
@@ -38,6 +38,7 @@ import (
 	"github.com/shubhamdubey02/coreth/core/state"
 	"github.com/shubhamdubey02/coreth/core/types"
 	"github.com/shubhamdubey02/coreth/params"
+	"github.com/shubhamdubey02/coreth/precompile/contracts/txallowlist"
 	"github.com/shubhamdubey02/coreth/vmerrs"
 )
 
@@ -46,9 +47,10 @@ import (
 type ValidationOptions struct {
 	Config *params.ChainConfig // Chain configuration to selectively validate based on current fork rules
 
-	Accept  uint8    // Bitmap of transaction types that should be accepted for the calling pool
-	MaxSize uint64   // Maximum size of a transaction that the caller can meaningfully handle
-	MinTip  *big.Int // Minimum gas tip needed to allow a transaction into the caller pool
+	Accept       uint8    // Bitmap of transaction types that should be accepted for the calling pool
+	AcceptCustom bool     // Whether registered custom transaction types should be accepted for the calling pool
+	MaxSize      uint64   // Maximum size of a transaction that the caller can meaningfully handle
+	MinTip       *big.Int // Minimum gas tip needed to allow a transaction into the caller pool
 }
 
 // ValidateTransaction is a helper method to check whether a transaction is valid
@@ -58,8 +60,19 @@ type ValidationOptions struct {
 // This check is public to allow different transaction pools to check the basic
 // rules without duplicating code and running the risk of missed updates.
 func ValidateTransaction(tx *types.Transaction, head *types.Header, signer types.Signer, opts *ValidationOptions) error {
-	// Ensure transactions not implemented by the calling pool are rejected
-	if opts.Accept&(1<<tx.Type()) == 0 {
+	// Ensure transactions not implemented by the calling pool are rejected.
+	// Custom types are accepted by pools setting AcceptCustom once enabled.
+	if types.IsCustomTxType(tx.Type()) {
+		if !opts.AcceptCustom {
+			return fmt.Errorf("%w: tx type %v not supported by this pool", core.ErrTxTypeNotSupported, tx.Type())
+		}
+		if !opts.Config.IsTxTypeEnabled(tx.Type(), head.Time) {
+			return fmt.Errorf("%w: type %d rejected, type not yet enabled", core.ErrTxTypeNotSupported, tx.Type())
+		}
+		if err := types.ValidateCustomTx(tx); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidCustomTx, err)
+		}
+	} else if opts.Accept&(1<<tx.Type()) == 0 {
 		return fmt.Errorf("%w: tx type %v not supported by this pool", core.ErrTxTypeNotSupported, tx.Type())
 	}
 	// Before performing any expensive validations, sanity check that the tx is
@@ -78,8 +91,8 @@ func ValidateTransaction(tx *types.Transaction, head *types.Header, signer types
 		return fmt.Errorf("%w: type %d rejected, pool not yet in Cancun", core.ErrTxTypeNotSupported, tx.Type())
 	}
 	// Check whether the init code size has been exceeded
-	if opts.Config.IsDurango(head.Time) && tx.To() == nil && len(tx.Data()) > params.MaxInitCodeSize {
-		return fmt.Errorf("%w: code size %v, limit %v", vmerrs.ErrMaxInitCodeSizeExceeded, len(tx.Data()), params.MaxInitCodeSize)
+	if maxInitCodeSize := opts.Config.MaxInitCodeSize(head.Time); opts.Config.IsDurango(head.Time) && tx.To() == nil && len(tx.Data()) > maxInitCodeSize {
+		return fmt.Errorf("%w: code size %v, limit %v", vmerrs.ErrMaxInitCodeSizeExceeded, len(tx.Data()), maxInitCodeSize)
 	}
 	// Transactions can't be negative. This may never happen using RLP decoded
 	// transactions but may occur for transactions created using the RPC.
@@ -117,6 +130,14 @@ func ValidateTransaction(tx *types.Transaction, head *types.Header, signer types
 	if err != nil {
 		return err
 	}
+	customGas, err := types.CustomIntrinsicGas(tx)
+	if err != nil {
+		return err
+	}
+	if intrGas+customGas < intrGas {
+		return core.ErrGasUintOverflow
+	}
+	intrGas += customGas
 	if txGas := tx.Gas(); txGas < intrGas {
 		return fmt.Errorf("%w: address %v tx gas (%v) < intrinsic gas (%v)", core.ErrIntrinsicGas, from.Hex(), tx.Gas(), intrGas)
 	}
@@ -140,14 +161,16 @@ func ValidateTransaction(tx *types.Transaction, head *types.Header, signer types
 		if len(hashes) > params.MaxBlobGasPerBlock/params.BlobTxBlobGasPerBlob {
 			return fmt.Errorf("too many blobs in transaction: have %d, permitted %d", len(hashes), params.MaxBlobGasPerBlock/params.BlobTxBlobGasPerBlob)
 		}
-		if err := validateBlobSidecar(hashes, sidecar); err != nil {
+		if err := ValidateBlobSidecar(hashes, sidecar); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func validateBlobSidecar(hashes []common.Hash, sidecar *types.BlobTxSidecar) error {
+// ValidateBlobSidecar checks that [sidecar] carries the blobs, commitments and
+// proofs of the blob transaction with the versioned hashes [hashes].
+func ValidateBlobSidecar(hashes []common.Hash, sidecar *types.BlobTxSidecar) error {
 	if len(sidecar.Blobs) != len(hashes) {
 		return fmt.Errorf("invalid number of %d blobs compared to %d blob hashes", len(sidecar.Blobs), len(hashes))
 	}
@@ -207,6 +230,12 @@ type ValidationOptionsWithState struct {
 	// transaction's cost with the given nonce to check for overdrafts.
 	ExistingCost func(addr common.Address, nonce uint64) *big.Int
 
+	// Sponsored is an optional callback to check whether the gas of a
+	// transaction is paid by a sponsor. If this method is set, transactions
+	// that the transactor cannot afford are admitted if they are sponsored
+	// and the transactor can pay their value.
+	Sponsored func(addr common.Address, tx *types.Transaction) bool
+
 	Rules      params.Rules
 	MinimumFee *big.Int
 }
@@ -224,6 +253,11 @@ func ValidateTransactionWithState(tx *types.Transaction, signer types.Signer, op
 		return err
 	}
 
+	// Drop the transaction if the sender is not allow listed
+	if opts.Rules.IsPrecompileEnabled(txallowlist.ContractAddress) && !txallowlist.GetTxAllowListStatus(opts.State, from).IsEnabled() {
+		return fmt.Errorf("%w: address %s", vmerrs.ErrSenderNotAllowListed, from.Hex())
+	}
+
 	// Drop the transaction if the gas fee cap is below the pool's minimum fee
 	if opts.MinimumFee != nil && tx.GasFeeCapIntCmp(opts.MinimumFee) < 0 {
 		return fmt.Errorf("%w: address %s have gas fee cap (%d) < pool minimum fee cap (%d)", ErrUnderpriced, from.Hex(), tx.GasFeeCap(), opts.MinimumFee)
@@ -245,6 +279,15 @@ func ValidateTransactionWithState(tx *types.Transaction, signer types.Signer, op
 		balance = opts.State.GetBalance(from)
 		cost    = tx.Cost()
 	)
+	if opts.Sponsored != nil && balance.Cmp(tx.Value()) >= 0 &&
+		balance.Cmp(new(big.Int).Add(opts.ExistingExpenditure(from), cost)) < 0 && opts.Sponsored(from, tx) {
+		if opts.ExistingCost(from, tx.Nonce()) == nil {
+			if used, left := opts.UsedAndLeftSlots(from); left <= 0 {
+				return fmt.Errorf("%w: pooled %d txs", ErrAccountLimitExceeded, used)
+			}
+		}
+		return nil
+	}
 	if balance.Cmp(cost) < 0 {
 		return fmt.Errorf("%w: balance %v, tx cost %v, overshot %v", core.ErrInsufficientFunds, balance, cost, new(big.Int).Sub(cost, balance))
 	}
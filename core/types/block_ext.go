@@ -4,9 +4,11 @@
 package types
 
 import (
+	"encoding/json"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 )
 
 func (b *Block) WithExtData(version uint32, extdata *[]byte) *Block {
@@ -65,3 +67,63 @@ func NewBlockWithExtData(
 	b.setExtData(extdata, recalc)
 	return b
 }
+
+// HeaderExtensions holds the Avalanche-specific fields of a [Header].
+// ExtDataGasUsed and BlockGasCost were added by Apricot Phase 4 and are nil for
+// headers produced before it.
+type HeaderExtensions struct {
+	ExtDataHash    common.Hash
+	ExtDataGasUsed *big.Int
+	BlockGasCost   *big.Int
+}
+
+type headerExtensionsJSON struct {
+	ExtDataHash    common.Hash  `json:"extDataHash"`
+	ExtDataGasUsed *hexutil.Big `json:"extDataGasUsed"`
+	BlockGasCost   *hexutil.Big `json:"blockGasCost"`
+}
+
+// Extensions returns a copy of the Avalanche-specific fields of [h].
+func (h *Header) Extensions() HeaderExtensions {
+	return HeaderExtensions{
+		ExtDataHash:    h.ExtDataHash,
+		ExtDataGasUsed: copyBig(h.ExtDataGasUsed),
+		BlockGasCost:   copyBig(h.BlockGasCost),
+	}
+}
+
+// Extensions returns a copy of the Avalanche-specific fields of the block
+// header.
+func (b *Block) Extensions() HeaderExtensions {
+	return b.header.Extensions()
+}
+
+// MarshalJSON encodes the extensions using the same field names and
+// encodings as the header JSON encoding.
+func (e HeaderExtensions) MarshalJSON() ([]byte, error) {
+	return json.Marshal(headerExtensionsJSON{
+		ExtDataHash:    e.ExtDataHash,
+		ExtDataGasUsed: (*hexutil.Big)(e.ExtDataGasUsed),
+		BlockGasCost:   (*hexutil.Big)(e.BlockGasCost),
+	})
+}
+
+// UnmarshalJSON decodes the encoding produced by MarshalJSON. Missing or null
+// gas fields decode as nil.
+func (e *HeaderExtensions) UnmarshalJSON(input []byte) error {
+	var dec headerExtensionsJSON
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	e.ExtDataHash = dec.ExtDataHash
+	e.ExtDataGasUsed = (*big.Int)(dec.ExtDataGasUsed)
+	e.BlockGasCost = (*big.Int)(dec.BlockGasCost)
+	return nil
+}
+
+func copyBig(v *big.Int) *big.Int {
+	if v == nil {
+		return nil
+	}
+	return new(big.Int).Set(v)
+}
@@ -0,0 +1,59 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestHeaderExtensions(t *testing.T) {
+	// Headers produced before Apricot Phase 4 have no gas fields.
+	legacy := &Header{ExtDataHash: EmptyExtDataHash}
+	ext := legacy.Extensions()
+	if ext.ExtDataHash != EmptyExtDataHash || ext.ExtDataGasUsed != nil || ext.BlockGasCost != nil {
+		t.Fatalf("unexpected legacy extensions: %+v", ext)
+	}
+	enc, err := json.Marshal(ext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"extDataHash":"` + EmptyExtDataHash.Hex() + `","extDataGasUsed":null,"blockGasCost":null}`
+	if string(enc) != want {
+		t.Fatalf("have %s, want %s", enc, want)
+	}
+
+	header := &Header{
+		ExtDataHash:    common.Hash{1},
+		ExtDataGasUsed: big.NewInt(25_000),
+		BlockGasCost:   big.NewInt(100_000),
+	}
+	block := NewBlockWithHeader(header)
+	ext = block.Extensions()
+	if !reflect.DeepEqual(ext, header.Extensions()) {
+		t.Fatalf("block extensions %+v do not match header extensions", ext)
+	}
+
+	// The returned values are copies.
+	ext.BlockGasCost.SetUint64(0)
+	if block.BlockGasCost().Uint64() != 100_000 {
+		t.Fatal("modifying extensions modified the block header")
+	}
+
+	enc, err = json.Marshal(block.Extensions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dec HeaderExtensions
+	if err := json.Unmarshal(enc, &dec); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dec, block.Extensions()) {
+		t.Fatalf("have %+v, want %+v", dec, block.Extensions())
+	}
+}
@@ -76,6 +76,11 @@ type Receipt struct {
 	BlobGasUsed       uint64         `json:"blobGasUsed,omitempty"`
 	BlobGasPrice      *big.Int       `json:"blobGasPrice,omitempty"`
 
+	// Costs is the fee breakdown of the transaction. It is set when the
+	// transaction is processed and is only persisted, separately from the
+	// receipt, if enabled in the blockchain's cache config.
+	Costs *ReceiptCosts `json:"-"`
+
 	// Inclusion information: These fields provide information about the inclusion of the
 	// transaction corresponding to this receipt.
 	BlockHash        common.Hash `json:"blockHash,omitempty"`
@@ -215,16 +220,20 @@ func (r *Receipt) decodeTyped(b []byte) error {
 	}
 	switch b[0] {
 	case DynamicFeeTxType, AccessListTxType, BlobTxType:
-		var data receiptRLP
-		err := rlp.DecodeBytes(b[1:], &data)
-		if err != nil {
-			return err
-		}
-		r.Type = b[0]
-		return r.setFromRLP(data)
 	default:
-		return ErrTxTypeNotSupported
+		// Receipts of custom transaction types share the encoding of the
+		// builtin typed receipts.
+		if !IsCustomTxType(b[0]) {
+			return ErrTxTypeNotSupported
+		}
 	}
+	var data receiptRLP
+	err := rlp.DecodeBytes(b[1:], &data)
+	if err != nil {
+		return err
+	}
+	r.Type = b[0]
+	return r.setFromRLP(data)
 }
 
 func (r *Receipt) setFromRLP(data receiptRLP) error {
@@ -325,6 +334,10 @@ func (rs Receipts) EncodeIndex(i int, w *bytes.Buffer) {
 	case AccessListTxType, DynamicFeeTxType, BlobTxType:
 		rlp.Encode(w, data)
 	default:
+		if IsCustomTxType(r.Type) {
+			rlp.Encode(w, data)
+			return
+		}
 		// For unsupported types, write nothing. Since this is for
 		// DeriveSha, the error will be caught matching the derived hash
 		// to the block.
@@ -0,0 +1,34 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package types
+
+import (
+	"math/big"
+)
+
+// ReceiptCosts breaks down the fees paid by a transaction, so that they can be
+// accounted for without re-executing it.
+type ReceiptCosts struct {
+	BaseFeeBurned *big.Int // gas used times the block's base fee
+	TipPaid       *big.Int // gas used times the effective tip paid above the base fee
+	GasRefunded   uint64   // gas refunded after execution, not included in gas used
+}
+
+// NewReceiptCosts returns the fees paid by a transaction that used [gasUsed]
+// gas at the effective [gasPrice] in a block with [baseFee], after
+// [gasRefunded] gas was refunded. [baseFee] may be nil before the base fee was
+// introduced, in which case the whole gas price is treated as a tip.
+func NewReceiptCosts(gasUsed, gasRefunded uint64, gasPrice, baseFee *big.Int) *ReceiptCosts {
+	gas := new(big.Int).SetUint64(gasUsed)
+	costs := &ReceiptCosts{
+		BaseFeeBurned: new(big.Int),
+		TipPaid:       new(big.Int).Mul(gas, gasPrice),
+		GasRefunded:   gasRefunded,
+	}
+	if baseFee != nil {
+		costs.BaseFeeBurned.Mul(gas, baseFee)
+		costs.TipPaid.Sub(costs.TipPaid, costs.BaseFeeBurned)
+	}
+	return costs
+}
@@ -0,0 +1,196 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package types
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/holiman/uint256"
+)
+
+// SSZ encodings of headers, receipts and logs are provided for consumers,
+// such as proof systems, that work with SSZ rather than RLP. They are export
+// formats only: RLP remains the consensus encoding and the header hash is
+// unaffected.
+//
+// The SSZ schemas follow the field order of the RLP encodings. Integers
+// that are *big.Int fields are encoded as uint256, except for the header
+// number which is a uint64. Optional header fields that are unset are
+// encoded as zero.
+
+var errSSZOverflow = errors.New("value overflows its SSZ type")
+
+// sszField is a field of an SSZ container, with either a fixed or a variable
+// size.
+type sszField struct {
+	data     []byte
+	variable bool
+}
+
+func sszFixed(data []byte) sszField {
+	return sszField{data: data}
+}
+
+func sszVariable(data []byte) sszField {
+	return sszField{data: data, variable: true}
+}
+
+func sszUint8(v uint8) sszField {
+	return sszFixed([]byte{v})
+}
+
+func sszUint64(v uint64) sszField {
+	return sszFixed(binary.LittleEndian.AppendUint64(nil, v))
+}
+
+// sszUint256 encodes [v], which is zero if nil, as a uint256.
+func sszUint256(v *big.Int) (sszField, error) {
+	var u uint256.Int
+	if v != nil {
+		if v.Sign() < 0 {
+			return sszField{}, errSSZOverflow
+		}
+		if overflow := u.SetFromBig(v); overflow {
+			return sszField{}, errSSZOverflow
+		}
+	}
+	data := make([]byte, 0, 32)
+	for _, word := range u {
+		data = binary.LittleEndian.AppendUint64(data, word)
+	}
+	return sszFixed(data), nil
+}
+
+// sszContainer encodes [fields], writing the offsets of the variable size
+// fields in the fixed size part and their data after it.
+func sszContainer(fields ...sszField) []byte {
+	var fixedSize, size int
+	for _, field := range fields {
+		if field.variable {
+			fixedSize += 4
+		} else {
+			fixedSize += len(field.data)
+		}
+		size += len(field.data)
+	}
+
+	var (
+		out    = make([]byte, 0, fixedSize+size)
+		offset = fixedSize
+	)
+	for _, field := range fields {
+		if !field.variable {
+			out = append(out, field.data...)
+			continue
+		}
+		out = binary.LittleEndian.AppendUint32(out, uint32(offset))
+		offset += len(field.data)
+	}
+	for _, field := range fields {
+		if field.variable {
+			out = append(out, field.data...)
+		}
+	}
+	return out
+}
+
+// MarshalSSZ returns the SSZ encoding of the header.
+func (h *Header) MarshalSSZ() ([]byte, error) {
+	if h.Number == nil || !h.Number.IsUint64() {
+		return nil, errSSZOverflow
+	}
+	difficulty, err := sszUint256(h.Difficulty)
+	if err != nil {
+		return nil, err
+	}
+	baseFee, err := sszUint256(h.BaseFee)
+	if err != nil {
+		return nil, err
+	}
+	extDataGasUsed, err := sszUint256(h.ExtDataGasUsed)
+	if err != nil {
+		return nil, err
+	}
+	blockGasCost, err := sszUint256(h.BlockGasCost)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		blobGasUsed, excessBlobGas uint64
+		parentBeaconRoot           [32]byte
+	)
+	if h.BlobGasUsed != nil {
+		blobGasUsed = *h.BlobGasUsed
+	}
+	if h.ExcessBlobGas != nil {
+		excessBlobGas = *h.ExcessBlobGas
+	}
+	if h.ParentBeaconRoot != nil {
+		parentBeaconRoot = *h.ParentBeaconRoot
+	}
+	return sszContainer(
+		sszFixed(h.ParentHash[:]),
+		sszFixed(h.UncleHash[:]),
+		sszFixed(h.Coinbase[:]),
+		sszFixed(h.Root[:]),
+		sszFixed(h.TxHash[:]),
+		sszFixed(h.ReceiptHash[:]),
+		sszFixed(h.Bloom[:]),
+		difficulty,
+		sszUint64(h.Number.Uint64()),
+		sszUint64(h.GasLimit),
+		sszUint64(h.GasUsed),
+		sszUint64(h.Time),
+		sszVariable(h.Extra),
+		sszFixed(h.MixDigest[:]),
+		sszFixed(h.Nonce[:]),
+		sszFixed(h.ExtDataHash[:]),
+		baseFee,
+		extDataGasUsed,
+		blockGasCost,
+		sszUint64(blobGasUsed),
+		sszUint64(excessBlobGas),
+		sszFixed(parentBeaconRoot[:]),
+	), nil
+}
+
+// MarshalSSZ returns the SSZ encoding of the consensus fields of the receipt.
+func (r *Receipt) MarshalSSZ() ([]byte, error) {
+	// Logs are variable size, so the list starts with their offsets.
+	var (
+		offsets = make([]byte, 0, 4*len(r.Logs))
+		logs    []byte
+	)
+	for _, log := range r.Logs {
+		offsets = binary.LittleEndian.AppendUint32(offsets, uint32(4*len(r.Logs)+len(logs)))
+		enc, err := log.MarshalSSZ()
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, enc...)
+	}
+	return sszContainer(
+		sszUint8(r.Type),
+		sszVariable(r.PostState),
+		sszUint64(r.Status),
+		sszUint64(r.CumulativeGasUsed),
+		sszFixed(r.Bloom[:]),
+		sszVariable(append(offsets, logs...)),
+	), nil
+}
+
+// MarshalSSZ returns the SSZ encoding of the consensus fields of the log.
+func (l *Log) MarshalSSZ() ([]byte, error) {
+	topics := make([]byte, 0, 32*len(l.Topics))
+	for _, topic := range l.Topics {
+		topics = append(topics, topic[:]...)
+	}
+	return sszContainer(
+		sszFixed(l.Address[:]),
+		sszVariable(topics),
+		sszVariable(l.Data),
+	), nil
+}
@@ -0,0 +1,117 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package types
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestLogMarshalSSZ(t *testing.T) {
+	log := &Log{
+		Address: common.HexToAddress("0x1111111111111111111111111111111111111111"),
+		Topics:  []common.Hash{common.HexToHash("0x2222222222222222222222222222222222222222222222222222222222222222")},
+		Data:    []byte{0xab, 0xcd},
+		// Derived fields are not encoded.
+		BlockNumber: 1,
+	}
+	want := common.FromHex("0x" +
+		"1111111111111111111111111111111111111111" + // address
+		"1c000000" + // topics offset
+		"3c000000" + // data offset
+		"2222222222222222222222222222222222222222222222222222222222222222" +
+		"abcd")
+	enc, err := log.MarshalSSZ()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(enc, want) {
+		t.Fatalf("log SSZ mismatch: got %x, want %x", enc, want)
+	}
+}
+
+func TestReceiptMarshalSSZ(t *testing.T) {
+	log := &Log{
+		Address: common.Address{0x11},
+		Topics:  []common.Hash{{0x22}},
+	}
+	receipt := &Receipt{
+		Type:              DynamicFeeTxType,
+		Status:            ReceiptStatusSuccessful,
+		CumulativeGasUsed: 21000,
+		Logs:              []*Log{log},
+	}
+	logEnc, err := log.MarshalSSZ()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const fixedSize = 1 + 4 + 8 + 8 + BloomByteLength + 4
+	var want []byte
+	want = append(want, DynamicFeeTxType)
+	want = binary.LittleEndian.AppendUint32(want, fixedSize) // empty post state
+	want = binary.LittleEndian.AppendUint64(want, ReceiptStatusSuccessful)
+	want = binary.LittleEndian.AppendUint64(want, 21000)
+	want = append(want, make([]byte, BloomByteLength)...)
+	want = binary.LittleEndian.AppendUint32(want, fixedSize) // logs
+	want = binary.LittleEndian.AppendUint32(want, 4)         // first log
+	want = append(want, logEnc...)
+
+	enc, err := receipt.MarshalSSZ()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(enc, want) {
+		t.Fatalf("receipt SSZ mismatch: got %x, want %x", enc, want)
+	}
+}
+
+func TestHeaderMarshalSSZ(t *testing.T) {
+	header := &Header{
+		Difficulty: big.NewInt(1),
+		Number:     big.NewInt(0x0102),
+		Extra:      []byte{0xee},
+		BaseFee:    big.NewInt(25_000_000_000),
+	}
+	enc, err := header.MarshalSSZ()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const (
+		difficultyOffset = 32 + 32 + 20 + 32 + 32 + 32 + BloomByteLength
+		numberOffset     = difficultyOffset + 32
+		extraOffset      = numberOffset + 4*8
+		baseFeeOffset    = extraOffset + 4 + 32 + 8 + 32
+		fixedSize        = baseFeeOffset + 3*32 + 2*8 + 32
+	)
+	if len(enc) != fixedSize+len(header.Extra) {
+		t.Fatalf("header SSZ length: got %d, want %d", len(enc), fixedSize+len(header.Extra))
+	}
+	if enc[difficultyOffset] != 1 {
+		t.Fatalf("difficulty not encoded little endian: %x", enc[difficultyOffset:difficultyOffset+32])
+	}
+	if number := binary.LittleEndian.Uint64(enc[numberOffset:]); number != 0x0102 {
+		t.Fatalf("number: got %d, want %d", number, 0x0102)
+	}
+	if offset := binary.LittleEndian.Uint32(enc[extraOffset:]); offset != fixedSize {
+		t.Fatalf("extra offset: got %d, want %d", offset, fixedSize)
+	}
+	if baseFee := binary.LittleEndian.Uint64(enc[baseFeeOffset:]); baseFee != 25_000_000_000 {
+		t.Fatalf("base fee: got %d, want %d", baseFee, 25_000_000_000)
+	}
+	if enc[fixedSize] != 0xee {
+		t.Fatalf("extra data: got %x, want ee", enc[fixedSize:])
+	}
+
+	header.Difficulty = new(big.Int).Lsh(big.NewInt(1), 256)
+	if _, err := header.MarshalSSZ(); !errors.Is(err, errSSZOverflow) {
+		t.Fatalf("expected %v for an overflowing difficulty, got %v", errSSZOverflow, err)
+	}
+}
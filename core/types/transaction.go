@@ -80,7 +80,8 @@ func NewTx(inner TxData) *Transaction {
 
 // TxData is the underlying data of a transaction.
 //
-// This is implemented by DynamicFeeTx, LegacyTx and AccessListTx.
+// This is implemented by DynamicFeeTx, LegacyTx, AccessListTx, BlobTx and by
+// the adapter of custom transaction types.
 type TxData interface {
 	txType() byte // returns the type ID
 	copy() TxData // creates a deep copy and initializes all fields
@@ -216,7 +217,11 @@ func (tx *Transaction) decodeTyped(b []byte) (TxData, error) {
 	case BlobTxType:
 		inner = new(BlobTx)
 	default:
-		return nil, ErrTxTypeNotSupported
+		t, ok := GetCustomTxType(b[0])
+		if !ok {
+			return nil, ErrTxTypeNotSupported
+		}
+		inner = &customTx{typ: t.Type, inner: t.New()}
 	}
 	err := inner.decode(b[1:])
 	return inner, err
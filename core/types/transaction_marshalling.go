@@ -152,6 +152,26 @@ func (tx *Transaction) MarshalJSON() ([]byte, error) {
 		enc.S = (*hexutil.Big)(itx.S.ToBig())
 		yparity := itx.V.Uint64()
 		enc.YParity = (*hexutil.Uint64)(&yparity)
+
+	case *customTx:
+		enc.ChainID = (*hexutil.Big)(tx.ChainId())
+		nonce, gas := tx.Nonce(), tx.Gas()
+		enc.Nonce = (*hexutil.Uint64)(&nonce)
+		enc.To = tx.To()
+		enc.Gas = (*hexutil.Uint64)(&gas)
+		enc.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		enc.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+		enc.Value = (*hexutil.Big)(tx.Value())
+		input := hexutil.Bytes(tx.Data())
+		enc.Input = &input
+		accessList := tx.AccessList()
+		enc.AccessList = &accessList
+		v, r, s := tx.RawSignatureValues()
+		enc.V = (*hexutil.Big)(v)
+		enc.R = (*hexutil.Big)(r)
+		enc.S = (*hexutil.Big)(s)
+		yparity := v.Uint64()
+		enc.YParity = (*hexutil.Uint64)(&yparity)
 	}
 	return json.Marshal(&enc)
 }
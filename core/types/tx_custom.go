@@ -0,0 +1,244 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package types
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// MinCustomTxType is the lowest type that may be registered with
+// [RegisterTxType]. Lower types are reserved for the builtin transactions.
+const MinCustomTxType = 0x04
+
+// MaxCustomTxType is the highest type that may be registered with
+// [RegisterTxType], as EIP-2718 reserves higher leading bytes for legacy
+// transactions.
+const MaxCustomTxType = 0x7f
+
+var (
+	errCustomTxTypeRange      = fmt.Errorf("custom transaction type must be between %#x and %#x", MinCustomTxType, MaxCustomTxType)
+	errCustomTxTypeRegistered = errors.New("custom transaction type already registered")
+	errCustomTxTypeNew        = errors.New("custom transaction type must define New")
+)
+
+// CustomTxData is the payload of a transaction of a type registered with
+// [RegisterTxType]. It mirrors [TxData] with exported methods, so that new
+// transaction types can be implemented outside of this package.
+//
+// Signatures of custom transactions use 0 and 1 as their recovery id, like
+// EIP-1559 transactions.
+type CustomTxData interface {
+	// Copy creates a deep copy and initializes all fields.
+	Copy() CustomTxData
+
+	ChainID() *big.Int
+	AccessList() AccessList
+	Data() []byte
+	Gas() uint64
+	GasPrice() *big.Int
+	GasTipCap() *big.Int
+	GasFeeCap() *big.Int
+	Value() *big.Int
+	Nonce() uint64
+	To() *common.Address
+
+	RawSignatureValues() (v, r, s *big.Int)
+	SetSignatureValues(chainID, v, r, s *big.Int)
+
+	// EffectiveGasPrice computes the gas price paid by the transaction,
+	// given the inclusion block baseFee, and may store the result in dst.
+	EffectiveGasPrice(dst *big.Int, baseFee *big.Int) *big.Int
+
+	// Encode writes the payload, without the type byte, to the buffer, and
+	// Decode reads it back. The transaction hash is the hash of the type
+	// byte followed by the payload.
+	Encode(*bytes.Buffer) error
+	Decode([]byte) error
+}
+
+// CustomTxType describes a transaction type registered with [RegisterTxType].
+// Transactions of the type are decoded by every node, but are only valid in
+// blocks once the type is enabled by the upgrade config of the chain.
+type CustomTxType struct {
+	// Type is the EIP-2718 type byte of the transactions.
+	Type byte
+	// New returns an empty payload to decode transactions into.
+	New func() CustomTxData
+	// SigHash returns the hash signed by the sender of [tx] on the chain
+	// [chainID]. If nil, the hash covers the fields of an EIP-1559
+	// transaction, prefixed with Type.
+	SigHash func(tx *Transaction, chainID *big.Int) common.Hash
+	// Validate checks the rules of the type that are independent of the
+	// state before a transaction is accepted by the transaction pool or
+	// included in a block. It may be nil.
+	Validate func(data CustomTxData) error
+	// IntrinsicGas returns the gas charged for the transaction in addition
+	// to the intrinsic gas of its data and access list. It may be nil.
+	IntrinsicGas func(data CustomTxData) (uint64, error)
+}
+
+var (
+	customTxTypesLock sync.RWMutex
+	customTxTypes     = make(map[byte]*CustomTxType)
+)
+
+// RegisterTxType registers a custom transaction type. It is expected to be
+// called from an init function, before transactions are decoded.
+func RegisterTxType(t CustomTxType) error {
+	if t.Type < MinCustomTxType || t.Type > MaxCustomTxType {
+		return fmt.Errorf("%w: %#x", errCustomTxTypeRange, t.Type)
+	}
+	if t.New == nil {
+		return fmt.Errorf("%w: %#x", errCustomTxTypeNew, t.Type)
+	}
+	customTxTypesLock.Lock()
+	defer customTxTypesLock.Unlock()
+
+	if _, ok := customTxTypes[t.Type]; ok {
+		return fmt.Errorf("%w: %#x", errCustomTxTypeRegistered, t.Type)
+	}
+	customTxTypes[t.Type] = &t
+	return nil
+}
+
+// GetCustomTxType returns the custom transaction type registered as [typ].
+func GetCustomTxType(typ byte) (*CustomTxType, bool) {
+	customTxTypesLock.RLock()
+	defer customTxTypesLock.RUnlock()
+
+	t, ok := customTxTypes[typ]
+	return t, ok
+}
+
+// IsCustomTxType returns true if [typ] is a registered custom transaction
+// type.
+func IsCustomTxType(typ byte) bool {
+	_, ok := GetCustomTxType(typ)
+	return ok
+}
+
+// NewCustomTx creates a new transaction of the registered custom type [typ].
+func NewCustomTx(typ byte, data CustomTxData) (*Transaction, error) {
+	if !IsCustomTxType(typ) {
+		return nil, fmt.Errorf("%w: %#x", ErrTxTypeNotSupported, typ)
+	}
+	return NewTx(&customTx{typ: typ, inner: data}), nil
+}
+
+// CustomTxData returns the payload of a custom transaction, or nil if the
+// transaction is of a builtin type.
+func (tx *Transaction) CustomTxData() CustomTxData {
+	if inner, ok := tx.inner.(*customTx); ok {
+		return inner.inner
+	}
+	return nil
+}
+
+// ValidateCustomTx checks the rules of the custom type of [tx] that are
+// independent of the state. It returns nil for builtin types.
+func ValidateCustomTx(tx *Transaction) error {
+	inner, ok := tx.inner.(*customTx)
+	if !ok {
+		return nil
+	}
+	if t, _ := GetCustomTxType(inner.typ); t.Validate != nil {
+		return t.Validate(inner.inner)
+	}
+	return nil
+}
+
+// CustomIntrinsicGas returns the gas charged by the custom type of [tx] in
+// addition to its intrinsic gas. It returns zero for builtin types.
+func CustomIntrinsicGas(tx *Transaction) (uint64, error) {
+	inner, ok := tx.inner.(*customTx)
+	if !ok {
+		return 0, nil
+	}
+	if t, _ := GetCustomTxType(inner.typ); t.IntrinsicGas != nil {
+		return t.IntrinsicGas(inner.inner)
+	}
+	return 0, nil
+}
+
+// customSigHash returns the hash signed by the sender of the custom
+// transaction [tx] on the chain [chainID].
+func customSigHash(tx *Transaction, chainID *big.Int) common.Hash {
+	if t, _ := GetCustomTxType(tx.Type()); t.SigHash != nil {
+		return t.SigHash(tx, chainID)
+	}
+	return prefixedRlpHash(
+		tx.Type(),
+		[]interface{}{
+			chainID,
+			tx.Nonce(),
+			tx.GasTipCap(),
+			tx.GasFeeCap(),
+			tx.Gas(),
+			tx.To(),
+			tx.Value(),
+			tx.Data(),
+			tx.AccessList(),
+		})
+}
+
+// customTx adapts a [CustomTxData] to [TxData].
+type customTx struct {
+	typ   byte
+	inner CustomTxData
+}
+
+func (tx *customTx) copy() TxData {
+	return &customTx{typ: tx.typ, inner: tx.inner.Copy()}
+}
+
+// accessors for innerTx.
+func (tx *customTx) txType() byte           { return tx.typ }
+func (tx *customTx) chainID() *big.Int      { return tx.inner.ChainID() }
+func (tx *customTx) accessList() AccessList { return tx.inner.AccessList() }
+func (tx *customTx) data() []byte           { return tx.inner.Data() }
+func (tx *customTx) gas() uint64            { return tx.inner.Gas() }
+func (tx *customTx) gasFeeCap() *big.Int    { return tx.inner.GasFeeCap() }
+func (tx *customTx) gasTipCap() *big.Int    { return tx.inner.GasTipCap() }
+func (tx *customTx) gasPrice() *big.Int     { return tx.inner.GasPrice() }
+func (tx *customTx) value() *big.Int        { return tx.inner.Value() }
+func (tx *customTx) nonce() uint64          { return tx.inner.Nonce() }
+func (tx *customTx) to() *common.Address    { return tx.inner.To() }
+
+func (tx *customTx) effectiveGasPrice(dst *big.Int, baseFee *big.Int) *big.Int {
+	return tx.inner.EffectiveGasPrice(dst, baseFee)
+}
+
+func (tx *customTx) rawSignatureValues() (v, r, s *big.Int) {
+	return tx.inner.RawSignatureValues()
+}
+
+func (tx *customTx) setSignatureValues(chainID, v, r, s *big.Int) {
+	tx.inner.SetSignatureValues(chainID, v, r, s)
+}
+
+func (tx *customTx) encode(b *bytes.Buffer) error {
+	return tx.inner.Encode(b)
+}
+
+func (tx *customTx) decode(input []byte) error {
+	return tx.inner.Decode(input)
+}
+
+// EncodeRLP implements rlp.Encoder, writing the payload as is so that the
+// hash and size of the transaction match its canonical encoding.
+func (tx *customTx) EncodeRLP(w io.Writer) error {
+	var buf bytes.Buffer
+	if err := tx.inner.Encode(&buf); err != nil {
+		return err
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
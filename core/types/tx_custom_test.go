@@ -0,0 +1,237 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package types
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/shubhamdubey02/coreth/params"
+)
+
+const testCustomTxType = 0x7e
+
+var errTestCustomTxValue = errors.New("value must be zero")
+
+// testCustomTx is a custom transaction type with the fields of a dynamic fee
+// transaction.
+type testCustomTx struct{ tx DynamicFeeTx }
+
+func (t *testCustomTx) Copy() CustomTxData {
+	return &testCustomTx{tx: *t.tx.copy().(*DynamicFeeTx)}
+}
+func (t *testCustomTx) ChainID() *big.Int      { return t.tx.chainID() }
+func (t *testCustomTx) AccessList() AccessList { return t.tx.accessList() }
+func (t *testCustomTx) Data() []byte           { return t.tx.data() }
+func (t *testCustomTx) Gas() uint64            { return t.tx.gas() }
+func (t *testCustomTx) GasPrice() *big.Int     { return t.tx.gasPrice() }
+func (t *testCustomTx) GasTipCap() *big.Int    { return t.tx.gasTipCap() }
+func (t *testCustomTx) GasFeeCap() *big.Int    { return t.tx.gasFeeCap() }
+func (t *testCustomTx) Value() *big.Int        { return t.tx.value() }
+func (t *testCustomTx) Nonce() uint64          { return t.tx.nonce() }
+func (t *testCustomTx) To() *common.Address    { return t.tx.to() }
+
+func (t *testCustomTx) RawSignatureValues() (v, r, s *big.Int) {
+	return t.tx.rawSignatureValues()
+}
+
+func (t *testCustomTx) SetSignatureValues(chainID, v, r, s *big.Int) {
+	t.tx.setSignatureValues(chainID, v, r, s)
+}
+
+func (t *testCustomTx) EffectiveGasPrice(dst *big.Int, baseFee *big.Int) *big.Int {
+	return t.tx.effectiveGasPrice(dst, baseFee)
+}
+
+func (t *testCustomTx) Encode(b *bytes.Buffer) error { return t.tx.encode(b) }
+func (t *testCustomTx) Decode(b []byte) error        { return t.tx.decode(b) }
+
+func init() {
+	err := RegisterTxType(CustomTxType{
+		Type: testCustomTxType,
+		New:  func() CustomTxData { return new(testCustomTx) },
+		Validate: func(data CustomTxData) error {
+			if data.Value().Sign() != 0 {
+				return errTestCustomTxValue
+			}
+			return nil
+		},
+		IntrinsicGas: func(data CustomTxData) (uint64, error) {
+			return 1000, nil
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func TestRegisterTxType(t *testing.T) {
+	newData := func() CustomTxData { return new(testCustomTx) }
+	tests := []struct {
+		typ     CustomTxType
+		wantErr error
+	}{
+		{typ: CustomTxType{Type: DynamicFeeTxType, New: newData}, wantErr: errCustomTxTypeRange},
+		{typ: CustomTxType{Type: 0x80, New: newData}, wantErr: errCustomTxTypeRange},
+		{typ: CustomTxType{Type: 0x10}, wantErr: errCustomTxTypeNew},
+		{typ: CustomTxType{Type: testCustomTxType, New: newData}, wantErr: errCustomTxTypeRegistered},
+	}
+	for _, tt := range tests {
+		if err := RegisterTxType(tt.typ); !errors.Is(err, tt.wantErr) {
+			t.Errorf("type %#x: got error %v, want %v", tt.typ.Type, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCustomTx(t *testing.T) {
+	var (
+		key, _  = crypto.GenerateKey()
+		addr    = crypto.PubkeyToAddress(key.PublicKey)
+		to      = common.Address{0x01}
+		chainID = big.NewInt(43114)
+		signer  = NewCancunSigner(chainID)
+	)
+	tx, err := NewCustomTx(testCustomTxType, &testCustomTx{tx: DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     1,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(2),
+		Gas:       25000,
+		To:        &to,
+		Value:     new(big.Int),
+		Data:      []byte{0xde, 0xad},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err = SignTx(tx, signer, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	enc, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if enc[0] != testCustomTxType {
+		t.Fatalf("encoded type %#x, want %#x", enc[0], testCustomTxType)
+	}
+	var dec Transaction
+	if err := dec.UnmarshalBinary(enc); err != nil {
+		t.Fatal(err)
+	}
+	if dec.Hash() != tx.Hash() {
+		t.Fatalf("decoded hash %s, want %s", dec.Hash(), tx.Hash())
+	}
+	from, err := Sender(signer, &dec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from != addr {
+		t.Fatalf("sender %s, want %s", from, addr)
+	}
+	if _, err := Sender(NewCancunSigner(big.NewInt(1)), &dec); !errors.Is(err, ErrInvalidChainId) {
+		t.Fatalf("got error %v, want %v", err, ErrInvalidChainId)
+	}
+
+	if err := ValidateCustomTx(&dec); err != nil {
+		t.Fatal(err)
+	}
+	if gas, err := CustomIntrinsicGas(&dec); err != nil || gas != 1000 {
+		t.Fatalf("got intrinsic gas (%d, %v), want 1000", gas, err)
+	}
+	if gas, err := CustomIntrinsicGas(NewTx(&DynamicFeeTx{})); err != nil || gas != 0 {
+		t.Fatalf("got intrinsic gas (%d, %v) for builtin type, want 0", gas, err)
+	}
+
+	var unregistered Transaction
+	if err := unregistered.UnmarshalBinary(append([]byte{0x7d}, enc[1:]...)); !errors.Is(err, ErrTxTypeNotSupported) {
+		t.Fatalf("got error %v, want %v", err, ErrTxTypeNotSupported)
+	}
+}
+
+func TestCustomTxReceipt(t *testing.T) {
+	to := common.Address{0x01}
+	tx, err := NewCustomTx(testCustomTxType, &testCustomTx{tx: DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(2),
+		Gas:       25000,
+		To:        &to,
+		Value:     new(big.Int),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	receipt := &Receipt{
+		Type:              testCustomTxType,
+		Status:            ReceiptStatusSuccessful,
+		CumulativeGasUsed: 21000,
+		Logs:              []*Log{{Address: to, Topics: []common.Hash{{0x02}}, Data: []byte{0x03}}},
+	}
+	receipt.Bloom = CreateBloom(Receipts{receipt})
+
+	// The consensus encoding is the type followed by the RLP of the receipt.
+	enc, err := receipt.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := rlp.EncodeToBytes(&receiptRLP{receipt.statusEncoding(), receipt.CumulativeGasUsed, receipt.Bloom, receipt.Logs})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := append([]byte{testCustomTxType}, body...); !bytes.Equal(enc, want) {
+		t.Fatalf("encoded receipt %x, want %x", enc, want)
+	}
+	var buf bytes.Buffer
+	Receipts{receipt}.EncodeIndex(0, &buf)
+	if !bytes.Equal(buf.Bytes(), enc) {
+		t.Fatalf("indexed encoding %x, want %x", buf.Bytes(), enc)
+	}
+
+	var dec Receipt
+	if err := dec.UnmarshalBinary(enc); err != nil {
+		t.Fatal(err)
+	}
+	if dec.Type != testCustomTxType || dec.Status != receipt.Status || dec.CumulativeGasUsed != receipt.CumulativeGasUsed || dec.Bloom != receipt.Bloom {
+		t.Fatalf("decoded receipt %+v, want %+v", dec, receipt)
+	}
+	rlpEnc, err := rlp.EncodeToBytes(receipt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rlpDec Receipt
+	if err := rlp.DecodeBytes(rlpEnc, &rlpDec); err != nil {
+		t.Fatal(err)
+	}
+	if rlpDec.Type != testCustomTxType {
+		t.Fatalf("decoded receipt type %#x, want %#x", rlpDec.Type, testCustomTxType)
+	}
+
+	// The stored receipt recovers its type from the transaction.
+	stored, err := rlp.EncodeToBytes((*ReceiptForStorage)(receipt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var storedDec ReceiptForStorage
+	if err := rlp.DecodeBytes(stored, &storedDec); err != nil {
+		t.Fatal(err)
+	}
+	receipts := Receipts{(*Receipt)(&storedDec)}
+	if err := receipts.DeriveFields(params.TestChainConfig, common.Hash{}, 0, 0, big.NewInt(1), nil, []*Transaction{tx}); err != nil {
+		t.Fatal(err)
+	}
+	if receipts[0].Type != testCustomTxType || receipts[0].Bloom != receipt.Bloom {
+		t.Fatalf("stored receipt %+v, want %+v", receipts[0], receipt)
+	}
+
+	if err := dec.UnmarshalBinary(append([]byte{0x7d}, enc[1:]...)); !errors.Is(err, ErrTxTypeNotSupported) {
+		t.Fatalf("got error %v, want %v", err, ErrTxTypeNotSupported)
+	}
+}
@@ -96,7 +96,7 @@ func (evm *EVM) precompile(addr common.Address) (contract.StatefulPrecompiledCon
 	// Check the existing precompiles first
 	p, ok := precompiles[addr]
 	if ok {
-		return p, true
+		return evm.Config.GasOverrides.precompile(addr, p), true
 	}
 
 	// Otherwise, check the chain rules for the additionally configured precompiles.
@@ -649,7 +649,7 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 	ret, err := evm.interpreter.Run(contract, nil, false)
 
 	// Check whether the max code size has been exceeded, assign err if the case.
-	if err == nil && evm.chainRules.IsEIP158 && len(ret) > params.MaxCodeSize {
+	if err == nil && evm.chainRules.IsEIP158 && len(ret) > evm.chainRules.MaxCodeSize {
 		err = vmerrs.ErrMaxCodeSizeExceeded
 	}
 
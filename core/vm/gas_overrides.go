@@ -0,0 +1,119 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shubhamdubey02/coreth/precompile/contract"
+)
+
+// GasOverrides replaces the gas costs of opcodes and native precompiled
+// contracts. It is only meant for simulations, such as calls served by the
+// API, as blocks executed with it are invalid.
+type GasOverrides struct {
+	// Opcodes replaces the constant and dynamic gas of each opcode, except
+	// for the gas of the memory expansion it causes.
+	Opcodes map[OpCode]uint64
+	// Precompiles replaces the gas required by each native precompiled
+	// contract, regardless of its input.
+	Precompiles map[common.Address]uint64
+}
+
+// Validate returns an error if [o] overrides the gas of an opcode whose gas
+// cannot be replaced, or of an address which is not a native precompiled
+// contract.
+func (o *GasOverrides) Validate() error {
+	if o == nil {
+		return nil
+	}
+	for op := range o.Opcodes {
+		switch op {
+		case CALL, CALLCODE, DELEGATECALL, STATICCALL, CALLEX:
+			// The dynamic gas of calls determines the gas available to the
+			// callee.
+			return fmt.Errorf("cannot override the gas of %s", op)
+		}
+	}
+	for addr := range o.Precompiles {
+		if !isNativePrecompile(addr) {
+			return fmt.Errorf("cannot override the gas of %s, which is not a native precompiled contract", addr)
+		}
+	}
+	return nil
+}
+
+// applyOpcodes replaces the gas of the opcodes of [table], which must be a
+// copy of the instruction set.
+func (o *GasOverrides) applyOpcodes(table *JumpTable) {
+	if o == nil {
+		return
+	}
+	for op, gas := range o.Opcodes {
+		operation := table[op]
+		operation.constantGas = gas
+		operation.dynamicGas = nil
+		if operation.memorySize != nil {
+			// The interpreter only expands the memory of opcodes with dynamic
+			// gas.
+			operation.dynamicGas = gasMemoryExpansion
+		}
+	}
+}
+
+// precompile returns [p], the precompiled contract at [addr], with its gas
+// replaced if it is overridden.
+func (o *GasOverrides) precompile(addr common.Address, p contract.StatefulPrecompiledContract) contract.StatefulPrecompiledContract {
+	if o == nil {
+		return p
+	}
+	gas, ok := o.Precompiles[addr]
+	if !ok {
+		return p
+	}
+	wrapped, ok := p.(*wrappedPrecompiledContract)
+	if !ok {
+		return p
+	}
+	return newWrappedPrecompiledContract(&fixedGasPrecompile{PrecompiledContract: wrapped.p, gas: gas})
+}
+
+// hasOpcodes returns whether [o] overrides the gas of any opcode.
+func (o *GasOverrides) hasOpcodes() bool {
+	return o != nil && len(o.Opcodes) > 0
+}
+
+func gasMemoryExpansion(evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+	return memoryGasCost(mem, memorySize)
+}
+
+// fixedGasPrecompile is a native precompiled contract requiring a fixed
+// amount of gas.
+type fixedGasPrecompile struct {
+	PrecompiledContract
+	gas uint64
+}
+
+func (f *fixedGasPrecompile) RequiredGas([]byte) uint64 {
+	return f.gas
+}
+
+func isNativePrecompile(addr common.Address) bool {
+	for _, precompiles := range []map[common.Address]contract.StatefulPrecompiledContract{
+		PrecompiledContractsHomestead,
+		PrecompiledContractsByzantium,
+		PrecompiledContractsIstanbul,
+		PrecompiledContractsApricotPhase2,
+		PrecompiledContractsApricotPhasePre6,
+		PrecompiledContractsApricotPhase6,
+		PrecompiledContractsBanff,
+		PrecompiledContractsCancun,
+	} {
+		if _, ok := precompiles[addr].(*wrappedPrecompiledContract); ok {
+			return true
+		}
+	}
+	return false
+}
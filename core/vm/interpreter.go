@@ -45,6 +45,11 @@ type Config struct {
 	NoBaseFee               bool      // Forces the EIP-1559 baseFee to 0 (needed for 0 price calls)
 	EnablePreimageRecording bool      // Enables recording of SHA3/keccak preimages
 	ExtraEips               []int     // Additional EIPS that are to be enabled
+
+	OpcodeStats  *OpcodeStats // Records the opcodes of sampled transactions if non-nil
+	ReceiptCosts bool         // Records the fee breakdown of each receipt
+
+	GasOverrides *GasOverrides // Replaces the gas costs of opcodes and precompiles, for simulations only
 }
 
 // ScopeContext contains the things that are per-call, such as stack and memory,
@@ -65,6 +70,7 @@ type EVMInterpreter struct {
 
 	readOnly   bool   // Whether to throw on stateful modifications
 	returnData []byte // Last CALL's return data for subsequent reuse
+	sampled    bool   // Whether the opcodes of the current transaction are recorded
 }
 
 // NewEVMInterpreter returns a new instance of the Interpreter.
@@ -98,7 +104,7 @@ func NewEVMInterpreter(evm *EVM) *EVMInterpreter {
 		table = &frontierInstructionSet
 	}
 	var extraEips []int
-	if len(evm.Config.ExtraEips) > 0 {
+	if len(evm.Config.ExtraEips) > 0 || evm.Config.GasOverrides.hasOpcodes() {
 		// Deep-copy jumptable to prevent modification of opcodes in other tables
 		table = copyJumpTable(table)
 	}
@@ -111,6 +117,7 @@ func NewEVMInterpreter(evm *EVM) *EVMInterpreter {
 		}
 	}
 	evm.Config.ExtraEips = extraEips
+	evm.Config.GasOverrides.applyOpcodes(table)
 	return &EVMInterpreter{evm: evm, table: table}
 }
 
@@ -137,6 +144,11 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 	in.evm.depth++
 	defer func() { in.evm.depth-- }()
 
+	// Decide whether to record the opcodes of the transaction on its outermost call.
+	if stats := in.evm.Config.OpcodeStats; stats != nil && in.evm.depth == 1 {
+		in.sampled = stats.sample()
+	}
+
 	// Make sure the readOnly is only set if we aren't in readOnly yet.
 	// This also makes sure that the readOnly flag isn't removed for child calls.
 	if readOnly && !in.readOnly {
@@ -175,6 +187,7 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 		logged  bool   // deferred EVMLogger should ignore already logged steps
 		res     []byte // result of the opcode execution function
 		debug   = in.evm.Config.Tracer != nil
+		sampled = in.sampled
 	)
 
 	// Don't move this deferred function, it's placed before the capturestate-deferred method,
@@ -259,6 +272,10 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 			logged = true
 		}
 
+		if sampled {
+			in.evm.Config.OpcodeStats.record(op, cost)
+		}
+
 		// execute the operation
 		res, err = operation.execute(&pc, in, callContext)
 		if err != nil {
@@ -0,0 +1,79 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"sync/atomic"
+
+	"github.com/shubhamdubey02/coreth/metrics"
+)
+
+// OpcodeStat is the number of sampled executions of an opcode and the gas
+// they were charged.
+type OpcodeStat struct {
+	Count uint64 `json:"count"`
+	Gas   uint64 `json:"gas"`
+}
+
+// OpcodeStats records the frequency and gas of the opcodes executed by a
+// sample of the transactions, to guide gas schedule tuning. The gas of an
+// opcode includes the gas it forwards to calls.
+type OpcodeStats struct {
+	sampleRate uint64
+	txs        atomic.Uint64
+
+	counts [256]atomic.Uint64
+	gas    [256]atomic.Uint64
+
+	countCounters [256]metrics.Counter
+	gasCounters   [256]metrics.Counter
+}
+
+// NewOpcodeStats returns an OpcodeStats recording the opcodes of one in every
+// [sampleRate] transactions, which must be positive.
+func NewOpcodeStats(sampleRate uint64) *OpcodeStats {
+	s := &OpcodeStats{sampleRate: sampleRate}
+	for op, name := range opCodeToString {
+		if name == "" {
+			continue
+		}
+		s.countCounters[op] = metrics.GetOrRegisterCounter("vm/opcodes/"+name+"/count", nil)
+		s.gasCounters[op] = metrics.GetOrRegisterCounter("vm/opcodes/"+name+"/gas", nil)
+	}
+	return s
+}
+
+// sample reports whether the opcodes of the next transaction are recorded.
+func (s *OpcodeStats) sample() bool {
+	return s.txs.Add(1)%s.sampleRate == 0
+}
+
+// record adds an execution of [op] charged [gas].
+func (s *OpcodeStats) record(op OpCode, gas uint64) {
+	s.counts[op].Add(1)
+	s.gas[op].Add(gas)
+	if counter := s.countCounters[op]; counter != nil {
+		counter.Inc(1)
+		s.gasCounters[op].Inc(int64(gas))
+	}
+}
+
+// Dump returns the statistics of every opcode executed by the sampled
+// transactions.
+func (s *OpcodeStats) Dump() map[string]OpcodeStat {
+	dump := make(map[string]OpcodeStat)
+	for op := range s.counts {
+		count := s.counts[op].Load()
+		if count == 0 {
+			continue
+		}
+		dump[OpCode(op).String()] = OpcodeStat{Count: count, Gas: s.gas[op].Load()}
+	}
+	return dump
+}
+
+// SampledTxs returns the number of transactions whose opcodes were recorded.
+func (s *OpcodeStats) SampledTxs() uint64 {
+	return s.txs.Load() / s.sampleRate
+}
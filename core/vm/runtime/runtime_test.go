@@ -27,6 +27,7 @@
 package runtime
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 	"os"
@@ -45,6 +46,7 @@ import (
 	"github.com/shubhamdubey02/coreth/eth/tracers"
 	"github.com/shubhamdubey02/coreth/eth/tracers/logger"
 	"github.com/shubhamdubey02/coreth/params"
+	"github.com/shubhamdubey02/coreth/vmerrs"
 
 	// force-load js tracers to trigger registration
 	_ "github.com/shubhamdubey02/coreth/eth/tracers/js"
@@ -135,6 +137,67 @@ func TestCall(t *testing.T) {
 	}
 }
 
+func TestGasOverrides(t *testing.T) {
+	address := common.HexToAddress("0xcc")
+	newState := func() *state.StateDB {
+		state, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		state.SetCode(address, []byte{
+			byte(vm.PUSH1), 10,
+			byte(vm.PUSH1), 0,
+			byte(vm.MSTORE),
+			byte(vm.PUSH1), 0,
+			byte(vm.SLOAD),
+			byte(vm.POP),
+			byte(vm.PUSH1), 32,
+			byte(vm.PUSH1), 0,
+			byte(vm.RETURN),
+		})
+		return state
+	}
+	_, leftOver, err := Call(address, nil, &Config{State: newState()})
+	if err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+
+	overrides := &vm.GasOverrides{
+		Opcodes:     map[vm.OpCode]uint64{vm.SLOAD: 0, vm.MSTORE: 0},
+		Precompiles: map[common.Address]uint64{common.BytesToAddress([]byte{2}): 0},
+	}
+	if err := overrides.Validate(); err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	ret, overriddenLeftOver, err := Call(address, nil, &Config{State: newState(), EVMConfig: vm.Config{GasOverrides: overrides}})
+	if err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	// The memory expanded by MSTORE is still charged and written.
+	if num := new(big.Int).SetBytes(ret); num.Cmp(big.NewInt(10)) != 0 {
+		t.Error("Expected 10, got", num)
+	}
+	if overriddenLeftOver <= leftOver {
+		t.Errorf("expected overridden call to use less gas, left over %d <= %d", overriddenLeftOver, leftOver)
+	}
+
+	// Overridden precompiles require the overridden gas.
+	cfg := &Config{State: newState(), EVMConfig: vm.Config{GasOverrides: overrides}}
+	_, precompileLeftOver, err := Call(common.BytesToAddress([]byte{2}), []byte("input"), cfg)
+	if err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	if precompileLeftOver != cfg.GasLimit {
+		t.Errorf("expected sha256 to be free, left over %d != %d", precompileLeftOver, cfg.GasLimit)
+	}
+
+	// The gas of calls and of addresses which are not native precompiles
+	// cannot be overridden.
+	if err := (&vm.GasOverrides{Opcodes: map[vm.OpCode]uint64{vm.CALL: 0}}).Validate(); err == nil {
+		t.Error("expected error overriding CALL")
+	}
+	if err := (&vm.GasOverrides{Precompiles: map[common.Address]uint64{address: 0}}).Validate(); err == nil {
+		t.Error("expected error overriding a contract")
+	}
+}
+
 func BenchmarkCall(b *testing.B) {
 	var definition = `[{"constant":true,"inputs":[],"name":"seller","outputs":[{"name":"","type":"address"}],"type":"function"},{"constant":false,"inputs":[],"name":"abort","outputs":[],"type":"function"},{"constant":true,"inputs":[],"name":"value","outputs":[{"name":"","type":"uint256"}],"type":"function"},{"constant":false,"inputs":[],"name":"refund","outputs":[],"type":"function"},{"constant":true,"inputs":[],"name":"buyer","outputs":[{"name":"","type":"address"}],"type":"function"},{"constant":false,"inputs":[],"name":"confirmReceived","outputs":[],"type":"function"},{"constant":true,"inputs":[],"name":"state","outputs":[{"name":"","type":"uint8"}],"type":"function"},{"constant":false,"inputs":[],"name":"confirmPurchase","outputs":[],"type":"function"},{"inputs":[],"type":"constructor"},{"anonymous":false,"inputs":[],"name":"Aborted","type":"event"},{"anonymous":false,"inputs":[],"name":"PurchaseConfirmed","type":"event"},{"anonymous":false,"inputs":[],"name":"ItemReceived","type":"event"},{"anonymous":false,"inputs":[],"name":"Refunded","type":"event"}]`
 
@@ -922,3 +985,107 @@ func BenchmarkTracerStepVsCallFrame(b *testing.B) {
 	benchmarkNonModifyingCode(10000000, code, "tracer-step-10M", stepTracer, b)
 	benchmarkNonModifyingCode(10000000, code, "tracer-call-frame-10M", callFrameTracer, b)
 }
+
+func TestCreateCodeSizeLimits(t *testing.T) {
+	// The initcode returns 30000 zero bytes: PUSH2 30000 PUSH1 0 RETURN.
+	initCode := common.FromHex("0x617530" + "6000" + "f3")
+
+	config := *params.TestChainConfig
+	if _, _, _, err := Create(initCode, &Config{ChainConfig: &config}); !errors.Is(err, vmerrs.ErrMaxCodeSizeExceeded) {
+		t.Fatalf("expected %v with the default limit, got %v", vmerrs.ErrMaxCodeSizeExceeded, err)
+	}
+
+	config.CodeSizeLimits = []params.CodeSizeLimit{{BlockTimestamp: 10, MaxCodeSize: 2 * params.MaxCodeSize, MaxInitCodeSize: 2 * params.MaxInitCodeSize}}
+	if _, _, _, err := Create(initCode, &Config{ChainConfig: &config, Time: 9}); !errors.Is(err, vmerrs.ErrMaxCodeSizeExceeded) {
+		t.Fatalf("expected %v before the limit is raised, got %v", vmerrs.ErrMaxCodeSizeExceeded, err)
+	}
+	code, _, _, err := Create(initCode, &Config{ChainConfig: &config, Time: 10})
+	if err != nil {
+		t.Fatalf("failed to create contract with the raised limit: %v", err)
+	}
+	if len(code) != 30000 {
+		t.Fatalf("code size mismatch: have %d, want %d", len(code), 30000)
+	}
+}
+
+func TestOpcodeStats(t *testing.T) {
+	// PUSH1 1 PUSH1 2 ADD POP STOP
+	code := common.FromHex("0x6001600201" + "50" + "00")
+	stats := vm.NewOpcodeStats(2)
+	for i := 0; i < 4; i++ {
+		if _, _, err := Execute(code, nil, &Config{EVMConfig: vm.Config{OpcodeStats: stats}}); err != nil {
+			t.Fatalf("failed to execute code: %v", err)
+		}
+	}
+	if have := stats.SampledTxs(); have != 2 {
+		t.Fatalf("sampled transactions mismatch: have %d, want %d", have, 2)
+	}
+	want := map[string]vm.OpcodeStat{
+		"PUSH1": {Count: 4, Gas: 12},
+		"ADD":   {Count: 2, Gas: 6},
+		"POP":   {Count: 2, Gas: 4},
+		"STOP":  {Count: 2, Gas: 0},
+	}
+	have := stats.Dump()
+	if len(have) != len(want) {
+		t.Fatalf("opcode stats mismatch: have %v, want %v", have, want)
+	}
+	for op, stat := range want {
+		if have[op] != stat {
+			t.Errorf("%s stats mismatch: have %+v, want %+v", op, have[op], stat)
+		}
+	}
+}
+
+func TestStepRecorder(t *testing.T) {
+	// PUSH1 1 PUSH1 2 ADD POP STOP
+	code := common.FromHex("0x6001600201" + "50" + "00")
+	recorder := vm.NewStepRecorder()
+	for i := 0; i < 2; i++ {
+		if _, _, err := Execute(code, nil, &Config{EVMConfig: vm.Config{Tracer: recorder}}); err != nil {
+			t.Fatalf("failed to execute code: %v", err)
+		}
+	}
+	digests := recorder.Digests()
+	if len(digests) != 2 {
+		t.Fatalf("digests mismatch: have %d, want %d", len(digests), 2)
+	}
+	if digests[0] != digests[1] {
+		t.Fatalf("digests of identical executions differ: %+v, %+v", digests[0], digests[1])
+	}
+	if digests[0].Steps != 5 || digests[0].GasUsed != 11 || digests[0].Failed {
+		t.Fatalf("digest mismatch: have %+v", digests[0])
+	}
+
+	// Executions differing by a single step have different digests.
+	recorder.Reset()
+	// PUSH1 1 PUSH1 3 ADD POP STOP
+	if _, _, err := Execute(common.FromHex("0x6001600301"+"50"+"00"), nil, &Config{EVMConfig: vm.Config{Tracer: recorder}}); err != nil {
+		t.Fatalf("failed to execute code: %v", err)
+	}
+	if have := recorder.Digests()[0]; have.Digest != digests[0].Digest {
+		t.Fatalf("digest depends on stack values: have %x, want %x", have.Digest, digests[0].Digest)
+	}
+	recorder.Reset()
+	// PUSH1 1 DUP1 ADD POP STOP
+	if _, _, err := Execute(common.FromHex("0x600180"+"01"+"50"+"00"), nil, &Config{EVMConfig: vm.Config{Tracer: recorder}}); err != nil {
+		t.Fatalf("failed to execute code: %v", err)
+	}
+	if have := recorder.Digests()[0]; have.Digest == digests[0].Digest {
+		t.Fatalf("digests of different executions are equal: %x", have.Digest)
+	}
+}
+
+func FuzzStepRecorder(f *testing.F) {
+	f.Add(common.FromHex("0x6001600201" + "50" + "00"))
+	f.Add(common.FromHex("0x60006000fd"))
+	f.Fuzz(func(t *testing.T, code []byte) {
+		recorder := vm.NewStepRecorder()
+		for i := 0; i < 2; i++ {
+			Execute(code, nil, &Config{GasLimit: 100_000, EVMConfig: vm.Config{Tracer: recorder}})
+		}
+		if digests := recorder.Digests(); len(digests) != 2 || digests[0] != digests[1] {
+			t.Fatalf("digests of identical executions differ: %+v", digests)
+		}
+	})
+}
@@ -0,0 +1,105 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// stepRecordLen is the length of the encoding of a step hashed into a
+// [StepDigest]: pc, gas and stack depth as 8-byte big-endian integers, the
+// opcode as a single byte, and the call depth as a 2-byte big-endian integer.
+const stepRecordLen = 8 + 1 + 8 + 2 + 8
+
+// StepDigest summarizes the opcode-level execution of a transaction, so that
+// it can be compared against the execution of the same transaction by another
+// EVM implementation.
+type StepDigest struct {
+	// Steps is the number of executed opcodes, including the opcode which
+	// failed if execution failed.
+	Steps uint64 `json:"steps"`
+	// GasUsed is the gas used by the execution, excluding the intrinsic gas
+	// and refunds.
+	GasUsed uint64 `json:"gasUsed"`
+	// Failed is set if the execution reverted or failed.
+	Failed bool `json:"failed"`
+	// Digest is the keccak256 hash of the encoded steps, in execution order.
+	Digest common.Hash `json:"digest"`
+}
+
+// StepRecorder is an [EVMLogger] recording a [StepDigest] of each transaction
+// it traces. Each step is encoded as its pc, opcode, remaining gas before the
+// opcode, call depth and stack depth, which only depend on the executed
+// code and are defined identically by every EVM implementation, so that
+// differential fuzzers can compare digests instead of full traces.
+//
+// StepRecorder is not safe for concurrent use.
+type StepRecorder struct {
+	hasher  crypto.KeccakState
+	buf     [stepRecordLen]byte
+	steps   uint64
+	digests []StepDigest
+}
+
+// NewStepRecorder returns a recorder without digests.
+func NewStepRecorder() *StepRecorder {
+	return &StepRecorder{hasher: crypto.NewKeccakState()}
+}
+
+// Digests returns the digests of the transactions traced so far, in execution
+// order.
+func (r *StepRecorder) Digests() []StepDigest {
+	return r.digests
+}
+
+// Reset discards the recorded digests.
+func (r *StepRecorder) Reset() {
+	r.digests = nil
+}
+
+func (r *StepRecorder) CaptureTxStart(gasLimit uint64) {}
+
+func (r *StepRecorder) CaptureTxEnd(restGas uint64) {}
+
+// CaptureStart starts the digest of a transaction.
+func (r *StepRecorder) CaptureStart(env *EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	r.hasher.Reset()
+	r.steps = 0
+}
+
+// CaptureEnd records the digest of the transaction.
+func (r *StepRecorder) CaptureEnd(output []byte, gasUsed uint64, err error) {
+	digest := StepDigest{
+		Steps:   r.steps,
+		GasUsed: gasUsed,
+		Failed:  err != nil,
+	}
+	r.hasher.Read(digest.Digest[:])
+	r.digests = append(r.digests, digest)
+}
+
+func (r *StepRecorder) CaptureEnter(typ OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+}
+
+func (r *StepRecorder) CaptureExit(output []byte, gasUsed uint64, err error) {}
+
+// CaptureState adds a step to the digest of the transaction.
+func (r *StepRecorder) CaptureState(pc uint64, op OpCode, gas, cost uint64, scope *ScopeContext, rData []byte, depth int, err error) {
+	binary.BigEndian.PutUint64(r.buf[0:8], pc)
+	r.buf[8] = byte(op)
+	binary.BigEndian.PutUint64(r.buf[9:17], gas)
+	binary.BigEndian.PutUint16(r.buf[17:19], uint16(depth))
+	binary.BigEndian.PutUint64(r.buf[19:27], uint64(scope.Stack.len()))
+	r.hasher.Write(r.buf[:])
+	r.steps++
+}
+
+// CaptureFault is called for steps already passed to [CaptureState], which
+// are not added again.
+func (r *StepRecorder) CaptureFault(pc uint64, op OpCode, gas, cost uint64, scope *ScopeContext, depth int, err error) {
+}
@@ -0,0 +1,182 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shubhamdubey02/coreth/params"
+)
+
+// UpgradeCapabilities describes what a network upgrade changes in the EVM.
+//
+// To integrate a new upgrade, append its entry to [UpgradeMatrix] along with
+// its instruction set and precompiled contracts. The activation tests derived
+// from the matrix fail until the entry matches the EVM.
+type UpgradeCapabilities struct {
+	Name string
+	// Active returns whether the upgrade is activated by [rules].
+	Active func(rules params.Rules) bool
+	// EIPs are the EIPs adopted by the upgrade.
+	EIPs []int
+	// Opcodes are defined from the upgrade on, and RemovedOpcodes are
+	// undefined from the upgrade on.
+	Opcodes        []OpCode
+	RemovedOpcodes []OpCode
+	// Precompiles are the native precompiled contracts enabled by the
+	// upgrade, DeprecatedPrecompiles those which revert from the upgrade on,
+	// and RemovedPrecompiles those which are no longer contracts.
+	Precompiles           []common.Address
+	DeprecatedPrecompiles []common.Address
+	RemovedPrecompiles    []common.Address
+}
+
+// UpgradeMatrix lists the network upgrades of the C-Chain in activation
+// order. Upgrades changing neither the opcodes nor the precompiled contracts
+// are listed so that the matrix is complete.
+var UpgradeMatrix = []UpgradeCapabilities{
+	{
+		Name:   "Frontier",
+		Active: func(params.Rules) bool { return true },
+		Precompiles: []common.Address{
+			common.BytesToAddress([]byte{1}),
+			common.BytesToAddress([]byte{2}),
+			common.BytesToAddress([]byte{3}),
+			common.BytesToAddress([]byte{4}),
+		},
+	},
+	{
+		Name:    "Homestead",
+		Active:  func(r params.Rules) bool { return r.IsHomestead },
+		EIPs:    []int{2, 7, 8},
+		Opcodes: []OpCode{DELEGATECALL},
+	},
+	{
+		Name:   "TangerineWhistle",
+		Active: func(r params.Rules) bool { return r.IsEIP150 },
+		EIPs:   []int{150},
+	},
+	{
+		Name:   "SpuriousDragon",
+		Active: func(r params.Rules) bool { return r.IsEIP158 },
+		EIPs:   []int{155, 160, 161, 170},
+	},
+	{
+		Name:    "Byzantium",
+		Active:  func(r params.Rules) bool { return r.IsByzantium },
+		EIPs:    []int{140, 196, 197, 198, 211, 214, 658},
+		Opcodes: []OpCode{REVERT, RETURNDATASIZE, RETURNDATACOPY, STATICCALL},
+		Precompiles: []common.Address{
+			common.BytesToAddress([]byte{5}),
+			common.BytesToAddress([]byte{6}),
+			common.BytesToAddress([]byte{7}),
+			common.BytesToAddress([]byte{8}),
+		},
+	},
+	{
+		Name:    "Constantinople",
+		Active:  func(r params.Rules) bool { return r.IsConstantinople },
+		EIPs:    []int{145, 1014, 1052},
+		Opcodes: []OpCode{SHL, SHR, SAR, CREATE2, EXTCODEHASH},
+	},
+	{
+		Name:        "Istanbul",
+		Active:      func(r params.Rules) bool { return r.IsIstanbul },
+		EIPs:        []int{152, 1108, 1344, 1884, 2028, 2200},
+		Opcodes:     []OpCode{CHAINID, SELFBALANCE},
+		Precompiles: []common.Address{common.BytesToAddress([]byte{9})},
+	},
+	{
+		// Apricot Phase 1 removes gas refunds, similarly to EIP-3298.
+		Name:   "ApricotPhase1",
+		Active: func(r params.Rules) bool { return r.IsApricotPhase1 },
+	},
+	{
+		Name:                  "ApricotPhase2",
+		Active:                func(r params.Rules) bool { return r.IsApricotPhase2 },
+		EIPs:                  []int{2565, 2718, 2929, 2930},
+		RemovedOpcodes:        []OpCode{BALANCEMC, CALLEX},
+		Precompiles:           []common.Address{NativeAssetBalanceAddr, NativeAssetCallAddr},
+		DeprecatedPrecompiles: []common.Address{genesisContractAddr},
+	},
+	{
+		Name:    "ApricotPhase3",
+		Active:  func(r params.Rules) bool { return r.IsApricotPhase3 },
+		EIPs:    []int{1559, 3198, 3529, 3541},
+		Opcodes: []OpCode{BASEFEE},
+	},
+	{
+		Name:   "ApricotPhase4",
+		Active: func(r params.Rules) bool { return r.IsApricotPhase4 },
+	},
+	{
+		Name:   "ApricotPhase5",
+		Active: func(r params.Rules) bool { return r.IsApricotPhase5 },
+	},
+	{
+		Name:                  "ApricotPhasePre6",
+		Active:                func(r params.Rules) bool { return r.IsApricotPhasePre6 },
+		DeprecatedPrecompiles: []common.Address{NativeAssetBalanceAddr, NativeAssetCallAddr},
+	},
+	{
+		Name:        "ApricotPhase6",
+		Active:      func(r params.Rules) bool { return r.IsApricotPhase6 },
+		Precompiles: []common.Address{NativeAssetBalanceAddr, NativeAssetCallAddr},
+	},
+	{
+		Name:   "ApricotPhasePost6",
+		Active: func(r params.Rules) bool { return r.IsApricotPhasePost6 },
+	},
+	{
+		Name:                  "Banff",
+		Active:                func(r params.Rules) bool { return r.IsBanff },
+		DeprecatedPrecompiles: []common.Address{NativeAssetBalanceAddr, NativeAssetCallAddr},
+	},
+	{
+		Name:   "Cortina",
+		Active: func(r params.Rules) bool { return r.IsCortina },
+	},
+	{
+		Name:    "Durango",
+		Active:  func(r params.Rules) bool { return r.IsDurango },
+		EIPs:    []int{3651, 3855, 3860},
+		Opcodes: []OpCode{PUSH0},
+	},
+	{
+		// The precompiled contracts of Cancun do not include the deprecated
+		// native asset contracts.
+		Name:               "Cancun",
+		Active:             func(r params.Rules) bool { return r.IsCancun },
+		EIPs:               []int{1153, 4788, 4844, 5656, 6780, 7516},
+		Opcodes:            []OpCode{TLOAD, TSTORE, BLOBHASH, MCOPY, BLOBBASEFEE},
+		Precompiles:        []common.Address{common.BytesToAddress([]byte{0x0a})},
+		RemovedPrecompiles: []common.Address{genesisContractAddr, NativeAssetBalanceAddr, NativeAssetCallAddr},
+	},
+}
+
+// ActiveUpgrades returns the names of the upgrades activated by [rules], in
+// activation order.
+func ActiveUpgrades(rules params.Rules) []string {
+	var names []string
+	for _, upgrade := range UpgradeMatrix {
+		if upgrade.Active(rules) {
+			names = append(names, upgrade.Name)
+		}
+	}
+	return names
+}
+
+// ActiveEIPs returns the EIPs adopted by the upgrades activated by [rules], in
+// ascending order.
+func ActiveEIPs(rules params.Rules) []int {
+	var eips []int
+	for _, upgrade := range UpgradeMatrix {
+		if upgrade.Active(rules) {
+			eips = append(eips, upgrade.EIPs...)
+		}
+	}
+	sort.Ints(eips)
+	return eips
+}
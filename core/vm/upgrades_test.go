@@ -0,0 +1,169 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shubhamdubey02/coreth/params"
+	"github.com/shubhamdubey02/coreth/precompile/contract"
+	"github.com/stretchr/testify/require"
+)
+
+// upgradeActivators set the rules activating each upgrade of [UpgradeMatrix].
+var upgradeActivators = map[string]func(*params.Rules){
+	"Frontier":          func(*params.Rules) {},
+	"Homestead":         func(r *params.Rules) { r.IsHomestead = true },
+	"TangerineWhistle":  func(r *params.Rules) { r.IsEIP150 = true },
+	"SpuriousDragon":    func(r *params.Rules) { r.IsEIP155, r.IsEIP158 = true, true },
+	"Byzantium":         func(r *params.Rules) { r.IsByzantium = true },
+	"Constantinople":    func(r *params.Rules) { r.IsConstantinople, r.IsPetersburg = true, true },
+	"Istanbul":          func(r *params.Rules) { r.IsIstanbul = true },
+	"ApricotPhase1":     func(r *params.Rules) { r.IsApricotPhase1 = true },
+	"ApricotPhase2":     func(r *params.Rules) { r.IsApricotPhase2 = true },
+	"ApricotPhase3":     func(r *params.Rules) { r.IsApricotPhase3 = true },
+	"ApricotPhase4":     func(r *params.Rules) { r.IsApricotPhase4 = true },
+	"ApricotPhase5":     func(r *params.Rules) { r.IsApricotPhase5 = true },
+	"ApricotPhasePre6":  func(r *params.Rules) { r.IsApricotPhasePre6 = true },
+	"ApricotPhase6":     func(r *params.Rules) { r.IsApricotPhase6 = true },
+	"ApricotPhasePost6": func(r *params.Rules) { r.IsApricotPhasePost6 = true },
+	"Banff":             func(r *params.Rules) { r.IsBanff = true },
+	"Cortina":           func(r *params.Rules) { r.IsCortina = true },
+	"Durango":           func(r *params.Rules) { r.IsDurango = true },
+	"Cancun":            func(r *params.Rules) { r.IsCancun = true },
+}
+
+// rulesAt returns the rules activating the first [n] upgrades of
+// [UpgradeMatrix].
+func rulesAt(t *testing.T, n int) params.Rules {
+	rules := params.Rules{ChainID: common.Big1}
+	for _, upgrade := range UpgradeMatrix[:n] {
+		activate, ok := upgradeActivators[upgrade.Name]
+		require.True(t, ok, "no activator for upgrade %s", upgrade.Name)
+		activate(&rules)
+	}
+	return rules
+}
+
+func opcodeDefined(rules params.Rules, op OpCode) bool {
+	interpreter := NewEVMInterpreter(&EVM{chainRules: rules})
+	return interpreter.table[op].HasCost()
+}
+
+type precompileState int
+
+const (
+	precompileAbsent precompileState = iota
+	precompileDeprecated
+	precompileEnabled
+)
+
+func precompileStateOf(rules params.Rules, addr common.Address) precompileState {
+	p, ok := (&EVM{chainRules: rules}).precompile(addr)
+	if !ok {
+		return precompileAbsent
+	}
+	if _, ok := p.(*deprecatedContract); ok {
+		return precompileDeprecated
+	}
+	return precompileEnabled
+}
+
+// TestUpgradeMatrix checks that each upgrade of [UpgradeMatrix] changes the
+// opcodes and precompiled contracts as the matrix describes.
+func TestUpgradeMatrix(t *testing.T) {
+	for i, upgrade := range UpgradeMatrix {
+		i, upgrade := i, upgrade
+		t.Run(upgrade.Name, func(t *testing.T) {
+			before, after := rulesAt(t, i), rulesAt(t, i+1)
+			require.True(t, upgrade.Active(after))
+			if i > 0 {
+				require.False(t, upgrade.Active(before))
+			}
+			require.Equal(t, upgrade.Name, ActiveUpgrades(after)[i])
+
+			for _, op := range upgrade.Opcodes {
+				if i > 0 {
+					require.False(t, opcodeDefined(before, op), "%s defined before %s", op, upgrade.Name)
+				}
+				require.True(t, opcodeDefined(after, op), "%s undefined by %s", op, upgrade.Name)
+			}
+			for _, op := range upgrade.RemovedOpcodes {
+				require.True(t, opcodeDefined(before, op), "%s undefined before %s", op, upgrade.Name)
+				require.False(t, opcodeDefined(after, op), "%s defined after %s", op, upgrade.Name)
+			}
+
+			for _, addr := range upgrade.Precompiles {
+				if i > 0 {
+					require.NotEqual(t, precompileEnabled, precompileStateOf(before, addr), "%s enabled before %s", addr, upgrade.Name)
+				}
+				require.Equal(t, precompileEnabled, precompileStateOf(after, addr), "%s not enabled by %s", addr, upgrade.Name)
+			}
+			for _, addr := range upgrade.DeprecatedPrecompiles {
+				require.NotEqual(t, precompileDeprecated, precompileStateOf(before, addr), "%s deprecated before %s", addr, upgrade.Name)
+				require.Equal(t, precompileDeprecated, precompileStateOf(after, addr), "%s not deprecated by %s", addr, upgrade.Name)
+			}
+			for _, addr := range upgrade.RemovedPrecompiles {
+				require.NotEqual(t, precompileAbsent, precompileStateOf(before, addr), "%s absent before %s", addr, upgrade.Name)
+				require.Equal(t, precompileAbsent, precompileStateOf(after, addr), "%s not removed by %s", addr, upgrade.Name)
+			}
+		})
+	}
+}
+
+// TestUpgradeMatrixComplete checks that the changes to the opcodes and
+// precompiled contracts between consecutive upgrades are all listed by
+// [UpgradeMatrix].
+func TestUpgradeMatrixComplete(t *testing.T) {
+	var addrs []common.Address
+	for _, precompiles := range []map[common.Address]contract.StatefulPrecompiledContract{
+		PrecompiledContractsHomestead,
+		PrecompiledContractsApricotPhase2,
+		PrecompiledContractsCancun,
+	} {
+		for addr := range precompiles {
+			addrs = append(addrs, addr)
+		}
+	}
+
+	for i := 1; i < len(UpgradeMatrix); i++ {
+		upgrade := UpgradeMatrix[i]
+		before, after := rulesAt(t, i), rulesAt(t, i+1)
+		for op := 0; op < 256; op++ {
+			defined, wasDefined := opcodeDefined(after, OpCode(op)), opcodeDefined(before, OpCode(op))
+			switch {
+			case defined && !wasDefined:
+				require.Contains(t, upgrade.Opcodes, OpCode(op), "%s defined by %s", OpCode(op), upgrade.Name)
+			case !defined && wasDefined:
+				require.Contains(t, upgrade.RemovedOpcodes, OpCode(op), "%s undefined by %s", OpCode(op), upgrade.Name)
+			}
+		}
+		for _, addr := range addrs {
+			state, previous := precompileStateOf(after, addr), precompileStateOf(before, addr)
+			if state == previous {
+				continue
+			}
+			switch state {
+			case precompileEnabled:
+				require.Contains(t, upgrade.Precompiles, addr, "%s enabled by %s", addr, upgrade.Name)
+			case precompileDeprecated:
+				require.Contains(t, upgrade.DeprecatedPrecompiles, addr, "%s deprecated by %s", addr, upgrade.Name)
+			case precompileAbsent:
+				require.Contains(t, upgrade.RemovedPrecompiles, addr, "%s removed by %s", addr, upgrade.Name)
+			}
+		}
+	}
+}
+
+// TestUpgradeMatrixEIPs checks that the EIPs which can be enabled on their
+// own are listed by the upgrade adopting them.
+func TestUpgradeMatrixEIPs(t *testing.T) {
+	latest := rulesAt(t, len(UpgradeMatrix))
+	eips := ActiveEIPs(latest)
+	for eip := range activators {
+		require.Contains(t, eips, eip)
+	}
+	require.IsIncreasing(t, eips)
+}
@@ -27,9 +27,19 @@
 package eth
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/shubhamdubey02/coreth/core/types"
 )
 
+// errBlobSidecarsUnavailable is returned for blocks whose blob sidecars were
+// deleted by the retention policy or never received.
+var errBlobSidecarsUnavailable = errors.New("blob sidecars unavailable")
+
 // EthereumAPI provides an API to access Ethereum full node-related information.
 type EthereumAPI struct {
 	e *Ethereum
@@ -49,3 +59,53 @@ func (api *EthereumAPI) Etherbase() (common.Address, error) {
 func (api *EthereumAPI) Coinbase() (common.Address, error) {
 	return api.Etherbase()
 }
+
+// BlobSidecar is the sidecar of a blob transaction, holding the blobs it
+// commits to.
+type BlobSidecar struct {
+	TxHash      common.Hash          `json:"transactionHash"`
+	TxIndex     hexutil.Uint64       `json:"transactionIndex"`
+	BlobHashes  []common.Hash        `json:"blobVersionedHashes"`
+	Blobs       []kzg4844.Blob       `json:"blobs"`
+	Commitments []kzg4844.Commitment `json:"commitments"`
+	Proofs      []kzg4844.Proof      `json:"proofs"`
+}
+
+// GetBlobSidecars returns the sidecars of the blob transactions of the block
+// with the given hash, in transaction order. Sidecars are kept for the blob
+// sidecars retention of the node.
+func (api *EthereumAPI) GetBlobSidecars(blockHash common.Hash) ([]*BlobSidecar, error) {
+	block := api.e.blockchain.GetBlockByHash(blockHash)
+	if block == nil {
+		return nil, fmt.Errorf("block %s not found", blockHash)
+	}
+	var blobTxs []*types.Transaction
+	for _, tx := range block.Transactions() {
+		if tx.Type() == types.BlobTxType {
+			blobTxs = append(blobTxs, tx)
+		}
+	}
+	if len(blobTxs) == 0 {
+		return []*BlobSidecar{}, nil
+	}
+	sidecars := api.e.blockchain.GetBlobSidecars(blockHash)
+	if len(sidecars) != len(blobTxs) {
+		return nil, fmt.Errorf("%w: block %s", errBlobSidecarsUnavailable, blockHash)
+	}
+	result := make([]*BlobSidecar, 0, len(blobTxs))
+	for i, tx := range block.Transactions() {
+		if tx.Type() != types.BlobTxType {
+			continue
+		}
+		sidecar := sidecars[len(result)]
+		result = append(result, &BlobSidecar{
+			TxHash:      tx.Hash(),
+			TxIndex:     hexutil.Uint64(i),
+			BlobHashes:  tx.BlobHashes(),
+			Blobs:       sidecar.Blobs,
+			Commitments: sidecar.Commitments,
+			Proofs:      sidecar.Proofs,
+		})
+	}
+	return result, nil
+}
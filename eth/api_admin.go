@@ -37,6 +37,7 @@ import (
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/shubhamdubey02/coreth/core"
 	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/internal/ethapi"
 )
 
 // AdminAPI is the collection of Ethereum full node related APIs for node
@@ -151,3 +152,15 @@ func (api *AdminAPI) ImportChain(file string) (bool, error) {
 	}
 	return true, nil
 }
+
+// ExportTxPool returns the content of the transaction pool, so that a standby
+// node can take over the pending set with ImportTxPool.
+func (api *AdminAPI) ExportTxPool() (*ethapi.PoolSnapshot, error) {
+	return ethapi.ExportPoolSnapshot(api.eth.APIBackend)
+}
+
+// ImportTxPool adds the transactions of a snapshot produced by ExportTxPool to
+// the transaction pool, preserving their arrival time and locality.
+func (api *AdminAPI) ImportTxPool(snapshot ethapi.PoolSnapshot) (*ethapi.PoolSnapshotImport, error) {
+	return ethapi.ImportPoolSnapshot(api.eth.APIBackend, snapshot)
+}
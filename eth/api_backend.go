@@ -36,12 +36,14 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/shubhamdubey02/coreth/accounts"
+	"github.com/shubhamdubey02/coreth/accounts/abi"
 	"github.com/shubhamdubey02/coreth/consensus"
 	"github.com/shubhamdubey02/coreth/consensus/dummy"
 	"github.com/shubhamdubey02/coreth/core"
 	"github.com/shubhamdubey02/coreth/core/bloombits"
 	"github.com/shubhamdubey02/coreth/core/rawdb"
 	"github.com/shubhamdubey02/coreth/core/state"
+	"github.com/shubhamdubey02/coreth/core/txpool"
 	"github.com/shubhamdubey02/coreth/core/types"
 	"github.com/shubhamdubey02/coreth/core/vm"
 	"github.com/shubhamdubey02/coreth/eth/gasprice"
@@ -229,44 +231,60 @@ func (b *EthAPIBackend) BadBlocks() ([]*types.Block, []*core.BadBlockReason) {
 	return b.eth.blockchain.BadBlocks()
 }
 
-func (b *EthAPIBackend) StateAndHeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
+func (b *EthAPIBackend) StateAndHeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*state.StateDB, *types.Header, func(), error) {
 	// Request the block by its number and retrieve its state
 	header, err := b.HeaderByNumber(ctx, number)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	if header == nil {
-		return nil, nil, errors.New("header not found")
+		return nil, nil, nil, errors.New("header not found")
 	}
-	stateDb, err := b.eth.BlockChain().StateAt(header.Root)
+	stateDb, release, err := b.stateAt(ctx, header)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
-	return stateDb, header, nil
+	return stateDb, header, release, nil
 }
 
-func (b *EthAPIBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error) {
+func (b *EthAPIBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, func(), error) {
 	if blockNr, ok := blockNrOrHash.Number(); ok {
 		return b.StateAndHeaderByNumber(ctx, blockNr)
 	}
 	if err := ctx.Err(); err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	if hash, ok := blockNrOrHash.Hash(); ok {
 		header, err := b.HeaderByHash(ctx, hash)
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
 		if header == nil {
-			return nil, nil, errors.New("header for hash not found")
+			return nil, nil, nil, errors.New("header for hash not found")
 		}
-		stateDb, err := b.eth.BlockChain().StateAt(header.Root)
+		stateDb, release, err := b.stateAt(ctx, header)
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
-		return stateDb, header, nil
+		return stateDb, header, release, nil
+	}
+	return nil, nil, nil, errors.New("invalid arguments; neither block nor hash specified")
+}
+
+// stateAt returns the state of the block with [header], regenerating it if it
+// was pruned and historical state regeneration is enabled. The returned
+// release function must be called once the state is no longer used, so that
+// a regenerated state is not evicted while in use.
+func (b *EthAPIBackend) stateAt(ctx context.Context, header *types.Header) (*state.StateDB, func(), error) {
+	stateDb, err := b.eth.BlockChain().StateAt(header.Root)
+	if err == nil || b.eth.historicalStates == nil {
+		return stateDb, noopReleaser, err
+	}
+	block := b.eth.blockchain.GetBlock(header.Hash(), header.Number.Uint64())
+	if block == nil {
+		return nil, nil, err
 	}
-	return nil, nil, errors.New("invalid arguments; neither block nor hash specified")
+	return b.eth.historicalStates.stateAt(ctx, block)
 }
 
 func (b *EthAPIBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
@@ -399,6 +417,14 @@ func (b *EthAPIBackend) TxPoolContentFrom(addr common.Address) ([]*types.Transac
 	return b.eth.txPool.ContentFrom(addr)
 }
 
+func (b *EthAPIBackend) TxPoolSnapshot() []*txpool.SnapshotTx {
+	return b.eth.txPool.Snapshot()
+}
+
+func (b *EthAPIBackend) RestoreTxPoolSnapshot(snapshot []*txpool.SnapshotTx) []error {
+	return b.eth.txPool.Restore(snapshot)
+}
+
 func (b *EthAPIBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscription {
 	return b.eth.txPool.SubscribeTransactions(ch, true)
 }
@@ -415,6 +441,10 @@ func (b *EthAPIBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error)
 	return b.gpo.SuggestTipCap(ctx)
 }
 
+func (b *EthAPIBackend) FeeEstimate(ctx context.Context) (*big.Int, []gasprice.FeeEstimate, error) {
+	return b.gpo.FeeEstimate(ctx)
+}
+
 func (b *EthAPIBackend) FeeHistory(ctx context.Context, blockCount uint64, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (firstBlock *big.Int, reward [][]*big.Int, baseFee []*big.Int, gasUsedRatio []float64, err error) {
 	return b.gpo.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
 }
@@ -467,6 +497,18 @@ func (b *EthAPIBackend) RPCTxFeeCap() float64 {
 	return b.eth.config.RPCTxFeeCap
 }
 
+func (b *EthAPIBackend) RPCEstimateGasTrace() bool {
+	return b.eth.config.RPCEstimateGasTrace
+}
+
+func (b *EthAPIBackend) RPCErrorABI() *abi.ABI {
+	return b.eth.config.RPCErrorABI
+}
+
+func (b *EthAPIBackend) RPCAcceptanceMetadata() bool {
+	return b.eth.config.RPCAcceptanceMetadata
+}
+
 func (b *EthAPIBackend) BloomStatus() (uint64, uint64) {
 	sections, _, _ := b.eth.bloomIndexer.Sections()
 	return params.BloomBitsBlocks, sections
@@ -39,6 +39,7 @@ import (
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/shubhamdubey02/coreth/core/rawdb"
 	"github.com/shubhamdubey02/coreth/core/state"
+	"github.com/shubhamdubey02/coreth/core/state/snapshot"
 	"github.com/shubhamdubey02/coreth/core/types"
 	"github.com/shubhamdubey02/coreth/internal/ethapi"
 	"github.com/shubhamdubey02/coreth/rpc"
@@ -104,7 +105,9 @@ func (api *DebugAPI) GetBadBlocks(ctx context.Context) ([]*ethapi.BadBlockArgs,
 // AccountRangeMaxResults is the maximum number of results to be returned per call
 const AccountRangeMaxResults = 256
 
-// AccountRange enumerates all accounts in the given block and start point in paging request
+// AccountRange enumerates all accounts in the given block and start point in paging request.
+// Accounts are read from the flat snapshot if it is available for the block's
+// state root, falling back to iterating the state trie otherwise.
 func (api *DebugAPI) AccountRange(blockNrOrHash rpc.BlockNumberOrHash, start hexutil.Bytes, maxResults int, nocode, nostorage, incompletes bool) (state.Dump, error) {
 	var stateDb *state.StateDB
 	var err error
@@ -154,6 +157,13 @@ func (api *DebugAPI) AccountRange(blockNrOrHash rpc.BlockNumberOrHash, start hex
 	if maxResults > AccountRangeMaxResults || maxResults <= 0 {
 		opts.Max = AccountRangeMaxResults
 	}
+	if snaps := api.eth.blockchain.Snapshots(); snaps != nil {
+		dump, err := stateDb.RawDumpSnapshot(snaps, opts)
+		if err == nil {
+			return dump, nil
+		}
+		log.Debug("Snapshot unavailable for account range, iterating trie", "err", err)
+	}
 	return stateDb.RawDump(opts), nil
 }
 
@@ -171,6 +181,9 @@ type storageEntry struct {
 }
 
 // StorageRangeAt returns the storage at the given block height and transaction index.
+// Storage is read from the flat snapshot if the contract's storage at that
+// point matches the snapshot of the block or its parent, falling back to
+// iterating the storage trie otherwise.
 func (api *DebugAPI) StorageRangeAt(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, txIndex int, contractAddress common.Address, keyStart hexutil.Bytes, maxResult int) (StorageRangeResult, error) {
 	var block *types.Block
 
@@ -187,9 +200,82 @@ func (api *DebugAPI) StorageRangeAt(ctx context.Context, blockNrOrHash rpc.Block
 	}
 	defer release()
 
+	if snaps := api.eth.blockchain.Snapshots(); snaps != nil {
+		roots := []common.Hash{block.Root()}
+		if parent := api.eth.blockchain.GetHeaderByHash(block.ParentHash()); parent != nil {
+			roots = append(roots, parent.Root)
+		}
+		result, err := snapshotStorageRangeAt(snaps, roots, statedb, contractAddress, keyStart, maxResult)
+		if err == nil {
+			return result, nil
+		}
+		log.Debug("Snapshot unavailable for storage range, iterating trie", "err", err)
+	}
 	return storageRangeAt(statedb, block.Root(), contractAddress, keyStart, maxResult)
 }
 
+// snapshotStorageRangeAt is like storageRangeAt, but iterates the flat snapshot
+// of the first state root in [roots] where the storage root of [address]
+// matches its storage root in [statedb]. Storage modified by the transactions
+// preceding the requested one is not reflected by the storage root, so the
+// snapshot is only used if [address] has no dirty storage in [statedb].
+func snapshotStorageRangeAt(snaps *snapshot.Tree, roots []common.Hash, statedb *state.StateDB, address common.Address, start []byte, maxResult int) (StorageRangeResult, error) {
+	if statedb.HasDirtyStorage(address) {
+		return StorageRangeResult{}, fmt.Errorf("storage of %s modified by preceding transactions", address)
+	}
+	storageRoot := statedb.GetStorageRoot(address)
+	if storageRoot == types.EmptyRootHash || storageRoot == (common.Hash{}) {
+		return StorageRangeResult{}, nil // empty storage
+	}
+	accountHash := crypto.Keccak256Hash(address.Bytes())
+	for _, root := range roots {
+		snap := snaps.Snapshot(root)
+		if snap == nil {
+			continue
+		}
+		data, err := snap.AccountRLP(accountHash)
+		if err != nil || len(data) == 0 {
+			continue
+		}
+		account, err := types.FullAccount(data)
+		if err != nil || account.Root != storageRoot {
+			continue
+		}
+
+		seek := common.BytesToHash(common.RightPadBytes(start, common.HashLength))
+		it, err := snaps.StorageIterator(root, accountHash, seek, false)
+		if err != nil {
+			return StorageRangeResult{}, err
+		}
+		defer it.Release()
+
+		preimages := statedb.Database().TrieDB()
+		result := StorageRangeResult{Storage: storageMap{}}
+		for i := 0; i < maxResult && it.Next(); i++ {
+			_, content, _, err := rlp.Split(it.Slot())
+			if err != nil {
+				return StorageRangeResult{}, err
+			}
+			e := storageEntry{Value: common.BytesToHash(content)}
+			if preimage := preimages.Preimage(it.Hash()); preimage != nil {
+				preimage := common.BytesToHash(preimage)
+				e.Key = &preimage
+			}
+			result.Storage[it.Hash()] = e
+		}
+		// Add the 'next key' so clients can continue downloading.
+		if it.Next() {
+			next := it.Hash()
+			result.NextKey = &next
+		}
+		if err := it.Error(); err != nil {
+			return StorageRangeResult{}, err
+		}
+		return result, nil
+	}
+	return StorageRangeResult{}, fmt.Errorf("no snapshot with storage root %s for %s", storageRoot, address)
+}
+
 func storageRangeAt(statedb *state.StateDB, root common.Hash, address common.Address, start []byte, maxResult int) (StorageRangeResult, error) {
 	storageRoot := statedb.GetStorageRoot(address)
 	if storageRoot == types.EmptyRootHash || storageRoot == (common.Hash{}) {
@@ -327,6 +413,10 @@ func (api *DebugAPI) GetAccessibleState(from, to rpc.BlockNumber) (uint64, error
 		return 0, errors.New("state history is not yet available in path-based scheme")
 	}
 	var resolveNum = func(num rpc.BlockNumber) (uint64, error) {
+		// safe and finalized refer to the last accepted block
+		if num.IsFinalized() {
+			return api.eth.LastAcceptedBlock().NumberU64(), nil
+		}
 		// We don't have state for pending (-2), so treat it as latest
 		if num.Int64() < 0 {
 			block := api.eth.blockchain.CurrentBlock()
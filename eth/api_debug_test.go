@@ -36,6 +36,7 @@ import (
 
 	"github.com/shubhamdubey02/coreth/core/rawdb"
 	"github.com/shubhamdubey02/coreth/core/state"
+	"github.com/shubhamdubey02/coreth/core/state/snapshot"
 	"github.com/shubhamdubey02/coreth/core/types"
 	"github.com/shubhamdubey02/coreth/trie"
 
@@ -238,3 +239,95 @@ func TestStorageRangeAt(t *testing.T) {
 		}
 	}
 }
+
+func TestSnapshotRanges(t *testing.T) {
+	t.Parallel()
+
+	var (
+		diskdb  = rawdb.NewMemoryDatabase()
+		db      = state.NewDatabaseWithConfig(diskdb, &trie.Config{Preimages: true})
+		sdb, _  = state.New(types.EmptyRootHash, db, nil)
+		storage = common.Address{0x01}
+	)
+	for i := 0; i < 20; i++ {
+		addr := common.BigToAddress(big.NewInt(int64(i + 2)))
+		sdb.SetBalance(addr, big.NewInt(int64(i+1)))
+		sdb.SetCode(addr, []byte{byte(i)})
+	}
+	sdb.SetBalance(storage, big.NewInt(1))
+	for i := 0; i < 10; i++ {
+		sdb.SetState(storage, common.BigToHash(big.NewInt(int64(i))), common.BigToHash(big.NewInt(int64(i+1))))
+	}
+	root, _ := sdb.Commit(0, false, false)
+	if err := db.TrieDB().Commit(root, false); err != nil {
+		t.Fatal(err)
+	}
+	snaps, err := snapshot.New(snapshot.Config{CacheSize: 16, SkipVerify: true}, diskdb, db.TrieDB(), common.Hash{}, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sdb, _ = state.New(root, db, nil)
+
+	// Paging through the snapshot matches paging through the trie.
+	opts := &state.DumpConfig{Max: 7}
+	for {
+		want := sdb.RawDump(opts)
+		got, err := sdb.RawDumpSnapshot(snaps, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("snapshot dump mismatch from %x:\ngot %s\nwant %s", opts.Start, dumper.Sdump(got), dumper.Sdump(want))
+		}
+		if got.Next == nil {
+			break
+		}
+		opts.Start = got.Next
+	}
+
+	for _, limit := range []int{0, 3, 100} {
+		want, err := storageRangeAt(sdb, root, storage, nil, limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := snapshotStorageRangeAt(snaps, []common.Hash{root}, sdb, storage, nil, limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("snapshot storage range mismatch for limit %d:\ngot %s\nwant %s", limit, dumper.Sdump(got), dumper.Sdump(want))
+		}
+	}
+
+	// A snapshot of a root with different storage is not used.
+	if _, err := snapshotStorageRangeAt(snaps, []common.Hash{{0xff}}, sdb, storage, nil, 100); err == nil {
+		t.Fatal("expected error for missing snapshot")
+	}
+
+	// Storage written by transaction 0 is not reflected by the storage root
+	// the range at transaction 1 is checked against, so the snapshot is not
+	// used.
+	sdb.SetTxContext(common.Hash{0x01}, 0)
+	sdb.SetState(storage, common.Hash{}, common.Hash{0xaa})
+	sdb.Finalise(true)
+	sdb.SetTxContext(common.Hash{0x02}, 1)
+	if sdb.GetStorageRoot(storage) != storageRootOf(t, snaps, root, storage) {
+		t.Fatal("storage root changed before hashing the state")
+	}
+	if _, err := snapshotStorageRangeAt(snaps, []common.Hash{root}, sdb, storage, nil, 100); err == nil {
+		t.Fatal("expected error for storage modified by a preceding transaction")
+	}
+}
+
+// storageRootOf returns the storage root of [addr] in the snapshot of [root].
+func storageRootOf(t *testing.T, snaps *snapshot.Tree, root common.Hash, addr common.Address) common.Hash {
+	data, err := snaps.Snapshot(root).AccountRLP(crypto.Keccak256Hash(addr.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	account, err := types.FullAccount(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return account.Root
+}
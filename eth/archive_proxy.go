@@ -0,0 +1,535 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package eth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/shubhamdubey02/coreth/consensus/misc/eip4844"
+	"github.com/shubhamdubey02/coreth/core"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/internal/ethapi"
+	"github.com/shubhamdubey02/coreth/metrics"
+	"github.com/shubhamdubey02/coreth/rpc"
+	"github.com/shubhamdubey02/coreth/trie"
+)
+
+var (
+	_ rpc.CallFallback = (*ArchiveProxy)(nil)
+
+	archiveProxyForwarded = metrics.NewRegisteredCounter("rpc/archiveproxy/forwarded", nil)
+	archiveProxyFailed    = metrics.NewRegisteredCounter("rpc/archiveproxy/failed", nil)
+	archiveProxyInvalid   = metrics.NewRegisteredCounter("rpc/archiveproxy/invalid", nil)
+
+	// errArchiveUnavailable is returned when the archive node fails or has
+	// no result, which is not a validation failure.
+	errArchiveUnavailable = errors.New("archive node result unavailable")
+)
+
+// archiveStateMethods maps the methods reading the state of an account to the
+// position of their block parameter. Their results are proven by eth_getProof
+// against the state root of the local block, so calls executing on the state,
+// such as eth_call, are not forwarded.
+var archiveStateMethods = map[string]int{
+	"eth_getBalance":          1,
+	"eth_getCode":             1,
+	"eth_getTransactionCount": 1,
+	"eth_getStorageAt":        2,
+	"eth_getProof":            2,
+}
+
+// archiveLookupMethods are the methods answering null once the block data
+// they look up was deleted by the retention policy.
+var archiveLookupMethods = map[string]struct{}{
+	"eth_getBlockByNumber":      {},
+	"eth_getBlockByHash":        {},
+	"eth_getBlockReceipts":      {},
+	"eth_getTransactionByHash":  {},
+	"eth_getTransactionReceipt": {},
+}
+
+// ArchiveProxy forwards the calls needing block data or state missing
+// locally to an upstream archive node, so that a pruned node serves the full
+// history of the chain.
+//
+// Upstream results are only returned for accepted blocks of the local chain,
+// once verified against their local header, which is kept when the block data
+// and state are deleted: transactions and receipts against the roots of the
+// header, and state against its state root with the proofs of eth_getProof.
+// Verified results are encoded locally rather than returned as received.
+type ArchiveProxy struct {
+	chain   *core.BlockChain
+	client  *rpc.Client
+	timeout time.Duration
+}
+
+// NewArchiveProxy returns a proxy to the archive node at [url], bounding each
+// forwarded call to [timeout].
+func NewArchiveProxy(chain *core.BlockChain, url string, timeout time.Duration) (*ArchiveProxy, error) {
+	client, err := rpc.DialContext(context.Background(), url)
+	if err != nil {
+		return nil, err
+	}
+	return &ArchiveProxy{
+		chain:   chain,
+		client:  client,
+		timeout: timeout,
+	}, nil
+}
+
+// Methods implements rpc.CallFallback.
+func (p *ArchiveProxy) Methods() []string {
+	methods := make([]string, 0, len(archiveStateMethods)+len(archiveLookupMethods))
+	for method := range archiveStateMethods {
+		methods = append(methods, method)
+	}
+	for method := range archiveLookupMethods {
+		methods = append(methods, method)
+	}
+	return methods
+}
+
+// Fallback implements rpc.CallFallback.
+func (p *ArchiveProxy) Fallback(ctx context.Context, method string, params json.RawMessage, result json.RawMessage, err error) (json.RawMessage, bool) {
+	var value interface{}
+	if index, ok := archiveStateMethods[method]; ok {
+		if !isMissingDataError(err) {
+			return nil, false
+		}
+		value, err = p.forwardStateCall(ctx, method, params, index)
+	} else if _, ok := archiveLookupMethods[method]; ok {
+		if err == nil && !bytes.Equal(result, []byte("null")) {
+			return nil, false
+		}
+		if err != nil && !isMissingDataError(err) {
+			return nil, false
+		}
+		value, err = p.forwardLookup(ctx, method, params)
+	} else {
+		return nil, false
+	}
+	if err != nil {
+		if !errors.Is(err, errArchiveUnavailable) {
+			log.Debug("Dropping archive proxy result failing validation", "method", method, "err", err)
+			archiveProxyInvalid.Inc(1)
+		}
+		return nil, false
+	}
+	if value == nil {
+		return nil, false
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, false
+	}
+	return encoded, true
+}
+
+// forwardStateCall answers a call reading the state of an account at the
+// block at position [index] of [params] from the proof of the account at the
+// local block.
+func (p *ArchiveProxy) forwardStateCall(ctx context.Context, method string, params json.RawMessage, index int) (interface{}, error) {
+	args, ok := decodeParams(params)
+	if !ok || index >= len(args) {
+		return nil, nil
+	}
+	var address common.Address
+	if err := json.Unmarshal(args[0], &address); err != nil {
+		return nil, nil
+	}
+	var keys []string
+	switch method {
+	case "eth_getStorageAt":
+		var key string
+		if err := json.Unmarshal(args[1], &key); err != nil {
+			return nil, nil
+		}
+		keys = []string{key}
+	case "eth_getProof":
+		if err := json.Unmarshal(args[1], &keys); err != nil {
+			return nil, nil
+		}
+	}
+	var blockNrOrHash rpc.BlockNumberOrHash
+	if err := json.Unmarshal(args[index], &blockNrOrHash); err != nil {
+		return nil, nil
+	}
+	header := p.resolve(blockNrOrHash)
+	if header == nil {
+		return nil, nil
+	}
+
+	account, err := p.proveAccount(ctx, header, address, keys)
+	if err != nil {
+		return nil, err
+	}
+	switch method {
+	case "eth_getBalance":
+		return account.Balance, nil
+	case "eth_getTransactionCount":
+		return account.Nonce, nil
+	case "eth_getStorageAt":
+		return hexutil.Bytes(common.BigToHash(account.StorageProof[0].Value.ToInt()).Bytes()), nil
+	case "eth_getCode":
+		return p.code(ctx, header, address, account.CodeHash)
+	default:
+		return account, nil
+	}
+}
+
+// proveAccount returns the account [address] and its storage [keys] at the
+// local block [header], as proven by the archive node.
+func (p *ArchiveProxy) proveAccount(ctx context.Context, header *types.Header, address common.Address, keys []string) (*ethapi.AccountResult, error) {
+	if keys == nil {
+		keys = []string{}
+	}
+	result, err := p.forward(ctx, "eth_getProof", address, keys, pinnedBlock(header.Hash()))
+	if err != nil {
+		return nil, err
+	}
+	var account ethapi.AccountResult
+	if err := json.Unmarshal(result, &account); err != nil {
+		return nil, err
+	}
+	if err := verifyAccountProof(header.Root, address, keys, &account); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// code returns the code of [address] at the local block [header], whose hash
+// must be [codeHash].
+func (p *ArchiveProxy) code(ctx context.Context, header *types.Header, address common.Address, codeHash common.Hash) (hexutil.Bytes, error) {
+	result, err := p.forward(ctx, "eth_getCode", address, pinnedBlock(header.Hash()))
+	if err != nil {
+		return nil, err
+	}
+	var code hexutil.Bytes
+	if err := json.Unmarshal(result, &code); err != nil {
+		return nil, err
+	}
+	// The code hash of a missing account is zero.
+	if len(code) == 0 && codeHash == (common.Hash{}) {
+		return code, nil
+	}
+	if hash := crypto.Keccak256Hash(code); hash != codeHash {
+		return nil, fmt.Errorf("code hash mismatch (proven %x, code %x)", codeHash, hash)
+	}
+	return code, nil
+}
+
+// forwardLookup answers a lookup of block data from the block data of the
+// archive node, verified against the local header of its block.
+func (p *ArchiveProxy) forwardLookup(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+	args, ok := decodeParams(params)
+	if !ok || len(args) == 0 {
+		return nil, nil
+	}
+	switch method {
+	case "eth_getTransactionByHash", "eth_getTransactionReceipt":
+		var hash common.Hash
+		if err := json.Unmarshal(args[0], &hash); err != nil {
+			return nil, nil
+		}
+		return p.lookupTransaction(ctx, method, hash)
+	}
+
+	var blockNrOrHash rpc.BlockNumberOrHash
+	if err := json.Unmarshal(args[0], &blockNrOrHash); err != nil {
+		return nil, nil
+	}
+	header := p.resolve(blockNrOrHash)
+	if header == nil {
+		return nil, nil
+	}
+	block, err := p.block(ctx, header)
+	if err != nil {
+		return nil, err
+	}
+	if method == "eth_getBlockReceipts" {
+		return p.receipts(ctx, block)
+	}
+	var fullTx bool
+	if len(args) > 1 {
+		if err := json.Unmarshal(args[1], &fullTx); err != nil {
+			return nil, nil
+		}
+	}
+	return p.marshalBlock(block, fullTx), nil
+}
+
+// lookupTransaction answers a lookup of the transaction [hash] or of its
+// receipt, located by the archive node.
+func (p *ArchiveProxy) lookupTransaction(ctx context.Context, method string, hash common.Hash) (interface{}, error) {
+	result, err := p.forward(ctx, method, hash)
+	if err != nil {
+		return nil, err
+	}
+	var location struct {
+		BlockHash        *common.Hash    `json:"blockHash"`
+		TransactionIndex *hexutil.Uint64 `json:"transactionIndex"`
+	}
+	if err := json.Unmarshal(result, &location); err != nil {
+		return nil, err
+	}
+	if location.BlockHash == nil || location.TransactionIndex == nil {
+		return nil, errArchiveUnavailable
+	}
+	header := p.acceptedHeader(*location.BlockHash)
+	if header == nil {
+		return nil, fmt.Errorf("block %s is not accepted locally", location.BlockHash)
+	}
+	block, err := p.block(ctx, header)
+	if err != nil {
+		return nil, err
+	}
+	index := uint64(*location.TransactionIndex)
+	if index >= uint64(len(block.Transactions())) || block.Transactions()[index].Hash() != hash {
+		return nil, fmt.Errorf("transaction %s is not at index %d of block %s", hash, index, block.Hash())
+	}
+	if method == "eth_getTransactionByHash" {
+		return p.marshalBlock(block, true)["transactions"].([]interface{})[index], nil
+	}
+	receipts, err := p.receipts(ctx, block)
+	if err != nil {
+		return nil, err
+	}
+	return receipts[index], nil
+}
+
+// block returns the block of the local [header], whose transactions and
+// extra data are downloaded from the archive node.
+func (p *ArchiveProxy) block(ctx context.Context, header *types.Header) (*types.Block, error) {
+	result, err := p.forward(ctx, "eth_getBlockByHash", header.Hash(), true)
+	if err != nil {
+		return nil, err
+	}
+	var body struct {
+		Transactions []*types.Transaction `json:"transactions"`
+		ExtData      hexutil.Bytes        `json:"blockExtraData"`
+	}
+	if err := json.Unmarshal(result, &body); err != nil {
+		return nil, err
+	}
+	if hash := types.DeriveSha(types.Transactions(body.Transactions), trie.NewStackTrie(nil)); hash != header.TxHash {
+		return nil, fmt.Errorf("transaction root hash mismatch (header value %x, calculated %x)", header.TxHash, hash)
+	}
+	// The extra data of blocks before Apricot Phase 1 is not committed to by
+	// their header.
+	if p.chain.Config().IsApricotPhase1(header.Time) || len(body.ExtData) > 0 {
+		if hash := types.CalcExtDataHash(body.ExtData); hash != header.ExtDataHash {
+			return nil, fmt.Errorf("extra data hash mismatch (header value %x, calculated %x)", header.ExtDataHash, hash)
+		}
+	}
+	extData := []byte(body.ExtData)
+	return types.NewBlockWithHeader(header).WithBody(body.Transactions, nil).WithExtData(0, &extData), nil
+}
+
+// receipts returns the encoded receipts of [block], downloaded from the
+// archive node.
+func (p *ArchiveProxy) receipts(ctx context.Context, block *types.Block) ([]map[string]interface{}, error) {
+	result, err := p.forward(ctx, "eth_getBlockReceipts", block.Hash())
+	if err != nil {
+		return nil, err
+	}
+	var receipts types.Receipts
+	if err := json.Unmarshal(result, &receipts); err != nil {
+		return nil, err
+	}
+	if err := core.ValidateReceipts(block, receipts); err != nil {
+		return nil, err
+	}
+	// Only the consensus fields of the receipts are verified, so the others
+	// are derived locally.
+	var blobGasPrice *big.Int
+	if excessBlobGas := block.ExcessBlobGas(); excessBlobGas != nil {
+		blobGasPrice = eip4844.CalcBlobFee(*excessBlobGas)
+	}
+	config := p.chain.Config()
+	if err := receipts.DeriveFields(config, block.Hash(), block.NumberU64(), block.Time(), block.BaseFee(), blobGasPrice, block.Transactions()); err != nil {
+		return nil, err
+	}
+	signer := types.MakeSigner(config, block.Number(), block.Time())
+	encoded := make([]map[string]interface{}, len(receipts))
+	for i, receipt := range receipts {
+		encoded[i] = ethapi.RPCMarshalReceipt(receipt, block.Hash(), block.NumberU64(), signer, block.Transactions()[i], i)
+	}
+	return encoded, nil
+}
+
+// marshalBlock encodes [block] as the local block APIs do.
+func (p *ArchiveProxy) marshalBlock(block *types.Block, fullTx bool) map[string]interface{} {
+	fields := ethapi.RPCMarshalBlock(block, true, fullTx, p.chain.Config())
+	fields["totalDifficulty"] = (*hexutil.Big)(block.Number())
+	return fields
+}
+
+// forward calls [method] on the archive node.
+func (p *ArchiveProxy) forward(ctx context.Context, method string, args ...interface{}) (json.RawMessage, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	var result json.RawMessage
+	if err := p.client.CallContext(ctx, &result, method, args...); err != nil {
+		log.Debug("Archive proxy call failed", "method", method, "err", err)
+		archiveProxyFailed.Inc(1)
+		return nil, fmt.Errorf("%w: %w", errArchiveUnavailable, err)
+	}
+	archiveProxyForwarded.Inc(1)
+	if len(result) == 0 || bytes.Equal(result, []byte("null")) {
+		return nil, errArchiveUnavailable
+	}
+	return result, nil
+}
+
+// resolve returns the header of the accepted local block [blockNrOrHash]
+// refers to, or nil if there is none.
+func (p *ArchiveProxy) resolve(blockNrOrHash rpc.BlockNumberOrHash) *types.Header {
+	if hash, ok := blockNrOrHash.Hash(); ok {
+		return p.acceptedHeader(hash)
+	}
+	lastAccepted := p.chain.LastAcceptedBlock().NumberU64()
+	number, ok := blockNrOrHash.Number()
+	if ok && number.IsFinalized() {
+		number = rpc.BlockNumber(lastAccepted)
+	}
+	if !ok || number < 0 || uint64(number) > lastAccepted {
+		return nil
+	}
+	return p.chain.GetHeaderByNumber(uint64(number))
+}
+
+// acceptedHeader returns the header of the accepted local block [hash], or
+// nil if there is none.
+func (p *ArchiveProxy) acceptedHeader(hash common.Hash) *types.Header {
+	header := p.chain.GetHeaderByHash(hash)
+	if header == nil || header.Number.Uint64() > p.chain.LastAcceptedBlock().NumberU64() {
+		return nil
+	}
+	if p.chain.GetCanonicalHash(header.Number.Uint64()) != hash {
+		return nil
+	}
+	return header
+}
+
+// Close closes the connection to the archive node.
+func (p *ArchiveProxy) Close() {
+	p.client.Close()
+}
+
+// verifyAccountProof verifies that [account] is the account [address] and
+// its storage [keys] under the state [root].
+func verifyAccountProof(root common.Hash, address common.Address, keys []string, account *ethapi.AccountResult) error {
+	if account.Address != address || account.Balance == nil || len(account.StorageProof) != len(keys) {
+		return fmt.Errorf("proof does not match the account %s", address)
+	}
+	db, err := proofDB(account.AccountProof)
+	if err != nil {
+		return err
+	}
+	enc, err := trie.VerifyProof(root, crypto.Keccak256(address.Bytes()), db)
+	if err != nil {
+		return fmt.Errorf("invalid proof of account %s: %w", address, err)
+	}
+	var proven types.StateAccount
+	if enc == nil {
+		// A missing account is proven empty, and its code and storage roots
+		// are zero.
+		proven = types.StateAccount{Balance: new(big.Int)}
+	} else if err := rlp.DecodeBytes(enc, &proven); err != nil {
+		return fmt.Errorf("invalid proof of account %s: %w", address, err)
+	}
+	if uint64(account.Nonce) != proven.Nonce || account.Balance.ToInt().Cmp(proven.Balance) != 0 ||
+		account.CodeHash != common.BytesToHash(proven.CodeHash) || account.StorageHash != proven.Root {
+		return fmt.Errorf("account %s does not match its proof", address)
+	}
+
+	for i, key := range keys {
+		storage := account.StorageProof[i]
+		slot := common.HexToHash(key)
+		if storage.Value == nil || common.HexToHash(storage.Key) != slot {
+			return fmt.Errorf("proof does not match the slot %s of account %s", key, address)
+		}
+		var value []byte
+		if proven.Root != (common.Hash{}) && proven.Root != types.EmptyRootHash {
+			db, err := proofDB(storage.Proof)
+			if err != nil {
+				return err
+			}
+			enc, err := trie.VerifyProof(proven.Root, crypto.Keccak256(slot.Bytes()), db)
+			if err != nil {
+				return fmt.Errorf("invalid proof of slot %s of account %s: %w", key, address, err)
+			}
+			if len(enc) > 0 {
+				if _, value, _, err = rlp.Split(enc); err != nil {
+					return fmt.Errorf("invalid proof of slot %s of account %s: %w", key, address, err)
+				}
+			}
+		}
+		if storage.Value.ToInt().Cmp(new(big.Int).SetBytes(value)) != 0 {
+			return fmt.Errorf("slot %s of account %s does not match its proof", key, address)
+		}
+	}
+	return nil
+}
+
+// proofDB returns the trie nodes of [proof] keyed by their hash.
+func proofDB(proof []string) (ethdb.KeyValueReader, error) {
+	db := memorydb.New()
+	for _, encoded := range proof {
+		node, err := hexutil.Decode(encoded)
+		if err != nil {
+			return nil, err
+		}
+		if err := db.Put(crypto.Keccak256(node), node); err != nil {
+			return nil, err
+		}
+	}
+	return db, nil
+}
+
+// pinnedBlock refers to the canonical block [hash].
+func pinnedBlock(hash common.Hash) rpc.BlockNumberOrHash {
+	return rpc.BlockNumberOrHashWithHash(hash, true)
+}
+
+// isMissingDataError reports whether [err] is caused by state or block data
+// missing locally.
+func isMissingDataError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var missingNode *trie.MissingNodeError
+	if errors.As(err, &missingNode) || errors.Is(err, errHistoricalStateUnavailable) {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "missing trie node") || strings.Contains(msg, "historical state unavailable")
+}
+
+// decodeParams splits the positional [params] of a call.
+func decodeParams(params json.RawMessage) ([]json.RawMessage, bool) {
+	var args []json.RawMessage
+	if len(params) == 0 {
+		return args, true
+	}
+	if err := json.Unmarshal(params, &args); err != nil {
+		return nil, false
+	}
+	return args, true
+}
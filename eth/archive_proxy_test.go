@@ -0,0 +1,255 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/shubhamdubey02/coreth/consensus/dummy"
+	"github.com/shubhamdubey02/coreth/core"
+	"github.com/shubhamdubey02/coreth/core/rawdb"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/core/vm"
+	"github.com/shubhamdubey02/coreth/internal/ethapi"
+	"github.com/shubhamdubey02/coreth/params"
+	"github.com/shubhamdubey02/coreth/rpc"
+	"github.com/shubhamdubey02/coreth/trie"
+	"github.com/stretchr/testify/require"
+)
+
+// archiveService is the upstream archive node of the tests, serving the data
+// of [chain] unless told to tamper with it.
+type archiveService struct {
+	db    ethdb.Database
+	chain *core.BlockChain
+
+	proofBlock     rpc.BlockNumberOrHash
+	tamperBalance  bool
+	tamperBlock    bool
+	tamperReceipts bool
+}
+
+// archiveProofList collects the nodes of a proof.
+type archiveProofList []string
+
+func (l *archiveProofList) Put(_ []byte, value []byte) error {
+	*l = append(*l, hexutil.Encode(value))
+	return nil
+}
+
+func (l *archiveProofList) Delete([]byte) error {
+	return errors.New("not supported")
+}
+
+func (s *archiveService) header(blockNrOrHash rpc.BlockNumberOrHash) *types.Header {
+	hash, _ := blockNrOrHash.Hash()
+	return s.chain.GetHeaderByHash(hash)
+}
+
+func (s *archiveService) GetProof(address common.Address, keys []string, blockNrOrHash rpc.BlockNumberOrHash) (*ethapi.AccountResult, error) {
+	s.proofBlock = blockNrOrHash
+	header := s.header(blockNrOrHash)
+	statedb, err := s.chain.StateAt(header.Root)
+	if err != nil {
+		return nil, err
+	}
+	tr, err := s.chain.StateCache().OpenTrie(header.Root)
+	if err != nil {
+		return nil, err
+	}
+	var proof archiveProofList
+	if err := tr.Prove(crypto.Keccak256(address.Bytes()), &proof); err != nil {
+		return nil, err
+	}
+	balance := statedb.GetBalance(address)
+	if s.tamperBalance {
+		balance = new(big.Int).Add(balance, common.Big1)
+	}
+	storage := make([]ethapi.StorageResult, len(keys))
+	for i, key := range keys {
+		storage[i] = ethapi.StorageResult{Key: key, Value: &hexutil.Big{}, Proof: []string{}}
+	}
+	return &ethapi.AccountResult{
+		Address:      address,
+		AccountProof: proof,
+		Balance:      (*hexutil.Big)(balance),
+		CodeHash:     statedb.GetCodeHash(address),
+		Nonce:        hexutil.Uint64(statedb.GetNonce(address)),
+		StorageHash:  statedb.GetStorageRoot(address),
+		StorageProof: storage,
+	}, nil
+}
+
+func (s *archiveService) GetCode(address common.Address, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	statedb, err := s.chain.StateAt(s.header(blockNrOrHash).Root)
+	if err != nil {
+		return nil, err
+	}
+	return statedb.GetCode(address), nil
+}
+
+func (s *archiveService) GetBlockByHash(hash common.Hash, fullTx bool) map[string]interface{} {
+	block := s.chain.GetBlockByHash(hash)
+	if s.tamperBlock {
+		block = block.WithBody(nil, nil)
+	}
+	return ethapi.RPCMarshalBlock(block, true, fullTx, s.chain.Config())
+}
+
+func (s *archiveService) GetBlockReceipts(blockNrOrHash rpc.BlockNumberOrHash) []map[string]interface{} {
+	hash, _ := blockNrOrHash.Hash()
+	block := s.chain.GetBlockByHash(hash)
+	receipts := s.chain.GetReceiptsByHash(hash)
+	signer := types.MakeSigner(s.chain.Config(), block.Number(), block.Time())
+	result := make([]map[string]interface{}, len(receipts))
+	for i, receipt := range receipts {
+		result[i] = ethapi.RPCMarshalReceipt(receipt, hash, block.NumberU64(), signer, block.Transactions()[i], i)
+		if s.tamperReceipts {
+			result[i]["status"] = hexutil.Uint(types.ReceiptStatusFailed)
+		}
+	}
+	return result
+}
+
+func (s *archiveService) location(hash common.Hash) map[string]interface{} {
+	_, blockHash, _, index := rawdb.ReadTransaction(s.db, hash)
+	return map[string]interface{}{"blockHash": blockHash, "transactionIndex": hexutil.Uint64(index)}
+}
+
+func (s *archiveService) GetTransactionByHash(hash common.Hash) map[string]interface{} {
+	return s.location(hash)
+}
+
+func (s *archiveService) GetTransactionReceipt(hash common.Hash) map[string]interface{} {
+	return s.location(hash)
+}
+
+func TestArchiveProxy(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		funder  = crypto.PubkeyToAddress(key.PublicKey)
+		to      = common.HexToAddress("0x0101010101010101010101010101010101010101")
+		gspec   = &core.Genesis{Config: params.TestChainConfig, Alloc: core.GenesisAlloc{funder: {Balance: big.NewInt(params.Ether)}}}
+		signer  = types.LatestSigner(params.TestChainConfig)
+		missing = &trie.MissingNodeError{}
+		ctx     = context.Background()
+	)
+	_, blocks, _, err := core.GenerateChainWithGenesis(gspec, dummy.NewCoinbaseFaker(), 2, 10, func(i int, gen *core.BlockGen) {
+		tx := types.MustSignNewTx(key, signer, &types.LegacyTx{Nonce: gen.TxNonce(funder), To: &to, Value: common.Big1, Gas: params.TxGas, GasPrice: gen.BaseFee()})
+		gen.AddTx(tx)
+	})
+	require.NoError(err)
+	db := rawdb.NewMemoryDatabase()
+	chain, err := core.NewBlockChain(db, core.DefaultCacheConfig, gspec, dummy.NewCoinbaseFaker(), vm.Config{}, common.Hash{}, false)
+	require.NoError(err)
+	defer chain.Stop()
+	_, err = chain.InsertChain(blocks)
+	require.NoError(err)
+	for _, block := range blocks {
+		require.NoError(chain.Accept(block))
+	}
+	chain.DrainAcceptorQueue()
+
+	service := &archiveService{db: db, chain: chain}
+	server := rpc.NewServer(0)
+	defer server.Stop()
+	require.NoError(server.RegisterName("eth", service))
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	proxy, err := NewArchiveProxy(chain, ts.URL, time.Second)
+	require.NoError(err)
+	defer proxy.Close()
+
+	// State reads are answered from proofs pinned to the hash of the local
+	// block.
+	params := json.RawMessage(`["` + funder.Hex() + `", "0x1"]`)
+	statedb, err := chain.StateAt(blocks[0].Root())
+	require.NoError(err)
+	result, ok := proxy.Fallback(ctx, "eth_getBalance", params, nil, missing)
+	require.True(ok)
+	require.JSONEq(`"`+hexutil.EncodeBig(statedb.GetBalance(funder))+`"`, string(result))
+	hash, ok := service.proofBlock.Hash()
+	require.True(ok)
+	require.Equal(blocks[0].Hash(), hash)
+
+	result, ok = proxy.Fallback(ctx, "eth_getTransactionCount", params, nil, missing)
+	require.True(ok)
+	require.JSONEq(`"0x1"`, string(result))
+	result, ok = proxy.Fallback(ctx, "eth_getCode", params, nil, missing)
+	require.True(ok)
+	require.JSONEq(`"0x"`, string(result))
+	result, ok = proxy.Fallback(ctx, "eth_getStorageAt", json.RawMessage(`["`+funder.Hex()+`", "0x0", "0x1"]`), nil, missing)
+	require.True(ok)
+	require.JSONEq(`"`+common.Hash{}.Hex()+`"`, string(result))
+
+	// Results not matching the proof are dropped.
+	service.tamperBalance = true
+	_, ok = proxy.Fallback(ctx, "eth_getBalance", params, nil, missing)
+	require.False(ok)
+	service.tamperBalance = false
+
+	// State reads failing for other reasons or at unknown blocks are not forwarded.
+	_, ok = proxy.Fallback(ctx, "eth_getBalance", params, nil, errors.New("invalid argument"))
+	require.False(ok)
+	_, ok = proxy.Fallback(ctx, "eth_getBalance", json.RawMessage(`["`+funder.Hex()+`", "0x10"]`), nil, missing)
+	require.False(ok)
+
+	// Lookups found locally are not forwarded.
+	params = json.RawMessage(`["0x1", false]`)
+	_, ok = proxy.Fallback(ctx, "eth_getBlockByNumber", params, json.RawMessage(`{}`), nil)
+	require.False(ok)
+
+	// Lookups missing locally are answered from the upstream block data
+	// matching the local header.
+	result, ok = proxy.Fallback(ctx, "eth_getBlockByNumber", params, json.RawMessage(`null`), nil)
+	require.True(ok)
+	expected := ethapi.RPCMarshalBlock(blocks[0], true, false, chain.Config())
+	expected["totalDifficulty"] = (*hexutil.Big)(blocks[0].Number())
+	encoded, err := json.Marshal(expected)
+	require.NoError(err)
+	require.JSONEq(string(encoded), string(result))
+
+	txHash := json.RawMessage(`["` + blocks[1].Transactions()[0].Hash().Hex() + `"]`)
+	result, ok = proxy.Fallback(ctx, "eth_getTransactionByHash", txHash, json.RawMessage(`null`), nil)
+	require.True(ok)
+	var tx map[string]interface{}
+	require.NoError(json.Unmarshal(result, &tx))
+	require.Equal(blocks[1].Transactions()[0].Hash().Hex(), tx["hash"])
+	require.Equal(blocks[1].Hash().Hex(), tx["blockHash"])
+
+	result, ok = proxy.Fallback(ctx, "eth_getTransactionReceipt", txHash, json.RawMessage(`null`), nil)
+	require.True(ok)
+	var receipt map[string]interface{}
+	require.NoError(json.Unmarshal(result, &receipt))
+	require.Equal("0x1", receipt["status"])
+	require.Equal(blocks[1].Hash().Hex(), receipt["blockHash"])
+
+	// Upstream block data not matching the local header is dropped.
+	service.tamperBlock = true
+	_, ok = proxy.Fallback(ctx, "eth_getBlockByNumber", params, json.RawMessage(`null`), nil)
+	require.False(ok)
+	service.tamperBlock = false
+	service.tamperReceipts = true
+	_, ok = proxy.Fallback(ctx, "eth_getTransactionReceipt", txHash, json.RawMessage(`null`), nil)
+	require.False(ok)
+	service.tamperReceipts = false
+
+	// Other methods are never forwarded.
+	require.NotContains(proxy.Methods(), "eth_call")
+	_, ok = proxy.Fallback(ctx, "eth_call", json.RawMessage(`[{}, "0x1"]`), nil, missing)
+	require.False(ok)
+}
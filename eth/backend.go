@@ -113,6 +113,20 @@ type Ethereum struct {
 
 	stackRPCs []rpc.API
 
+	// historicalStates regenerates pruned state. Nil if disabled.
+	historicalStates *historicalStates
+
+	// traceIndex persists the traces of accepted blocks. Nil if disabled.
+	traceIndex *tracers.TraceIndex
+
+	// archiveProxy forwards calls needing missing data to an archive node.
+	// Nil if disabled.
+	archiveProxy *ArchiveProxy
+
+	// filterAPI serves the filters of the eth namespace. Nil until the APIs
+	// are created.
+	filterAPI *filters.FilterAPI
+
 	settings Settings // Settings for Ethereum API
 }
 
@@ -210,6 +224,9 @@ func New(
 			TrieDirtyLimit:                  config.TrieDirtyCache,
 			TrieDirtyCommitTarget:           config.TrieDirtyCommitTarget,
 			TriePrefetcherParallelism:       config.TriePrefetcherParallelism,
+			TriePrefetcherMaxPendingTasks:   config.TriePrefetcherMaxPendingTasks,
+			TriePrefetcherAdaptive:          config.TriePrefetcherAdaptive,
+			TriePrefetcherMaxParallelism:    config.TriePrefetcherMaxParallelism,
 			Pruning:                         config.Pruning,
 			AcceptorQueueLimit:              config.AcceptorQueueLimit,
 			CommitInterval:                  config.CommitInterval,
@@ -225,11 +242,18 @@ func New(
 			AcceptedCacheSize:               config.AcceptedCacheSize,
 			TxLookupLimit:                   config.TxLookupLimit,
 			SkipTxIndexing:                  config.SkipTxIndexing,
+			ReceiptCosts:                    config.ReceiptCosts,
+			ReceiptsVerification:            config.ReceiptsVerification,
 			StateHistory:                    config.StateHistory,
 			StateScheme:                     scheme,
+			CacheTuner:                      config.CacheTuner,
 		}
 	)
 
+	if config.OpcodeStatsSampleRate > 0 {
+		vmConfig.OpcodeStats = vm.NewOpcodeStats(config.OpcodeStatsSampleRate)
+	}
+
 	if err := eth.precheckPopulateMissingTries(); err != nil {
 		return nil, err
 	}
@@ -258,6 +282,16 @@ func New(
 
 	eth.miner = miner.New(eth, &config.Miner, eth.blockchain.Config(), eth.EventMux(), eth.engine, clock)
 
+	if config.HistoricalStateReexec > 0 && scheme == rawdb.HashScheme {
+		eth.historicalStates = newHistoricalStates(eth.blockchain, dummy.NewFakerWithClock(cb, clock).ReplayEngine(), chainDb, config.HistoricalStateReexec, config.HistoricalStateCache)
+	}
+	if config.ArchiveProxyURL != "" {
+		eth.archiveProxy, err = NewArchiveProxy(eth.blockchain, config.ArchiveProxyURL, config.ArchiveProxyTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to archive proxy: %w", err)
+		}
+	}
+
 	allowUnprotectedTxHashes := make(map[common.Hash]struct{})
 	for _, txHash := range config.AllowUnprotectedTxHashes {
 		allowUnprotectedTxHashes[txHash] = struct{}{}
@@ -282,6 +316,14 @@ func New(
 	// Start the RPC service
 	eth.netRPCService = ethapi.NewNetAPI(eth.NetVersion())
 
+	if config.TraceIndex {
+		consumer, err := eth.blockchain.NewAcceptedConsumer(tracers.TraceIndexConsumer)
+		if err != nil {
+			return nil, err
+		}
+		eth.traceIndex = tracers.NewTraceIndex(eth.APIBackend, consumer)
+	}
+
 	eth.stackRPCs = stack.APIs()
 
 	// Successful startup; push a marker and check previous unclean shutdowns.
@@ -297,14 +339,24 @@ func (s *Ethereum) APIs() []rpc.API {
 
 	// Append tracing APIs
 	apis = append(apis, tracers.APIs(s.APIBackend)...)
+	apis = append(apis, rpc.API{
+		Namespace: "trace",
+		Service:   tracers.NewTraceAPI(s.APIBackend, s.traceIndex),
+		Name:      "trace",
+	})
 
 	// Add the APIs from the node
 	apis = append(apis, s.stackRPCs...)
 
 	// Create [filterSystem] with the log cache size set in the config.
 	filterSystem := filters.NewFilterSystem(s.APIBackend, filters.Config{
-		Timeout: 5 * time.Minute,
+		Timeout:                5 * time.Minute,
+		DurableFilterRetention: s.config.DurableFilterRetention,
 	})
+	filterAPI := filters.NewFilterAPI(filterSystem)
+	s.lock.Lock()
+	s.filterAPI = filterAPI
+	s.lock.Unlock()
 
 	// Append all the local APIs and return
 	return append(apis, []rpc.API{
@@ -314,7 +366,7 @@ func (s *Ethereum) APIs() []rpc.API {
 			Name:      "eth",
 		}, {
 			Namespace: "eth",
-			Service:   filters.NewFilterAPI(filterSystem),
+			Service:   filterAPI,
 			Name:      "eth-filter",
 		}, {
 			Namespace: "admin",
@@ -365,6 +417,15 @@ func (s *Ethereum) NetVersion() uint64               { return s.networkID }
 func (s *Ethereum) ArchiveMode() bool                { return !s.config.Pruning }
 func (s *Ethereum) BloomIndexer() *core.ChainIndexer { return s.bloomIndexer }
 
+// FilterAPI returns the API serving the filters of the eth namespace, or nil
+// if the APIs have not been created.
+func (s *Ethereum) FilterAPI() *filters.FilterAPI {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.filterAPI
+}
+
 // Start implements node.Lifecycle, starting all internal goroutines needed by the
 // Ethereum protocol implementation.
 func (s *Ethereum) Start() {
@@ -373,12 +434,22 @@ func (s *Ethereum) Start() {
 
 	// Regularly update shutdown marker
 	s.shutdownTracker.Start()
+
+	if s.traceIndex != nil {
+		s.traceIndex.Start()
+	}
 }
 
 // Stop implements node.Lifecycle, terminating all internal goroutines used by the
 // Ethereum protocol.
 // FIXME remove error from type if this will never return an error
 func (s *Ethereum) Stop() error {
+	if s.traceIndex != nil {
+		s.traceIndex.Stop()
+	}
+	if s.archiveProxy != nil {
+		s.archiveProxy.Close()
+	}
 	s.bloomIndexer.Close()
 	close(s.closeBloomHandler)
 	s.txPool.Close()
@@ -396,6 +467,11 @@ func (s *Ethereum) Stop() error {
 	return nil
 }
 
+// ArchiveProxy returns the proxy to the archive node, or nil if disabled.
+func (s *Ethereum) ArchiveProxy() *ArchiveProxy {
+	return s.archiveProxy
+}
+
 func (s *Ethereum) LastAcceptedBlock() *types.Block {
 	return s.blockchain.LastAcceptedBlock()
 }
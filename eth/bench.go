@@ -0,0 +1,122 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/shubhamdubey02/coreth/consensus/dummy"
+	"github.com/shubhamdubey02/coreth/core"
+	"github.com/shubhamdubey02/coreth/core/rawdb"
+	"github.com/shubhamdubey02/coreth/core/state"
+	"github.com/shubhamdubey02/coreth/core/vm"
+	"github.com/shubhamdubey02/coreth/trie"
+)
+
+var errBenchPathScheme = errors.New("block benchmarks require the hash state scheme")
+
+// BlockBenchmark is the time spent re-executing an accepted block.
+type BlockBenchmark struct {
+	Number  uint64      `json:"number"`
+	Hash    common.Hash `json:"hash"`
+	Txs     int         `json:"txs"`
+	GasUsed uint64      `json:"gasUsed"`
+	// Execution is the time spent processing the transactions of the block,
+	// Root the time spent hashing the resulting state and Commit the time
+	// spent committing it to the trie database.
+	Execution time.Duration `json:"execution"`
+	Root      time.Duration `json:"root"`
+	Commit    time.Duration `json:"commit"`
+}
+
+// BenchmarkBlocks re-executes the accepted blocks [from] to [to] on top of
+// the state of the parent of [from], calling [report] with the measurements
+// of each block. The resulting states are kept in an ephemeral trie database,
+// so the live state is left untouched.
+func (s *Ethereum) BenchmarkBlocks(ctx context.Context, from, to uint64, report func(*BlockBenchmark) error) error {
+	if s.blockchain.TrieDB().Scheme() == rawdb.PathScheme {
+		return errBenchPathScheme
+	}
+	if from == 0 || from > to {
+		return fmt.Errorf("invalid block range [%d, %d]", from, to)
+	}
+	if last := s.blockchain.LastAcceptedBlock().NumberU64(); to > last {
+		return fmt.Errorf("block %d is after the last accepted block %d", to, last)
+	}
+	parent := s.blockchain.GetBlockByNumber(from - 1)
+	if parent == nil {
+		return fmt.Errorf("block %d not found", from-1)
+	}
+
+	engine := s.engine
+	if faker, ok := engine.(*dummy.DummyEngine); ok {
+		engine = faker.ReplayEngine()
+	}
+	var (
+		config    = s.blockchain.Config()
+		processor = core.NewStateProcessor(config, s.blockchain, engine)
+		triedb    = trie.NewDatabase(s.chainDb, trie.HashDefaults)
+		database  = state.NewDatabaseWithNodeDB(s.chainDb, triedb)
+	)
+	defer triedb.Close()
+
+	statedb, err := state.New(parent.Root(), database, nil)
+	if err != nil {
+		return fmt.Errorf("state of block %d is unavailable: %w", parent.NumberU64(), err)
+	}
+	log.Info("Benchmarking blocks", "from", from, "to", to)
+	for number := from; number <= to; number++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		block := s.blockchain.GetBlockByNumber(number)
+		if block == nil {
+			return fmt.Errorf("block %d not found", number)
+		}
+		bench := &BlockBenchmark{
+			Number: number,
+			Hash:   block.Hash(),
+			Txs:    len(block.Transactions()),
+		}
+
+		start := time.Now()
+		_, _, bench.GasUsed, err = processor.Process(block, parent.Header(), statedb, vm.Config{})
+		if err != nil {
+			return fmt.Errorf("processing block %d failed: %w", number, err)
+		}
+		bench.Execution = time.Since(start)
+
+		deleteEmptyObjects := config.IsEIP158(block.Number())
+		start = time.Now()
+		if root := statedb.IntermediateRoot(deleteEmptyObjects); root != block.Root() {
+			return fmt.Errorf("state root mismatch at block %d: have %x, want %x", number, root, block.Root())
+		}
+		bench.Root = time.Since(start)
+
+		start = time.Now()
+		root, err := statedb.Commit(number, deleteEmptyObjects, false)
+		if err != nil {
+			return fmt.Errorf("committing block %d failed: %w", number, err)
+		}
+		bench.Commit = time.Since(start)
+
+		// Only the state of the last replayed block is retained in [triedb].
+		triedb.Reference(root, common.Hash{})
+		triedb.Dereference(parent.Root())
+		if statedb, err = state.New(root, database, nil); err != nil {
+			return fmt.Errorf("state of block %d is unavailable: %w", number, err)
+		}
+		if err := report(bench); err != nil {
+			return err
+		}
+		parent = block
+	}
+	triedb.Dereference(parent.Root())
+	return nil
+}
@@ -30,6 +30,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/shubhamdubey02/coreth/accounts/abi"
 	"github.com/shubhamdubey02/coreth/core"
 	"github.com/shubhamdubey02/coreth/core/txpool/blobpool"
 	"github.com/shubhamdubey02/coreth/core/txpool/legacypool"
@@ -100,12 +101,19 @@ type Config struct {
 	SkipBcVersionCheck bool `toml:"-"`
 
 	// TrieDB and snapshot options
-	TrieCleanCache            int
-	TrieDirtyCache            int
-	TrieDirtyCommitTarget     int
-	TriePrefetcherParallelism int
-	SnapshotCache             int
-	Preimages                 bool
+	TrieCleanCache                int
+	TrieDirtyCache                int
+	TrieDirtyCommitTarget         int
+	TriePrefetcherParallelism     int
+	TriePrefetcherMaxPendingTasks int
+	TriePrefetcherAdaptive        bool
+	TriePrefetcherMaxParallelism  int
+	SnapshotCache                 int
+	Preimages                     bool
+
+	// CacheTuner resizes the trie clean cache and the snapshot cache within
+	// bounds when accepted blocks lag behind the wall clock. Nil disables it.
+	CacheTuner *core.CacheTunerConfig
 
 	// AcceptedCacheSize is the depth of accepted headers cache and accepted
 	// logs cache at the accepted tip.
@@ -134,9 +142,25 @@ type Config struct {
 	// send-transaction variants. The unit is ether.
 	RPCTxFeeCap float64 `toml:",omitempty"`
 
+	// RPCEstimateGasTrace attaches a summary of the failed call frames to the
+	// errors of eth_estimateGas for reverting transactions.
+	RPCEstimateGasTrace bool
+
+	// RPCErrorABI holds the custom errors used to decode revert data in those
+	// summaries.
+	RPCErrorABI *abi.ABI `toml:"-"`
+
+	// RPCAcceptanceMetadata attaches the acceptance records of blocks to block
+	// and receipt responses.
+	RPCAcceptanceMetadata bool
+
 	// AllowUnfinalizedQueries allow unfinalized queries
 	AllowUnfinalizedQueries bool
 
+	// DurableFilterRetention is how long durable log filters survive without
+	// being polled. Zero disables durable filters.
+	DurableFilterRetention time.Duration
+
 	// AllowUnprotectedTxs allow unprotected transactions to be locally issued.
 	// Unprotected transactions are transactions that are signed without EIP-155
 	// replay protection.
@@ -175,4 +199,34 @@ type Config struct {
 	// This is useful for validators that don't need to index transactions.
 	// TxLookupLimit can be still used to control unindexing old transactions.
 	SkipTxIndexing bool
+
+	// HistoricalStateReexec is the maximum number of blocks re-executed to
+	// regenerate state pruned from disk for calls and traces. Zero disables
+	// regeneration.
+	HistoricalStateReexec uint64
+	// HistoricalStateCache is the disk budget (MB) of regenerated states.
+	HistoricalStateCache int
+
+	// ArchiveProxyURL is the endpoint of an archive node serving the calls
+	// needing state or block data missing locally. Empty disables the proxy.
+	ArchiveProxyURL string
+	// ArchiveProxyTimeout bounds each call forwarded to the archive node.
+	ArchiveProxyTimeout time.Duration
+
+	// ReceiptCosts stores the fee breakdown of each receipt (base fee burned,
+	// tip paid and gas refunded) and serves it with transaction receipts.
+	ReceiptCosts bool
+
+	// ReceiptsVerification inserts blocks with receipts verified against
+	// their headers instead of executing them, keeping no state.
+	ReceiptsVerification bool
+
+	// TraceIndex persists the call traces of accepted blocks, which are
+	// served by trace_filter.
+	TraceIndex bool
+
+	// OpcodeStatsSampleRate records the opcodes executed by one in every
+	// OpcodeStatsSampleRate transactions of processed blocks. Zero disables
+	// recording.
+	OpcodeStatsSampleRate uint64
 }
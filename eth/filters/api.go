@@ -38,6 +38,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
 	"github.com/shubhamdubey02/coreth/core/types"
 	"github.com/shubhamdubey02/coreth/interfaces"
 	"github.com/shubhamdubey02/coreth/internal/ethapi"
@@ -49,6 +50,7 @@ var (
 	errFilterNotFound    = errors.New("filter not found")
 	errInvalidBlockRange = errors.New("invalid block range params")
 	errExceedMaxTopics   = errors.New("exceed max topics")
+	errLogsNotRetained   = errors.New("logs deleted by the retention policy")
 )
 
 // The maximum number of topic criteria allowed, vm.LOG4 - vm.LOG0
@@ -75,6 +77,10 @@ type FilterAPI struct {
 	filtersMu sync.Mutex
 	filters   map[rpc.ID]*filter
 	timeout   time.Duration
+
+	// durableMu serializes the updates of durable filters, which are
+	// persisted rather than held in [filters].
+	durableMu sync.Mutex
 }
 
 // NewFilterAPI returns a new FilterAPI instance.
@@ -117,6 +123,12 @@ func (api *FilterAPI) timeoutLoop(timeout time.Duration) {
 			s.Unsubscribe()
 		}
 		toUninstall = nil
+
+		if api.sys.cfg.DurableFilterRetention > 0 {
+			if err := api.expireDurableFilters(); err != nil {
+				log.Warn("Failed to expire durable filters", "err", err)
+			}
+		}
 	}
 }
 
@@ -462,6 +474,9 @@ func (api *FilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([]*type
 
 // UninstallFilter removes the filter with the given filter id.
 func (api *FilterAPI) UninstallFilter(id rpc.ID) bool {
+	if name, ok := durableFilterName(id); ok {
+		return api.uninstallDurableFilter(name)
+	}
 	api.filtersMu.Lock()
 	f, found := api.filters[id]
 	if found {
@@ -520,6 +535,10 @@ func (api *FilterAPI) GetFilterLogs(ctx context.Context, id rpc.ID) ([]*types.Lo
 // For pending transaction and block filters the result is []common.Hash.
 // (pending)Log filters return []Log.
 func (api *FilterAPI) GetFilterChanges(id rpc.ID) (interface{}, error) {
+	if name, ok := durableFilterName(id); ok {
+		return api.durableFilterChanges(context.Background(), name)
+	}
+
 	api.filtersMu.Lock()
 	defer api.filtersMu.Unlock()
 
@@ -0,0 +1,187 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package filters
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/shubhamdubey02/coreth/rpc"
+	"github.com/shubhamdubey02/cryftgo/ids"
+)
+
+// atomicOpsBuffer is the number of blocks of atomic operations buffered for a
+// subscriber, beyond which the operations of further blocks are dropped.
+const atomicOpsBuffer = 64
+
+// AtomicOp is a shared memory request of an accepted atomic transaction,
+// putting a value in or removing a key from the shared memory of ChainID.
+type AtomicOp struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	BlockHash   common.Hash    `json:"blockHash"`
+	TxID        ids.ID         `json:"txID"`
+	ChainID     ids.ID         `json:"chainID"`
+	Remove      bool           `json:"remove"`
+	Key         hexutil.Bytes  `json:"key"`
+	// Value and Traits are only set for puts. Traits hold the addresses
+	// able to spend the put UTXO.
+	Value  hexutil.Bytes   `json:"value,omitempty"`
+	Traits []hexutil.Bytes `json:"traits,omitempty"`
+}
+
+// AtomicBackend provides the accepted atomic operations served by
+// [AtomicFilterAPI].
+type AtomicBackend interface {
+	// AtomicOps returns the atomic operations of the accepted block at
+	// [number], grouped by transaction.
+	AtomicOps(ctx context.Context, number uint64) ([]*AtomicOp, error)
+	// LastAcceptedNumber returns the number of the last accepted block.
+	LastAcceptedNumber() uint64
+	// SubscribeAtomicOps delivers the atomic operations of each accepted
+	// block that has any.
+	SubscribeAtomicOps(ch chan<- []*AtomicOp) event.Subscription
+	GetMaxBlocksPerRequest() int64
+}
+
+// AtomicFilterCriteria selects atomic operations by block range, destination
+// chain and address. Empty ChainIDs or Addresses match any chain or address.
+// Addresses are matched against the traits of puts, so removes only match
+// criteria without addresses.
+type AtomicFilterCriteria struct {
+	FromBlock *rpc.BlockNumber `json:"fromBlock"`
+	ToBlock   *rpc.BlockNumber `json:"toBlock"`
+	ChainIDs  []ids.ID         `json:"chainIDs"`
+	Addresses []ids.ShortID    `json:"addresses"`
+}
+
+func (crit *AtomicFilterCriteria) matches(op *AtomicOp) bool {
+	if len(crit.ChainIDs) > 0 && !slices.Contains(crit.ChainIDs, op.ChainID) {
+		return false
+	}
+	if len(crit.Addresses) == 0 {
+		return true
+	}
+	for _, trait := range op.Traits {
+		for _, addr := range crit.Addresses {
+			if bytes.Equal(trait, addr[:]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AtomicFilterAPI serves the shared memory operations of accepted atomic
+// transactions with the same ergonomics as logs, so that cross-chain
+// applications can observe atomic activity.
+type AtomicFilterAPI struct {
+	backend AtomicBackend
+}
+
+// NewAtomicFilterAPI returns a new AtomicFilterAPI.
+func NewAtomicFilterAPI(backend AtomicBackend) *AtomicFilterAPI {
+	return &AtomicFilterAPI{backend: backend}
+}
+
+// GetAtomicOps returns the accepted atomic operations matching [crit]. The
+// block range defaults to the last accepted block.
+func (api *AtomicFilterAPI) GetAtomicOps(ctx context.Context, crit AtomicFilterCriteria) ([]*AtomicOp, error) {
+	lastAccepted := api.backend.LastAcceptedNumber()
+	resolve := func(number *rpc.BlockNumber) uint64 {
+		if number == nil || *number < 0 {
+			return lastAccepted
+		}
+		return uint64(*number)
+	}
+	begin, end := resolve(crit.FromBlock), resolve(crit.ToBlock)
+	if begin > end {
+		return nil, errInvalidBlockRange
+	}
+	if end > lastAccepted {
+		end = lastAccepted
+	}
+	if maxBlocks := api.backend.GetMaxBlocksPerRequest(); maxBlocks > 0 && end-begin >= uint64(maxBlocks) {
+		return nil, fmt.Errorf("requested too many blocks from %d to %d, maximum is set to %d", begin, end, maxBlocks)
+	}
+
+	matched := []*AtomicOp{}
+	for number := begin; number <= end; number++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		ops, err := api.backend.AtomicOps(ctx, number)
+		if err != nil {
+			return nil, err
+		}
+		for _, op := range ops {
+			if crit.matches(op) {
+				matched = append(matched, op)
+			}
+		}
+	}
+	return matched, nil
+}
+
+// subscribeAtomicOps delivers the atomic operations of [backend] to [ch]
+// without ever blocking the backend, which sends them while accepting blocks.
+// The operations of blocks not fitting in [ch] are dropped.
+func subscribeAtomicOps(backend AtomicBackend, ch chan<- []*AtomicOp) event.Subscription {
+	accepted := make(chan []*AtomicOp)
+	sub := backend.SubscribeAtomicOps(accepted)
+	go func() {
+		for {
+			select {
+			case ops := <-accepted:
+				select {
+				case ch <- ops:
+				default:
+					log.Warn("Dropping atomic operations for slow subscriber", "block", ops[0].BlockNumber, "ops", len(ops))
+				}
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+	return sub
+}
+
+// AtomicOps creates a subscription that fires for each atomic operation
+// matching [crit] as its block is accepted. The block range is ignored.
+func (api *AtomicFilterAPI) AtomicOps(ctx context.Context, crit AtomicFilterCriteria) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	var (
+		rpcSub   = notifier.CreateSubscription()
+		accepted = make(chan []*AtomicOp, atomicOpsBuffer)
+		opsSub   = subscribeAtomicOps(api.backend, accepted)
+	)
+	go func() {
+		defer opsSub.Unsubscribe()
+		for {
+			select {
+			case ops := <-accepted:
+				for _, op := range ops {
+					if crit.matches(op) {
+						notifier.Notify(rpcSub.ID, op)
+					}
+				}
+			case <-rpcSub.Err(): // client send an unsubscribe request
+				return
+			case <-notifier.Closed(): // connection dropped
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
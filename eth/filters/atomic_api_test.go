@@ -0,0 +1,49 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package filters
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// testAtomicBackend is an [AtomicBackend] only serving subscriptions.
+type testAtomicBackend struct {
+	feed event.Feed
+}
+
+func (b *testAtomicBackend) AtomicOps(context.Context, uint64) ([]*AtomicOp, error) { return nil, nil }
+func (b *testAtomicBackend) LastAcceptedNumber() uint64                             { return 0 }
+func (b *testAtomicBackend) GetMaxBlocksPerRequest() int64                          { return 0 }
+func (b *testAtomicBackend) SubscribeAtomicOps(ch chan<- []*AtomicOp) event.Subscription {
+	return b.feed.Subscribe(ch)
+}
+
+// Tests that a subscriber not reading its atomic operations never blocks the
+// accepting backend.
+func TestSubscribeAtomicOpsStalled(t *testing.T) {
+	backend := new(testAtomicBackend)
+	stalled := make(chan []*AtomicOp, 1)
+	sub := subscribeAtomicOps(backend, stalled)
+	defer sub.Unsubscribe()
+
+	sent := make(chan struct{})
+	go func() {
+		defer close(sent)
+		for i := 0; i < 3; i++ {
+			backend.feed.Send([]*AtomicOp{{BlockNumber: 1}})
+		}
+	}()
+	select {
+	case <-sent:
+	case <-time.After(5 * time.Second):
+		t.Fatal("backend blocked by stalled subscriber")
+	}
+	if len(stalled) != 1 {
+		t.Fatalf("buffered blocks %d, want %d", len(stalled), 1)
+	}
+}
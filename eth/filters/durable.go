@@ -0,0 +1,204 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package filters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shubhamdubey02/coreth/core/rawdb"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/rpc"
+)
+
+// durableFilterIDPrefix prefixes the IDs of durable filters, which are
+// derived from their names so that reconnecting clients poll the same ID.
+const durableFilterIDPrefix = "durable:"
+
+// maxDurableFilterName bounds the length of the name of a durable filter.
+const maxDurableFilterName = 64
+
+var (
+	errDurableFiltersDisabled = errors.New("durable filters are not enabled")
+	errInvalidDurableName     = fmt.Errorf("durable filter name must be between 1 and %d characters", maxDurableFilterName)
+	errDurableBlockHash       = errors.New("durable filters cannot filter a single block hash")
+	errDurableFilterExists    = errors.New("durable filter exists with different criteria")
+)
+
+// durableFilterID returns the ID of the durable filter named [name].
+func durableFilterID(name string) rpc.ID {
+	return rpc.ID(durableFilterIDPrefix + name)
+}
+
+// durableFilterName returns the name of the durable filter with ID [id], and
+// whether [id] is the ID of a durable filter.
+func durableFilterName(id rpc.ID) (string, bool) {
+	return strings.CutPrefix(string(id), durableFilterIDPrefix)
+}
+
+// NewDurableFilter creates a log filter named [name] whose cursor is
+// persisted, and returns its ID. Polling the filter with getFilterChanges
+// returns the accepted logs matching [crit] since the last poll, including
+// those accepted while the client was disconnected, as long as the client
+// polls again within the durable filter retention. The logs are returned
+// from fromBlock if set, or else from the next accepted block.
+//
+// Creating a filter with the name of an existing filter and the same criteria
+// resumes the existing filter.
+func (api *FilterAPI) NewDurableFilter(name string, crit FilterCriteria) (rpc.ID, error) {
+	if api.sys.cfg.DurableFilterRetention <= 0 {
+		return "", errDurableFiltersDisabled
+	}
+	if len(name) == 0 || len(name) > maxDurableFilterName {
+		return "", errInvalidDurableName
+	}
+	if len(crit.Topics) > maxTopics {
+		return "", errExceedMaxTopics
+	}
+	if crit.BlockHash != nil {
+		return "", errDurableBlockHash
+	}
+
+	lastAccepted := api.sys.backend.LastAcceptedBlock().NumberU64()
+	filter := &rawdb.DurableFilter{
+		Addresses: crit.Addresses,
+		Topics:    crit.Topics,
+		Cursor:    lastAccepted,
+		LastPoll:  uint64(time.Now().Unix()),
+	}
+	if crit.FromBlock != nil && crit.FromBlock.Sign() >= 0 {
+		from := crit.FromBlock.Uint64()
+		if from == 0 {
+			// The genesis block has no logs.
+			from = 1
+		}
+		filter.Cursor = from - 1
+	}
+	if crit.ToBlock != nil && crit.ToBlock.Sign() >= 0 {
+		filter.ToBlock, filter.HasToBlock = crit.ToBlock.Uint64(), true
+		if filter.ToBlock < filter.Cursor {
+			return "", errInvalidBlockRange
+		}
+	}
+
+	api.durableMu.Lock()
+	defer api.durableMu.Unlock()
+
+	db := api.sys.backend.ChainDb()
+	if existing := rawdb.ReadDurableFilter(db, name); existing != nil && !api.durableFilterExpired(existing) {
+		if !sameCriteria(existing, filter) {
+			return "", errDurableFilterExists
+		}
+		existing.LastPoll = filter.LastPoll
+		filter = existing
+	}
+	rawdb.WriteDurableFilter(db, name, filter)
+	return durableFilterID(name), nil
+}
+
+// sameCriteria returns whether [a] and [b] filter the same logs. Nil and
+// empty lists are equal, as the stored lists do not distinguish them.
+func sameCriteria(a, b *rawdb.DurableFilter) bool {
+	if a.HasToBlock != b.HasToBlock || a.ToBlock != b.ToBlock {
+		return false
+	}
+	if len(a.Addresses) != len(b.Addresses) || len(a.Topics) != len(b.Topics) {
+		return false
+	}
+	for i := range a.Addresses {
+		if a.Addresses[i] != b.Addresses[i] {
+			return false
+		}
+	}
+	for i := range a.Topics {
+		if !slices.Equal(a.Topics[i], b.Topics[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// durableFilterExpired returns whether [filter] was last polled longer than
+// the durable filter retention ago.
+func (api *FilterAPI) durableFilterExpired(filter *rawdb.DurableFilter) bool {
+	lastPoll := time.Unix(int64(filter.LastPoll), 0)
+	return time.Since(lastPoll) > api.sys.cfg.DurableFilterRetention
+}
+
+// durableFilterChanges returns the accepted logs matching the durable filter
+// named [name] since it was last polled, and advances its cursor. At most
+// the maximum number of blocks per request are searched per poll, so that a
+// client catching up after a long disconnect polls repeatedly.
+func (api *FilterAPI) durableFilterChanges(ctx context.Context, name string) ([]*types.Log, error) {
+	api.durableMu.Lock()
+	defer api.durableMu.Unlock()
+
+	db := api.sys.backend.ChainDb()
+	filter := rawdb.ReadDurableFilter(db, name)
+	if filter == nil {
+		return nil, errFilterNotFound
+	}
+	if api.durableFilterExpired(filter) {
+		rawdb.DeleteDurableFilter(db, name)
+		return nil, errFilterNotFound
+	}
+
+	end := api.sys.backend.LastAcceptedBlock().NumberU64()
+	if filter.HasToBlock && filter.ToBlock < end {
+		end = filter.ToBlock
+	}
+	if maxBlocks := api.sys.backend.GetMaxBlocksPerRequest(); maxBlocks > 0 && end > filter.Cursor+uint64(maxBlocks) {
+		end = filter.Cursor + uint64(maxBlocks)
+	}
+	var logs []*types.Log
+	if end > filter.Cursor {
+		var err error
+		logs, err = api.sys.NewRangeFilter(int64(filter.Cursor+1), int64(end), filter.Addresses, filter.Topics).Logs(ctx)
+		if err != nil {
+			return nil, err
+		}
+		filter.Cursor = end
+	}
+	filter.LastPoll = uint64(time.Now().Unix())
+	rawdb.WriteDurableFilter(db, name, filter)
+	return returnLogs(logs), nil
+}
+
+// uninstallDurableFilter removes the durable filter named [name], and
+// returns whether it existed.
+func (api *FilterAPI) uninstallDurableFilter(name string) bool {
+	api.durableMu.Lock()
+	defer api.durableMu.Unlock()
+
+	db := api.sys.backend.ChainDb()
+	if rawdb.ReadDurableFilter(db, name) == nil {
+		return false
+	}
+	rawdb.DeleteDurableFilter(db, name)
+	return true
+}
+
+// expireDurableFilters removes the durable filters that were not polled
+// within the durable filter retention.
+func (api *FilterAPI) expireDurableFilters() error {
+	api.durableMu.Lock()
+	defer api.durableMu.Unlock()
+
+	db := api.sys.backend.ChainDb()
+	var expired []string
+	err := rawdb.IterateDurableFilters(db, func(name string, filter *rawdb.DurableFilter) bool {
+		if api.durableFilterExpired(filter) {
+			expired = append(expired, name)
+		}
+		return true
+	})
+	for _, name := range expired {
+		rawdb.DeleteDurableFilter(db, name)
+	}
+	return err
+}
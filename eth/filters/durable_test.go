@@ -0,0 +1,121 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package filters
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shubhamdubey02/coreth/consensus/dummy"
+	"github.com/shubhamdubey02/coreth/core"
+	"github.com/shubhamdubey02/coreth/core/rawdb"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/params"
+	"github.com/shubhamdubey02/coreth/trie"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDurableFilter(t *testing.T) {
+	var (
+		db       = rawdb.NewMemoryDatabase()
+		config   = Config{DurableFilterRetention: time.Hour}
+		_, sys   = newTestFilterSystem(t, db, config)
+		contract = common.BytesToAddress([]byte("contract"))
+		gspec    = &core.Genesis{
+			Config:  params.TestChainConfig,
+			BaseFee: big.NewInt(1),
+		}
+	)
+	_, chain, receipts, err := core.GenerateChainWithGenesis(gspec, dummy.NewFaker(), 10, 10, func(i int, gen *core.BlockGen) {
+		gen.AddUncheckedReceipt(makeReceipt(contract))
+		gen.AddUncheckedTx(types.NewTransaction(uint64(i), common.HexToAddress("0x999"), big.NewInt(999), 999, gen.BaseFee(), nil))
+	})
+	require.NoError(t, err)
+	gspec.MustCommit(db, trie.NewDatabase(db, trie.HashDefaults))
+	acceptUpTo := func(number uint64) {
+		for i, block := range chain[:number] {
+			rawdb.WriteBlock(db, block)
+			rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+			rawdb.WriteHeadBlockHash(db, block.Hash())
+			rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), receipts[i])
+		}
+	}
+
+	acceptUpTo(5)
+	api := NewFilterAPI(sys)
+	crit := FilterCriteria{Addresses: []common.Address{contract}}
+	id, err := api.NewDurableFilter("indexer", crit)
+	require.NoError(t, err)
+
+	changes, err := api.GetFilterChanges(id)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	// The logs accepted while the client is disconnected are returned when it
+	// reconnects, even to another instance of the API.
+	acceptUpTo(10)
+	api = NewFilterAPI(sys)
+	resumed, err := api.NewDurableFilter("indexer", crit)
+	require.NoError(t, err)
+	require.Equal(t, id, resumed)
+	changes, err = api.GetFilterChanges(id)
+	require.NoError(t, err)
+	logs := changes.([]*types.Log)
+	require.Len(t, logs, 5)
+	for i, log := range logs {
+		require.Equal(t, uint64(6+i), log.BlockNumber)
+	}
+	changes, err = api.GetFilterChanges(id)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	_, err = api.NewDurableFilter("indexer", FilterCriteria{})
+	require.ErrorIs(t, err, errDurableFilterExists)
+
+	// A filter can replay the logs from a past block.
+	replay, err := api.NewDurableFilter("replay", FilterCriteria{FromBlock: big.NewInt(8)})
+	require.NoError(t, err)
+	changes, err = api.GetFilterChanges(replay)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+
+	require.True(t, api.UninstallFilter(id))
+	require.False(t, api.UninstallFilter(id))
+	_, err = api.GetFilterChanges(id)
+	require.ErrorIs(t, err, errFilterNotFound)
+}
+
+func TestDurableFilterExpiry(t *testing.T) {
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		_, sys = newTestFilterSystem(t, db, Config{DurableFilterRetention: time.Hour})
+		api    = NewFilterAPI(sys)
+		gspec  = &core.Genesis{Config: params.TestChainConfig, BaseFee: big.NewInt(1)}
+	)
+	genesis := gspec.MustCommit(db, trie.NewDatabase(db, trie.HashDefaults))
+	rawdb.WriteHeadBlockHash(db, genesis.Hash())
+
+	id, err := api.NewDurableFilter("stale", FilterCriteria{})
+	require.NoError(t, err)
+	_, err = api.NewDurableFilter("fresh", FilterCriteria{})
+	require.NoError(t, err)
+
+	filter := rawdb.ReadDurableFilter(db, "stale")
+	filter.LastPoll = uint64(time.Now().Add(-2 * time.Hour).Unix())
+	rawdb.WriteDurableFilter(db, "stale", filter)
+
+	require.NoError(t, api.expireDurableFilters())
+	require.Nil(t, rawdb.ReadDurableFilter(db, "stale"))
+	require.NotNil(t, rawdb.ReadDurableFilter(db, "fresh"))
+	_, err = api.GetFilterChanges(id)
+	require.ErrorIs(t, err, errFilterNotFound)
+}
+
+func TestDurableFilterDisabled(t *testing.T) {
+	_, sys := newTestFilterSystem(t, rawdb.NewMemoryDatabase(), Config{})
+	_, err := NewFilterAPI(sys).NewDurableFilter("indexer", FilterCriteria{})
+	require.ErrorIs(t, err, errDurableFiltersDisabled)
+}
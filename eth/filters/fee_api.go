@@ -0,0 +1,93 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package filters
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/params"
+	"github.com/shubhamdubey02/coreth/rpc"
+)
+
+// FeeUpdate is the fee market state of an accepted block.
+type FeeUpdate struct {
+	Number    hexutil.Uint64 `json:"number"`
+	Hash      common.Hash    `json:"hash"`
+	Timestamp hexutil.Uint64 `json:"timestamp"`
+	// BaseFee and BlockGasCost are nil before the upgrades introducing them.
+	BaseFee      *hexutil.Big `json:"baseFee"`
+	BlockGasCost *hexutil.Big `json:"blockGasCost"`
+	// GasUsed includes the gas used by the atomic transactions of the block.
+	GasUsed  hexutil.Uint64 `json:"gasUsed"`
+	GasLimit hexutil.Uint64 `json:"gasLimit"`
+	// GasTarget is the gas the blocks of the rollup window of the base fee
+	// may consume before the base fee increases, or zero if the base fee is
+	// not dynamic.
+	GasTarget hexutil.Uint64 `json:"gasTarget"`
+	// Utilization is the ratio of GasUsed to GasLimit.
+	Utilization float64 `json:"utilization"`
+}
+
+// newFeeUpdate returns the fee update of the accepted block [header].
+func newFeeUpdate(config *params.ChainConfig, header *types.Header) *FeeUpdate {
+	update := &FeeUpdate{
+		Number:       hexutil.Uint64(header.Number.Uint64()),
+		Hash:         header.Hash(),
+		Timestamp:    hexutil.Uint64(header.Time),
+		BaseFee:      (*hexutil.Big)(header.BaseFee),
+		BlockGasCost: (*hexutil.Big)(header.BlockGasCost),
+		GasUsed:      hexutil.Uint64(header.GasUsed),
+		GasLimit:     hexutil.Uint64(header.GasLimit),
+	}
+	if header.ExtDataGasUsed != nil && header.ExtDataGasUsed.IsUint64() {
+		update.GasUsed += hexutil.Uint64(header.ExtDataGasUsed.Uint64())
+	}
+	switch {
+	case config.IsApricotPhase5(header.Time):
+		update.GasTarget = hexutil.Uint64(params.ApricotPhase5TargetGas)
+	case config.IsApricotPhase3(header.Time):
+		update.GasTarget = hexutil.Uint64(params.ApricotPhase3TargetGas)
+	}
+	if header.GasLimit > 0 {
+		update.Utilization = float64(update.GasUsed) / float64(header.GasLimit)
+	}
+	return update
+}
+
+// FeeUpdates sends a notification with the base fee, block gas cost and gas
+// utilization of each accepted block, so that fee oracles can follow the fee
+// market without fetching full headers.
+func (api *FilterAPI) FeeUpdates(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	var (
+		rpcSub = notifier.CreateSubscription()
+		config = api.sys.backend.ChainConfig()
+	)
+	go func() {
+		headers := make(chan *types.Header)
+		headersSub := api.events.SubscribeAcceptedHeads(headers)
+
+		for {
+			select {
+			case h := <-headers:
+				notifier.Notify(rpcSub.ID, newFeeUpdate(config, h))
+			case <-rpcSub.Err():
+				headersSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				headersSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
@@ -0,0 +1,46 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package filters
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/params"
+)
+
+func TestNewFeeUpdate(t *testing.T) {
+	header := &types.Header{
+		Number:         big.NewInt(7),
+		Time:           100,
+		BaseFee:        big.NewInt(25_000_000_000),
+		BlockGasCost:   big.NewInt(0),
+		GasUsed:        6_000_000,
+		ExtDataGasUsed: big.NewInt(2_000_000),
+		GasLimit:       16_000_000,
+	}
+	update := newFeeUpdate(params.TestChainConfig, header)
+	if update.Hash != header.Hash() || update.Number != 7 || update.Timestamp != 100 {
+		t.Fatalf("unexpected block of fee update %+v", update)
+	}
+	if update.BaseFee.ToInt().Cmp(header.BaseFee) != 0 {
+		t.Fatalf("base fee %s, want %s", update.BaseFee, header.BaseFee)
+	}
+	if update.GasUsed != hexutil.Uint64(8_000_000) {
+		t.Fatalf("gas used %d, want %d", update.GasUsed, 8_000_000)
+	}
+	if update.GasTarget != hexutil.Uint64(params.ApricotPhase5TargetGas) {
+		t.Fatalf("gas target %d, want %d", update.GasTarget, params.ApricotPhase5TargetGas)
+	}
+	if update.Utilization != 0.5 {
+		t.Fatalf("utilization %f, want 0.5", update.Utilization)
+	}
+
+	legacy := newFeeUpdate(params.TestLaunchConfig, &types.Header{Number: big.NewInt(1), GasLimit: 8_000_000})
+	if legacy.BaseFee != nil || legacy.GasTarget != 0 {
+		t.Fatalf("unexpected fee market of legacy block %+v", legacy)
+	}
+}
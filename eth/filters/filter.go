@@ -34,6 +34,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/shubhamdubey02/coreth/core/bloombits"
+	"github.com/shubhamdubey02/coreth/core/rawdb"
 	"github.com/shubhamdubey02/coreth/core/types"
 	"github.com/shubhamdubey02/coreth/rpc"
 )
@@ -115,6 +116,13 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 		if header == nil {
 			return nil, errors.New("unknown block")
 		}
+		tail, err := f.retentionTail(rawdb.RetentionReceipts)
+		if err != nil {
+			return nil, err
+		}
+		if header.Number.Uint64() < tail {
+			return nil, fmt.Errorf("%w: block %d is below %d", errLogsNotRetained, header.Number, tail)
+		}
 		return f.blockLogs(ctx, header)
 	}
 
@@ -198,6 +206,16 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 	if maxBlocks := f.sys.backend.GetMaxBlocksPerRequest(); f.end-f.begin >= maxBlocks && maxBlocks > 0 {
 		return nil, fmt.Errorf("requested too many blocks from %d to %d, maximum is set to %d", f.begin, f.end, maxBlocks)
 	}
+
+	// Blocks whose receipts or bloom bits were deleted would silently be
+	// reported to have no logs.
+	tail, err := f.retentionTail(rawdb.RetentionReceipts, rawdb.RetentionLogsIndex)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(f.begin) < tail {
+		return nil, fmt.Errorf("%w: from block %d is below %d", errLogsNotRetained, f.begin, tail)
+	}
 	// Gather all indexed logs, and finish with non indexed ones
 	logChan, errChan := f.rangeLogsAsync(ctx)
 	var logs []*types.Log
@@ -215,6 +233,22 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 	}
 }
 
+// retentionTail returns the first block whose data of every retention class
+// in [classes] is retained.
+func (f *Filter) retentionTail(classes ...string) (uint64, error) {
+	var tail uint64
+	for _, class := range classes {
+		classTail, err := rawdb.ReadRetentionTail(f.sys.backend.ChainDb(), class)
+		if err != nil {
+			return 0, err
+		}
+		if classTail != nil {
+			tail = max(tail, *classTail)
+		}
+	}
+	return tail, nil
+}
+
 // rangeLogsAsync retrieves block-range logs that match the filter criteria asynchronously,
 // it creates and returns two channels: one for delivering log data, and one for reporting errors.
 func (f *Filter) rangeLogsAsync(ctx context.Context) (chan *types.Log, chan error) {
@@ -49,6 +49,9 @@ import (
 // Config represents the configuration of the filter system.
 type Config struct {
 	Timeout time.Duration // how long filters stay active (default: 5min)
+	// DurableFilterRetention is how long durable filters survive without
+	// being polled. Zero disables durable filters.
+	DurableFilterRetention time.Duration
 }
 
 func (cfg Config) withDefaults() Config {
@@ -304,6 +307,10 @@ func (es *EventSystem) SubscribeLogs(crit interfaces.FilterQuery, logs chan []*t
 		to = rpc.BlockNumber(crit.ToBlock.Int64())
 	}
 
+	// safe and finalized logs are the logs of accepted blocks
+	if from.IsFinalized() || to.IsFinalized() {
+		return es.SubscribeAcceptedLogs(crit, logs)
+	}
 	// only interested in pending logs
 	if from == rpc.PendingBlockNumber && to == rpc.PendingBlockNumber {
 		return es.subscribePendingLogs(crit, logs), nil
@@ -339,6 +346,14 @@ func (es *EventSystem) SubscribeAcceptedLogs(crit interfaces.FilterQuery, logs c
 	} else {
 		to = rpc.BlockNumber(crit.ToBlock.Int64())
 	}
+	// safe and finalized refer to the last accepted block, which is where
+	// accepted logs start from
+	if from.IsFinalized() {
+		from = rpc.LatestBlockNumber
+	}
+	if to.IsFinalized() {
+		to = rpc.LatestBlockNumber
+	}
 
 	// subscribeAcceptedLogs if filter is valid (from SubscribeLogs)
 	if from == rpc.PendingBlockNumber && to == rpc.PendingBlockNumber ||
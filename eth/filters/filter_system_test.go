@@ -413,6 +413,10 @@ func TestLogFilterCreation(t *testing.T) {
 			{FilterCriteria{FromBlock: big.NewInt(1), ToBlock: big.NewInt(rpc.LatestBlockNumber.Int64())}, true},
 			// new mined and pending blocks
 			{FilterCriteria{FromBlock: big.NewInt(rpc.LatestBlockNumber.Int64()), ToBlock: big.NewInt(rpc.PendingBlockNumber.Int64())}, true},
+			// new accepted blocks
+			{FilterCriteria{FromBlock: big.NewInt(rpc.SafeBlockNumber.Int64()), ToBlock: big.NewInt(rpc.FinalizedBlockNumber.Int64())}, true},
+			// block number range to new accepted blocks
+			{FilterCriteria{FromBlock: big.NewInt(1), ToBlock: big.NewInt(rpc.FinalizedBlockNumber.Int64())}, true},
 			// from block "higher" than to block
 			{FilterCriteria{FromBlock: big.NewInt(2), ToBlock: big.NewInt(1)}, false},
 			// from block "higher" than to block
@@ -508,6 +512,38 @@ func TestInvalidGetRangeLogsRequest(t *testing.T) {
 	}
 }
 
+// TestRetainedLogsRequest tests that getLogs rejects blocks whose logs were
+// deleted by the retention policy.
+func TestRetainedLogsRequest(t *testing.T) {
+	t.Parallel()
+
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		_, sys = newTestFilterSystem(t, db, Config{})
+		api    = NewFilterAPI(sys)
+		header = &types.Header{Number: big.NewInt(2)}
+	)
+	rawdb.WriteHeader(db, header)
+	rawdb.WriteCanonicalHash(db, header.Hash(), 2)
+	if err := rawdb.WriteRetentionTail(db, rawdb.RetentionReceipts, 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := rawdb.WriteRetentionTail(db, rawdb.RetentionLogsIndex, 8); err != nil {
+		t.Fatal(err)
+	}
+
+	hash := header.Hash()
+	if _, err := api.GetLogs(context.Background(), FilterCriteria{BlockHash: &hash}); !errors.Is(err, errLogsNotRetained) {
+		t.Errorf("Expected Logs below the receipts tail to fail, but got: %v", err)
+	}
+	if _, err := api.GetLogs(context.Background(), FilterCriteria{FromBlock: big.NewInt(5), ToBlock: big.NewInt(9)}); !errors.Is(err, errLogsNotRetained) {
+		t.Errorf("Expected Logs below the logs index tail to fail, but got: %v", err)
+	}
+	if _, err := api.GetLogs(context.Background(), FilterCriteria{FromBlock: big.NewInt(8), ToBlock: big.NewInt(9)}); err != nil {
+		t.Errorf("Expected Logs of retained blocks to succeed, but got: %v", err)
+	}
+}
+
 // TestLogFilter tests whether log filters match the correct logs that are posted to the event feed.
 func TestLogFilter(t *testing.T) {
 	t.Parallel()
@@ -0,0 +1,80 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package filters
+
+import (
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shubhamdubey02/coreth/rpc"
+)
+
+// logOverhead approximates the memory of a log besides its topics and data.
+const logOverhead = 128
+
+// size returns an estimate of the bytes buffered by [f] until it is polled.
+// The filters lock is assumed to be held.
+func (f *filter) size() uint64 {
+	size := uint64(len(f.hashes) * common.HashLength)
+	for _, tx := range f.txs {
+		size += tx.Size()
+	}
+	for _, log := range f.logs {
+		size += uint64(logOverhead + len(log.Topics)*common.HashLength + len(log.Data))
+	}
+	return size
+}
+
+// MemoryUsage returns an estimate of the bytes buffered by the installed
+// filters until they are polled.
+func (api *FilterAPI) MemoryUsage() uint64 {
+	api.filtersMu.Lock()
+	defer api.filtersMu.Unlock()
+
+	var usage uint64
+	for _, f := range api.filters {
+		usage += f.size()
+	}
+	return usage
+}
+
+// ShedFilters uninstalls the filters buffering the most data until at most
+// [target] bytes remain buffered, and returns the number of filters
+// uninstalled. Clients polling an uninstalled filter are told it is not found,
+// as if it had timed out.
+func (api *FilterAPI) ShedFilters(target uint64) int {
+	type sizedFilter struct {
+		id   rpc.ID
+		size uint64
+	}
+	api.filtersMu.Lock()
+	var (
+		usage   uint64
+		filters = make([]sizedFilter, 0, len(api.filters))
+	)
+	for id, f := range api.filters {
+		size := f.size()
+		usage += size
+		filters = append(filters, sizedFilter{id: id, size: size})
+	}
+	sort.Slice(filters, func(i, j int) bool {
+		return filters[i].size > filters[j].size
+	})
+	var uninstalled []*Subscription
+	for _, f := range filters {
+		if usage <= target {
+			break
+		}
+		uninstalled = append(uninstalled, api.filters[f.id].s)
+		delete(api.filters, f.id)
+		usage -= f.size
+	}
+	api.filtersMu.Unlock()
+
+	// Unsubscribe outside the lock, as in timeoutLoop.
+	for _, s := range uninstalled {
+		s.Unsubscribe()
+	}
+	return len(uninstalled)
+}
@@ -0,0 +1,153 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package gasprice
+
+import (
+	"context"
+	"math/big"
+	"slices"
+
+	"github.com/shubhamdubey02/coreth/rpc"
+)
+
+const (
+	// feeEstimateBlocks is the number of recent blocks whose inclusion data
+	// is sampled by FeeEstimate.
+	feeEstimateBlocks = 40
+	// growthDenominator is the precision of the base fee growth ratios.
+	growthDenominator = 10_000
+)
+
+var (
+	// FeeEstimateTargets are the numbers of blocks in which the suggestions
+	// of FeeEstimate target the inclusion of a transaction.
+	FeeEstimateTargets = []uint64{1, 3, 10}
+
+	// feeEstimatePercentiles are the shares of the sampled windows of blocks
+	// in which the low, median and high suggestions would have been included.
+	feeEstimatePercentiles = [3]int{25, 50, 90}
+)
+
+// FeeSuggestion is a max fee and a tip per unit of gas for a dynamic fee
+// transaction.
+type FeeSuggestion struct {
+	MaxFee *big.Int
+	Tip    *big.Int
+}
+
+// FeeEstimate is the fees a transaction should pay to be included within
+// [Target] blocks. The low, median and high suggestions would have been
+// sufficient in 25%, 50% and 90% of the windows of [Target] recent blocks.
+type FeeEstimate struct {
+	Target uint64
+	Low    FeeSuggestion
+	Median FeeSuggestion
+	High   FeeSuggestion
+}
+
+// FeeEstimate returns the estimated base fee of the next block and the fee
+// suggestions for each of [FeeEstimateTargets], based on the minimum tips
+// required to be included in recent blocks and the growth of their base fees.
+func (oracle *Oracle) FeeEstimate(ctx context.Context) (*big.Int, []FeeEstimate, error) {
+	baseFee, err := oracle.EstimateBaseFee(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if baseFee == nil {
+		baseFee = new(big.Int)
+	}
+	head, err := oracle.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		latest   = head.Number.Uint64()
+		oldest   = uint64(0)
+		tips     []*big.Int
+		baseFees []*big.Int
+	)
+	if latest >= feeEstimateBlocks {
+		oldest = latest - feeEstimateBlocks + 1
+	}
+	for number := oldest; number <= latest; number++ {
+		feeInfo, err := oracle.getFeeInfo(ctx, number)
+		if err != nil {
+			return nil, nil, err
+		}
+		tip, blockBaseFee := feeInfo.tip, feeInfo.baseFee
+		if tip == nil {
+			tip = new(big.Int)
+		}
+		if blockBaseFee == nil {
+			blockBaseFee = new(big.Int)
+		}
+		tips = append(tips, tip)
+		baseFees = append(baseFees, blockBaseFee)
+	}
+
+	estimates := make([]FeeEstimate, len(FeeEstimateTargets))
+	for i, target := range FeeEstimateTargets {
+		windowTips, growths := sampleWindows(tips, baseFees, target)
+		suggestions := make([]FeeSuggestion, len(feeEstimatePercentiles))
+		for j, percentile := range feeEstimatePercentiles {
+			tip := new(big.Int).Set(windowTips[(len(windowTips)-1)*percentile/100])
+			if tip.Cmp(oracle.maxPrice) > 0 {
+				tip.Set(oracle.maxPrice)
+			}
+			if tip.Cmp(oracle.minPrice) < 0 {
+				tip.Set(oracle.minPrice)
+			}
+			maxFee := new(big.Int).Mul(baseFee, growths[(len(growths)-1)*percentile/100])
+			maxFee.Div(maxFee, big.NewInt(growthDenominator))
+			suggestions[j] = FeeSuggestion{MaxFee: maxFee.Add(maxFee, tip), Tip: tip}
+		}
+		estimates[i] = FeeEstimate{
+			Target: target,
+			Low:    suggestions[0],
+			Median: suggestions[1],
+			High:   suggestions[2],
+		}
+	}
+	return baseFee, estimates, nil
+}
+
+// sampleWindows returns, sorted in ascending order, the tip required to be
+// included within each window of [target] consecutive blocks and the growth
+// of the base fee over the window, relative to its first block and scaled by
+// [growthDenominator].
+func sampleWindows(tips, baseFees []*big.Int, target uint64) ([]*big.Int, []*big.Int) {
+	size := int(target)
+	if size > len(tips) {
+		size = len(tips)
+	}
+	var (
+		windowTips []*big.Int
+		growths    []*big.Int
+	)
+	for start := 0; start+size <= len(tips); start++ {
+		var (
+			tip        = tips[start]
+			maxBaseFee = baseFees[start]
+		)
+		for k := start + 1; k < start+size; k++ {
+			if tips[k].Cmp(tip) < 0 {
+				tip = tips[k]
+			}
+			if baseFees[k].Cmp(maxBaseFee) > 0 {
+				maxBaseFee = baseFees[k]
+			}
+		}
+		growth := big.NewInt(growthDenominator)
+		if first := baseFees[start]; first.Sign() > 0 {
+			growth.Mul(maxBaseFee, growth)
+			growth.Div(growth, first)
+		}
+		windowTips = append(windowTips, tip)
+		growths = append(growths, growth)
+	}
+	slices.SortFunc(windowTips, func(a, b *big.Int) int { return a.Cmp(b) })
+	slices.SortFunc(growths, func(a, b *big.Int) int { return a.Cmp(b) })
+	return windowTips, growths
+}
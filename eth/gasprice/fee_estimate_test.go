@@ -0,0 +1,66 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package gasprice
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shubhamdubey02/coreth/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSampleWindows(t *testing.T) {
+	require := require.New(t)
+
+	tips := []*big.Int{big.NewInt(5), big.NewInt(1), big.NewInt(3), big.NewInt(4)}
+	baseFees := []*big.Int{big.NewInt(100), big.NewInt(150), big.NewInt(100), big.NewInt(200)}
+
+	// A single block window requires the tip of the block.
+	windowTips, growths := sampleWindows(tips, baseFees, 1)
+	require.Equal([]*big.Int{big.NewInt(1), big.NewInt(3), big.NewInt(4), big.NewInt(5)}, windowTips)
+	require.Equal([]*big.Int{big.NewInt(10_000), big.NewInt(10_000), big.NewInt(10_000), big.NewInt(10_000)}, growths)
+
+	// Larger windows require the lowest tip of their blocks and grow to the
+	// highest base fee of their blocks.
+	windowTips, growths = sampleWindows(tips, baseFees, 2)
+	require.Equal([]*big.Int{big.NewInt(1), big.NewInt(1), big.NewInt(3)}, windowTips)
+	require.Equal([]*big.Int{big.NewInt(10_000), big.NewInt(15_000), big.NewInt(20_000)}, growths)
+
+	// Windows are truncated to the number of sampled blocks.
+	windowTips, growths = sampleWindows(tips, baseFees, 10)
+	require.Equal([]*big.Int{big.NewInt(1)}, windowTips)
+	require.Equal([]*big.Int{big.NewInt(20_000)}, growths)
+}
+
+func TestFeeEstimate(t *testing.T) {
+	require := require.New(t)
+
+	backend := newTestBackend(t, params.TestChainConfig, 3, common.Big0, testGenBlock(t, 55, 370))
+	defer backend.teardown()
+	oracle, err := NewOracle(backend, defaultOracleConfig())
+	require.NoError(err)
+	oracle.clock.Set(time.Unix(20, 0))
+
+	baseFee, estimates, err := oracle.FeeEstimate(context.Background())
+	require.NoError(err)
+	require.NotNil(baseFee)
+	require.Len(estimates, len(FeeEstimateTargets))
+	for i, estimate := range estimates {
+		require.Equal(FeeEstimateTargets[i], estimate.Target)
+		suggestions := []FeeSuggestion{estimate.Low, estimate.Median, estimate.High}
+		for j, suggestion := range suggestions {
+			require.GreaterOrEqual(suggestion.MaxFee.Cmp(new(big.Int).Add(baseFee, suggestion.Tip)), 0)
+			if j > 0 {
+				require.GreaterOrEqual(suggestion.Tip.Cmp(suggestions[j-1].Tip), 0)
+				require.GreaterOrEqual(suggestion.MaxFee.Cmp(suggestions[j-1].MaxFee), 0)
+			}
+		}
+	}
+	// The busy blocks required a tip to be included.
+	require.Positive(estimates[0].High.Tip.Sign())
+}
@@ -0,0 +1,386 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/shubhamdubey02/coreth/consensus"
+	"github.com/shubhamdubey02/coreth/core"
+	"github.com/shubhamdubey02/coreth/core/rawdb"
+	"github.com/shubhamdubey02/coreth/core/state"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/core/vm"
+	"github.com/shubhamdubey02/coreth/eth/tracers"
+	"github.com/shubhamdubey02/coreth/trie"
+)
+
+var errHistoricalStateUnavailable = errors.New("historical state unavailable")
+
+// historicalStates regenerates the state of blocks that was pruned from disk
+// by re-executing blocks from the nearest ancestor whose state is available,
+// so that calls and traces can be served at older heights on pruned nodes.
+//
+// Each regenerated state is cached on disk in a namespace of its own, holding
+// only the trie nodes and code that changed since the state it was replayed
+// from, and is itself used as a starting point by later regenerations. Since
+// the live state is never written to, the least recently used states can be
+// deleted once the cache exceeds its disk budget.
+type historicalStates struct {
+	chain     *core.BlockChain
+	chainDb   ethdb.Database
+	processor core.Processor
+	maxReexec uint64
+	budget    common.StorageSize
+
+	// lock serializes regenerations and guards the cache below.
+	lock sync.Mutex
+	// database reads the states on disk, excluding the cached ones.
+	database state.Database
+	nextID   uint64
+	size     common.StorageSize
+	// states holds the cached states by root. Each state is used more
+	// recently than the states replayed on top of it, so that the least
+	// recently used state never has descendants in the cache.
+	states lru.BasicLRU[common.Hash, *historicalState]
+}
+
+// historicalState is a regenerated state cached on disk.
+type historicalState struct {
+	id        uint64
+	namespace []byte
+	root      common.Hash
+	size      common.StorageSize
+
+	// parent is the cached state this one was replayed from, nil if it was
+	// replayed from a state on disk.
+	parent   *historicalState
+	children map[*historicalState]struct{}
+	// refs counts the callers using this state or one of its descendants.
+	refs int
+}
+
+// newHistoricalStates returns a regenerator replaying at most [maxReexec]
+// blocks per request with [engine], and caching up to [budgetMB] MB of
+// regenerated state on disk. States cached by a previous run are deleted.
+func newHistoricalStates(chain *core.BlockChain, engine consensus.Engine, chainDb ethdb.Database, maxReexec uint64, budgetMB int) *historicalStates {
+	if err := rawdb.DeleteHistoricalStates(chainDb); err != nil {
+		log.Warn("Failed to delete cached historical states", "err", err)
+	}
+	return &historicalStates{
+		chain:     chain,
+		chainDb:   chainDb,
+		processor: core.NewStateProcessor(chain.Config(), chain, engine),
+		maxReexec: maxReexec,
+		budget:    common.StorageSize(budgetMB * 1024 * 1024),
+		database:  state.NewDatabaseWithNodeDB(chainDb, trie.NewDatabase(chainDb, trie.HashDefaults)),
+		states:    lru.NewBasicLRU[common.Hash, *historicalState](math.MaxInt),
+	}
+}
+
+// stateAt returns the state of [block], regenerating it if necessary. The
+// returned release function must be called once the state is no longer used.
+func (h *historicalStates) stateAt(ctx context.Context, block *types.Block) (*state.StateDB, tracers.StateReleaseFunc, error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	// Walk back to the nearest ancestor whose state is cached or on disk,
+	// remembering the blocks to replay on top of it.
+	var (
+		current = block
+		replay  []*types.Block
+	)
+	base, err := h.available(current.Root())
+	for err != nil {
+		if uint64(len(replay)) == h.maxReexec {
+			return nil, nil, fmt.Errorf("%w: no state within %d blocks of block %d", errHistoricalStateUnavailable, h.maxReexec, block.NumberU64())
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		if current.NumberU64() == 0 {
+			return nil, nil, errors.New("genesis state is missing")
+		}
+		replay = append(replay, current)
+		parent := h.chain.GetBlock(current.ParentHash(), current.NumberU64()-1)
+		if parent == nil {
+			return nil, nil, fmt.Errorf("missing block %s %d", current.ParentHash(), current.NumberU64()-1)
+		}
+		current = parent
+		base, err = h.available(current.Root())
+	}
+	h.touch(base)
+
+	db := &historicalStateDB{
+		Database: h.chainDb,
+		state:    base,
+		memory:   memorydb.New(),
+	}
+	database := state.NewDatabaseWithNodeDB(db, trie.NewDatabase(db, trie.HashDefaults))
+	statedb, err := state.New(current.Root(), database, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	start := time.Now()
+	for i := len(replay) - 1; i >= 0; i-- {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		parent := current
+		current = replay[i]
+		if _, _, _, err := h.processor.Process(current, parent.Header(), statedb, vm.Config{}); err != nil {
+			return nil, nil, fmt.Errorf("processing block %d failed: %w", current.NumberU64(), err)
+		}
+		if err := h.commit(db, database, statedb, current); err != nil {
+			return nil, nil, err
+		}
+		statedb, err = state.New(current.Root(), database, nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("state reset after block %d failed: %w", current.NumberU64(), err)
+		}
+	}
+	if len(replay) > 0 {
+		log.Debug("Regenerated historical state", "block", block.NumberU64(), "replayed", len(replay), "elapsed", time.Since(start))
+	}
+
+	// Pin the returned state on behalf of the caller, so that neither it nor
+	// the states it reads from are deleted while in use.
+	pinned := db.state
+	for s := pinned; s != nil; s = s.parent {
+		s.refs++
+	}
+	h.shrink()
+	return statedb, func() {
+		h.lock.Lock()
+		defer h.lock.Unlock()
+
+		for s := pinned; s != nil; s = s.parent {
+			s.refs--
+		}
+		h.shrink()
+	}, nil
+}
+
+// available returns the cached state of [root], or nil if the state of
+// [root] is on disk. Returns an error if the state is unavailable.
+func (h *historicalStates) available(root common.Hash) (*historicalState, error) {
+	if s, ok := h.states.Peek(root); ok {
+		return s, nil
+	}
+	_, err := state.New(root, h.database, nil)
+	return nil, err
+}
+
+// commit writes the state of the replayed [block] to a new namespace of the
+// cache and makes it the state read by [db].
+func (h *historicalStates) commit(db *historicalStateDB, database state.Database, statedb *state.StateDB, block *types.Block) error {
+	s := &historicalState{
+		id:        h.nextID,
+		namespace: rawdb.HistoricalStateNamespace(h.nextID),
+		parent:    db.state,
+		children:  make(map[*historicalState]struct{}),
+	}
+	h.nextID++
+
+	db.write = s
+	defer func() { db.write = nil }()
+	root, err := statedb.Commit(block.NumberU64(), h.chain.Config().IsEIP158(block.Number()), false)
+	if err == nil && root != block.Root() {
+		err = fmt.Errorf("regenerated state root %s of block %d does not match %s", root, block.NumberU64(), block.Root())
+	}
+	if err == nil {
+		err = database.TrieDB().Commit(root, false)
+	}
+	if err != nil {
+		h.discard(s)
+		return fmt.Errorf("failed to commit regenerated state of block %d: %w", block.NumberU64(), err)
+	}
+	// A block leaving the state unchanged does not need an entry of its own.
+	if existing, ok := h.states.Peek(root); ok {
+		h.discard(s)
+		db.state = existing
+		return nil
+	}
+	s.root = root
+	if s.parent != nil {
+		s.parent.children[s] = struct{}{}
+	}
+	h.states.Add(root, s)
+	h.size += s.size
+	h.touch(s)
+	db.state = s
+	return nil
+}
+
+// discard deletes whatever was written for [s] before it was cached.
+func (h *historicalStates) discard(s *historicalState) {
+	if err := rawdb.DeleteHistoricalState(h.chainDb, s.id); err != nil {
+		log.Warn("Failed to delete historical state", "id", s.id, "err", err)
+	}
+}
+
+// touch marks [s] and its ancestors as used, each ancestor after its
+// descendants.
+func (h *historicalStates) touch(s *historicalState) {
+	for ; s != nil; s = s.parent {
+		h.states.Get(s.root)
+	}
+}
+
+// shrink deletes the least recently used states not in use until the cache
+// fits its budget.
+func (h *historicalStates) shrink() {
+	for h.size > h.budget {
+		var victim *historicalState
+		for _, root := range h.states.Keys() {
+			if s, _ := h.states.Peek(root); s.refs == 0 {
+				victim = s
+				break
+			}
+		}
+		if victim == nil {
+			return
+		}
+		if err := h.evict(victim); err != nil {
+			log.Warn("Failed to delete historical state", "root", victim.root, "err", err)
+			return
+		}
+	}
+}
+
+// evict deletes [s] along with the states replayed on top of it.
+func (h *historicalStates) evict(s *historicalState) error {
+	for child := range s.children {
+		if err := h.evict(child); err != nil {
+			return err
+		}
+	}
+	if err := rawdb.DeleteHistoricalState(h.chainDb, s.id); err != nil {
+		return err
+	}
+	h.states.Remove(s.root)
+	h.size -= s.size
+	if s.parent != nil {
+		delete(s.parent.children, s)
+	}
+	return nil
+}
+
+// historicalStateDB is the database of a regenerated state. It reads trie
+// nodes and code from the namespaces of [state] and its ancestors before
+// falling back to the chain database, and writes those of a replayed block to
+// the namespace of [write]. Writes made by callers of the returned state, such
+// as the code of contracts created while tracing, are kept in [memory].
+type historicalStateDB struct {
+	ethdb.Database
+	state  *historicalState
+	write  *historicalState
+	memory ethdb.KeyValueStore
+}
+
+func (db *historicalStateDB) Has(key []byte) (bool, error) {
+	if ok, err := db.memory.Has(key); err != nil || ok {
+		return ok, err
+	}
+	for s := db.state; s != nil; s = s.parent {
+		if ok, err := db.Database.Has(append(common.CopyBytes(s.namespace), key...)); err != nil || ok {
+			return ok, err
+		}
+	}
+	return db.Database.Has(key)
+}
+
+func (db *historicalStateDB) Get(key []byte) ([]byte, error) {
+	if data, err := db.memory.Get(key); err == nil {
+		return data, nil
+	}
+	for s := db.state; s != nil; s = s.parent {
+		if data, err := db.Database.Get(append(common.CopyBytes(s.namespace), key...)); err == nil {
+			return data, nil
+		}
+	}
+	return db.Database.Get(key)
+}
+
+func (db *historicalStateDB) Put(key []byte, value []byte) error {
+	if db.write == nil {
+		return db.memory.Put(key, value)
+	}
+	batch := db.NewBatch()
+	if err := batch.Put(key, value); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+func (db *historicalStateDB) Delete(key []byte) error {
+	if db.write == nil {
+		return db.memory.Delete(key)
+	}
+	batch := db.NewBatch()
+	if err := batch.Delete(key); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+func (db *historicalStateDB) NewBatch() ethdb.Batch {
+	if db.write == nil {
+		return db.memory.NewBatch()
+	}
+	return &historicalStateBatch{Batch: db.Database.NewBatch(), state: db.write}
+}
+
+func (db *historicalStateDB) NewBatchWithSize(size int) ethdb.Batch {
+	if db.write == nil {
+		return db.memory.NewBatchWithSize(size)
+	}
+	return &historicalStateBatch{Batch: db.Database.NewBatchWithSize(size), state: db.write}
+}
+
+// historicalStateBatch writes to the namespace of [state], accounting for the
+// size of the written data.
+type historicalStateBatch struct {
+	ethdb.Batch
+	state *historicalState
+}
+
+func (b *historicalStateBatch) Put(key []byte, value []byte) error {
+	key = append(common.CopyBytes(b.state.namespace), key...)
+	b.state.size += common.StorageSize(len(key) + len(value))
+	return b.Batch.Put(key, value)
+}
+
+func (b *historicalStateBatch) Delete(key []byte) error {
+	return b.Batch.Delete(append(common.CopyBytes(b.state.namespace), key...))
+}
+
+func (b *historicalStateBatch) Replay(w ethdb.KeyValueWriter) error {
+	return b.Batch.Replay(&historicalStateReplayer{w: w, prefix: len(b.state.namespace)})
+}
+
+// historicalStateReplayer strips the namespace from the keys of a replayed
+// batch.
+type historicalStateReplayer struct {
+	w      ethdb.KeyValueWriter
+	prefix int
+}
+
+func (r *historicalStateReplayer) Put(key []byte, value []byte) error {
+	return r.w.Put(key[r.prefix:], value)
+}
+
+func (r *historicalStateReplayer) Delete(key []byte) error {
+	return r.w.Delete(key[r.prefix:])
+}
@@ -0,0 +1,120 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package eth
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/shubhamdubey02/coreth/consensus/dummy"
+	"github.com/shubhamdubey02/coreth/core"
+	"github.com/shubhamdubey02/coreth/core/rawdb"
+	"github.com/shubhamdubey02/coreth/core/state"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/core/vm"
+	"github.com/shubhamdubey02/coreth/params"
+	"github.com/stretchr/testify/require"
+)
+
+// countHistoricalStateKeys returns the number of keys stored for the cached
+// historical state [id], or for every cached state if [id] is nil.
+func countHistoricalStateKeys(db ethdb.Iteratee, id *uint64) int {
+	prefix := []byte("hs")
+	if id != nil {
+		prefix = rawdb.HistoricalStateNamespace(*id)
+	}
+	it := db.NewIterator(prefix, nil)
+	defer it.Release()
+
+	count := 0
+	for it.Next() {
+		count++
+	}
+	return count
+}
+
+func TestHistoricalStates(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		funder = crypto.PubkeyToAddress(key.PublicKey)
+		to     = common.HexToAddress("0x0101010101010101010101010101010101010101")
+		gspec  = &core.Genesis{Config: params.TestChainConfig, Alloc: core.GenesisAlloc{funder: {Balance: big.NewInt(params.Ether)}}}
+		signer = types.LatestSigner(params.TestChainConfig)
+		// initCode deploys the single byte runtime code 0x00.
+		initCode = common.FromHex("6001600c60003960016000f300")
+		contract = crypto.CreateAddress(funder, 2)
+		ctx      = context.Background()
+	)
+	_, blocks, _, err := core.GenerateChainWithGenesis(gspec, dummy.NewCoinbaseFaker(), 10, 10, func(i int, gen *core.BlockGen) {
+		tx := types.MustSignNewTx(key, signer, &types.LegacyTx{Nonce: gen.TxNonce(funder), To: &to, Value: common.Big1, Gas: params.TxGas, GasPrice: gen.BaseFee()})
+		gen.AddTx(tx)
+		if i == 1 {
+			tx = types.MustSignNewTx(key, signer, &types.LegacyTx{Nonce: gen.TxNonce(funder), Data: initCode, Gas: 100_000, GasPrice: gen.BaseFee()})
+			gen.AddTx(tx)
+		}
+	})
+	require.NoError(err)
+	db := rawdb.NewMemoryDatabase()
+	chain, err := core.NewBlockChain(db, core.DefaultCacheConfig, gspec, dummy.NewCoinbaseFaker(), vm.Config{}, common.Hash{}, false)
+	require.NoError(err)
+	defer chain.Stop()
+	_, err = chain.InsertChain(blocks)
+	require.NoError(err)
+	for _, block := range blocks {
+		require.NoError(chain.Accept(block))
+	}
+	chain.DrainAcceptorQueue()
+
+	h := newHistoricalStates(chain, dummy.NewCoinbaseFaker(), db, 64, 1)
+
+	// Only the genesis state is on disk, so the states of the first blocks
+	// are regenerated and cached on disk.
+	statedb5, release5, err := h.stateAt(ctx, blocks[4])
+	require.NoError(err)
+	require.Equal(big.NewInt(5), statedb5.GetBalance(to))
+	require.Equal([]byte{0}, statedb5.GetCode(contract))
+	require.Equal(5, h.states.Len())
+	require.Positive(countHistoricalStateKeys(db, nil))
+
+	// Later states are regenerated from the cached ones.
+	statedb, release7, err := h.stateAt(ctx, blocks[6])
+	require.NoError(err)
+	require.Equal(big.NewInt(7), statedb.GetBalance(to))
+	require.Equal([]byte{0}, statedb.GetCode(contract))
+	require.Equal(7, h.states.Len())
+	s7, ok := h.states.Peek(blocks[6].Root())
+	require.True(ok)
+	require.Positive(countHistoricalStateKeys(db, &s7.id))
+
+	// Once over budget, the least recently used states are deleted from
+	// disk, except for those in use.
+	h.budget = 0
+	release7()
+	require.Equal(5, h.states.Len())
+	require.Zero(countHistoricalStateKeys(db, &s7.id))
+	statedb, err = state.New(blocks[4].Root(), statedb5.Database(), nil)
+	require.NoError(err)
+	require.Equal(big.NewInt(5), statedb.GetBalance(to))
+
+	release5()
+	require.Zero(h.states.Len())
+	require.Zero(h.size)
+	require.Zero(countHistoricalStateKeys(db, nil))
+
+	// Evicted states are regenerated when requested again, and the states
+	// left on disk by a previous run are deleted.
+	statedb, release, err := h.stateAt(ctx, blocks[6])
+	require.NoError(err)
+	require.Equal(big.NewInt(7), statedb.GetBalance(to))
+	require.Positive(countHistoricalStateKeys(db, nil))
+	newHistoricalStates(chain, dummy.NewCoinbaseFaker(), db, 64, 1)
+	require.Zero(countHistoricalStateKeys(db, nil))
+	release()
+}
@@ -69,6 +69,10 @@ func (eth *Ethereum) hashState(ctx context.Context, block *types.Block, reexec u
 			}, nil
 		}
 	}
+	// Regenerate the state within the configured effort bound if enabled.
+	if base == nil && eth.historicalStates != nil {
+		return eth.historicalStates.stateAt(ctx, block)
+	}
 	// The state is both for reading and writing, or it's unavailable in disk,
 	// try to construct/recover the state over an ephemeral trie.Database for
 	// isolating the live one.
@@ -287,6 +291,12 @@ func (eth *Ethereum) StateAtNextBlock(ctx context.Context, parent *types.Block,
 		release()
 		return nil, nil, err
 	}
+	if eth.blockchain.Config().IsStorageExpiry(nextBlock.Time()) {
+		if err := core.ApplyStorageExpiry(eth.blockchain, parent.Header(), nextBlock.Time(), statedb); err != nil {
+			release()
+			return nil, nil, err
+		}
+	}
 
 	return statedb, release, nil
 }
@@ -76,7 +76,10 @@ const (
 	maximumPendingTraceStates = 128
 )
 
-var errTxNotFound = errors.New("transaction not found")
+var (
+	errTxNotFound        = errors.New("transaction not found")
+	errTraceStreamClosed = errors.New("trace stream closed")
+)
 
 // StateReleaseFunc is used to deallocate resources held by constructing a
 // historical state for tracing purposes.
@@ -209,6 +212,13 @@ type txTraceResult struct {
 	Error  string      `json:"error,omitempty"`  // Trace failure produced by the tracer
 }
 
+// traceStreamEnd is the last notification of a trace stream, telling
+// consumers that the stream is complete rather than dropped.
+type traceStreamEnd struct {
+	Done    bool `json:"done"`    // always true
+	Results int  `json:"results"` // number of results notified before
+}
+
 func (t *txTraceResult) String() string {
 	return fmt.Sprintf("result: %s, error: %s", t.Result, t.Error)
 }
@@ -260,8 +270,15 @@ func (api *API) TraceChain(ctx context.Context, start, end rpc.BlockNumber, conf
 
 	resCh := api.traceChain(from, to, config, notifier.Closed())
 	go func() {
+		results := 0
 		for result := range resCh {
 			notifier.Notify(sub.ID, result)
+			results++
+		}
+		select {
+		case <-notifier.Closed():
+		default:
+			notifier.Notify(sub.ID, &traceStreamEnd{Done: true, Results: results})
 		}
 	}()
 	return sub, nil
@@ -481,6 +498,90 @@ func (api *API) TraceBlockByHash(ctx context.Context, hash common.Hash, config *
 	return api.traceBlock(ctx, block, config)
 }
 
+// TraceBlockByNumberStream is like TraceBlockByNumber, but pushes the result
+// of each transaction over a subscription as soon as it is produced instead of
+// buffering the results of the whole block.
+func (api *API) TraceBlockByNumberStream(ctx context.Context, number rpc.BlockNumber, config *TraceConfig) (*rpc.Subscription, error) {
+	block, err := api.blockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	return api.subscribeBlockTraces(ctx, block, config)
+}
+
+// TraceBlockByHashStream is like TraceBlockByHash, but pushes the result of
+// each transaction over a subscription as soon as it is produced instead of
+// buffering the results of the whole block.
+func (api *API) TraceBlockByHashStream(ctx context.Context, hash common.Hash, config *TraceConfig) (*rpc.Subscription, error) {
+	block, err := api.blockByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	return api.subscribeBlockTraces(ctx, block, config)
+}
+
+// subscribeBlockTraces pushes the trace of each transaction in [block] to a new
+// subscription, in transaction order. If tracing fails, a result carrying the
+// error is pushed and no further results follow. Unless the subscription was
+// closed, the stream ends with a [traceStreamEnd] notification.
+func (api *API) subscribeBlockTraces(ctx context.Context, block *types.Block, config *TraceConfig) (*rpc.Subscription, error) {
+	// Tracing a large block may produce more than fits in a single response,
+	// so streaming is only supported with subscriptions
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	resCh, err := api.traceBlockStream(block, config, notifier.Closed())
+	if err != nil {
+		return nil, err
+	}
+	sub := notifier.CreateSubscription()
+	go func() {
+		results := 0
+		for result := range resCh {
+			notifier.Notify(sub.ID, result)
+			results++
+		}
+		select {
+		case <-notifier.Closed():
+		default:
+			notifier.Notify(sub.ID, &traceStreamEnd{Done: true, Results: results})
+		}
+	}()
+	return sub, nil
+}
+
+// traceBlockStream traces the transactions of [block] in the background and
+// sends the result of each to the returned channel, which is closed once the
+// block has been traced, tracing fails or [closed] is closed. Only one result
+// is held in memory at a time, as each is sent before the next transaction
+// is traced.
+func (api *API) traceBlockStream(block *types.Block, config *TraceConfig, closed <-chan interface{}) (<-chan *txTraceResult, error) {
+	statedb, release, err := api.blockState(context.Background(), block, config)
+	if err != nil {
+		return nil, err
+	}
+	resCh := make(chan *txTraceResult)
+	go func() {
+		defer close(resCh)
+		defer release()
+
+		send := func(result *txTraceResult) error {
+			select {
+			case resCh <- result:
+				return nil
+			case <-closed:
+				return errTraceStreamClosed
+			}
+		}
+		err := api.traceBlockSequential(context.Background(), block, statedb, config, send)
+		if err != nil && !errors.Is(err, errTraceStreamClosed) {
+			_ = send(&txTraceResult{Error: err.Error()})
+		}
+	}()
+	return resCh, nil
+}
+
 // TraceBlock returns the structured logs created during the execution of EVM
 // and returns them as a JSON object.
 func (api *baseAPI) TraceBlock(ctx context.Context, blob hexutil.Bytes, config *TraceConfig) ([]*txTraceResult, error) {
@@ -616,19 +717,7 @@ func (api *FileTracerAPI) StandardTraceBadBlockToFile(ctx context.Context, hash
 // executes all the transactions contained within. The return value will be one item
 // per transaction, dependent on the requested tracer.
 func (api *baseAPI) traceBlock(ctx context.Context, block *types.Block, config *TraceConfig) ([]*txTraceResult, error) {
-	if block.NumberU64() == 0 {
-		return nil, errors.New("genesis is not traceable")
-	}
-	// Prepare base state
-	parent, err := api.blockByNumberAndHash(ctx, rpc.BlockNumber(block.NumberU64()-1), block.ParentHash())
-	if err != nil {
-		return nil, err
-	}
-	reexec := defaultTraceReexec
-	if config != nil && config.Reexec != nil {
-		reexec = *config.Reexec
-	}
-	statedb, release, err := api.backend.StateAtNextBlock(ctx, parent, block, reexec, nil, true, false)
+	statedb, release, err := api.blockState(ctx, block, config)
 	if err != nil {
 		return nil, err
 	}
@@ -643,13 +732,46 @@ func (api *baseAPI) traceBlock(ctx context.Context, block *types.Block, config *
 		}
 	}
 	// Native tracers have low overhead
+	results := make([]*txTraceResult, 0, block.Transactions().Len())
+	err = api.traceBlockSequential(ctx, block, statedb, config, func(result *txTraceResult) error {
+		results = append(results, result)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// blockState returns the state prior to executing [block], along with a
+// function releasing it.
+func (api *baseAPI) blockState(ctx context.Context, block *types.Block, config *TraceConfig) (*state.StateDB, StateReleaseFunc, error) {
+	if block.NumberU64() == 0 {
+		return nil, nil, errors.New("genesis is not traceable")
+	}
+	// Prepare base state
+	parent, err := api.blockByNumberAndHash(ctx, rpc.BlockNumber(block.NumberU64()-1), block.ParentHash())
+	if err != nil {
+		return nil, nil, err
+	}
+	reexec := defaultTraceReexec
+	if config != nil && config.Reexec != nil {
+		reexec = *config.Reexec
+	}
+	return api.backend.StateAtNextBlock(ctx, parent, block, reexec, nil, true, false)
+}
+
+// traceBlockSequential traces the transactions of [block] one after another
+// on top of [statedb], the state prior to executing [block], passing each
+// result to [onResult] as soon as it is produced. Tracing stops at the first
+// error returned by [onResult].
+func (api *baseAPI) traceBlockSequential(ctx context.Context, block *types.Block, statedb *state.StateDB, config *TraceConfig, onResult func(*txTraceResult) error) error {
 	var (
 		txs       = block.Transactions()
 		blockHash = block.Hash()
 		is158     = api.backend.ChainConfig().IsEIP158(block.Number())
 		blockCtx  = core.NewEVMBlockContext(block.Header(), api.chainContext(ctx), nil)
 		signer    = types.MakeSigner(api.backend.ChainConfig(), block.Number(), block.Time())
-		results   = make([]*txTraceResult, len(txs))
 	)
 	for i, tx := range txs {
 		// Generate the next state snapshot fast without tracing
@@ -662,14 +784,16 @@ func (api *baseAPI) traceBlock(ctx context.Context, block *types.Block, config *
 		}
 		res, err := api.traceTx(ctx, msg, txctx, blockCtx, statedb, config)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		if err := onResult(&txTraceResult{TxHash: tx.Hash(), Result: res}); err != nil {
+			return err
 		}
-		results[i] = &txTraceResult{TxHash: tx.Hash(), Result: res}
 		// Finalize the state so any modifications are written to the trie
 		// Only delete empty objects if EIP158/161 (a.k.a Spurious Dragon) is in effect
 		statedb.Finalise(is158)
 	}
-	return results, nil
+	return nil
 }
 
 // traceBlockParallel is for tracers that have a high overhead (read JS tracers). One thread
@@ -1032,3 +1032,116 @@ func TestTraceChain(t *testing.T) {
 		}
 	}
 }
+
+func TestTraceBlockStream(t *testing.T) {
+	t.Parallel()
+
+	accounts := newAccounts(2)
+	genesis := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc: core.GenesisAlloc{
+			accounts[0].addr: {Balance: big.NewInt(5 * params.Ether)},
+		},
+	}
+	const txs = 5
+	signer := types.HomesteadSigner{}
+	backend := newTestBackend(t, 1, genesis, func(i int, b *core.BlockGen) {
+		for nonce := uint64(0); nonce < txs; nonce++ {
+			tx, _ := types.SignTx(types.NewTransaction(nonce, accounts[1].addr, big.NewInt(1000), params.TxGas, b.BaseFee(), nil), signer, accounts[0].key)
+			b.AddTx(tx)
+		}
+	})
+	defer backend.teardown()
+	api := NewAPI(backend)
+
+	block, err := api.blockByNumber(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := api.traceBlock(context.Background(), block, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resCh, err := api.traceBlockStream(block, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var have []*txTraceResult
+	for result := range resCh {
+		have = append(have, result)
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Fatalf("streamed traces differ from buffered traces, have %v want %v", have, want)
+	}
+
+	// Closing the stream stops tracing after the pending result.
+	closed := make(chan interface{})
+	resCh, err = api.traceBlockStream(block, nil, closed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-resCh
+	close(closed)
+	for range resCh {
+	}
+
+	genesisBlock, _ := api.blockByNumber(context.Background(), 0)
+	if _, err := api.traceBlockStream(genesisBlock, nil, nil); err == nil {
+		t.Fatal("expected error tracing genesis")
+	}
+}
+
+func TestTraceBlockStreamSubscription(t *testing.T) {
+	t.Parallel()
+
+	accounts := newAccounts(2)
+	genesis := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc: core.GenesisAlloc{
+			accounts[0].addr: {Balance: big.NewInt(5 * params.Ether)},
+		},
+	}
+	const txs = 3
+	signer := types.HomesteadSigner{}
+	backend := newTestBackend(t, 1, genesis, func(i int, b *core.BlockGen) {
+		for nonce := uint64(0); nonce < txs; nonce++ {
+			tx, _ := types.SignTx(types.NewTransaction(nonce, accounts[1].addr, big.NewInt(1000), params.TxGas, b.BaseFee(), nil), signer, accounts[0].key)
+			b.AddTx(tx)
+		}
+	})
+	defer backend.teardown()
+
+	server := rpc.NewServer(0)
+	defer server.Stop()
+	if err := server.RegisterName("debug", NewAPI(backend)); err != nil {
+		t.Fatal(err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	ch := make(chan json.RawMessage)
+	sub, err := client.Subscribe(context.Background(), "debug", ch, "traceBlockByNumberStream", rpc.BlockNumber(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	// Each transaction is notified, followed by the end of the stream.
+	for i := 0; i < txs; i++ {
+		var result txTraceResult
+		if err := json.Unmarshal(<-ch, &result); err != nil {
+			t.Fatal(err)
+		}
+		if result.TxHash == (common.Hash{}) || result.Error != "" {
+			t.Fatalf("unexpected result %d: %v", i, result)
+		}
+	}
+	var end traceStreamEnd
+	if err := json.Unmarshal(<-ch, &end); err != nil {
+		t.Fatal(err)
+	}
+	if !end.Done || end.Results != txs {
+		t.Fatalf("unexpected end of stream %+v, want %d results", end, txs)
+	}
+}
@@ -35,12 +35,18 @@ import (
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/shubhamdubey02/coreth/core"
 	"github.com/shubhamdubey02/coreth/core/rawdb"
+	"github.com/shubhamdubey02/coreth/core/state"
 	"github.com/shubhamdubey02/coreth/core/types"
 	"github.com/shubhamdubey02/coreth/core/vm"
 	"github.com/shubhamdubey02/coreth/eth/tracers"
 	"github.com/shubhamdubey02/coreth/tests"
+	"github.com/shubhamdubey02/coreth/trie"
 )
 
 // prestateTrace is the result of a prestateTrace run.
@@ -91,61 +97,14 @@ func testPrestateDiffTracer(tracerName string, dirPath string, t *testing.T) {
 		t.Run(camel(strings.TrimSuffix(file.Name(), ".json")), func(t *testing.T) {
 			t.Parallel()
 
-			var (
-				test = new(testcase)
-				tx   = new(types.Transaction)
-			)
+			test := new(testcase)
 			// Call tracer test found, read if from disk
 			if blob, err := os.ReadFile(filepath.Join("testdata", dirPath, file.Name())); err != nil {
 				t.Fatalf("failed to read testcase: %v", err)
 			} else if err := json.Unmarshal(blob, test); err != nil {
 				t.Fatalf("failed to parse testcase: %v", err)
 			}
-			if err := tx.UnmarshalBinary(common.FromHex(test.Input)); err != nil {
-				t.Fatalf("failed to parse testcase input: %v", err)
-			}
-			// Configure a blockchain with the given prestate
-			var (
-				signer    = types.MakeSigner(test.Genesis.Config, new(big.Int).SetUint64(uint64(test.Context.Number)), uint64(test.Context.Time))
-				origin, _ = signer.Sender(tx)
-				txContext = vm.TxContext{
-					Origin:   origin,
-					GasPrice: tx.GasPrice(),
-				}
-				context = vm.BlockContext{
-					CanTransfer:       core.CanTransfer,
-					CanTransferMC:     core.CanTransferMC,
-					Transfer:          core.Transfer,
-					TransferMultiCoin: core.TransferMultiCoin,
-					Coinbase:          test.Context.Miner,
-					BlockNumber:       new(big.Int).SetUint64(uint64(test.Context.Number)),
-					Time:              uint64(test.Context.Time),
-					Difficulty:        (*big.Int)(test.Context.Difficulty),
-					GasLimit:          uint64(test.Context.GasLimit),
-					BaseFee:           test.Genesis.BaseFee,
-				}
-				triedb, _, statedb = tests.MakePreState(rawdb.NewMemoryDatabase(), test.Genesis.Alloc, false, rawdb.HashScheme)
-			)
-			defer triedb.Close()
-
-			tracer, err := tracers.DefaultDirectory.New(tracerName, new(tracers.Context), test.TracerConfig)
-			if err != nil {
-				t.Fatalf("failed to create call tracer: %v", err)
-			}
-			evm := vm.NewEVM(context, txContext, statedb, test.Genesis.Config, vm.Config{Tracer: tracer})
-			msg, err := core.TransactionToMessage(tx, signer, nil)
-			if err != nil {
-				t.Fatalf("failed to prepare transaction for tracing: %v", err)
-			}
-			st := core.NewStateTransition(evm, msg, new(core.GasPool).AddGas(tx.Gas()))
-			if _, err = st.TransitionDb(); err != nil {
-				t.Fatalf("failed to execute transaction: %v", err)
-			}
-			// Retrieve the trace result and compare against the expected
-			res, err := tracer.GetResult()
-			if err != nil {
-				t.Fatalf("failed to retrieve trace result: %v", err)
-			}
+			res, _ := tracePrestateTestcase(t, tracerName, test, test.TracerConfig, nil)
 			// The legacy javascript calltracer marshals json in js, which
 			// is not deterministic (as opposed to the golang json encoder).
 			if strings.HasSuffix(dirPath, "_legacy") {
@@ -165,3 +124,159 @@ func testPrestateDiffTracer(tracerName string, dirPath string, t *testing.T) {
 		})
 	}
 }
+
+// tracePrestateTestcase executes the transaction of [test] on top of its
+// genesis with the tracer [tracerName] configured by [tracerConfig]. It
+// returns the trace result and the root of the genesis state.
+// tracePrestateTestcase traces the transaction of [test], after applying
+// [prepare] to its pre state if set, and returns the trace and the root of
+// the state the transaction was executed on.
+func tracePrestateTestcase(t *testing.T, tracerName string, test *testcase, tracerConfig json.RawMessage, prepare func(*state.StateDB)) (json.RawMessage, common.Hash) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(common.FromHex(test.Input)); err != nil {
+		t.Fatalf("failed to parse testcase input: %v", err)
+	}
+	// Configure a blockchain with the given prestate
+	var (
+		signer    = types.MakeSigner(test.Genesis.Config, new(big.Int).SetUint64(uint64(test.Context.Number)), uint64(test.Context.Time))
+		origin, _ = signer.Sender(tx)
+		txContext = vm.TxContext{
+			Origin:   origin,
+			GasPrice: tx.GasPrice(),
+		}
+		context = vm.BlockContext{
+			CanTransfer:       core.CanTransfer,
+			CanTransferMC:     core.CanTransferMC,
+			Transfer:          core.Transfer,
+			TransferMultiCoin: core.TransferMultiCoin,
+			Coinbase:          test.Context.Miner,
+			BlockNumber:       new(big.Int).SetUint64(uint64(test.Context.Number)),
+			Time:              uint64(test.Context.Time),
+			Difficulty:        (*big.Int)(test.Context.Difficulty),
+			GasLimit:          uint64(test.Context.GasLimit),
+			BaseFee:           test.Genesis.BaseFee,
+		}
+		triedb, _, statedb = tests.MakePreState(rawdb.NewMemoryDatabase(), test.Genesis.Alloc, false, rawdb.HashScheme)
+	)
+	defer triedb.Close()
+
+	if prepare != nil {
+		prepare(statedb)
+	}
+	root := statedb.IntermediateRoot(true)
+
+	tracer, err := tracers.DefaultDirectory.New(tracerName, new(tracers.Context), tracerConfig)
+	if err != nil {
+		t.Fatalf("failed to create call tracer: %v", err)
+	}
+	evm := vm.NewEVM(context, txContext, statedb, test.Genesis.Config, vm.Config{Tracer: tracer})
+	msg, err := core.TransactionToMessage(tx, signer, nil)
+	if err != nil {
+		t.Fatalf("failed to prepare transaction for tracing: %v", err)
+	}
+	st := core.NewStateTransition(evm, msg, new(core.GasPool).AddGas(tx.Gas()))
+	if _, err = st.TransitionDb(); err != nil {
+		t.Fatalf("failed to execute transaction: %v", err)
+	}
+	// Retrieve the trace result
+	res, err := tracer.GetResult()
+	if err != nil {
+		t.Fatalf("failed to retrieve trace result: %v", err)
+	}
+	return res, root
+}
+
+func TestPrestateTracerWithProofs(t *testing.T) {
+	test := new(testcase)
+	if blob, err := os.ReadFile(filepath.Join("testdata", "prestate_tracer", "create_existing_contract.json")); err != nil {
+		t.Fatalf("failed to read testcase: %v", err)
+	} else if err := json.Unmarshal(blob, test); err != nil {
+		t.Fatalf("failed to parse testcase: %v", err)
+	}
+	t.Run("first", func(t *testing.T) {
+		testPrestateProofs(t, test, nil)
+	})
+	// The pre state of a transaction which is not the first of its block
+	// includes the changes of the preceding transactions, and must be proven
+	// against the state they produced.
+	t.Run("after", func(t *testing.T) {
+		testPrestateProofs(t, test, func(statedb *state.StateDB) {
+			statedb.AddBalance(common.HexToAddress("0x082d4cdf07f386ffa9258f52a5c49db4ac321ec6"), big.NewInt(1))
+			statedb.SetState(common.HexToAddress("0x52bc44d5378309ee2abf1539bf71de1b7d7be3b5"), common.Hash{1}, common.Hash{1})
+			statedb.Finalise(true)
+		})
+	})
+}
+
+func testPrestateProofs(t *testing.T, test *testcase, prepare func(*state.StateDB)) {
+	res, root := tracePrestateTestcase(t, "prestateTracer", test, json.RawMessage(`{"withProofs": true}`), prepare)
+
+	var trace map[common.Address]struct {
+		Balance *hexutil.Big                `json:"balance"`
+		Storage map[common.Hash]common.Hash `json:"storage"`
+		Proof   struct {
+			Root         common.Hash                     `json:"root"`
+			AccountProof []hexutil.Bytes                 `json:"accountProof"`
+			StorageProof map[common.Hash][]hexutil.Bytes `json:"storageProof"`
+		} `json:"proof"`
+	}
+	if err := json.Unmarshal(res, &trace); err != nil {
+		t.Fatalf("failed to parse trace result: %v", err)
+	}
+	if len(trace) == 0 {
+		t.Fatal("empty trace result")
+	}
+	// Every account and slot of the pre state must be proven at the root of
+	// the state the transaction was executed on.
+	verify := func(root common.Hash, key []byte, proof []hexutil.Bytes) []byte {
+		if root == types.EmptyRootHash {
+			// Nothing is proven absent from an empty trie.
+			if len(proof) != 0 {
+				t.Fatalf("unexpected proof of %x in empty trie", key)
+			}
+			return nil
+		}
+		proofDB := memorydb.New()
+		for _, node := range proof {
+			proofDB.Put(crypto.Keccak256(node), node)
+		}
+		value, err := trie.VerifyProof(root, crypto.Keccak256(key), proofDB)
+		if err != nil {
+			t.Fatalf("invalid proof of %x: %v", key, err)
+		}
+		return value
+	}
+	for addr, acc := range trace {
+		if acc.Proof.Root != root {
+			t.Fatalf("%s proven at %s, want %s", addr, acc.Proof.Root, root)
+		}
+		data := verify(root, addr.Bytes(), acc.Proof.AccountProof)
+		stateAccount := types.StateAccount{Balance: new(big.Int), Root: types.EmptyRootHash}
+		if len(data) > 0 {
+			if err := rlp.DecodeBytes(data, &stateAccount); err != nil {
+				t.Fatalf("failed to decode account %s: %v", addr, err)
+			}
+		}
+		if balance := acc.Balance.ToInt(); balance != nil && balance.Cmp(stateAccount.Balance) != 0 {
+			t.Fatalf("balance of %s: proven %s, want %s", addr, stateAccount.Balance, balance)
+		}
+		for slot, value := range acc.Storage {
+			proof, ok := acc.Proof.StorageProof[slot]
+			if !ok {
+				t.Fatalf("missing proof of slot %s of %s", slot, addr)
+			}
+			key := slot
+			state.NormalizeStateKey(&key)
+			enc := verify(stateAccount.Root, key.Bytes(), proof)
+			var proven []byte
+			if len(enc) > 0 {
+				if err := rlp.DecodeBytes(enc, &proven); err != nil {
+					t.Fatalf("failed to decode slot %s of %s: %v", slot, addr, err)
+				}
+			}
+			if common.BytesToHash(proven) != value {
+				t.Fatalf("slot %s of %s: proven %x, want %s", slot, addr, proven, value)
+			}
+		}
+	}
+}
@@ -103,8 +103,9 @@ func (al accessList) accessList() types.AccessList {
 // AccessListTracer is a tracer that accumulates touched accounts and storage
 // slots into an internal set.
 type AccessListTracer struct {
-	excl map[common.Address]struct{} // Set of account to exclude from the list
-	list accessList                  // Set of accounts and storage slots touched
+	excl   map[common.Address]struct{} // Set of account to exclude from the list
+	list   accessList                  // Set of accounts and storage slots touched
+	called map[common.Address]struct{} // Set of accounts entered by a call
 }
 
 // NewAccessListTracer creates a new tracer that can generate AccessLists.
@@ -127,12 +128,14 @@ func NewAccessListTracer(acl types.AccessList, from, to common.Address, precompi
 		}
 	}
 	return &AccessListTracer{
-		excl: excl,
-		list: list,
+		excl:   excl,
+		list:   list,
+		called: make(map[common.Address]struct{}),
 	}
 }
 
 func (a *AccessListTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	a.called[to] = struct{}{}
 }
 
 // CaptureState captures all opcodes that touch storage or addresses and adds them to the accesslist.
@@ -163,7 +166,8 @@ func (*AccessListTracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64,
 
 func (*AccessListTracer) CaptureEnd(output []byte, gasUsed uint64, err error) {}
 
-func (*AccessListTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+func (a *AccessListTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	a.called[to] = struct{}{}
 }
 
 func (*AccessListTracer) CaptureExit(output []byte, gasUsed uint64, err error) {}
@@ -177,6 +181,13 @@ func (a *AccessListTracer) AccessList() types.AccessList {
 	return a.list.accessList()
 }
 
+// Called returns if [addr] was the target of a call, including precompiles
+// which are excluded from the accesslist.
+func (a *AccessListTracer) Called(addr common.Address) bool {
+	_, ok := a.called[addr]
+	return ok
+}
+
 // Equal returns if the content of two access list traces are equal.
 func (a *AccessListTracer) Equal(other *AccessListTracer) bool {
 	return a.list.equal(other.list)
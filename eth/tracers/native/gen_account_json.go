@@ -19,12 +19,14 @@ func (a account) MarshalJSON() ([]byte, error) {
 		Code    hexutil.Bytes               `json:"code,omitempty"`
 		Nonce   uint64                      `json:"nonce,omitempty"`
 		Storage map[common.Hash]common.Hash `json:"storage,omitempty"`
+		Proof   *accountProof               `json:"proof,omitempty"`
 	}
 	var enc account
 	enc.Balance = (*hexutil.Big)(a.Balance)
 	enc.Code = a.Code
 	enc.Nonce = a.Nonce
 	enc.Storage = a.Storage
+	enc.Proof = a.Proof
 	return json.Marshal(&enc)
 }
 
@@ -35,6 +37,7 @@ func (a *account) UnmarshalJSON(input []byte) error {
 		Code    *hexutil.Bytes              `json:"code,omitempty"`
 		Nonce   *uint64                     `json:"nonce,omitempty"`
 		Storage map[common.Hash]common.Hash `json:"storage,omitempty"`
+		Proof   *accountProof               `json:"proof,omitempty"`
 	}
 	var dec account
 	if err := json.Unmarshal(input, &dec); err != nil {
@@ -52,5 +55,8 @@ func (a *account) UnmarshalJSON(input []byte) error {
 	if dec.Storage != nil {
 		a.Storage = dec.Storage
 	}
+	if dec.Proof != nil {
+		a.Proof = dec.Proof
+	}
 	return nil
 }
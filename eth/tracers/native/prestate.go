@@ -36,6 +36,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
+	corestate "github.com/shubhamdubey02/coreth/core/state"
 	"github.com/shubhamdubey02/coreth/core/vm"
 	"github.com/shubhamdubey02/coreth/eth/tracers"
 )
@@ -53,6 +54,7 @@ type account struct {
 	Code    []byte                      `json:"code,omitempty"`
 	Nonce   uint64                      `json:"nonce,omitempty"`
 	Storage map[common.Hash]common.Hash `json:"storage,omitempty"`
+	Proof   *accountProof               `json:"proof,omitempty"`
 }
 
 func (a *account) exists() bool {
@@ -77,10 +79,14 @@ type prestateTracer struct {
 	reason    error       // Textual reason for the interruption
 	created   map[common.Address]bool
 	deleted   map[common.Address]bool
+
+	witness     *corestate.StateDB // State the pre state is read from, if proven
+	witnessRoot common.Hash
 }
 
 type prestateTracerConfig struct {
-	DiffMode bool `json:"diffMode"` // If true, this tracer will return state modifications
+	DiffMode   bool `json:"diffMode"`   // If true, this tracer will return state modifications
+	WithProofs bool `json:"withProofs"` // If true, this tracer will prove the pre state accounts and slots against the state before the transaction
 }
 
 func newPrestateTracer(ctx *tracers.Context, cfg json.RawMessage) (tracers.Tracer, error) {
@@ -125,6 +131,12 @@ func (t *prestateTracer) CaptureStart(env *vm.EVM, from common.Address, to commo
 	if create && t.config.DiffMode {
 		t.created[to] = true
 	}
+	if t.config.WithProofs {
+		var err error
+		if t.witness, t.witnessRoot, err = t.witnessState(env, from, to); err != nil {
+			t.reason = err
+		}
+	}
 }
 
 // CaptureEnd is called after the call finishes to finalize the tracing.
@@ -194,10 +206,19 @@ func (t *prestateTracer) CaptureTxStart(gasLimit uint64) {
 }
 
 func (t *prestateTracer) CaptureTxEnd(restGas uint64) {
-	if !t.config.DiffMode {
-		return
+	if t.config.DiffMode {
+		t.processDiffState()
+	}
+	if t.config.WithProofs {
+		if err := t.prove(); err != nil {
+			t.reason = err
+		}
 	}
+}
 
+// processDiffState removes the unmodified accounts and slots from the pre
+// state and records the modified ones in the post state.
+func (t *prestateTracer) processDiffState() {
 	for addr, state := range t.pre {
 		// The deleted account's state is pruned from `post` but kept in `pre`
 		if _, ok := t.deleted[addr]; ok {
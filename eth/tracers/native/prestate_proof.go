@@ -0,0 +1,98 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package native
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	corestate "github.com/shubhamdubey02/coreth/core/state"
+	"github.com/shubhamdubey02/coreth/core/vm"
+)
+
+var errProofsUnsupported = errors.New("state does not support proofs")
+
+// accountProof holds the Merkle proofs of an account and of its storage
+// slots, keyed by slot, against the state root the pre state was read from.
+// Slots of an absent account or of an empty storage have empty proofs, their
+// absence following from the account proof.
+type accountProof struct {
+	Root         common.Hash                     `json:"root"`
+	AccountProof []hexutil.Bytes                 `json:"accountProof"`
+	StorageProof map[common.Hash][]hexutil.Bytes `json:"storageProof,omitempty"`
+}
+
+// copyableStateDB is implemented by the state databases which can be copied
+// to prove the state the transaction is executed on top of.
+type copyableStateDB interface {
+	Copy() *corestate.StateDB
+}
+
+// proofList implements ethdb.KeyValueWriter and collects the nodes of a
+// proof, from the root to the leaf.
+type proofList []hexutil.Bytes
+
+func (n *proofList) Put(key []byte, value []byte) error {
+	*n = append(*n, common.CopyBytes(value))
+	return nil
+}
+
+func (n *proofList) Delete(key []byte) error {
+	panic("not supported")
+}
+
+// witnessState returns a copy of the state the pre state is read from, the
+// state before the transaction, hashed so that it can be proven. The changes
+// made to [from] and [to] before the execution started are undone on the
+// copy from the pre state, which accounts for them. The storage an account a
+// contract is created at had before the creation is not restored.
+func (t *prestateTracer) witnessState(env *vm.EVM, from, to common.Address) (*corestate.StateDB, common.Hash, error) {
+	statedb, ok := env.StateDB.(copyableStateDB)
+	if !ok {
+		return nil, common.Hash{}, errProofsUnsupported
+	}
+	witness := statedb.Copy()
+	for _, addr := range []common.Address{from, to} {
+		acc := t.pre[addr]
+		witness.SetBalance(addr, acc.Balance)
+		witness.SetNonce(addr, acc.Nonce)
+	}
+	if t.create {
+		// A contract can only be created at an address without nonce.
+		witness.SetNonce(to, 0)
+	}
+	root := witness.IntermediateRoot(env.ChainConfig().IsEIP158(env.Context.BlockNumber))
+	return witness, root, nil
+}
+
+// prove attaches to every account of the pre state the proofs of the account
+// and of its slots against the state the pre state was read from. Together
+// with its root, the pre state and its proofs form a self-contained witness
+// of the transaction's execution.
+func (t *prestateTracer) prove() error {
+	if t.witness == nil {
+		return errProofsUnsupported
+	}
+	for addr, acc := range t.pre {
+		proof := proofList{}
+		if err := t.witness.Prove(addr, &proof); err != nil {
+			return fmt.Errorf("failed to prove account %s: %w", addr, err)
+		}
+		acc.Proof = &accountProof{Root: t.witnessRoot, AccountProof: proof}
+		if len(acc.Storage) == 0 {
+			continue
+		}
+		acc.Proof.StorageProof = make(map[common.Hash][]hexutil.Bytes, len(acc.Storage))
+		for slot := range acc.Storage {
+			proof := proofList{}
+			if err := t.witness.ProveStorage(addr, slot, &proof); err != nil {
+				return fmt.Errorf("failed to prove slot %s of %s: %w", slot, addr, err)
+			}
+			acc.Proof.StorageProof[slot] = proof
+		}
+	}
+	return nil
+}
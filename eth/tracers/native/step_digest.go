@@ -0,0 +1,38 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package native
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/shubhamdubey02/coreth/core/vm"
+	"github.com/shubhamdubey02/coreth/eth/tracers"
+)
+
+func init() {
+	tracers.DefaultDirectory.Register("stepDigestTracer", newStepDigestTracer, false)
+}
+
+// stepDigestTracer returns the [vm.StepDigest] of a transaction, to compare
+// its execution against other EVM implementations.
+type stepDigestTracer struct {
+	*vm.StepRecorder
+}
+
+func newStepDigestTracer(ctx *tracers.Context, _ json.RawMessage) (tracers.Tracer, error) {
+	return &stepDigestTracer{StepRecorder: vm.NewStepRecorder()}, nil
+}
+
+// GetResult returns the step digest of the traced transaction.
+func (t *stepDigestTracer) GetResult() (json.RawMessage, error) {
+	digests := t.Digests()
+	if len(digests) == 0 {
+		return nil, errors.New("no execution was traced")
+	}
+	return json.Marshal(digests[0])
+}
+
+// Stop has no effect, as recording steps is cheap.
+func (t *stepDigestTracer) Stop(err error) {}
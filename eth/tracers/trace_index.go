@@ -0,0 +1,411 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tracers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/shubhamdubey02/coreth/core"
+	"github.com/shubhamdubey02/coreth/core/rawdb"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/rpc"
+)
+
+const (
+	// TraceIndexConsumer is the name of the accepted block consumer whose
+	// offset checkpoints the trace index.
+	TraceIndexConsumer = "trace-index"
+
+	// traceIndexTracer produces the traces stored by the trace index, in the
+	// format served by trace_filter.
+	traceIndexTracer = "flatCallTracer"
+
+	// maxTraceFilterResults is the maximum number of traces returned by a
+	// single trace_filter call.
+	maxTraceFilterResults = 10_000
+)
+
+var (
+	errTraceIndexDisabled  = errors.New("trace index is not enabled")
+	errInvalidTraceRange   = errors.New("fromBlock is after toBlock")
+	errTooManyTraceResults = fmt.Errorf("query matches more than %d traces, use after and count to paginate", maxTraceFilterResults)
+
+	traceIndexTracerConfig = json.RawMessage(`{"convertParityErrors": true}`)
+)
+
+// traceFrameAddresses holds the addresses of a flat call trace used to
+// filter traces.
+type traceFrameAddresses struct {
+	Action struct {
+		From           *common.Address `json:"from"`
+		To             *common.Address `json:"to"`
+		SelfDestructed *common.Address `json:"address"`
+		RefundAddress  *common.Address `json:"refundAddress"`
+	} `json:"action"`
+	Result *struct {
+		Address *common.Address `json:"address"`
+	} `json:"result"`
+}
+
+// from returns the sender of a call or creation, or the destroyed contract of
+// a self-destruct.
+func (f *traceFrameAddresses) from() *common.Address {
+	if f.Action.From != nil {
+		return f.Action.From
+	}
+	return f.Action.SelfDestructed
+}
+
+// to returns the recipient of a call, the created contract of a creation or
+// the beneficiary of a self-destruct.
+func (f *traceFrameAddresses) to() *common.Address {
+	switch {
+	case f.Action.To != nil:
+		return f.Action.To
+	case f.Action.RefundAddress != nil:
+		return f.Action.RefundAddress
+	case f.Result != nil:
+		return f.Result.Address
+	default:
+		return nil
+	}
+}
+
+// TraceIndex persists the flat call traces of accepted blocks as they are
+// accepted, along with an index of the blocks whose traces involve each
+// address, so that trace_filter serves them without re-executing blocks.
+// Blocks are indexed in order, and the index is checkpointed by acknowledging
+// each indexed block to its accepted block consumer, so that it resumes where
+// it left off after a restart.
+type TraceIndex struct {
+	api      *baseAPI
+	consumer *core.AcceptedConsumer
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	started bool
+	done    chan struct{}
+}
+
+// NewTraceIndex returns a trace index of the blocks delivered by [consumer],
+// tracing them with [backend]. Blocks accepted before the consumer was first
+// registered are not indexed.
+func NewTraceIndex(backend Backend, consumer *core.AcceptedConsumer) *TraceIndex {
+	db := backend.ChainDb()
+	if rawdb.ReadTraceIndexTail(db) == nil {
+		rawdb.WriteTraceIndexTail(db, consumer.Offset()+1)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &TraceIndex{
+		api:      &baseAPI{backend: backend},
+		consumer: consumer,
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+}
+
+// Start indexes accepted blocks in the background until [Stop] is called.
+func (t *TraceIndex) Start() {
+	t.started = true
+	ctx := t.ctx
+	go func() {
+		defer close(t.done)
+		for {
+			block, err := t.consumer.Next(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Error("Trace index stopped", "err", err)
+				}
+				return
+			}
+			if err := t.index(ctx, block); err != nil {
+				if ctx.Err() == nil {
+					log.Error("Trace index stopped", "number", block.NumberU64(), "hash", block.Hash(), "err", err)
+				}
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops indexing, waiting for the block being indexed.
+func (t *TraceIndex) Stop() {
+	t.cancel()
+	if t.started {
+		<-t.done
+	}
+	t.consumer.Close()
+}
+
+// Head returns the number of the last indexed block.
+func (t *TraceIndex) Head() uint64 {
+	return t.consumer.Offset()
+}
+
+// index traces [block] and stores its traces.
+func (t *TraceIndex) index(ctx context.Context, block *types.Block) error {
+	tracer := traceIndexTracer
+	results, err := t.api.traceBlock(ctx, block, &TraceConfig{Tracer: &tracer, TracerConfig: traceIndexTracerConfig})
+	if err != nil {
+		return err
+	}
+	var (
+		frames    []json.RawMessage
+		addresses = make(map[common.Address]struct{})
+	)
+	for _, result := range results {
+		raw, ok := result.Result.(json.RawMessage)
+		if !ok {
+			return fmt.Errorf("unexpected trace of tx %s: %s", result.TxHash, result)
+		}
+		var txFrames []json.RawMessage
+		if err := json.Unmarshal(raw, &txFrames); err != nil {
+			return fmt.Errorf("failed to decode trace of tx %s: %w", result.TxHash, err)
+		}
+		for _, frame := range txFrames {
+			var frameAddresses traceFrameAddresses
+			if err := json.Unmarshal(frame, &frameAddresses); err != nil {
+				return fmt.Errorf("failed to decode trace of tx %s: %w", result.TxHash, err)
+			}
+			for _, addr := range []*common.Address{frameAddresses.from(), frameAddresses.to()} {
+				if addr != nil {
+					addresses[*addr] = struct{}{}
+				}
+			}
+		}
+		frames = append(frames, txFrames...)
+	}
+	encoded, err := json.Marshal(frames)
+	if err != nil {
+		return err
+	}
+
+	number := block.NumberU64()
+	batch := t.api.backend.ChainDb().NewBatch()
+	rawdb.WriteBlockTraces(batch, number, encoded)
+	for addr := range addresses {
+		rawdb.WriteTraceAddressIndex(batch, addr, number)
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	return t.consumer.Ack(number)
+}
+
+// DeleteIndexedTraces deletes the traces of the accepted block [number] and
+// its entries in the address index from [db] through [batch], raising the
+// trace index tail above [number].
+func DeleteIndexedTraces(db ethdb.KeyValueReader, batch ethdb.KeyValueWriter, number uint64) error {
+	if tail := rawdb.ReadTraceIndexTail(db); tail == nil || *tail > number {
+		return nil
+	}
+	rawdb.WriteTraceIndexTail(batch, number+1)
+
+	data := rawdb.ReadBlockTraces(db, number)
+	if data == nil {
+		return nil
+	}
+	var frames []traceFrameAddresses
+	if err := json.Unmarshal(data, &frames); err != nil {
+		return fmt.Errorf("failed to decode traces of block %d: %w", number, err)
+	}
+	for _, frame := range frames {
+		for _, addr := range []*common.Address{frame.from(), frame.to()} {
+			if addr != nil {
+				rawdb.DeleteTraceAddressIndex(batch, *addr, number)
+			}
+		}
+	}
+	rawdb.DeleteBlockTraces(batch, number)
+	return nil
+}
+
+// TraceFilterArgs are the arguments of trace_filter. Traces match if their
+// sender is one of FromAddress and their recipient is one of ToAddress, where
+// an empty list matches every address. After skips the first matching traces
+// and Count bounds the number of traces returned.
+type TraceFilterArgs struct {
+	FromBlock   *rpc.BlockNumber `json:"fromBlock"`
+	ToBlock     *rpc.BlockNumber `json:"toBlock"`
+	FromAddress []common.Address `json:"fromAddress"`
+	ToAddress   []common.Address `json:"toAddress"`
+	After       *uint64          `json:"after"`
+	Count       *uint64          `json:"count"`
+}
+
+// TraceAPI is the collection of tracing APIs served from the trace index.
+type TraceAPI struct {
+	backend Backend
+	index   *TraceIndex
+}
+
+// NewTraceAPI creates a new API serving the traces of [index], which is nil
+// if the trace index is disabled.
+func NewTraceAPI(backend Backend, index *TraceIndex) *TraceAPI {
+	return &TraceAPI{backend: backend, index: index}
+}
+
+// Filter returns the flat call traces of the accepted blocks in the range of
+// [args] matching its address filters, in chain order.
+func (api *TraceAPI) Filter(ctx context.Context, args TraceFilterArgs) ([]json.RawMessage, error) {
+	if api.index == nil {
+		return nil, errTraceIndexDisabled
+	}
+	from, err := api.blockNumber(ctx, args.FromBlock)
+	if err != nil {
+		return nil, err
+	}
+	to, err := api.blockNumber(ctx, args.ToBlock)
+	if err != nil {
+		return nil, err
+	}
+	if from > to {
+		return nil, errInvalidTraceRange
+	}
+	db := api.backend.ChainDb()
+	tail := rawdb.ReadTraceIndexTail(db)
+	if tail == nil {
+		return nil, errTraceIndexDisabled
+	}
+	if from < *tail {
+		return nil, fmt.Errorf("traces of blocks before %d are not indexed", *tail)
+	}
+	// Blocks accepted but not yet indexed are excluded.
+	to = min(to, api.index.Head())
+
+	var (
+		fromAddresses = addressSet(args.FromAddress)
+		toAddresses   = addressSet(args.ToAddress)
+		skip          uint64
+		results       = []json.RawMessage{}
+	)
+	if args.After != nil {
+		skip = *args.After
+	}
+	err = api.forEachCandidate(args, from, to, func(number uint64) (bool, error) {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		data := rawdb.ReadBlockTraces(db, number)
+		if data == nil {
+			return true, nil
+		}
+		var frames []json.RawMessage
+		if err := json.Unmarshal(data, &frames); err != nil {
+			return false, fmt.Errorf("failed to decode traces of block %d: %w", number, err)
+		}
+		for _, frame := range frames {
+			var addresses traceFrameAddresses
+			if err := json.Unmarshal(frame, &addresses); err != nil {
+				return false, fmt.Errorf("failed to decode traces of block %d: %w", number, err)
+			}
+			if !matchAddress(fromAddresses, addresses.from()) || !matchAddress(toAddresses, addresses.to()) {
+				continue
+			}
+			if skip > 0 {
+				skip--
+				continue
+			}
+			if args.Count != nil && uint64(len(results)) >= *args.Count {
+				return false, nil
+			}
+			if len(results) >= maxTraceFilterResults {
+				return false, errTooManyTraceResults
+			}
+			results = append(results, frame)
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// blockNumber resolves [number] to the number of an accepted block, defaulting
+// to the latest one.
+func (api *TraceAPI) blockNumber(ctx context.Context, number *rpc.BlockNumber) (uint64, error) {
+	n := rpc.LatestBlockNumber
+	if number != nil {
+		n = *number
+	}
+	header, err := api.backend.HeaderByNumber(ctx, n)
+	if err != nil {
+		return 0, err
+	}
+	if header == nil {
+		return 0, fmt.Errorf("block %d not found", n)
+	}
+	return header.Number.Uint64(), nil
+}
+
+// forEachCandidate calls [fn] with the number of each block in [from, to]
+// whose traces may match [args], in ascending order, until [fn] returns false
+// or an error. The address index is used when addresses are filtered.
+func (api *TraceAPI) forEachCandidate(args TraceFilterArgs, from, to uint64, fn func(number uint64) (bool, error)) error {
+	// Matching traces involve one of the addresses of both lists, so either
+	// list bounds the candidate blocks.
+	addresses := args.FromAddress
+	if len(addresses) == 0 || (len(args.ToAddress) != 0 && len(args.ToAddress) < len(addresses)) {
+		addresses = args.ToAddress
+	}
+	if len(addresses) == 0 {
+		for number := from; number <= to; number++ {
+			if ok, err := fn(number); !ok || err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var numbers []uint64
+	for _, addr := range addresses {
+		err := rawdb.IterateTraceAddressIndex(api.backend.ChainDb(), addr, from, to, func(number uint64) bool {
+			numbers = append(numbers, number)
+			return true
+		})
+		if err != nil {
+			return err
+		}
+	}
+	slices.Sort(numbers)
+	for _, number := range slices.Compact(numbers) {
+		if ok, err := fn(number); !ok || err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func addressSet(addresses []common.Address) map[common.Address]struct{} {
+	if len(addresses) == 0 {
+		return nil
+	}
+	set := make(map[common.Address]struct{}, len(addresses))
+	for _, addr := range addresses {
+		set[addr] = struct{}{}
+	}
+	return set
+}
+
+// matchAddress returns whether [addr] is in [set], where a nil set matches
+// every address.
+func matchAddress(set map[common.Address]struct{}, addr *common.Address) bool {
+	if set == nil {
+		return true
+	}
+	if addr == nil {
+		return false
+	}
+	_, ok := set[*addr]
+	return ok
+}
@@ -0,0 +1,35 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ethapi
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/shubhamdubey02/coreth/core/rawdb"
+)
+
+// addAcceptanceFields attaches the acceptance metadata of the block [hash] at
+// [number] to [fields], a block or receipt response, if it is enabled:
+//   - acceptedHeight: the height of the last accepted block, which the block
+//     is final at or below
+//   - acceptedAt: the unix time at which the block was accepted, in
+//     milliseconds, if it was recorded
+//   - pChainHeight: the P-Chain height of the proposervm block wrapping the
+//     block, if it was recorded
+func addAcceptanceFields(b Backend, fields map[string]interface{}, hash common.Hash, number uint64) {
+	if !b.RPCAcceptanceMetadata() {
+		return
+	}
+	if lastAccepted := b.LastAcceptedBlock(); lastAccepted != nil {
+		fields["acceptedHeight"] = hexutil.Uint64(lastAccepted.NumberU64())
+	}
+	acceptance := rawdb.ReadBlockAcceptance(b.ChainDb(), hash, number)
+	if acceptance == nil {
+		return
+	}
+	fields["acceptedAt"] = hexutil.Uint64(acceptance.Time)
+	if acceptance.PChainHeight != 0 {
+		fields["pChainHeight"] = hexutil.Uint64(acceptance.PChainHeight)
+	}
+}
@@ -0,0 +1,182 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/shubhamdubey02/coreth/rpc"
+)
+
+// maxCallManyCalls is the maximum number of calls accepted by CallMany.
+const maxCallManyCalls = 256
+
+var (
+	errTooManyCalls       = errors.New("too many calls")
+	errDuplicateCallID    = errors.New("duplicate call id")
+	errUnknownDependency  = errors.New("unknown call dependency")
+	errDependencyCycle    = errors.New("call dependencies contain a cycle")
+	errDependencyFailed   = errors.New("dependency failed")
+	errCallManyGasCapUsed = errors.New("gas cap exhausted by previous calls")
+)
+
+// DependentCall is a call executed by CallMany once every call listed in
+// DependsOn has been executed.
+type DependentCall struct {
+	ID        string          `json:"id"`
+	DependsOn []string        `json:"dependsOn"`
+	Args      TransactionArgs `json:"call"`
+}
+
+// CallManyResult is the result of a single call executed by CallMany.
+type CallManyResult struct {
+	ID         string         `json:"id"`
+	UsedGas    hexutil.Uint64 `json:"gasUsed"`
+	ReturnData hexutil.Bytes  `json:"returnData"`
+	Err        string         `json:"error,omitempty"`
+}
+
+// CallMany executes [calls] in dependency order against a single copy of the
+// state at [blockNrOrHash], so that each call observes the state changes made
+// by the calls executed before it. A call is skipped if one of its
+// dependencies failed. Results are returned in the order of [calls].
+//
+// The EVM timeout and gas cap apply to the whole batch rather than to each
+// call, and [gasOverrides] to every call.
+func (s *BlockChainAPI) CallMany(ctx context.Context, calls []DependentCall, blockNrOrHash *rpc.BlockNumberOrHash, overrides *StateOverride, blockOverrides *BlockOverrides, gasOverrides *GasOverrides) ([]CallManyResult, error) {
+	if len(calls) > maxCallManyCalls {
+		return nil, fmt.Errorf("%w: %d > %d", errTooManyCalls, len(calls), maxCallManyCalls)
+	}
+	order, err := sortDependentCalls(calls)
+	if err != nil {
+		return nil, err
+	}
+	vmGasOverrides, err := gasOverrides.toVM()
+	if err != nil {
+		return nil, err
+	}
+	if blockNrOrHash == nil {
+		latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+		blockNrOrHash = &latest
+	}
+	state, header, release, err := callStateAndHeader(ctx, s.b, *blockNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	defer release()
+	if err := overrides.Apply(state); err != nil {
+		return nil, err
+	}
+
+	var (
+		timeout  = s.b.RPCEVMTimeout()
+		deadline = time.Now().Add(timeout)
+		gasCap   = s.b.RPCGasCap()
+		results  = make([]CallManyResult, len(calls))
+		failed   = make(map[string]bool, len(calls))
+	)
+	for _, i := range order {
+		call := calls[i]
+		results[i].ID = call.ID
+		if dep := failedDependency(call, failed); dep != "" {
+			failed[call.ID] = true
+			results[i].Err = fmt.Sprintf("%s: %s", errDependencyFailed, dep)
+			continue
+		}
+
+		var remaining time.Duration
+		if timeout > 0 {
+			if remaining = time.Until(deadline); remaining <= 0 {
+				return nil, fmt.Errorf("execution aborted (timeout = %v)", timeout)
+			}
+		}
+		result, err := doCall(ctx, s.b, call.Args, state, header, nil, blockOverrides, vmGasOverrides, remaining, gasCap)
+		if err != nil {
+			failed[call.ID] = true
+			results[i].Err = err.Error()
+			continue
+		}
+		results[i].UsedGas = hexutil.Uint64(result.UsedGas)
+		results[i].ReturnData = result.Return()
+		if len(result.Revert()) > 0 {
+			failed[call.ID] = true
+			results[i].ReturnData = result.Revert()
+			results[i].Err = newRevertError(result.Revert()).Error()
+		} else if result.Err != nil {
+			failed[call.ID] = true
+			results[i].Err = result.Err.Error()
+		}
+
+		if gasCap != 0 {
+			if result.UsedGas >= gasCap {
+				return nil, fmt.Errorf("%w (cap: %d)", errCallManyGasCapUsed, s.b.RPCGasCap())
+			}
+			gasCap -= result.UsedGas
+		}
+	}
+	return results, nil
+}
+
+// sortDependentCalls returns the indices of [calls] in an order in which every
+// call comes after its dependencies. Calls without an ordering constraint
+// between them keep their relative order.
+func sortDependentCalls(calls []DependentCall) ([]int, error) {
+	index := make(map[string]int, len(calls))
+	for i, call := range calls {
+		if _, ok := index[call.ID]; ok {
+			return nil, fmt.Errorf("%w: %q", errDuplicateCallID, call.ID)
+		}
+		index[call.ID] = i
+	}
+
+	var (
+		pending    = make([]int, len(calls)) // number of unexecuted dependencies
+		dependents = make([][]int, len(calls))
+	)
+	for i, call := range calls {
+		for _, dep := range call.DependsOn {
+			j, ok := index[dep]
+			if !ok {
+				return nil, fmt.Errorf("%w: %q depends on %q", errUnknownDependency, call.ID, dep)
+			}
+			pending[i]++
+			dependents[j] = append(dependents[j], i)
+		}
+	}
+
+	order := make([]int, 0, len(calls))
+	for len(order) < len(calls) {
+		// Pick the first ready call to keep the order deterministic.
+		next := -1
+		for i := range calls {
+			if pending[i] == 0 {
+				next = i
+				break
+			}
+		}
+		if next == -1 {
+			return nil, errDependencyCycle
+		}
+		pending[next] = -1
+		order = append(order, next)
+		for _, j := range dependents[next] {
+			pending[j]--
+		}
+	}
+	return order, nil
+}
+
+// failedDependency returns the first dependency of [call] that failed.
+func failedDependency(call DependentCall, failed map[string]bool) string {
+	for _, dep := range call.DependsOn {
+		if failed[dep] {
+			return dep
+		}
+	}
+	return ""
+}
@@ -0,0 +1,86 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ethapi
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/shubhamdubey02/coreth/consensus/dummy"
+	"github.com/shubhamdubey02/coreth/core"
+	"github.com/shubhamdubey02/coreth/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallMany(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	var (
+		accounts = newAccounts(2)
+		storage  = common.Address{0xaa}
+		// Stores the first word of calldata in slot 0 if calldata is
+		// provided, otherwise returns the value of slot 0.
+		storageCode = common.FromHex("0x3660" + "0f" + "5760005460005260206000f3" + "5b60003560005500")
+		genesis     = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: core.GenesisAlloc{
+				accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+				storage:          {Balance: common.Big0, Code: storageCode},
+			},
+		}
+	)
+	api := NewBlockChainAPI(newTestBackend(t, 1, genesis, dummy.NewCoinbaseFaker(), func(int, *core.BlockGen) {}))
+
+	value := hexutil.Bytes(common.BigToHash(big.NewInt(42)).Bytes())
+	calls := []DependentCall{
+		{
+			ID:        "read",
+			DependsOn: []string{"write"},
+			Args:      TransactionArgs{From: &accounts[0].addr, To: &storage},
+		},
+		{
+			ID:   "write",
+			Args: TransactionArgs{From: &accounts[0].addr, To: &storage, Input: &value},
+		},
+		{
+			ID:   "overdraw",
+			Args: TransactionArgs{From: &accounts[1].addr, To: &accounts[0].addr, Value: (*hexutil.Big)(big.NewInt(params.Ether))},
+		},
+		{
+			ID:        "transfer",
+			DependsOn: []string{"overdraw", "read"},
+			Args:      TransactionArgs{From: &accounts[1].addr, To: &accounts[0].addr},
+		},
+	}
+	results, err := api.CallMany(context.Background(), calls, nil, nil, nil, nil)
+	require.NoError(err)
+	require.Len(results, len(calls))
+
+	// The read observes the write it depends on, even though it is listed first.
+	require.Equal("read", results[0].ID)
+	require.Empty(results[0].Err)
+	require.Equal(value, results[0].ReturnData)
+	require.Equal("write", results[1].ID)
+	require.Empty(results[1].Err)
+
+	// Calls depending on a failed call are skipped.
+	require.NotEmpty(results[2].Err)
+	require.Contains(results[3].Err, errDependencyFailed.Error())
+
+	_, err = api.CallMany(context.Background(), []DependentCall{
+		{ID: "a", DependsOn: []string{"b"}},
+		{ID: "b", DependsOn: []string{"a"}},
+	}, nil, nil, nil, nil)
+	require.ErrorIs(err, errDependencyCycle)
+
+	_, err = api.CallMany(context.Background(), []DependentCall{{ID: "a", DependsOn: []string{"b"}}}, nil, nil, nil, nil)
+	require.ErrorIs(err, errUnknownDependency)
+
+	_, err = api.CallMany(context.Background(), []DependentCall{{ID: "a"}, {ID: "a"}}, nil, nil, nil, nil)
+	require.ErrorIs(err, errDuplicateCallID)
+}
@@ -6,11 +6,15 @@ package ethapi
 import (
 	"context"
 	"fmt"
+	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/shubhamdubey02/coreth/consensus/dummy"
 	"github.com/shubhamdubey02/coreth/core"
+	"github.com/shubhamdubey02/coreth/eth/gasprice"
 	"github.com/shubhamdubey02/coreth/params"
 	"github.com/shubhamdubey02/coreth/rpc"
 )
@@ -29,7 +33,7 @@ type DetailedExecutionResult struct {
 
 // CallDetailed performs the same call as Call, but returns the full context
 func (s *BlockChainAPI) CallDetailed(ctx context.Context, args TransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *StateOverride) (*DetailedExecutionResult, error) {
-	result, err := DoCall(ctx, s.b, args, blockNrOrHash, overrides, nil, s.b.RPCEVMTimeout(), s.b.RPCGasCap())
+	result, err := DoCall(ctx, s.b, args, blockNrOrHash, overrides, nil, nil, s.b.RPCEVMTimeout(), s.b.RPCGasCap())
 	if err != nil {
 		return nil, err
 	}
@@ -92,3 +96,96 @@ func (s *BlockChainAPI) GetBadBlocks(ctx context.Context) ([]*BadBlockArgs, erro
 	}
 	return results, nil
 }
+
+// AvaxAPI provides an API to access Avalanche specific fee information.
+type AvaxAPI struct {
+	b Backend
+}
+
+// NewAvaxAPI creates a new Avalanche specific API.
+func NewAvaxAPI(b Backend) *AvaxAPI {
+	return &AvaxAPI{b}
+}
+
+// EstimateRequiredTip returns the minimum effective tip per unit of gas that
+// the transactions of a block built now must pay for the block to cover its
+// block gas cost. [gas] is the gas the block is expected to use and defaults
+// to the gas used by the last accepted block.
+// Returns 0 prior to Apricot Phase 4, when blocks have no block gas cost.
+func (s *AvaxAPI) EstimateRequiredTip(ctx context.Context, gas *hexutil.Uint64) (*hexutil.Big, error) {
+	header, err := s.b.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	var gasUsed uint64
+	if gas != nil {
+		gasUsed = uint64(*gas)
+	} else {
+		gasUsed = header.GasUsed
+		if header.ExtDataGasUsed != nil {
+			gasUsed += header.ExtDataGasUsed.Uint64()
+		}
+		if gasUsed == 0 {
+			gasUsed = params.TxGas
+		}
+	}
+	tip, err := dummy.EstimateRequiredTip(s.b.ChainConfig(), header, uint64(time.Now().Unix()), gasUsed)
+	if err != nil {
+		return nil, err
+	}
+	if tip == nil {
+		tip = new(big.Int)
+	}
+	return (*hexutil.Big)(tip), nil
+}
+
+// feeSuggestion is a max fee and a tip per unit of gas.
+type feeSuggestion struct {
+	MaxFeePerGas         *hexutil.Big `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big `json:"maxPriorityFeePerGas"`
+}
+
+// feeEstimate is the fee suggestions for the inclusion of a transaction
+// within [TargetBlocks] blocks.
+type feeEstimate struct {
+	TargetBlocks hexutil.Uint64 `json:"targetBlocks"`
+	Low          feeSuggestion  `json:"low"`
+	Median       feeSuggestion  `json:"median"`
+	High         feeSuggestion  `json:"high"`
+}
+
+type feeEstimateResult struct {
+	BaseFee   *hexutil.Big  `json:"baseFeePerGas"`
+	Estimates []feeEstimate `json:"estimates"`
+}
+
+// FeeEstimate returns low, median and high max fee and tip suggestions for
+// a transaction to be included within 1, 3 and 10 blocks, based on the
+// inclusion data of recent blocks. The low, median and high suggestions would
+// have been sufficient in 25%, 50% and 90% of the recent windows of blocks.
+func (s *AvaxAPI) FeeEstimate(ctx context.Context) (*feeEstimateResult, error) {
+	baseFee, estimates, err := s.b.FeeEstimate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := &feeEstimateResult{
+		BaseFee:   (*hexutil.Big)(baseFee),
+		Estimates: make([]feeEstimate, len(estimates)),
+	}
+	for i, estimate := range estimates {
+		result.Estimates[i] = feeEstimate{
+			TargetBlocks: hexutil.Uint64(estimate.Target),
+			Low:          newFeeSuggestion(estimate.Low),
+			Median:       newFeeSuggestion(estimate.Median),
+			High:         newFeeSuggestion(estimate.High),
+		}
+	}
+	return result, nil
+}
+
+func newFeeSuggestion(suggestion gasprice.FeeSuggestion) feeSuggestion {
+	return feeSuggestion{
+		MaxFeePerGas:         (*hexutil.Big)(suggestion.MaxFee),
+		MaxPriorityFeePerGas: (*hexutil.Big)(suggestion.Tip),
+	}
+}
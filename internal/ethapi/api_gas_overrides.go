@@ -0,0 +1,45 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ethapi
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/shubhamdubey02/coreth/core/vm"
+)
+
+// GasOverrides replaces the gas costs of opcodes, keyed by their names such
+// as "SLOAD", and of native precompiled contracts, keyed by their addresses,
+// for the duration of a simulation.
+type GasOverrides struct {
+	Opcodes     map[string]hexutil.Uint64         `json:"opcodes"`
+	Precompiles map[common.Address]hexutil.Uint64 `json:"precompiles"`
+}
+
+// toVM returns the overrides applied by the EVM, or nil if [o] is nil.
+func (o *GasOverrides) toVM() (*vm.GasOverrides, error) {
+	if o == nil {
+		return nil, nil
+	}
+	overrides := &vm.GasOverrides{
+		Opcodes:     make(map[vm.OpCode]uint64, len(o.Opcodes)),
+		Precompiles: make(map[common.Address]uint64, len(o.Precompiles)),
+	}
+	for name, gas := range o.Opcodes {
+		op := vm.StringToOp(name)
+		if op.String() != name {
+			return nil, fmt.Errorf("unknown opcode %q", name)
+		}
+		overrides.Opcodes[op] = uint64(gas)
+	}
+	for addr, gas := range o.Precompiles {
+		overrides.Precompiles[addr] = uint64(gas)
+	}
+	if err := overrides.Validate(); err != nil {
+		return nil, err
+	}
+	return overrides, nil
+}
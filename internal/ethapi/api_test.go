@@ -36,6 +36,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -44,19 +45,25 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/holiman/uint256"
 	"github.com/shubhamdubey02/coreth/accounts"
+	"github.com/shubhamdubey02/coreth/accounts/abi"
 	"github.com/shubhamdubey02/coreth/consensus"
 	"github.com/shubhamdubey02/coreth/consensus/dummy"
 	"github.com/shubhamdubey02/coreth/core"
 	"github.com/shubhamdubey02/coreth/core/bloombits"
 	"github.com/shubhamdubey02/coreth/core/rawdb"
 	"github.com/shubhamdubey02/coreth/core/state"
+	"github.com/shubhamdubey02/coreth/core/txpool"
 	"github.com/shubhamdubey02/coreth/core/types"
 	"github.com/shubhamdubey02/coreth/core/vm"
+	"github.com/shubhamdubey02/coreth/eth/gasprice"
 	"github.com/shubhamdubey02/coreth/internal/blocktest"
 	"github.com/shubhamdubey02/coreth/params"
+	"github.com/shubhamdubey02/coreth/precompile/contracts/warp"
 	"github.com/shubhamdubey02/coreth/rpc"
+	"github.com/shubhamdubey02/coreth/utils"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/exp/slices"
 )
@@ -414,6 +421,10 @@ func allBlobTxs(addr common.Address, config *params.ChainConfig) []txData {
 type testBackend struct {
 	db    ethdb.Database
 	chain *core.BlockChain
+
+	estimateGasTrace   bool
+	errorABI           *abi.ABI
+	acceptanceMetadata bool
 }
 
 func newTestBackend(t *testing.T, n int, gspec *core.Genesis, engine consensus.Engine, generator func(i int, b *core.BlockGen)) *testBackend {
@@ -457,6 +468,9 @@ func (b testBackend) ExtRPCEnabled() bool                        { return false
 func (b testBackend) RPCGasCap() uint64                          { return 10000000 }
 func (b testBackend) RPCEVMTimeout() time.Duration               { return time.Second }
 func (b testBackend) RPCTxFeeCap() float64                       { return 0 }
+func (b testBackend) RPCEstimateGasTrace() bool                  { return b.estimateGasTrace }
+func (b testBackend) RPCErrorABI() *abi.ABI                      { return b.errorABI }
+func (b testBackend) RPCAcceptanceMetadata() bool                { return b.acceptanceMetadata }
 func (b testBackend) UnprotectedAllowed(*types.Transaction) bool { return false }
 func (b testBackend) SetHead(number uint64)                      {}
 func (b testBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
@@ -501,21 +515,21 @@ func (b testBackend) BlockByNumberOrHash(ctx context.Context, blockNrOrHash rpc.
 func (b testBackend) GetBody(ctx context.Context, hash common.Hash, number rpc.BlockNumber) (*types.Body, error) {
 	return b.chain.GetBlock(hash, uint64(number.Int64())).Body(), nil
 }
-func (b testBackend) StateAndHeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
+func (b testBackend) StateAndHeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*state.StateDB, *types.Header, func(), error) {
 	if number == rpc.PendingBlockNumber {
 		panic("pending state not implemented")
 	}
 	header, err := b.HeaderByNumber(ctx, number)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	if header == nil {
-		return nil, nil, errors.New("header not found")
+		return nil, nil, nil, errors.New("header not found")
 	}
 	stateDb, err := b.chain.StateAt(header.Root)
-	return stateDb, header, err
+	return stateDb, header, func() {}, err
 }
-func (b testBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error) {
+func (b testBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, func(), error) {
 	if blockNr, ok := blockNrOrHash.Number(); ok {
 		return b.StateAndHeaderByNumber(ctx, blockNr)
 	}
@@ -544,6 +558,9 @@ func (b testBackend) GetEVM(ctx context.Context, msg *core.Message, state *state
 func (b testBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription {
 	panic("implement me")
 }
+func (b testBackend) SubscribeChainAcceptedEvent(ch chan<- core.ChainEvent) event.Subscription {
+	return b.chain.SubscribeChainAcceptedEvent(ch)
+}
 func (b testBackend) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
 	panic("implement me")
 }
@@ -569,6 +586,10 @@ func (b testBackend) TxPoolContent() (map[common.Address][]*types.Transaction, m
 func (b testBackend) TxPoolContentFrom(addr common.Address) ([]*types.Transaction, []*types.Transaction) {
 	panic("implement me")
 }
+func (b testBackend) TxPoolSnapshot() []*txpool.SnapshotTx { panic("implement me") }
+func (b testBackend) RestoreTxPoolSnapshot(snapshot []*txpool.SnapshotTx) []error {
+	panic("implement me")
+}
 func (b testBackend) SubscribeNewTxsEvent(events chan<- core.NewTxsEvent) event.Subscription {
 	panic("implement me")
 }
@@ -594,10 +615,13 @@ func (b testBackend) BadBlocks() ([]*types.Block, []*core.BadBlockReason) { retu
 func (b testBackend) EstimateBaseFee(ctx context.Context) (*big.Int, error) {
 	panic("implement me")
 }
-func (b testBackend) LastAcceptedBlock() *types.Block { panic("implement me") }
+func (b testBackend) LastAcceptedBlock() *types.Block { return b.chain.LastAcceptedBlock() }
 func (b testBackend) SuggestPrice(ctx context.Context) (*big.Int, error) {
 	panic("implement me")
 }
+func (b testBackend) FeeEstimate(ctx context.Context) (*big.Int, []gasprice.FeeEstimate, error) {
+	panic("implement me")
+}
 
 func TestEstimateGas(t *testing.T) {
 	t.Parallel()
@@ -743,6 +767,122 @@ func TestEstimateGas(t *testing.T) {
 	}
 }
 
+func TestEstimateGasRevertTrace(t *testing.T) {
+	t.Parallel()
+	var (
+		accounts = newAccounts(1)
+		genesis  = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: core.GenesisAlloc{
+				accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+			},
+		}
+		caller  = common.HexToAddress("0xa000000000000000000000000000000000000000")
+		callee  = common.HexToAddress("0xb000000000000000000000000000000000000000")
+		empty   = common.HexToAddress("0xc000000000000000000000000000000000000000")
+		errABI  = `[{"type":"error","name":"Unauthorized","inputs":[{"name":"account","type":"address"}]}]`
+		errorID = crypto.Keccak256([]byte("Unauthorized(address)"))[:4]
+	)
+	parsedABI, err := abi.JSON(strings.NewReader(errABI))
+	require.NoError(t, err)
+	backend := newTestBackend(t, 1, genesis, dummy.NewCoinbaseFaker(), func(i int, b *core.BlockGen) {})
+	backend.estimateGasTrace = true
+	backend.errorABI = &parsedABI
+	api := NewBlockChainAPI(backend)
+
+	// The callee reverts with Unauthorized(msg.sender).
+	calleeCode := append(hexutil.Bytes{byte(vm.PUSH4)}, errorID...)
+	calleeCode = append(calleeCode,
+		byte(vm.PUSH1), 0xe0, byte(vm.SHL), byte(vm.PUSH1), 0, byte(vm.MSTORE),
+		byte(vm.CALLER), byte(vm.PUSH1), 4, byte(vm.MSTORE),
+		byte(vm.PUSH1), 0x24, byte(vm.PUSH1), 0, byte(vm.REVERT),
+	)
+	// The caller successfully calls an empty account, then calls the callee
+	// and bubbles up its revert.
+	var callerCode hexutil.Bytes
+	for _, to := range []common.Address{empty, callee} {
+		callerCode = append(callerCode, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH20))
+		callerCode = append(callerCode, to[:]...)
+		callerCode = append(callerCode, byte(vm.GAS), byte(vm.CALL), byte(vm.POP))
+	}
+	callerCode = append(callerCode,
+		byte(vm.RETURNDATASIZE), byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.RETURNDATACOPY),
+		byte(vm.RETURNDATASIZE), byte(vm.PUSH1), 0, byte(vm.REVERT),
+	)
+
+	latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	_, err = api.EstimateGas(context.Background(), TransactionArgs{
+		From:  &accounts[0].addr,
+		To:    &caller,
+		Input: (*hexutil.Bytes)(&[]byte{1, 2, 3, 4}),
+	}, &latest, &StateOverride{
+		caller: OverrideAccount{Code: &callerCode},
+		callee: OverrideAccount{Code: &calleeCode},
+	})
+	var revertErr *revertError
+	require.ErrorAs(t, err, &revertErr)
+
+	reason := fmt.Sprintf("Unauthorized(%s)", caller)
+	want := &revertFrame{
+		Type:     "CALL",
+		From:     accounts[0].addr,
+		To:       caller,
+		Selector: []byte{1, 2, 3, 4},
+		Error:    "execution reverted",
+		Reason:   reason,
+		Calls: []*revertFrame{{
+			Type:   "CALL",
+			From:   caller,
+			To:     callee,
+			Error:  "execution reverted",
+			Reason: reason,
+		}},
+	}
+	require.Equal(t, want, revertErr.trace)
+}
+
+func TestCreateAccessListPrecompiles(t *testing.T) {
+	t.Parallel()
+	var (
+		accounts = newAccounts(1)
+		config   = *params.TestChainConfig
+		contract = common.HexToAddress("0xa000000000000000000000000000000000000000")
+		other    = common.HexToAddress("0xb000000000000000000000000000000000000000")
+		sha256   = common.BytesToAddress([]byte{0x02})
+	)
+	config.PrecompileUpgrades = []params.PrecompileUpgrade{{Config: warp.NewDefaultConfig(utils.NewUint64(0))}}
+
+	// The contract calls the sha256 precompile, the warp precompile and an
+	// empty account.
+	var code []byte
+	for _, to := range []common.Address{sha256, warp.ContractAddress, other} {
+		code = append(code, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH20))
+		code = append(code, to[:]...)
+		code = append(code, byte(vm.GAS), byte(vm.CALL), byte(vm.POP))
+	}
+	genesis := &core.Genesis{
+		Config: &config,
+		Alloc: core.GenesisAlloc{
+			accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+			contract:         {Code: code, Balance: big.NewInt(0)},
+		},
+	}
+	api := NewBlockChainAPI(newTestBackend(t, 1, genesis, dummy.NewCoinbaseFaker(), func(i int, b *core.BlockGen) {}))
+
+	latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	result, err := api.CreateAccessList(context.Background(), TransactionArgs{
+		From:  &accounts[0].addr,
+		To:    &contract,
+		Nonce: new(hexutil.Uint64),
+		Gas:   (*hexutil.Uint64)(utils.NewUint64(1_000_000)),
+	}, &latest)
+	require.NoError(t, err)
+	require.Empty(t, result.Error)
+	require.Equal(t, types.AccessList{{Address: other, StorageKeys: []common.Hash{}}}, *result.Accesslist)
+	require.Equal(t, []common.Address{sha256, warp.ContractAddress}, result.Precompiles)
+	require.Equal(t, []common.Address{warp.ContractAddress}, result.Predicates)
+}
+
 func TestCall(t *testing.T) {
 	t.Parallel()
 	// Initialize test accounts
@@ -888,7 +1028,7 @@ func TestCall(t *testing.T) {
 		},
 	}
 	for i, tc := range testSuite {
-		result, err := api.Call(context.Background(), tc.call, &rpc.BlockNumberOrHash{BlockNumber: &tc.blockNumber}, &tc.overrides, &tc.blockOverrides)
+		result, err := api.Call(context.Background(), tc.call, &rpc.BlockNumberOrHash{BlockNumber: &tc.blockNumber}, &tc.overrides, &tc.blockOverrides, nil)
 		if tc.expectErr != nil {
 			if err == nil {
 				t.Errorf("test %d: want error %v, have nothing", i, tc.expectErr)
@@ -1660,6 +1800,111 @@ func TestRPCGetBlockReceipts(t *testing.T) {
 	}
 }
 
+func TestRPCGetRaw(t *testing.T) {
+	t.Parallel()
+
+	var (
+		genBlocks         = 6
+		backend, txHashes = setupReceiptBackend(t, genBlocks)
+		api               = NewDebugAPI(backend)
+		ctx               = context.Background()
+	)
+	for i := 0; i <= genBlocks; i++ {
+		block, err := backend.BlockByNumber(ctx, rpc.BlockNumber(i))
+		require.NoError(t, err)
+
+		for _, blockNrOrHash := range []rpc.BlockNumberOrHash{
+			rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(i)),
+			rpc.BlockNumberOrHashWithHash(block.Hash(), false),
+		} {
+			rawHeader, err := api.GetRawHeader(ctx, blockNrOrHash)
+			require.NoError(t, err)
+			header := new(types.Header)
+			require.NoError(t, rlp.DecodeBytes(rawHeader, header))
+			require.Equal(t, block.Hash(), header.Hash())
+
+			rawBlock, err := api.GetRawBlock(ctx, blockNrOrHash)
+			require.NoError(t, err)
+			decoded := new(types.Block)
+			require.NoError(t, rlp.DecodeBytes(rawBlock, decoded))
+			require.Equal(t, block.Hash(), decoded.Hash())
+			require.Equal(t, block.Transactions().Len(), decoded.Transactions().Len())
+
+			rawReceipts, err := api.GetRawReceipts(ctx, blockNrOrHash)
+			require.NoError(t, err)
+			require.Len(t, rawReceipts, block.Transactions().Len())
+			receipts, err := backend.GetReceipts(ctx, block.Hash())
+			require.NoError(t, err)
+			for j, receipt := range receipts {
+				expected, err := receipt.MarshalBinary()
+				require.NoError(t, err)
+				require.Equal(t, hexutil.Bytes(expected), rawReceipts[j])
+			}
+		}
+	}
+
+	for _, txHash := range txHashes {
+		tx, _, _, _, err := backend.GetTransaction(ctx, txHash)
+		require.NoError(t, err)
+		expected, err := tx.MarshalBinary()
+		require.NoError(t, err)
+		rawTx, err := api.GetRawTransaction(ctx, txHash)
+		require.NoError(t, err)
+		require.Equal(t, hexutil.Bytes(expected), rawTx)
+	}
+
+	// Unknown blocks are reported instead of crashing the handler.
+	for _, blockNrOrHash := range []rpc.BlockNumberOrHash{
+		rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(genBlocks + 1)),
+		rpc.BlockNumberOrHashWithHash(common.HexToHash("deadbeef"), false),
+	} {
+		_, err := api.GetRawHeader(ctx, blockNrOrHash)
+		require.ErrorContains(t, err, "not found")
+		_, err = api.GetRawBlock(ctx, blockNrOrHash)
+		require.ErrorContains(t, err, "not found")
+		_, err = api.GetRawReceipts(ctx, blockNrOrHash)
+		require.ErrorContains(t, err, "not found")
+	}
+}
+
+func TestRPCAcceptanceMetadata(t *testing.T) {
+	t.Parallel()
+
+	var (
+		backend, txHashes = setupReceiptBackend(t, 6)
+		txAPI             = NewTransactionAPI(backend, new(AddrLocker))
+		chainAPI          = NewBlockChainAPI(backend)
+		ctx               = context.Background()
+	)
+	_, blockHash, blockNumber, _, err := backend.GetTransaction(ctx, txHashes[0])
+	require.NoError(t, err)
+	rawdb.WriteBlockAcceptance(backend.db, blockHash, blockNumber, &rawdb.BlockAcceptance{Time: 1000, PChainHeight: 7})
+
+	// The metadata is only attached if enabled.
+	receipt, err := txAPI.GetTransactionReceipt(ctx, txHashes[0])
+	require.NoError(t, err)
+	require.NotContains(t, receipt, "acceptedAt")
+
+	backend.acceptanceMetadata = true
+	lastAccepted := hexutil.Uint64(backend.chain.LastAcceptedBlock().NumberU64())
+	receipt, err = txAPI.GetTransactionReceipt(ctx, txHashes[0])
+	require.NoError(t, err)
+	require.Equal(t, lastAccepted, receipt["acceptedHeight"])
+	require.Equal(t, hexutil.Uint64(1000), receipt["acceptedAt"])
+	require.Equal(t, hexutil.Uint64(7), receipt["pChainHeight"])
+
+	block, err := chainAPI.GetBlockByNumber(ctx, rpc.BlockNumber(blockNumber), false)
+	require.NoError(t, err)
+	require.Equal(t, lastAccepted, block["acceptedHeight"])
+	require.Equal(t, hexutil.Uint64(1000), block["acceptedAt"])
+
+	// Blocks accepted without a record only report the last accepted height.
+	block, err = chainAPI.GetBlockByNumber(ctx, rpc.BlockNumber(blockNumber+1), false)
+	require.NoError(t, err)
+	require.Equal(t, lastAccepted, block["acceptedHeight"])
+	require.NotContains(t, block, "acceptedAt")
+}
+
 func testRPCResponseWithFile(t *testing.T, testid int, result interface{}, rpc string, file string) {
 	data, err := json.MarshalIndent(result, "", "  ")
 	if err != nil {
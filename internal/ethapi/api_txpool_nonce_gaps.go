@@ -0,0 +1,112 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ethapi
+
+import (
+	"context"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/shubhamdubey02/coreth/core/txpool"
+	"github.com/shubhamdubey02/coreth/params"
+)
+
+// maxNonceGapFillers bounds the number of filler transactions returned for a
+// nonce gap. Once they are included, the fillers of the remaining missing
+// nonces can be requested again.
+const maxNonceGapFillers = 64
+
+// RPCNonceGap is a range of nonces missing from the transactions of an
+// account, which prevents its queued transactions from being executed.
+type RPCNonceGap struct {
+	Address common.Address `json:"address"`
+	First   hexutil.Uint64 `json:"firstMissingNonce"`
+	Last    hexutil.Uint64 `json:"lastMissingNonce"`
+	Blocked hexutil.Uint   `json:"blocked"`
+	// Fillers are unsigned zero-value self-transfers, one for each missing
+	// nonce, whose inclusion closes the gap at the minimum cost.
+	Fillers []TransactionArgs `json:"fillers,omitempty"`
+}
+
+func newRPCNonceGap(gap *txpool.NonceGap) *RPCNonceGap {
+	return &RPCNonceGap{
+		Address: gap.Address,
+		First:   hexutil.Uint64(gap.First),
+		Last:    hexutil.Uint64(gap.Last),
+		Blocked: hexutil.Uint(gap.Blocked),
+	}
+}
+
+// NonceGaps returns the nonce gaps of the accounts of the pool, blocking at
+// least [minBlocked] queued transactions if specified, by decreasing number
+// of blocked transactions.
+func (s *TxPoolAPI) NonceGaps(ctx context.Context, minBlocked *hexutil.Uint) ([]*RPCNonceGap, error) {
+	_, queued := s.b.TxPoolContent()
+
+	gaps := []*RPCNonceGap{}
+	for addr, txs := range queued {
+		if minBlocked != nil && len(txs) < int(*minBlocked) {
+			continue
+		}
+		nonce, err := s.b.GetPoolNonce(ctx, addr)
+		if err != nil {
+			return nil, err
+		}
+		gap := txpool.FindNonceGap(addr, nonce, txs)
+		if gap == nil || (minBlocked != nil && gap.Blocked < int(*minBlocked)) {
+			continue
+		}
+		gaps = append(gaps, newRPCNonceGap(gap))
+	}
+	sort.Slice(gaps, func(i, j int) bool {
+		if gaps[i].Blocked != gaps[j].Blocked {
+			return gaps[i].Blocked > gaps[j].Blocked
+		}
+		return gaps[i].Address.Cmp(gaps[j].Address) < 0
+	})
+	return gaps, nil
+}
+
+// NonceGap returns the nonce gap blocking the queued transactions of
+// [address], along with the filler transactions closing it, or nil if its
+// queued transactions are not blocked by a gap. The fillers are to be signed
+// by the account, for instance with eth_signTransaction, and sent.
+func (s *TxPoolAPI) NonceGap(ctx context.Context, address common.Address) (*RPCNonceGap, error) {
+	_, queued := s.b.TxPoolContentFrom(address)
+	nonce, err := s.b.GetPoolNonce(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	gap := txpool.FindNonceGap(address, nonce, queued)
+	if gap == nil {
+		return nil, nil
+	}
+
+	// All fillers share the suggested fees of a plain transfer.
+	var (
+		value = new(hexutil.Big)
+		gas   = hexutil.Uint64(params.TxGas)
+		fees  = TransactionArgs{From: &address}
+	)
+	if err := fees.setFeeDefaults(ctx, s.b); err != nil {
+		return nil, err
+	}
+	result := newRPCNonceGap(gap)
+	for n := gap.First; n <= gap.Last && len(result.Fillers) < maxNonceGapFillers; n++ {
+		nonce := hexutil.Uint64(n)
+		result.Fillers = append(result.Fillers, TransactionArgs{
+			From:                 &address,
+			To:                   &address,
+			Value:                value,
+			Gas:                  &gas,
+			Nonce:                &nonce,
+			GasPrice:             fees.GasPrice,
+			MaxFeePerGas:         fees.MaxFeePerGas,
+			MaxPriorityFeePerGas: fees.MaxPriorityFeePerGas,
+			ChainID:              (*hexutil.Big)(s.b.ChainConfig().ChainID),
+		})
+	}
+	return result, nil
+}
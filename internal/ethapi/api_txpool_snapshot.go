@@ -0,0 +1,98 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ethapi
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/shubhamdubey02/coreth/core/txpool"
+	"github.com/shubhamdubey02/coreth/core/types"
+)
+
+// PoolSnapshot is the content of a transaction pool as exported by
+// admin_exportTxPool and accepted by admin_importTxPool. As it reveals which
+// senders the pool treats as local, and importing it lets the caller mark
+// senders as local, it is only served by the admin API.
+type PoolSnapshot struct {
+	Exported     time.Time         `json:"exported"`
+	Transactions []*PoolSnapshotTx `json:"transactions"`
+}
+
+// PoolSnapshotTx is the RPC representation of a [txpool.SnapshotTx].
+type PoolSnapshotTx struct {
+	Hash    common.Hash   `json:"hash"`
+	Raw     hexutil.Bytes `json:"raw"`
+	Time    time.Time     `json:"time"`
+	Local   bool          `json:"local"`
+	Pending bool          `json:"pending"`
+}
+
+// PoolSnapshotImport summarizes the result of admin_importTxPool.
+type PoolSnapshotImport struct {
+	Imported hexutil.Uint           `json:"imported"`
+	Errors   map[common.Hash]string `json:"errors,omitempty"`
+}
+
+// ExportPoolSnapshot returns the pending and queued transactions of the pool
+// of [b] along with their arrival time and locality, so that they can be
+// imported on a standby node with [ImportPoolSnapshot].
+func ExportPoolSnapshot(b Backend) (*PoolSnapshot, error) {
+	exported := b.TxPoolSnapshot()
+	snapshot := &PoolSnapshot{
+		Exported:     time.Now(),
+		Transactions: make([]*PoolSnapshotTx, 0, len(exported)),
+	}
+	for _, stx := range exported {
+		raw, err := stx.Tx.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode transaction %s: %w", stx.Tx.Hash(), err)
+		}
+		snapshot.Transactions = append(snapshot.Transactions, &PoolSnapshotTx{
+			Hash:    stx.Tx.Hash(),
+			Raw:     raw,
+			Time:    stx.Time,
+			Local:   stx.Local,
+			Pending: stx.Pending,
+		})
+	}
+	return snapshot, nil
+}
+
+// ImportPoolSnapshot adds the transactions of a snapshot produced by
+// [ExportPoolSnapshot] to the pool of [b]. Transactions rejected by the pool,
+// including those it already knows, are reported by hash without failing the
+// import.
+func ImportPoolSnapshot(b Backend, snapshot PoolSnapshot) (*PoolSnapshotImport, error) {
+	txs := make([]*txpool.SnapshotTx, 0, len(snapshot.Transactions))
+	for i, stx := range snapshot.Transactions {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(stx.Raw); err != nil {
+			return nil, fmt.Errorf("failed to decode transaction %d: %w", i, err)
+		}
+		if tx.Hash() != stx.Hash {
+			return nil, fmt.Errorf("transaction %d has hash %s, expected %s", i, tx.Hash(), stx.Hash)
+		}
+		txs = append(txs, &txpool.SnapshotTx{
+			Tx:      tx,
+			Time:    stx.Time,
+			Local:   stx.Local,
+			Pending: stx.Pending,
+		})
+	}
+
+	result := &PoolSnapshotImport{Errors: make(map[common.Hash]string)}
+	for i, err := range b.RestoreTxPoolSnapshot(txs) {
+		if err != nil {
+			result.Errors[txs[i].Tx.Hash()] = err.Error()
+			continue
+		}
+		result.Imported++
+	}
+	log.Info("Imported transaction pool snapshot", "imported", result.Imported, "rejected", len(result.Errors), "exported", snapshot.Exported)
+	return result, nil
+}
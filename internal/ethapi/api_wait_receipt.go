@@ -0,0 +1,91 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/shubhamdubey02/coreth/core"
+	"github.com/shubhamdubey02/coreth/core/types"
+)
+
+const (
+	// defaultReceiptWaitTimeout is how long WaitForTransactionReceipt waits
+	// if no timeout is given.
+	defaultReceiptWaitTimeout = 30 * time.Second
+	// maxReceiptWaitTimeout is the longest timeout WaitForTransactionReceipt
+	// accepts.
+	maxReceiptWaitTimeout = 5 * time.Minute
+	// maxReceiptWaiters is the maximum number of concurrent calls to
+	// WaitForTransactionReceipt.
+	maxReceiptWaiters = 1024
+)
+
+var errTooManyReceiptWaiters = errors.New("too many pending calls waiting for transaction receipts")
+
+// WaitForTransactionReceipt returns the receipt of transaction [hash] once it
+// is accepted, waiting at most [timeout] seconds for it. The timeout defaults
+// to 30 seconds and is capped at 5 minutes. Like GetTransactionReceipt, it
+// returns null if the transaction is not accepted in time.
+func (s *TransactionAPI) WaitForTransactionReceipt(ctx context.Context, hash common.Hash, timeout *hexutil.Uint64) (map[string]interface{}, error) {
+	wait := defaultReceiptWaitTimeout
+	if timeout != nil {
+		wait = min(time.Duration(*timeout)*time.Second, maxReceiptWaitTimeout)
+	}
+	if s.receiptWaiters.Add(1) > maxReceiptWaiters {
+		s.receiptWaiters.Add(-1)
+		return nil, errTooManyReceiptWaiters
+	}
+	defer s.receiptWaiters.Add(-1)
+
+	// Subscribe before looking up the receipt so that a transaction accepted
+	// in between is not missed.
+	acceptedCh := make(chan core.ChainEvent, 16)
+	sub := s.b.SubscribeChainAcceptedEvent(acceptedCh)
+	defer sub.Unsubscribe()
+
+	receipt, err := s.GetTransactionReceipt(ctx, hash)
+	if receipt != nil || err != nil {
+		return receipt, err
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	for {
+		select {
+		case ev := <-acceptedCh:
+			for i, tx := range ev.Block.Transactions() {
+				if tx.Hash() == hash {
+					return s.blockReceipt(ctx, ev.Block, tx, i)
+				}
+			}
+		case err := <-sub.Err():
+			return nil, err
+		case <-timer.C:
+			return nil, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// blockReceipt returns the receipt of [tx], the [index]th transaction of
+// [block].
+func (s *TransactionAPI) blockReceipt(ctx context.Context, block *types.Block, tx *types.Transaction, index int) (map[string]interface{}, error) {
+	receipts, err := s.b.GetReceipts(ctx, block.Hash())
+	if err != nil {
+		return nil, err
+	}
+	if len(receipts) <= index {
+		return nil, nil
+	}
+	signer := types.MakeSigner(s.b.ChainConfig(), block.Number(), block.Time())
+	fields := marshalReceipt(receipts[index], block.Hash(), block.NumberU64(), signer, tx, index)
+	addAcceptanceFields(s.b, fields, block.Hash(), block.NumberU64())
+	return fields, nil
+}
@@ -0,0 +1,84 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ethapi
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/shubhamdubey02/coreth/consensus/dummy"
+	"github.com/shubhamdubey02/coreth/core"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitForTransactionReceipt(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	var (
+		accounts = newAccounts(2)
+		genesis  = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  core.GenesisAlloc{accounts[0].addr: {Balance: big.NewInt(params.Ether)}},
+		}
+		signer = types.LatestSigner(params.TestChainConfig)
+		engine = dummy.NewCoinbaseFaker()
+		nonce  uint64
+	)
+	transfer := func(b *core.BlockGen) *types.Transaction {
+		tx, err := types.SignTx(types.NewTransaction(nonce, accounts[1].addr, big.NewInt(1), params.TxGas, b.BaseFee(), nil), signer, accounts[0].key)
+		require.NoError(err)
+		b.AddTx(tx)
+		nonce++
+		return tx
+	}
+	var accepted *types.Transaction
+	backend := newTestBackend(t, 1, genesis, engine, func(_ int, b *core.BlockGen) {
+		accepted = transfer(b)
+	})
+	api := NewTransactionAPI(backend, new(AddrLocker))
+
+	// Accepted transactions are returned immediately.
+	receipt, err := api.WaitForTransactionReceipt(context.Background(), accepted.Hash(), nil)
+	require.NoError(err)
+	require.Equal(accepted.Hash(), receipt["transactionHash"])
+
+	// Unknown transactions time out.
+	zero := hexutil.Uint64(0)
+	receipt, err = api.WaitForTransactionReceipt(context.Background(), common.Hash{1}, &zero)
+	require.NoError(err)
+	require.Nil(receipt)
+
+	// Pending transactions are returned once accepted.
+	var pending *types.Transaction
+	blocks, _, err := core.GenerateChain(params.TestChainConfig, backend.chain.LastAcceptedBlock(), engine, backend.db, 1, 10, func(_ int, b *core.BlockGen) {
+		pending = transfer(b)
+	})
+	require.NoError(err)
+	type result struct {
+		receipt map[string]interface{}
+		err     error
+	}
+	resultCh := make(chan result, 1)
+	go func() {
+		receipt, err := api.WaitForTransactionReceipt(context.Background(), pending.Hash(), nil)
+		resultCh <- result{receipt, err}
+	}()
+	require.Eventually(func() bool { return api.receiptWaiters.Load() == 1 }, time.Second, time.Millisecond)
+	_, err = backend.chain.InsertChain(blocks)
+	require.NoError(err)
+	require.NoError(backend.chain.Accept(blocks[0]))
+	backend.chain.DrainAcceptorQueue()
+
+	res := <-resultCh
+	require.NoError(res.err)
+	require.Equal(pending.Hash(), res.receipt["transactionHash"])
+	require.Equal(hexutil.Uint64(2), res.receipt["blockNumber"])
+}
@@ -31,14 +31,63 @@ import (
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/shubhamdubey02/coreth/accounts/abi"
+	"github.com/shubhamdubey02/coreth/core"
+	"github.com/shubhamdubey02/coreth/core/txpool"
+	"github.com/shubhamdubey02/coreth/core/txpool/legacypool"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/rpc/errcodes"
 	"github.com/shubhamdubey02/coreth/vmerrs"
 )
 
+var errUnprotectedTx = errcodes.New(errcodes.TxUnprotected, "only replay-protected (EIP-155) transactions allowed over RPC")
+
+// txErrorCodes assigns codes to the errors of the transaction pool rejecting
+// a transaction submitted over RPC.
+var txErrorCodes = []errcodes.Rule{
+	{Err: txpool.ErrAlreadyKnown, Code: errcodes.TxAlreadyKnown},
+	{Err: core.ErrNonceTooLow, Code: errcodes.TxNonceTooLow},
+	{Err: core.ErrNonceTooHigh, Code: errcodes.TxNonceTooHigh},
+	{Err: txpool.ErrUnderpriced, Code: errcodes.TxUnderpriced},
+	{Err: core.ErrFeeCapTooLow, Code: errcodes.TxUnderpriced},
+	{Err: txpool.ErrReplaceUnderpriced, Code: errcodes.TxReplacementUnderpriced},
+	{Err: txpool.ErrFutureReplacePending, Code: errcodes.TxReplacementUnderpriced},
+	{Err: core.ErrInsufficientFunds, Code: errcodes.TxInsufficientFunds},
+	{Err: core.ErrInsufficientFundsForTransfer, Code: errcodes.TxInsufficientFunds},
+	{Err: txpool.ErrGasLimit, Code: errcodes.TxGasLimitExceeded},
+	{Err: legacypool.ErrTxPoolOverflow, Code: errcodes.TxPoolFull},
+	{Err: txpool.ErrAccountLimitExceeded, Code: errcodes.TxPoolFull},
+	{Err: txpool.ErrInvalidSender, Code: errcodes.TxInvalid},
+	{Err: txpool.ErrNegativeValue, Code: errcodes.TxInvalid},
+	{Err: txpool.ErrOversizedData, Code: errcodes.TxInvalid},
+	{Err: txpool.ErrInvalidCustomTx, Code: errcodes.TxInvalid},
+	{Err: core.ErrIntrinsicGas, Code: errcodes.TxInvalid},
+	{Err: core.ErrTipAboveFeeCap, Code: errcodes.TxInvalid},
+	{Err: core.ErrTipVeryHigh, Code: errcodes.TxInvalid},
+	{Err: core.ErrFeeCapVeryHigh, Code: errcodes.TxInvalid},
+	{Err: core.ErrGasUintOverflow, Code: errcodes.TxInvalid},
+	{Err: core.ErrMaxInitCodeSizeExceeded, Code: errcodes.TxInvalid},
+	{Err: core.ErrNonceMax, Code: errcodes.TxInvalid},
+	{Err: core.ErrSenderNoEOA, Code: errcodes.TxInvalid},
+	{Err: types.ErrTxTypeNotSupported, Code: errcodes.TxInvalid},
+	{Err: types.ErrInvalidChainId, Code: errcodes.TxInvalid},
+}
+
+// txError returns [err], rejecting [tx], with the code of its reason and the
+// hash of [tx] as detail. Errors without a code are returned unchanged.
+func txError(tx *types.Transaction, err error) error {
+	coded, ok := errcodes.Classify(err, txErrorCodes).(*errcodes.Error)
+	if !ok {
+		return err
+	}
+	return coded.WithDetail("hash", tx.Hash())
+}
+
 // revertError is an API error that encompasses an EVM revert with JSON error
 // code and a binary data blob.
 type revertError struct {
 	error
-	reason string // revert reason hex encoded
+	reason string       // revert reason hex encoded
+	trace  *revertFrame // summary of the failed call frames, if traced
 }
 
 // ErrorCode returns the JSON error code for a revert.
@@ -47,9 +96,19 @@ func (e *revertError) ErrorCode() int {
 	return 3
 }
 
-// ErrorData returns the hex encoded revert reason.
+// ErrorData returns the hex encoded revert reason, along with the summary of
+// the failed call frames if the execution was traced.
 func (e *revertError) ErrorData() interface{} {
-	return e.reason
+	if e.trace == nil {
+		return e.reason
+	}
+	return struct {
+		Data  string       `json:"data"`
+		Trace *revertFrame `json:"trace"`
+	}{
+		Data:  e.reason,
+		Trace: e.trace,
+	}
 }
 
 // newRevertError creates a revertError instance with the provided revert data.
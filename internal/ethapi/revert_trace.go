@@ -0,0 +1,151 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ethapi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/shubhamdubey02/coreth/accounts/abi"
+	"github.com/shubhamdubey02/coreth/core/vm"
+	"github.com/shubhamdubey02/coreth/vmerrs"
+)
+
+var _ vm.EVMLogger = (*revertTracer)(nil)
+
+// revertFrame is a call frame of the summary of a failed execution.
+type revertFrame struct {
+	Type     string         `json:"type"`
+	From     common.Address `json:"from"`
+	To       common.Address `json:"to"`
+	Selector hexutil.Bytes  `json:"selector,omitempty"`
+	Error    string         `json:"error,omitempty"`
+	// Reason is the decoded revert reason, or Output the revert data if it
+	// could not be decoded.
+	Reason string         `json:"reason,omitempty"`
+	Output hexutil.Bytes  `json:"output,omitempty"`
+	Calls  []*revertFrame `json:"calls,omitempty"`
+}
+
+// revertTracer summarizes the call frames of an execution so that a failure
+// can be diagnosed without a separate trace. Only the frames that failed and
+// their callers are kept. Revert data is decoded as Error(string), Panic(uint256)
+// or, if [errorABI] is set, as one of its custom errors.
+type revertTracer struct {
+	errorABI *abi.ABI
+	root     *revertFrame
+	stack    []*revertFrame
+}
+
+func newRevertTracer(errorABI *abi.ABI) *revertTracer {
+	return &revertTracer{errorABI: errorABI}
+}
+
+func newRevertFrame(typ vm.OpCode, from common.Address, to common.Address, input []byte) *revertFrame {
+	frame := &revertFrame{
+		Type: typ.String(),
+		From: from,
+		To:   to,
+	}
+	if typ != vm.CREATE && typ != vm.CREATE2 && len(input) >= 4 {
+		frame.Selector = common.CopyBytes(input[:4])
+	}
+	return frame
+}
+
+func (t *revertTracer) CaptureTxStart(uint64) {}
+
+func (t *revertTracer) CaptureTxEnd(uint64) {}
+
+func (t *revertTracer) CaptureStart(_ *vm.EVM, from common.Address, to common.Address, create bool, input []byte, _ uint64, _ *big.Int) {
+	typ := vm.CALL
+	if create {
+		typ = vm.CREATE
+	}
+	t.root = newRevertFrame(typ, from, to, input)
+	t.stack = []*revertFrame{t.root}
+}
+
+func (t *revertTracer) CaptureEnd(output []byte, _ uint64, err error) {
+	if t.root != nil {
+		t.fail(t.root, output, err)
+	}
+}
+
+func (t *revertTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, _ uint64, _ *big.Int) {
+	if len(t.stack) == 0 {
+		return
+	}
+	frame := newRevertFrame(typ, from, to, input)
+	parent := t.stack[len(t.stack)-1]
+	parent.Calls = append(parent.Calls, frame)
+	t.stack = append(t.stack, frame)
+}
+
+func (t *revertTracer) CaptureExit(output []byte, _ uint64, err error) {
+	if len(t.stack) < 2 {
+		return
+	}
+	frame := t.stack[len(t.stack)-1]
+	t.stack = t.stack[:len(t.stack)-1]
+	if err == nil {
+		// Drop the successful call from its caller.
+		parent := t.stack[len(t.stack)-1]
+		parent.Calls = parent.Calls[:len(parent.Calls)-1]
+		return
+	}
+	t.fail(frame, output, err)
+}
+
+func (t *revertTracer) CaptureState(uint64, vm.OpCode, uint64, uint64, *vm.ScopeContext, []byte, int, error) {
+}
+
+func (t *revertTracer) CaptureFault(uint64, vm.OpCode, uint64, uint64, *vm.ScopeContext, int, error) {
+}
+
+// fail records the failure [err] of [frame].
+func (t *revertTracer) fail(frame *revertFrame, output []byte, err error) {
+	if err == nil {
+		return
+	}
+	frame.Error = err.Error()
+	if !errors.Is(err, vmerrs.ErrExecutionReverted) || len(output) == 0 {
+		return
+	}
+	if reason, ok := t.decodeRevert(output); ok {
+		frame.Reason = reason
+	} else {
+		frame.Output = common.CopyBytes(output)
+	}
+}
+
+// decodeRevert returns the revert reason encoded in [data].
+func (t *revertTracer) decodeRevert(data []byte) (string, bool) {
+	if reason, err := abi.UnpackRevert(data); err == nil {
+		return reason, true
+	}
+	if t.errorABI == nil || len(data) < 4 {
+		return "", false
+	}
+	for _, abiErr := range t.errorABI.Errors {
+		if !bytes.Equal(data[:4], abiErr.ID[:4]) {
+			continue
+		}
+		args, err := abiErr.Inputs.Unpack(data[4:])
+		if err != nil {
+			return "", false
+		}
+		strs := make([]string, len(args))
+		for i, arg := range args {
+			strs[i] = fmt.Sprint(arg)
+		}
+		return fmt.Sprintf("%s(%s)", abiErr.Name, strings.Join(strs, ", ")), true
+	}
+	return "", false
+}
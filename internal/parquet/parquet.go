@@ -0,0 +1,285 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package parquet writes flat tables as Apache Parquet files.
+//
+// Every file holds a single row group with one PLAIN encoded, uncompressed
+// data page per column, which is enough for the batch exports it is used for
+// and is readable by every Parquet implementation.
+package parquet
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+const (
+	magic     = "PAR1"
+	createdBy = "coreth"
+)
+
+// Type is the type of the values of a column.
+type Type int
+
+const (
+	// Int64 columns hold int64 values.
+	Int64 Type = iota
+	// String columns hold UTF-8 string values.
+	String
+)
+
+func (t Type) String() string {
+	switch t {
+	case Int64:
+		return "int64"
+	case String:
+		return "string"
+	default:
+		return "unknown"
+	}
+}
+
+// Values of the Parquet format's Thrift enums.
+const (
+	typeInt64     = 2
+	typeByteArray = 6
+
+	repetitionRequired = 0
+	repetitionOptional = 1
+
+	convertedTypeUTF8 = 0
+
+	encodingPlain = 0
+	encodingRLE   = 3
+
+	codecUncompressed = 0
+
+	pageTypeData = 0
+)
+
+// Column describes a column of a [Table]. Optional columns accept nil values.
+type Column struct {
+	Name     string
+	Type     Type
+	Optional bool
+}
+
+func (c Column) physicalType() int32 {
+	if c.Type == String {
+		return typeByteArray
+	}
+	return typeInt64
+}
+
+// Table accumulates rows to be written as a Parquet file.
+type Table struct {
+	columns []Column
+	values  [][]any
+}
+
+// NewTable returns an empty table with the given columns.
+func NewTable(columns []Column) *Table {
+	return &Table{
+		columns: columns,
+		values:  make([][]any, len(columns)),
+	}
+}
+
+// Columns returns the columns of the table.
+func (t *Table) Columns() []Column {
+	return t.columns
+}
+
+// Len returns the number of rows appended to the table.
+func (t *Table) Len() int {
+	if len(t.values) == 0 {
+		return 0
+	}
+	return len(t.values[0])
+}
+
+// Append adds a row holding a value for each column of the table, in order.
+// Int64 columns take int64 values and String columns take string values.
+func (t *Table) Append(row ...any) error {
+	if len(row) != len(t.columns) {
+		return fmt.Errorf("row has %d values, expected %d", len(row), len(t.columns))
+	}
+	for i, v := range row {
+		column := t.columns[i]
+		switch v.(type) {
+		case nil:
+			if !column.Optional {
+				return fmt.Errorf("column %q is not optional", column.Name)
+			}
+		case int64:
+			if column.Type != Int64 {
+				return fmt.Errorf("column %q expects %s values, got int64", column.Name, column.Type)
+			}
+		case string:
+			if column.Type != String {
+				return fmt.Errorf("column %q expects %s values, got string", column.Name, column.Type)
+			}
+		default:
+			return fmt.Errorf("column %q: unsupported value type %T", column.Name, v)
+		}
+	}
+	for i, v := range row {
+		t.values[i] = append(t.values[i], v)
+	}
+	return nil
+}
+
+// Reset removes every row from the table.
+func (t *Table) Reset() {
+	for i := range t.values {
+		t.values[i] = t.values[i][:0]
+	}
+}
+
+type columnChunk struct {
+	offset int64
+	size   int64
+	values int64
+}
+
+// WriteTo writes the table to [w] as a Parquet file.
+func (t *Table) WriteTo(w io.Writer) (int64, error) {
+	out := []byte(magic)
+	chunks := make([]columnChunk, len(t.columns))
+	for i, column := range t.columns {
+		page := encodePage(column, t.values[i])
+		var header thriftWriter
+		header.structBegin()
+		header.i32(1, pageTypeData)
+		header.i32(2, int32(len(page)))
+		header.i32(3, int32(len(page)))
+		header.structField(5) // data_page_header
+		header.i32(1, int32(len(t.values[i])))
+		header.i32(2, encodingPlain)
+		header.i32(3, encodingRLE)
+		header.i32(4, encodingRLE)
+		header.structEnd()
+		header.structEnd()
+
+		chunks[i] = columnChunk{
+			offset: int64(len(out)),
+			size:   int64(len(header.buf) + len(page)),
+			values: int64(len(t.values[i])),
+		}
+		out = append(out, header.buf...)
+		out = append(out, page...)
+	}
+
+	footer := t.encodeMetadata(chunks)
+	out = append(out, footer...)
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(footer)))
+	out = append(out, magic...)
+
+	n, err := w.Write(out)
+	return int64(n), err
+}
+
+// encodePage returns the body of the data page holding [values].
+func encodePage(column Column, values []any) []byte {
+	var page []byte
+	if column.Optional {
+		levels := encodeDefinitionLevels(values)
+		page = binary.LittleEndian.AppendUint32(page, uint32(len(levels)))
+		page = append(page, levels...)
+	}
+	for _, v := range values {
+		switch v := v.(type) {
+		case int64:
+			page = binary.LittleEndian.AppendUint64(page, uint64(v))
+		case string:
+			page = binary.LittleEndian.AppendUint32(page, uint32(len(v)))
+			page = append(page, v...)
+		}
+	}
+	return page
+}
+
+// encodeDefinitionLevels encodes whether each value is defined with the RLE
+// hybrid encoding and a bit width of 1, as runs of equal levels.
+func encodeDefinitionLevels(values []any) []byte {
+	var levels []byte
+	for start := 0; start < len(values); {
+		defined := values[start] != nil
+		end := start + 1
+		for end < len(values) && (values[end] != nil) == defined {
+			end++
+		}
+		levels = binary.AppendUvarint(levels, uint64(end-start)<<1)
+		if defined {
+			levels = append(levels, 1)
+		} else {
+			levels = append(levels, 0)
+		}
+		start = end
+	}
+	return levels
+}
+
+// encodeMetadata returns the FileMetaData of the file.
+func (t *Table) encodeMetadata(chunks []columnChunk) []byte {
+	var w thriftWriter
+	w.structBegin()
+	w.i32(1, 1) // version
+
+	w.listField(2, compactStruct, len(t.columns)+1) // schema
+	w.structBegin()
+	w.string(4, "schema")
+	w.i32(5, int32(len(t.columns)))
+	w.structEnd()
+	for _, column := range t.columns {
+		w.structBegin()
+		w.i32(1, column.physicalType())
+		repetition := int32(repetitionRequired)
+		if column.Optional {
+			repetition = repetitionOptional
+		}
+		w.i32(3, repetition)
+		w.string(4, column.Name)
+		if column.Type == String {
+			w.i32(6, convertedTypeUTF8)
+		}
+		w.structEnd()
+	}
+
+	w.i64(3, int64(t.Len())) // num_rows
+
+	w.listField(4, compactStruct, 1) // row_groups
+	w.structBegin()
+	w.listField(1, compactStruct, len(t.columns)) // columns
+	var totalSize int64
+	for i, column := range t.columns {
+		chunk := chunks[i]
+		totalSize += chunk.size
+		w.structBegin()
+		w.i64(2, chunk.offset) // file_offset
+		w.structField(3)       // meta_data
+		w.i32(1, column.physicalType())
+		encodings := []int32{encodingPlain, encodingRLE}
+		w.listField(2, compactI32, len(encodings))
+		for _, encoding := range encodings {
+			w.zigzag(int64(encoding))
+		}
+		w.listField(3, compactBinary, 1) // path_in_schema
+		w.binary(column.Name)
+		w.i32(4, codecUncompressed)
+		w.i64(5, chunk.values)
+		w.i64(6, chunk.size) // total_uncompressed_size
+		w.i64(7, chunk.size) // total_compressed_size
+		w.i64(9, chunk.offset)
+		w.structEnd()
+		w.structEnd()
+	}
+	w.i64(2, totalSize)
+	w.i64(3, int64(t.Len()))
+	w.structEnd()
+
+	w.string(6, createdBy)
+	w.structEnd()
+	return w.buf
+}
@@ -0,0 +1,245 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// thriftReader decodes Thrift compact protocol structs into maps from field
+// identifiers to values, which is enough to check the written metadata.
+type thriftReader struct {
+	buf []byte
+	err error
+}
+
+func (r *thriftReader) byte() byte {
+	if len(r.buf) == 0 {
+		r.err = fmt.Errorf("unexpected end of input")
+		return 0
+	}
+	b := r.buf[0]
+	r.buf = r.buf[1:]
+	return b
+}
+
+func (r *thriftReader) varint() uint64 {
+	v, n := binary.Uvarint(r.buf)
+	if n <= 0 {
+		r.err = fmt.Errorf("invalid varint")
+		return 0
+	}
+	r.buf = r.buf[n:]
+	return v
+}
+
+func (r *thriftReader) zigzag() int64 {
+	v := r.varint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *thriftReader) value(typ byte) any {
+	switch typ {
+	case compactI32, compactI64:
+		return r.zigzag()
+	case compactBinary:
+		n := r.varint()
+		if uint64(len(r.buf)) < n {
+			r.err = fmt.Errorf("unexpected end of input")
+			return nil
+		}
+		s := string(r.buf[:n])
+		r.buf = r.buf[n:]
+		return s
+	case compactList:
+		header := r.byte()
+		size := uint64(header >> 4)
+		if size == 15 {
+			size = r.varint()
+		}
+		list := make([]any, size)
+		for i := range list {
+			list[i] = r.value(header & 0x0f)
+		}
+		return list
+	case compactStruct:
+		return r.readStruct()
+	default:
+		r.err = fmt.Errorf("unsupported type %d", typ)
+		return nil
+	}
+}
+
+func (r *thriftReader) readStruct() map[int16]any {
+	fields := make(map[int16]any)
+	var id int16
+	for r.err == nil {
+		header := r.byte()
+		if header == 0 {
+			break
+		}
+		if delta := int16(header >> 4); delta != 0 {
+			id += delta
+		} else {
+			id = int16(r.zigzag())
+		}
+		fields[id] = r.value(header & 0x0f)
+	}
+	return fields
+}
+
+// readTable decodes a file written by [Table.WriteTo], returning the column
+// names and the values of each column.
+func readTable(t *testing.T, file []byte) ([]string, [][]any) {
+	t.Helper()
+
+	if !bytes.HasPrefix(file, []byte(magic)) || !bytes.HasSuffix(file, []byte(magic)) {
+		t.Fatal("missing magic")
+	}
+	footerLen := binary.LittleEndian.Uint32(file[len(file)-8:])
+	footer := &thriftReader{buf: file[len(file)-8-int(footerLen) : len(file)-8]}
+	metadata := footer.readStruct()
+	if footer.err != nil {
+		t.Fatal(footer.err)
+	}
+
+	numRows := metadata[3].(int64)
+	schema := metadata[2].([]any)
+	if got := schema[0].(map[int16]any)[5].(int64); got != int64(len(schema)-1) {
+		t.Fatalf("root has %d children, expected %d", got, len(schema)-1)
+	}
+	var (
+		names  []string
+		values [][]any
+	)
+	rowGroup := metadata[4].([]any)[0].(map[int16]any)
+	for i, element := range schema[1:] {
+		element := element.(map[int16]any)
+		names = append(names, element[4].(string))
+		optional := element[3].(int64) == repetitionOptional
+
+		chunk := rowGroup[1].([]any)[i].(map[int16]any)[3].(map[int16]any)
+		if path := chunk[3].([]any)[0]; path != element[4] {
+			t.Fatalf("column path %v does not match schema name %v", path, element[4])
+		}
+		if chunk[5].(int64) != numRows {
+			t.Fatalf("column has %d values, expected %d", chunk[5], numRows)
+		}
+		page := &thriftReader{buf: file[chunk[9].(int64):]}
+		header := page.readStruct()
+		if page.err != nil {
+			t.Fatal(page.err)
+		}
+		body := page.buf[:header[2].(int64)]
+
+		defined := make([]bool, numRows)
+		if optional {
+			levelsLen := binary.LittleEndian.Uint32(body)
+			levels := &thriftReader{buf: body[4 : 4+levelsLen]}
+			body = body[4+levelsLen:]
+			for pos := 0; len(levels.buf) > 0; {
+				run := int(levels.varint() >> 1)
+				level := levels.byte()
+				for j := 0; j < run; j++ {
+					defined[pos] = level == 1
+					pos++
+				}
+			}
+		} else {
+			for j := range defined {
+				defined[j] = true
+			}
+		}
+
+		column := make([]any, numRows)
+		for j := range column {
+			if !defined[j] {
+				continue
+			}
+			if element[1].(int64) == typeInt64 {
+				column[j] = int64(binary.LittleEndian.Uint64(body))
+				body = body[8:]
+				continue
+			}
+			n := binary.LittleEndian.Uint32(body)
+			column[j] = string(body[4 : 4+n])
+			body = body[4+n:]
+		}
+		values = append(values, column)
+	}
+	return names, values
+}
+
+func TestTableRoundTrip(t *testing.T) {
+	table := NewTable([]Column{
+		{Name: "number", Type: Int64},
+		{Name: "hash", Type: String},
+		{Name: "base_fee", Type: Int64, Optional: true},
+	})
+	rows := [][]any{
+		{int64(0), "0xaa", nil},
+		{int64(1), "0xbb", int64(25)},
+		{int64(-2), "", int64(30)},
+		{int64(3), "0xdd", nil},
+	}
+	for _, row := range rows {
+		if err := table.Append(row...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if table.Len() != len(rows) {
+		t.Fatalf("have %d rows, want %d", table.Len(), len(rows))
+	}
+
+	var buf bytes.Buffer
+	if _, err := table.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	names, values := readTable(t, buf.Bytes())
+	if want := []string{"number", "hash", "base_fee"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("names mismatch: have %v, want %v", names, want)
+	}
+	for i, row := range rows {
+		for j, v := range row {
+			if values[j][i] != v {
+				t.Fatalf("value mismatch at row %d column %d: have %v, want %v", i, j, values[j][i], v)
+			}
+		}
+	}
+
+	table.Reset()
+	if table.Len() != 0 {
+		t.Fatalf("have %d rows after reset", table.Len())
+	}
+}
+
+func TestTableAppendErrors(t *testing.T) {
+	table := NewTable([]Column{
+		{Name: "number", Type: Int64},
+		{Name: "hash", Type: String, Optional: true},
+	})
+	tests := []struct {
+		name string
+		row  []any
+	}{
+		{name: "too few values", row: []any{int64(1)}},
+		{name: "null required value", row: []any{nil, "0xaa"}},
+		{name: "wrong type", row: []any{"1", "0xaa"}},
+		{name: "unsupported type", row: []any{1, "0xaa"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := table.Append(test.row...); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+	if table.Len() != 0 {
+		t.Fatalf("have %d rows after failed appends", table.Len())
+	}
+}
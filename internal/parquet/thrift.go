@@ -0,0 +1,94 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package parquet
+
+import "encoding/binary"
+
+// Type identifiers of the Thrift compact protocol.
+const (
+	compactI32    = 5
+	compactI64    = 6
+	compactBinary = 8
+	compactList   = 9
+	compactStruct = 12
+)
+
+// thriftWriter encodes the Parquet metadata structures with the Thrift compact
+// protocol. Fields must be written in increasing order of their identifiers
+// within each struct.
+type thriftWriter struct {
+	buf []byte
+	// lastField holds the identifier of the last field written in each of the
+	// structs being written.
+	lastField []int16
+}
+
+func (w *thriftWriter) varint(v uint64) {
+	w.buf = binary.AppendUvarint(w.buf, v)
+}
+
+func (w *thriftWriter) zigzag(v int64) {
+	w.varint(uint64((v << 1) ^ (v >> 63)))
+}
+
+func (w *thriftWriter) fieldHeader(id int16, typ byte) {
+	last := &w.lastField[len(w.lastField)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		w.buf = append(w.buf, byte(delta)<<4|typ)
+	} else {
+		w.buf = append(w.buf, typ)
+		w.zigzag(int64(id))
+	}
+	*last = id
+}
+
+func (w *thriftWriter) structBegin() {
+	w.lastField = append(w.lastField, 0)
+}
+
+func (w *thriftWriter) structEnd() {
+	w.buf = append(w.buf, 0)
+	w.lastField = w.lastField[:len(w.lastField)-1]
+}
+
+func (w *thriftWriter) listBegin(elemType byte, size int) {
+	if size < 15 {
+		w.buf = append(w.buf, byte(size)<<4|elemType)
+		return
+	}
+	w.buf = append(w.buf, 0xf0|elemType)
+	w.varint(uint64(size))
+}
+
+func (w *thriftWriter) i32(id int16, v int32) {
+	w.fieldHeader(id, compactI32)
+	w.zigzag(int64(v))
+}
+
+func (w *thriftWriter) i64(id int16, v int64) {
+	w.fieldHeader(id, compactI64)
+	w.zigzag(v)
+}
+
+func (w *thriftWriter) string(id int16, v string) {
+	w.fieldHeader(id, compactBinary)
+	w.binary(v)
+}
+
+func (w *thriftWriter) binary(v string) {
+	w.varint(uint64(len(v)))
+	w.buf = append(w.buf, v...)
+}
+
+// structField begins a struct field, which must be ended with structEnd.
+func (w *thriftWriter) structField(id int16) {
+	w.fieldHeader(id, compactStruct)
+	w.structBegin()
+}
+
+// listField begins a list field of [size] elements of [elemType].
+func (w *thriftWriter) listField(id int16, elemType byte, size int) {
+	w.fieldHeader(id, compactList)
+	w.listBegin(elemType, size)
+}
@@ -0,0 +1,69 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package miner
+
+import (
+	"errors"
+	"math/big"
+	"time"
+)
+
+var (
+	errAgingNegativeThreshold = errors.New("aging threshold must not be negative")
+	errAgingZeroInterval      = errors.New("aging interval must be positive")
+	errAgingZeroBoost         = errors.New("aging boost must be positive")
+	errAgingMaxBoostTooLow    = errors.New("aging max boost must be at least the boost")
+)
+
+// AgingPolicy boosts the priority of the transactions pending for longer than
+// Threshold when selecting the transactions of a block, so that transactions
+// paying low tips are not starved during busy periods. The priority of such a
+// transaction grows by Boost wei per gas for every Interval it pends beyond
+// Threshold, up to MaxBoost.
+//
+// Only the order of selection changes: transactions pay their own tip, must
+// afford the base fee and are included in nonce order.
+type AgingPolicy struct {
+	Threshold time.Duration
+	Interval  time.Duration
+	Boost     uint64
+	MaxBoost  uint64
+}
+
+// Verify returns an error if the policy is malformed.
+func (p *AgingPolicy) Verify() error {
+	switch {
+	case p.Threshold < 0:
+		return errAgingNegativeThreshold
+	case p.Interval <= 0:
+		return errAgingZeroInterval
+	case p.Boost == 0:
+		return errAgingZeroBoost
+	case p.MaxBoost < p.Boost:
+		return errAgingMaxBoostTooLow
+	}
+	return nil
+}
+
+// boost returns the priority added to a transaction pending for [age], or
+// zero if it is not boosted. It returns zero for a nil policy.
+func (p *AgingPolicy) boost(age time.Duration) uint64 {
+	if p == nil || age <= p.Threshold {
+		return 0
+	}
+	intervals := 1 + uint64((age-p.Threshold)/p.Interval)
+	if intervals > p.MaxBoost/p.Boost {
+		return p.MaxBoost
+	}
+	return intervals * p.Boost
+}
+
+// priority returns [fees] boosted for a transaction pending for [age].
+func (p *AgingPolicy) priority(fees *big.Int, age time.Duration) *big.Int {
+	boost := p.boost(age)
+	if boost == 0 {
+		return fees
+	}
+	return new(big.Int).Add(fees, new(big.Int).SetUint64(boost))
+}
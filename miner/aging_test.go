@@ -0,0 +1,139 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package miner
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/shubhamdubey02/coreth/core/txpool"
+	"github.com/shubhamdubey02/coreth/core/types"
+)
+
+func TestAgingPolicyVerify(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy *AgingPolicy
+		err    error
+	}{
+		{
+			name:   "valid",
+			policy: &AgingPolicy{Threshold: time.Minute, Interval: time.Minute, Boost: 1, MaxBoost: 10},
+		},
+		{
+			name:   "negative threshold",
+			policy: &AgingPolicy{Threshold: -time.Minute, Interval: time.Minute, Boost: 1, MaxBoost: 10},
+			err:    errAgingNegativeThreshold,
+		},
+		{
+			name:   "zero interval",
+			policy: &AgingPolicy{Threshold: time.Minute, Boost: 1, MaxBoost: 10},
+			err:    errAgingZeroInterval,
+		},
+		{
+			name:   "zero boost",
+			policy: &AgingPolicy{Threshold: time.Minute, Interval: time.Minute, MaxBoost: 10},
+			err:    errAgingZeroBoost,
+		},
+		{
+			name:   "max boost too low",
+			policy: &AgingPolicy{Threshold: time.Minute, Interval: time.Minute, Boost: 10, MaxBoost: 1},
+			err:    errAgingMaxBoostTooLow,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.policy.Verify(); !errors.Is(err, test.err) {
+				t.Fatalf("error mismatch: have %v, want %v", err, test.err)
+			}
+		})
+	}
+}
+
+func TestAgingPolicyBoost(t *testing.T) {
+	policy := &AgingPolicy{Threshold: time.Minute, Interval: 10 * time.Second, Boost: 2, MaxBoost: 7}
+	tests := []struct {
+		age   time.Duration
+		boost uint64
+	}{
+		{age: 0, boost: 0},
+		{age: time.Minute, boost: 0},
+		{age: time.Minute + time.Second, boost: 2},
+		{age: time.Minute + 10*time.Second, boost: 4},
+		{age: time.Minute + 25*time.Second, boost: 6},
+		{age: time.Minute + 30*time.Second, boost: 7},
+		{age: time.Hour, boost: 7},
+	}
+	for _, test := range tests {
+		if boost := policy.boost(test.age); boost != test.boost {
+			t.Errorf("age %s: have boost %d, want %d", test.age, boost, test.boost)
+		}
+	}
+	if boost := (*AgingPolicy)(nil).boost(time.Hour); boost != 0 {
+		t.Errorf("nil policy: have boost %d, want 0", boost)
+	}
+}
+
+// Tests that transactions pending beyond the aging threshold are selected
+// before newer transactions paying a tip they are boosted above, and that
+// their nonce order is kept.
+func TestTransactionAgedSort(t *testing.T) {
+	t.Parallel()
+
+	var (
+		signer    = types.HomesteadSigner{}
+		now       = time.Unix(1000, 0)
+		oldKey, _ = crypto.GenerateKey()
+		newKey, _ = crypto.GenerateKey()
+	)
+	lazy := func(tx *types.Transaction, seen time.Time) *txpool.LazyTransaction {
+		return &txpool.LazyTransaction{
+			Hash:      tx.Hash(),
+			Tx:        tx,
+			Time:      seen,
+			GasFeeCap: tx.GasFeeCap(),
+			GasTipCap: tx.GasTipCap(),
+			Gas:       tx.Gas(),
+		}
+	}
+	old0, _ := types.SignTx(types.NewTransaction(0, common.Address{}, big.NewInt(1), 21000, big.NewInt(1), nil), signer, oldKey)
+	old1, _ := types.SignTx(types.NewTransaction(1, common.Address{}, big.NewInt(1), 21000, big.NewInt(1), nil), signer, oldKey)
+	fresh, _ := types.SignTx(types.NewTransaction(0, common.Address{}, big.NewInt(1), 21000, big.NewInt(5), nil), signer, newKey)
+	oldFrom, _ := types.Sender(signer, old0)
+	newFrom, _ := types.Sender(signer, fresh)
+
+	order := func(aging *AgingPolicy) []common.Hash {
+		groups := map[common.Address][]*txpool.LazyTransaction{
+			// The second transaction of the account was seen recently, but
+			// is aged with the first.
+			oldFrom: {lazy(old0, now.Add(-time.Hour)), lazy(old1, now)},
+			newFrom: {lazy(fresh, now)},
+		}
+		txset := newAgedTransactionsByPriceAndNonce(signer, groups, nil, nil, aging, now)
+
+		var hashes []common.Hash
+		for tx := txset.Peek(); tx != nil; tx = txset.Peek() {
+			hashes = append(hashes, tx.Hash)
+			txset.Shift()
+		}
+		return hashes
+	}
+	check := func(name string, have []common.Hash, want ...*types.Transaction) {
+		if len(have) != len(want) {
+			t.Fatalf("%s: have %d transactions, want %d", name, len(have), len(want))
+		}
+		for i, tx := range want {
+			if have[i] != tx.Hash() {
+				t.Errorf("%s: tx #%d: have %x, want %x", name, i, have[i], tx.Hash())
+			}
+		}
+	}
+
+	check("unaged", order(nil), fresh, old0, old1)
+	check("aged", order(&AgingPolicy{Threshold: time.Minute, Interval: time.Minute, Boost: 10, MaxBoost: 10}), old0, fresh, old1)
+}
@@ -0,0 +1,39 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package miner
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/shubhamdubey02/coreth/core/state"
+	"github.com/shubhamdubey02/coreth/core/types"
+)
+
+// Bundler supplies a transaction bundling operations submitted outside of
+// the transaction pool, such as the user operations of account abstraction,
+// which is committed ahead of the transactions of the pool.
+type Bundler interface {
+	// Bundle returns the transaction bundling operations valid on [statedb]
+	// at [header] and using at most [gasLimit] gas, or nil if there is none.
+	// Any change made to [statedb] must be reverted.
+	Bundle(header *types.Header, statedb *state.StateDB, gasLimit uint64) *types.Transaction
+}
+
+// commitBundle commits the transaction supplied by [bundler], if any.
+func (w *worker) commitBundle(env *environment, bundler Bundler, coinbase common.Address) {
+	gasLimit := env.gasPool.Gas()
+	if gasLimit <= env.reservedGas {
+		return
+	}
+	tx := bundler.Bundle(env.header, env.state, gasLimit-env.reservedGas)
+	if tx == nil {
+		return
+	}
+	env.state.SetTxContext(tx.Hash(), env.tcount)
+	if _, err := w.commitTransaction(env, tx, coinbase); err != nil {
+		log.Debug("Failed to commit bundle", "hash", tx.Hash(), "err", err)
+		return
+	}
+	env.tcount++
+}
@@ -0,0 +1,78 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package miner
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	errCoinbaseScheduleConflict = errors.New("coinbase schedule cannot set both entries and rotation")
+	errZeroRotationInterval     = errors.New("coinbase rotation interval must be positive")
+	errZeroCoinbase             = errors.New("coinbase schedule cannot contain the zero address")
+)
+
+// CoinbaseSchedule changes the coinbase of built blocks over time, for example
+// to rotate the address fees are paid to every day for accounting. Either
+// Entries or Rotation may be set.
+type CoinbaseSchedule struct {
+	// Entries switch the coinbase to Address from the block with timestamp
+	// Start onward. Blocks before the first entry use the etherbase.
+	Entries []CoinbaseScheduleEntry `json:"entries,omitempty"`
+
+	// Rotation is used round-robin, switching to the next address every
+	// RotationInterval seconds since the unix epoch. For example, an interval
+	// of 86400 rotates at midnight UTC.
+	Rotation         []common.Address `json:"rotation,omitempty"`
+	RotationInterval uint64           `json:"rotationInterval,omitempty"`
+}
+
+// CoinbaseScheduleEntry is an entry of a [CoinbaseSchedule].
+type CoinbaseScheduleEntry struct {
+	Start   uint64         `json:"start"`
+	Address common.Address `json:"address"`
+}
+
+// Verify returns an error if the schedule is malformed.
+func (s *CoinbaseSchedule) Verify() error {
+	if len(s.Entries) > 0 && len(s.Rotation) > 0 {
+		return errCoinbaseScheduleConflict
+	}
+	for i, entry := range s.Entries {
+		if entry.Address == (common.Address{}) {
+			return errZeroCoinbase
+		}
+		if i > 0 && entry.Start <= s.Entries[i-1].Start {
+			return fmt.Errorf("coinbase schedule entry %d starts at %d, not after the previous entry at %d", i, entry.Start, s.Entries[i-1].Start)
+		}
+	}
+	if len(s.Rotation) > 0 && s.RotationInterval == 0 {
+		return errZeroRotationInterval
+	}
+	for _, addr := range s.Rotation {
+		if addr == (common.Address{}) {
+			return errZeroCoinbase
+		}
+	}
+	return nil
+}
+
+// coinbaseAt returns the coinbase scheduled for a block with [timestamp], or
+// [etherbase] if none is.
+func (s *CoinbaseSchedule) coinbaseAt(timestamp uint64, etherbase common.Address) common.Address {
+	if len(s.Rotation) > 0 {
+		return s.Rotation[(timestamp/s.RotationInterval)%uint64(len(s.Rotation))]
+	}
+	coinbase := etherbase
+	for _, entry := range s.Entries {
+		if entry.Start > timestamp {
+			break
+		}
+		coinbase = entry.Address
+	}
+	return coinbase
+}
@@ -0,0 +1,87 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package miner
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestCoinbaseSchedule(t *testing.T) {
+	var (
+		etherbase = common.Address{19: 0xee}
+		a         = common.Address{19: 0xaa}
+		b         = common.Address{19: 0xbb}
+		c         = common.Address{19: 0xcc}
+	)
+	entries := &CoinbaseSchedule{Entries: []CoinbaseScheduleEntry{{Start: 100, Address: a}, {Start: 200, Address: b}}}
+	rotation := &CoinbaseSchedule{Rotation: []common.Address{a, b, c}, RotationInterval: 86400}
+	tests := []struct {
+		name      string
+		schedule  *CoinbaseSchedule
+		timestamp uint64
+		coinbase  common.Address
+	}{
+		{"before first entry", entries, 99, etherbase},
+		{"first entry", entries, 100, a},
+		{"between entries", entries, 199, a},
+		{"last entry", entries, 1000, b},
+		{"first day", rotation, 0, a},
+		{"second day", rotation, 86400, b},
+		{"end of third day", rotation, 3*86400 - 1, c},
+		{"wraps around", rotation, 3 * 86400, a},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.schedule.Verify(); err != nil {
+				t.Fatal(err)
+			}
+			if have := test.schedule.coinbaseAt(test.timestamp, etherbase); have != test.coinbase {
+				t.Fatalf("coinbase mismatch: have %s, want %s", have, test.coinbase)
+			}
+		})
+	}
+}
+
+func TestCoinbaseScheduleVerify(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule *CoinbaseSchedule
+		err      error
+	}{
+		{
+			name: "entries and rotation",
+			schedule: &CoinbaseSchedule{
+				Entries:          []CoinbaseScheduleEntry{{Start: 1, Address: common.Address{1}}},
+				Rotation:         []common.Address{{1}},
+				RotationInterval: 1,
+			},
+			err: errCoinbaseScheduleConflict,
+		},
+		{
+			name:     "zero interval",
+			schedule: &CoinbaseSchedule{Rotation: []common.Address{{1}}},
+			err:      errZeroRotationInterval,
+		},
+		{
+			name:     "zero address",
+			schedule: &CoinbaseSchedule{Entries: []CoinbaseScheduleEntry{{Start: 1}}},
+			err:      errZeroCoinbase,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.schedule.Verify(); !errors.Is(err, test.err) {
+				t.Fatalf("error mismatch: have %v, want %v", err, test.err)
+			}
+		})
+	}
+
+	unordered := &CoinbaseSchedule{Entries: []CoinbaseScheduleEntry{{Start: 2, Address: common.Address{1}}, {Start: 2, Address: common.Address{2}}}}
+	if err := unordered.Verify(); err == nil {
+		t.Fatal("expected error for unordered entries")
+	}
+}
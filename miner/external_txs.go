@@ -0,0 +1,87 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package miner
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/params"
+	"github.com/shubhamdubey02/coreth/precompile/precompileconfig"
+)
+
+var (
+	errExternalTxInvalid  = errors.New("invalid external transaction")
+	errExternalTxGas      = errors.New("not enough gas left in block")
+	errExternalTxBlobGas  = errors.New("not enough blob gas left in block")
+	errExternalTxSize     = errors.New("block would exceed target size")
+	errExternalTxSidecar  = errors.New("blob transaction without blobs")
+	errExternalTxReplayed = errors.New("replay protected transaction before EIP155")
+)
+
+// commitExternalWork builds a block on top of the current block containing
+// exactly [txs], in order, instead of transactions selected from the pool.
+// Returns an error identifying the first transaction which is invalid or does
+// not fit in the block. The incident filter, gas reservation, inclusion hook
+// and bundler do not apply to the block.
+func (w *worker) commitExternalWork(predicateContext *precompileconfig.PredicateContext, txs []*types.Transaction) (*types.Block, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	env, err := w.prepareWork(predicateContext)
+	if err != nil {
+		return nil, err
+	}
+	// Ensure we always stop prefetcher after block building is complete.
+	defer env.state.StopPrefetcher()
+
+	env.reservation, env.reservedGas = nil, 0
+	env.inclusionHook = nil
+	if err := w.commitExternalTransactions(env, txs, env.header.Coinbase); err != nil {
+		return nil, err
+	}
+	return w.commit(env)
+}
+
+// commitExternalTransactions commits [txs] in order, stopping at the first
+// transaction which cannot be committed.
+func (w *worker) commitExternalTransactions(env *environment, txs []*types.Transaction, coinbase common.Address) error {
+	for i, tx := range txs {
+		if err := w.checkExternalTransaction(env, tx); err != nil {
+			return fmt.Errorf("%w %d (%s): %w", errExternalTxInvalid, i, tx.Hash(), err)
+		}
+		env.state.SetTxContext(tx.Hash(), env.tcount)
+		if _, err := w.commitTransaction(env, tx, coinbase); err != nil {
+			return fmt.Errorf("%w %d (%s): %w", errExternalTxInvalid, i, tx.Hash(), err)
+		}
+		env.tcount++
+	}
+	return nil
+}
+
+// checkExternalTransaction returns an error if [tx] does not fit in the block
+// of [env], or cannot be committed to it. The validity of [tx] against the
+// state is checked when it is applied.
+func (w *worker) checkExternalTransaction(env *environment, tx *types.Transaction) error {
+	if left := env.gasPool.Gas(); left < tx.Gas() {
+		return fmt.Errorf("%w: left %d, needed %d", errExternalTxGas, left, tx.Gas())
+	}
+	if tx.Type() == types.BlobTxType {
+		if tx.BlobTxSidecar() == nil {
+			return errExternalTxSidecar
+		}
+		if left := uint64(params.MaxBlobGasPerBlock - env.blobs*params.BlobTxBlobGasPerBlob); left < tx.BlobGas() {
+			return fmt.Errorf("%w: left %d, needed %d", errExternalTxBlobGas, left, tx.BlobGas())
+		}
+	}
+	if totalTxsSize := env.size + tx.Size() + uint64(env.predicateResultsSize); totalTxsSize > targetTxsSize {
+		return fmt.Errorf("%w: %d > %d", errExternalTxSize, totalTxsSize, targetTxsSize)
+	}
+	if tx.Protected() && !w.chainConfig.IsEIP155(env.header.Number) {
+		return errExternalTxReplayed
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package miner
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/shubhamdubey02/coreth/core"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/params"
+)
+
+func TestCheckExternalTransaction(t *testing.T) {
+	preEIP155 := *params.TestChainConfig
+	preEIP155.EIP155Block = big.NewInt(10)
+
+	tests := []struct {
+		name   string
+		config *params.ChainConfig
+		tx     *types.Transaction
+		size   uint64
+		err    error
+	}{
+		{
+			name: "fits",
+			tx:   types.NewTx(&types.LegacyTx{Gas: params.TxGas}),
+		},
+		{
+			name: "exceeds gas",
+			tx:   types.NewTx(&types.LegacyTx{Gas: 2 * params.TxGas}),
+			err:  errExternalTxGas,
+		},
+		{
+			name: "exceeds size",
+			tx:   types.NewTx(&types.LegacyTx{Gas: params.TxGas}),
+			size: targetTxsSize,
+			err:  errExternalTxSize,
+		},
+		{
+			name: "blob tx without sidecar",
+			tx:   types.NewTx(&types.BlobTx{Gas: params.TxGas}),
+			err:  errExternalTxSidecar,
+		},
+		{
+			name:   "replay protected before EIP155",
+			config: &preEIP155,
+			tx:     types.NewTx(&types.DynamicFeeTx{Gas: params.TxGas}),
+			err:    errExternalTxReplayed,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := test.config
+			if config == nil {
+				config = params.TestChainConfig
+			}
+			w := &worker{chainConfig: config}
+			env := &environment{
+				header:  &types.Header{Number: big.NewInt(1)},
+				gasPool: new(core.GasPool).AddGas(params.TxGas + 1),
+				size:    test.size,
+			}
+			if err := w.checkExternalTransaction(env, test.tx); !errors.Is(err, test.err) {
+				t.Fatalf("error mismatch: have %v, want %v", err, test.err)
+			}
+		})
+	}
+}
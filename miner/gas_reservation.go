@@ -0,0 +1,64 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package miner
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	errGasReservationNoSenders = errors.New("gas reservation requires at least one sender")
+	errGasReservationZeroGas   = errors.New("gas reservation must reserve a positive amount of gas")
+	errZeroReservationSender   = errors.New("gas reservation cannot contain the zero address")
+)
+
+// GasReservation reserves Gas of every built block for the transactions of
+// Senders, such as oracle updates, so that they are included even when other
+// transactions outbid them for the rest of the block. Gas left unused by
+// Senders is not given to other transactions.
+type GasReservation struct {
+	Gas     uint64           `json:"gas"`
+	Senders []common.Address `json:"senders"`
+}
+
+// Verify returns an error if the reservation is malformed.
+func (r *GasReservation) Verify() error {
+	if r.Gas == 0 {
+		return errGasReservationZeroGas
+	}
+	if len(r.Senders) == 0 {
+		return errGasReservationNoSenders
+	}
+	for _, sender := range r.Senders {
+		if sender == (common.Address{}) {
+			return errZeroReservationSender
+		}
+	}
+	return nil
+}
+
+// gasReservation is a parsed [GasReservation].
+type gasReservation struct {
+	gas     uint64
+	senders map[common.Address]struct{}
+}
+
+func (r *GasReservation) parse() *gasReservation {
+	parsed := &gasReservation{
+		gas:     r.Gas,
+		senders: make(map[common.Address]struct{}, len(r.Senders)),
+	}
+	for _, sender := range r.Senders {
+		parsed.senders[sender] = struct{}{}
+	}
+	return parsed
+}
+
+// reserved returns whether [sender] may use the reserved gas.
+func (r *gasReservation) reserved(sender common.Address) bool {
+	_, ok := r.senders[sender]
+	return ok
+}
@@ -0,0 +1,51 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package miner
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestGasReservationVerify(t *testing.T) {
+	tests := []struct {
+		name        string
+		reservation *GasReservation
+		err         error
+	}{
+		{
+			name:        "valid",
+			reservation: &GasReservation{Gas: 100_000, Senders: []common.Address{{1}}},
+		},
+		{
+			name:        "zero gas",
+			reservation: &GasReservation{Senders: []common.Address{{1}}},
+			err:         errGasReservationZeroGas,
+		},
+		{
+			name:        "no senders",
+			reservation: &GasReservation{Gas: 100_000},
+			err:         errGasReservationNoSenders,
+		},
+		{
+			name:        "zero address",
+			reservation: &GasReservation{Gas: 100_000, Senders: []common.Address{{}}},
+			err:         errZeroReservationSender,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.reservation.Verify(); !errors.Is(err, test.err) {
+				t.Fatalf("error mismatch: have %v, want %v", err, test.err)
+			}
+		})
+	}
+
+	parsed := (&GasReservation{Gas: 1, Senders: []common.Address{{1}}}).parse()
+	if !parsed.reserved(common.Address{1}) || parsed.reserved(common.Address{2}) {
+		t.Fatal("reserved senders mismatch")
+	}
+}
@@ -0,0 +1,85 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package miner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/shubhamdubey02/coreth/core/types"
+)
+
+// selectorLength is the length of a function selector in calldata.
+const selectorLength = 4
+
+// IncidentFilter lists the addresses and function selectors of a protocol
+// involved in an incident. Transactions interacting with them are excluded
+// from locally built blocks until the filter is cleared.
+type IncidentFilter struct {
+	Addresses []common.Address `json:"addresses"`
+	Selectors []hexutil.Bytes  `json:"selectors"`
+}
+
+// ReadIncidentFilter reads an [IncidentFilter] from the JSON file at [path].
+func ReadIncidentFilter(path string) (*IncidentFilter, error) {
+	filterBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read incident filter %s: %w", path, err)
+	}
+	filter := new(IncidentFilter)
+	if err := json.Unmarshal(filterBytes, filter); err != nil {
+		return nil, fmt.Errorf("failed to parse incident filter %s: %w", path, err)
+	}
+	return filter, nil
+}
+
+// incidentFilter is a parsed [IncidentFilter].
+type incidentFilter struct {
+	addresses map[common.Address]struct{}
+	selectors map[[selectorLength]byte]struct{}
+}
+
+func (f *IncidentFilter) parse() (*incidentFilter, error) {
+	parsed := &incidentFilter{
+		addresses: make(map[common.Address]struct{}, len(f.Addresses)),
+		selectors: make(map[[selectorLength]byte]struct{}, len(f.Selectors)),
+	}
+	for _, addr := range f.Addresses {
+		parsed.addresses[addr] = struct{}{}
+	}
+	for _, selector := range f.Selectors {
+		if len(selector) != selectorLength {
+			return nil, fmt.Errorf("invalid selector %s: expected %d bytes", selector, selectorLength)
+		}
+		parsed.selectors[[selectorLength]byte(selector)] = struct{}{}
+	}
+	return parsed, nil
+}
+
+// match returns a description of why [tx] sent by [from] interacts with the
+// filtered addresses or selectors, or the empty string if it does not.
+func (f *incidentFilter) match(from common.Address, tx *types.Transaction) string {
+	if _, ok := f.addresses[from]; ok {
+		return fmt.Sprintf("sender %s", from)
+	}
+	if to := tx.To(); to != nil {
+		if _, ok := f.addresses[*to]; ok {
+			return fmt.Sprintf("recipient %s", to)
+		}
+	}
+	for _, tuple := range tx.AccessList() {
+		if _, ok := f.addresses[tuple.Address]; ok {
+			return fmt.Sprintf("access list entry %s", tuple.Address)
+		}
+	}
+	if data := tx.Data(); len(data) >= selectorLength {
+		if _, ok := f.selectors[[selectorLength]byte(data)]; ok {
+			return fmt.Sprintf("selector %s", hexutil.Bytes(data[:selectorLength]))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,58 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package miner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/shubhamdubey02/coreth/core/types"
+)
+
+func TestIncidentFilter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "incident.json")
+	contents := `{"addresses": ["0x00000000000000000000000000000000000000aa"], "selectors": ["0xa9059cbb"]}`
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	filter, err := ReadIncidentFilter(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := filter.parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		flagged = common.Address{19: 0xaa}
+		other   = common.Address{19: 0xbb}
+	)
+	tests := []struct {
+		name    string
+		from    common.Address
+		tx      types.TxData
+		matches bool
+	}{
+		{"unrelated", other, &types.LegacyTx{To: &other}, false},
+		{"sender", flagged, &types.LegacyTx{To: &other}, true},
+		{"recipient", other, &types.LegacyTx{To: &flagged}, true},
+		{"access list", other, &types.AccessListTx{To: &other, AccessList: types.AccessList{{Address: flagged}}}, true},
+		{"selector", other, &types.LegacyTx{To: &other, Data: common.FromHex("0xa9059cbb0000")}, true},
+		{"short calldata", other, &types.LegacyTx{To: &other, Data: common.FromHex("0xa905")}, false},
+	}
+	for _, test := range tests {
+		if reason := parsed.match(test.from, types.NewTx(test.tx)); (reason != "") != test.matches {
+			t.Errorf("%s: expected match %t, got %q", test.name, test.matches, reason)
+		}
+	}
+
+	invalid := &IncidentFilter{Selectors: []hexutil.Bytes{{0x01, 0x02}}}
+	if _, err := invalid.parse(); err == nil {
+		t.Fatal("expected error for short selector")
+	}
+}
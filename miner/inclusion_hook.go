@@ -0,0 +1,95 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package miner
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shubhamdubey02/coreth/core/types"
+)
+
+var (
+	errInclusionSkipped  = errors.New("transaction skipped by inclusion hook")
+	errInclusionDeferred = errors.New("transaction deferred by inclusion hook")
+)
+
+// InclusionDecision is the decision of an [InclusionHook] on a candidate
+// transaction.
+type InclusionDecision uint8
+
+const (
+	// InclusionInclude keeps the transaction in the block.
+	InclusionInclude InclusionDecision = iota
+	// InclusionSkip excludes the transaction and the following transactions
+	// of its sender from the block.
+	InclusionSkip
+	// InclusionDefer moves the transaction after the remaining candidates,
+	// where it is simulated again on top of them. The following transactions
+	// of its sender are excluded from the block. A transaction is deferred at
+	// most once, deferring it again skips it.
+	InclusionDefer
+)
+
+func (d InclusionDecision) String() string {
+	switch d {
+	case InclusionInclude:
+		return "include"
+	case InclusionSkip:
+		return "skip"
+	case InclusionDefer:
+		return "defer"
+	default:
+		return "unknown"
+	}
+}
+
+// InclusionCandidate is the simulated outcome of executing a candidate
+// transaction on top of the transactions already in the block.
+type InclusionCandidate struct {
+	Tx      *types.Transaction
+	Sender  common.Address
+	Receipt *types.Receipt
+	// CoinbaseValue is the change of the coinbase balance caused by the
+	// transaction, including its fees.
+	CoinbaseValue *big.Int
+	// Deferred is set if the transaction was deferred before.
+	Deferred bool
+}
+
+// InclusionHook decides the inclusion of the transactions of locally built
+// blocks from their simulated outcome, allowing in-process modules to
+// experiment with value-aware ordering without changes to the worker.
+//
+// Decide is called on the goroutine building the block, after each candidate
+// transaction executed successfully, so it must return quickly. The state of
+// the block is reverted before the transaction is skipped or deferred.
+type InclusionHook interface {
+	Decide(header *types.Header, candidate *InclusionCandidate) InclusionDecision
+}
+
+// InclusionHookFunc is an adapter allowing a function to be used as an
+// [InclusionHook].
+type InclusionHookFunc func(header *types.Header, candidate *InclusionCandidate) InclusionDecision
+
+// Decide implements InclusionHook.
+func (f InclusionHookFunc) Decide(header *types.Header, candidate *InclusionCandidate) InclusionDecision {
+	return f(header, candidate)
+}
+
+// inclusionError returns the error reverting a candidate transaction the
+// hook decided on [decision] for, or nil if the transaction is included.
+// Deferring an already [deferred] transaction, or an unknown decision, skips
+// it.
+func inclusionError(decision InclusionDecision, deferred bool) error {
+	switch {
+	case decision == InclusionInclude:
+		return nil
+	case decision == InclusionDefer && !deferred:
+		return errInclusionDeferred
+	default:
+		return errInclusionSkipped
+	}
+}
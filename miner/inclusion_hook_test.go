@@ -0,0 +1,29 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package miner
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestInclusionError(t *testing.T) {
+	tests := []struct {
+		decision InclusionDecision
+		deferred bool
+		err      error
+	}{
+		{decision: InclusionInclude},
+		{decision: InclusionInclude, deferred: true},
+		{decision: InclusionSkip, err: errInclusionSkipped},
+		{decision: InclusionDefer, err: errInclusionDeferred},
+		{decision: InclusionDefer, deferred: true, err: errInclusionSkipped},
+		{decision: InclusionDefer + 1, err: errInclusionSkipped},
+	}
+	for _, test := range tests {
+		if err := inclusionError(test.decision, test.deferred); !errors.Is(err, test.err) {
+			t.Fatalf("%s (deferred: %t): have %v, want %v", test.decision, test.deferred, err, test.err)
+		}
+	}
+}
@@ -48,6 +48,25 @@ type Backend interface {
 // Config is the configuration parameters of mining.
 type Config struct {
 	Etherbase common.Address `toml:",omitempty"` // Public address for block mining rewards
+
+	// CoinbaseSchedule, if set, overrides Etherbase for blocks it schedules a
+	// coinbase for.
+	CoinbaseSchedule *CoinbaseSchedule `toml:",omitempty"`
+
+	// GasReservation, if set, reserves gas of built blocks for the
+	// transactions of system addresses.
+	GasReservation *GasReservation `toml:",omitempty"`
+
+	// OrderingSeed, if set, breaks ties between transactions paying the same
+	// tip by a hash of the seed and the transaction hash instead of the time
+	// they were first seen, so that integration tests and replay tooling
+	// build the same blocks from the same transactions. It is not meant for
+	// production block building.
+	OrderingSeed *uint64 `toml:",omitempty"`
+
+	// Aging, if set, boosts the priority of transactions pending for long
+	// when selecting the transactions of built blocks.
+	Aging *AgingPolicy `toml:",omitempty"`
 }
 
 type Miner struct {
@@ -64,10 +83,54 @@ func (miner *Miner) SetEtherbase(addr common.Address) {
 	miner.worker.setEtherbase(addr)
 }
 
+// SetCoinbaseSchedule replaces the schedule of coinbases of subsequently built
+// blocks. A nil [schedule] reverts to the etherbase.
+func (miner *Miner) SetCoinbaseSchedule(schedule *CoinbaseSchedule) error {
+	return miner.worker.setCoinbaseSchedule(schedule)
+}
+
+// SetGasReservation replaces the reservation of gas of subsequently built
+// blocks. A nil [reservation] clears it.
+func (miner *Miner) SetGasReservation(reservation *GasReservation) error {
+	return miner.worker.setGasReservation(reservation)
+}
+
+// ActiveCoinbase returns the coinbase of a block built at the current time.
+func (miner *Miner) ActiveCoinbase() common.Address {
+	return miner.worker.activeCoinbase()
+}
+
+// SetIncidentFilter excludes transactions matching [filter] from subsequently
+// built blocks. A nil [filter] stops excluding transactions.
+func (miner *Miner) SetIncidentFilter(filter *IncidentFilter) error {
+	return miner.worker.setIncidentFilter(filter)
+}
+
+// SetInclusionHook consults [hook] on the inclusion of each transaction of
+// subsequently built blocks. A nil [hook] includes transactions unconditionally.
+func (miner *Miner) SetInclusionHook(hook InclusionHook) {
+	miner.worker.setInclusionHook(hook)
+}
+
+// SetBundler commits the transaction supplied by [bundler] ahead of the
+// transactions of the pool in subsequently built blocks. A nil [bundler]
+// stops committing bundles.
+func (miner *Miner) SetBundler(bundler Bundler) {
+	miner.worker.setBundler(bundler)
+}
+
 func (miner *Miner) GenerateBlock(predicateContext *precompileconfig.PredicateContext) (*types.Block, error) {
 	return miner.worker.commitNewWork(predicateContext)
 }
 
+// GenerateBlockWithTxs builds a block containing exactly [txs], in order,
+// instead of transactions selected from the pool, for replaying blocks and
+// for transaction ordering supplied by external services. Returns an error
+// if any transaction is invalid or does not fit in the block.
+func (miner *Miner) GenerateBlockWithTxs(predicateContext *precompileconfig.PredicateContext, txs []*types.Transaction) (*types.Block, error) {
+	return miner.worker.commitExternalWork(predicateContext, txs)
+}
+
 // SubscribePendingLogs starts delivering logs from pending transactions
 // to the given channel.
 func (miner *Miner) SubscribePendingLogs(ch chan<- []*types.Log) event.Subscription {
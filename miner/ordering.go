@@ -27,11 +27,15 @@
 package miner
 
 import (
+	"bytes"
 	"container/heap"
+	"encoding/binary"
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/shubhamdubey02/coreth/core/txpool"
 	"github.com/shubhamdubey02/coreth/core/types"
 )
@@ -41,12 +45,19 @@ type txWithMinerFee struct {
 	tx   *txpool.LazyTransaction
 	from common.Address
 	fees *big.Int
+	// priority orders the transaction, which is its fees unless boosted by
+	// an aging policy.
+	priority *big.Int
+
+	// rank breaks ties between transactions with the same fees in place of
+	// the time they were first seen, if the ordering is seeded.
+	rank *common.Hash
 }
 
 // newTxWithMinerFee creates a wrapped transaction, calculating the effective
 // miner gasTipCap if a base fee is provided.
 // Returns error in case of a negative effective miner gasTipCap.
-func newTxWithMinerFee(tx *txpool.LazyTransaction, from common.Address, baseFee *big.Int) (*txWithMinerFee, error) {
+func newTxWithMinerFee(tx *txpool.LazyTransaction, from common.Address, baseFee *big.Int, seed *uint64) (*txWithMinerFee, error) {
 	tip := new(big.Int).Set(tx.GasTipCap)
 	if baseFee != nil {
 		if tx.GasFeeCap.Cmp(baseFee) < 0 {
@@ -54,11 +65,17 @@ func newTxWithMinerFee(tx *txpool.LazyTransaction, from common.Address, baseFee
 		}
 		tip = math.BigMin(tx.GasTipCap, new(big.Int).Sub(tx.GasFeeCap, baseFee))
 	}
-	return &txWithMinerFee{
-		tx:   tx,
-		from: from,
-		fees: tip,
-	}, nil
+	wrapped := &txWithMinerFee{
+		tx:       tx,
+		from:     from,
+		fees:     tip,
+		priority: tip,
+	}
+	if seed != nil {
+		rank := crypto.Keccak256Hash(binary.BigEndian.AppendUint64(nil, *seed), tx.Hash.Bytes())
+		wrapped.rank = &rank
+	}
+	return wrapped, nil
 }
 
 // txByPriceAndTime implements both the sort and the heap interface, making it useful
@@ -69,8 +86,11 @@ func (s txByPriceAndTime) Len() int { return len(s) }
 func (s txByPriceAndTime) Less(i, j int) bool {
 	// If the prices are equal, use the time the transaction was first seen for
 	// deterministic sorting
-	cmp := s[i].fees.Cmp(s[j].fees)
+	cmp := s[i].priority.Cmp(s[j].priority)
 	if cmp == 0 {
+		if s[i].rank != nil && s[j].rank != nil {
+			return bytes.Compare(s[i].rank[:], s[j].rank[:]) < 0
+		}
 		return s[i].tx.Time.Before(s[j].tx.Time)
 	}
 	return cmp > 0
@@ -98,6 +118,9 @@ type transactionsByPriceAndNonce struct {
 	heads   txByPriceAndTime                             // Next transaction for each unique account (price heap)
 	signer  types.Signer                                 // Signer for the set of transactions
 	baseFee *big.Int                                     // Current base fee
+	seed    *uint64                                      // Seed breaking ties between equal fees, nil to use arrival time
+	aging   *AgingPolicy                                 // Policy boosting long pending transactions, nil to order by fees
+	now     time.Time                                    // Time the ages of transactions are measured at
 }
 
 // newTransactionsByPriceAndNonce creates a transaction set that can retrieve
@@ -106,10 +129,35 @@ type transactionsByPriceAndNonce struct {
 // Note, the input map is reowned so the caller should not interact any more with
 // if after providing it to the constructor.
 func newTransactionsByPriceAndNonce(signer types.Signer, txs map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int) *transactionsByPriceAndNonce {
+	return newSeededTransactionsByPriceAndNonce(signer, txs, baseFee, nil)
+}
+
+// newSeededTransactionsByPriceAndNonce is newTransactionsByPriceAndNonce
+// breaking ties between transactions with the same fees by a hash of [seed]
+// and the transaction hash rather than by the time they were first seen, so
+// that the same pool always yields the same order. A nil [seed] orders ties
+// by time.
+func newSeededTransactionsByPriceAndNonce(signer types.Signer, txs map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int, seed *uint64) *transactionsByPriceAndNonce {
+	return newAgedTransactionsByPriceAndNonce(signer, txs, baseFee, seed, nil, time.Time{})
+}
+
+// newAgedTransactionsByPriceAndNonce is newSeededTransactionsByPriceAndNonce
+// ordering the transactions pending for longer than the threshold of [aging]
+// at [now] by their boosted priority. A nil [aging] orders transactions by
+// fees.
+func newAgedTransactionsByPriceAndNonce(signer types.Signer, txs map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int, seed *uint64, aging *AgingPolicy, now time.Time) *transactionsByPriceAndNonce {
+	set := &transactionsByPriceAndNonce{
+		txs:     txs,
+		signer:  signer,
+		baseFee: baseFee,
+		seed:    seed,
+		aging:   aging,
+		now:     now,
+	}
 	// Initialize a price and received time based heap with the head transactions
 	heads := make(txByPriceAndTime, 0, len(txs))
 	for from, accTxs := range txs {
-		wrapped, err := newTxWithMinerFee(accTxs[0], from, baseFee)
+		wrapped, err := set.wrap(accTxs[0], from)
 		if err != nil {
 			delete(txs, from)
 			continue
@@ -118,14 +166,18 @@ func newTransactionsByPriceAndNonce(signer types.Signer, txs map[common.Address]
 		txs[from] = accTxs[1:]
 	}
 	heap.Init(&heads)
+	set.heads = heads
+	return set
+}
 
-	// Assemble and return the transaction set
-	return &transactionsByPriceAndNonce{
-		txs:     txs,
-		heads:   heads,
-		signer:  signer,
-		baseFee: baseFee,
+// wrap wraps [tx] sent by [from] with its fees and priority.
+func (t *transactionsByPriceAndNonce) wrap(tx *txpool.LazyTransaction, from common.Address) (*txWithMinerFee, error) {
+	wrapped, err := newTxWithMinerFee(tx, from, t.baseFee, t.seed)
+	if err != nil {
+		return nil, err
 	}
+	wrapped.priority = t.aging.priority(wrapped.fees, t.now.Sub(tx.Time))
+	return wrapped, nil
 }
 
 // Peek returns the next transaction by price.
@@ -140,7 +192,7 @@ func (t *transactionsByPriceAndNonce) Peek() *txpool.LazyTransaction {
 func (t *transactionsByPriceAndNonce) Shift() {
 	acc := t.heads[0].from
 	if txs, ok := t.txs[acc]; ok && len(txs) > 0 {
-		if wrapped, err := newTxWithMinerFee(txs[0], acc, t.baseFee); err == nil {
+		if wrapped, err := t.wrap(txs[0], acc); err == nil {
 			t.heads[0], t.txs[acc] = wrapped, txs[1:]
 			heap.Fix(&t.heads, 0)
 			return
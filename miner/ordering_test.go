@@ -203,3 +203,65 @@ func TestTransactionTimeSort(t *testing.T) {
 		}
 	}
 }
+
+// Tests that a seeded ordering breaks ties between transactions with the same
+// price identically, whatever the time they were first seen.
+func TestTransactionSeededSort(t *testing.T) {
+	t.Parallel()
+	// Generate a batch of accounts to start with
+	keys := make([]*ecdsa.PrivateKey, 8)
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	signer := types.HomesteadSigner{}
+
+	txs := make([]*types.Transaction, len(keys))
+	for i, key := range keys {
+		txs[i], _ = types.SignTx(types.NewTransaction(0, common.Address{}, big.NewInt(100), 100, big.NewInt(1), nil), signer, key)
+	}
+	order := func(seed uint64, arrival func(i int) time.Time) []common.Hash {
+		groups := map[common.Address][]*txpool.LazyTransaction{}
+		for i, tx := range txs {
+			from, _ := types.Sender(signer, tx)
+			groups[from] = []*txpool.LazyTransaction{{
+				Hash:      tx.Hash(),
+				Tx:        tx,
+				Time:      arrival(i),
+				GasFeeCap: tx.GasFeeCap(),
+				GasTipCap: tx.GasTipCap(),
+				Gas:       tx.Gas(),
+			}}
+		}
+		txset := newSeededTransactionsByPriceAndNonce(signer, groups, nil, &seed)
+
+		var hashes []common.Hash
+		for tx := txset.Peek(); tx != nil; tx = txset.Peek() {
+			hashes = append(hashes, tx.Hash)
+			txset.Shift()
+		}
+		return hashes
+	}
+	ascending := func(i int) time.Time { return time.Unix(int64(i), 0) }
+	descending := func(i int) time.Time { return time.Unix(int64(len(txs)-i), 0) }
+
+	first := order(1, ascending)
+	if len(first) != len(txs) {
+		t.Fatalf("expected %d transactions, found %d", len(txs), len(first))
+	}
+	for i, hash := range order(1, descending) {
+		if hash != first[i] {
+			t.Errorf("tx #%d: expected %x with the same seed, found %x", i, first[i], hash)
+		}
+	}
+	// Another seed orders the transactions by other ranks.
+	second := order(2, ascending)
+	for i := 0; i+1 < len(second); i++ {
+		var seed [8]byte
+		seed[7] = 2
+		rank := crypto.Keccak256Hash(seed[:], second[i].Bytes())
+		next := crypto.Keccak256Hash(seed[:], second[i+1].Bytes())
+		if rank.Cmp(next) >= 0 {
+			t.Errorf("invalid seeded ordering: tx #%d (R=%x) >= tx #%d (R=%x)", i, rank, i+1, next)
+		}
+	}
+}
@@ -33,7 +33,9 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -60,6 +62,8 @@ const (
 	targetTxsSize = 1792 * units.KiB
 )
 
+var errPredicateResultsCapacity = errors.New("insufficient capacity for predicate results")
+
 // environment is the worker's current environment and holds all of the current state information.
 type environment struct {
 	signer  types.Signer
@@ -75,6 +79,20 @@ type environment struct {
 	blobs    int
 	size     uint64
 
+	// predicateResultsSize is the number of bytes the predicate results will
+	// add to the header Extra field.
+	predicateResultsSize int
+
+	// reservation is the gas reservation of the block, nil if none, and
+	// reservedGas the reserved gas not yet used by its senders.
+	reservation *gasReservation
+	reservedGas uint64
+
+	// inclusionHook decides the inclusion of transactions, nil if none, and
+	// deferred is set while deferred transactions are committed.
+	inclusionHook InclusionHook
+	deferred      bool
+
 	rules            params.Rules
 	predicateContext *precompileconfig.PredicateContext
 	// predicateResults contains the results of checking the predicates for each transaction in the miner.
@@ -105,6 +123,18 @@ type worker struct {
 	coinbase   common.Address
 	clock      *mockable.Clock // Allows us mock the clock for testing
 	beaconRoot *common.Hash    // TODO: set to empty hash, retained for upstream compatibility and future use
+
+	// incidentFilter excludes transactions from built blocks. Nil if unset.
+	incidentFilter atomic.Pointer[incidentFilter]
+	// coinbaseSchedule overrides the coinbase of built blocks. Nil if unset.
+	coinbaseSchedule atomic.Pointer[CoinbaseSchedule]
+	// gasReservation reserves gas of built blocks. Nil if unset.
+	gasReservation atomic.Pointer[gasReservation]
+	// inclusionHook decides the inclusion of transactions. Nil if unset.
+	inclusionHook atomic.Pointer[InclusionHook]
+	// bundler supplies a transaction committed ahead of the pool. Nil if
+	// unset.
+	bundler atomic.Pointer[Bundler]
 }
 
 func newWorker(config *Config, chainConfig *params.ChainConfig, engine consensus.Engine, eth Backend, mux *event.TypeMux, clock *mockable.Clock) *worker {
@@ -119,10 +149,51 @@ func newWorker(config *Config, chainConfig *params.ChainConfig, engine consensus
 		clock:       clock,
 		beaconRoot:  &common.Hash{},
 	}
+	if err := worker.setCoinbaseSchedule(config.CoinbaseSchedule); err != nil {
+		log.Error("Ignoring invalid coinbase schedule", "err", err)
+	}
+	if err := worker.setGasReservation(config.GasReservation); err != nil {
+		log.Error("Ignoring invalid gas reservation", "err", err)
+	}
 
 	return worker
 }
 
+// setIncidentFilter replaces the filter of transactions excluded from built
+// blocks. A nil [filter] clears it.
+func (w *worker) setIncidentFilter(filter *IncidentFilter) error {
+	if filter == nil {
+		w.incidentFilter.Store(nil)
+		return nil
+	}
+	parsed, err := filter.parse()
+	if err != nil {
+		return err
+	}
+	w.incidentFilter.Store(parsed)
+	return nil
+}
+
+// setInclusionHook replaces the hook deciding the inclusion of transactions
+// in built blocks. A nil [hook] clears it.
+func (w *worker) setInclusionHook(hook InclusionHook) {
+	if hook == nil {
+		w.inclusionHook.Store(nil)
+		return
+	}
+	w.inclusionHook.Store(&hook)
+}
+
+// setBundler replaces the bundler supplying a transaction committed ahead of
+// the pool. A nil [bundler] clears it.
+func (w *worker) setBundler(bundler Bundler) {
+	if bundler == nil {
+		w.bundler.Store(nil)
+		return
+	}
+	w.bundler.Store(&bundler)
+}
+
 // setEtherbase sets the etherbase used to initialize the block coinbase field.
 func (w *worker) setEtherbase(addr common.Address) {
 	w.mu.Lock()
@@ -130,11 +201,105 @@ func (w *worker) setEtherbase(addr common.Address) {
 	w.coinbase = addr
 }
 
+// setCoinbaseSchedule replaces the schedule overriding the coinbase of built
+// blocks. A nil [schedule] clears it.
+func (w *worker) setCoinbaseSchedule(schedule *CoinbaseSchedule) error {
+	if schedule == nil {
+		w.coinbaseSchedule.Store(nil)
+		return nil
+	}
+	if err := schedule.Verify(); err != nil {
+		return err
+	}
+	w.coinbaseSchedule.Store(&CoinbaseSchedule{
+		Entries:          slices.Clone(schedule.Entries),
+		Rotation:         slices.Clone(schedule.Rotation),
+		RotationInterval: schedule.RotationInterval,
+	})
+	return nil
+}
+
+// setGasReservation replaces the reservation of gas of built blocks. A nil
+// [reservation] clears it.
+func (w *worker) setGasReservation(reservation *GasReservation) error {
+	if reservation == nil {
+		w.gasReservation.Store(nil)
+		return nil
+	}
+	if err := reservation.Verify(); err != nil {
+		return err
+	}
+	w.gasReservation.Store(reservation.parse())
+	return nil
+}
+
+// activeCoinbase returns the coinbase of a block built at the current time.
+func (w *worker) activeCoinbase() common.Address {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	timestamp := uint64(w.clock.Time().Unix())
+	if parent := w.chain.CurrentBlock(); parent.Time > timestamp {
+		timestamp = parent.Time
+	}
+	return w.coinbaseAt(timestamp)
+}
+
+// coinbaseAt returns the coinbase of a block with [timestamp]. Assumes [w.mu]
+// is held.
+func (w *worker) coinbaseAt(timestamp uint64) common.Address {
+	if schedule := w.coinbaseSchedule.Load(); schedule != nil {
+		return schedule.coinbaseAt(timestamp, w.coinbase)
+	}
+	return w.coinbase
+}
+
 // commitNewWork generates several new sealing tasks based on the parent block.
 func (w *worker) commitNewWork(predicateContext *precompileconfig.PredicateContext) (*types.Block, error) {
 	w.mu.RLock()
 	defer w.mu.RUnlock()
 
+	env, err := w.prepareWork(predicateContext)
+	if err != nil {
+		return nil, err
+	}
+	// Ensure we always stop prefetcher after block building is complete.
+	defer env.state.StopPrefetcher()
+
+	pending := w.eth.TxPool().PendingWithBaseFee(true, env.header.BaseFee)
+
+	// Split the pending transactions into locals and remotes.
+	localTxs, remoteTxs := make(map[common.Address][]*txpool.LazyTransaction), pending
+	for _, account := range w.eth.TxPool().Locals() {
+		if txs := remoteTxs[account]; len(txs) > 0 {
+			delete(remoteTxs, account)
+			localTxs[account] = txs
+		}
+	}
+
+	// Commit the bundle ahead of the pool, as its operations were validated
+	// on top of the parent state.
+	if bundler := w.bundler.Load(); bundler != nil {
+		w.commitBundle(env, *bundler, env.header.Coinbase)
+	}
+	// Fill the block with all available pending transactions.
+	if len(localTxs) > 0 {
+		txs := w.newTransactionSet(env, localTxs)
+		w.commitTransactions(env, txs, env.header.Coinbase)
+	}
+	if len(remoteTxs) > 0 {
+		txs := w.newTransactionSet(env, remoteTxs)
+		w.commitTransactions(env, txs, env.header.Coinbase)
+	}
+
+	return w.commit(env)
+}
+
+// prepareWork returns the environment of a block built on top of the current
+// block, with the upgrades activated by the block applied. The prefetcher of
+// the returned environment must be stopped by the caller. Assumes [w.mu] is
+// held.
+func (w *worker) prepareWork(predicateContext *precompileconfig.PredicateContext) (*environment, error) {
 	tstart := w.clock.Time()
 	timestamp := uint64(tstart.Unix())
 	parent := w.chain.CurrentBlock()
@@ -185,10 +350,10 @@ func (w *worker) commitNewWork(predicateContext *precompileconfig.PredicateConte
 		header.ParentBeaconRoot = w.beaconRoot
 	}
 
-	if w.coinbase == (common.Address{}) {
+	header.Coinbase = w.coinbaseAt(timestamp)
+	if header.Coinbase == (common.Address{}) {
 		return nil, errors.New("cannot mine without etherbase")
 	}
-	header.Coinbase = w.coinbase
 	if err := w.engine.Prepare(w.chain, header); err != nil {
 		return nil, fmt.Errorf("failed to prepare header for mining: %w", err)
 	}
@@ -202,42 +367,26 @@ func (w *worker) commitNewWork(predicateContext *precompileconfig.PredicateConte
 		vmenv := vm.NewEVM(context, vm.TxContext{}, env.state, w.chainConfig, vm.Config{})
 		core.ProcessBeaconBlockRoot(*header.ParentBeaconRoot, vmenv, env.state)
 	}
-	// Ensure we always stop prefetcher after block building is complete.
-	defer func() {
-		if env.state == nil {
-			return
-		}
-		env.state.StopPrefetcher()
-	}()
 	// Configure any upgrades that should go into effect during this block.
 	err = core.ApplyUpgrades(w.chainConfig, &parent.Time, types.NewBlockWithHeader(header), env.state)
 	if err != nil {
+		env.state.StopPrefetcher()
 		log.Error("failed to configure precompiles mining new block", "parent", parent.Hash(), "number", header.Number, "timestamp", header.Time, "err", err)
 		return nil, err
 	}
-
-	pending := w.eth.TxPool().PendingWithBaseFee(true, header.BaseFee)
-
-	// Split the pending transactions into locals and remotes.
-	localTxs, remoteTxs := make(map[common.Address][]*txpool.LazyTransaction), pending
-	for _, account := range w.eth.TxPool().Locals() {
-		if txs := remoteTxs[account]; len(txs) > 0 {
-			delete(remoteTxs, account)
-			localTxs[account] = txs
+	if w.chainConfig.IsStorageExpiry(header.Time) {
+		if err := core.ApplyStorageExpiry(w.chain, parent, header.Time, env.state); err != nil {
+			env.state.StopPrefetcher()
+			return nil, fmt.Errorf("failed to expire storage: %w", err)
 		}
 	}
+	return env, nil
+}
 
-	// Fill the block with all available pending transactions.
-	if len(localTxs) > 0 {
-		txs := newTransactionsByPriceAndNonce(env.signer, localTxs, header.BaseFee)
-		w.commitTransactions(env, txs, header.Coinbase)
-	}
-	if len(remoteTxs) > 0 {
-		txs := newTransactionsByPriceAndNonce(env.signer, remoteTxs, header.BaseFee)
-		w.commitTransactions(env, txs, header.Coinbase)
-	}
-
-	return w.commit(env)
+// newTransactionSet orders [txs] for inclusion in the block of [env] as
+// configured.
+func (w *worker) newTransactionSet(env *environment, txs map[common.Address][]*txpool.LazyTransaction) *transactionsByPriceAndNonce {
+	return newAgedTransactionsByPriceAndNonce(env.signer, txs, env.header.BaseFee, w.config.OrderingSeed, w.config.Aging, w.clock.Time())
 }
 
 func (w *worker) createCurrentEnvironment(predicateContext *precompileconfig.PredicateContext, parent *types.Header, header *types.Header, tstart time.Time) (*environment, error) {
@@ -245,7 +394,18 @@ func (w *worker) createCurrentEnvironment(predicateContext *precompileconfig.Pre
 	if err != nil {
 		return nil, err
 	}
-	state.StartPrefetcher("miner", w.eth.BlockChain().CacheConfig().TriePrefetcherParallelism)
+	state.StartTunedPrefetcher("miner", w.eth.BlockChain().PrefetcherTuner())
+	var (
+		reservation = w.gasReservation.Load()
+		reservedGas uint64
+	)
+	if reservation != nil {
+		reservedGas = min(reservation.gas, header.GasLimit)
+	}
+	var inclusionHook InclusionHook
+	if hook := w.inclusionHook.Load(); hook != nil {
+		inclusionHook = *hook
+	}
 	return &environment{
 		signer:           types.MakeSigner(w.chainConfig, header.Number, header.Time),
 		state:            state,
@@ -256,7 +416,13 @@ func (w *worker) createCurrentEnvironment(predicateContext *precompileconfig.Pre
 		rules:            w.chainConfig.Rules(header.Number, header.Time),
 		predicateContext: predicateContext,
 		predicateResults: predicate.NewResults(),
-		start:            tstart,
+		// predicateResultsSize starts at the size of empty results since these
+		// are always appended to Extra after Durango.
+		predicateResultsSize: predicate.NewResults().Size(),
+		reservation:          reservation,
+		reservedGas:          reservedGas,
+		inclusionHook:        inclusionHook,
+		start:                tstart,
 	}, nil
 }
 
@@ -270,6 +436,10 @@ func (w *worker) commitTransaction(env *environment, tx *types.Transaction, coin
 	}
 	env.txs = append(env.txs, tx)
 	env.receipts = append(env.receipts, receipt)
+	// Without accumulating the size of the committed transactions, the target
+	// size checks would only bound each transaction on its own, and predicate
+	// results could be added to an already full block.
+	env.size += tx.Size()
 	return receipt.Logs, nil
 }
 
@@ -297,13 +467,20 @@ func (w *worker) commitBlobTransaction(env *environment, tx *types.Transaction,
 	return receipt.Logs, nil
 }
 
-// applyTransaction runs the transaction. If execution fails, state and gas pool are reverted.
+// applyTransaction runs the transaction. If execution fails, or the inclusion
+// hook rejects the transaction, state and gas pool are reverted.
 func (w *worker) applyTransaction(env *environment, tx *types.Transaction, coinbase common.Address) (*types.Receipt, error) {
 	var (
-		snap         = env.state.Snapshot()
-		gp           = env.gasPool.Gas()
-		blockContext vm.BlockContext
+		snap          = env.state.Snapshot()
+		gp            = env.gasPool.Gas()
+		gasUsed       = env.header.GasUsed
+		balance       *big.Int
+		blockContext  vm.BlockContext
+		txResultsSize int
 	)
+	if env.inclusionHook != nil {
+		balance = env.state.GetBalance(coinbase)
+	}
 
 	if env.rules.IsDurango {
 		results, err := core.CheckPredicates(env.rules, env.predicateContext, tx)
@@ -311,6 +488,15 @@ func (w *worker) applyTransaction(env *environment, tx *types.Transaction, coinb
 			log.Debug("Transaction predicate failed verification in miner", "tx", tx.Hash(), "err", err)
 			return nil, err
 		}
+		// Defer transactions whose predicate results would not fit in the
+		// block, since the results are appended to the header Extra field.
+		txResultsSize = predicate.TxResultsSize(results)
+		if txResultsSize > 0 {
+			resultsSize := env.predicateResultsSize + txResultsSize
+			if resultsSize > predicate.MaxResultsSize || env.size+tx.Size()+uint64(resultsSize) > targetTxsSize {
+				return nil, fmt.Errorf("%w: tx %s requires %d bytes (used: %d)", errPredicateResultsCapacity, tx.Hash(), txResultsSize, env.predicateResultsSize)
+			}
+		}
 		env.predicateResults.SetTxResults(tx.Hash(), results)
 
 		blockContext = core.NewEVMBlockContextWithPredicateResults(env.header, w.chain, &coinbase, env.predicateResults)
@@ -323,11 +509,33 @@ func (w *worker) applyTransaction(env *environment, tx *types.Transaction, coinb
 		env.state.RevertToSnapshot(snap)
 		env.gasPool.SetGas(gp)
 		env.predicateResults.DeleteTxResults(tx.Hash())
+		return receipt, err
+	}
+	if env.inclusionHook != nil {
+		from, _ := types.Sender(env.signer, tx)
+		decision := env.inclusionHook.Decide(env.header, &InclusionCandidate{
+			Tx:            tx,
+			Sender:        from,
+			Receipt:       receipt,
+			CoinbaseValue: new(big.Int).Sub(env.state.GetBalance(coinbase), balance),
+			Deferred:      env.deferred,
+		})
+		if err := inclusionError(decision, env.deferred); err != nil {
+			env.state.RevertToSnapshot(snap)
+			env.gasPool.SetGas(gp)
+			env.header.GasUsed = gasUsed
+			env.predicateResults.DeleteTxResults(tx.Hash())
+			return nil, err
+		}
 	}
-	return receipt, err
+	env.predicateResultsSize += txResultsSize
+	return receipt, nil
 }
 
 func (w *worker) commitTransactions(env *environment, txs *transactionsByPriceAndNonce, coinbase common.Address) {
+	// deferred holds the transactions deferred by the inclusion hook, which
+	// are committed after the other candidates.
+	deferred := make(map[common.Address][]*txpool.LazyTransaction)
 	for {
 		// If we don't have enough gas for any further transactions then we're done.
 		if env.gasPool.Gas() < params.TxGas {
@@ -359,7 +567,7 @@ func (w *worker) commitTransactions(env *environment, txs *transactionsByPriceAn
 		}
 		// Abort transaction if it won't fit in the block and continue to search for a smaller
 		// transction that will fit.
-		if totalTxsSize := env.size + tx.Size(); totalTxsSize > targetTxsSize {
+		if totalTxsSize := env.size + tx.Size() + uint64(env.predicateResultsSize); totalTxsSize > targetTxsSize {
 			log.Trace("Skipping transaction that would exceed target size", "hash", tx.Hash(), "totalTxsSize", totalTxsSize, "txSize", tx.Size())
 			txs.Pop()
 			continue
@@ -376,11 +584,30 @@ func (w *worker) commitTransactions(env *environment, txs *transactionsByPriceAn
 			txs.Pop()
 			continue
 		}
+		// Exclude the sender's transactions if this one matches the incident filter.
+		if filter := w.incidentFilter.Load(); filter != nil {
+			if reason := filter.match(from, tx); reason != "" {
+				log.Warn("Excluding transaction matching incident filter", "hash", ltx.Hash, "sender", from, "nonce", tx.Nonce(), "match", reason)
+				txs.Pop()
+				continue
+			}
+		}
+		// Leave the reserved gas to the transactions of reserved senders.
+		reserved := env.reservation != nil && env.reservation.reserved(from)
+		if !reserved && env.gasPool.Gas() < ltx.Gas+env.reservedGas {
+			log.Trace("Not enough unreserved gas left for transaction", "hash", ltx.Hash, "left", env.gasPool.Gas(), "reserved", env.reservedGas, "needed", ltx.Gas)
+			txs.Pop()
+			continue
+		}
 
 		// Start executing the transaction
 		env.state.SetTxContext(tx.Hash(), env.tcount)
 
+		gasLeft := env.gasPool.Gas()
 		_, err := w.commitTransaction(env, tx, coinbase)
+		if err == nil && reserved {
+			env.reservedGas -= min(env.reservedGas, gasLeft-env.gasPool.Gas())
+		}
 		switch {
 		case errors.Is(err, core.ErrNonceTooLow):
 			// New head notification data race between the transaction pool and miner, shift
@@ -391,6 +618,21 @@ func (w *worker) commitTransactions(env *environment, txs *transactionsByPriceAn
 			env.tcount++
 			txs.Shift()
 
+		case errors.Is(err, errPredicateResultsCapacity):
+			// Not enough Extra space remains for the predicate results, defer the
+			// account's transactions to a future block.
+			log.Trace("Deferring transaction with predicate results exceeding remaining capacity", "hash", ltx.Hash, "err", err)
+			txs.Pop()
+
+		case errors.Is(err, errInclusionSkipped):
+			log.Trace("Skipping transaction rejected by inclusion hook", "hash", ltx.Hash, "sender", from)
+			txs.Pop()
+
+		case errors.Is(err, errInclusionDeferred):
+			log.Trace("Deferring transaction by inclusion hook", "hash", ltx.Hash, "sender", from)
+			deferred[from] = []*txpool.LazyTransaction{ltx}
+			txs.Pop()
+
 		default:
 			// Transaction is regarded as invalid, drop all consecutive transactions from
 			// the same sender because of `nonce-too-high` clause.
@@ -398,6 +640,11 @@ func (w *worker) commitTransactions(env *environment, txs *transactionsByPriceAn
 			txs.Pop()
 		}
 	}
+	if len(deferred) > 0 {
+		env.deferred = true
+		w.commitTransactions(env, w.newTransactionSet(env, deferred), coinbase)
+		env.deferred = false
+	}
 }
 
 // commit runs any post-transaction state modifications, assembles the final block
@@ -459,6 +706,12 @@ func (w *worker) handleResult(env *environment, block *types.Block, createdAt ti
 		"gas", block.GasUsed(), "fees", feesInEther,
 		"elapsed", common.PrettyDuration(time.Since(env.start)))
 
+	// The sidecars stripped from the blob transactions of the block are
+	// persisted with it, and deleted if the block is rejected.
+	if len(env.sidecars) > 0 {
+		w.chain.WriteBlobSidecars(hash, block.NumberU64(), env.sidecars)
+	}
+
 	// Note: the miner no longer emits a NewMinedBlock event. Instead the caller
 	// is responsible for running any additional verification and then inserting
 	// the block with InsertChain, which will also emit a new head event.
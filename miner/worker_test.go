@@ -0,0 +1,204 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package miner
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/shubhamdubey02/coreth/consensus/dummy"
+	"github.com/shubhamdubey02/coreth/core"
+	"github.com/shubhamdubey02/coreth/core/rawdb"
+	"github.com/shubhamdubey02/coreth/core/txpool"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/core/vm"
+	"github.com/shubhamdubey02/coreth/params"
+	"github.com/shubhamdubey02/coreth/precompile/precompileconfig"
+	"github.com/shubhamdubey02/coreth/predicate"
+	"github.com/shubhamdubey02/cryftgo/snow/engine/snowman/block"
+	"github.com/shubhamdubey02/cryftgo/utils/timer/mockable"
+)
+
+// testBackend is a [Backend] without a transaction pool.
+type testBackend struct {
+	chain *core.BlockChain
+}
+
+func (b *testBackend) BlockChain() *core.BlockChain { return b.chain }
+func (b *testBackend) TxPool() *txpool.TxPool       { return nil }
+
+// testPredicater is a [precompileconfig.Predicater] accepting all predicates
+// for free.
+type testPredicater struct{}
+
+func (testPredicater) PredicateGas([]byte) (uint64, error) { return 0, nil }
+func (testPredicater) VerifyPredicate(*precompileconfig.PredicateContext, []byte) error {
+	return nil
+}
+
+// lazyTransactions returns [tx] as the pending transactions of its sender.
+func lazyTransactions(tx *types.Transaction) []*txpool.LazyTransaction {
+	return []*txpool.LazyTransaction{{
+		Hash:      tx.Hash(),
+		Tx:        tx,
+		Time:      tx.Time(),
+		GasFeeCap: tx.GasFeeCap(),
+		GasTipCap: tx.GasTipCap(),
+		Gas:       tx.Gas(),
+	}}
+}
+
+// Tests that a transaction deferred because its predicate results do not fit
+// in the block, once the size of the transactions committed before it is
+// accounted for, is included in the next block.
+func TestPredicateResultsDeferred(t *testing.T) {
+	var (
+		keyA, _    = crypto.GenerateKey()
+		keyB, _    = crypto.GenerateKey()
+		addrA      = crypto.PubkeyToAddress(keyA.PublicKey)
+		addrB      = crypto.PubkeyToAddress(keyB.PublicKey)
+		predicater = common.Address{0xff}
+		funds      = new(big.Int).Mul(big.NewInt(params.Ether), big.NewInt(100))
+		gspec      = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  core.GenesisAlloc{addrA: {Balance: funds}, addrB: {Balance: funds}},
+		}
+		signer = types.LatestSigner(params.TestChainConfig)
+	)
+	chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), core.DefaultCacheConfig, gspec, dummy.NewETHFaker(), vm.Config{}, common.Hash{}, false)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	w := newWorker(&Config{Etherbase: common.Address{0x01}}, params.TestChainConfig, dummy.NewETHFaker(), &testBackend{chain}, nil, &mockable.Clock{})
+	predicateContext := &precompileconfig.PredicateContext{ProposerVMBlockCtx: &block.Context{}}
+	prepareWork := func() *environment {
+		env, err := w.prepareWork(predicateContext)
+		if err != nil {
+			t.Fatalf("failed to prepare work: %v", err)
+		}
+		env.rules.Predicaters = map[common.Address]precompileconfig.Predicater{predicater: testPredicater{}}
+		return env
+	}
+	txA := types.MustSignNewTx(keyA, signer, &types.DynamicFeeTx{
+		ChainID:   params.TestChainConfig.ChainID,
+		GasTipCap: big.NewInt(2 * params.GWei),
+		GasFeeCap: big.NewInt(1000 * params.GWei),
+		Gas:       params.TxGas,
+		To:        &common.Address{0x02},
+	})
+	txB := types.MustSignNewTx(keyB, signer, &types.DynamicFeeTx{
+		ChainID:   params.TestChainConfig.ChainID,
+		GasTipCap: big.NewInt(params.GWei),
+		GasFeeCap: big.NewInt(1000 * params.GWei),
+		Gas:       100_000,
+		To:        &common.Address{0x02},
+		AccessList: types.AccessList{{
+			Address:     predicater,
+			StorageKeys: []common.Hash{{0x01}},
+		}},
+	})
+	// Fill the first block such that txB fits with empty predicate results,
+	// but not with its own results once txA is committed.
+	env := prepareWork()
+	env.size = targetTxsSize - txA.Size() - txB.Size() - uint64(env.predicateResultsSize)
+	txs := map[common.Address][]*txpool.LazyTransaction{addrA: lazyTransactions(txA), addrB: lazyTransactions(txB)}
+	w.commitTransactions(env, w.newTransactionSet(env, txs), env.header.Coinbase)
+	if len(env.txs) != 1 || env.txs[0].Hash() != txA.Hash() {
+		t.Fatalf("first block transactions %d, want only %s", len(env.txs), txA.Hash())
+	}
+	first, err := w.commit(env)
+	env.state.StopPrefetcher()
+	if err != nil {
+		t.Fatalf("failed to commit first block: %v", err)
+	}
+	if _, err := chain.InsertChain(types.Blocks{first}); err != nil {
+		t.Fatalf("failed to insert first block: %v", err)
+	}
+
+	env = prepareWork()
+	defer env.state.StopPrefetcher()
+	txs = map[common.Address][]*txpool.LazyTransaction{addrB: lazyTransactions(txB)}
+	w.commitTransactions(env, w.newTransactionSet(env, txs), env.header.Coinbase)
+	second, err := w.commit(env)
+	if err != nil {
+		t.Fatalf("failed to commit second block: %v", err)
+	}
+	if second.ParentHash() != first.Hash() {
+		t.Fatalf("second block parent %s, want %s", second.ParentHash(), first.Hash())
+	}
+	if txs := second.Transactions(); len(txs) != 1 || txs[0].Hash() != txB.Hash() {
+		t.Fatalf("second block transactions %d, want only %s", len(txs), txB.Hash())
+	}
+	resultsBytes, ok := predicate.GetPredicateResultBytes(second.Extra())
+	if !ok {
+		t.Fatal("missing predicate results")
+	}
+	results, err := predicate.ParseResults(resultsBytes)
+	if err != nil {
+		t.Fatalf("failed to parse predicate results: %v", err)
+	}
+	if _, ok := results.Results[txB.Hash()][predicater]; !ok {
+		t.Fatalf("missing predicate results of %s", txB.Hash())
+	}
+}
+
+// Tests that the size of committed transactions is accumulated, so that a
+// transaction fitting in the target size on its own is skipped once the
+// transactions committed before it leave no room for it.
+func TestCommitTransactionsSize(t *testing.T) {
+	var (
+		keyA, _ = crypto.GenerateKey()
+		keyB, _ = crypto.GenerateKey()
+		addrA   = crypto.PubkeyToAddress(keyA.PublicKey)
+		addrB   = crypto.PubkeyToAddress(keyB.PublicKey)
+		funds   = new(big.Int).Mul(big.NewInt(params.Ether), big.NewInt(100))
+		gspec   = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  core.GenesisAlloc{addrA: {Balance: funds}, addrB: {Balance: funds}},
+		}
+		signer = types.LatestSigner(params.TestChainConfig)
+	)
+	chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), core.DefaultCacheConfig, gspec, dummy.NewETHFaker(), vm.Config{}, common.Hash{}, false)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	w := newWorker(&Config{Etherbase: common.Address{0x01}}, params.TestChainConfig, dummy.NewETHFaker(), &testBackend{chain}, nil, &mockable.Clock{})
+	env, err := w.prepareWork(&precompileconfig.PredicateContext{ProposerVMBlockCtx: &block.Context{}})
+	if err != nil {
+		t.Fatalf("failed to prepare work: %v", err)
+	}
+	defer env.state.StopPrefetcher()
+
+	txA := types.MustSignNewTx(keyA, signer, &types.DynamicFeeTx{
+		ChainID:   params.TestChainConfig.ChainID,
+		GasTipCap: big.NewInt(2 * params.GWei),
+		GasFeeCap: big.NewInt(1000 * params.GWei),
+		Gas:       params.TxGas,
+		To:        &common.Address{0x02},
+	})
+	txB := types.MustSignNewTx(keyB, signer, &types.DynamicFeeTx{
+		ChainID:   params.TestChainConfig.ChainID,
+		GasTipCap: big.NewInt(params.GWei),
+		GasFeeCap: big.NewInt(1000 * params.GWei),
+		Gas:       params.TxGas,
+		To:        &common.Address{0x02},
+	})
+	// Leave room for either transaction, but not for both.
+	used := targetTxsSize - uint64(env.predicateResultsSize) - txA.Size() - txB.Size() + 1
+	env.size = used
+	txs := map[common.Address][]*txpool.LazyTransaction{addrA: lazyTransactions(txA), addrB: lazyTransactions(txB)}
+	w.commitTransactions(env, w.newTransactionSet(env, txs), env.header.Coinbase)
+	if len(env.txs) != 1 || env.txs[0].Hash() != txA.Hash() {
+		t.Fatalf("block transactions %d, want only %s", len(env.txs), txA.Hash())
+	}
+	if want := used + txA.Size(); env.size != want {
+		t.Fatalf("block size %d, want %d", env.size, want)
+	}
+}
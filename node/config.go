@@ -45,14 +45,18 @@ type Config struct {
 	// be specified as a relative path, in which case it is resolved relative to the
 	// current directory.
 	//
-	// If KeyStoreDir is empty, the default location is the "keystore" subdirectory of
-	// DataDir. If DataDir is unspecified and KeyStoreDir is empty, an ephemeral directory
-	// is created by New and destroyed when the node is stopped.
+	// If KeyStoreDir is empty, no local key store is used, so that private keys are
+	// only held by the node if explicitly configured.
 	KeyStoreDir string `toml:",omitempty"`
 
 	// ExternalSigner specifies an external URI for a clef-type signer.
 	ExternalSigner string `toml:",omitempty"`
 
+	// AccountBackends are account backends, such as HSM or remote signers,
+	// provided by the embedder of the node. If any are set, or ExternalSigner
+	// is set, the local key store is not used.
+	AccountBackends []accounts.Backend `toml:"-"`
+
 	// UseLightweightKDF lowers the memory and CPU requirements of the key store
 	// scrypt KDF at the expense of security.
 	UseLightweightKDF bool `toml:",omitempty"`
@@ -91,28 +95,17 @@ func (c *Config) KeyDirConfig() (string, error) {
 	return keydir, err
 }
 
-// GetKeyStoreDir retrieves the key directory and will create
-// and ephemeral one if necessary.
-func (c *Config) GetKeyStoreDir() (string, bool, error) {
+// GetKeyStoreDir retrieves the key directory, creating it if necessary. It
+// returns an empty directory if no local key store is configured.
+func (c *Config) GetKeyStoreDir() (string, error) {
 	keydir, err := c.KeyDirConfig()
-	if err != nil {
-		return "", false, err
-	}
-	isEphemeral := false
-	if keydir == "" {
-		// There is no datadir.
-		keydir, err = os.MkdirTemp("", "coreth-keystore")
-		isEphemeral = true
-	}
-
-	if err != nil {
-		return "", false, err
+	if err != nil || keydir == "" {
+		return "", err
 	}
 	if err := os.MkdirAll(keydir, 0700); err != nil {
-		return "", false, err
+		return "", err
 	}
-
-	return keydir, isEphemeral, nil
+	return keydir, nil
 }
 
 func makeAccountManager(conf *Config) (*accounts.Manager, error) {
@@ -123,12 +116,12 @@ func makeAccountManager(conf *Config) (*accounts.Manager, error) {
 		scryptP = keystore.LightScryptP
 	}
 
-	keydir, _, err := conf.GetKeyStoreDir()
+	keydir, err := conf.GetKeyStoreDir()
 	if err != nil {
 		return nil, err
 	}
 	// Assemble the account manager and supported backends
-	var backends []accounts.Backend
+	backends := append([]accounts.Backend(nil), conf.AccountBackends...)
 	if len(conf.ExternalSigner) > 0 {
 		log.Info("Using external signer", "url", conf.ExternalSigner)
 		if extapi, err := external.NewExternalBackend(conf.ExternalSigner); err == nil {
@@ -137,7 +130,7 @@ func makeAccountManager(conf *Config) (*accounts.Manager, error) {
 			return nil, fmt.Errorf("error connecting to external signer: %v", err)
 		}
 	}
-	if len(backends) == 0 {
+	if len(backends) == 0 && keydir != "" {
 		// For now, we're using EITHER external signer OR local signers.
 		// If/when we implement some form of lockfile for USB and keystore wallets,
 		// we can have both, but it's very confusing for the user to see the same
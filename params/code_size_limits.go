@@ -0,0 +1,103 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package params
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	errCodeSizeLimitsUnordered = errors.New("code size limits must be in strictly increasing order of blockTimestamp")
+	errZeroCodeSizeLimit       = errors.New("code size limits must be positive")
+)
+
+// CodeSizeLimit overrides the maximum size of contract code (EIP-170) and of
+// initcode (EIP-3860) for blocks with a timestamp of at least BlockTimestamp,
+// until the next limit of the schedule.
+type CodeSizeLimit struct {
+	BlockTimestamp  uint64 `json:"blockTimestamp"`
+	MaxCodeSize     int    `json:"maxCodeSize"`
+	MaxInitCodeSize int    `json:"maxInitCodeSize"`
+}
+
+// codeSizeLimit returns the limit of the schedule in effect at [time], or nil
+// if the default limits apply.
+func (c *ChainConfig) codeSizeLimit(time uint64) *CodeSizeLimit {
+	var active *CodeSizeLimit
+	for i := range c.CodeSizeLimits {
+		if c.CodeSizeLimits[i].BlockTimestamp > time {
+			break
+		}
+		active = &c.CodeSizeLimits[i]
+	}
+	return active
+}
+
+// MaxCodeSize returns the maximum size of contract code deployed by blocks
+// at [time].
+func (c *ChainConfig) MaxCodeSize(time uint64) int {
+	if limit := c.codeSizeLimit(time); limit != nil {
+		return limit.MaxCodeSize
+	}
+	return MaxCodeSize
+}
+
+// MaxInitCodeSize returns the maximum size of initcode executed by blocks at
+// [time].
+func (c *ChainConfig) MaxInitCodeSize(time uint64) int {
+	if limit := c.codeSizeLimit(time); limit != nil {
+		return limit.MaxInitCodeSize
+	}
+	return MaxInitCodeSize
+}
+
+// LargestMaxCodeSize returns the largest maximum contract code size of the
+// schedule, which bounds the code of every account of the chain.
+func (c *ChainConfig) LargestMaxCodeSize() int {
+	largest := MaxCodeSize
+	for _, limit := range c.CodeSizeLimits {
+		largest = max(largest, limit.MaxCodeSize)
+	}
+	return largest
+}
+
+// verifyCodeSizeLimits checks that the code size limit schedule is ordered
+// and that its limits are positive.
+func (c *ChainConfig) verifyCodeSizeLimits() error {
+	for i, limit := range c.CodeSizeLimits {
+		if i > 0 && limit.BlockTimestamp <= c.CodeSizeLimits[i-1].BlockTimestamp {
+			return fmt.Errorf("%w: %d follows %d", errCodeSizeLimitsUnordered, limit.BlockTimestamp, c.CodeSizeLimits[i-1].BlockTimestamp)
+		}
+		if limit.MaxCodeSize <= 0 || limit.MaxInitCodeSize <= 0 {
+			return fmt.Errorf("%w: limit at %d", errZeroCodeSizeLimit, limit.BlockTimestamp)
+		}
+	}
+	return nil
+}
+
+// checkCodeSizeLimitsCompatible returns an error if [newcfg] changes the code
+// size limits in effect at or before [time].
+func (c *ChainConfig) checkCodeSizeLimitsCompatible(newcfg *ChainConfig, time uint64) *ConfigCompatError {
+	var (
+		stored = c.CodeSizeLimits
+		next   = newcfg.CodeSizeLimits
+	)
+	for i := 0; i < len(stored) || i < len(next); i++ {
+		var storedTime, nextTime *uint64
+		if i < len(stored) {
+			storedTime = &stored[i].BlockTimestamp
+		}
+		if i < len(next) {
+			nextTime = &next[i].BlockTimestamp
+		}
+		if (storedTime == nil || *storedTime > time) && (nextTime == nil || *nextTime > time) {
+			return nil
+		}
+		if storedTime == nil || nextTime == nil || stored[i] != next[i] {
+			return newTimestampCompatError("code size limit timestamp", storedTime, nextTime)
+		}
+	}
+	return nil
+}
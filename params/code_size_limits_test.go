@@ -0,0 +1,97 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package params
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCodeSizeLimits(t *testing.T) {
+	config := &ChainConfig{CodeSizeLimits: []CodeSizeLimit{
+		{BlockTimestamp: 100, MaxCodeSize: 2 * MaxCodeSize, MaxInitCodeSize: 4 * MaxCodeSize},
+		{BlockTimestamp: 200, MaxCodeSize: 30_000, MaxInitCodeSize: 60_000},
+	}}
+	tests := []struct {
+		time                         uint64
+		maxCodeSize, maxInitCodeSize int
+	}{
+		{time: 0, maxCodeSize: MaxCodeSize, maxInitCodeSize: MaxInitCodeSize},
+		{time: 99, maxCodeSize: MaxCodeSize, maxInitCodeSize: MaxInitCodeSize},
+		{time: 100, maxCodeSize: 2 * MaxCodeSize, maxInitCodeSize: 4 * MaxCodeSize},
+		{time: 250, maxCodeSize: 30_000, maxInitCodeSize: 60_000},
+	}
+	for _, test := range tests {
+		if have := config.MaxCodeSize(test.time); have != test.maxCodeSize {
+			t.Errorf("max code size at %d: have %d, want %d", test.time, have, test.maxCodeSize)
+		}
+		if have := config.MaxInitCodeSize(test.time); have != test.maxInitCodeSize {
+			t.Errorf("max initcode size at %d: have %d, want %d", test.time, have, test.maxInitCodeSize)
+		}
+		rules := config.Rules(nil, test.time)
+		if rules.MaxCodeSize != test.maxCodeSize || rules.MaxInitCodeSize != test.maxInitCodeSize {
+			t.Errorf("rules at %d have limits %d/%d", test.time, rules.MaxCodeSize, rules.MaxInitCodeSize)
+		}
+	}
+	if have := config.LargestMaxCodeSize(); have != 2*MaxCodeSize {
+		t.Errorf("largest max code size: have %d, want %d", have, 2*MaxCodeSize)
+	}
+}
+
+func TestVerifyCodeSizeLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits []CodeSizeLimit
+		err    error
+	}{
+		{
+			name:   "valid",
+			limits: []CodeSizeLimit{{BlockTimestamp: 1, MaxCodeSize: 1, MaxInitCodeSize: 2}, {BlockTimestamp: 2, MaxCodeSize: 1, MaxInitCodeSize: 2}},
+		},
+		{
+			name:   "unordered",
+			limits: []CodeSizeLimit{{BlockTimestamp: 2, MaxCodeSize: 1, MaxInitCodeSize: 2}, {BlockTimestamp: 2, MaxCodeSize: 1, MaxInitCodeSize: 2}},
+			err:    errCodeSizeLimitsUnordered,
+		},
+		{
+			name:   "zero limit",
+			limits: []CodeSizeLimit{{BlockTimestamp: 1, MaxInitCodeSize: 2}},
+			err:    errZeroCodeSizeLimit,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := &ChainConfig{CodeSizeLimits: test.limits}
+			if err := config.verifyCodeSizeLimits(); !errors.Is(err, test.err) {
+				t.Fatalf("error mismatch: have %v, want %v", err, test.err)
+			}
+		})
+	}
+}
+
+func TestCheckCodeSizeLimitsCompatible(t *testing.T) {
+	stored := &ChainConfig{CodeSizeLimits: []CodeSizeLimit{
+		{BlockTimestamp: 100, MaxCodeSize: 30_000, MaxInitCodeSize: 60_000},
+		{BlockTimestamp: 200, MaxCodeSize: 40_000, MaxInitCodeSize: 80_000},
+	}}
+	// Limits scheduled after the head may change.
+	rescheduled := &ChainConfig{CodeSizeLimits: []CodeSizeLimit{
+		{BlockTimestamp: 100, MaxCodeSize: 30_000, MaxInitCodeSize: 60_000},
+		{BlockTimestamp: 300, MaxCodeSize: 50_000, MaxInitCodeSize: 100_000},
+	}}
+	if err := stored.checkCodeSizeLimitsCompatible(rescheduled, 150); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Limits in effect at the head may not.
+	err := stored.checkCodeSizeLimitsCompatible(rescheduled, 250)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if err.RewindToTime != 199 {
+		t.Fatalf("rewind mismatch: have %d, want %d", err.RewindToTime, 199)
+	}
+	if err := stored.checkCodeSizeLimitsCompatible(&ChainConfig{}, 150); err == nil {
+		t.Fatal("expected error when removing a limit in effect")
+	}
+}
@@ -501,6 +501,19 @@ type ChainConfig struct {
 	// Verkle activates the Verkle upgrade from Ethereum. (nil = no fork, 0 = already activated)
 	VerkleTime *uint64 `json:"verkleTime,omitempty"` // Verkle switch time (nil = no fork, 0 = already on verkle)
 
+	// CodeSizeLimits schedules overrides of the maximum contract code size
+	// (EIP-170) and initcode size (EIP-3860), for networks that want larger
+	// contracts. (nil = default limits)
+	CodeSizeLimits []CodeSizeLimit `json:"codeSizeLimits,omitempty"`
+
+	// StorageExpiry enables the experimental expiry of storage slots left
+	// unaccessed, for custom networks. (nil = storage never expires)
+	StorageExpiry *StorageExpiryConfig `json:"storageExpiry,omitempty"`
+
+	// GasSponsorship lets a contract pay the gas of transactions, for custom
+	// networks. (nil = senders always pay for gas)
+	GasSponsorship *GasSponsorshipConfig `json:"gasSponsorship,omitempty"`
+
 	UpgradeConfig `json:"-"` // Config specified in upgradeBytes (avalanche network upgrades or enable/disabling precompiles). Skip encoding/decoding directly into ChainConfig.
 }
 
@@ -735,6 +748,22 @@ func (c *ChainConfig) Verify() error {
 	if err := c.verifyPrecompileUpgrades(); err != nil {
 		return fmt.Errorf("invalid precompile upgrades: %w", err)
 	}
+	if err := c.verifyTxTypeUpgrades(); err != nil {
+		return fmt.Errorf("invalid tx type upgrades: %w", err)
+	}
+	if err := c.verifyCodeSizeLimits(); err != nil {
+		return fmt.Errorf("invalid code size limits: %w", err)
+	}
+	if c.StorageExpiry != nil {
+		if err := c.StorageExpiry.verify(); err != nil {
+			return fmt.Errorf("invalid storage expiry: %w", err)
+		}
+	}
+	if c.GasSponsorship != nil {
+		if err := c.GasSponsorship.verify(); err != nil {
+			return fmt.Errorf("invalid gas sponsorship: %w", err)
+		}
+	}
 
 	return nil
 }
@@ -910,6 +939,18 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, height *big.Int, time
 	if isForkTimestampIncompatible(c.CancunTime, newcfg.CancunTime, time) {
 		return newTimestampCompatError("Cancun fork block timestamp", c.CancunTime, newcfg.CancunTime)
 	}
+	if err := c.checkCodeSizeLimitsCompatible(newcfg, time); err != nil {
+		return err
+	}
+	if err := c.checkStorageExpiryCompatible(newcfg, time); err != nil {
+		return err
+	}
+	if err := c.checkGasSponsorshipCompatible(newcfg, time); err != nil {
+		return err
+	}
+	if err := c.checkTxTypeUpgradesCompatible(newcfg, time); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -1036,6 +1077,10 @@ type Rules struct {
 	// Rules for Avalanche releases
 	AvalancheRules
 
+	// MaxCodeSize and MaxInitCodeSize are the EIP-170 and EIP-3860 limits,
+	// which the chain config may override.
+	MaxCodeSize, MaxInitCodeSize int
+
 	// ActivePrecompiles maps addresses to stateful precompiled contracts that are enabled
 	// for this rule set.
 	// Note: none of these addresses should conflict with the address space used by
@@ -1081,6 +1126,8 @@ func (c *ChainConfig) Rules(blockNum *big.Int, timestamp uint64) Rules {
 	rules := c.rules(blockNum, timestamp)
 
 	rules.AvalancheRules = c.GetAvalancheRules(timestamp)
+	rules.MaxCodeSize = c.MaxCodeSize(timestamp)
+	rules.MaxInitCodeSize = c.MaxInitCodeSize(timestamp)
 
 	// Initialize the stateful precompiles that should be enabled at [blockTimestamp].
 	rules.ActivePrecompiles = make(map[common.Address]precompileconfig.Config)
@@ -0,0 +1,70 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package params
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// ConfigChange is a field of a chain config, including its upgrades, whose
+// value differs between two configs. Old and New are the JSON encoding of the
+// values, or empty if the field is not set.
+type ConfigChange struct {
+	Field string
+	Old   string
+	New   string
+}
+
+func (c ConfigChange) String() string {
+	old, new := c.Old, c.New
+	if old == "" {
+		old = "<unset>"
+	}
+	if new == "" {
+		new = "<unset>"
+	}
+	return fmt.Sprintf("%s: %s -> %s", c.Field, old, new)
+}
+
+// Diff returns the top-level fields of the JSON encoding of [c] and its
+// upgrades whose value differs in [newcfg], ordered by field name.
+func (c *ChainConfig) Diff(newcfg *ChainConfig) ([]ConfigChange, error) {
+	oldFields, err := configFields(c)
+	if err != nil {
+		return nil, err
+	}
+	newFields, err := configFields(newcfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []ConfigChange
+	for field, old := range oldFields {
+		if new, ok := newFields[field]; !ok || !bytes.Equal(old, new) {
+			changes = append(changes, ConfigChange{Field: field, Old: string(old), New: string(new)})
+		}
+	}
+	for field, new := range newFields {
+		if _, ok := oldFields[field]; !ok {
+			changes = append(changes, ConfigChange{Field: field, New: string(new)})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes, nil
+}
+
+func configFields(c *ChainConfig) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(c.ToWithUpgradesJSON())
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
@@ -0,0 +1,48 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package params
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/shubhamdubey02/coreth/utils"
+)
+
+func TestChainConfigDiff(t *testing.T) {
+	stored := &ChainConfig{
+		ChainID:               big.NewInt(1),
+		HomesteadBlock:        big.NewInt(0),
+		BanffBlockTimestamp:   utils.NewUint64(100),
+		CortinaBlockTimestamp: utils.NewUint64(200),
+	}
+	changes, err := stored.Diff(stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Fatalf("unexpected changes of identical configs: %v", changes)
+	}
+
+	supplied := *stored
+	supplied.CortinaBlockTimestamp = nil
+	supplied.DurangoBlockTimestamp = utils.NewUint64(300)
+	supplied.UpgradeConfig = UpgradeConfig{TxTypeUpgrades: []TxTypeUpgrade{{TxType: 0x10, BlockTimestamp: 300}}}
+	changes, err = stored.Diff(&supplied)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ConfigChange{
+		{Field: "cortinaBlockTimestamp", Old: "200"},
+		{Field: "durangoBlockTimestamp", New: "300"},
+		{Field: "upgrades", Old: "{}", New: `{"txTypeUpgrades":[{"txType":16,"blockTimestamp":300}]}`},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Fatalf("changes mismatch: have %v, want %v", changes, want)
+	}
+	if have, want := changes[0].String(), "cortinaBlockTimestamp: 200 -> <unset>"; have != want {
+		t.Fatalf("description mismatch: have %q, want %q", have, want)
+	}
+}
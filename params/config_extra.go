@@ -14,10 +14,15 @@ import (
 
 // UpgradeConfig includes the following configs that may be specified in upgradeBytes:
 // - Timestamps that enable avalanche network upgrades,
-// - Enabling or disabling precompiles as network upgrades.
+// - Enabling or disabling precompiles as network upgrades,
+// - Enabling or disabling custom transaction types as network upgrades.
 type UpgradeConfig struct {
 	// Config for enabling and disabling precompiles as network upgrades.
 	PrecompileUpgrades []PrecompileUpgrade `json:"precompileUpgrades,omitempty"`
+
+	// Config for enabling and disabling custom transaction types as network
+	// upgrades.
+	TxTypeUpgrades []TxTypeUpgrade `json:"txTypeUpgrades,omitempty"`
 }
 
 // AvalancheContext provides Avalanche specific context directly into the EVM.
@@ -0,0 +1,54 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package params
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var errGasSponsorshipContract = errors.New("gas sponsorship requires a contract address")
+
+// GasSponsorshipConfig lets a contract pay the gas of transactions, for custom
+// networks with account-abstraction-style relays. From BlockTimestamp, the gas
+// of a transaction whose sender cannot afford it is charged to Contract if its
+// isSponsored view function returns true for the transaction. The sender must
+// still pay the value of the transaction.
+type GasSponsorshipConfig struct {
+	BlockTimestamp uint64         `json:"blockTimestamp"`
+	Contract       common.Address `json:"contract"`
+}
+
+// IsGasSponsorship returns whether [time] is at or after the activation of
+// gas sponsorship.
+func (c *ChainConfig) IsGasSponsorship(time uint64) bool {
+	return c.GasSponsorship != nil && time >= c.GasSponsorship.BlockTimestamp
+}
+
+func (c *GasSponsorshipConfig) verify() error {
+	if c.Contract == (common.Address{}) {
+		return errGasSponsorshipContract
+	}
+	return nil
+}
+
+// checkGasSponsorshipCompatible returns an error if [newcfg] changes gas
+// sponsorship once it is active at [time].
+func (c *ChainConfig) checkGasSponsorshipCompatible(newcfg *ChainConfig, time uint64) *ConfigCompatError {
+	var storedTime, nextTime *uint64
+	if c.GasSponsorship != nil {
+		storedTime = &c.GasSponsorship.BlockTimestamp
+	}
+	if newcfg.GasSponsorship != nil {
+		nextTime = &newcfg.GasSponsorship.BlockTimestamp
+	}
+	if !c.IsGasSponsorship(time) && !newcfg.IsGasSponsorship(time) {
+		return nil
+	}
+	if storedTime == nil || nextTime == nil || *c.GasSponsorship != *newcfg.GasSponsorship {
+		return newTimestampCompatError("gas sponsorship timestamp", storedTime, nextTime)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package params
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MinStorageExpiryEpochDuration is the minimum length of a storage expiry
+// epoch, in seconds. Epochs must be long enough for the blocks of an epoch to
+// be accepted before the slots they access can expire.
+const MinStorageExpiryEpochDuration = 3600
+
+var (
+	errStorageExpiryEpochDuration = errors.New("storage expiry epoch duration too short")
+	errStorageExpiryEpochs        = errors.New("storage expiry requires at least 2 epochs")
+)
+
+// StorageExpiryConfig configures the experimental expiry of contract storage.
+// From BlockTimestamp, time is divided into epochs of EpochDuration seconds
+// and the epoch of the last access to each storage slot is recorded. Slots
+// not accessed for ExpiryEpochs full epochs are cleared by the first block of
+// the following epoch.
+//
+// The access epochs are kept in an index beside the state, so all the nodes
+// of a network using storage expiry must execute the chain from the
+// activation of the experiment. State sync is not supported.
+type StorageExpiryConfig struct {
+	BlockTimestamp uint64 `json:"blockTimestamp"`
+	EpochDuration  uint64 `json:"epochDuration"`
+	ExpiryEpochs   uint64 `json:"expiryEpochs"`
+}
+
+// IsStorageExpiry returns whether [time] is at or after the activation of
+// storage expiry.
+func (c *ChainConfig) IsStorageExpiry(time uint64) bool {
+	return c.StorageExpiry != nil && time >= c.StorageExpiry.BlockTimestamp
+}
+
+// Epoch returns the storage expiry epoch of [time], which must be at or after
+// the activation of storage expiry.
+func (c *StorageExpiryConfig) Epoch(time uint64) uint64 {
+	return (time - c.BlockTimestamp) / c.EpochDuration
+}
+
+func (c *StorageExpiryConfig) verify() error {
+	if c.EpochDuration < MinStorageExpiryEpochDuration {
+		return fmt.Errorf("%w: %d < %d", errStorageExpiryEpochDuration, c.EpochDuration, MinStorageExpiryEpochDuration)
+	}
+	if c.ExpiryEpochs < 2 {
+		return fmt.Errorf("%w: %d", errStorageExpiryEpochs, c.ExpiryEpochs)
+	}
+	return nil
+}
+
+// checkStorageExpiryCompatible returns an error if [newcfg] changes storage
+// expiry once it is active at [time].
+func (c *ChainConfig) checkStorageExpiryCompatible(newcfg *ChainConfig, time uint64) *ConfigCompatError {
+	var storedTime, nextTime *uint64
+	if c.StorageExpiry != nil {
+		storedTime = &c.StorageExpiry.BlockTimestamp
+	}
+	if newcfg.StorageExpiry != nil {
+		nextTime = &newcfg.StorageExpiry.BlockTimestamp
+	}
+	if !c.IsStorageExpiry(time) && !newcfg.IsStorageExpiry(time) {
+		return nil
+	}
+	if storedTime == nil || nextTime == nil || *c.StorageExpiry != *newcfg.StorageExpiry {
+		return newTimestampCompatError("storage expiry timestamp", storedTime, nextTime)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package params
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStorageExpiryEpoch(t *testing.T) {
+	config := &ChainConfig{StorageExpiry: &StorageExpiryConfig{BlockTimestamp: 1000, EpochDuration: 3600, ExpiryEpochs: 2}}
+	if config.IsStorageExpiry(999) || !config.IsStorageExpiry(1000) {
+		t.Fatal("activation mismatch")
+	}
+	tests := []struct {
+		time, epoch uint64
+	}{
+		{time: 1000, epoch: 0},
+		{time: 4599, epoch: 0},
+		{time: 4600, epoch: 1},
+		{time: 1000 + 10*3600, epoch: 10},
+	}
+	for _, test := range tests {
+		if have := config.StorageExpiry.Epoch(test.time); have != test.epoch {
+			t.Errorf("epoch at %d: have %d, want %d", test.time, have, test.epoch)
+		}
+	}
+}
+
+func TestVerifyStorageExpiry(t *testing.T) {
+	tests := []struct {
+		name   string
+		config StorageExpiryConfig
+		err    error
+	}{
+		{
+			name:   "valid",
+			config: StorageExpiryConfig{EpochDuration: MinStorageExpiryEpochDuration, ExpiryEpochs: 2},
+		},
+		{
+			name:   "short epochs",
+			config: StorageExpiryConfig{EpochDuration: MinStorageExpiryEpochDuration - 1, ExpiryEpochs: 2},
+			err:    errStorageExpiryEpochDuration,
+		},
+		{
+			name:   "single epoch",
+			config: StorageExpiryConfig{EpochDuration: MinStorageExpiryEpochDuration, ExpiryEpochs: 1},
+			err:    errStorageExpiryEpochs,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.config.verify(); !errors.Is(err, test.err) {
+				t.Fatalf("error mismatch: have %v, want %v", err, test.err)
+			}
+		})
+	}
+}
+
+func TestCheckStorageExpiryCompatible(t *testing.T) {
+	stored := &ChainConfig{StorageExpiry: &StorageExpiryConfig{BlockTimestamp: 100, EpochDuration: 3600, ExpiryEpochs: 2}}
+	changed := &ChainConfig{StorageExpiry: &StorageExpiryConfig{BlockTimestamp: 100, EpochDuration: 7200, ExpiryEpochs: 2}}
+
+	// Storage expiry may change before it activates.
+	if err := stored.checkStorageExpiryCompatible(changed, 50); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := stored.checkStorageExpiryCompatible(&ChainConfig{}, 50); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// But not once it is active.
+	if err := stored.checkStorageExpiryCompatible(changed, 150); err == nil {
+		t.Fatal("expected error when changing active storage expiry")
+	}
+	if err := stored.checkStorageExpiryCompatible(&ChainConfig{}, 150); err == nil {
+		t.Fatal("expected error when removing active storage expiry")
+	}
+	if err := stored.checkStorageExpiryCompatible(stored, 150); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
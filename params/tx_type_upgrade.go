@@ -0,0 +1,88 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package params
+
+import (
+	"errors"
+	"fmt"
+)
+
+// minCustomTxType is the lowest transaction type that may be enabled by a
+// [TxTypeUpgrade], as lower types are the builtin transactions.
+const minCustomTxType = 0x04
+
+var (
+	errTxTypeUpgradeBuiltin   = errors.New("tx type upgrade cannot change a builtin transaction type")
+	errTxTypeUpgradeUnordered = errors.New("tx type upgrades must be in non-decreasing order of blockTimestamp")
+	errTxTypeUpgradeDisable   = errors.New("tx type upgrades must alternate between enabling and disabling a type")
+)
+
+// TxTypeUpgrade enables, or disables if Disable is set, the custom transaction
+// type TxType for blocks with a timestamp of at least BlockTimestamp. The type
+// must be registered with [types.RegisterTxType] by every node of the chain.
+type TxTypeUpgrade struct {
+	TxType         uint8  `json:"txType"`
+	BlockTimestamp uint64 `json:"blockTimestamp"`
+	Disable        bool   `json:"disable,omitempty"`
+}
+
+// IsTxTypeEnabled returns whether the custom transaction type [txType] is
+// enabled for blocks at [time].
+func (c *ChainConfig) IsTxTypeEnabled(txType uint8, time uint64) bool {
+	enabled := false
+	for _, upgrade := range c.TxTypeUpgrades {
+		if upgrade.BlockTimestamp > time {
+			break
+		}
+		if upgrade.TxType == txType {
+			enabled = !upgrade.Disable
+		}
+	}
+	return enabled
+}
+
+// verifyTxTypeUpgrades checks that the tx type upgrades are ordered, only
+// change custom types, and alternate between enabling and disabling each
+// type, starting with enabling it.
+func (c *ChainConfig) verifyTxTypeUpgrades() error {
+	enabled := make(map[uint8]bool)
+	for i, upgrade := range c.TxTypeUpgrades {
+		if upgrade.TxType < minCustomTxType {
+			return fmt.Errorf("%w: %#x at [%d]", errTxTypeUpgradeBuiltin, upgrade.TxType, i)
+		}
+		if i > 0 && upgrade.BlockTimestamp < c.TxTypeUpgrades[i-1].BlockTimestamp {
+			return fmt.Errorf("%w: %d follows %d", errTxTypeUpgradeUnordered, upgrade.BlockTimestamp, c.TxTypeUpgrades[i-1].BlockTimestamp)
+		}
+		if enabled[upgrade.TxType] != upgrade.Disable {
+			return fmt.Errorf("%w: type %#x at [%d] should have disable %t", errTxTypeUpgradeDisable, upgrade.TxType, i, enabled[upgrade.TxType])
+		}
+		enabled[upgrade.TxType] = !upgrade.Disable
+	}
+	return nil
+}
+
+// checkTxTypeUpgradesCompatible returns an error if [newcfg] changes the tx
+// type upgrades in effect at or before [time].
+func (c *ChainConfig) checkTxTypeUpgradesCompatible(newcfg *ChainConfig, time uint64) *ConfigCompatError {
+	var (
+		stored = c.TxTypeUpgrades
+		next   = newcfg.TxTypeUpgrades
+	)
+	for i := 0; i < len(stored) || i < len(next); i++ {
+		var storedTime, nextTime *uint64
+		if i < len(stored) {
+			storedTime = &stored[i].BlockTimestamp
+		}
+		if i < len(next) {
+			nextTime = &next[i].BlockTimestamp
+		}
+		if (storedTime == nil || *storedTime > time) && (nextTime == nil || *nextTime > time) {
+			return nil
+		}
+		if storedTime == nil || nextTime == nil || stored[i] != next[i] {
+			return newTimestampCompatError(fmt.Sprintf("TxTypeUpgrade[%d] timestamp", i), storedTime, nextTime)
+		}
+	}
+	return nil
+}
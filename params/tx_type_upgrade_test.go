@@ -0,0 +1,92 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package params
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTxTypeUpgrades(t *testing.T) {
+	config := &ChainConfig{UpgradeConfig: UpgradeConfig{TxTypeUpgrades: []TxTypeUpgrade{
+		{TxType: 0x10, BlockTimestamp: 100},
+		{TxType: 0x11, BlockTimestamp: 100},
+		{TxType: 0x10, BlockTimestamp: 200, Disable: true},
+	}}}
+	if err := config.verifyTxTypeUpgrades(); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		txType  uint8
+		time    uint64
+		enabled bool
+	}{
+		{txType: 0x10, time: 99},
+		{txType: 0x10, time: 100, enabled: true},
+		{txType: 0x10, time: 200},
+		{txType: 0x11, time: 250, enabled: true},
+		{txType: 0x12, time: 250},
+	}
+	for _, test := range tests {
+		if have := config.IsTxTypeEnabled(test.txType, test.time); have != test.enabled {
+			t.Errorf("type %#x at %d: have enabled %t, want %t", test.txType, test.time, have, test.enabled)
+		}
+	}
+}
+
+func TestVerifyTxTypeUpgrades(t *testing.T) {
+	tests := []struct {
+		upgrades []TxTypeUpgrade
+		want     error
+	}{
+		{
+			upgrades: []TxTypeUpgrade{{TxType: 0x02, BlockTimestamp: 100}},
+			want:     errTxTypeUpgradeBuiltin,
+		},
+		{
+			upgrades: []TxTypeUpgrade{{TxType: 0x10, BlockTimestamp: 200}, {TxType: 0x11, BlockTimestamp: 100}},
+			want:     errTxTypeUpgradeUnordered,
+		},
+		{
+			upgrades: []TxTypeUpgrade{{TxType: 0x10, BlockTimestamp: 100, Disable: true}},
+			want:     errTxTypeUpgradeDisable,
+		},
+		{
+			upgrades: []TxTypeUpgrade{{TxType: 0x10, BlockTimestamp: 100}, {TxType: 0x10, BlockTimestamp: 200}},
+			want:     errTxTypeUpgradeDisable,
+		},
+	}
+	for i, test := range tests {
+		config := &ChainConfig{UpgradeConfig: UpgradeConfig{TxTypeUpgrades: test.upgrades}}
+		if err := config.verifyTxTypeUpgrades(); !errors.Is(err, test.want) {
+			t.Errorf("test %d: have error %v, want %v", i, err, test.want)
+		}
+	}
+}
+
+func TestCheckTxTypeUpgradesCompatible(t *testing.T) {
+	stored := &ChainConfig{UpgradeConfig: UpgradeConfig{TxTypeUpgrades: []TxTypeUpgrade{
+		{TxType: 0x10, BlockTimestamp: 100},
+		{TxType: 0x10, BlockTimestamp: 200, Disable: true},
+	}}}
+	// Upgrades scheduled after the head may change.
+	rescheduled := &ChainConfig{UpgradeConfig: UpgradeConfig{TxTypeUpgrades: []TxTypeUpgrade{
+		{TxType: 0x10, BlockTimestamp: 100},
+		{TxType: 0x10, BlockTimestamp: 300, Disable: true},
+	}}}
+	if err := stored.checkTxTypeUpgradesCompatible(rescheduled, 150); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Upgrades in effect at the head may not.
+	err := stored.checkTxTypeUpgradesCompatible(rescheduled, 250)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if err.RewindToTime != 199 {
+		t.Fatalf("rewind mismatch: have %d, want %d", err.RewindToTime, 199)
+	}
+	if err := stored.checkTxTypeUpgradesCompatible(&ChainConfig{}, 150); err == nil {
+		t.Fatal("expected error when removing an upgrade in effect")
+	}
+}
@@ -37,6 +37,9 @@ type NetworkClient interface {
 	// TrackBandwidth should be called for each valid request with the bandwidth
 	// (length of response divided by request time), and with 0 if the response is invalid.
 	TrackBandwidth(nodeID ids.NodeID, bandwidth float64)
+
+	// Peers returns the connected peers running at least [minVersion].
+	Peers(minVersion *version.Application) []ids.NodeID
 }
 
 // client implements NetworkClient interface
@@ -90,3 +93,7 @@ func (c *client) SendCrossChainRequest(ctx context.Context, chainID ids.ID, requ
 func (c *client) TrackBandwidth(nodeID ids.NodeID, bandwidth float64) {
 	c.network.TrackBandwidth(nodeID, bandwidth)
 }
+
+func (c *client) Peers(minVersion *version.Application) []ids.NodeID {
+	return c.network.Peers(minVersion)
+}
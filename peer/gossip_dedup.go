@@ -0,0 +1,67 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package peer
+
+import (
+	"sync"
+
+	"github.com/shubhamdubey02/cryftgo/cache"
+	"github.com/shubhamdubey02/cryftgo/ids"
+	"github.com/shubhamdubey02/cryftgo/utils/hashing"
+
+	"github.com/shubhamdubey02/coreth/metrics"
+)
+
+// gossipDeduplicator drops the gossip payloads identical to one of the most
+// recently received distinct payloads, so that gossip forwarded by many peers
+// is decoded and handled only once.
+type gossipDeduplicator struct {
+	// lock ensures concurrent copies of a payload are handled once.
+	lock sync.Mutex
+	seen *cache.LRU[ids.ID, struct{}]
+
+	received       metrics.Counter
+	duplicates     metrics.Counter
+	duplicateRatio metrics.GaugeFloat64
+}
+
+// newGossipDeduplicator returns a deduplicator remembering the hashes of the
+// [size] most recent distinct payloads, or nil if [size] is not positive, in
+// which case no payload is dropped.
+func newGossipDeduplicator(size int) *gossipDeduplicator {
+	if size <= 0 {
+		return nil
+	}
+	return &gossipDeduplicator{
+		seen:           &cache.LRU[ids.ID, struct{}]{Size: size},
+		received:       metrics.GetOrRegisterCounter("net_gossip_received", nil),
+		duplicates:     metrics.GetOrRegisterCounter("net_gossip_duplicates", nil),
+		duplicateRatio: metrics.GetOrRegisterGaugeFloat64("net_gossip_duplicate_ratio", nil),
+	}
+}
+
+// duplicate returns whether [gossipBytes] is a duplicate of a recently
+// received payload, and remembers it otherwise.
+func (d *gossipDeduplicator) duplicate(gossipBytes []byte) bool {
+	if d == nil {
+		return false
+	}
+	id := ids.ID(hashing.ComputeHash256Array(gossipBytes))
+
+	d.lock.Lock()
+	_, seen := d.seen.Get(id)
+	if !seen {
+		d.seen.Put(id, struct{}{})
+	}
+	d.lock.Unlock()
+
+	d.received.Inc(1)
+	if seen {
+		d.duplicates.Inc(1)
+	}
+	if received := d.received.Snapshot().Count(); received > 0 {
+		d.duplicateRatio.Update(float64(d.duplicates.Snapshot().Count()) / float64(received))
+	}
+	return seen
+}
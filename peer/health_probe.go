@@ -0,0 +1,107 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package peer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/shubhamdubey02/cryftgo/ids"
+
+	"github.com/shubhamdubey02/coreth/plugin/evm/message"
+)
+
+// HealthProbeConfig configures the probing of idle peers, which keeps the
+// peers [SendAppRequestAny] picks from limited to peers that still answer.
+type HealthProbeConfig struct {
+	// Interval is how often idle peers are probed.
+	Interval time.Duration
+	// IdleTimeout is how long a tracked peer must go without requests before
+	// it is probed.
+	IdleTimeout time.Duration
+	// Timeout is how long a peer has to answer a probe.
+	Timeout time.Duration
+	// MaxFailures is the number of consecutive probes a peer may fail to
+	// answer before it is expired.
+	MaxFailures int
+}
+
+// StartHealthProbes probes idle peers in the background according to
+// [config] until [Shutdown] is called. Probes are capabilities requests,
+// which every peer answers cheaply.
+func (n *network) StartHealthProbes(config HealthProbeConfig) error {
+	request, err := message.RequestToBytes(n.codec, message.CapabilitiesRequest{})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	n.probeCancel = cancel
+	n.probeWg.Add(1)
+	go func() {
+		defer n.probeWg.Done()
+
+		ticker := time.NewTicker(config.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				n.probeIdlePeers(ctx, config, request)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// probeIdlePeers sends [request] to each peer idle for longer than the idle
+// timeout of [config], and waits for the answers.
+func (n *network) probeIdlePeers(ctx context.Context, config HealthProbeConfig, request []byte) {
+	n.lock.RLock()
+	idle := n.peers.IdlePeers(time.Now().Add(-config.IdleTimeout))
+	n.lock.RUnlock()
+	if len(idle) == 0 {
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, nodeID := range idle {
+		wg.Add(1)
+		go func(nodeID ids.NodeID) {
+			defer wg.Done()
+			n.probe(ctx, config, nodeID, request)
+		}(nodeID)
+	}
+	wg.Wait()
+}
+
+func (n *network) probe(ctx context.Context, config HealthProbeConfig, nodeID ids.NodeID, request []byte) {
+	probeCtx, cancel := context.WithTimeout(ctx, config.Timeout)
+	defer cancel()
+
+	handler := newWaitingResponseHandler()
+	err := n.SendAppRequest(probeCtx, nodeID, request, handler)
+	var response []byte
+	if err == nil {
+		response, err = handler.WaitForResult(probeCtx)
+	}
+	if ctx.Err() != nil {
+		// Probes interrupted by shutdown are not held against peers.
+		return
+	}
+
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if err == nil && len(response) > 0 {
+		n.peers.ProbeSucceeded(nodeID)
+		return
+	}
+	if n.peers.ProbeFailed(nodeID, config.MaxFailures) {
+		log.Debug("expired peer failing health probes", "nodeID", nodeID, "failures", config.MaxFailures, "err", err)
+	}
+}
@@ -71,6 +71,16 @@ type Network interface {
 	// Size returns the size of the network in number of connected peers
 	Size() uint32
 
+	// Peers returns the connected peers running at least [minVersion].
+	Peers(minVersion *version.Application) []ids.NodeID
+
+	// PeerReputation returns the bandwidth of known peers, to be restored with
+	// LoadPeerReputation after a restart.
+	PeerReputation() map[ids.NodeID]float64
+	// LoadPeerReputation restores the bandwidth of peers, so that historically
+	// good peers are preferred as soon as they connect.
+	LoadPeerReputation(reputation map[ids.NodeID]float64)
+
 	// TrackBandwidth should be called for each valid request with the bandwidth
 	// (length of response divided by request time), and with 0 if the response is invalid.
 	TrackBandwidth(nodeID ids.NodeID, bandwidth float64)
@@ -79,6 +89,20 @@ type Network interface {
 	NewClient(protocol uint64, options ...p2p.ClientOption) *p2p.Client
 	// AddHandler registers a server handler for an application protocol
 	AddHandler(protocol uint64, handler p2p.Handler) error
+	// AddVersionedHandler registers a server handler for an application
+	// protocol, serving peers running at least [minVersion]. Several versions
+	// may be registered for a protocol, each peer being served by the newest
+	// version it runs.
+	AddVersionedHandler(protocol uint64, minVersion *version.Application, handler p2p.Handler) error
+	// NegotiateVersion returns the minimum version of the newest handler of
+	// [protocol] run by [nodeID], so that requests to it use the matching
+	// message format. Returns false if [nodeID] runs none of them.
+	NegotiateVersion(protocol uint64, nodeID ids.NodeID) (*version.Application, bool)
+
+	// StartHealthProbes periodically probes peers that were not sent
+	// requests recently, demoting peers which do not answer and expiring
+	// them after repeated failures.
+	StartHealthProbes(config HealthProbeConfig) error
 }
 
 // network is an implementation of Network that processes message requests for
@@ -100,6 +124,13 @@ type network struct {
 	peers                      *peerTracker                     // tracking of peers & bandwidth
 	appStats                   stats.RequestHandlerStats        // Provide request handler metrics
 	crossChainStats            stats.RequestHandlerStats        // Provide cross chain request handler metrics
+	versionedHandlers          map[uint64]*versionedHandler     // maps protocol => versioned handler
+	gossipDedup                *gossipDeduplicator              // drops duplicate gossip payloads
+
+	// probeCancel stops the health probes of idle peers, if started, and
+	// probeWg tracks their goroutine.
+	probeCancel context.CancelFunc
+	probeWg     sync.WaitGroup
 
 	// Set to true when Shutdown is called, after which all operations on this
 	// struct are no-ops.
@@ -112,7 +143,10 @@ type network struct {
 	closed utils.Atomic[bool]
 }
 
-func NewNetwork(p2pNetwork *p2p.Network, appSender common.AppSender, codec codec.Manager, crossChainCodec codec.Manager, self ids.NodeID, maxActiveAppRequests int64, maxActiveCrossChainRequests int64) Network {
+// NewNetwork returns a network handling the messages of peers. Gossip
+// payloads identical to one of the [gossipDedupSize] most recent distinct
+// payloads are dropped, unless [gossipDedupSize] is 0.
+func NewNetwork(p2pNetwork *p2p.Network, appSender common.AppSender, codec codec.Manager, crossChainCodec codec.Manager, self ids.NodeID, maxActiveAppRequests int64, maxActiveCrossChainRequests int64, gossipDedupSize int) Network {
 	return &network{
 		appSender:                  appSender,
 		codec:                      codec,
@@ -121,6 +155,7 @@ func NewNetwork(p2pNetwork *p2p.Network, appSender common.AppSender, codec codec
 		outstandingRequestHandlers: make(map[uint32]message.ResponseHandler),
 		activeAppRequests:          semaphore.NewWeighted(maxActiveAppRequests),
 		activeCrossChainRequests:   semaphore.NewWeighted(maxActiveCrossChainRequests),
+		gossipDedup:                newGossipDeduplicator(gossipDedupSize),
 		p2pNetwork:                 p2pNetwork,
 		gossipHandler:              message.NoopMempoolGossipHandler{},
 		appRequestHandler:          message.NoopRequestHandler{},
@@ -128,6 +163,7 @@ func NewNetwork(p2pNetwork *p2p.Network, appSender common.AppSender, codec codec
 		peers:                      NewPeerTracker(),
 		appStats:                   stats.NewRequestHandlerStats(),
 		crossChainStats:            stats.NewCrossChainRequestHandlerStats(),
+		versionedHandlers:          make(map[uint64]*versionedHandler),
 	}
 }
 
@@ -484,6 +520,11 @@ func (n *network) markRequestFulfilled(requestID uint32) (message.ResponseHandle
 // from a peer. An error returned by this function is treated as fatal by the
 // engine.
 func (n *network) AppGossip(ctx context.Context, nodeID ids.NodeID, gossipBytes []byte) error {
+	if n.gossipDedup.duplicate(gossipBytes) {
+		log.Trace("dropping duplicate AppGossip", "nodeID", nodeID, "gossipLen", len(gossipBytes))
+		return nil
+	}
+
 	var gossipMsg message.GossipMessage
 	if _, err := n.codec.Unmarshal(gossipBytes, &gossipMsg); err != nil {
 		log.Debug("forwarding AppGossip to SDK network", "nodeID", nodeID, "gossipLen", len(gossipBytes), "err", err)
@@ -533,6 +574,12 @@ func (n *network) Disconnected(ctx context.Context, nodeID ids.NodeID) error {
 
 // Shutdown disconnects all peers
 func (n *network) Shutdown() {
+	// Stop the health probes before taking the lock, which they acquire.
+	if n.probeCancel != nil {
+		n.probeCancel()
+	}
+	n.probeWg.Wait()
+
 	n.lock.Lock()
 	defer n.lock.Unlock()
 
@@ -574,6 +621,27 @@ func (n *network) Size() uint32 {
 	return uint32(n.peers.Size())
 }
 
+func (n *network) Peers(minVersion *version.Application) []ids.NodeID {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	return n.peers.Peers(minVersion)
+}
+
+func (n *network) PeerReputation() map[ids.NodeID]float64 {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	return n.peers.Reputation()
+}
+
+func (n *network) LoadPeerReputation(reputation map[ids.NodeID]float64) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.peers.LoadReputation(reputation)
+}
+
 func (n *network) TrackBandwidth(nodeID ids.NodeID, bandwidth float64) {
 	n.lock.Lock()
 	defer n.lock.Unlock()
@@ -589,6 +657,41 @@ func (n *network) AddHandler(protocol uint64, handler p2p.Handler) error {
 	return n.p2pNetwork.AddHandler(protocol, handler)
 }
 
+func (n *network) AddVersionedHandler(protocol uint64, minVersion *version.Application, handler p2p.Handler) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	versioned, ok := n.versionedHandlers[protocol]
+	if !ok {
+		versioned = newVersionedHandler(n.peerVersion)
+		if err := n.p2pNetwork.AddHandler(protocol, versioned); err != nil {
+			return err
+		}
+		n.versionedHandlers[protocol] = versioned
+	}
+	return versioned.add(minVersion, handler)
+}
+
+func (n *network) NegotiateVersion(protocol uint64, nodeID ids.NodeID) (*version.Application, bool) {
+	n.lock.RLock()
+	versioned, ok := n.versionedHandlers[protocol]
+	n.lock.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	negotiated, ok := versioned.negotiate(nodeID)
+	return negotiated.minVersion, ok
+}
+
+// peerVersion returns the application version advertised by [nodeID].
+// Assumes the lock is not held.
+func (n *network) peerVersion(nodeID ids.NodeID) (*version.Application, bool) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	return n.peers.Version(nodeID)
+}
+
 // invariant: peer/network must use explicitly even request ids.
 // for this reason, [n.requestID] is initialized as zero and incremented by 2.
 // This is for backwards-compatibility while the SDK router exists with the
@@ -59,7 +59,7 @@ func TestNetworkDoesNotConnectToItself(t *testing.T) {
 	selfNodeID := ids.GenerateTestNodeID()
 	p2pNetwork, err := p2p.NewNetwork(logging.NoLog{}, nil, prometheus.NewRegistry(), "")
 	require.NoError(t, err)
-	n := NewNetwork(p2pNetwork, nil, nil, nil, selfNodeID, 1, 1)
+	n := NewNetwork(p2pNetwork, nil, nil, nil, selfNodeID, 1, 1, 0)
 	assert.NoError(t, n.Connected(context.Background(), selfNodeID, defaultPeerVersion))
 	assert.EqualValues(t, 0, n.Size())
 }
@@ -97,7 +97,7 @@ func TestRequestAnyRequestsRoutingAndResponse(t *testing.T) {
 	crossChainCodecManager := buildCodec(t, ExampleCrossChainRequest{}, ExampleCrossChainResponse{})
 	p2pNetwork, err := p2p.NewNetwork(logging.NoLog{}, nil, prometheus.NewRegistry(), "")
 	require.NoError(t, err)
-	net = NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 16, 16)
+	net = NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 16, 16, 0)
 	net.SetRequestHandler(&HelloGreetingRequestHandler{codec: codecManager})
 	client := NewNetworkClient(net)
 	nodeID := ids.GenerateTestNodeID()
@@ -151,7 +151,7 @@ func TestAppRequestOnCtxCancellation(t *testing.T) {
 
 	p2pNetwork, err := p2p.NewNetwork(logging.NoLog{}, nil, prometheus.NewRegistry(), "")
 	require.NoError(t, err)
-	net := NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1)
+	net := NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1, 0)
 	net.SetRequestHandler(&HelloGreetingRequestHandler{codec: codecManager})
 
 	requestMessage := HelloRequest{Message: "this is a request"}
@@ -205,7 +205,7 @@ func TestRequestRequestsRoutingAndResponse(t *testing.T) {
 	crossChainCodecManager := buildCodec(t, ExampleCrossChainRequest{}, ExampleCrossChainResponse{})
 	p2pNetwork, err := p2p.NewNetwork(logging.NoLog{}, nil, prometheus.NewRegistry(), "")
 	require.NoError(t, err)
-	net = NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 16, 16)
+	net = NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 16, 16, 0)
 	net.SetRequestHandler(&HelloGreetingRequestHandler{codec: codecManager})
 	client := NewNetworkClient(net)
 
@@ -287,7 +287,7 @@ func TestAppRequestOnShutdown(t *testing.T) {
 	crossChainCodecManager := buildCodec(t, ExampleCrossChainRequest{}, ExampleCrossChainResponse{})
 	p2pNetwork, err := p2p.NewNetwork(logging.NoLog{}, nil, prometheus.NewRegistry(), "")
 	require.NoError(t, err)
-	net = NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1)
+	net = NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1, 0)
 	client := NewNetworkClient(net)
 	nodeID := ids.GenerateTestNodeID()
 	require.NoError(t, net.Connected(context.Background(), nodeID, defaultPeerVersion))
@@ -338,7 +338,7 @@ func TestAppRequestAnyOnCtxCancellation(t *testing.T) {
 
 	p2pNetwork, err := p2p.NewNetwork(logging.NoLog{}, nil, prometheus.NewRegistry(), "")
 	require.NoError(t, err)
-	net := NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1)
+	net := NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1, 0)
 	net.SetRequestHandler(&HelloGreetingRequestHandler{codec: codecManager})
 	assert.NoError(t,
 		net.Connected(
@@ -417,7 +417,7 @@ func TestRequestMinVersion(t *testing.T) {
 	// passing nil as codec works because the net.AppRequest is never called
 	p2pNetwork, err := p2p.NewNetwork(logging.NoLog{}, nil, prometheus.NewRegistry(), "")
 	require.NoError(t, err)
-	net = NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 16)
+	net = NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 16, 0)
 	client := NewNetworkClient(net)
 	requestMessage := TestMessage{Message: "this is a request"}
 	requestBytes, err := message.RequestToBytes(codecManager, requestMessage)
@@ -485,7 +485,7 @@ func TestOnRequestHonoursDeadline(t *testing.T) {
 
 	p2pNetwork, err := p2p.NewNetwork(logging.NoLog{}, nil, prometheus.NewRegistry(), "")
 	require.NoError(t, err)
-	net = NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1)
+	net = NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1, 0)
 	net.SetRequestHandler(requestHandler)
 	nodeID := ids.GenerateTestNodeID()
 
@@ -513,7 +513,7 @@ func TestHandleInvalidMessages(t *testing.T) {
 
 	p2pNetwork, err := p2p.NewNetwork(logging.NoLog{}, nil, prometheus.NewRegistry(), "")
 	require.NoError(t, err)
-	clientNetwork := NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1)
+	clientNetwork := NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1, 0)
 	clientNetwork.SetGossipHandler(message.NoopMempoolGossipHandler{})
 	clientNetwork.SetRequestHandler(&testRequestHandler{})
 
@@ -564,7 +564,7 @@ func TestNetworkPropagatesRequestHandlerError(t *testing.T) {
 
 	p2pNetwork, err := p2p.NewNetwork(logging.NoLog{}, nil, prometheus.NewRegistry(), "")
 	require.NoError(t, err)
-	clientNetwork := NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1)
+	clientNetwork := NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1, 0)
 	clientNetwork.SetGossipHandler(message.NoopMempoolGossipHandler{})
 	clientNetwork.SetRequestHandler(&testRequestHandler{err: errors.New("fail")}) // Return an error from the request handler
 
@@ -606,7 +606,7 @@ func TestCrossChainAppRequest(t *testing.T) {
 
 	p2pNetwork, err := p2p.NewNetwork(logging.NoLog{}, nil, prometheus.NewRegistry(), "")
 	require.NoError(t, err)
-	net = NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1)
+	net = NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1, 0)
 	net.SetCrossChainRequestHandler(&testCrossChainHandler{codec: crossChainCodecManager})
 	client := NewNetworkClient(net)
 
@@ -643,7 +643,7 @@ func TestCrossChainAppRequestOnCtxCancellation(t *testing.T) {
 
 	p2pNetwork, err := p2p.NewNetwork(logging.NoLog{}, nil, prometheus.NewRegistry(), "")
 	require.NoError(t, err)
-	net := NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1)
+	net := NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1, 0)
 	net.SetCrossChainRequestHandler(&testCrossChainHandler{codec: crossChainCodecManager})
 
 	exampleCrossChainRequest := ExampleCrossChainRequest{
@@ -697,7 +697,7 @@ func TestCrossChainRequestRequestsRoutingAndResponse(t *testing.T) {
 	crossChainCodecManager := buildCodec(t, ExampleCrossChainRequest{}, ExampleCrossChainResponse{})
 	p2pNetwork, err := p2p.NewNetwork(logging.NoLog{}, nil, prometheus.NewRegistry(), "")
 	require.NoError(t, err)
-	net = NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1)
+	net = NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1, 0)
 	net.SetCrossChainRequestHandler(&testCrossChainHandler{codec: crossChainCodecManager})
 	client := NewNetworkClient(net)
 
@@ -759,7 +759,7 @@ func TestCrossChainRequestOnShutdown(t *testing.T) {
 	crossChainCodecManager := buildCodec(t, ExampleCrossChainRequest{}, ExampleCrossChainResponse{})
 	p2pNetwork, err := p2p.NewNetwork(logging.NoLog{}, nil, prometheus.NewRegistry(), "")
 	require.NoError(t, err)
-	net = NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1)
+	net = NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1, 0)
 	client := NewNetworkClient(net)
 
 	exampleCrossChainRequest := ExampleCrossChainRequest{
@@ -780,10 +780,42 @@ func TestCrossChainRequestOnShutdown(t *testing.T) {
 	require.True(t, called)
 }
 
+func TestNetworkGossipDeduplication(t *testing.T) {
+	require := require.New(t)
+
+	codecManager := buildCodec(t, HelloGossip{}, TestMessage{})
+	p2pNetwork, err := p2p.NewNetwork(logging.NoLog{}, nil, prometheus.NewRegistry(), "")
+	require.NoError(err)
+	net := NewNetwork(p2pNetwork, nil, codecManager, codec.NewManager(0), ids.EmptyNodeID, 1, 1, 2)
+	handler := &testGossipHandler{}
+	net.SetGossipHandler(handler)
+
+	gossip := func(msg string) {
+		gossipBytes, err := buildGossip(codecManager, HelloGossip{Msg: msg})
+		require.NoError(err)
+		require.NoError(net.AppGossip(context.Background(), ids.GenerateTestNodeID(), gossipBytes))
+	}
+
+	// Identical payloads forwarded by several peers are handled once.
+	for i := 0; i < 3; i++ {
+		gossip("hello")
+	}
+	require.Equal(1, handler.calls)
+
+	gossip("foo")
+	gossip("bar")
+	require.Equal(3, handler.calls)
+
+	// Payloads are only remembered within the window of the most recent
+	// distinct payloads.
+	gossip("hello")
+	require.Equal(4, handler.calls)
+}
+
 func TestNetworkAppRequestAfterShutdown(t *testing.T) {
 	require := require.New(t)
 
-	net := NewNetwork(nil, nil, nil, nil, ids.EmptyNodeID, 1, 0)
+	net := NewNetwork(nil, nil, nil, nil, ids.EmptyNodeID, 1, 0, 0)
 	net.Shutdown()
 
 	require.NoError(net.SendAppRequest(context.Background(), ids.GenerateTestNodeID(), nil, nil))
@@ -793,7 +825,7 @@ func TestNetworkAppRequestAfterShutdown(t *testing.T) {
 func TestNetworkCrossChainAppRequestAfterShutdown(t *testing.T) {
 	require := require.New(t)
 
-	net := NewNetwork(nil, nil, nil, nil, ids.EmptyNodeID, 0, 1)
+	net := NewNetwork(nil, nil, nil, nil, ids.EmptyNodeID, 0, 1, 0)
 	net.Shutdown()
 
 	require.NoError(net.SendCrossChainRequest(context.Background(), ids.GenerateTestID(), nil, nil))
@@ -827,6 +859,7 @@ func TestNetworkRouting(t *testing.T) {
 		ids.EmptyNodeID,
 		1,
 		1,
+		0,
 	)
 
 	nodeID := ids.GenerateTestNodeID()
@@ -842,6 +875,65 @@ func TestNetworkRouting(t *testing.T) {
 	require.ErrorIs(err, p2p.ErrUnrequestedResponse)
 }
 
+func TestNetworkVersionedHandlers(t *testing.T) {
+	require := require.New(t)
+	sender := &testAppSender{
+		sendAppResponseFn: func(id ids.NodeID, u uint32, bytes []byte) error {
+			return nil
+		},
+	}
+	p2pNetwork, err := p2p.NewNetwork(logging.NoLog{}, sender, prometheus.NewRegistry(), "")
+	require.NoError(err)
+	network := NewNetwork(p2pNetwork, nil, codec.NewManager(0), codec.NewManager(0), ids.EmptyNodeID, 1, 1, 0)
+
+	var (
+		protocol = uint64(1)
+		v1       = &version.Application{Major: 1, Minor: 0, Patch: 0}
+		v2       = &version.Application{Major: 1, Minor: 2, Patch: 0}
+		handler1 = &testSDKHandler{}
+		handler2 = &testSDKHandler{}
+	)
+	require.NoError(network.AddVersionedHandler(protocol, v2, handler2))
+	require.NoError(network.AddVersionedHandler(protocol, v1, handler1))
+	require.ErrorIs(network.AddVersionedHandler(protocol, &version.Application{Major: 1, Minor: 2, Patch: 0}, handler1), errDuplicateHandlerVersion)
+	require.Error(network.AddHandler(protocol, handler1))
+
+	var (
+		oldPeer     = ids.GenerateTestNodeID()
+		newPeer     = ids.GenerateTestNodeID()
+		ancientPeer = ids.GenerateTestNodeID()
+	)
+	require.NoError(network.Connected(context.Background(), oldPeer, &version.Application{Major: 1, Minor: 1, Patch: 5}))
+	require.NoError(network.Connected(context.Background(), newPeer, &version.Application{Major: 1, Minor: 3, Patch: 0}))
+	require.NoError(network.Connected(context.Background(), ancientPeer, &version.Application{Major: 0, Minor: 9, Patch: 0}))
+
+	negotiated, ok := network.NegotiateVersion(protocol, oldPeer)
+	require.True(ok)
+	require.Equal(v1, negotiated)
+	negotiated, ok = network.NegotiateVersion(protocol, newPeer)
+	require.True(ok)
+	require.Equal(v2, negotiated)
+	_, ok = network.NegotiateVersion(protocol, ancientPeer)
+	require.False(ok)
+	_, ok = network.NegotiateVersion(protocol+1, newPeer)
+	require.False(ok)
+
+	request := append([]byte{byte(protocol)}, []byte("foobar")...)
+	require.NoError(network.AppRequest(context.Background(), oldPeer, 0, time.Now().Add(time.Minute), request))
+	require.True(handler1.appRequested)
+	require.False(handler2.appRequested)
+
+	handler1.appRequested = false
+	require.NoError(network.AppRequest(context.Background(), newPeer, 2, time.Now().Add(time.Minute), request))
+	require.False(handler1.appRequested)
+	require.True(handler2.appRequested)
+
+	handler2.appRequested = false
+	require.NoError(network.AppRequest(context.Background(), ancientPeer, 4, time.Now().Add(time.Minute), request))
+	require.False(handler1.appRequested)
+	require.False(handler2.appRequested)
+}
+
 func buildCodec(t *testing.T, types ...interface{}) codec.Manager {
 	codecManager := codec.NewDefaultManager()
 	c := linearcodec.NewDefault()
@@ -985,17 +1077,20 @@ func (h HelloGossip) Bytes() []byte {
 
 type testGossipHandler struct {
 	received bool
+	calls    int
 	nodeID   ids.NodeID
 }
 
 func (t *testGossipHandler) HandleAtomicTx(nodeID ids.NodeID, msg message.AtomicTxGossip) error {
 	t.received = true
+	t.calls++
 	t.nodeID = nodeID
 	return nil
 }
 
 func (t *testGossipHandler) HandleEthTxs(nodeID ids.NodeID, msg message.EthTxsGossip) error {
 	t.received = true
+	t.calls++
 	t.nodeID = nodeID
 	return nil
 }
@@ -4,10 +4,14 @@
 package peer
 
 import (
+	"cmp"
 	"math"
 	"math/rand"
+	"slices"
 	"time"
 
+	"golang.org/x/exp/maps"
+
 	"github.com/shubhamdubey02/cryftgo/ids"
 	utils_math "github.com/shubhamdubey02/cryftgo/utils/math"
 	"github.com/shubhamdubey02/cryftgo/utils/set"
@@ -29,12 +33,21 @@ const (
 	// controls how often we prefer a random responsive peer over the most
 	// performant peer.
 	randomPeerProbability = 0.2
+
+	// maximum number of peers whose bandwidth is remembered, keeping the
+	// peers with the highest bandwidth.
+	maxPeerHistory = 1024
 )
 
 // information we track on a given peer
 type peerInfo struct {
 	version   *version.Application
 	bandwidth utils_math.Averager
+	// lastActive is the last time the peer was sent a request or its
+	// response was tracked, and failedProbes the number of consecutive
+	// health probes it did not answer.
+	lastActive   time.Time
+	failedProbes int
 }
 
 // peerTracker tracks the bandwidth of responses coming from peers,
@@ -50,6 +63,8 @@ type peerTracker struct {
 	bandwidthHeap          utils_math.AveragerHeap // tracks bandwidth peers are responding with
 	averageBandwidthMetric metrics.GaugeFloat64
 	averageBandwidth       utils_math.Averager
+	history                map[ids.NodeID]float64 // bandwidth of disconnected or not yet connected peers
+	numExpiredPeers        metrics.Counter        // peers expired after failing health probes
 }
 
 func NewPeerTracker() *peerTracker {
@@ -62,6 +77,8 @@ func NewPeerTracker() *peerTracker {
 		bandwidthHeap:          utils_math.NewMaxAveragerHeap(),
 		averageBandwidthMetric: metrics.GetOrRegisterGaugeFloat64("net_average_bandwidth", nil),
 		averageBandwidth:       utils_math.NewAverager(0, bandwidthHalflife, time.Now()),
+		history:                make(map[ids.NodeID]float64),
+		numExpiredPeers:        metrics.GetOrRegisterCounter("net_expired_peers", nil),
 	}
 }
 
@@ -130,9 +147,35 @@ func (p *peerTracker) GetAnyPeer(minVersion *version.Application) (ids.NodeID, b
 	return p.trackedPeers.Peek()
 }
 
+// Peers returns the connected peers running at least [minVersion], or all
+// connected peers if [minVersion] is nil.
+func (p *peerTracker) Peers(minVersion *version.Application) []ids.NodeID {
+	peers := make([]ids.NodeID, 0, len(p.peers))
+	for nodeID, peer := range p.peers {
+		if minVersion != nil && peer.version.Compare(minVersion) < 0 {
+			continue
+		}
+		peers = append(peers, nodeID)
+	}
+	return peers
+}
+
+// Version returns the application version advertised by [nodeID], or false if
+// it is not connected.
+func (p *peerTracker) Version(nodeID ids.NodeID) (*version.Application, bool) {
+	peer := p.peers[nodeID]
+	if peer == nil {
+		return nil, false
+	}
+	return peer.version, true
+}
+
 func (p *peerTracker) TrackPeer(nodeID ids.NodeID) {
 	p.trackedPeers.Add(nodeID)
 	p.numTrackedPeers.Update(int64(p.trackedPeers.Len()))
+	if peer := p.peers[nodeID]; peer != nil {
+		peer.lastActive = time.Now()
+	}
 }
 
 func (p *peerTracker) TrackBandwidth(nodeID ids.NodeID, bandwidth float64) {
@@ -144,6 +187,7 @@ func (p *peerTracker) TrackBandwidth(nodeID ids.NodeID, bandwidth float64) {
 	}
 
 	now := time.Now()
+	peer.lastActive = now
 	if peer.bandwidth == nil {
 		peer.bandwidth = utils_math.NewAverager(bandwidth, bandwidthHalflife, now)
 	} else {
@@ -169,8 +213,10 @@ func (p *peerTracker) Connected(nodeID ids.NodeID, nodeVersion *version.Applicat
 		// that we have already marked as Connected.
 		if nodeVersion.Compare(peer.version) != 0 {
 			p.peers[nodeID] = &peerInfo{
-				version:   nodeVersion,
-				bandwidth: peer.bandwidth,
+				version:      nodeVersion,
+				bandwidth:    peer.bandwidth,
+				lastActive:   peer.lastActive,
+				failedProbes: peer.failedProbes,
 			}
 			log.Warn("updating node version of already connected peer", "nodeID", nodeID, "storedVersion", peer.version, "nodeVersion", nodeVersion)
 		} else {
@@ -182,10 +228,20 @@ func (p *peerTracker) Connected(nodeID ids.NodeID, nodeVersion *version.Applicat
 	p.peers[nodeID] = &peerInfo{
 		version: nodeVersion,
 	}
+	// Prefer peers that were responsive before they disconnected, instead of
+	// relearning their bandwidth from scratch.
+	if bandwidth, ok := p.history[nodeID]; ok {
+		delete(p.history, nodeID)
+		p.TrackPeer(nodeID)
+		p.TrackBandwidth(nodeID, bandwidth)
+	}
 }
 
 // Disconnected should be called when [nodeID] disconnects from this node
 func (p *peerTracker) Disconnected(nodeID ids.NodeID) {
+	if peer := p.peers[nodeID]; peer != nil && peer.bandwidth != nil {
+		p.history[nodeID] = peer.bandwidth.Read()
+	}
 	p.bandwidthHeap.Remove(nodeID)
 	p.trackedPeers.Remove(nodeID)
 	p.numTrackedPeers.Update(int64(p.trackedPeers.Len()))
@@ -194,6 +250,103 @@ func (p *peerTracker) Disconnected(nodeID ids.NodeID) {
 	delete(p.peers, nodeID)
 }
 
+// IdlePeers returns the tracked peers which were neither sent a request nor
+// had a response tracked since [since].
+func (p *peerTracker) IdlePeers(since time.Time) []ids.NodeID {
+	var idle []ids.NodeID
+	for nodeID := range p.trackedPeers {
+		if peer := p.peers[nodeID]; peer != nil && peer.lastActive.Before(since) {
+			idle = append(idle, nodeID)
+		}
+	}
+	return idle
+}
+
+// ProbeSucceeded records that [nodeID] answered a health probe. The answer
+// marks the peer as responsive again without affecting its bandwidth, since
+// probes are too small to measure it.
+func (p *peerTracker) ProbeSucceeded(nodeID ids.NodeID) {
+	peer := p.peers[nodeID]
+	if peer == nil {
+		return
+	}
+	peer.lastActive = time.Now()
+	peer.failedProbes = 0
+	// Peers are only picked from the responsive set once their bandwidth is
+	// known.
+	if peer.bandwidth != nil && p.trackedPeers.Contains(nodeID) {
+		p.responsivePeers.Add(nodeID)
+		p.numResponsivePeers.Update(int64(p.responsivePeers.Len()))
+	}
+}
+
+// ProbeFailed records that [nodeID] did not answer a health probe, demoting
+// it as a failed request does. After [maxFailures] consecutive failed probes
+// the peer is expired: it is no longer picked by [GetAnyPeer] until it is
+// tracked again as a new peer. Returns whether the peer was expired.
+func (p *peerTracker) ProbeFailed(nodeID ids.NodeID, maxFailures int) bool {
+	peer := p.peers[nodeID]
+	if peer == nil {
+		return false
+	}
+	p.TrackBandwidth(nodeID, 0)
+	peer.failedProbes++
+	if peer.failedProbes < maxFailures {
+		return false
+	}
+
+	peer.bandwidth = nil
+	peer.failedProbes = 0
+	p.bandwidthHeap.Remove(nodeID)
+	p.trackedPeers.Remove(nodeID)
+	p.numTrackedPeers.Update(int64(p.trackedPeers.Len()))
+	p.responsivePeers.Remove(nodeID)
+	p.numResponsivePeers.Update(int64(p.responsivePeers.Len()))
+	p.numExpiredPeers.Inc(1)
+	return true
+}
+
+// Reputation returns the bandwidth of the peers that were sent requests,
+// including disconnected peers, limited to the [maxPeerHistory] peers with
+// the highest bandwidth. Failed requests count as zero bandwidth, so the
+// bandwidth of a peer also reflects its reliability.
+func (p *peerTracker) Reputation() map[ids.NodeID]float64 {
+	reputation := make(map[ids.NodeID]float64, len(p.history)+len(p.peers))
+	for nodeID, bandwidth := range p.history {
+		reputation[nodeID] = bandwidth
+	}
+	for nodeID, peer := range p.peers {
+		if peer.bandwidth != nil {
+			reputation[nodeID] = peer.bandwidth.Read()
+		}
+	}
+	if len(reputation) <= maxPeerHistory {
+		return reputation
+	}
+	nodeIDs := maps.Keys(reputation)
+	slices.SortFunc(nodeIDs, func(a, b ids.NodeID) int {
+		return cmp.Compare(reputation[b], reputation[a])
+	})
+	for _, nodeID := range nodeIDs[maxPeerHistory:] {
+		delete(reputation, nodeID)
+	}
+	return reputation
+}
+
+// LoadReputation restores the bandwidth of peers returned by [Reputation],
+// typically before a restart. Peers are preferred according to their restored
+// bandwidth once they connect.
+func (p *peerTracker) LoadReputation(reputation map[ids.NodeID]float64) {
+	for nodeID, bandwidth := range reputation {
+		if peer := p.peers[nodeID]; peer == nil {
+			p.history[nodeID] = bandwidth
+		} else if peer.bandwidth == nil {
+			p.TrackPeer(nodeID)
+			p.TrackBandwidth(nodeID, bandwidth)
+		}
+	}
+}
+
 // Size returns the number of peers the node is connected to
 func (p *peerTracker) Size() int {
 	return len(p.peers)
@@ -5,6 +5,7 @@ package peer
 
 import (
 	"testing"
+	"time"
 
 	"github.com/shubhamdubey02/cryftgo/ids"
 	"github.com/stretchr/testify/require"
@@ -86,3 +87,81 @@ func TestPeerTracker(t *testing.T) {
 	require.True(ok)
 	require.Falsef(responsive, "expected connecting to a non-responsive peer, but got a peer that was responsive: peer %s", peer)
 }
+
+func TestPeerTrackerReputation(t *testing.T) {
+	require := require.New(t)
+	p := NewPeerTracker()
+
+	good, bad, connected := ids.GenerateTestNodeID(), ids.GenerateTestNodeID(), ids.GenerateTestNodeID()
+	for _, nodeID := range []ids.NodeID{good, bad, connected} {
+		p.Connected(nodeID, defaultPeerVersion)
+		p.TrackPeer(nodeID)
+	}
+	p.TrackBandwidth(good, 10)
+	p.TrackBandwidth(bad, 0)
+	p.TrackBandwidth(connected, 5)
+	p.Disconnected(good)
+	p.Disconnected(bad)
+
+	reputation := p.Reputation()
+	require.Equal(map[ids.NodeID]float64{good: 10, bad: 0, connected: 5}, reputation)
+
+	// A restarted tracker prefers the peers that were responsive before.
+	restarted := NewPeerTracker()
+	restarted.LoadReputation(reputation)
+	for _, nodeID := range []ids.NodeID{good, bad} {
+		restarted.Connected(nodeID, defaultPeerVersion)
+		require.True(restarted.trackedPeers.Contains(nodeID))
+	}
+	require.True(restarted.responsivePeers.Contains(good))
+	require.False(restarted.responsivePeers.Contains(bad))
+	nodeID, averager, ok := restarted.bandwidthHeap.Peek()
+	require.True(ok)
+	require.Equal(good, nodeID)
+	require.Equal(float64(10), averager.Read())
+
+	// Only the peers with the highest bandwidth are remembered.
+	history := make(map[ids.NodeID]float64, maxPeerHistory+1)
+	for i := 0; i <= maxPeerHistory; i++ {
+		history[ids.GenerateTestNodeID()] = float64(i + 1)
+	}
+	worst := ids.GenerateTestNodeID()
+	history[worst] = 0
+	restarted = NewPeerTracker()
+	restarted.LoadReputation(history)
+	reputation = restarted.Reputation()
+	require.Len(reputation, maxPeerHistory)
+	require.NotContains(reputation, worst)
+}
+
+func TestPeerTrackerHealthProbes(t *testing.T) {
+	require := require.New(t)
+	p := NewPeerTracker()
+
+	active, idle := ids.GenerateTestNodeID(), ids.GenerateTestNodeID()
+	for _, nodeID := range []ids.NodeID{active, idle} {
+		p.Connected(nodeID, defaultPeerVersion)
+		p.TrackPeer(nodeID)
+		p.TrackBandwidth(nodeID, 10)
+	}
+	p.peers[idle].lastActive = time.Now().Add(-time.Hour)
+	require.Equal([]ids.NodeID{idle}, p.IdlePeers(time.Now().Add(-time.Minute)))
+
+	// A failed probe demotes the peer, and an answered one restores it.
+	require.False(p.ProbeFailed(idle, 2))
+	require.False(p.responsivePeers.Contains(idle))
+	p.ProbeSucceeded(idle)
+	require.True(p.responsivePeers.Contains(idle))
+	require.Zero(p.peers[idle].failedProbes)
+
+	// Consecutive failed probes expire the peer.
+	require.False(p.ProbeFailed(idle, 2))
+	require.True(p.ProbeFailed(idle, 2))
+	require.False(p.trackedPeers.Contains(idle))
+	require.False(p.responsivePeers.Contains(idle))
+	require.Nil(p.peers[idle].bandwidth)
+	nodeID, _, ok := p.bandwidthHeap.Peek()
+	require.True(ok)
+	require.Equal(active, nodeID)
+	require.Equal([]ids.NodeID{active}, p.IdlePeers(time.Now().Add(time.Second)))
+}
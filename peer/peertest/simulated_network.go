@@ -0,0 +1,142 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package peertest provides tooling for testing code built on [peer.Network].
+package peertest
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/shubhamdubey02/cryftgo/ids"
+	"github.com/shubhamdubey02/cryftgo/version"
+
+	"github.com/shubhamdubey02/coreth/peer"
+	"github.com/shubhamdubey02/coreth/plugin/evm/message"
+)
+
+var _ peer.Network = (*SimulatedNetwork)(nil)
+
+// NetworkConditions are the conditions simulated by [SimulatedNetwork].
+type NetworkConditions struct {
+	// Latency is the delay added before each response or failure is delivered.
+	Latency time.Duration
+	// Jitter is the maximum random delay added on top of Latency. Non-zero
+	// jitter delivers responses out of the order the requests were sent in.
+	Jitter time.Duration
+	// FailureRate is the fraction of requests, in [0, 1], whose response is
+	// discarded and reported to the handler as a failure.
+	FailureRate float64
+}
+
+// SimulatedNetwork wraps a [peer.Network], injecting latency, reordering and
+// failures into the responses to outbound requests. It is intended for soak
+// testing sync and gossip code. Random choices are drawn from a seeded source
+// in the order requests are sent, so a test sending requests in a fixed order
+// sees the same failures and delays on every run. Delays are measured by an
+// injected clock, so that tests can advance time with [mclock.Simulated].
+type SimulatedNetwork struct {
+	peer.Network
+
+	clock      mclock.Clock
+	lock       sync.Mutex
+	conditions NetworkConditions
+	rand       *rand.Rand
+	pending    sync.WaitGroup
+}
+
+// NewSimulatedNetwork returns a network sending requests through [network]
+// under [conditions], drawing random choices from [seed] and delaying
+// responses with [clock].
+func NewSimulatedNetwork(network peer.Network, conditions NetworkConditions, seed int64, clock mclock.Clock) *SimulatedNetwork {
+	return &SimulatedNetwork{
+		Network:    network,
+		clock:      clock,
+		conditions: conditions,
+		rand:       rand.New(rand.NewSource(seed)),
+	}
+}
+
+// SetConditions replaces the conditions applied to subsequent requests.
+func (n *SimulatedNetwork) SetConditions(conditions NetworkConditions) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.conditions = conditions
+}
+
+// Wait blocks until all delayed responses and failures have been delivered.
+// With a simulated clock, the clock must be advanced past the delays first.
+func (n *SimulatedNetwork) Wait() {
+	n.pending.Wait()
+}
+
+func (n *SimulatedNetwork) SendAppRequestAny(ctx context.Context, minVersion *version.Application, request []byte, handler message.ResponseHandler) (ids.NodeID, error) {
+	return n.Network.SendAppRequestAny(ctx, minVersion, request, n.simulate(handler))
+}
+
+func (n *SimulatedNetwork) SendAppRequest(ctx context.Context, nodeID ids.NodeID, request []byte, handler message.ResponseHandler) error {
+	return n.Network.SendAppRequest(ctx, nodeID, request, n.simulate(handler))
+}
+
+func (n *SimulatedNetwork) SendCrossChainRequest(ctx context.Context, chainID ids.ID, request []byte, handler message.ResponseHandler) error {
+	return n.Network.SendCrossChainRequest(ctx, chainID, request, n.simulate(handler))
+}
+
+// simulate draws the delay and outcome of a request and returns a handler
+// applying them to the response.
+func (n *SimulatedNetwork) simulate(handler message.ResponseHandler) message.ResponseHandler {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	delay := n.conditions.Latency
+	if n.conditions.Jitter > 0 {
+		delay += time.Duration(n.rand.Int63n(int64(n.conditions.Jitter)))
+	}
+	return &simulatedResponseHandler{
+		network: n,
+		handler: handler,
+		delay:   delay,
+		fail:    n.rand.Float64() < n.conditions.FailureRate,
+	}
+}
+
+// simulatedResponseHandler delivers the response to a single request after
+// [delay], replacing it with a failure if [fail] is set.
+type simulatedResponseHandler struct {
+	network *SimulatedNetwork
+	handler message.ResponseHandler
+	delay   time.Duration
+	fail    bool
+}
+
+func (h *simulatedResponseHandler) OnResponse(response []byte) error {
+	if h.fail {
+		return h.deliver(h.handler.OnFailure)
+	}
+	return h.deliver(func() error { return h.handler.OnResponse(response) })
+}
+
+func (h *simulatedResponseHandler) OnFailure() error {
+	return h.deliver(h.handler.OnFailure)
+}
+
+// deliver calls [f] after [h.delay]. Errors from delayed calls are logged, as
+// there is no caller left to return them to.
+func (h *simulatedResponseHandler) deliver(f func() error) error {
+	if h.delay == 0 {
+		return f()
+	}
+	h.network.pending.Add(1)
+	h.network.clock.AfterFunc(h.delay, func() {
+		defer h.network.pending.Done()
+		if err := f(); err != nil {
+			log.Warn("simulated network response handler failed", "err", err)
+		}
+	})
+	return nil
+}
@@ -0,0 +1,111 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package peertest
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/shubhamdubey02/cryftgo/ids"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shubhamdubey02/coreth/peer"
+	"github.com/shubhamdubey02/coreth/plugin/evm/message"
+)
+
+// echoNetwork responds to each request with the request itself.
+type echoNetwork struct {
+	peer.Network
+}
+
+func (echoNetwork) SendAppRequest(_ context.Context, _ ids.NodeID, request []byte, handler message.ResponseHandler) error {
+	return handler.OnResponse(request)
+}
+
+// recordingHandler records the responses and failures it receives in order.
+type recordingHandler struct {
+	lock      *sync.Mutex
+	responses *[]byte
+	failures  *int
+}
+
+func (h recordingHandler) OnResponse(response []byte) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	*h.responses = append(*h.responses, response...)
+	return nil
+}
+
+func (h recordingHandler) OnFailure() error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	*h.failures++
+	return nil
+}
+
+func TestSimulatedNetwork(t *testing.T) {
+	require := require.New(t)
+
+	run := func(conditions NetworkConditions, seed int64) ([]byte, int) {
+		var (
+			lock      sync.Mutex
+			responses []byte
+			failures  int
+			handler   = recordingHandler{lock: &lock, responses: &responses, failures: &failures}
+			clock     = &mclock.Simulated{}
+			network   = NewSimulatedNetwork(echoNetwork{}, conditions, seed, clock)
+		)
+		for i := 0; i < 50; i++ {
+			require.NoError(network.SendAppRequest(context.Background(), ids.EmptyNodeID, []byte{byte(i)}, handler))
+		}
+		// Nothing is delivered before the latency has elapsed.
+		if conditions.Latency > 0 {
+			clock.Run(conditions.Latency - 1)
+			require.Empty(responses)
+			require.Zero(failures)
+		}
+		clock.Run(conditions.Latency + conditions.Jitter)
+		network.Wait()
+		require.Zero(clock.ActiveTimers())
+		return responses, failures
+	}
+
+	// Without simulated conditions, responses are delivered in order.
+	responses, failures := run(NetworkConditions{}, 1)
+	require.Zero(failures)
+	for i, response := range responses {
+		require.Equal(byte(i), response)
+	}
+
+	// Every request fails at a failure rate of 1.
+	responses, failures = run(NetworkConditions{FailureRate: 1}, 1)
+	require.Empty(responses)
+	require.Equal(50, failures)
+
+	// Failures are chosen deterministically from the seed.
+	conditions := NetworkConditions{FailureRate: 0.5}
+	responses, failures = run(conditions, 2)
+	responses2, failures2 := run(conditions, 2)
+	require.Equal(responses, responses2)
+	require.Equal(failures, failures2)
+	require.Equal(50, len(responses)+failures)
+
+	// Latency delays delivery and jitter reorders responses, in the same
+	// order for the same seed.
+	conditions = NetworkConditions{Latency: 10 * time.Millisecond, Jitter: 50 * time.Millisecond}
+	responses, failures = run(conditions, 1)
+	require.Zero(failures)
+	require.Len(responses, 50)
+	inOrder := make([]byte, len(responses))
+	for i := range inOrder {
+		inOrder[i] = byte(i)
+	}
+	require.NotEqual(inOrder, responses)
+	require.ElementsMatch(inOrder, responses)
+	responses2, _ = run(conditions, 1)
+	require.Equal(responses, responses2)
+}
@@ -0,0 +1,128 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package peer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/shubhamdubey02/cryftgo/ids"
+	"github.com/shubhamdubey02/cryftgo/network/p2p"
+	"github.com/shubhamdubey02/cryftgo/version"
+)
+
+var (
+	_ p2p.Handler = (*versionedHandler)(nil)
+
+	errDuplicateHandlerVersion = errors.New("handler version already registered")
+	errUnsupportedPeerVersion  = errors.New("no handler registered for peer version")
+)
+
+// handlerVersion is a handler serving peers running at least [minVersion].
+type handlerVersion struct {
+	minVersion *version.Application
+	handler    p2p.Handler
+}
+
+// versionedHandler is the handler of a protocol with several registered
+// versions. Each message is handled by the newest version the sending peer
+// runs, so that a protocol can migrate to a new message format while serving
+// peers that have not upgraded yet.
+type versionedHandler struct {
+	// peerVersion returns the application version advertised by a peer.
+	peerVersion func(nodeID ids.NodeID) (*version.Application, bool)
+
+	lock     sync.RWMutex
+	versions []handlerVersion // sorted by ascending [minVersion]
+}
+
+func newVersionedHandler(peerVersion func(ids.NodeID) (*version.Application, bool)) *versionedHandler {
+	return &versionedHandler{peerVersion: peerVersion}
+}
+
+// add registers [handler] for peers running at least [minVersion].
+func (v *versionedHandler) add(minVersion *version.Application, handler p2p.Handler) error {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	for _, existing := range v.versions {
+		if existing.minVersion.Compare(minVersion) == 0 {
+			return fmt.Errorf("%w: %s", errDuplicateHandlerVersion, minVersion)
+		}
+	}
+	v.versions = append(v.versions, handlerVersion{minVersion: minVersion, handler: handler})
+	sort.Slice(v.versions, func(i, j int) bool {
+		return v.versions[i].minVersion.Before(v.versions[j].minVersion)
+	})
+	return nil
+}
+
+// negotiate returns the newest version run by [nodeID]. If the version of
+// [nodeID] is unknown, the oldest version is returned, as it is the one most
+// likely to be supported.
+func (v *versionedHandler) negotiate(nodeID ids.NodeID) (handlerVersion, bool) {
+	// The peer version is looked up before [lock] is held, as registering a
+	// version holds the network lock before [lock].
+	peerVersion, ok := v.peerVersion(nodeID)
+
+	v.lock.RLock()
+	defer v.lock.RUnlock()
+
+	if len(v.versions) == 0 {
+		return handlerVersion{}, false
+	}
+	if !ok {
+		return v.versions[0], true
+	}
+	for i := len(v.versions) - 1; i >= 0; i-- {
+		if !peerVersion.Before(v.versions[i].minVersion) {
+			return v.versions[i], true
+		}
+	}
+	return handlerVersion{}, false
+}
+
+// newest returns the newest registered version.
+func (v *versionedHandler) newest() (handlerVersion, bool) {
+	v.lock.RLock()
+	defer v.lock.RUnlock()
+
+	if len(v.versions) == 0 {
+		return handlerVersion{}, false
+	}
+	return v.versions[len(v.versions)-1], true
+}
+
+func (v *versionedHandler) AppGossip(ctx context.Context, nodeID ids.NodeID, gossipBytes []byte) {
+	h, ok := v.negotiate(nodeID)
+	if !ok {
+		log.Debug("dropping AppGossip from peer without a supported handler version", "nodeID", nodeID)
+		return
+	}
+	h.handler.AppGossip(ctx, nodeID, gossipBytes)
+}
+
+func (v *versionedHandler) AppRequest(ctx context.Context, nodeID ids.NodeID, deadline time.Time, requestBytes []byte) ([]byte, error) {
+	h, ok := v.negotiate(nodeID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errUnsupportedPeerVersion, nodeID)
+	}
+	return h.handler.AppRequest(ctx, nodeID, deadline, requestBytes)
+}
+
+// CrossChainAppRequest is handled by the newest version, as chains of the
+// same node run the same application version.
+func (v *versionedHandler) CrossChainAppRequest(ctx context.Context, chainID ids.ID, deadline time.Time, requestBytes []byte) ([]byte, error) {
+	h, ok := v.newest()
+	if !ok {
+		return nil, fmt.Errorf("%w: chain %s", errUnsupportedPeerVersion, chainID)
+	}
+	return h.handler.CrossChainAppRequest(ctx, chainID, deadline, requestBytes)
+}
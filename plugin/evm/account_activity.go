@@ -0,0 +1,484 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/shubhamdubey02/coreth/core"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/core/vm"
+	"github.com/shubhamdubey02/coreth/eth"
+	"github.com/shubhamdubey02/coreth/params"
+
+	"github.com/shubhamdubey02/cryftgo/database"
+	"github.com/shubhamdubey02/cryftgo/database/prefixdb"
+)
+
+const (
+	// accountActivityConsumer is the name of the accepted block consumer
+	// feeding the account activity index.
+	accountActivityConsumer = "account-activity"
+
+	// maxAccountActivityPage is the maximum number of entries returned by a
+	// paginated account activity query.
+	maxAccountActivityPage = 1024
+)
+
+var (
+	accountActivityPrefix = []byte("accountActivity")
+
+	// Keys of the account activity index: the record of each address, the
+	// contracts created by each address in order of creation, the use of each
+	// nonce of each address, and the height of the last indexed block.
+	activityRecordPrefix   = []byte("r")
+	activityContractPrefix = []byte("c")
+	activityNoncePrefix    = []byte("n")
+	activityHeadKey        = []byte("head")
+
+	errAccountActivityDisabled = errors.New("account activity index is not enabled")
+)
+
+// accountActivity is the record stored in the account activity index for
+// each address that has been seen in an accepted block.
+type accountActivity struct {
+	FirstActivity         uint64
+	LastActivity          uint64
+	SentCount             uint64
+	ReceivedCount         uint64
+	LastNonce             uint64
+	ContractsCreated      uint64 // number of contracts listed under [activityContractPrefix]
+	InternalSentCount     uint64
+	InternalReceivedCount uint64
+}
+
+// nonceUse records the transaction which used a nonce of its sender.
+type nonceUse struct {
+	Nonce  uint64 `rlp:"-"` // the key of the record
+	Height uint64
+	TxHash common.Hash
+}
+
+// internalCall is a call or contract creation made by a contract during the
+// execution of a transaction.
+type internalCall struct {
+	From   common.Address
+	To     common.Address
+	Create bool
+}
+
+// accountActivityTracer is a [vm.EVMLogger] recording the internal calls of
+// the transactions it traces which were not reverted.
+type accountActivityTracer struct {
+	// frames holds the internal calls made in each open call frame, the
+	// first entry of each frame but the outermost being the call opening it.
+	frames [][]internalCall
+	calls  []internalCall
+}
+
+func (t *accountActivityTracer) CaptureTxStart(gasLimit uint64) {}
+
+func (t *accountActivityTracer) CaptureTxEnd(restGas uint64) {}
+
+func (t *accountActivityTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.frames = append(t.frames[:0], nil)
+}
+
+// CaptureEnd records the internal calls of the transaction, unless it failed.
+func (t *accountActivityTracer) CaptureEnd(output []byte, gasUsed uint64, err error) {
+	if err == nil && len(t.frames) == 1 {
+		t.calls = append(t.calls, t.frames[0]...)
+	}
+	t.frames = t.frames[:0]
+}
+
+// CaptureEnter opens the frame of an internal call.
+func (t *accountActivityTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	call := internalCall{From: from, To: to, Create: typ == vm.CREATE || typ == vm.CREATE2}
+	t.frames = append(t.frames, []internalCall{call})
+}
+
+// CaptureExit closes the frame of an internal call, moving its calls to the
+// calling frame unless it failed.
+func (t *accountActivityTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	if len(t.frames) < 2 {
+		return
+	}
+	last := len(t.frames) - 1
+	if err == nil {
+		t.frames[last-1] = append(t.frames[last-1], t.frames[last]...)
+	}
+	t.frames = t.frames[:last]
+}
+
+func (t *accountActivityTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+}
+
+func (t *accountActivityTracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+// traceInternalCalls re-executes the transactions of [block] on top of the
+// state of its parent, regenerating the state from up to [reexec] blocks back
+// if it was pruned, and returns their receipts and internal calls. Atomic
+// transactions, which make no calls, are not re-executed.
+func traceInternalCalls(ctx context.Context, backend *eth.Ethereum, block *types.Block, reexec uint64) (types.Receipts, []internalCall, error) {
+	bc := backend.BlockChain()
+	parent := bc.GetBlock(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, nil, fmt.Errorf("missing parent %s of block %s", block.ParentHash(), block.Hash())
+	}
+	statedb, release, err := backend.APIBackend.StateAtNextBlock(ctx, parent, block, reexec, nil, true, false)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read state of parent %s: %w", parent.Hash(), err)
+	}
+	defer release()
+
+	var (
+		config       = bc.Config()
+		header       = block.Header()
+		tracer       = new(accountActivityTracer)
+		blockContext = core.NewEVMBlockContext(header, bc, nil)
+		gp           = new(core.GasPool).AddGas(block.GasLimit())
+		usedGas      uint64
+		receipts     = make(types.Receipts, 0, len(block.Transactions()))
+	)
+	for i, tx := range block.Transactions() {
+		statedb.SetTxContext(tx.Hash(), i)
+		receipt, err := core.ApplyTransaction(config, bc, blockContext, gp, statedb, header, tx, &usedGas, vm.Config{Tracer: tracer})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to trace tx %s of block %s: %w", tx.Hash(), block.Hash(), err)
+		}
+		receipts = append(receipts, receipt)
+	}
+	return receipts, tracer.calls, nil
+}
+
+// startAccountActivityIndex indexes the activity of accepted blocks in the
+// background, rather than while accepting them, as indexing re-executes each
+// block to trace its internal calls.
+func (vm *VM) startAccountActivityIndex() error {
+	if vm.activityIndex == nil {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-vm.shutdownChan
+		cancel()
+	}()
+	return vm.consumeAcceptedBlocks(accountActivityConsumer, func(block *types.Block) error {
+		// Internal calls are not stored, so the block is re-executed to
+		// trace them.
+		receipts, calls, err := traceInternalCalls(ctx, vm.eth, block, vm.config.CommitInterval)
+		if err != nil {
+			return err
+		}
+		return vm.activityIndex.IndexBlock(vm.chainConfig, block, receipts, calls)
+	})
+}
+
+// accountActivityIndex maintains an index of [address] => [accountActivity]
+// for all addresses that have sent, received, or created a contract in an
+// accepted block.
+type accountActivityIndex struct {
+	db database.Database
+}
+
+// newAccountActivityIndex returns an index that writes to [db]. The index is
+// written independently of the last accepted block, so [db] must not be the
+// VM's versiondb.
+func newAccountActivityIndex(db database.Database) *accountActivityIndex {
+	return &accountActivityIndex{
+		db: prefixdb.New(accountActivityPrefix, db),
+	}
+}
+
+func activityKey(prefix []byte, addr common.Address, index uint64) []byte {
+	key := make([]byte, 0, len(prefix)+common.AddressLength+8)
+	key = append(key, prefix...)
+	key = append(key, addr[:]...)
+	return binary.BigEndian.AppendUint64(key, index)
+}
+
+func activityRecordKey(addr common.Address) []byte {
+	return append(append([]byte{}, activityRecordPrefix...), addr[:]...)
+}
+
+// Head returns the height of the last indexed block, or 0 if no block has
+// been indexed.
+func (a *accountActivityIndex) Head() (uint64, error) {
+	headBytes, err := a.db.Get(activityHeadKey)
+	if err == database.ErrNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(headBytes), nil
+}
+
+// Get returns the activity recorded for [addr]. If [addr] has never been
+// indexed, [database.ErrNotFound] is returned.
+func (a *accountActivityIndex) Get(addr common.Address) (*accountActivity, error) {
+	activityBytes, err := a.db.Get(activityRecordKey(addr))
+	if err != nil {
+		return nil, err
+	}
+	activity := new(accountActivity)
+	if err := rlp.DecodeBytes(activityBytes, activity); err != nil {
+		return nil, fmt.Errorf("failed to decode account activity for %s: %w", addr, err)
+	}
+	return activity, nil
+}
+
+// ContractsCreated returns up to [limit] of the contracts created by [addr],
+// starting from the [start]th.
+func (a *accountActivityIndex) ContractsCreated(addr common.Address, start uint64, limit int) ([]common.Address, error) {
+	prefix := append(append([]byte{}, activityContractPrefix...), addr[:]...)
+	it := a.db.NewIteratorWithStartAndPrefix(activityKey(activityContractPrefix, addr, start), prefix)
+	defer it.Release()
+
+	contracts := []common.Address{}
+	for len(contracts) < limit && it.Next() {
+		contracts = append(contracts, common.BytesToAddress(it.Value()))
+	}
+	return contracts, it.Error()
+}
+
+// NonceHistory returns the uses of up to [limit] nonces of [addr], starting
+// from nonce [start], in order of nonce.
+func (a *accountActivityIndex) NonceHistory(addr common.Address, start uint64, limit int) ([]nonceUse, error) {
+	prefix := append(append([]byte{}, activityNoncePrefix...), addr[:]...)
+	it := a.db.NewIteratorWithStartAndPrefix(activityKey(activityNoncePrefix, addr, start), prefix)
+	defer it.Release()
+
+	var uses []nonceUse
+	for len(uses) < limit && it.Next() {
+		use := nonceUse{Nonce: binary.BigEndian.Uint64(it.Key()[len(prefix):])}
+		if err := rlp.DecodeBytes(it.Value(), &use); err != nil {
+			return nil, fmt.Errorf("failed to decode nonce use of %s: %w", addr, err)
+		}
+		uses = append(uses, use)
+	}
+	return uses, it.Error()
+}
+
+// IndexBlock updates the index with the transactions in [block], their
+// corresponding [receipts] and the internal [calls] they made. The updates
+// are written atomically along with the height of [block], and blocks at or
+// below the last indexed height are ignored, so that a block delivered again
+// after a crash is not counted twice.
+func (a *accountActivityIndex) IndexBlock(config *params.ChainConfig, block *types.Block, receipts types.Receipts, calls []internalCall) error {
+	var (
+		height  = block.NumberU64()
+		signer  = types.MakeSigner(config, block.Number(), block.Time())
+		txs     = block.Transactions()
+		pending = make(map[common.Address]*accountActivity)
+		batch   = a.db.NewBatch()
+	)
+	if len(receipts) != len(txs) {
+		return fmt.Errorf("mismatched receipts (%d) and transactions (%d) for block %s", len(receipts), len(txs), block.Hash())
+	}
+	head, err := a.Head()
+	if err != nil {
+		return err
+	}
+	if height <= head {
+		return nil
+	}
+
+	load := func(addr common.Address) (*accountActivity, error) {
+		if activity, ok := pending[addr]; ok {
+			return activity, nil
+		}
+		activity, err := a.Get(addr)
+		switch {
+		case err == database.ErrNotFound:
+			activity = &accountActivity{FirstActivity: height}
+		case err != nil:
+			return nil, err
+		}
+		activity.LastActivity = height
+		pending[addr] = activity
+		return activity, nil
+	}
+	addContract := func(creator common.Address, activity *accountActivity, contract common.Address) error {
+		if err := batch.Put(activityKey(activityContractPrefix, creator, activity.ContractsCreated), contract[:]); err != nil {
+			return err
+		}
+		activity.ContractsCreated++
+		return nil
+	}
+
+	for i, tx := range txs {
+		from, err := types.Sender(signer, tx)
+		if err != nil {
+			return fmt.Errorf("failed to recover sender of tx %s: %w", tx.Hash(), err)
+		}
+		sender, err := load(from)
+		if err != nil {
+			return err
+		}
+		sender.SentCount++
+		sender.LastNonce = tx.Nonce()
+		useBytes, err := rlp.EncodeToBytes(&nonceUse{Height: height, TxHash: tx.Hash()})
+		if err != nil {
+			return err
+		}
+		if err := batch.Put(activityKey(activityNoncePrefix, from, tx.Nonce()), useBytes); err != nil {
+			return err
+		}
+
+		if to := tx.To(); to != nil {
+			recipient, err := load(*to)
+			if err != nil {
+				return err
+			}
+			recipient.ReceivedCount++
+			continue
+		}
+		if receipt := receipts[i]; receipt.Status == types.ReceiptStatusSuccessful {
+			if err := addContract(from, sender, receipt.ContractAddress); err != nil {
+				return err
+			}
+		}
+	}
+	for _, call := range calls {
+		caller, err := load(call.From)
+		if err != nil {
+			return err
+		}
+		caller.InternalSentCount++
+		if call.Create {
+			if err := addContract(call.From, caller, call.To); err != nil {
+				return err
+			}
+		}
+		callee, err := load(call.To)
+		if err != nil {
+			return err
+		}
+		callee.InternalReceivedCount++
+	}
+
+	for addr, activity := range pending {
+		activityBytes, err := rlp.EncodeToBytes(activity)
+		if err != nil {
+			return err
+		}
+		if err := batch.Put(activityRecordKey(addr), activityBytes); err != nil {
+			return err
+		}
+	}
+	if err := batch.Put(activityHeadKey, binary.BigEndian.AppendUint64(nil, height)); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+// ActivityAPI offers access to the account activity index
+type ActivityAPI struct{ vm *VM }
+
+// AccountActivityReply is the response for GetAccountActivity
+type AccountActivityReply struct {
+	Address          common.Address `json:"address"`
+	FirstActivity    hexutil.Uint64 `json:"firstActivityBlock"`
+	LastActivity     hexutil.Uint64 `json:"lastActivityBlock"`
+	SentCount        hexutil.Uint64 `json:"sentCount"`
+	ReceivedCount    hexutil.Uint64 `json:"receivedCount"`
+	LastNonce        hexutil.Uint64 `json:"lastNonce"`
+	ContractsCreated hexutil.Uint64 `json:"contractsCreated"`
+
+	InternalSentCount     hexutil.Uint64 `json:"internalSentCount"`
+	InternalReceivedCount hexutil.Uint64 `json:"internalReceivedCount"`
+
+	// IndexedBlock is the last block indexed, which may trail the last
+	// accepted block.
+	IndexedBlock hexutil.Uint64 `json:"indexedBlock"`
+}
+
+// GetAccountActivity returns the activity recorded for [addr] in accepted
+// blocks. Returns nil if [addr] has no recorded activity.
+func (api *ActivityAPI) GetAccountActivity(_ context.Context, addr common.Address) (*AccountActivityReply, error) {
+	if api.vm.activityIndex == nil {
+		return nil, errAccountActivityDisabled
+	}
+	head, err := api.vm.activityIndex.Head()
+	if err != nil {
+		return nil, err
+	}
+	activity, err := api.vm.activityIndex.Get(addr)
+	if err == database.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &AccountActivityReply{
+		Address:          addr,
+		FirstActivity:    hexutil.Uint64(activity.FirstActivity),
+		LastActivity:     hexutil.Uint64(activity.LastActivity),
+		SentCount:        hexutil.Uint64(activity.SentCount),
+		ReceivedCount:    hexutil.Uint64(activity.ReceivedCount),
+		LastNonce:        hexutil.Uint64(activity.LastNonce),
+		ContractsCreated: hexutil.Uint64(activity.ContractsCreated),
+
+		InternalSentCount:     hexutil.Uint64(activity.InternalSentCount),
+		InternalReceivedCount: hexutil.Uint64(activity.InternalReceivedCount),
+
+		IndexedBlock: hexutil.Uint64(head),
+	}, nil
+}
+
+// GetContractsCreated returns up to [limit] of the contracts created by
+// [addr], starting from the [start]th, in order of creation.
+func (api *ActivityAPI) GetContractsCreated(_ context.Context, addr common.Address, start hexutil.Uint64, limit hexutil.Uint64) ([]common.Address, error) {
+	if api.vm.activityIndex == nil {
+		return nil, errAccountActivityDisabled
+	}
+	return api.vm.activityIndex.ContractsCreated(addr, uint64(start), activityPageSize(limit))
+}
+
+// NonceUseReply is an entry of the response for GetNonceHistory
+type NonceUseReply struct {
+	Nonce  hexutil.Uint64 `json:"nonce"`
+	Block  hexutil.Uint64 `json:"blockNumber"`
+	TxHash common.Hash    `json:"transactionHash"`
+}
+
+// GetNonceHistory returns the transactions which used up to [limit] nonces
+// of [addr], starting from nonce [start], in order of nonce.
+func (api *ActivityAPI) GetNonceHistory(_ context.Context, addr common.Address, start hexutil.Uint64, limit hexutil.Uint64) ([]NonceUseReply, error) {
+	if api.vm.activityIndex == nil {
+		return nil, errAccountActivityDisabled
+	}
+	uses, err := api.vm.activityIndex.NonceHistory(addr, uint64(start), activityPageSize(limit))
+	if err != nil {
+		return nil, err
+	}
+	reply := make([]NonceUseReply, len(uses))
+	for i, use := range uses {
+		reply[i] = NonceUseReply{
+			Nonce:  hexutil.Uint64(use.Nonce),
+			Block:  hexutil.Uint64(use.Height),
+			TxHash: use.TxHash,
+		}
+	}
+	return reply, nil
+}
+
+// activityPageSize returns [limit], capped to [maxAccountActivityPage].
+func activityPageSize(limit hexutil.Uint64) int {
+	if limit == 0 || limit > maxAccountActivityPage {
+		return maxAccountActivityPage
+	}
+	return int(limit)
+}
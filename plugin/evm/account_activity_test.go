@@ -0,0 +1,175 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/shubhamdubey02/cryftgo/database"
+	"github.com/shubhamdubey02/cryftgo/database/memdb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/core/vm"
+	"github.com/shubhamdubey02/coreth/params"
+	"github.com/shubhamdubey02/coreth/trie"
+	"github.com/shubhamdubey02/coreth/vmerrs"
+)
+
+func TestAccountActivityIndex(t *testing.T) {
+	require := require.New(t)
+
+	key, err := crypto.GenerateKey()
+	require.NoError(err)
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	recipient := common.Address{1}
+	contract := crypto.CreateAddress(sender, 1)
+
+	config := params.TestChainConfig
+	signer := types.LatestSigner(config)
+	index := newAccountActivityIndex(memdb.New())
+
+	makeBlock := func(height uint64, txs []*types.Transaction, receipts []*types.Receipt) *types.Block {
+		header := &types.Header{Number: new(big.Int).SetUint64(height)}
+		return types.NewBlock(header, txs, nil, receipts, trie.NewStackTrie(nil))
+	}
+
+	transfer, err := types.SignTx(types.NewTransaction(0, recipient, big.NewInt(1), 21000, big.NewInt(1), nil), signer, key)
+	require.NoError(err)
+	block := makeBlock(1, []*types.Transaction{transfer}, []*types.Receipt{{Status: types.ReceiptStatusSuccessful}})
+	require.NoError(index.IndexBlock(config, block, []*types.Receipt{{Status: types.ReceiptStatusSuccessful}}, nil))
+
+	create, err := types.SignTx(types.NewContractCreation(1, big.NewInt(0), 100000, big.NewInt(1), nil), signer, key)
+	require.NoError(err)
+	receipts := []*types.Receipt{{Status: types.ReceiptStatusSuccessful, ContractAddress: contract}}
+	block = makeBlock(2, []*types.Transaction{create}, receipts)
+	calls := []internalCall{
+		{From: contract, To: recipient},
+		{From: contract, To: common.Address{3}, Create: true},
+	}
+	require.NoError(index.IndexBlock(config, block, receipts, calls))
+
+	// Blocks delivered again after a crash are not counted twice.
+	require.NoError(index.IndexBlock(config, block, receipts, calls))
+	head, err := index.Head()
+	require.NoError(err)
+	require.Equal(uint64(2), head)
+
+	activity, err := index.Get(sender)
+	require.NoError(err)
+	require.Equal(&accountActivity{
+		FirstActivity:    1,
+		LastActivity:     2,
+		SentCount:        2,
+		LastNonce:        1,
+		ContractsCreated: 1,
+	}, activity)
+	contracts, err := index.ContractsCreated(sender, 0, maxAccountActivityPage)
+	require.NoError(err)
+	require.Equal([]common.Address{contract}, contracts)
+	uses, err := index.NonceHistory(sender, 0, maxAccountActivityPage)
+	require.NoError(err)
+	require.Equal([]nonceUse{
+		{Nonce: 0, Height: 1, TxHash: transfer.Hash()},
+		{Nonce: 1, Height: 2, TxHash: create.Hash()},
+	}, uses)
+	uses, err = index.NonceHistory(sender, 1, 1)
+	require.NoError(err)
+	require.Equal([]nonceUse{{Nonce: 1, Height: 2, TxHash: create.Hash()}}, uses)
+
+	activity, err = index.Get(recipient)
+	require.NoError(err)
+	require.Equal(uint64(1), activity.FirstActivity)
+	require.Equal(uint64(2), activity.LastActivity)
+	require.Equal(uint64(1), activity.ReceivedCount)
+	require.Equal(uint64(1), activity.InternalReceivedCount)
+
+	activity, err = index.Get(contract)
+	require.NoError(err)
+	require.Equal(&accountActivity{
+		FirstActivity:     2,
+		LastActivity:      2,
+		ContractsCreated:  1,
+		InternalSentCount: 2,
+	}, activity)
+	contracts, err = index.ContractsCreated(contract, 0, maxAccountActivityPage)
+	require.NoError(err)
+	require.Equal([]common.Address{{3}}, contracts)
+	contracts, err = index.ContractsCreated(contract, 1, maxAccountActivityPage)
+	require.NoError(err)
+	require.Empty(contracts)
+
+	_, err = index.Get(common.Address{2})
+	require.ErrorIs(err, database.ErrNotFound)
+
+	// Mismatched receipts should be rejected rather than silently skipped.
+	require.Error(index.IndexBlock(config, makeBlock(3, []*types.Transaction{transfer}, nil), nil, nil))
+}
+
+// TestAccountActivityIndexer checks that accepted blocks are indexed in the
+// background.
+func TestAccountActivityIndexer(t *testing.T) {
+	require := require.New(t)
+
+	h := NewTestHarness(t, TestHarnessConfig{
+		Config: `{"account-activity-index-enabled": true}`,
+	})
+	api := &ActivityAPI{h.VM}
+
+	recipient := common.Address{0xaa}
+	tx := h.Fund(recipient, big.NewInt(1))
+	blk := h.BuildAndAccept()
+
+	var reply *AccountActivityReply
+	require.Eventually(func() bool {
+		var err error
+		reply, err = api.GetAccountActivity(context.Background(), recipient)
+		require.NoError(err)
+		return reply != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	require.EqualValues(blk.Height(), reply.FirstActivity)
+	require.EqualValues(blk.Height(), reply.IndexedBlock)
+	require.EqualValues(1, reply.ReceivedCount)
+
+	uses, err := api.GetNonceHistory(context.Background(), HarnessFunderAddress, 0, 0)
+	require.NoError(err)
+	require.Equal([]NonceUseReply{{Nonce: 0, Block: hexutil.Uint64(blk.Height()), TxHash: tx.Hash()}}, uses)
+}
+
+func TestAccountActivityTracer(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		a, b, c, d = common.Address{1}, common.Address{2}, common.Address{3}, common.Address{4}
+		tracer     = new(accountActivityTracer)
+	)
+	tracer.CaptureStart(nil, a, b, false, nil, 0, nil)
+	tracer.CaptureEnter(vm.CALL, b, c, nil, 0, nil)
+	tracer.CaptureEnter(vm.CREATE, c, d, nil, 0, nil)
+	tracer.CaptureExit(nil, 0, nil)
+	// Reverting a call discards the calls it made.
+	tracer.CaptureExit(nil, 0, vmerrs.ErrExecutionReverted)
+	tracer.CaptureEnter(vm.CREATE2, b, d, nil, 0, nil)
+	tracer.CaptureEnter(vm.STATICCALL, d, c, nil, 0, nil)
+	tracer.CaptureExit(nil, 0, nil)
+	tracer.CaptureExit(nil, 0, nil)
+	tracer.CaptureEnd(nil, 0, nil)
+
+	// A failed transaction records no internal calls.
+	tracer.CaptureStart(nil, a, b, false, nil, 0, nil)
+	tracer.CaptureEnter(vm.CALL, b, c, nil, 0, nil)
+	tracer.CaptureExit(nil, 0, nil)
+	tracer.CaptureEnd(nil, 0, vmerrs.ErrOutOfGas)
+
+	require.Equal([]internalCall{
+		{From: b, To: d, Create: true},
+		{From: d, To: c},
+	}, tracer.calls)
+}
@@ -6,12 +6,26 @@ package evm
 import (
 	"fmt"
 	"net/http"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/shubhamdubey02/coreth/core/rawdb"
+	"github.com/shubhamdubey02/coreth/core/state"
+	"github.com/shubhamdubey02/coreth/core/vm"
+	"github.com/shubhamdubey02/coreth/rpc/errcodes"
 	"github.com/shubhamdubey02/cryftgo/api"
+	"github.com/shubhamdubey02/cryftgo/utils/json"
 	"github.com/shubhamdubey02/cryftgo/utils/profiler"
 )
 
+var (
+	errIncidentFilterNotConfigured = errcodes.New(errcodes.FeatureDisabled, "incident-filter-file is not configured")
+	errRetentionNotConfigured      = errcodes.New(errcodes.FeatureDisabled, "retention is not configured")
+	errOpcodeStatsNotEnabled       = errcodes.New(errcodes.FeatureDisabled, "opcode-stats-sample-rate is not configured")
+)
+
 // Admin is the API service for admin API calls
 type Admin struct {
 	vm       *VM
@@ -67,20 +81,229 @@ func (p *Admin) LockProfile(_ *http.Request, _ *struct{}, _ *api.EmptyReply) err
 
 type SetLogLevelArgs struct {
 	Level string `json:"level"`
+	// Module, if set, is the module whose log level is set instead of the
+	// global log level. An empty Level reverts Module to the global log level.
+	Module string `json:"module,omitempty"`
 }
 
 func (p *Admin) SetLogLevel(_ *http.Request, args *SetLogLevelArgs, reply *api.EmptyReply) error {
-	log.Info("EVM: SetLogLevel called", "logLevel", args.Level)
+	log.Info("EVM: SetLogLevel called", "logLevel", args.Level, "module", args.Module)
 
 	p.vm.ctx.Lock.Lock()
 	defer p.vm.ctx.Lock.Unlock()
 
+	if args.Module != "" {
+		return errcodes.Wrap(errcodes.InvalidArgument, p.vm.logger.SetModuleLogLevel(args.Module, args.Level))
+	}
 	if err := p.vm.logger.SetLogLevel(args.Level); err != nil {
-		return fmt.Errorf("failed to parse log level: %w ", err)
+		return errcodes.Wrap(errcodes.InvalidArgument, fmt.Errorf("failed to parse log level: %w ", err))
 	}
 	return nil
 }
 
+type LogLevelsReply struct {
+	Level   string            `json:"level"`
+	Modules map[string]string `json:"modules"`
+}
+
+// GetLogLevels returns the global log level and the log levels of modules
+// that override it
+func (p *Admin) GetLogLevels(_ *http.Request, _ *struct{}, reply *LogLevelsReply) error {
+	log.Info("EVM: GetLogLevels called")
+
+	reply.Level, reply.Modules = p.vm.logger.LogLevels()
+	return nil
+}
+
+type PrefetcherSettingsReply struct {
+	Settings state.PrefetcherSettings `json:"settings"`
+	// Parallelism is the parallelism currently in use, which may differ
+	// from the configured value in adaptive mode.
+	Parallelism int `json:"currentParallelism"`
+}
+
+// GetPrefetcherSettings returns the trie prefetcher settings
+func (p *Admin) GetPrefetcherSettings(_ *http.Request, _ *struct{}, reply *PrefetcherSettingsReply) error {
+	tuner := p.vm.blockChain.PrefetcherTuner()
+	reply.Settings = tuner.Settings()
+	reply.Parallelism = tuner.Parallelism()
+	return nil
+}
+
+// SetPrefetcherSettings updates the trie prefetcher settings used for
+// subsequent block processing and block building
+func (p *Admin) SetPrefetcherSettings(_ *http.Request, args *state.PrefetcherSettings, _ *api.EmptyReply) error {
+	log.Info("EVM: SetPrefetcherSettings called", "settings", args)
+
+	return errcodes.Wrap(errcodes.InvalidArgument, p.vm.blockChain.PrefetcherTuner().SetSettings(*args))
+}
+
+// ReloadIncidentFilter re-reads the incident filter file, replacing the
+// addresses and selectors excluded from subsequently built blocks
+func (p *Admin) ReloadIncidentFilter(_ *http.Request, _ *struct{}, _ *api.EmptyReply) error {
+	log.Info("EVM: ReloadIncidentFilter called")
+
+	if p.vm.config.IncidentFilterFile == "" {
+		return errIncidentFilterNotConfigured
+	}
+	return p.vm.loadIncidentFilter()
+}
+
+// ReloadConfig re-reads the files referenced by the VM config which are
+// applied at runtime
+func (p *Admin) ReloadConfig(_ *http.Request, _ *struct{}, _ *api.EmptyReply) error {
+	log.Info("EVM: ReloadConfig called")
+
+	if p.vm.config.IncidentFilterFile != "" {
+		if err := p.vm.loadIncidentFilter(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type ActiveCoinbaseReply struct {
+	Coinbase common.Address `json:"coinbase"`
+}
+
+// GetActiveCoinbase returns the coinbase of a block built at the current time
+func (p *Admin) GetActiveCoinbase(_ *http.Request, _ *struct{}, reply *ActiveCoinbaseReply) error {
+	reply.Coinbase = p.vm.miner.ActiveCoinbase()
+	return nil
+}
+
+// InspectDatabase returns the size of each family of keys in the database
+func (p *Admin) InspectDatabase(_ *http.Request, _ *struct{}, reply *rawdb.DatabaseInspection) error {
+	log.Info("EVM: InspectDatabase called")
+
+	inspection, err := p.vm.inspectDatabase()
+	if err != nil {
+		return err
+	}
+	*reply = *inspection
+	return nil
+}
+
+type VerifyAcceptedChainArgs struct {
+	Start json.Uint64 `json:"start"`
+	// End defaults to the last accepted height.
+	End *json.Uint64 `json:"end,omitempty"`
+}
+
+type VerifyAcceptedChainReply struct {
+	Start    json.Uint64              `json:"start"`
+	End      json.Uint64              `json:"end"`
+	Errors   []string                 `json:"errors"`
+	Orphaned *rawdb.OrphanedBlockData `json:"orphaned"`
+}
+
+// VerifyAcceptedChain verifies the continuity of the accepted chain index and
+// counts the block data that is not part of the accepted chain
+func (p *Admin) VerifyAcceptedChain(_ *http.Request, args *VerifyAcceptedChainArgs, reply *VerifyAcceptedChainReply) error {
+	log.Info("EVM: VerifyAcceptedChain called", "start", args.Start, "end", args.End)
+
+	end := p.vm.blockChain.LastAcceptedBlock().NumberU64()
+	if args.End != nil {
+		end = uint64(*args.End)
+	}
+	errs, orphaned, err := p.vm.verifyAcceptedChain(uint64(args.Start), end)
+	if err != nil {
+		return err
+	}
+	reply.Start = args.Start
+	reply.End = json.Uint64(end)
+	reply.Errors = make([]string, len(errs))
+	for i, err := range errs {
+		reply.Errors[i] = err.Error()
+	}
+	reply.Orphaned = orphaned
+	return nil
+}
+
+type RepairIndexesArgs struct {
+	Start json.Uint64 `json:"start"`
+	// End defaults to the last accepted height.
+	End *json.Uint64 `json:"end,omitempty"`
+	// Repair rewrites the missing or inconsistent index entries. Otherwise
+	// they are only reported.
+	Repair bool `json:"repair"`
+}
+
+// RepairIndexes verifies, and optionally repairs, the transaction lookup
+// entries and the bloom bits of the accepted chain over a range of heights
+func (p *Admin) RepairIndexes(_ *http.Request, args *RepairIndexesArgs, reply *IndexRepairReport) error {
+	log.Info("EVM: RepairIndexes called", "start", args.Start, "end", args.End, "repair", args.Repair)
+
+	end := p.vm.blockChain.LastAcceptedBlock().NumberU64()
+	if args.End != nil {
+		end = uint64(*args.End)
+	}
+	report, err := p.vm.repairIndexes(uint64(args.Start), end, args.Repair)
+	if err != nil {
+		return err
+	}
+	log.Info("EVM: RepairIndexes completed",
+		"missingTxs", report.TxIndex.Missing,
+		"mismatchedTxs", report.TxIndex.Mismatched,
+		"repairedTxs", report.TxIndex.Repaired,
+		"inconsistentBloomSections", len(report.InconsistentBloomSections),
+	)
+	*reply = *report
+	return nil
+}
+
+type RetentionStatusReply struct {
+	// LastRun is the time the retention policy was last fully enforced.
+	LastRun time.Time                       `json:"lastRun"`
+	Classes map[string]RetentionClassStatus `json:"classes"`
+}
+
+// GetRetentionStatus reports the block data deleted by the retention policy
+func (p *Admin) GetRetentionStatus(_ *http.Request, _ *struct{}, reply *RetentionStatusReply) error {
+	log.Info("EVM: GetRetentionStatus called")
+
+	if p.vm.retention == nil {
+		return errRetentionNotConfigured
+	}
+	reply.Classes, reply.LastRun = p.vm.retention.Status()
+	return nil
+}
+
+type OpcodeStatsReply struct {
+	// SampledTxs is the number of transactions whose opcodes were recorded.
+	SampledTxs json.Uint64              `json:"sampledTxs"`
+	Opcodes    map[string]vm.OpcodeStat `json:"opcodes"`
+}
+
+// GetOpcodeStats returns the frequency and gas of the opcodes executed by the
+// sampled transactions since the node started
+func (p *Admin) GetOpcodeStats(_ *http.Request, _ *struct{}, reply *OpcodeStatsReply) error {
+	log.Info("EVM: GetOpcodeStats called")
+
+	stats := p.vm.blockChain.GetVMConfig().OpcodeStats
+	if stats == nil {
+		return errOpcodeStatsNotEnabled
+	}
+	reply.SampledTxs = json.Uint64(stats.SampledTxs())
+	reply.Opcodes = stats.Dump()
+	return nil
+}
+
+type MemoryUsageReply struct {
+	Subsystems map[string]MemoryUsage `json:"subsystems"`
+}
+
+// GetMemoryUsage returns the memory used by each subsystem and its budget
+func (p *Admin) GetMemoryUsage(_ *http.Request, _ *struct{}, reply *MemoryUsageReply) error {
+	log.Info("EVM: GetMemoryUsage called")
+
+	if p.vm.memoryAccountant == nil {
+		return errMemoryBudgetDisabled
+	}
+	reply.Subsystems = p.vm.memoryAccountant.report()
+	return nil
+}
+
 type ConfigReply struct {
 	Config *Config `json:"config"`
 }
@@ -89,3 +312,36 @@ func (p *Admin) GetVMConfig(_ *http.Request, _ *struct{}, reply *ConfigReply) er
 	reply.Config = &p.vm.config
 	return nil
 }
+
+type CompactDatabaseArgs struct {
+	// Start and Limit bound the compacted key range. A nil Start compacts from
+	// the first key and a nil Limit up to the last key.
+	Start hexutil.Bytes `json:"start,omitempty"`
+	Limit hexutil.Bytes `json:"limit,omitempty"`
+}
+
+// CompactDatabase compacts the key range of the chain database
+func (p *Admin) CompactDatabase(_ *http.Request, args *CompactDatabaseArgs, _ *api.EmptyReply) error {
+	log.Info("Admin: CompactDatabase called", "start", args.Start, "limit", args.Limit)
+
+	start := time.Now()
+	if err := p.vm.chaindb.Compact(args.Start, args.Limit); err != nil {
+		return fmt.Errorf("failed to compact database: %w", err)
+	}
+	log.Info("Compacted database", "elapsed", time.Since(start))
+	return nil
+}
+
+type ChainAliasesReply struct {
+	Aliases []string `json:"aliases"`
+}
+
+// GetChainAliases returns the aliases of the chain
+func (p *Admin) GetChainAliases(_ *http.Request, _ *struct{}, reply *ChainAliasesReply) error {
+	aliases, err := p.vm.ctx.BCLookup.Aliases(p.vm.ctx.ChainID)
+	if err != nil {
+		return err
+	}
+	reply.Aliases = aliases
+	return nil
+}
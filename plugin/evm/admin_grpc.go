@@ -0,0 +1,209 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/shubhamdubey02/cryftgo/vms/rpcchainvm/grpcutils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// adminGRPCServiceName is the full name of the admin gRPC service.
+const adminGRPCServiceName = "coreth.admin.v1.Admin"
+
+var (
+	errEmptyAdminGRPCToken  = errors.New("admin gRPC token is empty")
+	errAdminGRPCNotLoopback = errors.New("admin gRPC service without TLS must listen on a loopback address")
+)
+
+// adminGRPCServiceDesc exposes the operations of the admin API to
+// infrastructure automation over gRPC. Messages are the JSON encoded
+// arguments and replies of the admin JSON-RPC API, so clients must use a JSON
+// codec.
+var adminGRPCServiceDesc = grpc.ServiceDesc{
+	ServiceName: adminGRPCServiceName,
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{
+		adminGRPCMethod("SetLogLevel", (*Admin).SetLogLevel),
+		adminGRPCMethod("GetLogLevels", (*Admin).GetLogLevels),
+		adminGRPCMethod("StartCPUProfiler", (*Admin).StartCPUProfiler),
+		adminGRPCMethod("StopCPUProfiler", (*Admin).StopCPUProfiler),
+		adminGRPCMethod("MemoryProfile", (*Admin).MemoryProfile),
+		adminGRPCMethod("LockProfile", (*Admin).LockProfile),
+		adminGRPCMethod("ReloadIncidentFilter", (*Admin).ReloadIncidentFilter),
+		adminGRPCMethod("ReloadConfig", (*Admin).ReloadConfig),
+		adminGRPCMethod("CompactDatabase", (*Admin).CompactDatabase),
+		adminGRPCMethod("RepairIndexes", (*Admin).RepairIndexes),
+		adminGRPCMethod("GetChainAliases", (*Admin).GetChainAliases),
+	},
+}
+
+// adminGRPCMethod adapts an admin JSON-RPC method to a unary gRPC method.
+func adminGRPCMethod[Args, Reply any](name string, method func(*Admin, *http.Request, *Args, *Reply) error) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			args := new(Args)
+			if err := dec(args); err != nil {
+				return nil, status.Error(codes.InvalidArgument, err.Error())
+			}
+			handler := func(_ context.Context, req interface{}) (interface{}, error) {
+				reply := new(Reply)
+				if err := method(srv.(*Admin), nil, req.(*Args), reply); err != nil {
+					return nil, status.Error(codes.Unknown, err.Error())
+				}
+				return reply, nil
+			}
+			if interceptor == nil {
+				return handler(ctx, args)
+			}
+			info := &grpc.UnaryServerInfo{
+				Server:     srv,
+				FullMethod: "/" + adminGRPCServiceName + "/" + name,
+			}
+			return interceptor(ctx, args, info, handler)
+		},
+	}
+}
+
+// adminGRPCCodec encodes the messages of the admin gRPC service as JSON.
+type adminGRPCCodec struct{}
+
+func (adminGRPCCodec) Marshal(v interface{}) ([]byte, error) { return json.Marshal(v) }
+
+func (adminGRPCCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+func (adminGRPCCodec) Name() string { return "json" }
+
+// adminGRPCServer serves the admin gRPC service to the callers presenting
+// its bearer token.
+type adminGRPCServer struct {
+	token    []byte
+	server   *grpc.Server
+	listener net.Listener
+}
+
+// newAdminGRPCServer returns a server of [admin] listening on [address]. If
+// [tlsConfig] is nil, the connections are not encrypted, so [address] must be
+// a loopback address.
+func newAdminGRPCServer(admin *Admin, address string, token []byte, tlsConfig *tls.Config) (*adminGRPCServer, error) {
+	if len(token) == 0 {
+		return nil, errEmptyAdminGRPCToken
+	}
+	if tlsConfig == nil {
+		addr, err := net.ResolveTCPAddr("tcp", address)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", address, err)
+		}
+		if !addr.IP.IsLoopback() {
+			return nil, fmt.Errorf("%w: %s", errAdminGRPCNotLoopback, address)
+		}
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+	s := &adminGRPCServer{
+		token:    token,
+		listener: listener,
+	}
+	opts := append(slices.Clone(grpcutils.DefaultServerOptions),
+		grpc.ForceServerCodec(adminGRPCCodec{}),
+		grpc.UnaryInterceptor(s.authenticate),
+	)
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	s.server = grpc.NewServer(opts...)
+	s.server.RegisterService(&adminGRPCServiceDesc, admin)
+	return s, nil
+}
+
+// authenticate rejects the calls without the bearer token of the server.
+func (s *adminGRPCServer) authenticate(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		token, ok := strings.CutPrefix(value, "Bearer ")
+		if ok && subtle.ConstantTimeCompare([]byte(token), s.token) == 1 {
+			return handler(ctx, req)
+		}
+	}
+	log.Warn("Admin: rejected unauthenticated gRPC call", "method", info.FullMethod)
+	return nil, status.Error(codes.Unauthenticated, "invalid admin token")
+}
+
+func (s *adminGRPCServer) serve() {
+	if err := s.server.Serve(s.listener); err != nil {
+		log.Error("Admin: gRPC server stopped", "err", err)
+	}
+}
+
+func (s *adminGRPCServer) stop() {
+	s.server.Stop()
+}
+
+// startAdminGRPC starts the admin gRPC service if an address is configured.
+func (vm *VM) startAdminGRPC() error {
+	if vm.config.AdminGRPCAddress == "" {
+		return nil
+	}
+	token, err := os.ReadFile(vm.config.AdminGRPCTokenFile)
+	if err != nil {
+		return fmt.Errorf("failed to read admin gRPC token: %w", err)
+	}
+	var tlsConfig *tls.Config
+	if vm.config.AdminGRPCTLSCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(vm.config.AdminGRPCTLSCertFile, vm.config.AdminGRPCTLSKeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load admin gRPC TLS certificate: %w", err)
+		}
+		tlsConfig = &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS13,
+		}
+	}
+	admin, err := vm.adminService()
+	if err != nil {
+		return err
+	}
+	server, err := newAdminGRPCServer(admin, vm.config.AdminGRPCAddress, bytes.TrimSpace(token), tlsConfig)
+	if err != nil {
+		return err
+	}
+	vm.adminGRPC = server
+	go vm.ctx.Log.RecoverAndPanic(server.serve)
+	log.Info("Admin: started gRPC service", "address", server.listener.Addr(), "tls", tlsConfig != nil)
+	return nil
+}
+
+// adminService returns the admin service shared by the admin JSON-RPC and
+// gRPC APIs, so that a profile started through one can be stopped through the
+// other.
+func (vm *VM) adminService() (*Admin, error) {
+	if vm.admin == nil {
+		primaryAlias, err := vm.ctx.BCLookup.PrimaryAlias(vm.ctx.ChainID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get primary alias for chain due to %w", err)
+		}
+		vm.admin = NewAdminService(vm, os.ExpandEnv(fmt.Sprintf("%s_coreth_performance_%s", vm.config.AdminAPIDir, primaryAlias)))
+	}
+	return vm.admin, nil
+}
@@ -0,0 +1,59 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAdminGRPC(t *testing.T) {
+	require := require.New(t)
+
+	vm := &VM{ctx: NewContext()}
+	server, err := newAdminGRPCServer(NewAdminService(vm, t.TempDir()), "127.0.0.1:0", []byte("secret"), nil)
+	require.NoError(err)
+	go server.serve()
+	defer server.stop()
+
+	conn, err := grpc.Dial(
+		server.listener.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(adminGRPCCodec{})),
+	)
+	require.NoError(err)
+	defer conn.Close()
+
+	const method = "/" + adminGRPCServiceName + "/GetChainAliases"
+	var reply ChainAliasesReply
+
+	// Calls without the token are rejected.
+	err = conn.Invoke(context.Background(), method, &struct{}{}, &reply)
+	require.Equal(codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer wrong")
+	err = conn.Invoke(ctx, method, &struct{}{}, &reply)
+	require.Equal(codes.Unauthenticated, status.Code(err))
+
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer secret")
+	require.NoError(conn.Invoke(ctx, method, &struct{}{}, &reply))
+	require.Contains(reply.Aliases, "C")
+
+	// Reloading a config which references no runtime files is a no-op.
+	require.NoError(conn.Invoke(ctx, "/"+adminGRPCServiceName+"/ReloadConfig", &struct{}{}, &struct{}{}))
+
+	_, err = newAdminGRPCServer(NewAdminService(vm, t.TempDir()), "127.0.0.1:0", nil, nil)
+	require.ErrorIs(err, errEmptyAdminGRPCToken)
+
+	// Without TLS, the token must not leave the host.
+	_, err = newAdminGRPCServer(NewAdminService(vm, t.TempDir()), "0.0.0.0:0", []byte("secret"), nil)
+	require.ErrorIs(err, errAdminGRPCNotLoopback)
+}
@@ -0,0 +1,132 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/shubhamdubey02/coreth/metrics"
+	"github.com/shubhamdubey02/coreth/rpc"
+)
+
+var _ rpc.CallScheduler = (*resourceArbiter)(nil)
+
+// resourceArbiter schedules RPC calls around the consensus work of the VM,
+// which competes with them for the database and tries. RPC calls are admitted
+// in arrival order up to a number of concurrent calls, which is lowered while
+// blocks are verified, built or accepted so that heavy RPC load does not
+// increase their latency. Consensus work is never delayed by the arbiter.
+type resourceArbiter struct {
+	rpcSlots                int
+	rpcSlotsDuringConsensus int
+
+	lock sync.Mutex
+	// consensus is the number of consensus tasks in progress.
+	consensus int
+	// active is the number of RPC calls executing.
+	active int
+	// waiting holds the channel of each queued RPC call, which is closed
+	// once the call is admitted.
+	waiting *list.List
+
+	queued metrics.Gauge
+	wait   metrics.Timer
+}
+
+func newResourceArbiter(config ResourceArbiterConfig) *resourceArbiter {
+	return &resourceArbiter{
+		rpcSlots:                config.RPCSlots,
+		rpcSlotsDuringConsensus: config.RPCSlotsDuringConsensus,
+		waiting:                 list.New(),
+		queued:                  metrics.GetOrRegisterGauge("arbiter_rpc_queued", nil),
+		wait:                    metrics.GetOrRegisterTimer("arbiter_rpc_wait", nil),
+	}
+}
+
+// beginConsensus marks the start of a consensus task and returns the function
+// marking its end. It is a no-op on a nil arbiter, so that callers need not
+// check whether the arbiter is enabled.
+func (a *resourceArbiter) beginConsensus() func() {
+	if a == nil {
+		return func() {}
+	}
+	a.lock.Lock()
+	a.consensus++
+	a.lock.Unlock()
+
+	return func() {
+		a.lock.Lock()
+		defer a.lock.Unlock()
+
+		a.consensus--
+		a.admit()
+	}
+}
+
+// Admit implements rpc.CallScheduler.
+func (a *resourceArbiter) Admit(ctx context.Context, _ string) (func(), error) {
+	a.lock.Lock()
+	if a.waiting.Len() == 0 && a.active < a.slots() {
+		a.active++
+		a.lock.Unlock()
+		return a.release, nil
+	}
+	admitted := make(chan struct{})
+	elem := a.waiting.PushBack(admitted)
+	a.queued.Update(int64(a.waiting.Len()))
+	a.lock.Unlock()
+
+	start := time.Now()
+	select {
+	case <-admitted:
+		a.wait.UpdateSince(start)
+		return a.release, nil
+	case <-ctx.Done():
+		a.lock.Lock()
+		defer a.lock.Unlock()
+
+		select {
+		case <-admitted:
+			// The call was admitted concurrently, so its slot is handed on.
+			a.active--
+			a.admit()
+		default:
+			a.waiting.Remove(elem)
+			a.queued.Update(int64(a.waiting.Len()))
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// release frees the slot of a completed RPC call.
+func (a *resourceArbiter) release() {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.active--
+	a.admit()
+}
+
+// slots returns the number of RPC calls allowed to execute concurrently.
+// Assumes the lock is held.
+func (a *resourceArbiter) slots() int {
+	if a.consensus > 0 {
+		return a.rpcSlotsDuringConsensus
+	}
+	return a.rpcSlots
+}
+
+// admit admits queued RPC calls in arrival order while slots are available.
+// Assumes the lock is held.
+func (a *resourceArbiter) admit() {
+	for a.waiting.Len() > 0 && a.active < a.slots() {
+		admitted := a.waiting.Remove(a.waiting.Front()).(chan struct{})
+		a.active++
+		close(admitted)
+	}
+	a.queued.Update(int64(a.waiting.Len()))
+}
@@ -0,0 +1,65 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceArbiter(t *testing.T) {
+	require := require.New(t)
+
+	a := newResourceArbiter(ResourceArbiterConfig{
+		RPCSlots:                2,
+		RPCSlotsDuringConsensus: 1,
+	})
+	ctx := context.Background()
+
+	release1, err := a.Admit(ctx, "eth_getLogs")
+	require.NoError(err)
+
+	// Consensus work lowers the number of concurrent calls, queueing calls
+	// beyond it in arrival order.
+	endConsensus := a.beginConsensus()
+	admitted := make(chan int, 2)
+	for i := 0; i < 2; i++ {
+		go func(i int) {
+			release, err := a.Admit(ctx, "eth_call")
+			if err == nil {
+				admitted <- i
+				release()
+			}
+		}(i)
+		require.Eventually(func() bool {
+			a.lock.Lock()
+			defer a.lock.Unlock()
+			return a.waiting.Len() == i+1
+		}, time.Second, time.Millisecond)
+	}
+
+	// Calls are not admitted beyond the deadline of their context.
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = a.Admit(timeoutCtx, "eth_call")
+	require.ErrorIs(err, context.DeadlineExceeded)
+
+	release1()
+	require.Equal(0, <-admitted)
+	require.Equal(1, <-admitted)
+	endConsensus()
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	require.Zero(a.active)
+	require.Zero(a.waiting.Len())
+}
+
+func TestResourceArbiterDisabled(t *testing.T) {
+	var a *resourceArbiter
+	a.beginConsensus()()
+}
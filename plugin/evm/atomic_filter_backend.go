@@ -0,0 +1,85 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/event"
+
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/eth/filters"
+)
+
+var _ filters.AtomicBackend = (*atomicFilterBackend)(nil)
+
+// atomicFilterBackend serves the atomic operations of accepted blocks to the
+// atomic filter API.
+type atomicFilterBackend struct {
+	vm *VM
+}
+
+func (b *atomicFilterBackend) AtomicOps(_ context.Context, number uint64) ([]*filters.AtomicOp, error) {
+	block := b.vm.blockChain.GetBlockByNumber(number)
+	if block == nil {
+		return nil, fmt.Errorf("block %d not found", number)
+	}
+	rules := b.vm.chainConfig.Rules(block.Number(), block.Time())
+	txs, err := ExtractAtomicTxs(block.ExtData(), rules.IsApricotPhase5, b.vm.codec)
+	if err != nil {
+		return nil, err
+	}
+	return atomicOpsOf(block, txs)
+}
+
+func (b *atomicFilterBackend) LastAcceptedNumber() uint64 {
+	return b.vm.blockChain.LastAcceptedBlock().NumberU64()
+}
+
+func (b *atomicFilterBackend) SubscribeAtomicOps(ch chan<- []*filters.AtomicOp) event.Subscription {
+	return b.vm.atomicOpsFeed.Subscribe(ch)
+}
+
+func (b *atomicFilterBackend) GetMaxBlocksPerRequest() int64 {
+	return b.vm.config.MaxBlocksPerRequest
+}
+
+// atomicOpsOf returns the shared memory operations of the atomic [txs] of
+// [block].
+func atomicOpsOf(block *types.Block, txs []*Tx) ([]*filters.AtomicOp, error) {
+	var ops []*filters.AtomicOp
+	for _, tx := range txs {
+		chainID, requests, err := tx.AtomicOps()
+		if err != nil {
+			return nil, err
+		}
+		for _, put := range requests.PutRequests {
+			op := &filters.AtomicOp{
+				BlockNumber: hexutil.Uint64(block.NumberU64()),
+				BlockHash:   block.Hash(),
+				TxID:        tx.ID(),
+				ChainID:     chainID,
+				Key:         put.Key,
+				Value:       put.Value,
+			}
+			for _, trait := range put.Traits {
+				op.Traits = append(op.Traits, trait)
+			}
+			ops = append(ops, op)
+		}
+		for _, key := range requests.RemoveRequests {
+			ops = append(ops, &filters.AtomicOp{
+				BlockNumber: hexutil.Uint64(block.NumberU64()),
+				BlockHash:   block.Hash(),
+				TxID:        tx.ID(),
+				ChainID:     chainID,
+				Remove:      true,
+				Key:         key,
+			})
+		}
+	}
+	return ops, nil
+}
@@ -0,0 +1,82 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/shubhamdubey02/cryftgo/ids"
+	"github.com/shubhamdubey02/cryftgo/utils/crypto/secp256k1"
+
+	"github.com/shubhamdubey02/coreth/eth/filters"
+	"github.com/shubhamdubey02/coreth/rpc"
+)
+
+func TestAtomicFilterAPI(t *testing.T) {
+	require := require.New(t)
+
+	issuer, vm, _, _, _ := GenesisVMWithUTXOs(t, true, genesisJSONApricotPhase2, "", "", map[ids.ShortID]uint64{
+		testShortIDAddrs[0]: 50000000,
+	})
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	backend := &atomicFilterBackend{vm: vm}
+	accepted := make(chan []*filters.AtomicOp, 1)
+	sub := backend.SubscribeAtomicOps(accepted)
+	defer sub.Unsubscribe()
+
+	importTx, err := vm.newImportTx(vm.ctx.XChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]})
+	require.NoError(err)
+	require.NoError(vm.mempool.AddLocalTx(importTx))
+	<-issuer
+
+	blk, err := vm.BuildBlock(context.Background())
+	require.NoError(err)
+	require.NoError(blk.Verify(context.Background()))
+	require.NoError(vm.SetPreference(context.Background(), blk.ID()))
+	require.NoError(blk.Accept(context.Background()))
+
+	// The import consumes the UTXO from the X-Chain's shared memory.
+	inputID := importTx.UnsignedAtomicTx.(*UnsignedImportTx).ImportedInputs[0].InputID()
+	ops := <-accepted
+	require.Len(ops, 1)
+	require.Equal(importTx.ID(), ops[0].TxID)
+	require.Equal(vm.ctx.XChainID, ops[0].ChainID)
+	require.True(ops[0].Remove)
+	require.Equal(inputID[:], []byte(ops[0].Key))
+
+	vm.blockChain.DrainAcceptorQueue()
+	api := filters.NewAtomicFilterAPI(backend)
+	from := rpc.BlockNumber(0)
+	all, err := api.GetAtomicOps(context.Background(), filters.AtomicFilterCriteria{FromBlock: &from})
+	require.NoError(err)
+	require.Equal(ops, all)
+
+	byChain, err := api.GetAtomicOps(context.Background(), filters.AtomicFilterCriteria{
+		FromBlock: &from,
+		ChainIDs:  []ids.ID{vm.ctx.XChainID},
+	})
+	require.NoError(err)
+	require.Len(byChain, 1)
+
+	otherChain, err := api.GetAtomicOps(context.Background(), filters.AtomicFilterCriteria{
+		FromBlock: &from,
+		ChainIDs:  []ids.ID{ids.GenerateTestID()},
+	})
+	require.NoError(err)
+	require.Empty(otherChain)
+
+	// Removes carry no traits, so they never match an address.
+	byAddress, err := api.GetAtomicOps(context.Background(), filters.AtomicFilterCriteria{
+		FromBlock: &from,
+		Addresses: []ids.ShortID{testShortIDAddrs[0]},
+	})
+	require.NoError(err)
+	require.Empty(byAddress)
+}
@@ -69,7 +69,7 @@ func newAtomicSyncer(client syncclient.LeafClient, atomicBackend *atomicBackend,
 	tasks := make(chan syncclient.LeafSyncTask, 1)
 	tasks <- &atomicSyncerLeafTask{atomicSyncer: atomicSyncer}
 	close(tasks)
-	atomicSyncer.syncer = syncclient.NewCallbackLeafSyncer(client, tasks, requestSize)
+	atomicSyncer.syncer = syncclient.NewCallbackLeafSyncer(client, tasks, requestSize, nil)
 	return atomicSyncer, nil
 }
 
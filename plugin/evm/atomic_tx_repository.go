@@ -31,7 +31,8 @@ var (
 	atomicRepoMetadataDBPrefix = []byte("atomicRepoMetadataDB")
 	maxIndexedHeightKey        = []byte("maxIndexedAtomicTxHeight")
 
-	// Historically used to track the completion of a migration
+	// Historically used to track the completion of a migration, now removed
+	// by [databaseMigrations]
 	// bonusBlocksRepairedKey     = []byte("bonusBlocksRepaired")
 )
 
@@ -0,0 +1,508 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/shubhamdubey02/cryftgo/database"
+)
+
+// backupManifestName is the object describing the latest checkpoint.
+const backupManifestName = "latest.json"
+
+var (
+	// backupRestoreMarkerKey is stored in the database while a checkpoint is
+	// restored into it, so that a database left partially restored by a
+	// crash is detected and wiped when the node restarts.
+	backupRestoreMarkerKey = []byte("backup_restore_in_progress")
+
+	errBackupNotFound      = errors.New("backup not found")
+	errBackupIntegrity     = errors.New("backup failed integrity verification")
+	errDatabaseNotEmpty    = errors.New("cannot restore a backup into a non-empty database")
+	errMalformedCheckpoint = errors.New("malformed checkpoint")
+)
+
+// backupManifest describes a checkpoint of the database.
+type backupManifest struct {
+	// Checkpoint is the name of the checkpoint object.
+	Checkpoint string `json:"checkpoint"`
+	// Height and Hash are the last accepted block when the checkpoint began.
+	Height uint64      `json:"height"`
+	Hash   common.Hash `json:"hash"`
+	// Keys is the number of entries of the checkpoint.
+	Keys uint64 `json:"keys"`
+	// Size and SHA256 are the size and digest of the checkpoint object.
+	Size   int64     `json:"size"`
+	SHA256 string    `json:"sha256"`
+	Time   time.Time `json:"time"`
+}
+
+// backupStore stores the objects of the backups.
+type backupStore interface {
+	put(ctx context.Context, name string, r io.Reader, size int64) error
+	// get returns [errBackupNotFound] if there is no object [name].
+	get(ctx context.Context, name string) (io.ReadCloser, error)
+}
+
+// newBackupStore returns the store of the backups at [location], which is
+// either the http(s) URL of a bucket or a directory, such as a mounted
+// bucket. Objects of a URL are read and written with GET and PUT requests
+// carrying [headers], such as the authorization of the bucket.
+func newBackupStore(location string, headers map[string]string) (backupStore, error) {
+	u, err := url.Parse(location)
+	if err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		return &httpBackupStore{
+			base:    strings.TrimSuffix(location, "/"),
+			headers: headers,
+			client:  http.DefaultClient,
+		}, nil
+	}
+	if err == nil && u.Scheme == "file" {
+		location = u.Path
+	}
+	if err := os.MkdirAll(location, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	return dirBackupStore(location), nil
+}
+
+// dirBackupStore stores objects as the files of a directory.
+type dirBackupStore string
+
+func (d dirBackupStore) put(_ context.Context, name string, r io.Reader, _ int64) error {
+	// Write to a temporary file first, so that an interrupted upload never
+	// replaces a complete object.
+	f, err := os.CreateTemp(string(d), name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), filepath.Join(string(d), name))
+}
+
+func (d dirBackupStore) get(_ context.Context, name string) (io.ReadCloser, error) {
+	f, err := os.Open(filepath.Join(string(d), name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", errBackupNotFound, name)
+	}
+	return f, err
+}
+
+// httpBackupStore stores objects at the URLs below [base].
+type httpBackupStore struct {
+	base    string
+	headers map[string]string
+	client  *http.Client
+}
+
+func (h *httpBackupStore) request(ctx context.Context, method, name string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, h.base+"/"+name, body)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range h.headers {
+		req.Header.Set(key, value)
+	}
+	return req, nil
+}
+
+func (h *httpBackupStore) put(ctx context.Context, name string, r io.Reader, size int64) error {
+	req, err := h.request(ctx, http.MethodPut, name, r)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	res, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("failed to upload %s: %s", name, res.Status)
+	}
+	return nil
+}
+
+func (h *httpBackupStore) get(ctx context.Context, name string) (io.ReadCloser, error) {
+	req, err := h.request(ctx, http.MethodGet, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := h.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		res.Body.Close()
+		return nil, fmt.Errorf("%w: %s", errBackupNotFound, name)
+	case res.StatusCode/100 != 2:
+		res.Body.Close()
+		return nil, fmt.Errorf("failed to download %s: %s", name, res.Status)
+	}
+	return res.Body, nil
+}
+
+// writeCheckpoint writes every entry of [db] to [w] as a gzip compressed
+// sequence of length prefixed keys and values, returning the number of
+// entries. The iterator of the database reads a consistent view of it, so
+// that writes during the checkpoint are either entirely included or not.
+func writeCheckpoint(db database.Iteratee, w io.Writer) (uint64, error) {
+	zw := gzip.NewWriter(w)
+	bw := bufio.NewWriter(zw)
+	it := db.NewIterator()
+	defer it.Release()
+
+	var (
+		keys   uint64
+		prefix [binary.MaxVarintLen64]byte
+	)
+	for it.Next() {
+		for _, field := range [][]byte{it.Key(), it.Value()} {
+			n := binary.PutUvarint(prefix[:], uint64(len(field)))
+			if _, err := bw.Write(prefix[:n]); err != nil {
+				return 0, err
+			}
+			if _, err := bw.Write(field); err != nil {
+				return 0, err
+			}
+		}
+		keys++
+	}
+	if err := it.Error(); err != nil {
+		return 0, err
+	}
+	if err := bw.Flush(); err != nil {
+		return 0, err
+	}
+	return keys, zw.Close()
+}
+
+// readCheckpoint writes the entries of the checkpoint read from [r] to [db],
+// returning the number of entries.
+func readCheckpoint(r io.Reader, db database.Batcher) (uint64, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", errMalformedCheckpoint, err)
+	}
+	defer zr.Close()
+	br := bufio.NewReader(zr)
+
+	var (
+		keys  uint64
+		batch = db.NewBatch()
+	)
+	readField := func() ([]byte, error) {
+		length, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, err
+		}
+		field := make([]byte, length)
+		if _, err := io.ReadFull(br, field); err != nil {
+			return nil, fmt.Errorf("%w: %w", errMalformedCheckpoint, err)
+		}
+		return field, nil
+	}
+	for {
+		key, err := readField()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+		value, err := readField()
+		if err != nil {
+			return 0, fmt.Errorf("%w: %w", errMalformedCheckpoint, err)
+		}
+		if err := batch.Put(key, value); err != nil {
+			return 0, err
+		}
+		keys++
+		if batch.Size() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return 0, err
+			}
+			batch.Reset()
+		}
+	}
+	return keys, batch.Write()
+}
+
+// uploadCheckpoint uploads a checkpoint of [db] taken after the block
+// [height] with [hash] was accepted, then the manifest pointing to it.
+func uploadCheckpoint(ctx context.Context, store backupStore, db database.Iteratee, height uint64, hash common.Hash) (*backupManifest, error) {
+	// The checkpoint is staged in a temporary file, as its size and digest
+	// must be known before it is uploaded.
+	f, err := os.CreateTemp("", "coreth-checkpoint-*")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		f.Close()
+		os.Remove(f.Name())
+	}()
+
+	digest := sha256.New()
+	keys, err := writeCheckpoint(db, io.MultiWriter(f, digest))
+	if err != nil {
+		return nil, fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	size, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	manifest := &backupManifest{
+		Checkpoint: fmt.Sprintf("checkpoint-%d-%s.gz", height, hash.Hex()[2:10]),
+		Height:     height,
+		Hash:       hash,
+		Keys:       keys,
+		Size:       size,
+		SHA256:     hex.EncodeToString(digest.Sum(nil)),
+		Time:       time.Now().UTC(),
+	}
+	if err := store.put(ctx, manifest.Checkpoint, f, size); err != nil {
+		return nil, fmt.Errorf("failed to upload checkpoint: %w", err)
+	}
+	// The manifest is uploaded last, so that it only points to complete
+	// checkpoints.
+	manifestBytes, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.put(ctx, backupManifestName, bytes.NewReader(manifestBytes), int64(len(manifestBytes))); err != nil {
+		return nil, fmt.Errorf("failed to upload backup manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// restoreCheckpoint writes the latest checkpoint of [store] to the empty
+// database [db], after verifying its size and digest against its manifest.
+// [db] is wiped if the checkpoint cannot be restored entirely.
+func restoreCheckpoint(ctx context.Context, store backupStore, db database.Database) (*backupManifest, error) {
+	if empty, err := isEmpty(db); err != nil {
+		return nil, err
+	} else if !empty {
+		return nil, errDatabaseNotEmpty
+	}
+	r, err := store.get(ctx, backupManifestName)
+	if err != nil {
+		return nil, err
+	}
+	manifest := new(backupManifest)
+	err = json.NewDecoder(r).Decode(manifest)
+	r.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode backup manifest: %w", err)
+	}
+
+	// The checkpoint is verified before any of it is written to [db], so
+	// that a corrupted backup does not leave a partial database behind.
+	f, err := os.CreateTemp("", "coreth-checkpoint-*")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		f.Close()
+		os.Remove(f.Name())
+	}()
+	r, err = store.get(ctx, manifest.Checkpoint)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.New()
+	size, err := io.Copy(io.MultiWriter(f, digest), r)
+	r.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to download checkpoint: %w", err)
+	}
+	if err := verifyCheckpoint(manifest, size, digest); err != nil {
+		return nil, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if err := db.Put(backupRestoreMarkerKey, nil); err != nil {
+		return nil, err
+	}
+	keys, err := readCheckpoint(f, db)
+	if err != nil {
+		err = fmt.Errorf("failed to restore checkpoint: %w", err)
+	} else if keys != manifest.Keys {
+		err = fmt.Errorf("%w: restored %d keys, expected %d", errBackupIntegrity, keys, manifest.Keys)
+	}
+	if err != nil {
+		// If the wipe fails too, the marker left behind gets the database
+		// wiped on the next start.
+		if wipeErr := wipeDatabase(db); wipeErr != nil {
+			log.Error("Failed to wipe partially restored database", "err", wipeErr)
+		}
+		return nil, err
+	}
+	if err := db.Delete(backupRestoreMarkerKey); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// wipeDatabase deletes every entry of [db]. The restore marker is deleted
+// last, so that an interrupted wipe is resumed on the next start.
+func wipeDatabase(db database.Database) error {
+	it := db.NewIterator()
+	defer it.Release()
+
+	batch := db.NewBatch()
+	for it.Next() {
+		if bytes.Equal(it.Key(), backupRestoreMarkerKey) {
+			continue
+		}
+		if err := batch.Delete(it.Key()); err != nil {
+			return err
+		}
+		if batch.Size() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	return db.Delete(backupRestoreMarkerKey)
+}
+
+func verifyCheckpoint(manifest *backupManifest, size int64, digest hash.Hash) error {
+	if size != manifest.Size {
+		return fmt.Errorf("%w: size %d, expected %d", errBackupIntegrity, size, manifest.Size)
+	}
+	if sum := hex.EncodeToString(digest.Sum(nil)); sum != manifest.SHA256 {
+		return fmt.Errorf("%w: sha256 %s, expected %s", errBackupIntegrity, sum, manifest.SHA256)
+	}
+	return nil
+}
+
+func isEmpty(db database.Iteratee) (bool, error) {
+	it := db.NewIterator()
+	defer it.Release()
+	return !it.Next(), it.Error()
+}
+
+// restoreBackup bootstraps the empty database [db] from the latest backup,
+// if restoring is enabled. A database that already holds a chain is left
+// untouched, unless it was left partially restored by an interrupted restore,
+// in which case it is wiped first.
+func (vm *VM) restoreBackup(db database.Database) error {
+	if restoring, err := db.Has(backupRestoreMarkerKey); err != nil {
+		return err
+	} else if restoring {
+		log.Warn("Wiping database left partially restored from a backup")
+		if err := wipeDatabase(db); err != nil {
+			return fmt.Errorf("failed to wipe partially restored database: %w", err)
+		}
+	}
+	config := vm.config.Backup
+	if !config.Restore {
+		return nil
+	}
+	if empty, err := isEmpty(db); err != nil || !empty {
+		return err
+	}
+	store, err := newBackupStore(config.URL, config.Headers)
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	log.Info("Restoring database from backup", "url", config.URL)
+	manifest, err := restoreCheckpoint(context.TODO(), store, db)
+	if errors.Is(err, errBackupNotFound) {
+		// Nodes started before any backup was uploaded bootstrap normally.
+		log.Warn("No backup to restore the database from", "err", err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	log.Info("Restored database from backup", "height", manifest.Height, "hash", manifest.Hash, "keys", manifest.Keys, "taken", manifest.Time, "elapsed", time.Since(start))
+	return nil
+}
+
+// startBackups uploads a checkpoint of [db] every configured interval in the
+// background until the VM shuts down.
+func (vm *VM) startBackups(db database.Database) error {
+	config := vm.config.Backup
+	if config.URL == "" || config.Interval.Duration == 0 {
+		return nil
+	}
+	store, err := newBackupStore(config.URL, config.Headers)
+	if err != nil {
+		return err
+	}
+
+	vm.shutdownWg.Add(1)
+	go vm.ctx.Log.RecoverAndPanic(func() {
+		defer vm.shutdownWg.Done()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			<-vm.shutdownChan
+			cancel()
+		}()
+
+		ticker := time.NewTicker(config.Interval.Duration)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				start := time.Now()
+				lastAccepted := vm.blockChain.LastAcceptedBlock()
+				manifest, err := uploadCheckpoint(ctx, store, db, lastAccepted.NumberU64(), lastAccepted.Hash())
+				if err != nil {
+					log.Warn("Failed to back up database", "err", err)
+					continue
+				}
+				log.Info("Backed up database", "checkpoint", manifest.Checkpoint, "keys", manifest.Keys, "size", manifest.Size, "elapsed", time.Since(start))
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+	return nil
+}
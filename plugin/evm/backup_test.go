@@ -0,0 +1,178 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shubhamdubey02/cryftgo/database/memdb"
+	"github.com/stretchr/testify/require"
+)
+
+func newBackupTestDB(t *testing.T, entries int) *memdb.Database {
+	db := memdb.New()
+	for i := 0; i < entries; i++ {
+		require.NoError(t, db.Put([]byte(fmt.Sprintf("key-%d", i)), []byte(fmt.Sprintf("value-%d", i))))
+	}
+	return db
+}
+
+func requireSameEntries(t *testing.T, expected, actual *memdb.Database) {
+	it := expected.NewIterator()
+	defer it.Release()
+	var keys int
+	for it.Next() {
+		value, err := actual.Get(it.Key())
+		require.NoError(t, err)
+		require.Equal(t, it.Value(), value)
+		keys++
+	}
+	require.NoError(t, it.Error())
+	require.Equal(t, keys, countEntries(t, actual))
+}
+
+func countEntries(t *testing.T, db *memdb.Database) int {
+	it := db.NewIterator()
+	defer it.Release()
+	var keys int
+	for it.Next() {
+		keys++
+	}
+	require.NoError(t, it.Error())
+	return keys
+}
+
+func TestBackupRestore(t *testing.T) {
+	require := require.New(t)
+
+	dir := t.TempDir()
+	store, err := newBackupStore(dir, nil)
+	require.NoError(err)
+
+	_, err = restoreCheckpoint(context.Background(), store, memdb.New())
+	require.ErrorIs(err, errBackupNotFound)
+
+	db := newBackupTestDB(t, 1000)
+	manifest, err := uploadCheckpoint(context.Background(), store, db, 10, common.Hash{0x01})
+	require.NoError(err)
+	require.EqualValues(1000, manifest.Keys)
+	require.Equal(uint64(10), manifest.Height)
+
+	restored := memdb.New()
+	restoredManifest, err := restoreCheckpoint(context.Background(), store, restored)
+	require.NoError(err)
+	require.Equal(manifest.Checkpoint, restoredManifest.Checkpoint)
+	requireSameEntries(t, db, restored)
+
+	// Backups are only restored into empty databases.
+	_, err = restoreCheckpoint(context.Background(), store, restored)
+	require.ErrorIs(err, errDatabaseNotEmpty)
+
+	// A corrupted checkpoint is detected before it is written.
+	path := filepath.Join(dir, manifest.Checkpoint)
+	data, err := os.ReadFile(path)
+	require.NoError(err)
+	data[len(data)/2] ^= 0xff
+	require.NoError(os.WriteFile(path, data, 0o644))
+	restored = memdb.New()
+	_, err = restoreCheckpoint(context.Background(), store, restored)
+	require.ErrorIs(err, errBackupIntegrity)
+	require.Zero(countEntries(t, restored))
+}
+
+func TestBackupRestoreInterrupted(t *testing.T) {
+	require := require.New(t)
+
+	dir := t.TempDir()
+	store, err := newBackupStore(dir, nil)
+	require.NoError(err)
+	db := newBackupTestDB(t, 1000)
+	manifest, err := uploadCheckpoint(context.Background(), store, db, 10, common.Hash{0x01})
+	require.NoError(err)
+
+	// A checkpoint that cannot be restored entirely is wiped.
+	corrupted := *manifest
+	corrupted.Keys++
+	manifestBytes, err := json.Marshal(&corrupted)
+	require.NoError(err)
+	require.NoError(os.WriteFile(filepath.Join(dir, backupManifestName), manifestBytes, 0o644))
+	restored := memdb.New()
+	_, err = restoreCheckpoint(context.Background(), store, restored)
+	require.ErrorIs(err, errBackupIntegrity)
+	require.Zero(countEntries(t, restored))
+
+	// A database left partially restored by a crash is wiped and restored
+	// again on the next start.
+	manifestBytes, err = json.Marshal(manifest)
+	require.NoError(err)
+	require.NoError(os.WriteFile(filepath.Join(dir, backupManifestName), manifestBytes, 0o644))
+	restored = newBackupTestDB(t, 10)
+	require.NoError(restored.Put(backupRestoreMarkerKey, nil))
+	require.NoError(restored.Put([]byte("stale"), []byte("value")))
+	vm := &VM{config: Config{Backup: BackupConfig{URL: dir, Restore: true}}}
+	require.NoError(vm.restoreBackup(restored))
+	requireSameEntries(t, db, restored)
+}
+
+func TestHTTPBackupStore(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		lock    sync.Mutex
+		objects = make(map[string][]byte)
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		name := strings.TrimPrefix(r.URL.Path, "/bucket/")
+		lock.Lock()
+		defer lock.Unlock()
+		switch r.Method {
+		case http.MethodPut:
+			data, err := io.ReadAll(r.Body)
+			require.NoError(err)
+			require.EqualValues(len(data), r.ContentLength)
+			objects[name] = data
+		case http.MethodGet:
+			data, ok := objects[name]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write(data)
+		}
+	}))
+	defer server.Close()
+
+	store, err := newBackupStore(server.URL+"/bucket/", map[string]string{"Authorization": "Bearer token"})
+	require.NoError(err)
+	db := newBackupTestDB(t, 10)
+	manifest, err := uploadCheckpoint(context.Background(), store, db, 1, common.Hash{})
+	require.NoError(err)
+	require.Contains(objects, manifest.Checkpoint)
+	require.Contains(objects, backupManifestName)
+
+	restored := memdb.New()
+	_, err = restoreCheckpoint(context.Background(), store, restored)
+	require.NoError(err)
+	requireSameEntries(t, db, restored)
+
+	unauthorized, err := newBackupStore(server.URL+"/bucket", nil)
+	require.NoError(err)
+	_, err = restoreCheckpoint(context.Background(), unauthorized, memdb.New())
+	require.ErrorContains(err, "403")
+}
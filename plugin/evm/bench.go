@@ -0,0 +1,116 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/shubhamdubey02/coreth/eth"
+)
+
+const (
+	benchFormatCSV  = "csv"
+	benchFormatJSON = "json"
+)
+
+var benchFormats = []string{benchFormatCSV, benchFormatJSON}
+
+// benchCSVHeader names the columns of the CSV output. Durations are written
+// in nanoseconds.
+var benchCSVHeader = []string{"number", "hash", "txs", "gas_used", "execution_ns", "root_ns", "commit_ns"}
+
+// benchWriter writes block benchmarks in the configured format.
+type benchWriter struct {
+	buf  *bufio.Writer
+	csv  *csv.Writer
+	json *json.Encoder
+}
+
+func newBenchWriter(w io.Writer, format string) (*benchWriter, error) {
+	bw := &benchWriter{buf: bufio.NewWriter(w)}
+	switch format {
+	case benchFormatCSV:
+		bw.csv = csv.NewWriter(bw.buf)
+		if err := bw.csv.Write(benchCSVHeader); err != nil {
+			return nil, err
+		}
+	case benchFormatJSON:
+		bw.json = json.NewEncoder(bw.buf)
+	default:
+		return nil, fmt.Errorf("unknown bench format %q", format)
+	}
+	return bw, nil
+}
+
+func (w *benchWriter) write(b *eth.BlockBenchmark) error {
+	if w.json != nil {
+		return w.json.Encode(b)
+	}
+	return w.csv.Write([]string{
+		strconv.FormatUint(b.Number, 10),
+		b.Hash.Hex(),
+		strconv.Itoa(b.Txs),
+		strconv.FormatUint(b.GasUsed, 10),
+		strconv.FormatInt(b.Execution.Nanoseconds(), 10),
+		strconv.FormatInt(b.Root.Nanoseconds(), 10),
+		strconv.FormatInt(b.Commit.Nanoseconds(), 10),
+	})
+}
+
+func (w *benchWriter) flush() error {
+	if w.csv != nil {
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return err
+		}
+	}
+	return w.buf.Flush()
+}
+
+// runBench re-executes the configured range of accepted blocks, writing the
+// time spent on each to the configured output. It blocks until every block
+// is replayed.
+func (vm *VM) runBench() error {
+	config := vm.config.Bench
+	if config.Output == "" {
+		return nil
+	}
+	f, err := os.Create(config.Output)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w, err := newBenchWriter(f, config.Format)
+	if err != nil {
+		return err
+	}
+	var (
+		start   = time.Now()
+		blocks  int
+		elapsed time.Duration
+	)
+	err = vm.eth.BenchmarkBlocks(context.Background(), config.FromBlock, config.ToBlock, func(b *eth.BlockBenchmark) error {
+		blocks++
+		elapsed += b.Execution + b.Root + b.Commit
+		return w.write(b)
+	})
+	if err != nil {
+		return err
+	}
+	if err := w.flush(); err != nil {
+		return err
+	}
+	log.Info("Benchmarked blocks", "blocks", blocks, "processing", elapsed, "elapsed", time.Since(start), "output", config.Output)
+	return f.Close()
+}
@@ -0,0 +1,70 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"math/big"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/eth"
+	"github.com/shubhamdubey02/coreth/params"
+)
+
+func TestBenchmarkBlocks(t *testing.T) {
+	require := require.New(t)
+
+	// Replaying blocks requires the state of their parents on disk.
+	h := NewTestHarness(t, TestHarnessConfig{Config: `{"pruning-enabled": false}`})
+	for nonce := uint64(0); nonce < 3; nonce++ {
+		h.IssueTx(h.SignTx(types.NewTransaction(nonce, common.Address{0xaa}, big.NewInt(1), params.TxGas, big.NewInt(params.LaunchMinGasPrice), nil), HarnessFunderKey))
+		// Space the blocks out so that each can pay its block gas cost.
+		h.AdvanceTime(10 * time.Second)
+		h.BuildAndAccept()
+	}
+
+	var csvOut, jsonOut bytes.Buffer
+	csvWriter, err := newBenchWriter(&csvOut, benchFormatCSV)
+	require.NoError(err)
+	jsonWriter, err := newBenchWriter(&jsonOut, benchFormatJSON)
+	require.NoError(err)
+	require.NoError(h.VM.eth.BenchmarkBlocks(context.Background(), 2, 3, func(b *eth.BlockBenchmark) error {
+		require.Equal(h.VM.blockChain.GetBlockByNumber(b.Number).Hash(), b.Hash)
+		require.Equal(1, b.Txs)
+		require.Equal(params.TxGas, b.GasUsed)
+		if err := csvWriter.write(b); err != nil {
+			return err
+		}
+		return jsonWriter.write(b)
+	}))
+	require.NoError(csvWriter.flush())
+	require.NoError(jsonWriter.flush())
+
+	records, err := csv.NewReader(&csvOut).ReadAll()
+	require.NoError(err)
+	require.Len(records, 3)
+	require.Equal(benchCSVHeader, records[0])
+	require.Equal([]string{"2", "3"}, []string{records[1][0], records[2][0]})
+	require.Equal(strconv.FormatUint(params.TxGas, 10), records[1][3])
+
+	dec := json.NewDecoder(&jsonOut)
+	for _, number := range []uint64{2, 3} {
+		var b eth.BlockBenchmark
+		require.NoError(dec.Decode(&b))
+		require.Equal(number, b.Number)
+	}
+	require.False(dec.More())
+
+	require.ErrorContains(h.VM.eth.BenchmarkBlocks(context.Background(), 3, 4, nil), "after the last accepted block")
+	require.ErrorContains(h.VM.eth.BenchmarkBlocks(context.Background(), 0, 1, nil), "invalid block range")
+}
@@ -0,0 +1,76 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/shubhamdubey02/coreth/core/types"
+)
+
+const (
+	// blobSidecarsConsumer is the name of the accepted block consumer
+	// fetching the blob sidecars of accepted blocks.
+	blobSidecarsConsumer = "blob-sidecars"
+
+	// blobSidecarsFetchTimeout bounds the time spent fetching the blob
+	// sidecars of an accepted block from peers.
+	blobSidecarsFetchTimeout = time.Minute
+)
+
+// startBlobSidecars retrieves in the background the blob sidecars of accepted
+// blocks from peers if they are not stored locally, as is the case for blocks
+// built by other validators. Blocks accepted while the VM was down, or whose
+// fetch was interrupted by a shutdown, are fetched after a restart.
+func (vm *VM) startBlobSidecars() error {
+	if vm.syncClient == nil {
+		return nil
+	}
+	return vm.consumeAcceptedBlocks(blobSidecarsConsumer, vm.fetchBlobSidecars)
+}
+
+// fetchBlobSidecars retrieves the blob sidecars of the accepted [block] from
+// peers if they are not stored locally. Sidecars that cannot be fetched are
+// skipped, but an error is returned if the fetch is interrupted by a shutdown
+// so that it is retried after a restart.
+func (vm *VM) fetchBlobSidecars(block *types.Block) error {
+	if !hasBlobTxs(block) || vm.blockChain.HasBlobSidecars(block.Hash(), block.NumberU64()) {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), blobSidecarsFetchTimeout)
+	defer cancel()
+	go func() {
+		select {
+		case <-vm.shutdownChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	sidecars, err := vm.syncClient.GetBlobSidecars(ctx, block)
+	if err != nil {
+		select {
+		case <-vm.shutdownChan:
+			return err
+		default:
+		}
+		log.Warn("Failed to fetch blob sidecars", "hash", block.Hash(), "number", block.NumberU64(), "err", err)
+		return nil
+	}
+	vm.blockChain.WriteBlobSidecars(block.Hash(), block.NumberU64(), sidecars)
+	return nil
+}
+
+// hasBlobTxs reports whether [block] includes blob transactions.
+func hasBlobTxs(block *types.Block) bool {
+	for _, tx := range block.Transactions() {
+		if tx.Type() == types.BlobTxType {
+			return true
+		}
+	}
+	return false
+}
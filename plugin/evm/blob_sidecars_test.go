@@ -0,0 +1,33 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shubhamdubey02/coreth/core/rawdb"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/params"
+)
+
+func TestBlobSidecarsConsumer(t *testing.T) {
+	require := require.New(t)
+
+	h := NewTestHarness(t, TestHarnessConfig{})
+	h.IssueTx(h.SignTx(types.NewTransaction(0, common.Address{0xaa}, big.NewInt(1), params.TxGas, big.NewInt(params.LaunchMinGasPrice), nil), HarnessFunderKey))
+	h.BuildAndAccept()
+
+	// Accepted blocks are delivered to the blob sidecar fetcher by its
+	// accepted block consumer, which acknowledges blocks without blob
+	// transactions.
+	require.Eventually(func() bool {
+		offset, err := rawdb.ReadAcceptedConsumerOffset(h.VM.chaindb, blobSidecarsConsumer)
+		return err == nil && offset != nil && *offset == 1
+	}, 10*time.Second, 10*time.Millisecond)
+}
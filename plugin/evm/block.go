@@ -19,6 +19,7 @@ import (
 	"github.com/shubhamdubey02/coreth/core"
 	"github.com/shubhamdubey02/coreth/core/rawdb"
 	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/eth/filters"
 	"github.com/shubhamdubey02/coreth/params"
 	"github.com/shubhamdubey02/coreth/precompile/precompileconfig"
 	"github.com/shubhamdubey02/coreth/predicate"
@@ -152,6 +153,10 @@ type Block struct {
 	vm        *VM
 	status    choices.Status
 	atomicTxs []*Tx
+
+	// pChainHeight is the P-Chain height of the proposervm block wrapping the
+	// block, if it was verified with its context.
+	pChainHeight uint64
 }
 
 // newBlock returns a new Block wrapping the ethBlock type and implementing the snowman.Block interface
@@ -177,6 +182,11 @@ func (b *Block) ID() ids.ID { return b.id }
 func (b *Block) Accept(context.Context) error {
 	vm := b.vm
 
+	loop := vm.watchdog.loop(watchdogAcceptBlock)
+	loop.begin()
+	defer loop.end()
+	defer vm.arbiter.beginConsensus()()
+
 	// Although returning an error from Accept is considered fatal, it is good
 	// practice to cleanup the batch we were modifying in the case of an error.
 	defer vm.db.Abort()
@@ -194,6 +204,14 @@ func (b *Block) Accept(context.Context) error {
 	if err := b.handlePrecompileAccept(rules, sharedMemoryWriter); err != nil {
 		return err
 	}
+	// Record the acceptance before the accepted block is emitted to
+	// subscribers, which may serve it.
+	if vm.config.RPCAcceptanceMetadata {
+		rawdb.WriteBlockAcceptance(vm.chaindb, b.ethBlock.Hash(), b.ethBlock.NumberU64(), &rawdb.BlockAcceptance{
+			Time:         uint64(vm.clock.Time().UnixMilli()),
+			PChainHeight: b.pChainHeight,
+		})
+	}
 	if err := vm.blockChain.Accept(b.ethBlock); err != nil {
 		return fmt.Errorf("chain could not accept %s: %w", b.ID(), err)
 	}
@@ -221,10 +239,27 @@ func (b *Block) Accept(context.Context) error {
 		return fmt.Errorf("could not create commit batch processing block[%s]: %w", b.ID(), err)
 	}
 
+	// Decode the atomic operations before committing, so that a failure leaves
+	// the block unaccepted rather than missing from the subscriptions.
+	var ops []*filters.AtomicOp
+	if len(b.atomicTxs) > 0 {
+		ops, err = atomicOpsOf(b.ethBlock, b.atomicTxs)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Apply any shared memory requests that accumulated from processing the logs
 	// of the accepted block (generated by precompiles) atomically with other pending
 	// changes to the vm's versionDB.
-	return atomicState.Accept(vdbBatch, sharedMemoryWriter.requests)
+	if err := atomicState.Accept(vdbBatch, sharedMemoryWriter.requests); err != nil {
+		return err
+	}
+
+	if len(ops) > 0 {
+		vm.atomicOpsFeed.Send(ops)
+	}
+	return nil
 }
 
 // handlePrecompileAccept calls Accept on any logs generated with an active precompile address that implements
@@ -354,6 +389,9 @@ func (b *Block) ShouldVerifyWithContext(context.Context) (bool, error) {
 
 // VerifyWithContext implements the block.WithVerifyContext interface
 func (b *Block) VerifyWithContext(ctx context.Context, proposerVMBlockCtx *block.Context) error {
+	if proposerVMBlockCtx != nil {
+		b.pChainHeight = proposerVMBlockCtx.PChainHeight
+	}
 	return b.verify(&precompileconfig.PredicateContext{
 		SnowCtx:            b.vm.ctx,
 		ProposerVMBlockCtx: proposerVMBlockCtx,
@@ -364,6 +402,8 @@ func (b *Block) VerifyWithContext(ctx context.Context, proposerVMBlockCtx *block
 // Enforces that the predicates are valid within [predicateContext].
 // Writes the block details to disk and the state to the trie manager iff writes=true.
 func (b *Block) verify(predicateContext *precompileconfig.PredicateContext, writes bool) error {
+	defer b.vm.arbiter.beginConsensus()()
+
 	if predicateContext.ProposerVMBlockCtx != nil {
 		log.Debug("Verifying block with context", "block", b.ID(), "height", b.Height())
 	} else {
@@ -397,6 +437,10 @@ func (b *Block) verify(predicateContext *precompileconfig.PredicateContext, writ
 		return nil
 	}
 
+	if b.vm.config.ReceiptsVerification {
+		return b.verifyReceipts(writes)
+	}
+
 	err := b.vm.blockChain.InsertBlockManual(b.ethBlock, writes)
 	if err != nil || !writes {
 		// if an error occurred inserting the block into the chain
@@ -23,9 +23,29 @@ const (
 	minBlockBuildingRetryDelay = 500 * time.Millisecond
 )
 
+// blockBuilderConfig controls how often the block builder notifies the
+// consensus engine that a block should be built.
+type blockBuilderConfig struct {
+	// minDelay is the minimum time between the last build attempt and a
+	// notification triggered by new transactions. Notifications within
+	// [minDelay] are deferred rather than dropped.
+	minDelay time.Duration
+	// retryDelay is the time to wait after a build attempt before retrying
+	// while transactions remain pending.
+	retryDelay time.Duration
+	// maxRetryDelay bounds the retry delay, which doubles after every
+	// build attempt that fails to produce a block (e.g. when the pending
+	// transactions do not pay the required fees).
+	maxRetryDelay time.Duration
+	// idle stops retrying after a failed build attempt until a new
+	// transaction is received.
+	idle bool
+}
+
 type blockBuilder struct {
 	ctx         *snow.Context
 	chainConfig *params.ChainConfig
+	config      blockBuilderConfig
 
 	txPool  *txpool.TxPool
 	mempool *Mempool
@@ -49,18 +69,30 @@ type blockBuilder struct {
 	// If the mempool receives a new transaction, the block builder will send a new notification to
 	// the engine and cancel the timer.
 	buildBlockTimer *timer.Timer
+
+	// [lastBuild] is the time of the last build attempt and [retryDelay] is
+	// the delay before the next retry. Both are protected by [buildBlockLock].
+	lastBuild  time.Time
+	retryDelay time.Duration
 }
 
 func (vm *VM) NewBlockBuilder(notifyBuildBlockChan chan<- commonEng.Message) *blockBuilder {
 	b := &blockBuilder{
-		ctx:                  vm.ctx,
-		chainConfig:          vm.chainConfig,
+		ctx:         vm.ctx,
+		chainConfig: vm.chainConfig,
+		config: blockBuilderConfig{
+			minDelay:      vm.config.BuildBlockMinDelay.Duration,
+			retryDelay:    vm.config.BuildBlockRetryDelay.Duration,
+			maxRetryDelay: vm.config.BuildBlockMaxRetryDelay.Duration,
+			idle:          vm.config.BuildBlockIdleEnabled,
+		},
 		txPool:               vm.txPool,
 		mempool:              vm.mempool,
 		shutdownChan:         vm.shutdownChan,
 		shutdownWg:           &vm.shutdownWg,
 		notifyBuildBlockChan: notifyBuildBlockChan,
 	}
+	b.retryDelay = b.config.retryDelay
 	b.handleBlockBuilding()
 	return b
 }
@@ -86,15 +118,30 @@ func (b *blockBuilder) buildBlockTimerCallback() {
 }
 
 // handleGenerateBlock is called from the VM immediately after BuildBlock.
-func (b *blockBuilder) handleGenerateBlock() {
+// [built] reports whether a block was generated.
+func (b *blockBuilder) handleGenerateBlock(built bool) {
 	b.buildBlockLock.Lock()
 	defer b.buildBlockLock.Unlock()
 
 	// Reset buildSent now that the engine has called BuildBlock.
 	b.buildSent = false
+	b.lastBuild = time.Now()
+
+	switch {
+	case built:
+		b.retryDelay = b.config.retryDelay
+	case b.config.idle:
+		// Wait for a new transaction before trying again.
+		log.Debug("Block building failed, waiting for new transactions")
+		return
+	}
 
 	// Set a timer to check if calling build block a second time is needed.
-	b.buildBlockTimer.SetTimeoutIn(minBlockBuildingRetryDelay)
+	b.buildBlockTimer.SetTimeoutIn(b.retryDelay)
+	if !built {
+		// Back off further retries while build attempts keep failing.
+		b.retryDelay = min(2*b.retryDelay, b.config.maxRetryDelay)
+	}
 }
 
 // needToBuild returns true if there are outstanding transactions to be issued
@@ -111,6 +158,11 @@ func (b *blockBuilder) markBuilding() {
 	if b.buildSent {
 		return
 	}
+	// Defer the notification if the last build attempt was too recent.
+	if wait := b.config.minDelay - time.Since(b.lastBuild); wait > 0 {
+		b.buildBlockTimer.SetTimeoutIn(wait)
+		return
+	}
 	b.buildBlockTimer.Cancel() // Cancel any future attempt from the timer to send a PendingTxs message
 
 	select {
@@ -0,0 +1,56 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"testing"
+	"time"
+
+	commonEng "github.com/shubhamdubey02/cryftgo/snow/engine/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shubhamdubey02/coreth/utils"
+)
+
+func TestBlockBuilderRetryBackoff(t *testing.T) {
+	require := require.New(t)
+
+	notify := make(chan commonEng.Message, 1)
+	b := &blockBuilder{
+		ctx: utils.TestSnowContext(),
+		config: blockBuilderConfig{
+			minDelay:      time.Hour,
+			retryDelay:    time.Second,
+			maxRetryDelay: 4 * time.Second,
+		},
+		notifyBuildBlockChan: notify,
+	}
+	b.retryDelay = b.config.retryDelay
+	b.handleBlockBuilding()
+	defer b.buildBlockTimer.Stop()
+
+	// Failed builds double the retry delay up to the maximum.
+	for _, expected := range []time.Duration{2 * time.Second, 4 * time.Second, 4 * time.Second} {
+		b.handleGenerateBlock(false)
+		require.Equal(expected, b.retryDelay)
+	}
+	b.handleGenerateBlock(true)
+	require.Equal(time.Second, b.retryDelay)
+
+	// New transactions within the minimum delay of the last build do not
+	// notify the engine immediately.
+	b.signalTxsReady()
+	require.Empty(notify)
+
+	b.config.minDelay = 0
+	b.signalTxsReady()
+	require.Len(notify, 1)
+	require.True(b.buildSent)
+
+	// In idle mode, a failed build does not change the retry delay.
+	b.config.idle = true
+	b.handleGenerateBlock(false)
+	require.False(b.buildSent)
+	require.Equal(time.Second, b.retryDelay)
+}
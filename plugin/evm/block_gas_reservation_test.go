@@ -0,0 +1,56 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shubhamdubey02/coreth/core"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/params"
+)
+
+func TestBlockGasReservation(t *testing.T) {
+	require := require.New(t)
+
+	systemKey, err := crypto.GenerateKey()
+	require.NoError(err)
+	systemAddr := crypto.PubkeyToAddress(systemKey.PublicKey)
+	// Leave room for two transfers outside of the reservation.
+	reserved := params.CortinaGasLimit - 2*params.TxGas
+	h := NewTestHarness(t, TestHarnessConfig{
+		Config: fmt.Sprintf(`{"block-gas-reservation": {"gas": %d, "senders": ["%s"]}}`, reserved, systemAddr),
+		Genesis: &core.Genesis{
+			Config:     params.TestChainConfig,
+			Difficulty: big.NewInt(0),
+			Alloc:      core.GenesisAlloc{systemAddr: {Balance: big.NewInt(params.Ether)}},
+		},
+	})
+
+	// The system transaction pays the lowest price, so that it is considered
+	// after every other transaction.
+	for i := 0; i < 4; i++ {
+		tx := types.NewTransaction(uint64(i), common.Address{0xaa}, big.NewInt(1), params.TxGas, big.NewInt(2*params.LaunchMinGasPrice), nil)
+		h.IssueTx(h.SignTx(tx, HarnessFunderKey))
+	}
+	systemTx := types.NewTransaction(0, common.Address{0xbb}, big.NewInt(1), params.TxGas, big.NewInt(params.LaunchMinGasPrice), nil)
+	h.IssueTx(h.SignTx(systemTx, systemKey))
+
+	blk := h.BuildAndAccept()
+	txs := h.VM.blockChain.GetBlockByNumber(blk.Height()).Transactions()
+	require.Len(txs, 3)
+	senders := make(map[common.Address]int)
+	for _, tx := range txs {
+		sender, err := types.Sender(h.Signer, tx)
+		require.NoError(err)
+		senders[sender]++
+	}
+	require.Equal(map[common.Address]int{HarnessFunderAddress: 2, systemAddr: 1}, senders)
+}
@@ -0,0 +1,543 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/shubhamdubey02/coreth/core"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/internal/parquet"
+	"github.com/shubhamdubey02/coreth/params"
+)
+
+// chainExportConsumer is the name of the accepted block consumer whose
+// offset checkpoints the export.
+const chainExportConsumer = "chain-export"
+
+const (
+	exportBlocks       = "blocks"
+	exportTransactions = "transactions"
+	exportReceipts     = "receipts"
+	exportLogs         = "logs"
+	exportAtomicTxs    = "atomic_transactions"
+)
+
+// exportLevel is the entity each row of a table describes.
+type exportLevel int
+
+const (
+	blockLevel exportLevel = iota
+	txLevel
+	logLevel
+	atomicTxLevel
+)
+
+// exportRow holds the data a row is derived from. Fields below the level of
+// the table are unset.
+type exportRow struct {
+	block   *types.Block
+	index   int
+	tx      *types.Transaction
+	from    common.Address
+	receipt *types.Receipt
+	log     *types.Log
+
+	// atomicTx is the atomic tx of atomic tx rows, at [index] in the block,
+	// and atomicTxJSON its JSON representation.
+	atomicTx     *Tx
+	atomicTxJSON string
+}
+
+type exportColumn struct {
+	parquet.Column
+	value func(row *exportRow) any
+}
+
+type exportTableDef struct {
+	name  string
+	level exportLevel
+	// needsReceipts is set for tables whose rows are derived from receipts.
+	needsReceipts bool
+	columns       []exportColumn
+}
+
+func int64Column(name string, value func(row *exportRow) int64) exportColumn {
+	return exportColumn{
+		Column: parquet.Column{Name: name, Type: parquet.Int64},
+		value:  func(row *exportRow) any { return value(row) },
+	}
+}
+
+func stringColumn(name string, value func(row *exportRow) string) exportColumn {
+	return exportColumn{
+		Column: parquet.Column{Name: name, Type: parquet.String},
+		value:  func(row *exportRow) any { return value(row) },
+	}
+}
+
+// bigColumn exports an optional big integer as a decimal string, as its
+// values may not fit in an int64.
+func bigColumn(name string, value func(row *exportRow) *big.Int) exportColumn {
+	return exportColumn{
+		Column: parquet.Column{Name: name, Type: parquet.String, Optional: true},
+		value: func(row *exportRow) any {
+			if v := value(row); v != nil {
+				return v.String()
+			}
+			return nil
+		},
+	}
+}
+
+// optionalStringColumn exports a string that is absent if value returns
+// false.
+func optionalStringColumn(name string, value func(row *exportRow) (string, bool)) exportColumn {
+	return exportColumn{
+		Column: parquet.Column{Name: name, Type: parquet.String, Optional: true},
+		value: func(row *exportRow) any {
+			if v, ok := value(row); ok {
+				return v
+			}
+			return nil
+		},
+	}
+}
+
+func topicColumn(i int) exportColumn {
+	return optionalStringColumn(fmt.Sprintf("topic%d", i), func(row *exportRow) (string, bool) {
+		if i >= len(row.log.Topics) {
+			return "", false
+		}
+		return row.log.Topics[i].Hex(), true
+	})
+}
+
+var (
+	blockNumberColumn = int64Column("block_number", func(row *exportRow) int64 { return row.block.Number().Int64() })
+	txIndexColumn     = int64Column("transaction_index", func(row *exportRow) int64 { return int64(row.index) })
+	txHashColumn      = stringColumn("transaction_hash", func(row *exportRow) string { return row.tx.Hash().Hex() })
+)
+
+// exportTableDefs lists the tables that can be exported, with all of their
+// columns.
+var exportTableDefs = []*exportTableDef{
+	{
+		name:  exportBlocks,
+		level: blockLevel,
+		columns: []exportColumn{
+			int64Column("number", func(row *exportRow) int64 { return row.block.Number().Int64() }),
+			stringColumn("hash", func(row *exportRow) string { return row.block.Hash().Hex() }),
+			stringColumn("parent_hash", func(row *exportRow) string { return row.block.ParentHash().Hex() }),
+			int64Column("timestamp", func(row *exportRow) int64 { return int64(row.block.Time()) }),
+			stringColumn("miner", func(row *exportRow) string { return row.block.Coinbase().Hex() }),
+			stringColumn("state_root", func(row *exportRow) string { return row.block.Root().Hex() }),
+			int64Column("gas_limit", func(row *exportRow) int64 { return int64(row.block.GasLimit()) }),
+			int64Column("gas_used", func(row *exportRow) int64 { return int64(row.block.GasUsed()) }),
+			bigColumn("base_fee_per_gas", func(row *exportRow) *big.Int { return row.block.BaseFee() }),
+			int64Column("transaction_count", func(row *exportRow) int64 { return int64(len(row.block.Transactions())) }),
+			int64Column("size", func(row *exportRow) int64 { return int64(row.block.Size()) }),
+		},
+	},
+	{
+		name:  exportTransactions,
+		level: txLevel,
+		columns: []exportColumn{
+			blockNumberColumn,
+			txIndexColumn,
+			stringColumn("hash", func(row *exportRow) string { return row.tx.Hash().Hex() }),
+			int64Column("type", func(row *exportRow) int64 { return int64(row.tx.Type()) }),
+			stringColumn("from", func(row *exportRow) string { return row.from.Hex() }),
+			optionalStringColumn("to", func(row *exportRow) (string, bool) {
+				if to := row.tx.To(); to != nil {
+					return to.Hex(), true
+				}
+				return "", false
+			}),
+			int64Column("nonce", func(row *exportRow) int64 { return int64(row.tx.Nonce()) }),
+			bigColumn("value", func(row *exportRow) *big.Int { return row.tx.Value() }),
+			int64Column("gas", func(row *exportRow) int64 { return int64(row.tx.Gas()) }),
+			bigColumn("gas_price", func(row *exportRow) *big.Int { return row.tx.GasPrice() }),
+			bigColumn("max_fee_per_gas", func(row *exportRow) *big.Int { return row.tx.GasFeeCap() }),
+			bigColumn("max_priority_fee_per_gas", func(row *exportRow) *big.Int { return row.tx.GasTipCap() }),
+			stringColumn("input", func(row *exportRow) string { return hexutil.Encode(row.tx.Data()) }),
+		},
+	},
+	{
+		name:          exportReceipts,
+		level:         txLevel,
+		needsReceipts: true,
+		columns: []exportColumn{
+			blockNumberColumn,
+			txIndexColumn,
+			txHashColumn,
+			int64Column("status", func(row *exportRow) int64 { return int64(row.receipt.Status) }),
+			int64Column("gas_used", func(row *exportRow) int64 { return int64(row.receipt.GasUsed) }),
+			int64Column("cumulative_gas_used", func(row *exportRow) int64 { return int64(row.receipt.CumulativeGasUsed) }),
+			bigColumn("effective_gas_price", func(row *exportRow) *big.Int { return row.receipt.EffectiveGasPrice }),
+			optionalStringColumn("contract_address", func(row *exportRow) (string, bool) {
+				if row.receipt.ContractAddress == (common.Address{}) {
+					return "", false
+				}
+				return row.receipt.ContractAddress.Hex(), true
+			}),
+			int64Column("log_count", func(row *exportRow) int64 { return int64(len(row.receipt.Logs)) }),
+		},
+	},
+	{
+		name:          exportLogs,
+		level:         logLevel,
+		needsReceipts: true,
+		columns: []exportColumn{
+			blockNumberColumn,
+			txIndexColumn,
+			txHashColumn,
+			int64Column("log_index", func(row *exportRow) int64 { return int64(row.log.Index) }),
+			stringColumn("address", func(row *exportRow) string { return row.log.Address.Hex() }),
+			topicColumn(0),
+			topicColumn(1),
+			topicColumn(2),
+			topicColumn(3),
+			stringColumn("data", func(row *exportRow) string { return hexutil.Encode(row.log.Data) }),
+		},
+	},
+	{
+		name:  exportAtomicTxs,
+		level: atomicTxLevel,
+		columns: []exportColumn{
+			blockNumberColumn,
+			txIndexColumn,
+			stringColumn("id", func(row *exportRow) string { return row.atomicTx.ID().String() }),
+			stringColumn("json", func(row *exportRow) string { return row.atomicTxJSON }),
+		},
+	},
+}
+
+// chainExportTable is an exported table with its selected columns.
+type chainExportTable struct {
+	def     *exportTableDef
+	columns []exportColumn
+	rows    *parquet.Table
+}
+
+// newChainExportTables returns the tables selected by [selection], which maps
+// table names to column names as described by [ChainExportConfig].
+func newChainExportTables(selection map[string][]string) ([]*chainExportTable, error) {
+	known := make(map[string]*exportTableDef, len(exportTableDefs))
+	for _, def := range exportTableDefs {
+		known[def.name] = def
+	}
+	for name := range selection {
+		if _, ok := known[name]; !ok {
+			return nil, fmt.Errorf("unknown table %q", name)
+		}
+	}
+
+	var tables []*chainExportTable
+	for _, def := range exportTableDefs {
+		names, selected := selection[def.name]
+		if len(selection) != 0 && !selected {
+			continue
+		}
+		columns := def.columns
+		if len(names) != 0 {
+			byName := make(map[string]exportColumn, len(def.columns))
+			for _, column := range def.columns {
+				byName[column.Name] = column
+			}
+			columns = make([]exportColumn, 0, len(names))
+			for _, name := range names {
+				column, ok := byName[name]
+				if !ok {
+					return nil, fmt.Errorf("unknown column %q of table %q", name, def.name)
+				}
+				delete(byName, name)
+				columns = append(columns, column)
+			}
+		}
+		parquetColumns := make([]parquet.Column, len(columns))
+		for i, column := range columns {
+			parquetColumns[i] = column.Column
+		}
+		tables = append(tables, &chainExportTable{
+			def:     def,
+			columns: columns,
+			rows:    parquet.NewTable(parquetColumns),
+		})
+	}
+	return tables, nil
+}
+
+func (t *chainExportTable) append(row *exportRow) error {
+	values := make([]any, len(t.columns))
+	for i, column := range t.columns {
+		values[i] = column.value(row)
+	}
+	return t.rows.Append(values...)
+}
+
+// chainExporter writes accepted blocks to Parquet files. Blocks are buffered
+// until [blocksPerFile] blocks are buffered, the UTC date of the blocks
+// changes or no block is accepted for [flushInterval], and are then written
+// to one file per table:
+//
+//	<dir>/<table>/date=<YYYY-MM-DD>/<first block number>.parquet
+//
+// The export is checkpointed by acknowledging the last written block to the
+// accepted block consumer, so that it resumes after the last written file on
+// restart. Files written but not checkpointed before a crash are rewritten.
+type chainExporter struct {
+	dir           string
+	blocksPerFile uint64
+	flushInterval time.Duration
+	chainConfig   *params.ChainConfig
+	chain         *core.BlockChain
+	consumer      *core.AcceptedConsumer
+	tables        []*chainExportTable
+	needsReceipts bool
+	// needsAtomicTxs is set if a table of atomic txs is exported.
+	needsAtomicTxs bool
+
+	// first and last are the numbers of the first and last buffered blocks,
+	// and date their UTC date.
+	first, last uint64
+	date        string
+	buffered    uint64
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newChainExporter(config ChainExportConfig, chain *core.BlockChain) (*chainExporter, error) {
+	tables, err := newChainExportTables(config.Tables)
+	if err != nil {
+		return nil, err
+	}
+	consumer, err := chain.NewAcceptedConsumer(chainExportConsumer)
+	if err != nil {
+		return nil, err
+	}
+	e := &chainExporter{
+		dir:           config.Dir,
+		blocksPerFile: config.BlocksPerFile,
+		flushInterval: config.FlushInterval.Duration,
+		chainConfig:   chain.Config(),
+		chain:         chain,
+		consumer:      consumer,
+		tables:        tables,
+		done:          make(chan struct{}),
+	}
+	for _, table := range tables {
+		e.needsReceipts = e.needsReceipts || table.def.needsReceipts
+		e.needsAtomicTxs = e.needsAtomicTxs || table.def.level == atomicTxLevel
+	}
+	return e, nil
+}
+
+// start exports accepted blocks in the background until [close] is called.
+func (e *chainExporter) start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+	go func() {
+		defer close(e.done)
+		if err := e.run(ctx); err != nil {
+			log.Error("Chain export stopped", "err", err)
+		}
+	}()
+}
+
+// close stops the export, writing the buffered blocks.
+func (e *chainExporter) close() {
+	e.cancel()
+	<-e.done
+	e.consumer.Close()
+}
+
+func (e *chainExporter) run(ctx context.Context) error {
+	log.Info("Exporting accepted chain", "dir", e.dir, "from", e.consumer.Offset()+1)
+	for {
+		nextCtx, cancel := context.WithTimeout(ctx, e.flushInterval)
+		block, err := e.consumer.Next(nextCtx)
+		cancel()
+		switch {
+		case err == nil:
+		case errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil:
+			if err := e.flush(); err != nil {
+				return err
+			}
+			continue
+		case ctx.Err() != nil:
+			return e.flush()
+		default:
+			return err
+		}
+
+		date := time.Unix(int64(block.Time()), 0).UTC().Format(time.DateOnly)
+		if e.buffered > 0 && date != e.date {
+			if err := e.flush(); err != nil {
+				return err
+			}
+		}
+		if err := e.append(block); err != nil {
+			return err
+		}
+		if e.buffered == 0 {
+			e.first = block.NumberU64()
+			e.date = date
+		}
+		e.last = block.NumberU64()
+		e.buffered++
+		if e.buffered >= e.blocksPerFile {
+			if err := e.flush(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// append adds the rows derived from [block] to each table.
+func (e *chainExporter) append(block *types.Block) error {
+	txs := block.Transactions()
+	var receipts types.Receipts
+	if e.needsReceipts && len(txs) > 0 {
+		receipts = e.chain.GetReceiptsByHash(block.Hash())
+		if len(receipts) != len(txs) {
+			return fmt.Errorf("receipts of block %d (%s) unavailable", block.NumberU64(), block.Hash())
+		}
+	}
+	signer := types.MakeSigner(e.chainConfig, block.Number(), block.Time())
+
+	rows := make([]exportRow, len(txs))
+	for i, tx := range txs {
+		from, err := types.Sender(signer, tx)
+		if err != nil {
+			return fmt.Errorf("failed to recover sender of tx %s: %w", tx.Hash(), err)
+		}
+		rows[i] = exportRow{block: block, index: i, tx: tx, from: from}
+		if receipts != nil {
+			rows[i].receipt = receipts[i]
+		}
+	}
+
+	var atomicRows []exportRow
+	if e.needsAtomicTxs && len(block.ExtData()) > 0 {
+		rules := e.chainConfig.Rules(block.Number(), block.Time())
+		atomicTxs, err := ExtractAtomicTxs(block.ExtData(), rules.IsApricotPhase5, Codec)
+		if err != nil {
+			return fmt.Errorf("failed to extract atomic txs of block %d (%s): %w", block.NumberU64(), block.Hash(), err)
+		}
+		atomicRows = make([]exportRow, len(atomicTxs))
+		for i, tx := range atomicTxs {
+			txJSON, err := tx.MarshalJSON()
+			if err != nil {
+				return fmt.Errorf("failed to marshal atomic tx %s: %w", tx.ID(), err)
+			}
+			atomicRows[i] = exportRow{block: block, index: i, atomicTx: tx, atomicTxJSON: string(txJSON)}
+		}
+	}
+
+	for _, table := range e.tables {
+		switch table.def.level {
+		case blockLevel:
+			if err := table.append(&exportRow{block: block}); err != nil {
+				return err
+			}
+		case txLevel:
+			for i := range rows {
+				if err := table.append(&rows[i]); err != nil {
+					return err
+				}
+			}
+		case logLevel:
+			for i := range rows {
+				for _, l := range rows[i].receipt.Logs {
+					row := rows[i]
+					row.log = l
+					if err := table.append(&row); err != nil {
+						return err
+					}
+				}
+			}
+		case atomicTxLevel:
+			for i := range atomicRows {
+				if err := table.append(&atomicRows[i]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// flush writes the buffered blocks and checkpoints the export.
+func (e *chainExporter) flush() error {
+	if e.buffered == 0 {
+		return nil
+	}
+	for _, table := range e.tables {
+		if table.rows.Len() == 0 {
+			continue
+		}
+		if err := e.write(table); err != nil {
+			return fmt.Errorf("failed to export %s of blocks %d-%d: %w", table.def.name, e.first, e.last, err)
+		}
+		table.rows.Reset()
+	}
+	if err := e.consumer.Ack(e.last); err != nil {
+		return fmt.Errorf("failed to checkpoint chain export: %w", err)
+	}
+	log.Debug("Exported accepted blocks", "first", e.first, "last", e.last)
+	e.buffered = 0
+	return nil
+}
+
+// write writes the rows of [table] to its file, replacing the file left by an
+// export interrupted before its checkpoint.
+func (e *chainExporter) write(table *chainExportTable) error {
+	dir := filepath.Join(e.dir, table.def.name, "date="+e.date)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%012d.parquet", e.first))
+	tmpPath := path + ".tmp"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	if _, err := table.rows.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// startChainExport starts the export of the accepted chain, if configured.
+func (vm *VM) startChainExport() error {
+	if vm.config.ChainExport.Dir == "" {
+		return nil
+	}
+	exporter, err := newChainExporter(vm.config.ChainExport, vm.blockChain)
+	if err != nil {
+		return err
+	}
+	vm.chainExporter = exporter
+	exporter.start()
+	return nil
+}
@@ -0,0 +1,101 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shubhamdubey02/coreth/core/rawdb"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/params"
+)
+
+func TestChainExport(t *testing.T) {
+	require := require.New(t)
+
+	dir := t.TempDir()
+	h := NewTestHarness(t, TestHarnessConfig{
+		Config: fmt.Sprintf(`{"chain-export": {"dir": %q, "blocks-per-file": 2, "flush-interval": "1h"}}`, dir),
+	})
+
+	// Blocks 2 and 3 are accepted on the day after block 1, so block 1 is
+	// written alone and blocks 2 and 3 together.
+	h.IssueTx(h.SignTx(types.NewTransaction(0, common.Address{0xaa}, big.NewInt(1), params.TxGas, big.NewInt(params.LaunchMinGasPrice), nil), HarnessFunderKey))
+	h.BuildAndAccept()
+	h.AdvanceTime(24 * time.Hour)
+	// The init code of the contract emits a log: PUSH1 0 PUSH1 0 LOG0 STOP.
+	h.IssueTx(h.SignTx(types.NewContractCreation(1, big.NewInt(0), 100_000, big.NewInt(params.LaunchMinGasPrice), common.FromHex("0x60006000a000")), HarnessFunderKey))
+	h.BuildAndAccept()
+	h.AdvanceTime(10 * time.Second)
+	h.IssueTx(h.SignTx(types.NewTransaction(2, common.Address{0xaa}, big.NewInt(1), params.TxGas, big.NewInt(params.LaunchMinGasPrice), nil), HarnessFunderKey))
+	h.BuildAndAccept()
+
+	require.Eventually(func() bool {
+		offset, err := rawdb.ReadAcceptedConsumerOffset(h.VM.chaindb, chainExportConsumer)
+		return err == nil && offset != nil && *offset == 3
+	}, 10*time.Second, 10*time.Millisecond)
+
+	day := func(offset time.Duration) string {
+		return "date=" + time.Unix(int64(h.VM.blockChain.GetBlockByNumber(1).Time()), 0).Add(offset).UTC().Format(time.DateOnly)
+	}
+	expected := []string{
+		filepath.Join(exportBlocks, day(0), "000000000001.parquet"),
+		filepath.Join(exportTransactions, day(0), "000000000001.parquet"),
+		filepath.Join(exportReceipts, day(0), "000000000001.parquet"),
+		filepath.Join(exportBlocks, day(24*time.Hour), "000000000002.parquet"),
+		filepath.Join(exportTransactions, day(24*time.Hour), "000000000002.parquet"),
+		filepath.Join(exportReceipts, day(24*time.Hour), "000000000002.parquet"),
+		filepath.Join(exportLogs, day(24*time.Hour), "000000000002.parquet"),
+	}
+	var files []string
+	require.NoError(filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		files = append(files, rel)
+		return err
+	}))
+	require.ElementsMatch(expected, files)
+
+	for _, file := range files {
+		contents, err := os.ReadFile(filepath.Join(dir, file))
+		require.NoError(err)
+		require.True(bytes.HasPrefix(contents, []byte("PAR1")))
+		require.True(bytes.HasSuffix(contents, []byte("PAR1")))
+	}
+}
+
+func TestNewChainExportTables(t *testing.T) {
+	require := require.New(t)
+
+	tables, err := newChainExportTables(nil)
+	require.NoError(err)
+	require.Len(tables, len(exportTableDefs))
+
+	tables, err = newChainExportTables(map[string][]string{
+		exportBlocks: {"number", "hash"},
+		exportLogs:   nil,
+	})
+	require.NoError(err)
+	require.Len(tables, 2)
+	require.Equal(exportBlocks, tables[0].def.name)
+	require.Len(tables[0].columns, 2)
+	require.Equal(exportLogs, tables[1].def.name)
+	require.Len(tables[1].columns, len(tables[1].def.columns))
+
+	_, err = newChainExportTables(map[string][]string{"traces": nil})
+	require.ErrorContains(err, "unknown table")
+	_, err = newChainExportTables(map[string][]string{exportBlocks: {"number", "number"}})
+	require.ErrorContains(err, "unknown column")
+}
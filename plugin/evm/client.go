@@ -38,6 +38,7 @@ type Client interface {
 	MemoryProfile(ctx context.Context, options ...rpc.Option) error
 	LockProfile(ctx context.Context, options ...rpc.Option) error
 	SetLogLevel(ctx context.Context, level slog.Level, options ...rpc.Option) error
+	SetModuleLogLevel(ctx context.Context, module string, level slog.Level, options ...rpc.Option) error
 	GetVMConfig(ctx context.Context, options ...rpc.Option) (*Config, error)
 }
 
@@ -228,6 +229,14 @@ func (c *client) SetLogLevel(ctx context.Context, level slog.Level, options ...r
 	}, &api.EmptyReply{}, options...)
 }
 
+// SetModuleLogLevel dynamically sets the log level of [module] for the C Chain
+func (c *client) SetModuleLogLevel(ctx context.Context, module string, level slog.Level, options ...rpc.Option) error {
+	return c.adminRequester.SendRequest(ctx, "admin.setLogLevel", &SetLogLevelArgs{
+		Level:  level.String(),
+		Module: module,
+	}, &api.EmptyReply{}, options...)
+}
+
 // GetVMConfig returns the current config of the VM
 func (c *client) GetVMConfig(ctx context.Context, options ...rpc.Option) (*Config, error) {
 	res := &ConfigReply{}
@@ -0,0 +1,51 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"time"
+
+	"github.com/shubhamdubey02/coreth/core/rawdb"
+)
+
+const compactionInterval = time.Minute
+
+// startCompaction compacts the database on the configured schedule in the
+// background until the VM shuts down.
+func (vm *VM) startCompaction() error {
+	config := vm.config.Compaction
+	if !config.Enabled {
+		return nil
+	}
+	windows := make([]rawdb.QuietWindow, len(config.QuietHours))
+	for i, window := range config.QuietHours {
+		w, err := rawdb.ParseQuietWindow(window)
+		if err != nil {
+			return err
+		}
+		windows[i] = w
+	}
+	scheduler, err := rawdb.NewCompactionScheduler(vm.chaindb, windows, config.Ranges, config.DeletionThreshold)
+	if err != nil {
+		return err
+	}
+	vm.compaction = scheduler
+
+	vm.shutdownWg.Add(1)
+	go vm.ctx.Log.RecoverAndPanic(func() {
+		defer vm.shutdownWg.Done()
+
+		ticker := time.NewTicker(compactionInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				scheduler.Run(vm.shutdownChan)
+			case <-vm.shutdownChan:
+				return
+			}
+		}
+	})
+	return nil
+}
@@ -6,12 +6,20 @@ package evm
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/shubhamdubey02/coreth/core"
+	"github.com/shubhamdubey02/coreth/core/rawdb"
 	"github.com/shubhamdubey02/coreth/core/txpool/legacypool"
 	"github.com/shubhamdubey02/coreth/eth"
+	"github.com/shubhamdubey02/coreth/log"
+	"github.com/shubhamdubey02/coreth/miner"
+	"github.com/shubhamdubey02/coreth/peer"
+	"github.com/shubhamdubey02/coreth/rpc"
+	statesyncclient "github.com/shubhamdubey02/coreth/sync/client"
 	"github.com/spf13/cast"
 )
 
@@ -23,6 +31,7 @@ const (
 	defaultTrieDirtyCache                             = 512
 	defaultTrieDirtyCommitTarget                      = 20
 	defaultTriePrefetcherParallelism                  = 16
+	defaultTriePrefetcherMaxParallelism               = 64
 	defaultSnapshotCache                              = 256
 	defaultSyncableCommitInterval                     = defaultCommitInterval * 4
 	defaultSnapshotWait                               = false
@@ -32,6 +41,9 @@ const (
 	defaultApiMaxDuration                             = 0 // Default to no maximum API call duration
 	defaultWsCpuRefillRate                            = 0 // Default to no maximum WS CPU usage
 	defaultWsCpuMaxStored                             = 0 // Default to no maximum WS CPU usage
+	defaultWsMaxSubscriptions                         = 0 // Default to no maximum number of subscriptions per connection
+	defaultWsNotificationBuffer                       = 0 // Default to writing notifications directly to the connection
+	defaultWsSlowConsumerPolicy                       = rpc.DropNotifications
 	defaultMaxBlocksPerRequest                        = 0 // Default to no maximum on the number of blocks per getLogs request
 	defaultContinuousProfilerFrequency                = 15 * time.Minute
 	defaultContinuousProfilerMaxFiles                 = 5
@@ -43,14 +55,59 @@ const (
 	defaultPushGossipFrequency                        = 100 * time.Millisecond
 	defaultPullGossipFrequency                        = 1 * time.Second
 	defaultTxRegossipFrequency                        = 30 * time.Second
-	defaultOfflinePruningBloomFilterSize       uint64 = 512 // Default size (MB) for the offline pruner to use
+	defaultAtomicPushGossipPercentStake               = .9
+	defaultAtomicPushGossipNumValidators              = 100
+	defaultAtomicPushGossipNumPeers                   = 0
+	defaultAtomicPushRegossipNumValidators            = 10
+	defaultAtomicPushRegossipNumPeers                 = 0
+	defaultAtomicPushGossipFrequency                  = 100 * time.Millisecond
+	defaultAtomicPullGossipFrequency                  = 1 * time.Second
+	defaultAtomicRegossipFrequency                    = 30 * time.Second
+	defaultBuildBlockMinDelay                         = 0 // Default to building as soon as new transactions arrive
+	defaultBuildBlockRetryDelay                       = minBlockBuildingRetryDelay
+	defaultBuildBlockMaxRetryDelay                    = minBlockBuildingRetryDelay // Default to no backoff
+	defaultOfflinePruningBloomFilterSize       uint64 = 512                        // Default size (MB) for the offline pruner to use
 	defaultLogLevel                                   = "info"
 	defaultLogJSONFormat                              = false
+	defaultLogMaxSize                                 = 100 // 100 MB
 	defaultMaxOutboundActiveRequests                  = 16
 	defaultMaxOutboundActiveCrossChainRequests        = 64
+	defaultGossipDedupCacheSize                       = 8192
 	defaultPopulateMissingTriesParallelism            = 1024
-	defaultStateSyncServerTrieCache                   = 64 // MB
-	defaultAcceptedCacheSize                          = 32 // blocks
+	defaultStateSyncServerTrieCache                   = 64  // MB
+	defaultAcceptedCacheSize                          = 32  // blocks
+	defaultHistoricalStateCache                       = 256 // MB
+	defaultArchiveProxyTimeout                        = 10 * time.Second
+	defaultChainExportBlocksPerFile                   = 1000
+	defaultChainExportFlushInterval                   = time.Minute
+	defaultWatchdogBuildBlockThreshold                = 30 * time.Second
+	defaultWatchdogAcceptBlockThreshold               = 30 * time.Second
+	defaultWatchdogGossipThreshold                    = time.Minute
+	defaultArbiterRPCSlots                            = 16
+	defaultArbiterRPCSlotsDuringConsensus             = 2
+	defaultMemoryBudgetCheckInterval                  = 10 * time.Second
+	defaultCacheTunerLagThreshold                     = 30 * time.Second
+	defaultCacheTunerWindow                           = time.Minute
+	defaultCacheTunerWindows                          = 5
+	defaultStateSyncAvailabilitySamples               = 10
+	defaultStateSyncAvailabilityMinPeers              = 3
+	defaultStateSyncAvailabilityTimeout               = 30 * time.Second
+	defaultStateSyncAvailabilityParallelism           = 4
+	defaultPeerHealthProbeInterval                    = 30 * time.Second
+	defaultPeerHealthProbeIdleTimeout                 = 2 * time.Minute
+	defaultPeerHealthProbeTimeout                     = 5 * time.Second
+	defaultPeerHealthProbeMaxFailures                 = 3
+	defaultCompactionDeletionThreshold                = 100_000
+	defaultBenchFormat                                = benchFormatCSV
+	defaultPrivateTxsTTL                              = 10 * time.Minute
+	defaultTxLifecycleCapacity                        = 10_000
+	defaultUserOpsMaxOps                              = 4096
+	defaultUserOpsMaxBundleOps                        = 16
+	defaultBackupInterval                             = 6 * time.Hour
+	defaultWarpValidatorSnapshotsCacheSize            = 64
+	defaultWarpValidatorSnapshotsRetention            = 100_000
+	defaultFaucetAmount                               = 1_000_000_000 // 1 CRYFT in gwei
+	defaultFaucetInterval                             = 24 * time.Hour
 
 	// defaultStateSyncMinBlocks is the minimum number of blocks the blockchain
 	// should be ahead of local last accepted to perform state sync.
@@ -72,6 +129,15 @@ var (
 		"internal-eth",
 		"internal-blockchain",
 		"internal-transaction",
+		"internal-avax",
+	}
+	defaultCompactionRanges = []string{
+		retentionBodies,
+		retentionReceipts,
+		retentionTxIndex,
+		retentionLogsIndex,
+		retentionBlobSidecars,
+		retentionTraces,
 	}
 	defaultAllowUnprotectedTxHashes = []common.Hash{
 		common.HexToHash("0xfefb2da535e927b85fe68eb81cb2e4a5827c905f78381a01ef2322aa9b0aee8e"), // EIP-1820: https://eips.ethereum.org/EIPS/eip-1820
@@ -92,6 +158,15 @@ type Config struct {
 	CorethAdminAPIDir     string `json:"coreth-admin-api-dir"`     // Deprecated: use AdminAPIDir instead
 	WarpAPIEnabled        bool   `json:"warp-api-enabled"`
 
+	// AdminGRPCAddress, if set, is the address the admin gRPC service listens
+	// on. Calls must carry the bearer token read from AdminGRPCTokenFile.
+	// Unless a TLS certificate and key are configured, the address must be a
+	// loopback address, as the token would otherwise be sent in the clear.
+	AdminGRPCAddress     string `json:"admin-grpc-address"`
+	AdminGRPCTokenFile   string `json:"admin-grpc-token-file"`
+	AdminGRPCTLSCertFile string `json:"admin-grpc-tls-cert-file"`
+	AdminGRPCTLSKeyFile  string `json:"admin-grpc-tls-key-file"`
+
 	// EnabledEthAPIs is a list of Ethereum services that should be enabled
 	// If none is specified, then we use the default list [defaultEnabledAPIs]
 	EnabledEthAPIs []string `json:"eth-apis"`
@@ -105,12 +180,26 @@ type Config struct {
 	RPCGasCap   uint64  `json:"rpc-gas-cap"`
 	RPCTxFeeCap float64 `json:"rpc-tx-fee-cap"`
 
+	// EstimateGasRevertTrace attaches a summary of the failed call frames to
+	// the errors of eth_estimateGas for reverting transactions, decoding
+	// custom errors declared in the JSON ABI at RevertErrorABIFile if set.
+	EstimateGasRevertTrace bool   `json:"estimate-gas-revert-trace"`
+	RevertErrorABIFile     string `json:"revert-error-abi-file"`
+
+	// RPCAcceptanceMetadata records when each block is accepted, and attaches
+	// its acceptance time, the last accepted height and the P-Chain height of
+	// its proposervm block to block and receipt responses.
+	RPCAcceptanceMetadata bool `json:"rpc-acceptance-metadata"`
+
 	// Cache settings
-	TrieCleanCache            int `json:"trie-clean-cache"`            // Size of the trie clean cache (MB)
-	TrieDirtyCache            int `json:"trie-dirty-cache"`            // Size of the trie dirty cache (MB)
-	TrieDirtyCommitTarget     int `json:"trie-dirty-commit-target"`    // Memory limit to target in the dirty cache before performing a commit (MB)
-	TriePrefetcherParallelism int `json:"trie-prefetcher-parallelism"` // Max concurrent disk reads trie prefetcher should perform at once
-	SnapshotCache             int `json:"snapshot-cache"`              // Size of the snapshot disk layer clean cache (MB)
+	TrieCleanCache                int  `json:"trie-clean-cache"`                  // Size of the trie clean cache (MB)
+	TrieDirtyCache                int  `json:"trie-dirty-cache"`                  // Size of the trie dirty cache (MB)
+	TrieDirtyCommitTarget         int  `json:"trie-dirty-commit-target"`          // Memory limit to target in the dirty cache before performing a commit (MB)
+	TriePrefetcherParallelism     int  `json:"trie-prefetcher-parallelism"`       // Max concurrent disk reads trie prefetcher should perform at once
+	TriePrefetcherMaxPendingTasks int  `json:"trie-prefetcher-max-pending-tasks"` // Max keys the trie prefetcher queues per trie (0 = unbounded)
+	TriePrefetcherAdaptive        bool `json:"trie-prefetcher-adaptive"`          // Scale trie prefetcher parallelism based on measured read latency
+	TriePrefetcherMaxParallelism  int  `json:"trie-prefetcher-max-parallelism"`   // Upper bound on trie prefetcher parallelism in adaptive mode
+	SnapshotCache                 int  `json:"snapshot-cache"`                    // Size of the snapshot disk layer clean cache (MB)
 
 	// Eth Settings
 	Preimages      bool `json:"preimages-enabled"`
@@ -120,12 +209,27 @@ type Config struct {
 	// Pruning Settings
 	Pruning                         bool    `json:"pruning-enabled"`                    // If enabled, trie roots are only persisted every 4096 blocks
 	AcceptorQueueLimit              int     `json:"accepted-queue-limit"`               // Maximum blocks to queue before blocking during acceptance
-	CommitInterval                  uint64  `json:"commit-interval"`                    // Specifies the commit interval at which to persist EVM and atomic tries.
+	CommitInterval                  uint64  `json:"commit-interval"`                    // Blocks between trie commits; blocks since the last commit are journaled and replayed after a crash
 	AllowMissingTries               bool    `json:"allow-missing-tries"`                // If enabled, warnings preventing an incomplete trie index are suppressed
 	PopulateMissingTries            *uint64 `json:"populate-missing-tries,omitempty"`   // Sets the starting point for re-populating missing tries. Disables re-generation if nil.
 	PopulateMissingTriesParallelism int     `json:"populate-missing-tries-parallelism"` // Number of concurrent readers to use when re-populating missing tries on startup.
 	PruneWarpDB                     bool    `json:"prune-warp-db-enabled"`              // Determines if the warpDB should be cleared on startup
 
+	// HistoricalStateMaxReexec is the maximum number of blocks re-executed to
+	// regenerate pruned state, so that calls and traces can be served at older
+	// heights. Regenerated states are cached on disk up to HistoricalStateCache MB,
+	// evicting the least recently used ones. Zero disables regeneration.
+	HistoricalStateMaxReexec uint64 `json:"historical-state-max-reexec"`
+	HistoricalStateCache     int    `json:"historical-state-cache"`
+
+	// ArchiveProxyURL is the endpoint of an archive node to which calls
+	// needing pruned state or block data are forwarded, each bounded by
+	// ArchiveProxyTimeout. Results are verified against the local headers,
+	// so calls executing on pruned state, such as eth_call, are not
+	// forwarded. Empty disables the proxy.
+	ArchiveProxyURL     string   `json:"archive-proxy-url"`
+	ArchiveProxyTimeout Duration `json:"archive-proxy-timeout"`
+
 	// Metric Settings
 	MetricsExpensiveEnabled bool `json:"metrics-expensive-enabled"` // Debug-level metrics that might impact runtime performance
 
@@ -140,14 +244,32 @@ type Config struct {
 	TxPoolGlobalQueue  uint64   `json:"tx-pool-global-queue"`
 	TxPoolLifetime     Duration `json:"tx-pool-lifetime"`
 
+	// TxPoolSponsoredSlots is the maximum number of transactions admitted
+	// whose gas is paid by the gas sponsor contract of the chain config.
+	TxPoolSponsoredSlots uint64 `json:"tx-pool-sponsored-slots"`
+
 	APIMaxDuration           Duration      `json:"api-max-duration"`
 	WSCPURefillRate          Duration      `json:"ws-cpu-refill-rate"`
 	WSCPUMaxStored           Duration      `json:"ws-cpu-max-stored"`
+	WSMaxSubscriptions       int           `json:"ws-max-subscriptions"`    // Maximum number of subscriptions per websocket connection (0 = unlimited)
+	WSNotificationBuffer     int           `json:"ws-notification-buffer"`  // Notifications buffered per subscription for slow consumers (0 = unbuffered)
+	WSSlowConsumerPolicy     string        `json:"ws-slow-consumer-policy"` // Either "drop" or "disconnect" once a subscription's notification buffer is full
 	MaxBlocksPerRequest      int64         `json:"api-max-blocks-per-request"`
 	AllowUnfinalizedQueries  bool          `json:"allow-unfinalized-queries"`
 	AllowUnprotectedTxs      bool          `json:"allow-unprotected-txs"`
 	AllowUnprotectedTxHashes []common.Hash `json:"allow-unprotected-tx-hashes"`
 
+	// DurableFilterRetention enables eth_newDurableFilter, whose named log
+	// filters persist their cursor so that clients can reconnect without
+	// missing accepted logs. A durable filter not polled within the retention
+	// is removed. Zero disables durable filters.
+	DurableFilterRetention Duration `json:"durable-filter-retention"`
+
+	// APIAccessPolicy restricts, per namespace, the IP addresses, browser
+	// origins and virtual hosts allowed to call the eth RPC and websocket
+	// endpoints. The "*" entry applies to namespaces without their own entry.
+	APIAccessPolicy rpc.AccessPolicy `json:"api-access-policy"`
+
 	// Keystore Settings
 	KeystoreDirectory             string `json:"keystore-directory"` // both absolute and relative supported
 	KeystoreExternalSigner        string `json:"keystore-external-signer"`
@@ -164,9 +286,31 @@ type Config struct {
 	RegossipFrequency         Duration `json:"regossip-frequency"`
 	TxRegossipFrequency       Duration `json:"tx-regossip-frequency"` // Deprecated: use RegossipFrequency instead
 
+	// Atomic Tx Gossip Settings
+	AtomicPushGossipPercentStake    float64  `json:"atomic-push-gossip-percent-stake"`
+	AtomicPushGossipNumValidators   int      `json:"atomic-push-gossip-num-validators"`
+	AtomicPushGossipNumPeers        int      `json:"atomic-push-gossip-num-peers"`
+	AtomicPushRegossipNumValidators int      `json:"atomic-push-regossip-num-validators"`
+	AtomicPushRegossipNumPeers      int      `json:"atomic-push-regossip-num-peers"`
+	AtomicPushGossipFrequency       Duration `json:"atomic-push-gossip-frequency"`
+	AtomicPullGossipFrequency       Duration `json:"atomic-pull-gossip-frequency"`
+	AtomicRegossipFrequency         Duration `json:"atomic-regossip-frequency"`
+
+	// Block Building Settings
+	BuildBlockMinDelay      Duration `json:"build-block-min-delay"`       // Minimum time between build attempts triggered by new transactions
+	BuildBlockRetryDelay    Duration `json:"build-block-retry-delay"`     // Time to wait before retrying a build while transactions remain pending
+	BuildBlockMaxRetryDelay Duration `json:"build-block-max-retry-delay"` // Upper bound on the retry delay, which doubles after every failed build
+	BuildBlockIdleEnabled   bool     `json:"build-block-idle-enabled"`    // Stop retrying failed builds until a new transaction is received
+
 	// Log
-	LogLevel      string `json:"log-level"`
-	LogJSONFormat bool   `json:"log-json-format"`
+	LogLevel        string            `json:"log-level"`
+	LogJSONFormat   bool              `json:"log-json-format"`
+	LogModuleLevels map[string]string `json:"log-module-levels"` // Overrides [LogLevel] for modules such as miner, sync, peer, txpool and rpc
+	LogFile         string            `json:"log-file"`          // If set, logs are also written to this file
+	LogMaxSize      int               `json:"log-max-size"`      // Size in MB at which [LogFile] is rotated
+	LogMaxBackups   int               `json:"log-max-backups"`   // Number of rotated log files to retain, 0 retains all
+	LogMaxAge       int               `json:"log-max-age"`       // Days to retain rotated log files, 0 retains them indefinitely
+	LogCompress     bool              `json:"log-compress"`      // Compress rotated log files with gzip
 
 	// Offline Pruning Settings
 	OfflinePruning                bool   `json:"offline-pruning-enabled"`
@@ -176,6 +320,13 @@ type Config struct {
 	// VM2VM network
 	MaxOutboundActiveRequests           int64 `json:"max-outbound-active-requests"`
 	MaxOutboundActiveCrossChainRequests int64 `json:"max-outbound-active-cross-chain-requests"`
+	// GossipDedupCacheSize is the number of most recent distinct gossip
+	// payloads remembered to drop identical payloads forwarded by other
+	// peers before decoding them. Deduplication is disabled if 0.
+	GossipDedupCacheSize int `json:"gossip-dedup-cache-size"`
+	// PeerHealthProbes probes idle peers, so that peers which stopped
+	// answering are not picked for requests.
+	PeerHealthProbes PeerHealthProbeConfig `json:"peer-health-probes"`
 
 	// Sync settings
 	StateSyncEnabled         *bool  `json:"state-sync-enabled"`     // Pointer distinguishes false (no state sync) and not set (state sync only at genesis).
@@ -185,10 +336,49 @@ type Config struct {
 	StateSyncCommitInterval  uint64 `json:"state-sync-commit-interval"`
 	StateSyncMinBlocks       uint64 `json:"state-sync-min-blocks"`
 	StateSyncRequestSize     uint16 `json:"state-sync-request-size"`
+	// StateSyncMemoryBudget is the maximum number of megabytes of leafs, trie
+	// nodes and code the state syncer buffers in memory. Requests to peers are
+	// held back while the budget is exhausted. 0 means no limit.
+	StateSyncMemoryBudget uint64 `json:"state-sync-memory-budget"`
+
+	// StateSyncCheckpoints are trusted checkpoints that a proposed state summary
+	// must match if a checkpoint exists at the summary height. State sync fails
+	// if the majority of summaries advertised by peers at checkpointed heights
+	// conflict.
+	StateSyncCheckpoints []statesyncclient.Checkpoint `json:"state-sync-checkpoints"`
+	// StateSyncCheckpointFeed is the path to a JSON list of signed checkpoints,
+	// each of which must be signed by one of [StateSyncCheckpointSigners].
+	StateSyncCheckpointFeed    string           `json:"state-sync-checkpoint-feed"`
+	StateSyncCheckpointSigners []common.Address `json:"state-sync-checkpoint-signers"`
+	// StateSyncTrustedNodeIDs is a comma separated list of nodes, the majority
+	// of which must serve the block of a state summary before syncing to it.
+	StateSyncTrustedNodeIDs string `json:"state-sync-trusted-node-ids"`
+	// StateSyncRecordFile, if set, is the path the responses of peers to the
+	// requests of state sync are written to once the state trie is synced, as
+	// fixtures replayed by the sync/syncsim package.
+	StateSyncRecordFile string `json:"state-sync-record-file"`
+	// StateSyncAvailability evaluates the summaries advertised by peers
+	// before state syncing to one of them.
+	StateSyncAvailability StateSyncAvailabilityConfig `json:"state-sync-availability"`
 
 	// Database Settings
 	InspectDatabase bool `json:"inspect-database"` // Inspects the database on startup if enabled.
 
+	// DatabaseEncryptionKeyFile and DatabaseEncryptionKeyCommand configure
+	// encryption at rest of the values in the VM database with AES-256-GCM.
+	// The hex encoded 32 byte key is read from the file, or from the output
+	// of the command (run with sh -c), such as a KMS client decrypting a data
+	// key. At most one may be set. Encryption can only be enabled on an empty
+	// database, and an encrypted database cannot be opened without its key.
+	DatabaseEncryptionKeyFile    string `json:"database-encryption-key-file"`
+	DatabaseEncryptionKeyCommand string `json:"database-encryption-key-command"`
+
+	// AccountActivityIndexEnabled maintains an index of per-address activity
+	// (first/last active block, transaction counts, nonce history and
+	// contracts created) for blocks accepted while enabled, indexed in the
+	// background. Served by the "activity" API.
+	AccountActivityIndexEnabled bool `json:"account-activity-index-enabled"`
+
 	// SkipUpgradeCheck disables checking that upgrades must take place before the last
 	// accepted block. Skipping this check is useful when a node operator does not update
 	// their node before the network upgrade and their node accepts blocks that have
@@ -215,6 +405,108 @@ type Config struct {
 	// TxLookupLimit can be still used to control unindexing old transactions.
 	SkipTxIndexing bool `json:"skip-tx-indexing"`
 
+	// Retention declares how long each class of block data is kept. Data
+	// outside of its retention is deleted in the background.
+	Retention RetentionConfig `json:"retention"`
+
+	// ReceiptCostsEnabled stores the fee breakdown of each receipt (base fee
+	// burned, tip paid and gas refunded) and serves it with transaction
+	// receipts. Only blocks processed while enabled have a breakdown.
+	ReceiptCostsEnabled bool `json:"receipt-costs-enabled"`
+
+	// ReceiptsVerification runs the node as a replica that does not execute
+	// blocks: the receipts of each block are downloaded from peers and
+	// verified against the receipt root of its header. The node keeps no
+	// state, so it serves blocks, receipts and logs but neither builds
+	// blocks nor serves state queries. It trusts the state roots of the
+	// headers it verifies, so it is meant for analytics replicas only, and
+	// requires pruning-enabled to be false. It cannot be combined with
+	// validator block building, the account activity index or features
+	// submitting transactions to the tx pool.
+	ReceiptsVerification bool `json:"receipts-verification"`
+
+	// TraceIndexEnabled persists the call traces of blocks as they are
+	// accepted, which are served by trace_filter when the "trace" API is
+	// enabled. Blocks accepted before it was first enabled are not indexed.
+	TraceIndexEnabled bool `json:"trace-index-enabled"`
+
+	// OpcodeStatsSampleRate records the frequency and gas of the opcodes
+	// executed by one in every OpcodeStatsSampleRate transactions, exposed as
+	// metrics and by the admin.getOpcodeStats API. Zero disables recording.
+	OpcodeStatsSampleRate uint64 `json:"opcode-stats-sample-rate"`
+
+	// IncidentFilterFile is the path to a JSON file listing addresses and
+	// function selectors whose transactions are excluded from locally built
+	// blocks. The file is re-read by the admin.reloadIncidentFilter API.
+	IncidentFilterFile string `json:"incident-filter-file"`
+
+	// BlockGasReservation reserves gas of locally built blocks for the
+	// transactions of system addresses, such as oracle updates.
+	BlockGasReservation *miner.GasReservation `json:"block-gas-reservation,omitempty"`
+
+	// BlockBuildingSeed orders transactions paying the same tip by a hash of
+	// the seed instead of their arrival time, so that blocks built from the
+	// same transactions are identical across runs. It is meant for tests and
+	// replay tooling only.
+	BlockBuildingSeed *uint64 `json:"block-building-seed,omitempty"`
+
+	// BlockBuildingAging boosts the priority of transactions pending for
+	// long when building blocks, so that low-tip transactions are not
+	// starved during busy periods.
+	BlockBuildingAging BlockBuildingAgingConfig `json:"block-building-aging"`
+
+	// ChainExport exports accepted blocks, transactions, receipts, logs and
+	// atomic transactions to Parquet files for ingestion into data warehouses.
+	ChainExport ChainExportConfig `json:"chain-export"`
+
+	// Watchdog monitors the liveness of block building, block acceptance and
+	// gossip, failing the health check when one of them stalls.
+	Watchdog WatchdogConfig `json:"watchdog"`
+
+	// ResourceArbiter prioritizes block verification, building and
+	// acceptance over RPC calls competing for the database and tries.
+	ResourceArbiter ResourceArbiterConfig `json:"resource-arbiter"`
+
+	// CacheTuner grows the trie clean cache and the snapshot cache while
+	// accepted blocks lag behind the wall clock, and shrinks them once the
+	// node has caught up.
+	CacheTuner CacheTunerConfig `json:"cache-tuner"`
+
+	// MemoryBudget reports the memory used by the tx pool, caches, state
+	// sync and filters, and sheds memory from those exceeding their budget.
+	MemoryBudget MemoryBudgetConfig `json:"memory-budget"`
+
+	// Compaction compacts key ranges of the database during quiet hours and
+	// after large deletions, such as those of the retention policy.
+	Compaction CompactionConfig `json:"compaction"`
+
+	// Bench re-executes a range of accepted blocks on startup and reports
+	// the time spent executing, hashing and committing each of them.
+	Bench BenchConfig `json:"bench"`
+
+	// PrivateTxs serves the private API, whose transactions are never
+	// gossiped and are only included in blocks built by this node.
+	PrivateTxs PrivateTxsConfig `json:"private-txs"`
+
+	// TxLifecycle records the lifecycle of transactions, from their
+	// submission or gossip to their acceptance, served by debug_txLifecycle.
+	TxLifecycle TxLifecycleConfig `json:"tx-lifecycle"`
+
+	// UserOps serves ERC-4337 user operations in the eth namespace and
+	// bundles them into the blocks built by this node.
+	UserOps UserOpsConfig `json:"user-ops"`
+
+	// Backup uploads checkpoints of the database to object storage and
+	// bootstraps new nodes from the latest of them.
+	Backup BackupConfig `json:"backup"`
+
+	// WarpValidatorSnapshots caches and persists the validator sets used to
+	// verify warp messages at each P-chain height.
+	WarpValidatorSnapshots WarpValidatorSnapshotsConfig `json:"warp-validator-snapshots"`
+
+	// Faucet serves the faucet API, which is refused on mainnet.
+	Faucet FaucetConfig `json:"faucet"`
+
 	// WarpOffChainMessages encodes off-chain messages (unrelated to any on-chain event ie. block or AddressedCall)
 	// that the node should be willing to sign.
 	// Note: only supports AddressedCall payloads as defined here:
@@ -232,7 +524,9 @@ func (c Config) EthBackendSettings() eth.Settings {
 }
 
 func (c *Config) SetDefaults() {
-	c.EnabledEthAPIs = defaultEnabledAPIs
+	// Copy the defaults, as unmarshalling the config reuses the backing array
+	// and Deprecate filters the list.
+	c.EnabledEthAPIs = slices.Clone(defaultEnabledAPIs)
 	c.RPCGasCap = defaultRpcGasCap
 	c.RPCTxFeeCap = defaultRpcTxFeeCap
 	c.MetricsExpensiveEnabled = defaultMetricsExpensiveEnabled
@@ -244,10 +538,14 @@ func (c *Config) SetDefaults() {
 	c.TxPoolAccountQueue = legacypool.DefaultConfig.AccountQueue
 	c.TxPoolGlobalQueue = legacypool.DefaultConfig.GlobalQueue
 	c.TxPoolLifetime.Duration = legacypool.DefaultConfig.Lifetime
+	c.TxPoolSponsoredSlots = legacypool.DefaultConfig.SponsoredSlots
 
 	c.APIMaxDuration.Duration = defaultApiMaxDuration
 	c.WSCPURefillRate.Duration = defaultWsCpuRefillRate
 	c.WSCPUMaxStored.Duration = defaultWsCpuMaxStored
+	c.WSMaxSubscriptions = defaultWsMaxSubscriptions
+	c.WSNotificationBuffer = defaultWsNotificationBuffer
+	c.WSSlowConsumerPolicy = string(defaultWsSlowConsumerPolicy)
 	c.MaxBlocksPerRequest = defaultMaxBlocksPerRequest
 	c.ContinuousProfilerFrequency.Duration = defaultContinuousProfilerFrequency
 	c.ContinuousProfilerMaxFiles = defaultContinuousProfilerMaxFiles
@@ -256,6 +554,7 @@ func (c *Config) SetDefaults() {
 	c.TrieDirtyCache = defaultTrieDirtyCache
 	c.TrieDirtyCommitTarget = defaultTrieDirtyCommitTarget
 	c.TriePrefetcherParallelism = defaultTriePrefetcherParallelism
+	c.TriePrefetcherMaxParallelism = defaultTriePrefetcherMaxParallelism
 	c.SnapshotCache = defaultSnapshotCache
 	c.AcceptorQueueLimit = defaultAcceptorQueueLimit
 	c.CommitInterval = defaultCommitInterval
@@ -268,11 +567,24 @@ func (c *Config) SetDefaults() {
 	c.PushGossipFrequency.Duration = defaultPushGossipFrequency
 	c.PullGossipFrequency.Duration = defaultPullGossipFrequency
 	c.RegossipFrequency.Duration = defaultTxRegossipFrequency
+	c.AtomicPushGossipPercentStake = defaultAtomicPushGossipPercentStake
+	c.AtomicPushGossipNumValidators = defaultAtomicPushGossipNumValidators
+	c.AtomicPushGossipNumPeers = defaultAtomicPushGossipNumPeers
+	c.AtomicPushRegossipNumValidators = defaultAtomicPushRegossipNumValidators
+	c.AtomicPushRegossipNumPeers = defaultAtomicPushRegossipNumPeers
+	c.AtomicPushGossipFrequency.Duration = defaultAtomicPushGossipFrequency
+	c.AtomicPullGossipFrequency.Duration = defaultAtomicPullGossipFrequency
+	c.AtomicRegossipFrequency.Duration = defaultAtomicRegossipFrequency
+	c.BuildBlockMinDelay.Duration = defaultBuildBlockMinDelay
+	c.BuildBlockRetryDelay.Duration = defaultBuildBlockRetryDelay
+	c.BuildBlockMaxRetryDelay.Duration = defaultBuildBlockMaxRetryDelay
 	c.OfflinePruningBloomFilterSize = defaultOfflinePruningBloomFilterSize
 	c.LogLevel = defaultLogLevel
 	c.LogJSONFormat = defaultLogJSONFormat
+	c.LogMaxSize = defaultLogMaxSize
 	c.MaxOutboundActiveRequests = defaultMaxOutboundActiveRequests
 	c.MaxOutboundActiveCrossChainRequests = defaultMaxOutboundActiveCrossChainRequests
+	c.GossipDedupCacheSize = defaultGossipDedupCacheSize
 	c.PopulateMissingTriesParallelism = defaultPopulateMissingTriesParallelism
 	c.StateSyncServerTrieCache = defaultStateSyncServerTrieCache
 	c.StateSyncCommitInterval = defaultSyncableCommitInterval
@@ -280,6 +592,43 @@ func (c *Config) SetDefaults() {
 	c.StateSyncRequestSize = defaultStateSyncRequestSize
 	c.AllowUnprotectedTxHashes = defaultAllowUnprotectedTxHashes
 	c.AcceptedCacheSize = defaultAcceptedCacheSize
+	c.HistoricalStateCache = defaultHistoricalStateCache
+	c.ArchiveProxyTimeout.Duration = defaultArchiveProxyTimeout
+	c.ChainExport.BlocksPerFile = defaultChainExportBlocksPerFile
+	c.ChainExport.FlushInterval.Duration = defaultChainExportFlushInterval
+	c.Watchdog.BuildBlockThreshold.Duration = defaultWatchdogBuildBlockThreshold
+	c.Watchdog.AcceptBlockThreshold.Duration = defaultWatchdogAcceptBlockThreshold
+	c.Watchdog.GossipThreshold.Duration = defaultWatchdogGossipThreshold
+	c.ResourceArbiter.RPCSlots = defaultArbiterRPCSlots
+	c.ResourceArbiter.RPCSlotsDuringConsensus = defaultArbiterRPCSlotsDuringConsensus
+	c.MemoryBudget.CheckInterval.Duration = defaultMemoryBudgetCheckInterval
+	c.CacheTuner.LagThreshold.Duration = defaultCacheTunerLagThreshold
+	c.CacheTuner.Window.Duration = defaultCacheTunerWindow
+	c.CacheTuner.Windows = defaultCacheTunerWindows
+	c.CacheTuner.MinTrieCleanCache = defaultTrieCleanCache
+	c.CacheTuner.MaxTrieCleanCache = 4 * defaultTrieCleanCache
+	c.CacheTuner.MinSnapshotCache = defaultSnapshotCache
+	c.CacheTuner.MaxSnapshotCache = 4 * defaultSnapshotCache
+	c.StateSyncAvailability.Samples = defaultStateSyncAvailabilitySamples
+	c.StateSyncAvailability.MinPeers = defaultStateSyncAvailabilityMinPeers
+	c.StateSyncAvailability.Timeout.Duration = defaultStateSyncAvailabilityTimeout
+	c.StateSyncAvailability.Parallelism = defaultStateSyncAvailabilityParallelism
+	c.PeerHealthProbes.Interval.Duration = defaultPeerHealthProbeInterval
+	c.PeerHealthProbes.IdleTimeout.Duration = defaultPeerHealthProbeIdleTimeout
+	c.PeerHealthProbes.Timeout.Duration = defaultPeerHealthProbeTimeout
+	c.PeerHealthProbes.MaxFailures = defaultPeerHealthProbeMaxFailures
+	c.Compaction.Ranges = defaultCompactionRanges
+	c.Compaction.DeletionThreshold = defaultCompactionDeletionThreshold
+	c.Bench.Format = defaultBenchFormat
+	c.PrivateTxs.TTL.Duration = defaultPrivateTxsTTL
+	c.TxLifecycle.Capacity = defaultTxLifecycleCapacity
+	c.UserOps.MaxOps = defaultUserOpsMaxOps
+	c.UserOps.MaxBundleOps = defaultUserOpsMaxBundleOps
+	c.Backup.Interval.Duration = defaultBackupInterval
+	c.WarpValidatorSnapshots.CacheSize = defaultWarpValidatorSnapshotsCacheSize
+	c.WarpValidatorSnapshots.Retention = defaultWarpValidatorSnapshotsRetention
+	c.Faucet.Amount = defaultFaucetAmount
+	c.Faucet.Interval.Duration = defaultFaucetInterval
 }
 
 func (d *Duration) UnmarshalJSON(data []byte) (err error) {
@@ -301,6 +650,341 @@ func (d Duration) MarshalJSON() ([]byte, error) {
 	return json.Marshal(d.Duration.String())
 }
 
+// RetentionRule bounds the data of a class to the last Blocks accepted blocks
+// and to the blocks younger than Duration. Data is kept while either bound
+// keeps it, and zero values disable a bound.
+type RetentionRule struct {
+	Blocks   uint64   `json:"blocks"`
+	Duration Duration `json:"duration"`
+}
+
+func (r RetentionRule) enabled() bool {
+	return r.Blocks > 0 || r.Duration.Duration > 0
+}
+
+// RetentionConfig declares the retention of each class of block data. Block
+// data is always kept for the blocks since the last committed state, which
+// are reprocessed on restart, and bodies for the blocks not yet processed by
+// every accepted block consumer.
+type RetentionConfig struct {
+	// Bodies also bounds the receipts and transaction index, which cannot be
+	// served without the block body.
+	Bodies    RetentionRule `json:"bodies"`
+	Receipts  RetentionRule `json:"receipts"`
+	TxIndex   RetentionRule `json:"tx-index"`
+	LogsIndex RetentionRule `json:"logs-index"`
+	// BlobSidecars bounds the blob sidecars of accepted blocks, which are
+	// otherwise kept indefinitely.
+	BlobSidecars RetentionRule `json:"blob-sidecars"`
+	// Traces bounds the call traces persisted by the trace index.
+	Traces RetentionRule `json:"traces"`
+}
+
+func (c RetentionConfig) enabled() bool {
+	return c.Bodies.enabled() || c.Receipts.enabled() || c.TxIndex.enabled() || c.LogsIndex.enabled() || c.BlobSidecars.enabled() || c.Traces.enabled()
+}
+
+// CompactionConfig schedules compaction of key ranges of the database
+// instead of leaving it entirely to the heuristics of the backend.
+type CompactionConfig struct {
+	// Enabled starts the compaction scheduler.
+	Enabled bool `json:"enabled"`
+	// QuietHours lists the daily windows of low traffic, in UTC and
+	// formatted as "HH:MM-HH:MM", during which Ranges are compacted once.
+	QuietHours []string `json:"quiet-hours"`
+	// Ranges lists the key ranges compacted during quiet hours.
+	Ranges []string `json:"ranges"`
+	// DeletionThreshold is the number of heights whose data is deleted from
+	// a range before it is compacted outside of quiet hours. Zero disables
+	// compaction after deletions.
+	DeletionThreshold uint64 `json:"deletion-threshold"`
+}
+
+// BenchConfig configures the re-execution of accepted blocks to measure
+// their execution performance.
+type BenchConfig struct {
+	// Output is the file the measurements are written to. Benchmarking is
+	// disabled if empty.
+	Output string `json:"output"`
+	// Format is the format of Output, either "csv" or "json" (one object
+	// per line).
+	Format string `json:"format"`
+	// FromBlock and ToBlock are the first and last re-executed blocks. The
+	// state of the parent of FromBlock must be available.
+	FromBlock uint64 `json:"from-block"`
+	ToBlock   uint64 `json:"to-block"`
+}
+
+// PrivateTxsConfig configures the submission of private transactions.
+type PrivateTxsConfig struct {
+	// Enabled serves the private API.
+	Enabled bool `json:"enabled"`
+	// TTL is how long a private transaction is kept in the mempool before
+	// it is dropped if it was not included in an accepted block.
+	TTL Duration `json:"ttl"`
+}
+
+// UserOpsConfig configures the pool of ERC-4337 (v0.6) user operations.
+type UserOpsConfig struct {
+	// Enabled serves the user operation methods of the eth namespace and
+	// bundles user operations into locally built blocks.
+	Enabled bool `json:"enabled"`
+	// EntryPoint is the address of the EntryPoint contract validating and
+	// executing user operations.
+	EntryPoint common.Address `json:"entry-point"`
+	// BundlerKeyFile is the path to the hex encoded private key signing the
+	// bundles, whose account is refunded the gas of the bundles.
+	BundlerKeyFile string `json:"bundler-key-file"`
+	// MaxOps is the maximum number of pending user operations.
+	MaxOps int `json:"max-ops"`
+	// MaxBundleOps is the maximum number of user operations per bundle.
+	MaxBundleOps int `json:"max-bundle-ops"`
+}
+
+// BackupConfig configures the checkpoints of the database uploaded to
+// object storage, and the restore of new nodes from them.
+type BackupConfig struct {
+	// URL is where checkpoints are stored: the http(s) URL of a bucket, read
+	// and written with GET and PUT requests, or a directory such as a mounted
+	// bucket. Backups are disabled if empty.
+	URL string `json:"url"`
+	// Headers are added to the requests to an http(s) URL, such as the
+	// authorization of the bucket.
+	Headers map[string]string `json:"headers"`
+	// Interval is the time between checkpoints. No checkpoint is uploaded if
+	// zero, which only allows restoring from the backups of other nodes.
+	Interval Duration `json:"interval"`
+	// Restore bootstraps an empty database from the latest checkpoint.
+	Restore bool `json:"restore"`
+}
+
+// FaucetConfig configures the faucet of test networks.
+type FaucetConfig struct {
+	// Enabled serves the faucet API.
+	Enabled bool `json:"enabled"`
+	// KeyFile is the path to the hex encoded private key of the account
+	// funding the faucet.
+	KeyFile string `json:"key-file"`
+	// Account is the account funding the faucet if KeyFile is not set, which
+	// must be available from the account backends of the node such as
+	// keystore-external-signer.
+	Account common.Address `json:"account"`
+	// Amount is the amount sent per request, in gwei.
+	Amount uint64 `json:"amount"`
+	// Interval is the minimum time between the requests of an address or IP.
+	Interval Duration `json:"interval"`
+	// CaptchaURL is the siteverify endpoint of a captcha service, such as
+	// reCAPTCHA or hCaptcha, verifying the captcha response of each request.
+	// Requests are not verified if empty.
+	CaptchaURL string `json:"captcha-url"`
+	// CaptchaSecret is the secret key sent to the captcha service.
+	CaptchaSecret string `json:"captcha-secret"`
+}
+
+// WarpValidatorSnapshotsConfig configures the snapshots of the validator sets
+// used to verify warp messages.
+type WarpValidatorSnapshotsConfig struct {
+	// CacheSize is the number of most recently used validator sets kept in
+	// memory.
+	CacheSize int `json:"cache-size"`
+	// Retention is the number of P-chain heights below the most recent
+	// snapshot whose snapshots are persisted. Snapshots are kept indefinitely
+	// if zero.
+	Retention uint64 `json:"retention"`
+}
+
+// TxLifecycleConfig configures the tracing of the lifecycle of transactions.
+type TxLifecycleConfig struct {
+	// Enabled records the lifecycle events of transactions.
+	Enabled bool `json:"enabled"`
+	// Capacity is the number of most recently seen transactions whose events
+	// are kept.
+	Capacity int `json:"capacity"`
+}
+
+// ChainExportConfig configures the export of the accepted chain to Parquet
+// files, partitioned by table and by the UTC date of the blocks.
+type ChainExportConfig struct {
+	// Dir is the directory files are written to. Export is disabled if empty.
+	Dir string `json:"dir"`
+	// Tables maps each exported table to its exported columns. Every table
+	// is exported if empty, and every column of a table listed without
+	// columns is exported.
+	Tables map[string][]string `json:"tables"`
+	// BlocksPerFile is the maximum number of blocks written to each file.
+	BlocksPerFile uint64 `json:"blocks-per-file"`
+	// FlushInterval is how long blocks are buffered before being written
+	// when fewer than BlocksPerFile blocks are accepted.
+	FlushInterval Duration `json:"flush-interval"`
+}
+
+// WatchdogConfig bounds how long an iteration of each monitored loop may run
+// before the loop is considered stalled.
+type WatchdogConfig struct {
+	// Enabled starts the watchdog.
+	Enabled bool `json:"enabled"`
+	// BuildBlockThreshold bounds the time to build a block.
+	BuildBlockThreshold Duration `json:"build-block-threshold"`
+	// AcceptBlockThreshold bounds the time to accept a block.
+	AcceptBlockThreshold Duration `json:"accept-block-threshold"`
+	// GossipThreshold bounds the time of a cycle of each gossip loop.
+	GossipThreshold Duration `json:"gossip-threshold"`
+}
+
+// ResourceArbiterConfig bounds the number of RPC calls executing
+// concurrently, with a lower bound while consensus work is in progress.
+type ResourceArbiterConfig struct {
+	// Enabled schedules RPC calls through the arbiter.
+	Enabled bool `json:"enabled"`
+	// RPCSlots is the number of RPC calls executing concurrently while no
+	// consensus work is in progress.
+	RPCSlots int `json:"rpc-slots"`
+	// RPCSlotsDuringConsensus is the number of RPC calls executing
+	// concurrently while blocks are verified, built or accepted. Calls
+	// already executing are not interrupted.
+	RPCSlotsDuringConsensus int `json:"rpc-slots-during-consensus"`
+}
+
+// BlockBuildingAgingConfig boosts the priority of a transaction pending for
+// longer than Threshold by Boost wei per gas for every Interval it pends
+// beyond Threshold, up to MaxBoost. Transactions still pay their own tip.
+type BlockBuildingAgingConfig struct {
+	// Enabled applies the aging policy to locally built blocks.
+	Enabled   bool     `json:"enabled"`
+	Threshold Duration `json:"threshold"`
+	Interval  Duration `json:"interval"`
+	Boost     uint64   `json:"boost"`
+	MaxBoost  uint64   `json:"max-boost"`
+}
+
+// policy returns the aging policy of the config, or nil if it is disabled.
+func (c BlockBuildingAgingConfig) policy() *miner.AgingPolicy {
+	if !c.Enabled {
+		return nil
+	}
+	return &miner.AgingPolicy{
+		Threshold: c.Threshold.Duration,
+		Interval:  c.Interval.Duration,
+		Boost:     c.Boost,
+		MaxBoost:  c.MaxBoost,
+	}
+}
+
+// CacheTunerConfig resizes the trie clean cache and the snapshot cache
+// based on the lag of accepted blocks behind the wall clock. A window in
+// which every accepted block lagged by more than LagThreshold is lagging, and
+// after Windows consecutive lagging windows the caches are doubled. They are
+// halved after as many windows in which every block lagged by less than half
+// of LagThreshold. Cache sizes are in megabytes, and a resized cache keeps
+// serving the entries of the previous one while it fills up.
+type CacheTunerConfig struct {
+	// Enabled starts resizing the caches.
+	Enabled           bool     `json:"enabled"`
+	LagThreshold      Duration `json:"lag-threshold"`
+	Window            Duration `json:"window"`
+	Windows           int      `json:"windows"`
+	MinTrieCleanCache int      `json:"min-trie-clean-cache"`
+	MaxTrieCleanCache int      `json:"max-trie-clean-cache"`
+	MinSnapshotCache  int      `json:"min-snapshot-cache"`
+	MaxSnapshotCache  int      `json:"max-snapshot-cache"`
+}
+
+// tuner returns the cache tuner config of the chain, or nil if it is
+// disabled.
+func (c CacheTunerConfig) tuner() *core.CacheTunerConfig {
+	if !c.Enabled {
+		return nil
+	}
+	return &core.CacheTunerConfig{
+		LagThreshold:      c.LagThreshold.Duration,
+		Window:            c.Window.Duration,
+		Windows:           c.Windows,
+		MinTrieCleanLimit: c.MinTrieCleanCache,
+		MaxTrieCleanLimit: c.MaxTrieCleanCache,
+		MinSnapshotLimit:  c.MinSnapshotCache,
+		MaxSnapshotLimit:  c.MaxSnapshotCache,
+	}
+}
+
+// PeerHealthProbeConfig probes, every Interval, the peers that were not sent
+// a request for IdleTimeout. A peer not answering a probe within Timeout is
+// demoted as if a request to it failed, and after MaxFailures consecutive
+// unanswered probes it is no longer picked for requests until it is tried
+// again as a new peer.
+type PeerHealthProbeConfig struct {
+	// Enabled starts probing idle peers.
+	Enabled     bool     `json:"enabled"`
+	Interval    Duration `json:"interval"`
+	IdleTimeout Duration `json:"idle-timeout"`
+	Timeout     Duration `json:"timeout"`
+	MaxFailures int      `json:"max-failures"`
+}
+
+// probes returns the config of the health probes of the network, or nil if
+// they are disabled.
+func (c PeerHealthProbeConfig) probes() *peer.HealthProbeConfig {
+	if !c.Enabled {
+		return nil
+	}
+	return &peer.HealthProbeConfig{
+		Interval:    c.Interval.Duration,
+		IdleTimeout: c.IdleTimeout.Duration,
+		Timeout:     c.Timeout.Duration,
+		MaxFailures: c.MaxFailures,
+	}
+}
+
+// StateSyncAvailabilityConfig evaluates, in the background, each state
+// summary advertised by peers by requesting Samples ranges of leafs at random
+// keys of its state root, at most Parallelism summaries at a time. State sync
+// is skipped, falling back to bootstrapping, if fewer than MinPeers distinct
+// peers served valid proofs of the proposed summary within Timeout.
+type StateSyncAvailabilityConfig struct {
+	// Enabled starts evaluating summaries.
+	Enabled     bool     `json:"enabled"`
+	Samples     int      `json:"samples"`
+	MinPeers    int      `json:"min-peers"`
+	Timeout     Duration `json:"timeout"`
+	Parallelism int      `json:"parallelism"`
+}
+
+// evaluator returns the config of the evaluation of summaries, or nil if it
+// is disabled.
+func (c StateSyncAvailabilityConfig) evaluator() *statesyncclient.AvailabilityConfig {
+	if !c.Enabled {
+		return nil
+	}
+	return &statesyncclient.AvailabilityConfig{
+		Samples:     c.Samples,
+		MinPeers:    c.MinPeers,
+		Timeout:     c.Timeout.Duration,
+		Parallelism: c.Parallelism,
+	}
+}
+
+// MemoryBudgetConfig sets soft budgets, in megabytes, on the memory used by
+// each subsystem. A budget of 0 leaves the subsystem unbounded, although its
+// usage is still reported.
+type MemoryBudgetConfig struct {
+	// Enabled starts the memory accounting.
+	Enabled bool `json:"enabled"`
+	// CheckInterval is the interval at which usage is measured and budgets
+	// are enforced.
+	CheckInterval Duration `json:"check-interval"`
+	// TxPool bounds the transactions held by the tx pool. Remote
+	// transactions paying the lowest tips are dropped first.
+	TxPool uint64 `json:"txpool"`
+	// Caches bounds the clean trie cache and the snapshot cache, which are
+	// reset when exceeded.
+	Caches uint64 `json:"caches"`
+	// StateSync bounds the leafs, trie nodes and batches buffered by state
+	// sync, which is throttled instead of shed.
+	StateSync uint64 `json:"state-sync"`
+	// Filters bounds the results buffered by polled filters. The filters
+	// buffering the most are uninstalled first.
+	Filters uint64 `json:"filters"`
+}
+
 // Validate returns an error if this is an invalid config.
 func (c *Config) Validate() error {
 	if c.PopulateMissingTries != nil && (c.OfflinePruning || c.Pruning) {
@@ -318,9 +1002,226 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("cannot use commit interval of 0 with pruning enabled")
 	}
 
+	// The retention policy keeps the blocks since the last committed state,
+	// which it cannot bound with a commit interval of 0.
+	if c.Retention.enabled() && c.CommitInterval == 0 {
+		return fmt.Errorf("cannot use commit interval of 0 with retention enabled")
+	}
+	if c.Retention.TxIndex.enabled() && (c.TransactionHistory != 0 || c.TxLookupLimit != 0) {
+		return fmt.Errorf("cannot set both retention tx-index and transaction-history")
+	}
+	if c.Retention.Traces.enabled() && !c.TraceIndexEnabled {
+		return fmt.Errorf("cannot set retention traces without trace-index-enabled")
+	}
+	for _, rule := range []RetentionRule{c.Retention.Bodies, c.Retention.Receipts, c.Retention.TxIndex, c.Retention.LogsIndex, c.Retention.BlobSidecars, c.Retention.Traces} {
+		if rule.Duration.Duration < 0 {
+			return fmt.Errorf("retention duration %s must not be negative", rule.Duration)
+		}
+	}
+
+	if policy := c.BlockBuildingAging.policy(); policy != nil {
+		if err := policy.Verify(); err != nil {
+			return fmt.Errorf("invalid block-building-aging: %w", err)
+		}
+	}
+	if c.BlockGasReservation != nil {
+		if err := c.BlockGasReservation.Verify(); err != nil {
+			return fmt.Errorf("invalid block-gas-reservation: %w", err)
+		}
+	}
+
+	if c.ChainExport.Dir != "" {
+		if c.ChainExport.BlocksPerFile == 0 {
+			return fmt.Errorf("chain-export blocks-per-file must be positive")
+		}
+		if c.ChainExport.FlushInterval.Duration <= 0 {
+			return fmt.Errorf("chain-export flush-interval must be positive")
+		}
+		if _, err := newChainExportTables(c.ChainExport.Tables); err != nil {
+			return fmt.Errorf("invalid chain-export tables: %w", err)
+		}
+	}
+
+	if c.Watchdog.Enabled {
+		for _, threshold := range []Duration{c.Watchdog.BuildBlockThreshold, c.Watchdog.AcceptBlockThreshold, c.Watchdog.GossipThreshold} {
+			if threshold.Duration <= 0 {
+				return fmt.Errorf("watchdog threshold %s must be positive", threshold)
+			}
+		}
+	}
+
+	if c.AdminGRPCAddress != "" && c.AdminGRPCTokenFile == "" {
+		return fmt.Errorf("admin-grpc-address requires admin-grpc-token-file")
+	}
+	if (c.AdminGRPCTLSCertFile == "") != (c.AdminGRPCTLSKeyFile == "") {
+		return fmt.Errorf("admin-grpc-tls-cert-file and admin-grpc-tls-key-file must be set together")
+	}
+
+	if c.ArchiveProxyURL != "" && c.ArchiveProxyTimeout.Duration <= 0 {
+		return fmt.Errorf("archive-proxy-timeout %s must be positive", c.ArchiveProxyTimeout)
+	}
+
+	if tuner := c.CacheTuner.tuner(); tuner != nil {
+		if err := tuner.Verify(); err != nil {
+			return fmt.Errorf("invalid cache-tuner: %w", err)
+		}
+	}
+	if probes := c.PeerHealthProbes.probes(); probes != nil {
+		if probes.Interval <= 0 || probes.IdleTimeout <= 0 || probes.Timeout <= 0 {
+			return fmt.Errorf("peer-health-probes interval (%s), idle-timeout (%s) and timeout (%s) must be positive", probes.Interval, probes.IdleTimeout, probes.Timeout)
+		}
+		if probes.MaxFailures <= 0 {
+			return fmt.Errorf("peer-health-probes max-failures (%d) must be positive", probes.MaxFailures)
+		}
+	}
+	if c.MemoryBudget.Enabled && c.MemoryBudget.CheckInterval.Duration <= 0 {
+		return fmt.Errorf("memory budget check-interval (%s) must be positive", c.MemoryBudget.CheckInterval.Duration)
+	}
+	if c.ResourceArbiter.Enabled {
+		if c.ResourceArbiter.RPCSlots < 1 {
+			return fmt.Errorf("resource arbiter rpc-slots (%d) must be positive", c.ResourceArbiter.RPCSlots)
+		}
+		if c.ResourceArbiter.RPCSlotsDuringConsensus < 0 || c.ResourceArbiter.RPCSlotsDuringConsensus > c.ResourceArbiter.RPCSlots {
+			return fmt.Errorf("resource arbiter rpc-slots-during-consensus (%d) must be between 0 and rpc-slots (%d)", c.ResourceArbiter.RPCSlotsDuringConsensus, c.ResourceArbiter.RPCSlots)
+		}
+	}
+
+	if c.Compaction.Enabled {
+		for _, window := range c.Compaction.QuietHours {
+			if _, err := rawdb.ParseQuietWindow(window); err != nil {
+				return fmt.Errorf("invalid compaction quiet-hours: %w", err)
+			}
+		}
+		for _, name := range c.Compaction.Ranges {
+			if !slices.Contains(rawdb.CompactionRangeNames(), name) {
+				return fmt.Errorf("unknown compaction range %q, expected one of %v", name, rawdb.CompactionRangeNames())
+			}
+		}
+	}
+
+	if c.Bench.Output != "" {
+		if c.Bench.FromBlock == 0 || c.Bench.FromBlock > c.Bench.ToBlock {
+			return fmt.Errorf("invalid bench block range [%d, %d]", c.Bench.FromBlock, c.Bench.ToBlock)
+		}
+		if !slices.Contains(benchFormats, c.Bench.Format) {
+			return fmt.Errorf("unknown bench format %q, expected one of %v", c.Bench.Format, benchFormats)
+		}
+	}
+
+	if c.PrivateTxs.Enabled && c.PrivateTxs.TTL.Duration <= 0 {
+		return fmt.Errorf("private-txs ttl must be positive")
+	}
+
+	if c.TxLifecycle.Enabled && c.TxLifecycle.Capacity <= 0 {
+		return fmt.Errorf("tx-lifecycle capacity must be positive")
+	}
+
+	if c.UserOps.Enabled {
+		switch {
+		case c.UserOps.EntryPoint == (common.Address{}):
+			return fmt.Errorf("user-ops entry-point must be set")
+		case c.UserOps.BundlerKeyFile == "":
+			return fmt.Errorf("user-ops bundler-key-file must be set")
+		case c.UserOps.MaxOps <= 0:
+			return fmt.Errorf("user-ops max-ops must be positive")
+		case c.UserOps.MaxBundleOps <= 0:
+			return fmt.Errorf("user-ops max-bundle-ops must be positive")
+		}
+	}
+
+	if c.ReceiptsVerification {
+		// Replicas verifying receipts keep no state, so they can neither back
+		// the features reading it nor validate the chain.
+		switch {
+		case c.OfflinePruning || c.PopulateMissingTries != nil:
+			return fmt.Errorf("cannot verify receipts instead of executing blocks while offline pruning or populating missing tries")
+		case c.Pruning:
+			return fmt.Errorf("cannot verify receipts instead of executing blocks with pruning-enabled, whose commit journal recovers state")
+		case c.AccountActivityIndexEnabled:
+			return fmt.Errorf("cannot verify receipts instead of executing blocks with account-activity-index-enabled")
+		case c.LocalTxsEnabled || c.PrivateTxs.Enabled || c.UserOps.Enabled || c.Faucet.Enabled:
+			return fmt.Errorf("cannot verify receipts instead of executing blocks while submitting transactions to the tx pool")
+		case c.BlockBuildingSeed != nil || c.BlockGasReservation != nil || c.BlockBuildingAging.policy() != nil:
+			return fmt.Errorf("cannot verify receipts instead of executing blocks while configuring block building, which is for validators")
+		}
+	}
+
+	if c.Backup.Restore && c.Backup.URL == "" {
+		return fmt.Errorf("backup restore requires backup url to be set")
+	}
+	if c.Backup.Interval.Duration < 0 {
+		return fmt.Errorf("backup interval %s must not be negative", c.Backup.Interval)
+	}
+	if c.WarpValidatorSnapshots.CacheSize <= 0 {
+		return fmt.Errorf("warp-validator-snapshots cache-size must be positive")
+	}
+
+	if c.Faucet.Enabled {
+		switch {
+		case (c.Faucet.KeyFile == "") == (c.Faucet.Account == (common.Address{})):
+			return fmt.Errorf("faucet requires exactly one of key-file and account to be set")
+		case c.Faucet.Amount == 0:
+			return fmt.Errorf("faucet amount must be positive")
+		case c.Faucet.Interval.Duration < 0:
+			return fmt.Errorf("faucet interval %s must not be negative", c.Faucet.Interval)
+		}
+	}
+
+	if c.StateSyncCheckpointFeed != "" && len(c.StateSyncCheckpointSigners) == 0 {
+		return fmt.Errorf("state-sync-checkpoint-feed requires at least one state-sync-checkpoint-signers entry")
+	}
+	if evaluator := c.StateSyncAvailability.evaluator(); evaluator != nil {
+		if err := evaluator.Verify(); err != nil {
+			return fmt.Errorf("invalid state-sync-availability: %w", err)
+		}
+	}
+
+	if c.DatabaseEncryptionKeyFile != "" && c.DatabaseEncryptionKeyCommand != "" {
+		return fmt.Errorf("cannot set both database-encryption-key-file and database-encryption-key-command")
+	}
+
 	if c.PushGossipPercentStake < 0 || c.PushGossipPercentStake > 1 {
 		return fmt.Errorf("push-gossip-percent-stake is %f but must be in the range [0, 1]", c.PushGossipPercentStake)
 	}
+	if c.AtomicPushGossipPercentStake < 0 || c.AtomicPushGossipPercentStake > 1 {
+		return fmt.Errorf("atomic-push-gossip-percent-stake is %f but must be in the range [0, 1]", c.AtomicPushGossipPercentStake)
+	}
+
+	if err := c.APIAccessPolicy.Verify(); err != nil {
+		return fmt.Errorf("invalid api-access-policy: %w", err)
+	}
+
+	if c.WSMaxSubscriptions < 0 {
+		return fmt.Errorf("ws-max-subscriptions (%d) must not be negative", c.WSMaxSubscriptions)
+	}
+	if c.WSNotificationBuffer < 0 {
+		return fmt.Errorf("ws-notification-buffer (%d) must not be negative", c.WSNotificationBuffer)
+	}
+	if err := rpc.SlowConsumerPolicy(c.WSSlowConsumerPolicy).Verify(); err != nil {
+		return fmt.Errorf("invalid ws-slow-consumer-policy: %w", err)
+	}
+
+	for module, level := range c.LogModuleLevels {
+		if _, ok := logModules[module]; !ok {
+			return fmt.Errorf("log-module-levels contains unknown module %q", module)
+		}
+		if _, err := log.LvlFromString(level); err != nil {
+			return fmt.Errorf("invalid log level for module %q: %w", module, err)
+		}
+	}
+	if c.LogMaxSize < 0 || c.LogMaxBackups < 0 || c.LogMaxAge < 0 {
+		return fmt.Errorf("log-max-size (%d), log-max-backups (%d) and log-max-age (%d) must not be negative", c.LogMaxSize, c.LogMaxBackups, c.LogMaxAge)
+	}
+
+	if c.BuildBlockMinDelay.Duration < 0 {
+		return fmt.Errorf("build-block-min-delay (%s) must not be negative", c.BuildBlockMinDelay)
+	}
+	if c.BuildBlockRetryDelay.Duration <= 0 {
+		return fmt.Errorf("build-block-retry-delay (%s) must be positive", c.BuildBlockRetryDelay)
+	}
+	if c.BuildBlockMaxRetryDelay.Duration < c.BuildBlockRetryDelay.Duration {
+		return fmt.Errorf("build-block-max-retry-delay (%s) must be at least build-block-retry-delay (%s)", c.BuildBlockMaxRetryDelay, c.BuildBlockRetryDelay)
+	}
 	return nil
 }
 
@@ -339,10 +1240,22 @@ func (c *Config) Deprecate() string {
 		msg += "tx-regossip-frequency is deprecated, use regossip-frequency instead. "
 		c.RegossipFrequency = c.TxRegossipFrequency
 	}
+	if c.KeystoreInsecureUnlockAllowed {
+		msg += "keystore-insecure-unlock-allowed is deprecated and has no effect since the personal api was removed. "
+	}
 	if c.TxLookupLimit != 0 {
 		msg += "tx-lookup-limit is deprecated, use transaction-history instead. "
 		c.TransactionHistory = c.TxLookupLimit
 	}
+	enabledEthAPIs := make([]string, 0, len(c.EnabledEthAPIs))
+	for _, name := range c.EnabledEthAPIs {
+		if name == "internal-personal" || name == "internal-private-personal" {
+			msg += fmt.Sprintf("%s api has been removed and is ignored, use keystore-external-signer to sign with keys held outside of the node. ", name)
+			continue
+		}
+		enabledEthAPIs = append(enabledEthAPIs, name)
+	}
+	c.EnabledEthAPIs = enabledEthAPIs
 
 	return msg
 }
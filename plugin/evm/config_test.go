@@ -65,6 +65,22 @@ func TestUnmarshalConfig(t *testing.T) {
 			false,
 		},
 
+		{
+			"atomic tx gossip configurations",
+			[]byte(`{"atomic-push-gossip-percent-stake": 0.5, "atomic-push-gossip-num-validators": 1, "atomic-push-gossip-num-peers": 2, "atomic-push-regossip-num-validators": 3, "atomic-push-regossip-num-peers": 4, "atomic-push-gossip-frequency": "1s", "atomic-pull-gossip-frequency": "2s", "atomic-regossip-frequency": "3s"}`),
+			Config{
+				AtomicPushGossipPercentStake:    0.5,
+				AtomicPushGossipNumValidators:   1,
+				AtomicPushGossipNumPeers:        2,
+				AtomicPushRegossipNumValidators: 3,
+				AtomicPushRegossipNumPeers:      4,
+				AtomicPushGossipFrequency:       Duration{1 * time.Second},
+				AtomicPullGossipFrequency:       Duration{2 * time.Second},
+				AtomicRegossipFrequency:         Duration{3 * time.Second},
+			},
+			false,
+		},
+
 		{
 			"state sync enabled",
 			[]byte(`{"state-sync-enabled":true}`),
@@ -126,3 +142,31 @@ func TestUnmarshalConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateReceiptsVerification(t *testing.T) {
+	tests := []struct {
+		name        string
+		givenJSON   string
+		expectedErr string
+	}{
+		{"replica", `{"pruning-enabled": false}`, ""},
+		{"commit journal", `{}`, "pruning-enabled"},
+		{"account activity", `{"pruning-enabled": false, "account-activity-index-enabled": true}`, "account-activity-index-enabled"},
+		{"tx pool", `{"pruning-enabled": false, "local-txs-enabled": true}`, "tx pool"},
+		{"validator", `{"pruning-enabled": false, "block-building-seed": 1}`, "validators"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var config Config
+			config.SetDefaults()
+			config.ReceiptsVerification = true
+			assert.NoError(t, json.Unmarshal([]byte(tt.givenJSON), &config))
+			err := config.Validate()
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.expectedErr)
+		})
+	}
+}
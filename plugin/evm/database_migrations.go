@@ -0,0 +1,78 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/shubhamdubey02/cryftgo/database"
+	"github.com/shubhamdubey02/cryftgo/database/prefixdb"
+	"github.com/shubhamdubey02/cryftgo/database/versiondb"
+)
+
+// databaseVersionKey tracks the number of [databaseMigrations] applied to the
+// VM's database, under [metadataPrefix].
+var databaseVersionKey = []byte("database_version")
+
+// databaseMigration upgrades the VM's own key families (metadata, atomic
+// repository, indexes) from one version to the next. The chain database is
+// versioned separately by rawdb.
+type databaseMigration struct {
+	name    string
+	migrate func(db *versiondb.Database) error
+}
+
+// databaseMigrations are applied in order at startup, migration i upgrading
+// the database from version i to version i+1. Migrations must only ever be
+// appended to this list.
+var databaseMigrations = []databaseMigration{
+	{
+		name: "remove bonus blocks repaired marker",
+		migrate: func(db *versiondb.Database) error {
+			// The bonus blocks are now repaired by the atomic trie, see
+			// [repairedKey].
+			return prefixdb.New(atomicRepoMetadataDBPrefix, db).Delete([]byte("bonusBlocksRepaired"))
+		},
+	},
+}
+
+// migrateDatabase applies the [migrations] that were not yet applied to [db].
+// Each migration is committed atomically with the new version, so that a
+// failed migration is rolled back and is retried at the next startup.
+//
+// Assumes [db] has no pending writes.
+func migrateDatabase(db *versiondb.Database, migrations []databaseMigration) error {
+	metadataDB := prefixdb.New(metadataPrefix, db)
+	version, err := database.GetUInt64(metadataDB, databaseVersionKey)
+	if errors.Is(err, database.ErrNotFound) {
+		version = 0
+	} else if err != nil {
+		return fmt.Errorf("failed to read database version: %w", err)
+	}
+	if version > uint64(len(migrations)) {
+		return fmt.Errorf("database version %d is newer than the latest supported version %d", version, len(migrations))
+	}
+
+	for ; version < uint64(len(migrations)); version++ {
+		migration := migrations[version]
+		start := time.Now()
+		log.Info("Migrating database", "version", version+1, "migration", migration.name)
+		if err := migration.migrate(db); err != nil {
+			db.Abort()
+			return fmt.Errorf("database migration %d (%s) failed: %w", version+1, migration.name, err)
+		}
+		if err := database.PutUInt64(metadataDB, databaseVersionKey, version+1); err != nil {
+			db.Abort()
+			return err
+		}
+		if err := db.Commit(); err != nil {
+			return err
+		}
+		log.Info("Migrated database", "version", version+1, "elapsed", time.Since(start))
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/shubhamdubey02/cryftgo/database"
+	"github.com/shubhamdubey02/cryftgo/database/memdb"
+	"github.com/shubhamdubey02/cryftgo/database/prefixdb"
+	"github.com/shubhamdubey02/cryftgo/database/versiondb"
+)
+
+func TestMigrateDatabase(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		baseDB  = memdb.New()
+		db      = versiondb.New(baseDB)
+		errTest = errors.New("non-nil error")
+		failing = true
+		applied []string
+	)
+	migration := func(name string) databaseMigration {
+		return databaseMigration{
+			name: name,
+			migrate: func(db *versiondb.Database) error {
+				applied = append(applied, name)
+				return db.Put([]byte(name), []byte{1})
+			},
+		}
+	}
+	migrations := []databaseMigration{
+		migration("first"),
+		{
+			name: "second",
+			migrate: func(db *versiondb.Database) error {
+				if err := db.Put([]byte("second"), []byte{1}); err != nil {
+					return err
+				}
+				if failing {
+					return errTest
+				}
+				return nil
+			},
+		},
+		migration("third"),
+	}
+	version := func() uint64 {
+		version, err := database.GetUInt64(prefixdb.New(metadataPrefix, baseDB), databaseVersionKey)
+		require.NoError(err)
+		return version
+	}
+
+	// The failed migration is rolled back, keeping the earlier ones.
+	err := migrateDatabase(db, migrations)
+	require.ErrorIs(err, errTest)
+	require.Equal(uint64(1), version())
+	has, err := baseDB.Has([]byte("first"))
+	require.NoError(err)
+	require.True(has)
+	has, err = baseDB.Has([]byte("second"))
+	require.NoError(err)
+	require.False(has)
+
+	// Migrations resume from the failed one.
+	failing = false
+	require.NoError(migrateDatabase(db, migrations))
+	require.Equal(uint64(3), version())
+	require.Equal([]string{"first", "third"}, applied)
+
+	// Applied migrations are not run again.
+	require.NoError(migrateDatabase(db, migrations))
+	require.Equal([]string{"first", "third"}, applied)
+
+	// A database migrated by a newer version is rejected.
+	require.Error(migrateDatabase(db, migrations[:2]))
+}
+
+func TestMigrateDatabaseRemovesBonusBlocksRepairedMarker(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	atomicRepoMetadataDB := prefixdb.New(atomicRepoMetadataDBPrefix, baseDB)
+	require.NoError(atomicRepoMetadataDB.Put([]byte("bonusBlocksRepaired"), []byte{1}))
+
+	require.NoError(migrateDatabase(versiondb.New(baseDB), databaseMigrations))
+	has, err := atomicRepoMetadataDB.Has([]byte("bonusBlocksRepaired"))
+	require.NoError(err)
+	require.False(has)
+}
@@ -0,0 +1,386 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/shubhamdubey02/cryftgo/database"
+)
+
+// databaseEncryptionKeySize is the size of the AES-256 key used to encrypt the
+// database.
+const databaseEncryptionKeySize = 32
+
+var (
+	_ database.Database = (*encryptedDatabase)(nil)
+	_ database.Batch    = (*encryptedBatch)(nil)
+	_ database.Iterator = (*encryptedIterator)(nil)
+
+	// encryptionCheckKey is stored in an encrypted database with the value
+	// [encryptionCheckValue], so that a wrong key is detected on startup rather
+	// than on the first read of chain data.
+	encryptionCheckKey   = []byte("database_encryption_check")
+	encryptionCheckValue = []byte("coreth")
+
+	errEncryptedValueTooShort     = errors.New("encrypted value is too short")
+	errWrongDatabaseEncryptionKey = errors.New("database encryption key does not match the key the database was encrypted with")
+	errDatabaseNotEncrypted       = errors.New("cannot enable encryption of an existing unencrypted database")
+	errDatabaseEncrypted          = errors.New("database is encrypted but no database encryption key is configured")
+)
+
+// loadDatabaseEncryptionKey returns the database encryption key configured by
+// [config], or nil if encryption at rest is disabled. The key is read as hex
+// from a key file, or from the output of a command, such as a KMS client
+// decrypting a data key.
+func loadDatabaseEncryptionKey(config Config) ([]byte, error) {
+	var (
+		encoded []byte
+		err     error
+	)
+	switch {
+	case config.DatabaseEncryptionKeyFile != "":
+		encoded, err = os.ReadFile(config.DatabaseEncryptionKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read database encryption key file: %w", err)
+		}
+	case config.DatabaseEncryptionKeyCommand != "":
+		encoded, err = exec.Command("sh", "-c", config.DatabaseEncryptionKeyCommand).Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to run database encryption key command: %w", err)
+		}
+	default:
+		return nil, nil
+	}
+	key, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(string(encoded)), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode database encryption key: %w", err)
+	}
+	if len(key) != databaseEncryptionKeySize {
+		return nil, fmt.Errorf("database encryption key must be %d bytes but is %d bytes", databaseEncryptionKeySize, len(key))
+	}
+	return key, nil
+}
+
+// openDatabase returns [db] wrapped to encrypt values with [key], or [db]
+// itself if [key] is nil. It fails if [db] was encrypted with another key, or
+// if whether [db] is encrypted does not match whether a key is provided.
+func openDatabase(db database.Database, key []byte) (database.Database, error) {
+	encrypted, err := db.Has(encryptionCheckKey)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		if encrypted {
+			return nil, errDatabaseEncrypted
+		}
+		return db, nil
+	}
+
+	encDB, err := newEncryptedDatabase(key, db)
+	if err != nil {
+		return nil, err
+	}
+	if encrypted {
+		value, err := encDB.Get(encryptionCheckKey)
+		if err != nil || !bytes.Equal(value, encryptionCheckValue) {
+			return nil, errWrongDatabaseEncryptionKey
+		}
+		return encDB, nil
+	}
+
+	it := db.NewIterator()
+	empty := !it.Next()
+	it.Release()
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	if !empty {
+		return nil, errDatabaseNotEncrypted
+	}
+	if err := encDB.Put(encryptionCheckKey, encryptionCheckValue); err != nil {
+		return nil, err
+	}
+	return encDB, nil
+}
+
+// encryptedDatabase encrypts every value stored in the wrapped database with
+// AES-256-GCM, using a nonce drawn at random for every value. Keys are stored
+// in plaintext so that iteration order is preserved, and are authenticated
+// with the value they map to.
+type encryptedDatabase struct {
+	lock   sync.RWMutex
+	aead   cipher.AEAD
+	db     database.Database
+	closed bool
+}
+
+func newEncryptedDatabase(key []byte, db database.Database) (*encryptedDatabase, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptedDatabase{
+		aead: aead,
+		db:   db,
+	}, nil
+}
+
+func (db *encryptedDatabase) Has(key []byte) (bool, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return false, database.ErrClosed
+	}
+	return db.db.Has(key)
+}
+
+func (db *encryptedDatabase) Get(key []byte) ([]byte, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return nil, database.ErrClosed
+	}
+	encValue, err := db.db.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return db.decrypt(key, encValue)
+}
+
+func (db *encryptedDatabase) Put(key, value []byte) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+	encValue, err := db.encrypt(key, value)
+	if err != nil {
+		return err
+	}
+	return db.db.Put(key, encValue)
+}
+
+func (db *encryptedDatabase) Delete(key []byte) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+	return db.db.Delete(key)
+}
+
+func (db *encryptedDatabase) NewBatch() database.Batch {
+	return &encryptedBatch{
+		Batch: db.db.NewBatch(),
+		db:    db,
+	}
+}
+
+func (db *encryptedDatabase) NewIterator() database.Iterator {
+	return db.NewIteratorWithStartAndPrefix(nil, nil)
+}
+
+func (db *encryptedDatabase) NewIteratorWithStart(start []byte) database.Iterator {
+	return db.NewIteratorWithStartAndPrefix(start, nil)
+}
+
+func (db *encryptedDatabase) NewIteratorWithPrefix(prefix []byte) database.Iterator {
+	return db.NewIteratorWithStartAndPrefix(nil, prefix)
+}
+
+func (db *encryptedDatabase) NewIteratorWithStartAndPrefix(start, prefix []byte) database.Iterator {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return &database.IteratorError{
+			Err: database.ErrClosed,
+		}
+	}
+	return &encryptedIterator{
+		Iterator: db.db.NewIteratorWithStartAndPrefix(start, prefix),
+		db:       db,
+	}
+}
+
+func (db *encryptedDatabase) Compact(start, limit []byte) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+	return db.db.Compact(start, limit)
+}
+
+// Close closes the encrypted database, but not the wrapped database.
+func (db *encryptedDatabase) Close() error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+	db.closed = true
+	return nil
+}
+
+func (db *encryptedDatabase) isClosed() bool {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	return db.closed
+}
+
+func (db *encryptedDatabase) HealthCheck(ctx context.Context) (interface{}, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return nil, database.ErrClosed
+	}
+	return db.db.HealthCheck(ctx)
+}
+
+// encrypt returns the random nonce followed by the ciphertext of [value],
+// using [key] as additional data so that values cannot be moved between keys.
+func (db *encryptedDatabase) encrypt(key, value []byte) ([]byte, error) {
+	nonceSize := db.aead.NonceSize()
+	encValue := make([]byte, nonceSize, nonceSize+len(value)+db.aead.Overhead())
+	if _, err := rand.Read(encValue); err != nil {
+		return nil, err
+	}
+	return db.aead.Seal(encValue, encValue, value, key), nil
+}
+
+func (db *encryptedDatabase) decrypt(key, encValue []byte) ([]byte, error) {
+	nonceSize := db.aead.NonceSize()
+	if len(encValue) < nonceSize {
+		return nil, errEncryptedValueTooShort
+	}
+	value, err := db.aead.Open(nil, encValue[:nonceSize], encValue[nonceSize:], key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt value of key %x: %w", key, err)
+	}
+	return value, nil
+}
+
+// encryptedBatch encrypts values as they are added, so that it only holds
+// their ciphertext.
+type encryptedBatch struct {
+	database.Batch
+
+	db *encryptedDatabase
+}
+
+func (b *encryptedBatch) Put(key, value []byte) error {
+	encValue, err := b.db.encrypt(key, value)
+	if err != nil {
+		return err
+	}
+	return b.Batch.Put(key, encValue)
+}
+
+func (b *encryptedBatch) Write() error {
+	b.db.lock.Lock()
+	defer b.db.lock.Unlock()
+
+	if b.db.closed {
+		return database.ErrClosed
+	}
+	return b.Batch.Write()
+}
+
+// Replay replays the plaintext contents of the batch, decrypting the values
+// as they are replayed.
+func (b *encryptedBatch) Replay(w database.KeyValueWriterDeleter) error {
+	return b.Batch.Replay(&decryptingWriter{
+		KeyValueWriterDeleter: w,
+		db:                    b.db,
+	})
+}
+
+// decryptingWriter writes the plaintext of the values put into it.
+type decryptingWriter struct {
+	database.KeyValueWriterDeleter
+	db *encryptedDatabase
+}
+
+func (w *decryptingWriter) Put(key, encValue []byte) error {
+	value, err := w.db.decrypt(key, encValue)
+	if err != nil {
+		return err
+	}
+	return w.KeyValueWriterDeleter.Put(key, value)
+}
+
+type encryptedIterator struct {
+	database.Iterator
+	db *encryptedDatabase
+
+	key, value []byte
+	err        error
+}
+
+func (it *encryptedIterator) Next() bool {
+	// Short-circuit and set an error if the underlying database has been closed.
+	if it.db.isClosed() {
+		it.key = nil
+		it.value = nil
+		it.err = database.ErrClosed
+		return false
+	}
+
+	if !it.Iterator.Next() {
+		it.key = nil
+		it.value = nil
+		return false
+	}
+	key := it.Iterator.Key()
+	value, err := it.db.decrypt(key, it.Iterator.Value())
+	if err != nil {
+		it.key = nil
+		it.value = nil
+		it.err = err
+		return false
+	}
+	it.key = key
+	it.value = value
+	return true
+}
+
+func (it *encryptedIterator) Error() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.Iterator.Error()
+}
+
+func (it *encryptedIterator) Key() []byte {
+	return it.key
+}
+
+func (it *encryptedIterator) Value() []byte {
+	return it.value
+}
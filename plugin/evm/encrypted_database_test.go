@@ -0,0 +1,132 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shubhamdubey02/cryftgo/database"
+	"github.com/shubhamdubey02/cryftgo/database/memdb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptedDatabaseInterface(t *testing.T) {
+	for name, test := range database.Tests {
+		t.Run(name, func(t *testing.T) {
+			db, err := newEncryptedDatabase(make([]byte, databaseEncryptionKeySize), memdb.New())
+			require.NoError(t, err)
+
+			test(t, db)
+		})
+	}
+}
+
+func TestOpenEncryptedDatabase(t *testing.T) {
+	require := require.New(t)
+
+	key := make([]byte, databaseEncryptionKeySize)
+	key[0] = 1
+	baseDB := memdb.New()
+	db, err := openDatabase(baseDB, key)
+	require.NoError(err)
+
+	// Values are not stored in plaintext.
+	require.NoError(db.Put([]byte("key"), []byte("value")))
+	raw, err := baseDB.Get([]byte("key"))
+	require.NoError(err)
+	require.NotContains(string(raw), "value")
+
+	// Reopening with the same key returns the plaintext.
+	db, err = openDatabase(baseDB, key)
+	require.NoError(err)
+	value, err := db.Get([]byte("key"))
+	require.NoError(err)
+	require.Equal([]byte("value"), value)
+
+	// A value moved to another key fails authentication.
+	require.NoError(baseDB.Put([]byte("other"), raw))
+	_, err = db.Get([]byte("other"))
+	require.Error(err)
+
+	_, err = openDatabase(baseDB, make([]byte, databaseEncryptionKeySize))
+	require.ErrorIs(err, errWrongDatabaseEncryptionKey)
+	_, err = openDatabase(baseDB, nil)
+	require.ErrorIs(err, errDatabaseEncrypted)
+
+	unencryptedDB := memdb.New()
+	require.NoError(unencryptedDB.Put([]byte("key"), []byte("value")))
+	_, err = openDatabase(unencryptedDB, key)
+	require.ErrorIs(err, errDatabaseNotEncrypted)
+	db, err = openDatabase(unencryptedDB, nil)
+	require.NoError(err)
+	require.Equal(unencryptedDB, db)
+}
+
+func TestEncryptedBatch(t *testing.T) {
+	require := require.New(t)
+
+	db, err := newEncryptedDatabase(make([]byte, databaseEncryptionKeySize), memdb.New())
+	require.NoError(err)
+	batch := db.NewBatch()
+	require.NoError(batch.Put([]byte("key"), []byte("value")))
+
+	// The batch only holds the ciphertext of values.
+	raw := memdb.New()
+	require.NoError(batch.(*encryptedBatch).Batch.Replay(raw))
+	rawValue, err := raw.Get([]byte("key"))
+	require.NoError(err)
+	require.NotContains(string(rawValue), "value")
+
+	// Replaying the batch decrypts the values.
+	plain := memdb.New()
+	require.NoError(batch.Replay(plain))
+	value, err := plain.Get([]byte("key"))
+	require.NoError(err)
+	require.Equal([]byte("value"), value)
+}
+
+func TestLoadDatabaseEncryptionKey(t *testing.T) {
+	key := make([]byte, databaseEncryptionKeySize)
+	key[31] = 0xff
+	keyFile := filepath.Join(t.TempDir(), "key")
+	require.NoError(t, os.WriteFile(keyFile, []byte(hex.EncodeToString(key)+"\n"), 0o600))
+
+	tests := map[string]struct {
+		config      Config
+		expectedKey []byte
+		expectedErr bool
+	}{
+		"disabled": {},
+		"key file": {
+			config:      Config{DatabaseEncryptionKeyFile: keyFile},
+			expectedKey: key,
+		},
+		"key command": {
+			config:      Config{DatabaseEncryptionKeyCommand: "echo 0x" + hex.EncodeToString(key)},
+			expectedKey: key,
+		},
+		"short key": {
+			config:      Config{DatabaseEncryptionKeyCommand: "echo 0102"},
+			expectedErr: true,
+		},
+		"failing command": {
+			config:      Config{DatabaseEncryptionKeyCommand: "exit 1"},
+			expectedErr: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			key, err := loadDatabaseEncryptionKey(test.config)
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expectedKey, key)
+		})
+	}
+}
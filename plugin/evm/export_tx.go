@@ -285,6 +285,27 @@ func (vm *VM) newExportTx(
 	baseFee *big.Int, // fee to use post-AP3
 	keys []*secp256k1.PrivateKey, // Pay the fee and provide the tokens
 ) (*Tx, error) {
+	utx, err := vm.newUnsignedExportTx(assetID, amount, chainID, to, baseFee, ethAddresses(keys))
+	if err != nil {
+		return nil, err
+	}
+	tx := &Tx{UnsignedAtomicTx: utx}
+	if err := tx.Sign(vm.codec, inputSigners(utx.Ins, keys)); err != nil {
+		return nil, err
+	}
+	return tx, utx.Verify(vm.ctx, vm.currentRules())
+}
+
+// newUnsignedExportTx returns a new ExportTx spending the funds of [addrs]
+// without signing it
+func (vm *VM) newUnsignedExportTx(
+	assetID ids.ID, // AssetID of the tokens to export
+	amount uint64, // Amount of tokens to export
+	chainID ids.ID, // Chain to send the UTXOs to
+	to ids.ShortID, // Address of chain recipient
+	baseFee *big.Int, // fee to use post-AP3
+	addrs []common.Address, // Pay the fee and provide the tokens
+) (*UnsignedExportTx, error) {
 	outs := []*cryft.TransferableOutput{{
 		Asset: cryft.Asset{ID: assetID},
 		Out: &secp256k1fx.TransferOutput{
@@ -298,15 +319,14 @@ func (vm *VM) newExportTx(
 	}}
 
 	var (
-		cryftNeeded           uint64 = 0
-		ins, cryftIns         []EVMInput
-		signers, cryftSigners [][]*secp256k1.PrivateKey
-		err                   error
+		cryftNeeded   uint64 = 0
+		ins, cryftIns []EVMInput
+		err           error
 	)
 
 	// consume non-CRYFT
 	if assetID != vm.ctx.CRYFTAssetID {
-		ins, signers, err = vm.GetSpendableFunds(keys, assetID, amount)
+		ins, err = vm.getSpendableFunds(addrs, assetID, amount)
 		if err != nil {
 			return nil, fmt.Errorf("couldn't generate tx inputs/signers: %w", err)
 		}
@@ -335,37 +355,30 @@ func (vm *VM) newExportTx(
 			return nil, err
 		}
 
-		cryftIns, cryftSigners, err = vm.GetSpendableCRYFTWithFee(keys, cryftNeeded, cost, baseFee)
+		cryftIns, err = vm.getSpendableCRYFTWithFee(addrs, cryftNeeded, cost, baseFee)
 	default:
 		var newCryftNeeded uint64
 		newCryftNeeded, err = math.Add64(cryftNeeded, params.AvalancheAtomicTxFee)
 		if err != nil {
 			return nil, errOverflowExport
 		}
-		cryftIns, cryftSigners, err = vm.GetSpendableFunds(keys, vm.ctx.CRYFTAssetID, newCryftNeeded)
+		cryftIns, err = vm.getSpendableFunds(addrs, vm.ctx.CRYFTAssetID, newCryftNeeded)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("couldn't generate tx inputs/signers: %w", err)
 	}
 	ins = append(ins, cryftIns...)
-	signers = append(signers, cryftSigners...)
 
 	cryft.SortTransferableOutputs(outs, vm.codec)
-	SortEVMInputsAndSigners(ins, signers)
+	utils.Sort(ins)
 
-	// Create the transaction
-	utx := &UnsignedExportTx{
+	return &UnsignedExportTx{
 		NetworkID:        vm.ctx.NetworkID,
 		BlockchainID:     vm.ctx.ChainID,
 		DestinationChain: chainID,
 		Ins:              ins,
 		ExportedOutputs:  outs,
-	}
-	tx := &Tx{UnsignedAtomicTx: utx}
-	if err := tx.Sign(vm.codec, signers); err != nil {
-		return nil, err
-	}
-	return tx, utx.Verify(vm.ctx, vm.currentRules())
+	}, nil
 }
 
 // EVMStateTransfer executes the state update from the atomic export transaction
@@ -0,0 +1,261 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/shubhamdubey02/cryftgo/utils/constants"
+
+	"github.com/shubhamdubey02/coreth/accounts"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/params"
+	"github.com/shubhamdubey02/coreth/rpc"
+)
+
+// faucetCaptchaTimeout bounds the verification of a captcha response.
+const faucetCaptchaTimeout = 10 * time.Second
+
+var (
+	errFaucetDisabled    = errors.New("faucet is not enabled")
+	errFaucetMainnet     = errors.New("faucet cannot be enabled on mainnet")
+	errFaucetRateLimited = errors.New("faucet request rate limited")
+	errFaucetCaptcha     = errors.New("captcha verification failed")
+)
+
+// faucet sends a fixed amount from a funded account to the addresses
+// requesting it, at most once per interval for each address and IP.
+type faucet struct {
+	vm       *VM
+	address  common.Address
+	sign     func(*types.Transaction) (*types.Transaction, error)
+	amount   *big.Int
+	interval time.Duration
+	now      func() time.Time
+
+	// captchaURL is the siteverify endpoint of the captcha service, and
+	// empty if requests are not verified.
+	captchaURL    string
+	captchaSecret string
+	client        *http.Client
+
+	// lock serializes the requests, so that their nonces are assigned in
+	// order, and protects [drips].
+	lock sync.Mutex
+	// drips is the time of the last drip to each address and IP.
+	drips map[string]time.Time
+}
+
+// startFaucet creates the faucet funded by the configured key or account.
+// The faucet is refused on mainnet.
+func (vm *VM) startFaucet() error {
+	config := vm.config.Faucet
+	if !config.Enabled {
+		return nil
+	}
+	if vm.ctx.NetworkID == constants.MainnetID {
+		return errFaucetMainnet
+	}
+	f := &faucet{
+		vm:       vm,
+		amount:   new(big.Int).Mul(new(big.Int).SetUint64(config.Amount), big.NewInt(params.GWei)),
+		interval: config.Interval.Duration,
+		now:      time.Now,
+		drips:    make(map[string]time.Time),
+
+		captchaURL:    config.CaptchaURL,
+		captchaSecret: config.CaptchaSecret,
+		client:        &http.Client{Timeout: faucetCaptchaTimeout},
+	}
+	chainID := vm.chainConfig.ChainID
+	if config.KeyFile != "" {
+		key, err := crypto.LoadECDSA(config.KeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load faucet key: %w", err)
+		}
+		signer := types.LatestSignerForChainID(chainID)
+		f.address = crypto.PubkeyToAddress(key.PublicKey)
+		f.sign = func(tx *types.Transaction) (*types.Transaction, error) {
+			return types.SignTx(tx, signer, key)
+		}
+	} else {
+		account := accounts.Account{Address: config.Account}
+		wallet, err := vm.eth.AccountManager().Find(account)
+		if err != nil {
+			return fmt.Errorf("failed to find faucet account %s: %w", config.Account, err)
+		}
+		f.address = config.Account
+		f.sign = func(tx *types.Transaction) (*types.Transaction, error) {
+			return wallet.SignTx(account, tx, chainID)
+		}
+	}
+	vm.faucet = f
+	log.Info("Serving faucet", "address", f.address, "amount", f.amount, "interval", f.interval)
+	return nil
+}
+
+// request sends the faucet amount to [to] on behalf of the client at [ip],
+// once [captchaResponse] is verified.
+func (f *faucet) request(ctx context.Context, to common.Address, ip string, captchaResponse string) (common.Hash, error) {
+	if err := f.verifyCaptcha(ctx, ip, captchaResponse); err != nil {
+		return common.Hash{}, err
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	now := f.now()
+	keys := []string{"address:" + to.Hex()}
+	if ip != "" {
+		keys = append(keys, "ip:"+ip)
+	}
+	for _, key := range keys {
+		if last, ok := f.drips[key]; ok && now.Sub(last) < f.interval {
+			return common.Hash{}, fmt.Errorf("%w: retry after %s", errFaucetRateLimited, last.Add(f.interval).Sub(now).Round(time.Second))
+		}
+	}
+
+	hash, err := f.send(ctx, to)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	for key, last := range f.drips {
+		if now.Sub(last) >= f.interval {
+			delete(f.drips, key)
+		}
+	}
+	for _, key := range keys {
+		f.drips[key] = now
+	}
+	return hash, nil
+}
+
+// send signs and issues the transfer of the faucet amount to [to]. Assumes
+// [f.lock] is held.
+func (f *faucet) send(ctx context.Context, to common.Address) (common.Hash, error) {
+	backend := f.vm.eth.APIBackend
+	gasPrice, err := backend.SuggestPrice(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	tx, err := f.sign(types.NewTransaction(f.vm.txPool.Nonce(f.address), to, f.amount, params.TxGas, gasPrice, nil))
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to sign faucet transaction: %w", err)
+	}
+	if err := backend.SendTx(ctx, tx); err != nil {
+		return common.Hash{}, err
+	}
+	log.Debug("Sent faucet transaction", "to", to, "hash", tx.Hash())
+	return tx.Hash(), nil
+}
+
+// verifyCaptcha checks [response] against the configured captcha service,
+// which follows the siteverify protocol of reCAPTCHA and hCaptcha. Requests
+// are not verified if no captcha service is configured.
+func (f *faucet) verifyCaptcha(ctx context.Context, ip string, response string) error {
+	if f.captchaURL == "" {
+		return nil
+	}
+	if response == "" {
+		return fmt.Errorf("%w: missing captcha response", errFaucetCaptcha)
+	}
+	form := url.Values{
+		"secret":   {f.captchaSecret},
+		"response": {response},
+	}
+	if ip != "" {
+		form.Set("remoteip", ip)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.captchaURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to verify captcha: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to verify captcha: %s", resp.Status)
+	}
+	var result struct {
+		Success bool `json:"success"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode captcha verification: %w", err)
+	}
+	if !result.Success {
+		return errFaucetCaptcha
+	}
+	return nil
+}
+
+// FaucetAPI serves the faucet of test networks.
+type FaucetAPI struct {
+	vm *VM
+}
+
+// FaucetStatus describes the faucet.
+type FaucetStatus struct {
+	Address  common.Address `json:"address"`
+	Amount   *hexutil.Big   `json:"amount"`
+	Balance  *hexutil.Big   `json:"balance"`
+	Interval string         `json:"interval"`
+	Captcha  bool           `json:"captcha"`
+}
+
+// Request sends the faucet amount to [address], at most once per interval
+// for each address and client IP. [captchaResponse] is verified if a captcha
+// service is configured.
+func (api *FaucetAPI) Request(ctx context.Context, address common.Address, captchaResponse string) (common.Hash, error) {
+	if api.vm.faucet == nil {
+		return common.Hash{}, errFaucetDisabled
+	}
+	return api.vm.faucet.request(ctx, address, remoteIP(ctx), captchaResponse)
+}
+
+// Status returns the funding account and the limits of the faucet.
+func (api *FaucetAPI) Status(ctx context.Context) (*FaucetStatus, error) {
+	f := api.vm.faucet
+	if f == nil {
+		return nil, errFaucetDisabled
+	}
+	state, err := api.vm.blockChain.State()
+	if err != nil {
+		return nil, err
+	}
+	return &FaucetStatus{
+		Address:  f.address,
+		Amount:   (*hexutil.Big)(f.amount),
+		Balance:  (*hexutil.Big)(state.GetBalance(f.address)),
+		Interval: f.interval.String(),
+		Captcha:  f.captchaURL != "",
+	}, nil
+}
+
+// remoteIP returns the IP of the client of the RPC call in [ctx], or an empty
+// string if it is unknown.
+func remoteIP(ctx context.Context) string {
+	addr := rpc.PeerInfoFromContext(ctx).RemoteAddr
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
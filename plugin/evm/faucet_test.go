@@ -0,0 +1,84 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shubhamdubey02/coreth/params"
+)
+
+func TestFaucet(t *testing.T) {
+	require := require.New(t)
+
+	keyFile := filepath.Join(t.TempDir(), "faucet.key")
+	require.NoError(crypto.SaveECDSA(keyFile, HarnessFunderKey))
+	captcha := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(r.ParseForm())
+		success := r.PostForm.Get("secret") == "secret" && r.PostForm.Get("response") == "solved"
+		fmt.Fprintf(w, `{"success": %t}`, success)
+	}))
+	defer captcha.Close()
+
+	h := NewTestHarness(t, TestHarnessConfig{
+		Config: fmt.Sprintf(`{"faucet": {"enabled": true, "key-file": %q, "amount": 5, "interval": "1h", "captcha-url": %q, "captcha-secret": "secret"}}`, keyFile, captcha.URL),
+	})
+	var (
+		api    = &FaucetAPI{h.VM}
+		ctx    = context.Background()
+		now    = time.Now()
+		to     = common.Address{0xaa}
+		amount = big.NewInt(5 * params.GWei)
+	)
+	h.VM.faucet.now = func() time.Time { return now }
+
+	status, err := api.Status(ctx)
+	require.NoError(err)
+	require.Equal(HarnessFunderAddress, status.Address)
+	require.Equal(amount, status.Amount.ToInt())
+	require.True(status.Captcha)
+
+	// Requests are only served once their captcha is solved.
+	_, err = api.Request(ctx, to, "")
+	require.ErrorIs(err, errFaucetCaptcha)
+	_, err = api.Request(ctx, to, "unsolved")
+	require.ErrorIs(err, errFaucetCaptcha)
+
+	hash, err := api.Request(ctx, to, "solved")
+	require.NoError(err)
+	require.True(h.VM.txPool.Has(hash))
+	h.BuildAndAccept()
+	require.Equal(amount, h.Balance(to))
+
+	// Each address is served once per interval.
+	_, err = api.Request(ctx, to, "solved")
+	require.ErrorIs(err, errFaucetRateLimited)
+	now = now.Add(time.Hour)
+	// The faucet prices its transfers with the gas price oracle, which
+	// estimates the base fee at the wall clock time.
+	h.SetTime(time.Now())
+	_, err = api.Request(ctx, to, "solved")
+	require.NoError(err)
+	h.BuildAndAccept()
+	require.Equal(new(big.Int).Mul(amount, big.NewInt(2)), h.Balance(to))
+
+	// Each IP is served once per interval.
+	_, err = h.VM.faucet.request(ctx, common.Address{0xbb}, "127.0.0.1", "solved")
+	require.NoError(err)
+	_, err = h.VM.faucet.request(ctx, common.Address{0xcc}, "127.0.0.1", "solved")
+	require.ErrorIs(err, errFaucetRateLimited)
+	_, err = h.VM.faucet.request(ctx, common.Address{0xcc}, "127.0.0.2", "solved")
+	require.NoError(err)
+}
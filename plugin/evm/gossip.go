@@ -5,6 +5,7 @@ package evm
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"sync/atomic"
@@ -131,6 +132,11 @@ type GossipEthTxPool struct {
 	mempool    *txpool.TxPool
 	pendingTxs chan core.NewTxsEvent
 
+	// private holds the transactions that are never gossiped.
+	private *privateTxPool
+	// lifecycle records the transactions received from gossip.
+	lifecycle *txLifecycle
+
 	bloom *gossip.BloomFilter
 	lock  sync.RWMutex
 
@@ -165,6 +171,9 @@ func (g *GossipEthTxPool) Subscribe(ctx context.Context) {
 			g.lock.Lock()
 			optimalElements := (g.mempool.PendingSize(false) + len(pendingTxs.Txs)) * txGossipBloomChurnMultiplier
 			for _, pendingTx := range pendingTxs.Txs {
+				if !g.gossipable(pendingTx.Hash()) {
+					continue
+				}
 				tx := &GossipEthTx{Tx: pendingTx}
 				g.bloom.Add(tx)
 				reset, err := gossip.ResetBloomFilterIfNeeded(g.bloom, optimalElements)
@@ -177,7 +186,9 @@ func (g *GossipEthTxPool) Subscribe(ctx context.Context) {
 					log.Debug("resetting bloom filter", "reason", "reached max filled ratio")
 
 					g.mempool.IteratePending(func(tx *types.Transaction) bool {
-						g.bloom.Add(&GossipEthTx{Tx: tx})
+						if g.gossipable(tx.Hash()) {
+							g.bloom.Add(&GossipEthTx{Tx: tx})
+						}
 						return true
 					})
 				}
@@ -190,7 +201,15 @@ func (g *GossipEthTxPool) Subscribe(ctx context.Context) {
 // Add enqueues the transaction to the mempool. Subscribe should be called
 // to receive an event if tx is actually added to the mempool or not.
 func (g *GossipEthTxPool) Add(tx *GossipEthTx) error {
-	return g.mempool.Add([]*types.Transaction{tx.Tx}, false, false)[0]
+	err := g.mempool.Add([]*types.Transaction{tx.Tx}, false, false)[0]
+	switch {
+	case errors.Is(err, txpool.ErrAlreadyKnown):
+	case err != nil:
+		g.lifecycle.record(tx.Tx.Hash(), TxLifecycleEvent{Event: TxLifecycleGossip, Error: err.Error()})
+	default:
+		g.lifecycle.record(tx.Tx.Hash(), TxLifecycleEvent{Event: TxLifecycleGossip})
+	}
+	return err
 }
 
 // Has should just return whether or not the [txID] is still in the mempool,
@@ -199,12 +218,23 @@ func (g *GossipEthTxPool) Has(txID ids.ID) bool {
 	return g.mempool.Has(common.Hash(txID))
 }
 
+// Iterate calls [f] on the pending transactions that are not private or
+// sponsored.
 func (g *GossipEthTxPool) Iterate(f func(tx *GossipEthTx) bool) {
 	g.mempool.IteratePending(func(tx *types.Transaction) bool {
+		if !g.gossipable(tx.Hash()) {
+			return true
+		}
 		return f(&GossipEthTx{Tx: tx})
 	})
 }
 
+// gossipable returns false for private transactions, and for sponsored
+// transactions which peers not consulting the same sponsor would reject.
+func (g *GossipEthTxPool) gossipable(hash common.Hash) bool {
+	return !g.private.IsPrivate(hash) && !g.mempool.IsSponsored(hash)
+}
+
 func (g *GossipEthTxPool) GetFilter() ([]byte, []byte) {
 	g.lock.RLock()
 	defer g.lock.RUnlock()
@@ -243,8 +273,9 @@ type EthPushGossiper struct {
 func (e *EthPushGossiper) Add(tx *types.Transaction) {
 	// eth.Backend is initialized before the [ethTxPushGossiper] is created, so
 	// we just ignore any gossip requests until it is set.
+	e.vm.txLifecycle.record(tx.Hash(), TxLifecycleEvent{Event: TxLifecycleRPC})
 	ethTxPushGossiper := e.vm.ethTxPushGossiper.Get()
-	if ethTxPushGossiper == nil {
+	if ethTxPushGossiper == nil || e.vm.txPool.IsSponsored(tx.Hash()) {
 		return
 	}
 	ethTxPushGossiper.Add(&GossipEthTx{tx})
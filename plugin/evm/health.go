@@ -9,6 +9,12 @@ import "context"
 // Also returns details, which should be one of:
 // string, []byte, map[string]string
 func (vm *VM) HealthCheck(context.Context) (interface{}, error) {
-	// TODO perform actual health check
+	if vm.watchdog == nil {
+		return nil, nil
+	}
+	details, err := vm.watchdog.health()
+	if err != nil {
+		return details, err
+	}
 	return nil, nil
 }
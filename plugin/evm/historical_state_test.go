@@ -0,0 +1,70 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shubhamdubey02/coreth/rpc"
+)
+
+func TestHistoricalStateRegeneration(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      string
+		expectedErr string
+	}{
+		{
+			name:        "disabled",
+			config:      `{"pruning-enabled": true}`,
+			expectedErr: "missing trie node",
+		},
+		{
+			name:        "effort bound exceeded",
+			config:      `{"pruning-enabled": true, "historical-state-max-reexec": 2}`,
+			expectedErr: "historical state unavailable",
+		},
+		{
+			name:   "regenerated",
+			config: `{"pruning-enabled": true, "historical-state-max-reexec": 64}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			h := NewTestHarness(t, TestHarnessConfig{Config: test.config})
+			recipient := common.Address{0xaa}
+			// Build past the buffer of recent states kept in memory, so that
+			// the state of the first block is pruned.
+			for i := 0; i < 40; i++ {
+				h.AdvanceTime(10 * time.Second)
+				h.Fund(recipient, big.NewInt(1))
+				h.BuildAndAccept()
+			}
+
+			backend := h.VM.eth.APIBackend
+			statedb, _, release, err := backend.StateAndHeaderByNumber(context.Background(), rpc.BlockNumber(5))
+			if test.expectedErr != "" {
+				require.ErrorContains(err, test.expectedErr)
+				return
+			}
+			require.NoError(err)
+			defer release()
+			require.Equal(big.NewInt(5), statedb.GetBalance(recipient))
+
+			// Later blocks are regenerated from the cached state.
+			statedb, _, release, err = backend.StateAndHeaderByNumber(context.Background(), rpc.BlockNumber(6))
+			require.NoError(err)
+			defer release()
+			require.Equal(big.NewInt(6), statedb.GetBalance(recipient))
+		})
+	}
+}
@@ -305,7 +305,6 @@ func (vm *VM) newImportTxWithUTXOs(
 	importedInputs := []*cryft.TransferableInput{}
 	signers := [][]*secp256k1.PrivateKey{}
 
-	importedAmount := make(map[ids.ID]uint64)
 	now := vm.clock.Unix()
 	for _, utxo := range atomicUTXOs {
 		inputIntf, utxoSigners, err := kc.Spend(utxo.Out, now)
@@ -316,11 +315,6 @@ func (vm *VM) newImportTxWithUTXOs(
 		if !ok {
 			continue
 		}
-		aid := utxo.AssetID()
-		importedAmount[aid], err = math.Add64(importedAmount[aid], input.Amount())
-		if err != nil {
-			return nil, err
-		}
 		importedInputs = append(importedInputs, &cryft.TransferableInput{
 			UTXOID: utxo.UTXOID,
 			Asset:  utxo.Asset,
@@ -329,6 +323,89 @@ func (vm *VM) newImportTxWithUTXOs(
 		signers = append(signers, utxoSigners)
 	}
 	cryft.SortTransferableInputsWithSigners(importedInputs, signers)
+
+	utx, err := vm.newUnsignedImportTxWithInputs(chainID, to, baseFee, importedInputs)
+	if err != nil {
+		return nil, err
+	}
+	tx := &Tx{UnsignedAtomicTx: utx}
+	if err := tx.Sign(vm.codec, signers); err != nil {
+		return nil, err
+	}
+	return tx, utx.Verify(vm.ctx, vm.currentRules())
+}
+
+// newUnsignedImportTx returns a new ImportTx spending the atomic UTXOs of
+// [chainID] that [addrs] can spend without signing it
+func (vm *VM) newUnsignedImportTx(
+	chainID ids.ID, // chain to import from
+	to common.Address, // Address of recipient
+	baseFee *big.Int, // fee to use post-AP3
+	addrs set.Set[ids.ShortID], // Addresses owning the funds to import
+) (*UnsignedImportTx, error) {
+	atomicUTXOs, _, _, err := vm.GetAtomicUTXOs(chainID, addrs, ids.ShortEmpty, ids.Empty, -1)
+	if err != nil {
+		return nil, fmt.Errorf("problem retrieving atomic UTXOs: %w", err)
+	}
+
+	importedInputs := []*cryft.TransferableInput{}
+	now := vm.clock.Unix()
+	for _, utxo := range atomicUTXOs {
+		out, ok := utxo.Out.(*secp256k1fx.TransferOutput)
+		if !ok {
+			continue
+		}
+		sigIndices, ok := matchOwners(&out.OutputOwners, addrs, now)
+		if !ok {
+			continue
+		}
+		importedInputs = append(importedInputs, &cryft.TransferableInput{
+			UTXOID: utxo.UTXOID,
+			Asset:  utxo.Asset,
+			In: &secp256k1fx.TransferInput{
+				Amt:   out.Amt,
+				Input: secp256k1fx.Input{SigIndices: sigIndices},
+			},
+		})
+	}
+	utils.Sort(importedInputs)
+
+	return vm.newUnsignedImportTxWithInputs(chainID, to, baseFee, importedInputs)
+}
+
+// matchOwners returns the indices of the addresses of [owners] that are in
+// [addrs], up to the threshold of [owners]. Returns false if [addrs] cannot
+// spend an output owned by [owners] at [time].
+func matchOwners(owners *secp256k1fx.OutputOwners, addrs set.Set[ids.ShortID], time uint64) ([]uint32, bool) {
+	if time < owners.Locktime {
+		return nil, false
+	}
+	sigIndices := make([]uint32, 0, owners.Threshold)
+	for i := uint32(0); i < uint32(len(owners.Addrs)) && uint32(len(sigIndices)) < owners.Threshold; i++ {
+		if addrs.Contains(owners.Addrs[i]) {
+			sigIndices = append(sigIndices, i)
+		}
+	}
+	return sigIndices, uint32(len(sigIndices)) == owners.Threshold
+}
+
+// newUnsignedImportTxWithInputs returns a new ImportTx spending the sorted
+// [importedInputs] to [to], after paying the fee at [baseFee]
+func (vm *VM) newUnsignedImportTxWithInputs(
+	chainID ids.ID, // chain to import from
+	to common.Address, // Address of recipient
+	baseFee *big.Int, // fee to use post-AP3
+	importedInputs []*cryft.TransferableInput, // Inputs to import
+) (*UnsignedImportTx, error) {
+	importedAmount := make(map[ids.ID]uint64)
+	for _, input := range importedInputs {
+		aid := input.AssetID()
+		var err error
+		importedAmount[aid], err = math.Add64(importedAmount[aid], input.Input().Amount())
+		if err != nil {
+			return nil, err
+		}
+	}
 	importedCRYFTAmount := importedAmount[vm.ctx.CRYFTAssetID]
 
 	outs := make([]EVMOutput, 0, len(importedAmount))
@@ -411,19 +488,13 @@ func (vm *VM) newImportTxWithUTXOs(
 
 	utils.Sort(outs)
 
-	// Create the transaction
-	utx := &UnsignedImportTx{
+	return &UnsignedImportTx{
 		NetworkID:      vm.ctx.NetworkID,
 		BlockchainID:   vm.ctx.ChainID,
 		Outs:           outs,
 		ImportedInputs: importedInputs,
 		SourceChain:    chainID,
-	}
-	tx := &Tx{UnsignedAtomicTx: utx}
-	if err := tx.Sign(vm.codec, signers); err != nil {
-		return nil, err
-	}
-	return tx, utx.Verify(vm.ctx, vm.currentRules())
+	}, nil
 }
 
 // EVMStateTransfer performs the state transfer to increase the balances of
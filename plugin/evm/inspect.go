@@ -0,0 +1,158 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shubhamdubey02/coreth/core"
+	"github.com/shubhamdubey02/coreth/core/rawdb"
+	"github.com/shubhamdubey02/coreth/params"
+	"github.com/shubhamdubey02/coreth/rpc/errcodes"
+	"github.com/shubhamdubey02/cryftgo/database"
+	"github.com/shubhamdubey02/cryftgo/database/prefixdb"
+)
+
+var errInvalidHeightRange = errcodes.New(errcodes.InvalidArgument, "invalid height range")
+
+// vmDatabaseFamilies are the key families stored by the VM outside of the
+// chain database, reported by [VM.inspectDatabase].
+var vmDatabaseFamilies = []struct {
+	database string
+	category string
+	prefix   []byte
+}{
+	{"Atomic", "Atomic trie nodes", atomicTrieDBPrefix},
+	{"Atomic", "Atomic trie metadata", atomicTrieMetaDBPrefix},
+	{"Atomic", "Atomic txs by ID", atomicTxIDDBPrefix},
+	{"Atomic", "Atomic txs by height", atomicHeightTxDBPrefix},
+	{"Atomic", "Atomic repository metadata", atomicRepoMetadataDBPrefix},
+	{"VM", "Accepted block IDs", acceptedPrefix},
+	{"VM", "Metadata", metadataPrefix},
+}
+
+// inspectDatabase returns the size of each family of keys stored by the VM,
+// including the chain database.
+func (vm *VM) inspectDatabase() (*rawdb.DatabaseInspection, error) {
+	inspection, err := rawdb.InspectDatabaseStats(vm.chaindb, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, family := range vmDatabaseFamilies {
+		stat, err := inspectPrefix(vm.db, family.prefix)
+		if err != nil {
+			return nil, fmt.Errorf("failed to inspect %s: %w", family.category, err)
+		}
+		stat.Database = family.database
+		stat.Category = family.category
+		inspection.Stats = append(inspection.Stats, stat)
+		inspection.Total += stat.Size
+	}
+	return inspection, nil
+}
+
+// inspectPrefix returns the size and number of entries under [prefix] in [db].
+func inspectPrefix(db database.Database, prefix []byte) (rawdb.DatabaseStat, error) {
+	it := prefixdb.New(prefix, db).NewIterator()
+	defer it.Release()
+
+	var stat rawdb.DatabaseStat
+	for it.Next() {
+		stat.Size += common.StorageSize(len(prefix) + len(it.Key()) + len(it.Value()))
+		stat.Count++
+	}
+	return stat, it.Error()
+}
+
+// verifyAcceptedChain checks the continuity of the accepted chain index from
+// [start] to [end] and counts the block data orphaned below the last accepted
+// block. Heights whose bodies or receipts were deleted by the retention
+// policy are skipped.
+func (vm *VM) verifyAcceptedChain(start, end uint64) ([]error, *rawdb.OrphanedBlockData, error) {
+	lastAccepted := vm.blockChain.LastAcceptedBlock().NumberU64()
+	if end > lastAccepted {
+		return nil, nil, fmt.Errorf("%w: end height %d is above the last accepted height %d", errInvalidHeightRange, end, lastAccepted)
+	}
+	for _, class := range []string{retentionBodies, retentionReceipts} {
+		tail, err := rawdb.ReadRetentionTail(vm.chaindb, class)
+		if err != nil {
+			return nil, nil, err
+		}
+		if tail != nil && *tail > start {
+			start = min(*tail, end)
+		}
+	}
+	if start > end {
+		return nil, nil, fmt.Errorf("%w: start height %d is above end height %d", errInvalidHeightRange, start, end)
+	}
+	errs := rawdb.VerifyCanonicalChain(vm.chaindb, start, end)
+	orphaned, err := rawdb.FindOrphanedBlockData(vm.chaindb, lastAccepted)
+	if err != nil {
+		return nil, nil, err
+	}
+	return errs, orphaned, nil
+}
+
+// IndexRepairReport describes the transaction lookup entries and bloom bits
+// sections checked by [VM.repairIndexes].
+type IndexRepairReport struct {
+	Start   uint64               `json:"start"`
+	End     uint64               `json:"end"`
+	TxIndex *rawdb.TxIndexReport `json:"txIndex"`
+	// BloomSections is the number of bloom bits sections checked, and
+	// InconsistentBloomSections lists those that differed from the headers.
+	BloomSections             uint64   `json:"bloomSections"`
+	InconsistentBloomSections []uint64 `json:"inconsistentBloomSections"`
+	Repaired                  bool     `json:"repaired"`
+}
+
+// repairIndexes checks the transaction lookup entries of the accepted blocks
+// from [start] to [end], and the indexed bloom bits sections overlapping
+// them, rewriting the inconsistent entries and sections if [repair] is set.
+// Heights below the transaction index tail, or whose bodies were deleted by
+// the retention policy, are skipped.
+func (vm *VM) repairIndexes(start, end uint64, repair bool) (*IndexRepairReport, error) {
+	lastAccepted := vm.blockChain.LastAcceptedBlock().NumberU64()
+	if end > lastAccepted {
+		return nil, fmt.Errorf("%w: end height %d is above the last accepted height %d", errInvalidHeightRange, end, lastAccepted)
+	}
+	if start > end {
+		return nil, fmt.Errorf("%w: start height %d is above end height %d", errInvalidHeightRange, start, end)
+	}
+	report := &IndexRepairReport{Start: start, End: end, Repaired: repair}
+
+	sections, _, _ := vm.eth.BloomIndexer().Sections()
+	for section := start / params.BloomBitsBlocks; section <= end/params.BloomBitsBlocks && section < sections; section++ {
+		consistent, err := core.RepairBloomBits(vm.chaindb, params.BloomBitsBlocks, section, repair)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check bloom bits section %d: %w", section, err)
+		}
+		report.BloomSections++
+		if !consistent {
+			report.InconsistentBloomSections = append(report.InconsistentBloomSections, section)
+		}
+	}
+
+	txStart := start
+	if tail := rawdb.ReadTxIndexTail(vm.chaindb); tail != nil && *tail > txStart {
+		txStart = *tail
+	}
+	bodiesTail, err := rawdb.ReadRetentionTail(vm.chaindb, retentionBodies)
+	if err != nil {
+		return nil, err
+	}
+	if bodiesTail != nil && *bodiesTail > txStart {
+		txStart = *bodiesTail
+	}
+	if txStart > end {
+		report.TxIndex = &rawdb.TxIndexReport{}
+		return report, nil
+	}
+	report.TxIndex, err = rawdb.RepairTxIndex(vm.chaindb, txStart, end, repair)
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
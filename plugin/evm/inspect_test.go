@@ -0,0 +1,76 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shubhamdubey02/coreth/core/rawdb"
+)
+
+func TestInspectDatabase(t *testing.T) {
+	require := require.New(t)
+
+	_, vm, _, _, _ := GenesisVM(t, true, "", "", "")
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	inspection, err := vm.inspectDatabase()
+	require.NoError(err)
+	categories := make(map[string]rawdb.DatabaseStat)
+	var total uint64
+	for _, stat := range inspection.Stats {
+		categories[stat.Category] = stat
+		total += uint64(stat.Size)
+	}
+	require.EqualValues(1, categories["Headers"].Count)
+	require.Contains(categories, "Atomic trie nodes")
+	require.Contains(categories, "Accepted block IDs")
+	require.Equal(total+uint64(inspection.Unaccounted.Size), uint64(inspection.Total))
+
+	errs, orphaned, err := vm.verifyAcceptedChain(0, 0)
+	require.NoError(err)
+	require.Empty(errs)
+	require.Equal(rawdb.OrphanedBlockData{}, *orphaned)
+
+	_, _, err = vm.verifyAcceptedChain(0, 1)
+	require.ErrorContains(err, "above the last accepted height")
+}
+
+func TestRepairIndexes(t *testing.T) {
+	require := require.New(t)
+
+	h := NewTestHarness(t, TestHarnessConfig{})
+	tx := h.Fund(common.Address{0xaa}, big.NewInt(1))
+	h.BuildAndAccept()
+	h.VM.blockChain.DrainAcceptorQueue()
+
+	report, err := h.VM.repairIndexes(0, 1, false)
+	require.NoError(err)
+	require.EqualValues(2, report.TxIndex.Blocks)
+	require.EqualValues(1, report.TxIndex.Transactions)
+	require.Zero(report.TxIndex.Missing)
+
+	rawdb.DeleteTxLookupEntry(h.VM.chaindb, tx.Hash())
+	report, err = h.VM.repairIndexes(0, 1, false)
+	require.NoError(err)
+	require.EqualValues(1, report.TxIndex.Missing)
+	require.Nil(rawdb.ReadTxLookupEntry(h.VM.chaindb, tx.Hash()))
+
+	report, err = h.VM.repairIndexes(0, 1, true)
+	require.NoError(err)
+	require.EqualValues(1, report.TxIndex.Repaired)
+	entry := rawdb.ReadTxLookupEntry(h.VM.chaindb, tx.Hash())
+	require.NotNil(entry)
+	require.EqualValues(1, *entry)
+
+	_, err = h.VM.repairIndexes(0, 2, false)
+	require.ErrorContains(err, "above the last accepted height")
+}
@@ -8,23 +8,46 @@ import (
 	"fmt"
 	"io"
 	"runtime"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/shubhamdubey02/coreth/log"
 	gethlog "github.com/ethereum/go-ethereum/log"
 	"golang.org/x/exp/slog"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
+// corethPackagePrefix prefixes the names of functions in this module.
+const corethPackagePrefix = "github.com/shubhamdubey02/coreth/"
+
+// logModules maps the modules whose log level can be set independently of
+// the global log level to the packages they cover. A package covers its
+// subpackages.
+var logModules = map[string][]string{
+	"miner":  {"miner"},
+	"sync":   {"sync"},
+	"peer":   {"peer"},
+	"txpool": {"core/txpool"},
+	"rpc":    {"rpc", "internal/ethapi"},
+}
+
 type CorethLogger struct {
 	gethlog.Logger
 
-	logLevel *slog.LevelVar
+	levels *moduleLevels
 }
 
 // InitLogger initializes logger with alias and sets the log level and format with the original [os.StdErr] interface
 // along with the context logger.
 func InitLogger(alias string, level string, jsonFormat bool, writer io.Writer) (CorethLogger, error) {
+	// The handler is passed the lowest level of any module, and records are
+	// then filtered by the level of the module logging them.
 	logLevel := &slog.LevelVar{}
+	levels := &moduleLevels{
+		handlerLevel: logLevel,
+		modules:      make(map[string]slog.Level),
+	}
 
 	var handler slog.Handler
 	if jsonFormat {
@@ -44,11 +67,12 @@ func InitLogger(alias string, level string, jsonFormat bool, writer io.Writer) (
 		}
 		handler = termHandler
 	}
+	handler = &moduleLevelHandler{Handler: handler, levels: levels}
 
 	// Create handler
 	c := CorethLogger{
-		Logger:   gethlog.NewLogger(handler),
-		logLevel: logLevel,
+		Logger: gethlog.NewLogger(handler),
+		levels: levels,
 	}
 
 	if err := c.SetLogLevel(level); err != nil {
@@ -58,17 +82,193 @@ func InitLogger(alias string, level string, jsonFormat bool, writer io.Writer) (
 	return c, nil
 }
 
-// SetLogLevel sets the log level of initialized log handler.
+// SetLogLevel sets the log level of initialized log handler. Modules with a
+// log level of their own are not affected.
 func (c *CorethLogger) SetLogLevel(level string) error {
 	// Set log level
 	logLevel, err := log.LvlFromString(level)
 	if err != nil {
 		return err
 	}
-	c.logLevel.Set(logLevel)
+	c.levels.setGlobal(logLevel)
 	return nil
 }
 
+// SetModuleLogLevel sets the log level of [module], overriding the global
+// log level. An empty [level] reverts [module] to the global log level.
+func (c *CorethLogger) SetModuleLogLevel(module string, level string) error {
+	if _, ok := logModules[module]; !ok {
+		return fmt.Errorf("unknown log module %q, expected one of %s", module, strings.Join(LogModules(), ", "))
+	}
+	if level == "" {
+		c.levels.clearModule(module)
+		return nil
+	}
+	logLevel, err := log.LvlFromString(level)
+	if err != nil {
+		return err
+	}
+	c.levels.setModule(module, logLevel)
+	return nil
+}
+
+// LogLevels returns the global log level and the log levels of modules that
+// override it.
+func (c *CorethLogger) LogLevels() (string, map[string]string) {
+	return c.levels.get()
+}
+
+// LogModules returns the sorted names of the modules whose log level can be
+// set independently.
+func LogModules() []string {
+	modules := make([]string, 0, len(logModules))
+	for module := range logModules {
+		modules = append(modules, module)
+	}
+	sort.Strings(modules)
+	return modules
+}
+
+// newLogFileWriter returns a writer appending to [config.LogFile], rotating
+// the file once it exceeds [config.LogMaxSize] megabytes.
+func newLogFileWriter(config *Config) *lumberjack.Logger {
+	return &lumberjack.Logger{
+		Filename:   config.LogFile,
+		MaxSize:    config.LogMaxSize,
+		MaxBackups: config.LogMaxBackups,
+		MaxAge:     config.LogMaxAge,
+		Compress:   config.LogCompress,
+	}
+}
+
+// moduleLevels tracks the global log level and the log levels of modules
+// that override it.
+type moduleLevels struct {
+	// handlerLevel is the lowest level of any module, below which records
+	// are discarded before being created.
+	handlerLevel *slog.LevelVar
+
+	lock    sync.RWMutex
+	global  slog.Level
+	modules map[string]slog.Level
+
+	// pcModules caches the module of each logging call site.
+	pcModules sync.Map // uintptr -> string
+}
+
+func (m *moduleLevels) setGlobal(level slog.Level) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.global = level
+	m.updateHandlerLevel()
+}
+
+func (m *moduleLevels) setModule(module string, level slog.Level) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.modules[module] = level
+	m.updateHandlerLevel()
+}
+
+func (m *moduleLevels) clearModule(module string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	delete(m.modules, module)
+	m.updateHandlerLevel()
+}
+
+// updateHandlerLevel assumes the lock is held.
+func (m *moduleLevels) updateHandlerLevel() {
+	lowest := m.global
+	for _, level := range m.modules {
+		if level < lowest {
+			lowest = level
+		}
+	}
+	m.handlerLevel.Set(lowest)
+}
+
+func (m *moduleLevels) get() (string, map[string]string) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	modules := make(map[string]string, len(m.modules))
+	for module, level := range m.modules {
+		modules[module] = log.LevelString(level)
+	}
+	return log.LevelString(m.global), modules
+}
+
+// enabled returns true if a record logged at [level] from [pc] should be
+// handled.
+func (m *moduleLevels) enabled(pc uintptr, level slog.Level) bool {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	if len(m.modules) == 0 {
+		return level >= m.global
+	}
+	if moduleLevel, ok := m.modules[m.module(pc)]; ok {
+		return level >= moduleLevel
+	}
+	return level >= m.global
+}
+
+// module returns the module of the function containing [pc], or the empty
+// string if it is not part of a module.
+func (m *moduleLevels) module(pc uintptr) string {
+	if module, ok := m.pcModules.Load(pc); ok {
+		return module.(string)
+	}
+	frames := runtime.CallersFrames([]uintptr{pc})
+	frame, _ := frames.Next()
+	module := moduleOf(frame.Function)
+	m.pcModules.Store(pc, module)
+	return module
+}
+
+// moduleOf returns the module of [function], a fully qualified function name.
+func moduleOf(function string) string {
+	function, ok := strings.CutPrefix(function, corethPackagePrefix)
+	if !ok {
+		return ""
+	}
+	for module, packages := range logModules {
+		for _, pkg := range packages {
+			if rest, ok := strings.CutPrefix(function, pkg); ok && (strings.HasPrefix(rest, ".") || strings.HasPrefix(rest, "/")) {
+				return module
+			}
+		}
+	}
+	return ""
+}
+
+// moduleLevelHandler discards records below the log level of the module
+// logging them.
+type moduleLevelHandler struct {
+	slog.Handler
+
+	levels *moduleLevels
+}
+
+func (h *moduleLevelHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !h.levels.enabled(r.PC, r.Level) {
+		return nil
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *moduleLevelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &moduleLevelHandler{Handler: h.Handler.WithAttrs(attrs), levels: h.levels}
+}
+
+func (h *moduleLevelHandler) WithGroup(name string) slog.Handler {
+	return &moduleLevelHandler{Handler: h.Handler.WithGroup(name), levels: h.levels}
+}
+
 // locationTrims are trimmed for display to avoid unwieldy log lines.
 var locationTrims = []string{
 	"coreth/",
@@ -0,0 +1,50 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+func TestModuleOf(t *testing.T) {
+	tests := map[string]string{
+		"github.com/shubhamdubey02/coreth/miner.(*worker).commitNewWork":            "miner",
+		"github.com/shubhamdubey02/coreth/sync/statesync.(*stateSync).Start":        "sync",
+		"github.com/shubhamdubey02/coreth/core/txpool/legacypool.(*LegacyPool).add": "txpool",
+		"github.com/shubhamdubey02/coreth/internal/ethapi.(*BlockChainAPI).Call":    "rpc",
+		"github.com/shubhamdubey02/coreth/rpc.(*Server).serveRequest":               "rpc",
+		"github.com/shubhamdubey02/coreth/rpcx.Foo":                                 "",
+		"github.com/shubhamdubey02/coreth/core.(*BlockChain).insertBlock":           "",
+		"github.com/ethereum/go-ethereum/miner.Foo":                                 "",
+	}
+	for function, expected := range tests {
+		require.Equal(t, expected, moduleOf(function), function)
+	}
+}
+
+func TestModuleLogLevels(t *testing.T) {
+	require := require.New(t)
+
+	logger, err := InitLogger("C", "info", false, io.Discard)
+	require.NoError(err)
+	require.NoError(logger.SetModuleLogLevel("miner", "debug"))
+	require.NoError(logger.SetModuleLogLevel("rpc", "error"))
+	require.Error(logger.SetModuleLogLevel("unknown", "debug"))
+	require.Error(logger.SetModuleLogLevel("sync", "verbose"))
+
+	level, modules := logger.LogLevels()
+	require.Equal("info", level)
+	require.Equal(map[string]string{"miner": "debug", "rpc": "eror"}, modules)
+	// The handler must create records for the most verbose module.
+	require.Equal(slog.LevelDebug, logger.levels.handlerLevel.Level())
+
+	require.NoError(logger.SetModuleLogLevel("miner", ""))
+	_, modules = logger.LogLevels()
+	require.Equal(map[string]string{"rpc": "eror"}, modules)
+	require.Equal(slog.LevelInfo, logger.levels.handlerLevel.Level())
+}
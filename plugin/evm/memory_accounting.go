@@ -0,0 +1,252 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/metrics"
+	"github.com/shubhamdubey02/coreth/rpc/errcodes"
+	"github.com/shubhamdubey02/cryftgo/utils/units"
+)
+
+const (
+	memoryTxPool    = "txpool"
+	memoryCaches    = "caches"
+	memoryStateSync = "state-sync"
+	memoryFilters   = "filters"
+
+	// memoryShedTarget is the percentage of its budget a subsystem is shed
+	// down to, so that it is not shed again as soon as it grows.
+	memoryShedTarget = 90
+)
+
+var errMemoryBudgetDisabled = errcodes.New(errcodes.FeatureDisabled, "memory budget is not enabled")
+
+// memorySubsystem reports the memory used by a subsystem of the VM, and
+// sheds memory from it when it exceeds its budget.
+type memorySubsystem struct {
+	name string
+	// budget is the soft budget in bytes, or 0 if the subsystem is unbounded.
+	budget uint64
+	// usage returns the bytes used by the subsystem.
+	usage func() uint64
+	// shed reduces the memory used by the subsystem to about [target] bytes.
+	// It is nil for subsystems bounding their own usage.
+	shed func(target uint64)
+
+	usageGauge  metrics.Gauge
+	budgetGauge metrics.Gauge
+	shedCount   metrics.Counter
+}
+
+func newMemorySubsystem(name string, budget uint64, usage func() uint64, shed func(target uint64)) *memorySubsystem {
+	s := &memorySubsystem{
+		name:        name,
+		budget:      budget,
+		usage:       usage,
+		shed:        shed,
+		usageGauge:  metrics.GetOrRegisterGauge("memory_"+name+"_usage", nil),
+		budgetGauge: metrics.GetOrRegisterGauge("memory_"+name+"_budget", nil),
+		shedCount:   metrics.GetOrRegisterCounter("memory_"+name+"_shed", nil),
+	}
+	s.budgetGauge.Update(int64(budget))
+	return s
+}
+
+// MemoryUsage is the memory used by a subsystem.
+type MemoryUsage struct {
+	// Usage and Budget are in bytes. A Budget of 0 leaves the subsystem
+	// unbounded.
+	Usage  uint64 `json:"usage"`
+	Budget uint64 `json:"budget"`
+	// Shed is the number of times memory was shed from the subsystem.
+	Shed int64 `json:"shed"`
+}
+
+// memoryAccountant measures the memory used by each subsystem and sheds
+// memory from those exceeding their budget.
+type memoryAccountant struct {
+	subsystems []*memorySubsystem
+}
+
+// check measures the memory used by each subsystem, and sheds memory from
+// the subsystems exceeding their budget.
+func (a *memoryAccountant) check() {
+	for _, s := range a.subsystems {
+		usage := s.usage()
+		s.usageGauge.Update(int64(usage))
+		if s.budget == 0 || usage <= s.budget || s.shed == nil {
+			continue
+		}
+		target := s.budget * memoryShedTarget / 100
+		start := time.Now()
+		s.shed(target)
+		s.shedCount.Inc(1)
+		shedUsage := s.usage()
+		s.usageGauge.Update(int64(shedUsage))
+		log.Warn("Shed memory over budget",
+			"subsystem", s.name,
+			"budget", common.StorageSize(s.budget),
+			"usage", common.StorageSize(usage),
+			"shedUsage", common.StorageSize(shedUsage),
+			"elapsed", time.Since(start),
+		)
+	}
+}
+
+// report returns the memory used by each subsystem.
+func (a *memoryAccountant) report() map[string]MemoryUsage {
+	report := make(map[string]MemoryUsage, len(a.subsystems))
+	for _, s := range a.subsystems {
+		report[s.name] = MemoryUsage{
+			Usage:  s.usage(),
+			Budget: s.budget,
+			Shed:   s.shedCount.Snapshot().Count(),
+		}
+	}
+	return report
+}
+
+// txPoolMemoryUsage returns the bytes of the transactions held by the tx
+// pool.
+func (vm *VM) txPoolMemoryUsage() uint64 {
+	var usage uint64
+	pending, queued := vm.txPool.Content()
+	for _, txs := range []map[common.Address][]*types.Transaction{pending, queued} {
+		for _, accountTxs := range txs {
+			for _, tx := range accountTxs {
+				usage += tx.Size()
+			}
+		}
+	}
+	return usage
+}
+
+// shedTxPool drops remote transactions until the transactions of the tx pool
+// use at most [target] bytes. Queued transactions are dropped before pending
+// ones, and among those the transactions paying the lowest tips first, from
+// the highest nonce of each account so as not to gap the rest.
+func (vm *VM) shedTxPool(target uint64) {
+	usage := vm.txPoolMemoryUsage()
+	if usage <= target {
+		return
+	}
+	locals := make(map[common.Address]struct{})
+	for _, addr := range vm.txPool.Locals() {
+		locals[addr] = struct{}{}
+	}
+	pending, queued := vm.txPool.Content()
+	for _, txs := range []map[common.Address][]*types.Transaction{queued, pending} {
+		var remotes []*types.Transaction
+		for addr, accountTxs := range txs {
+			if _, ok := locals[addr]; ok {
+				continue
+			}
+			remotes = append(remotes, accountTxs...)
+		}
+		sort.SliceStable(remotes, func(i, j int) bool {
+			if cmp := remotes[i].GasTipCapCmp(remotes[j]); cmp != 0 {
+				return cmp < 0
+			}
+			return remotes[i].Nonce() > remotes[j].Nonce()
+		})
+		for _, tx := range remotes {
+			if usage <= target {
+				return
+			}
+			if vm.txPool.Remove(tx.Hash()) {
+				usage -= tx.Size()
+			}
+		}
+	}
+}
+
+// cachesMemoryUsage returns the bytes held by the clean trie cache and the
+// snapshot cache.
+func (vm *VM) cachesMemoryUsage() uint64 {
+	usage := uint64(vm.blockChain.TrieDB().CleanCacheSize())
+	if snaps := vm.blockChain.Snapshots(); snaps != nil {
+		usage += uint64(snaps.CacheSize())
+	}
+	return usage
+}
+
+// shedCaches resets the clean trie cache and the snapshot cache. Their
+// memory is kept for reuse by the caches rather than released to the
+// operating system.
+func (vm *VM) shedCaches(uint64) {
+	vm.blockChain.TrieDB().ResetCleanCache()
+	if snaps := vm.blockChain.Snapshots(); snaps != nil {
+		snaps.ResetCache()
+	}
+}
+
+// filtersMemoryUsage returns the bytes buffered by the installed filters.
+func (vm *VM) filtersMemoryUsage() uint64 {
+	if api := vm.eth.FilterAPI(); api != nil {
+		return api.MemoryUsage()
+	}
+	return 0
+}
+
+// shedFilters uninstalls the filters buffering the most until the filters
+// buffer at most [target] bytes.
+func (vm *VM) shedFilters(target uint64) {
+	if api := vm.eth.FilterAPI(); api != nil {
+		if n := api.ShedFilters(target); n > 0 {
+			log.Info("Uninstalled filters over memory budget", "filters", n)
+		}
+	}
+}
+
+// stateSyncMemoryBudget returns the state sync memory budget in bytes, the
+// lowest of the state-sync-memory-budget option and the state sync budget
+// of the memory accounting, or 0 if state sync is unbounded.
+func (vm *VM) stateSyncMemoryBudget() uint64 {
+	budget := vm.config.StateSyncMemoryBudget * units.MiB
+	if !vm.config.MemoryBudget.Enabled || vm.config.MemoryBudget.StateSync == 0 {
+		return budget
+	}
+	if accounted := vm.config.MemoryBudget.StateSync * units.MiB; budget == 0 || accounted < budget {
+		return accounted
+	}
+	return budget
+}
+
+func (vm *VM) startMemoryAccounting() {
+	config := vm.config.MemoryBudget
+	if !config.Enabled {
+		return
+	}
+	vm.memoryAccountant = &memoryAccountant{
+		subsystems: []*memorySubsystem{
+			newMemorySubsystem(memoryTxPool, config.TxPool*units.MiB, vm.txPoolMemoryUsage, vm.shedTxPool),
+			newMemorySubsystem(memoryCaches, config.Caches*units.MiB, vm.cachesMemoryUsage, vm.shedCaches),
+			// State sync is throttled by its own memory budget instead.
+			newMemorySubsystem(memoryStateSync, vm.stateSyncMemoryBudget(), vm.StateSyncClient.MemoryUsage, nil),
+			newMemorySubsystem(memoryFilters, config.Filters*units.MiB, vm.filtersMemoryUsage, vm.shedFilters),
+		},
+	}
+
+	vm.shutdownWg.Add(1)
+	go vm.ctx.Log.RecoverAndPanic(func() {
+		defer vm.shutdownWg.Done()
+
+		ticker := time.NewTicker(config.CheckInterval.Duration)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				vm.memoryAccountant.check()
+			case <-vm.shutdownChan:
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,96 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"testing"
+
+	"github.com/shubhamdubey02/cryftgo/utils/units"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryAccountant(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		boundedUsage   uint64 = 1000
+		unboundedUsage uint64 = 5000
+		throttledUsage uint64 = 3000
+		shedTargets    []uint64
+	)
+	bounded := newMemorySubsystem("test_bounded", 2000, func() uint64 { return boundedUsage }, func(target uint64) {
+		shedTargets = append(shedTargets, target)
+		boundedUsage = target
+	})
+	unbounded := newMemorySubsystem("test_unbounded", 0, func() uint64 { return unboundedUsage }, func(uint64) {
+		t.Fatal("unbounded subsystem shed")
+	})
+	// Subsystems without a shedding strategy are only reported.
+	throttled := newMemorySubsystem("test_throttled", 2000, func() uint64 { return throttledUsage }, nil)
+	a := &memoryAccountant{subsystems: []*memorySubsystem{bounded, unbounded, throttled}}
+
+	a.check()
+	require.Empty(shedTargets)
+	require.EqualValues(1000, bounded.usageGauge.Snapshot().Value())
+	require.EqualValues(2000, bounded.budgetGauge.Snapshot().Value())
+
+	// A subsystem over budget is shed below its budget.
+	boundedUsage = 2500
+	a.check()
+	require.Equal([]uint64{1800}, shedTargets)
+	require.EqualValues(1800, bounded.usageGauge.Snapshot().Value())
+	require.EqualValues(5000, unbounded.usageGauge.Snapshot().Value())
+	require.EqualValues(3000, throttled.usageGauge.Snapshot().Value())
+
+	require.Equal(map[string]MemoryUsage{
+		"test_bounded":   {Usage: 1800, Budget: 2000, Shed: 1},
+		"test_unbounded": {Usage: 5000},
+		"test_throttled": {Usage: 3000, Budget: 2000},
+	}, a.report())
+}
+
+func TestStateSyncMemoryBudget(t *testing.T) {
+	tests := []struct {
+		name       string
+		option     uint64
+		accounting MemoryBudgetConfig
+		expected   uint64
+	}{
+		{
+			name:     "unbounded",
+			expected: 0,
+		},
+		{
+			name:     "option",
+			option:   64,
+			expected: 64 * units.MiB,
+		},
+		{
+			name:       "accounting disabled",
+			option:     64,
+			accounting: MemoryBudgetConfig{StateSync: 32},
+			expected:   64 * units.MiB,
+		},
+		{
+			name:       "accounting",
+			accounting: MemoryBudgetConfig{Enabled: true, StateSync: 32},
+			expected:   32 * units.MiB,
+		},
+		{
+			name:       "lowest",
+			option:     16,
+			accounting: MemoryBudgetConfig{Enabled: true, StateSync: 32},
+			expected:   16 * units.MiB,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			vm := &VM{config: Config{
+				StateSyncMemoryBudget: test.option,
+				MemoryBudget:          test.accounting,
+			}}
+			require.Equal(t, test.expected, vm.stateSyncMemoryBudget())
+		})
+	}
+}
@@ -0,0 +1,49 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package message
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shubhamdubey02/cryftgo/ids"
+)
+
+// MaxAtomicProofKeysPerRequest is the maximum number of keys that may be
+// proven by a single AtomicProofRequest.
+const MaxAtomicProofKeysPerRequest = 32
+
+var _ Request = AtomicProofRequest{}
+
+// AtomicProofRequest is a request for Merkle proofs of entries in the atomic
+// trie at Root. Each key is the atomic trie key of an entry: the big endian
+// block height followed by the blockchainID of the shared memory operations.
+type AtomicProofRequest struct {
+	Root common.Hash `serialize:"true"`
+	Keys [][]byte    `serialize:"true"`
+}
+
+func (a AtomicProofRequest) String() string {
+	keyStrs := make([]string, len(a.Keys))
+	for i, key := range a.Keys {
+		keyStrs[i] = common.Bytes2Hex(key)
+	}
+	return fmt.Sprintf("AtomicProofRequest(Root=%s, Keys=%s)", a.Root, strings.Join(keyStrs, ", "))
+}
+
+func (a AtomicProofRequest) Handle(ctx context.Context, nodeID ids.NodeID, requestID uint32, handler RequestHandler) ([]byte, error) {
+	return handler.HandleAtomicProofRequest(ctx, nodeID, requestID, a)
+}
+
+// AtomicProofResponse is a response to an AtomicProofRequest.
+// Vals contains the value of each requested key, or an empty value if the key
+// is absent from the trie. ProofVals contains the trie nodes proving every
+// requested key (or its absence) against the requested root.
+// handler: handlers.AtomicProofRequestHandler
+type AtomicProofResponse struct {
+	Vals      [][]byte `serialize:"true"`
+	ProofVals [][]byte `serialize:"true"`
+}
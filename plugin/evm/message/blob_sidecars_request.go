@@ -0,0 +1,37 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package message
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shubhamdubey02/cryftgo/ids"
+)
+
+var _ Request = BlobSidecarsRequest{}
+
+// BlobSidecarsRequest is a request to retrieve the blob sidecars of the block
+// with the specified Hash and Height
+type BlobSidecarsRequest struct {
+	Hash   common.Hash `serialize:"true"`
+	Height uint64      `serialize:"true"`
+}
+
+func (b BlobSidecarsRequest) String() string {
+	return fmt.Sprintf("BlobSidecarsRequest(Hash=%s, Height=%d)", b.Hash, b.Height)
+}
+
+func (b BlobSidecarsRequest) Handle(ctx context.Context, nodeID ids.NodeID, requestID uint32, handler RequestHandler) ([]byte, error) {
+	return handler.HandleBlobSidecarsRequest(ctx, nodeID, requestID, b)
+}
+
+// BlobSidecarsResponse is a response to a BlobSidecarsRequest
+// Sidecars is a slice of RLP encoded blob sidecars, one for each blob
+// transaction of the block, in transaction order.
+// handler: handlers.BlobSidecarsRequestHandler
+type BlobSidecarsResponse struct {
+	Sidecars [][]byte `serialize:"true"`
+}
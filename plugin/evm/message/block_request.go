@@ -41,3 +41,56 @@ func (b BlockRequest) Handle(ctx context.Context, nodeID ids.NodeID, requestID u
 type BlockResponse struct {
 	Blocks [][]byte `serialize:"true"`
 }
+
+// BlockDataFlags selects the parts of each block returned in a BlockDataResponse
+type BlockDataFlags uint8
+
+const (
+	// HeadersOnly requests RLP encoded headers instead of full blocks
+	HeadersOnly BlockDataFlags = 1 << iota
+	// WithReceipts requests the RLP encoded receipts of each block
+	WithReceipts
+)
+
+// Has returns true if [flag] is set in [f]
+func (f BlockDataFlags) Has(flag BlockDataFlags) bool { return f&flag != 0 }
+
+func (f BlockDataFlags) String() string {
+	return fmt.Sprintf("BlockDataFlags(HeadersOnly=%t, WithReceipts=%t)", f.Has(HeadersOnly), f.Has(WithReceipts))
+}
+
+var _ Request = BlockDataRequest{}
+
+// BlockDataRequest is a BlockRequest with Flags selecting whether full blocks or
+// only headers are returned, and whether their receipts are included, so that
+// consumers do not have to download block bodies they do not need.
+type BlockDataRequest struct {
+	Hash    common.Hash    `serialize:"true"`
+	Height  uint64         `serialize:"true"`
+	Parents uint16         `serialize:"true"`
+	Flags   BlockDataFlags `serialize:"true"`
+}
+
+func (b BlockDataRequest) String() string {
+	return fmt.Sprintf(
+		"BlockDataRequest(Hash=%s, Height=%d, Parents=%d, Flags=%s)",
+		b.Hash, b.Height, b.Parents, b.Flags,
+	)
+}
+
+func (b BlockDataRequest) Handle(ctx context.Context, nodeID ids.NodeID, requestID uint32, handler RequestHandler) ([]byte, error) {
+	return handler.HandleBlockDataRequest(ctx, nodeID, requestID, b)
+}
+
+// BlockDataResponse is a response to a BlockDataRequest
+// Blocks is slice of RLP encoded blocks, or headers if HeadersOnly was requested,
+// starting with the block requested in BlockDataRequest.Hash. The next block is
+// the parent, etc.
+// Receipts is empty unless WithReceipts was requested, in which case it contains
+// the RLP encoded receipts of the corresponding element of Blocks, and Blocks
+// stops before the first block whose receipts are no longer stored.
+// handler: handlers.BlockRequestHandler
+type BlockDataResponse struct {
+	Blocks   [][]byte `serialize:"true"`
+	Receipts [][]byte `serialize:"true"`
+}
@@ -0,0 +1,58 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package message
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shubhamdubey02/cryftgo/ids"
+)
+
+// Sync features advertised in a CapabilitiesResponse. Features are only ever
+// added, so peers ignore the bits they do not know.
+const (
+	// FeatureAtomicProofs is set by peers serving AtomicProofRequest.
+	FeatureAtomicProofs uint64 = 1 << iota
+	// FeatureBlockData is set by peers serving BlockDataRequest.
+	FeatureBlockData
+	// FeatureBlobSidecars is set by peers serving BlobSidecarsRequest.
+	FeatureBlobSidecars
+)
+
+var _ Request = CapabilitiesRequest{}
+
+// CapabilitiesRequest asks a peer for the sync features it serves, so that
+// clients can pick peers and protocols up front rather than probing with
+// requests that may fail.
+type CapabilitiesRequest struct{}
+
+func (CapabilitiesRequest) String() string {
+	return "CapabilitiesRequest()"
+}
+
+func (c CapabilitiesRequest) Handle(ctx context.Context, nodeID ids.NodeID, requestID uint32, handler RequestHandler) ([]byte, error) {
+	return handler.HandleCapabilitiesRequest(ctx, nodeID, requestID, c)
+}
+
+// CapabilitiesResponse is a response to a CapabilitiesRequest.
+// Features is a bit set of the Feature constants. CommitInterval is the
+// interval of the heights whose state tries the peer keeps, and so can serve
+// leafs for. MaxCodeHashes is the number of hashes the peer serves in a
+// single CodeRequest.
+// handler: handlers.CapabilitiesRequestHandler
+type CapabilitiesResponse struct {
+	Features       uint64 `serialize:"true"`
+	CommitInterval uint64 `serialize:"true"`
+	MaxCodeHashes  uint16 `serialize:"true"`
+}
+
+// Supports returns true if all of [features] are advertised.
+func (c CapabilitiesResponse) Supports(features uint64) bool {
+	return c.Features&features == features
+}
+
+func (c CapabilitiesResponse) String() string {
+	return fmt.Sprintf("CapabilitiesResponse(Features=%#x, CommitInterval=%d, MaxCodeHashes=%d)", c.Features, c.CommitInterval, c.MaxCodeHashes)
+}
@@ -0,0 +1,32 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package message
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMarshalCapabilitiesResponse asserts that the structure or serialization logic hasn't changed, primarily to
+// ensure compatibility with the network.
+func TestMarshalCapabilitiesResponse(t *testing.T) {
+	capabilitiesResponse := CapabilitiesResponse{
+		Features:       FeatureAtomicProofs | FeatureBlockData,
+		CommitInterval: 4096,
+		MaxCodeHashes:  MaxCodeHashesPerRequest,
+	}
+
+	base64CapabilitiesResponse := "AAAAAAAAAAAAAwAAAAAAABAAAAU="
+
+	capabilitiesResponseBytes, err := Codec.Marshal(Version, capabilitiesResponse)
+	assert.NoError(t, err)
+	assert.Equal(t, base64CapabilitiesResponse, base64.StdEncoding.EncodeToString(capabilitiesResponseBytes))
+
+	var c CapabilitiesResponse
+	_, err = Codec.Unmarshal(capabilitiesResponseBytes, &c)
+	assert.NoError(t, err)
+	assert.Equal(t, capabilitiesResponse, c)
+}
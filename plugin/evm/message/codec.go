@@ -46,6 +46,22 @@ func init() {
 		c.RegisterType(BlockSignatureRequest{}),
 		c.RegisterType(SignatureResponse{}),
 
+		// Atomic trie proof types
+		c.RegisterType(AtomicProofRequest{}),
+		c.RegisterType(AtomicProofResponse{}),
+
+		// Block data types
+		c.RegisterType(BlockDataRequest{}),
+		c.RegisterType(BlockDataResponse{}),
+
+		// Capability handshake types
+		c.RegisterType(CapabilitiesRequest{}),
+		c.RegisterType(CapabilitiesResponse{}),
+
+		// Blob sidecar types
+		c.RegisterType(BlobSidecarsRequest{}),
+		c.RegisterType(BlobSidecarsResponse{}),
+
 		Codec.RegisterCodec(Version, c),
 	)
 
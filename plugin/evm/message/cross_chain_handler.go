@@ -54,6 +54,7 @@ func (c *crossChainHandler) HandleEthCallRequest(ctx context.Context, requesting
 		lastAcceptedBlockNumberOrHash,
 		nil,
 		nil,
+		nil,
 		c.backend.RPCEVMTimeout(),
 		c.backend.RPCGasCap())
 	if err != nil {
@@ -43,10 +43,15 @@ func (NoopMempoolGossipHandler) HandleEthTxs(nodeID ids.NodeID, msg EthTxsGossip
 type RequestHandler interface {
 	HandleStateTrieLeafsRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, leafsRequest LeafsRequest) ([]byte, error)
 	HandleAtomicTrieLeafsRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, leafsRequest LeafsRequest) ([]byte, error)
+	HandleNamespaceTrieLeafsRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, leafsRequest LeafsRequest) ([]byte, error)
 	HandleBlockRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, request BlockRequest) ([]byte, error)
 	HandleCodeRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, codeRequest CodeRequest) ([]byte, error)
 	HandleMessageSignatureRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, signatureRequest MessageSignatureRequest) ([]byte, error)
 	HandleBlockSignatureRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, signatureRequest BlockSignatureRequest) ([]byte, error)
+	HandleAtomicProofRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, atomicProofRequest AtomicProofRequest) ([]byte, error)
+	HandleBlockDataRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, request BlockDataRequest) ([]byte, error)
+	HandleCapabilitiesRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, request CapabilitiesRequest) ([]byte, error)
+	HandleBlobSidecarsRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, request BlobSidecarsRequest) ([]byte, error)
 }
 
 // ResponseHandler handles response for a sent request
@@ -68,6 +73,10 @@ func (NoopRequestHandler) HandleAtomicTrieLeafsRequest(ctx context.Context, node
 	return nil, nil
 }
 
+func (NoopRequestHandler) HandleNamespaceTrieLeafsRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, leafsRequest LeafsRequest) ([]byte, error) {
+	return nil, nil
+}
+
 func (NoopRequestHandler) HandleBlockRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, request BlockRequest) ([]byte, error) {
 	return nil, nil
 }
@@ -84,6 +93,22 @@ func (NoopRequestHandler) HandleBlockSignatureRequest(ctx context.Context, nodeI
 	return nil, nil
 }
 
+func (NoopRequestHandler) HandleAtomicProofRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, atomicProofRequest AtomicProofRequest) ([]byte, error) {
+	return nil, nil
+}
+
+func (NoopRequestHandler) HandleBlockDataRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, request BlockDataRequest) ([]byte, error) {
+	return nil, nil
+}
+
+func (NoopRequestHandler) HandleCapabilitiesRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, request CapabilitiesRequest) ([]byte, error) {
+	return nil, nil
+}
+
+func (NoopRequestHandler) HandleBlobSidecarsRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, request BlobSidecarsRequest) ([]byte, error) {
+	return nil, nil
+}
+
 // CrossChainRequestHandler interface handles incoming requests from another chain
 type CrossChainRequestHandler interface {
 	HandleEthCallRequest(ctx context.Context, requestingchainID ids.ID, requestID uint32, ethCallRequest EthCallRequest) ([]byte, error)
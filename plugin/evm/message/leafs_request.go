@@ -28,15 +28,23 @@ const (
 	AtomicTrieNode
 )
 
+// FirstNamespaceNode is the first NodeType of the additional trie namespaces
+// served by handlers.NamespaceLeafsRequestHandler. Node types from
+// FirstNamespaceNode onwards are reserved for them, so that new tries can be
+// synced without new message types.
+const FirstNamespaceNode NodeType = 64
+
 func (nt NodeType) String() string {
 	switch nt {
 	case StateTrieNode:
 		return "StateTrie"
 	case AtomicTrieNode:
 		return "AtomicTrie"
-	default:
-		return "Unknown"
 	}
+	if nt >= FirstNamespaceNode {
+		return fmt.Sprintf("NamespaceTrie(%d)", uint8(nt))
+	}
+	return "Unknown"
 }
 
 // LeafsRequest is a request to receive trie leaves at specified Root within Start and End byte range
@@ -65,6 +73,9 @@ func (l LeafsRequest) Handle(ctx context.Context, nodeID ids.NodeID, requestID u
 	case AtomicTrieNode:
 		return handler.HandleAtomicTrieLeafsRequest(ctx, nodeID, requestID, l)
 	}
+	if l.NodeType >= FirstNamespaceNode {
+		return handler.HandleNamespaceTrieLeafsRequest(ctx, nodeID, requestID, l)
+	}
 
 	log.Debug("node type is not recognised, dropping request", "nodeID", nodeID, "requestID", requestID, "nodeType", l.NodeType)
 	return nil, nil
@@ -146,6 +146,22 @@ func TestLeafsRequestValidation(t *testing.T) {
 				assert.False(t, mockRequestHandler.handleCodeRequestCalled)
 			},
 		},
+		"namespace node type": {
+			request: LeafsRequest{
+				Root:     common.BytesToHash([]byte("some hash goes here")),
+				Start:    bytes.Repeat([]byte{0x00}, common.HashLength),
+				End:      bytes.Repeat([]byte{0xff}, common.HashLength),
+				Limit:    10,
+				NodeType: FirstNamespaceNode + 1,
+			},
+			assertResponse: func(t *testing.T) {
+				assert.False(t, mockRequestHandler.handleStateTrieCalled)
+				assert.False(t, mockRequestHandler.handleAtomicTrieCalled)
+				assert.True(t, mockRequestHandler.handleNamespaceTrieCalled)
+				assert.False(t, mockRequestHandler.handleBlockRequestCalled)
+				assert.False(t, mockRequestHandler.handleCodeRequestCalled)
+			},
+		},
 		"unknown node type": {
 			request: LeafsRequest{
 				Root:     common.BytesToHash([]byte("some hash goes here")),
@@ -157,6 +173,7 @@ func TestLeafsRequestValidation(t *testing.T) {
 			assertResponse: func(t *testing.T) {
 				assert.False(t, mockRequestHandler.handleStateTrieCalled)
 				assert.False(t, mockRequestHandler.handleAtomicTrieCalled)
+				assert.False(t, mockRequestHandler.handleNamespaceTrieCalled)
 				assert.False(t, mockRequestHandler.handleBlockRequestCalled)
 				assert.False(t, mockRequestHandler.handleCodeRequestCalled)
 			},
@@ -176,10 +193,15 @@ var _ RequestHandler = &mockHandler{}
 type mockHandler struct {
 	handleStateTrieCalled,
 	handleAtomicTrieCalled,
+	handleNamespaceTrieCalled,
 	handleBlockRequestCalled,
 	handleCodeRequestCalled,
 	handleMessageSignatureCalled,
-	handleBlockSignatureCalled bool
+	handleBlockSignatureCalled,
+	handleAtomicProofCalled,
+	handleBlockDataCalled,
+	handleCapabilitiesCalled,
+	handleBlobSidecarsCalled bool
 }
 
 func (m *mockHandler) HandleStateTrieLeafsRequest(context.Context, ids.NodeID, uint32, LeafsRequest) ([]byte, error) {
@@ -192,6 +214,11 @@ func (m *mockHandler) HandleAtomicTrieLeafsRequest(context.Context, ids.NodeID,
 	return nil, nil
 }
 
+func (m *mockHandler) HandleNamespaceTrieLeafsRequest(context.Context, ids.NodeID, uint32, LeafsRequest) ([]byte, error) {
+	m.handleNamespaceTrieCalled = true
+	return nil, nil
+}
+
 func (m *mockHandler) HandleBlockRequest(context.Context, ids.NodeID, uint32, BlockRequest) ([]byte, error) {
 	m.handleBlockRequestCalled = true
 	return nil, nil
@@ -211,9 +238,30 @@ func (m *mockHandler) HandleBlockSignatureRequest(ctx context.Context, nodeID id
 	return nil, nil
 }
 
+func (m *mockHandler) HandleAtomicProofRequest(context.Context, ids.NodeID, uint32, AtomicProofRequest) ([]byte, error) {
+	m.handleAtomicProofCalled = true
+	return nil, nil
+}
+
+func (m *mockHandler) HandleBlockDataRequest(context.Context, ids.NodeID, uint32, BlockDataRequest) ([]byte, error) {
+	m.handleBlockDataCalled = true
+	return nil, nil
+}
+
+func (m *mockHandler) HandleCapabilitiesRequest(context.Context, ids.NodeID, uint32, CapabilitiesRequest) ([]byte, error) {
+	m.handleCapabilitiesCalled = true
+	return nil, nil
+}
+
+func (m *mockHandler) HandleBlobSidecarsRequest(context.Context, ids.NodeID, uint32, BlobSidecarsRequest) ([]byte, error) {
+	m.handleBlobSidecarsCalled = true
+	return nil, nil
+}
+
 func (m *mockHandler) reset() {
 	m.handleStateTrieCalled = false
 	m.handleAtomicTrieCalled = false
+	m.handleNamespaceTrieCalled = false
 	m.handleBlockRequestCalled = false
 	m.handleCodeRequestCalled = false
 }
@@ -0,0 +1,328 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package netgen generates the genesis, chain configs, funded accounts and
+// node credentials of local networks running coreth, along with the
+// docker-compose and avalanche-network-runner artifacts to start them.
+package netgen
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/shubhamdubey02/cryftgo/genesis"
+	"github.com/shubhamdubey02/cryftgo/ids"
+	"github.com/shubhamdubey02/cryftgo/staking"
+	"github.com/shubhamdubey02/cryftgo/utils/constants"
+	"github.com/shubhamdubey02/cryftgo/utils/crypto/bls"
+	"github.com/shubhamdubey02/cryftgo/utils/crypto/secp256k1"
+	"github.com/shubhamdubey02/cryftgo/utils/formatting/address"
+	"github.com/shubhamdubey02/cryftgo/utils/units"
+	"github.com/shubhamdubey02/cryftgo/vms/platformvm/signer"
+
+	"github.com/shubhamdubey02/coreth/core"
+	"github.com/shubhamdubey02/coreth/params"
+)
+
+const (
+	DefaultNetworkID = 1337
+	DefaultNodes     = 1
+	DefaultAccounts  = 10
+	DefaultImage     = "shubhamdubey02/cryftgo:latest"
+	DefaultBinary    = "/cryftgo/build/cryftgo"
+
+	// genesisGasLimit is the gas limit of the C-Chain genesis block.
+	genesisGasLimit = 100_000_000
+
+	// initialStakeDuration and initialStakeDurationOffset are the staking
+	// period of the initial validators, and the difference between the end
+	// of the periods of consecutive validators.
+	initialStakeDuration       = 365 * 24 * time.Hour
+	initialStakeDurationOffset = 90 * time.Minute
+
+	// stakePerNode is the stake of each initial validator, locked in the
+	// allocation of the first account.
+	stakePerNode = 2 * units.MegaCryft
+	// delegationFee is the delegation fee of the initial validators, in
+	// units of 1/10000 of a percent.
+	delegationFee = 20_000
+
+	// Nodes are assigned consecutive addresses and ports, starting from
+	// these.
+	baseIP          = 10
+	baseHTTPPort    = 9650
+	baseStakingPort = 9651
+)
+
+var (
+	// DefaultBalance is the C-Chain balance of each funded account.
+	DefaultBalance = new(big.Int).Mul(big.NewInt(1_000_000), big.NewInt(params.Ether))
+	// DefaultXBalance is the X-Chain balance of each funded account.
+	DefaultXBalance = 1 * units.MegaCryft
+
+	errNoNodes    = errors.New("network requires at least one node")
+	errNoAccounts = errors.New("network requires at least one funded account")
+)
+
+// Config describes the network to generate.
+type Config struct {
+	NetworkID uint32
+	ChainID   *big.Int
+	// Nodes is the number of validators of the network, typically 1 or 5.
+	Nodes int
+	// Accounts is the number of funded accounts, the first of which also
+	// holds the stake of the validators.
+	Accounts int
+	// Balance is the C-Chain balance of each account, in wei, and XBalance
+	// is its X-Chain balance, in nCRYFT.
+	Balance  *big.Int
+	XBalance uint64
+	// Seed derives the keys of the accounts, so that the same seed always
+	// funds the same accounts. The keys are random if it is empty. Node
+	// credentials are always random.
+	Seed string
+	// StartTime is the start time of the network, which may not be in the
+	// future. It defaults to the time of generation.
+	StartTime time.Time
+
+	// VMConfig is written as the config of the C-Chain, and Upgrade as its
+	// upgrade config.
+	VMConfig map[string]interface{}
+	Upgrade  params.UpgradeConfig
+
+	// Image is the docker image running the nodes, and Binary the path of
+	// the node binary in it.
+	Image  string
+	Binary string
+}
+
+// DefaultConfig returns the config of a single node network with the default
+// funded accounts.
+func DefaultConfig() Config {
+	return Config{
+		NetworkID: DefaultNetworkID,
+		ChainID:   new(big.Int).Set(params.AvalancheLocalChainID),
+		Nodes:     DefaultNodes,
+		Accounts:  DefaultAccounts,
+		Balance:   new(big.Int).Set(DefaultBalance),
+		XBalance:  DefaultXBalance,
+		Image:     DefaultImage,
+		Binary:    DefaultBinary,
+	}
+}
+
+// Account is a funded account, with its address on the C-Chain and on the
+// X-Chain.
+type Account struct {
+	Address      common.Address
+	CryftAddress string
+	PrivateKey   *ecdsa.PrivateKey
+}
+
+// MarshalJSON encodes [a] with its private key, in the hex format of the
+// C-Chain and the CB58 format of the X-Chain and P-Chain.
+func (a Account) MarshalJSON() ([]byte, error) {
+	key, err := secp256k1.ToPrivateKey(crypto.FromECDSA(a.PrivateKey))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(struct {
+		Address         common.Address `json:"address"`
+		CryftAddress    string         `json:"cryftAddress"`
+		PrivateKey      string         `json:"privateKey"`
+		CryftPrivateKey string         `json:"cryftPrivateKey"`
+	}{
+		Address:         a.Address,
+		CryftAddress:    a.CryftAddress,
+		PrivateKey:      common.Bytes2Hex(crypto.FromECDSA(a.PrivateKey)),
+		CryftPrivateKey: key.String(),
+	})
+}
+
+// Node is a validator of the network with its staking credentials.
+type Node struct {
+	Name        string
+	NodeID      ids.NodeID
+	IP          string
+	HTTPPort    uint16
+	StakingPort uint16
+	// StakingCert and StakingKey are the PEM encoded TLS certificate and key
+	// of the node, and SignerKey its BLS secret key.
+	StakingCert []byte
+	StakingKey  []byte
+	SignerKey   []byte
+	Signer      *signer.ProofOfPossession
+}
+
+// Network is a generated network.
+type Network struct {
+	Config   Config
+	Accounts []Account
+	Nodes    []*Node
+	// CChainGenesis is the genesis of the C-Chain, embedded in Genesis, the
+	// genesis of the network.
+	CChainGenesis *core.Genesis
+	Genesis       *genesis.UnparsedConfig
+}
+
+// Generate generates the network described by [config].
+func Generate(config Config) (*Network, error) {
+	if config.Nodes <= 0 {
+		return nil, errNoNodes
+	}
+	if config.Accounts <= 0 {
+		return nil, errNoAccounts
+	}
+	if config.ChainID == nil {
+		config.ChainID = new(big.Int).Set(params.AvalancheLocalChainID)
+	}
+	if config.Balance == nil {
+		config.Balance = new(big.Int).Set(DefaultBalance)
+	}
+	if config.StartTime.IsZero() {
+		config.StartTime = time.Now()
+	}
+	if config.StartTime.After(time.Now()) {
+		return nil, fmt.Errorf("start time %s is in the future", config.StartTime)
+	}
+
+	n := &Network{Config: config}
+	hrp := constants.GetHRP(config.NetworkID)
+	for i := 0; i < config.Accounts; i++ {
+		account, err := newAccount(config.Seed, i, hrp)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate account %d: %w", i, err)
+		}
+		n.Accounts = append(n.Accounts, account)
+	}
+	for i := 0; i < config.Nodes; i++ {
+		node, err := newNode(i)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate node %d: %w", i, err)
+		}
+		n.Nodes = append(n.Nodes, node)
+	}
+
+	chainConfig := *params.AvalancheLocalChainConfig
+	chainConfig.ChainID = config.ChainID
+	chainConfig.UpgradeConfig = config.Upgrade
+	n.CChainGenesis = &core.Genesis{
+		Config:     &chainConfig,
+		Timestamp:  uint64(config.StartTime.Unix()),
+		GasLimit:   genesisGasLimit,
+		Difficulty: big.NewInt(0),
+		Alloc:      make(core.GenesisAlloc, len(n.Accounts)),
+	}
+	for _, account := range n.Accounts {
+		n.CChainGenesis.Alloc[account.Address] = core.GenesisAccount{Balance: new(big.Int).Set(config.Balance)}
+	}
+	cChainGenesis, err := json.Marshal(n.CChainGenesis)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal C-Chain genesis: %w", err)
+	}
+
+	startTime := uint64(config.StartTime.Unix())
+	n.Genesis = &genesis.UnparsedConfig{
+		NetworkID:                  config.NetworkID,
+		StartTime:                  startTime,
+		InitialStakeDuration:       uint64(initialStakeDuration.Seconds()),
+		InitialStakeDurationOffset: uint64(initialStakeDurationOffset.Seconds()),
+		InitialStakedFunds:         []string{n.Accounts[0].CryftAddress},
+		CChainGenesis:              string(cChainGenesis),
+		Message:                    fmt.Sprintf("%d node network generated by coreth", config.Nodes),
+	}
+	for i, account := range n.Accounts {
+		allocation := genesis.UnparsedAllocation{
+			ETHAddr:       account.Address.Hex(),
+			CRYFTAddr:     account.CryftAddress,
+			InitialAmount: config.XBalance,
+		}
+		if i == 0 {
+			allocation.UnlockSchedule = []genesis.LockedAmount{{
+				Amount:   stakePerNode * uint64(config.Nodes),
+				Locktime: startTime,
+			}}
+		}
+		n.Genesis.Allocations = append(n.Genesis.Allocations, allocation)
+	}
+	for _, node := range n.Nodes {
+		n.Genesis.InitialStakers = append(n.Genesis.InitialStakers, genesis.UnparsedStaker{
+			NodeID:        node.NodeID,
+			RewardAddress: n.Accounts[0].CryftAddress,
+			DelegationFee: delegationFee,
+			Signer:        node.Signer,
+		})
+	}
+	return n, nil
+}
+
+// newAccount returns the [i]th account, derived from [seed] if it is not
+// empty.
+func newAccount(seed string, i int, hrp string) (Account, error) {
+	var (
+		key *ecdsa.PrivateKey
+		err error
+	)
+	if seed == "" {
+		key, err = crypto.GenerateKey()
+	} else {
+		index := make([]byte, 8)
+		binary.BigEndian.PutUint64(index, uint64(i))
+		key, err = crypto.ToECDSA(crypto.Keccak256([]byte(seed), index))
+	}
+	if err != nil {
+		return Account{}, err
+	}
+	cryftKey, err := secp256k1.ToPrivateKey(crypto.FromECDSA(key))
+	if err != nil {
+		return Account{}, err
+	}
+	addr := cryftKey.Address()
+	cryftAddr, err := address.Format("X", hrp, addr[:])
+	if err != nil {
+		return Account{}, err
+	}
+	return Account{
+		Address:      crypto.PubkeyToAddress(key.PublicKey),
+		CryftAddress: cryftAddr,
+		PrivateKey:   key,
+	}, nil
+}
+
+// newNode returns the [i]th node with new staking credentials.
+func newNode(i int) (*Node, error) {
+	certBytes, keyBytes, err := staking.NewCertAndKeyBytes()
+	if err != nil {
+		return nil, err
+	}
+	cert, err := staking.LoadTLSCertFromBytes(keyBytes, certBytes)
+	if err != nil {
+		return nil, err
+	}
+	stakingCert, err := staking.ParseCertificate(cert.Leaf.Raw)
+	if err != nil {
+		return nil, err
+	}
+	sk, err := bls.NewSecretKey()
+	if err != nil {
+		return nil, err
+	}
+	return &Node{
+		Name:        fmt.Sprintf("node%d", i+1),
+		NodeID:      ids.NodeIDFromCert(stakingCert),
+		IP:          fmt.Sprintf("172.28.0.%d", baseIP+i),
+		HTTPPort:    uint16(baseHTTPPort + 2*i),
+		StakingPort: uint16(baseStakingPort + 2*i),
+		StakingCert: certBytes,
+		StakingKey:  keyBytes,
+		SignerKey:   bls.SecretKeyToBytes(sk),
+		Signer:      signer.NewProofOfPossession(sk),
+	}, nil
+}
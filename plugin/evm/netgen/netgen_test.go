@@ -0,0 +1,79 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package netgen
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/shubhamdubey02/coreth/core"
+)
+
+func TestGenerate(t *testing.T) {
+	require := require.New(t)
+
+	config := DefaultConfig()
+	config.Nodes = 5
+	config.Seed = "coreth"
+	network, err := Generate(config)
+	require.NoError(err)
+	require.Len(network.Nodes, 5)
+	require.Len(network.Accounts, DefaultAccounts)
+
+	// The seed derives the same accounts.
+	other, err := Generate(config)
+	require.NoError(err)
+	for i, account := range network.Accounts {
+		require.Equal(account.Address, other.Accounts[i].Address)
+		require.Equal(account.CryftAddress, other.Accounts[i].CryftAddress)
+	}
+	require.NotEqual(network.Nodes[0].NodeID, other.Nodes[0].NodeID)
+
+	// The network genesis parses, stakes every node and embeds the C-Chain
+	// genesis funding every account.
+	parsed, err := network.Genesis.Parse()
+	require.NoError(err)
+	require.Len(parsed.InitialStakers, 5)
+	require.Len(parsed.Allocations, DefaultAccounts)
+	for i, staker := range network.Genesis.InitialStakers {
+		require.Equal(network.Nodes[i].NodeID, staker.NodeID)
+		require.NoError(staker.Signer.Verify())
+	}
+	var cChainGenesis core.Genesis
+	require.NoError(json.Unmarshal([]byte(network.Genesis.CChainGenesis), &cChainGenesis))
+	require.Equal(config.ChainID, cChainGenesis.Config.ChainID)
+	for _, account := range network.Accounts {
+		require.Equal(config.Balance, cChainGenesis.Alloc[account.Address].Balance)
+	}
+
+	dir := t.TempDir()
+	require.NoError(network.Write(dir))
+	for _, name := range []string{
+		GenesisFile,
+		CChainGenesisFile,
+		AccountsFile,
+		ComposeFile,
+		RunnerFile,
+		filepath.Join(ChainConfigDir, "C", "config.json"),
+		filepath.Join(ChainConfigDir, "C", "upgrade.json"),
+		filepath.Join(NodesDir, "node5", stakingCertFile),
+		filepath.Join(NodesDir, "node5", signerKeyFile),
+	} {
+		require.FileExists(filepath.Join(dir, name))
+	}
+	compose, err := os.ReadFile(filepath.Join(dir, ComposeFile))
+	require.NoError(err)
+	require.Contains(string(compose), "--bootstrap-ids="+network.Nodes[0].NodeID.String())
+}
+
+func TestGenerateInvalid(t *testing.T) {
+	config := DefaultConfig()
+	config.Nodes = 0
+	_, err := Generate(config)
+	require.ErrorIs(t, err, errNoNodes)
+}
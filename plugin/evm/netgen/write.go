@@ -0,0 +1,218 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package netgen
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/template"
+)
+
+// Files written by [Network.Write], relative to its output directory.
+const (
+	GenesisFile       = "genesis.json"
+	CChainGenesisFile = "cchain-genesis.json"
+	AccountsFile      = "accounts.json"
+	ComposeFile       = "docker-compose.yml"
+	RunnerFile        = "network-runner.json"
+	ChainConfigDir    = "chains"
+	NodesDir          = "nodes"
+
+	stakingCertFile = "staker.crt"
+	stakingKeyFile  = "staker.key"
+	signerKeyFile   = "signer.key"
+)
+
+// composeTemplate starts the nodes on a bridge network, bootstrapping from
+// the first node. The output directory is mounted in each container.
+var composeTemplate = template.Must(template.New(ComposeFile).Parse(`version: "3.8"
+services:
+{{- range .Nodes }}
+  {{ .Name }}:
+    image: {{ $.Image }}
+    command:
+      - {{ $.Binary }}
+{{- range .Flags }}
+      - "{{ . }}"
+{{- end }}
+    volumes:
+      - ./:/network:ro
+    ports:
+      - "{{ .HTTPPort }}:9650"
+    networks:
+      coreth:
+        ipv4_address: {{ .IP }}
+{{- end }}
+networks:
+  coreth:
+    ipam:
+      config:
+        - subnet: 172.28.0.0/24
+`))
+
+// Write writes the network to [dir]:
+//   - genesis.json and cchain-genesis.json: the network and C-Chain genesis
+//   - chains/C/config.json and chains/C/upgrade.json: the C-Chain configs
+//   - accounts.json: the funded accounts and their private keys
+//   - nodes/<name>/: the staking certificate, key and BLS key of each node
+//   - docker-compose.yml: a compose file starting the nodes
+//   - network-runner.json: a network config of avalanche-network-runner
+func (n *Network) Write(dir string) error {
+	genesisBytes, err := json.MarshalIndent(n.Genesis, "", "  ")
+	if err != nil {
+		return err
+	}
+	cChainGenesisBytes, err := json.MarshalIndent(n.CChainGenesis, "", "  ")
+	if err != nil {
+		return err
+	}
+	vmConfig := n.Config.VMConfig
+	if vmConfig == nil {
+		vmConfig = make(map[string]interface{})
+	}
+	vmConfigBytes, err := json.MarshalIndent(vmConfig, "", "  ")
+	if err != nil {
+		return err
+	}
+	upgradeBytes, err := json.MarshalIndent(n.Config.Upgrade, "", "  ")
+	if err != nil {
+		return err
+	}
+	accountsBytes, err := json.MarshalIndent(n.Accounts, "", "  ")
+	if err != nil {
+		return err
+	}
+	composeBytes, err := n.compose()
+	if err != nil {
+		return err
+	}
+	runnerBytes, err := n.runnerConfig(genesisBytes, vmConfigBytes, upgradeBytes)
+	if err != nil {
+		return err
+	}
+
+	files := map[string][]byte{
+		GenesisFile:       genesisBytes,
+		CChainGenesisFile: cChainGenesisBytes,
+		filepath.Join(ChainConfigDir, "C", "config.json"):  vmConfigBytes,
+		filepath.Join(ChainConfigDir, "C", "upgrade.json"): upgradeBytes,
+		AccountsFile: accountsBytes,
+		ComposeFile:  composeBytes,
+		RunnerFile:   runnerBytes,
+	}
+	for _, node := range n.Nodes {
+		files[filepath.Join(NodesDir, node.Name, stakingCertFile)] = node.StakingCert
+		files[filepath.Join(NodesDir, node.Name, stakingKeyFile)] = node.StakingKey
+		files[filepath.Join(NodesDir, node.Name, signerKeyFile)] = node.SignerKey
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, content, 0o600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// nodeFlags returns the flags of [node], with the paths of the files of
+// the network rooted at [root].
+func (n *Network) nodeFlags(node *Node, root string) []string {
+	flags := []string{
+		fmt.Sprintf("--network-id=%d", n.Config.NetworkID),
+		"--genesis-file=" + filepath.Join(root, GenesisFile),
+		"--chain-config-dir=" + filepath.Join(root, ChainConfigDir),
+		"--staking-tls-cert-file=" + filepath.Join(root, NodesDir, node.Name, stakingCertFile),
+		"--staking-tls-key-file=" + filepath.Join(root, NodesDir, node.Name, stakingKeyFile),
+		"--staking-signer-key-file=" + filepath.Join(root, NodesDir, node.Name, signerKeyFile),
+		"--http-host=0.0.0.0",
+		"--public-ip=" + node.IP,
+	}
+	return append(flags, n.bootstrapFlags(node)...)
+}
+
+// bootstrapFlags returns the flags bootstrapping [node] from the first node.
+func (n *Network) bootstrapFlags(node *Node) []string {
+	beacon := n.Nodes[0]
+	if node == beacon {
+		return []string{"--bootstrap-ips=", "--bootstrap-ids="}
+	}
+	return []string{
+		fmt.Sprintf("--bootstrap-ips=%s:%d", beacon.IP, baseStakingPort),
+		"--bootstrap-ids=" + beacon.NodeID.String(),
+	}
+}
+
+func (n *Network) compose() ([]byte, error) {
+	type composeNode struct {
+		Name     string
+		IP       string
+		HTTPPort uint16
+		Flags    []string
+	}
+	nodes := make([]composeNode, 0, len(n.Nodes))
+	for _, node := range n.Nodes {
+		nodes = append(nodes, composeNode{
+			Name:     node.Name,
+			IP:       node.IP,
+			HTTPPort: node.HTTPPort,
+			Flags:    n.nodeFlags(node, "/network"),
+		})
+	}
+	var buf bytes.Buffer
+	err := composeTemplate.Execute(&buf, struct {
+		Image  string
+		Binary string
+		Nodes  []composeNode
+	}{
+		Image:  n.Config.Image,
+		Binary: n.Config.Binary,
+		Nodes:  nodes,
+	})
+	return buf.Bytes(), err
+}
+
+// runnerConfig returns the network config of avalanche-network-runner, which
+// embeds the genesis, chain configs and credentials of the nodes.
+func (n *Network) runnerConfig(genesisBytes, vmConfigBytes, upgradeBytes []byte) ([]byte, error) {
+	type nodeConfig struct {
+		Name              string                 `json:"name"`
+		IsBeacon          bool                   `json:"isBeacon"`
+		StakingKey        string                 `json:"stakingKey"`
+		StakingCert       string                 `json:"stakingCert"`
+		StakingSigningKey string                 `json:"stakingSigningKey"`
+		Flags             map[string]interface{} `json:"flags"`
+	}
+	config := struct {
+		Genesis            string            `json:"genesis"`
+		ChainConfigFiles   map[string]string `json:"chainConfigFiles"`
+		UpgradeConfigFiles map[string]string `json:"upgradeConfigFiles"`
+		NodeConfigs        []nodeConfig      `json:"nodeConfigs"`
+	}{
+		Genesis:            string(genesisBytes),
+		ChainConfigFiles:   map[string]string{"C": string(vmConfigBytes)},
+		UpgradeConfigFiles: map[string]string{"C": string(upgradeBytes)},
+	}
+	for i, node := range n.Nodes {
+		config.NodeConfigs = append(config.NodeConfigs, nodeConfig{
+			Name:              node.Name,
+			IsBeacon:          i == 0,
+			StakingKey:        string(node.StakingKey),
+			StakingCert:       string(node.StakingCert),
+			StakingSigningKey: base64.StdEncoding.EncodeToString(node.SignerKey),
+			Flags: map[string]interface{}{
+				"network-id":   n.Config.NetworkID,
+				"http-port":    node.HTTPPort,
+				"staking-port": node.StakingPort,
+			},
+		})
+	}
+	return json.MarshalIndent(config, "", "  ")
+}
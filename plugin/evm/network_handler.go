@@ -23,8 +23,12 @@ var _ message.RequestHandler = &networkHandler{}
 type networkHandler struct {
 	stateTrieLeafsRequestHandler  *syncHandlers.LeafsRequestHandler
 	atomicTrieLeafsRequestHandler *syncHandlers.LeafsRequestHandler
+	namespaceLeafsRequestHandler  *syncHandlers.NamespaceLeafsRequestHandler
 	blockRequestHandler           *syncHandlers.BlockRequestHandler
 	codeRequestHandler            *syncHandlers.CodeRequestHandler
+	atomicProofRequestHandler     *syncHandlers.AtomicProofRequestHandler
+	capabilitiesRequestHandler    *syncHandlers.CapabilitiesRequestHandler
+	blobSidecarsRequestHandler    *syncHandlers.BlobSidecarsRequestHandler
 	signatureRequestHandler       *warpHandlers.SignatureRequestHandler
 }
 
@@ -34,17 +38,31 @@ func newNetworkHandler(
 	diskDB ethdb.KeyValueReader,
 	evmTrieDB *trie.Database,
 	atomicTrieDB *trie.Database,
+	trieNamespaces []syncHandlers.TrieNamespace,
 	warpBackend warp.Backend,
 	networkCodec codec.Manager,
-) message.RequestHandler {
+	commitInterval uint64,
+) (message.RequestHandler, error) {
 	syncStats := syncStats.NewHandlerStats(metrics.Enabled)
+	namespaceLeafsRequestHandler, err := syncHandlers.NewNamespaceLeafsRequestHandler(trieNamespaces, networkCodec, syncStats)
+	if err != nil {
+		return nil, err
+	}
 	return &networkHandler{
 		stateTrieLeafsRequestHandler:  syncHandlers.NewLeafsRequestHandler(evmTrieDB, provider, networkCodec, syncStats),
 		atomicTrieLeafsRequestHandler: syncHandlers.NewLeafsRequestHandler(atomicTrieDB, nil, networkCodec, syncStats),
-		blockRequestHandler:           syncHandlers.NewBlockRequestHandler(provider, networkCodec, syncStats),
+		namespaceLeafsRequestHandler:  namespaceLeafsRequestHandler,
+		blockRequestHandler:           syncHandlers.NewBlockRequestHandler(provider, provider, networkCodec, syncStats),
 		codeRequestHandler:            syncHandlers.NewCodeRequestHandler(diskDB, networkCodec, syncStats),
-		signatureRequestHandler:       warpHandlers.NewSignatureRequestHandler(warpBackend, networkCodec),
-	}
+		atomicProofRequestHandler:     syncHandlers.NewAtomicProofRequestHandler(atomicTrieDB, networkCodec, syncStats),
+		capabilitiesRequestHandler: syncHandlers.NewCapabilitiesRequestHandler(message.CapabilitiesResponse{
+			Features:       message.FeatureAtomicProofs | message.FeatureBlockData | message.FeatureBlobSidecars,
+			CommitInterval: commitInterval,
+			MaxCodeHashes:  message.MaxCodeHashesPerRequest,
+		}, networkCodec),
+		blobSidecarsRequestHandler: syncHandlers.NewBlobSidecarsRequestHandler(diskDB, networkCodec),
+		signatureRequestHandler:    warpHandlers.NewSignatureRequestHandler(warpBackend, networkCodec),
+	}, nil
 }
 
 func (n networkHandler) HandleStateTrieLeafsRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, leafsRequest message.LeafsRequest) ([]byte, error) {
@@ -55,14 +73,34 @@ func (n networkHandler) HandleAtomicTrieLeafsRequest(ctx context.Context, nodeID
 	return n.atomicTrieLeafsRequestHandler.OnLeafsRequest(ctx, nodeID, requestID, leafsRequest)
 }
 
+func (n networkHandler) HandleNamespaceTrieLeafsRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, leafsRequest message.LeafsRequest) ([]byte, error) {
+	return n.namespaceLeafsRequestHandler.OnLeafsRequest(ctx, nodeID, requestID, leafsRequest)
+}
+
 func (n networkHandler) HandleBlockRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, blockRequest message.BlockRequest) ([]byte, error) {
 	return n.blockRequestHandler.OnBlockRequest(ctx, nodeID, requestID, blockRequest)
 }
 
+func (n networkHandler) HandleBlockDataRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, request message.BlockDataRequest) ([]byte, error) {
+	return n.blockRequestHandler.OnBlockDataRequest(ctx, nodeID, requestID, request)
+}
+
 func (n networkHandler) HandleCodeRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, codeRequest message.CodeRequest) ([]byte, error) {
 	return n.codeRequestHandler.OnCodeRequest(ctx, nodeID, requestID, codeRequest)
 }
 
+func (n networkHandler) HandleAtomicProofRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, atomicProofRequest message.AtomicProofRequest) ([]byte, error) {
+	return n.atomicProofRequestHandler.OnAtomicProofRequest(ctx, nodeID, requestID, atomicProofRequest)
+}
+
+func (n networkHandler) HandleCapabilitiesRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, request message.CapabilitiesRequest) ([]byte, error) {
+	return n.capabilitiesRequestHandler.OnCapabilitiesRequest(ctx, nodeID, requestID, request)
+}
+
+func (n networkHandler) HandleBlobSidecarsRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, request message.BlobSidecarsRequest) ([]byte, error) {
+	return n.blobSidecarsRequestHandler.OnBlobSidecarsRequest(ctx, nodeID, requestID, request)
+}
+
 func (n networkHandler) HandleMessageSignatureRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, messageSignatureRequest message.MessageSignatureRequest) ([]byte, error) {
 	return n.signatureRequestHandler.OnMessageSignatureRequest(ctx, nodeID, requestID, messageSignatureRequest)
 }
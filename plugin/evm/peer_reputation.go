@@ -0,0 +1,77 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/shubhamdubey02/cryftgo/database"
+	"github.com/shubhamdubey02/cryftgo/ids"
+)
+
+// peerReputationInterval is how often the reputation of peers is persisted,
+// in addition to on shutdown.
+const peerReputationInterval = 5 * time.Minute
+
+var peerReputationKey = []byte("reputation")
+
+// loadPeerReputation restores the reputation of peers persisted by a previous
+// run, so that sync prefers historically good peers right away.
+func (vm *VM) loadPeerReputation() error {
+	reputationBytes, err := vm.peerDB.Get(peerReputationKey)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var reputation map[ids.NodeID]float64
+	if err := json.Unmarshal(reputationBytes, &reputation); err != nil {
+		// The reputation is only a hint, so start from scratch rather than
+		// failing to start.
+		log.Warn("Ignoring invalid peer reputation", "err", err)
+		return nil
+	}
+	vm.Network.LoadPeerReputation(reputation)
+	log.Info("Loaded peer reputation", "peers", len(reputation))
+	return nil
+}
+
+// savePeerReputation persists the reputation of peers.
+func (vm *VM) savePeerReputation() error {
+	reputationBytes, err := json.Marshal(vm.Network.PeerReputation())
+	if err != nil {
+		return err
+	}
+	if err := vm.peerDB.Put(peerReputationKey, reputationBytes); err != nil {
+		return fmt.Errorf("failed to save peer reputation: %w", err)
+	}
+	return nil
+}
+
+// startPeerReputationSaver persists the reputation of peers periodically, so
+// that it survives unclean shutdowns.
+func (vm *VM) startPeerReputationSaver() {
+	vm.shutdownWg.Add(1)
+	go func() {
+		defer vm.shutdownWg.Done()
+
+		ticker := time.NewTicker(peerReputationInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := vm.savePeerReputation(); err != nil {
+					log.Warn("Failed to save peer reputation", "err", err)
+				}
+			case <-vm.shutdownChan:
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,254 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/shubhamdubey02/coreth/core/rawdb"
+	"github.com/shubhamdubey02/coreth/core/txpool"
+	"github.com/shubhamdubey02/coreth/core/types"
+)
+
+// privateTxsCheckInterval is how often private transactions are checked for
+// inclusion and expiry.
+const privateTxsCheckInterval = 5 * time.Second
+
+// Statuses of private transactions.
+const (
+	// PrivateTxPending transactions have not expired nor been included in
+	// an accepted block. They may be in a block awaiting acceptance.
+	PrivateTxPending = "pending"
+	// PrivateTxIncluded transactions are included in an accepted block.
+	PrivateTxIncluded = "included"
+	// PrivateTxExpired transactions were removed from the mempool when their
+	// TTL elapsed.
+	PrivateTxExpired = "expired"
+	// PrivateTxDropped transactions left the mempool before their TTL
+	// elapsed, such as when replaced, and were not included.
+	PrivateTxDropped = "dropped"
+	// PrivateTxUnknown transactions were not submitted as private, or were
+	// resolved more than a TTL ago.
+	PrivateTxUnknown = "unknown"
+)
+
+var errPrivateTxsDisabled = errors.New("private transactions are not enabled")
+
+// privateTx is the record of a private transaction.
+type privateTx struct {
+	submitted time.Time
+	expires   time.Time
+	status    string
+	// resolved is when the status left pending.
+	resolved    time.Time
+	blockHash   common.Hash
+	blockNumber uint64
+}
+
+// privateTxPool tracks the transactions submitted through the private API,
+// which are added to the mempool but never gossiped, so that they are only
+// included in blocks built by this node.
+type privateTxPool struct {
+	txPool *txpool.TxPool
+	db     ethdb.Reader
+	ttl    time.Duration
+	now    func() time.Time
+
+	lock sync.RWMutex
+	txs  map[common.Hash]*privateTx
+}
+
+func newPrivateTxPool(txPool *txpool.TxPool, db ethdb.Reader, ttl time.Duration) *privateTxPool {
+	return &privateTxPool{
+		txPool: txPool,
+		db:     db,
+		ttl:    ttl,
+		now:    time.Now,
+		txs:    make(map[common.Hash]*privateTx),
+	}
+}
+
+// IsPrivate returns true if [hash] was submitted as a private transaction. It
+// returns false on a nil pool.
+func (p *privateTxPool) IsPrivate(hash common.Hash) bool {
+	if p == nil {
+		return false
+	}
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	_, ok := p.txs[hash]
+	return ok
+}
+
+// Submit adds [tx] to the mempool as a private transaction.
+func (p *privateTxPool) Submit(tx *types.Transaction) error {
+	hash := tx.Hash()
+	now := p.now()
+
+	// The transaction is recorded before being added to the mempool, so that
+	// it is never seen as public by the gossip subscription.
+	p.lock.Lock()
+	if _, ok := p.txs[hash]; ok {
+		p.lock.Unlock()
+		return txpool.ErrAlreadyKnown
+	}
+	p.txs[hash] = &privateTx{
+		submitted: now,
+		expires:   now.Add(p.ttl),
+		status:    PrivateTxPending,
+	}
+	p.lock.Unlock()
+
+	if err := p.txPool.Add([]*types.Transaction{tx}, false, true)[0]; err != nil {
+		p.lock.Lock()
+		delete(p.txs, hash)
+		p.lock.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Status returns the record of [hash], or nil if it is unknown.
+func (p *privateTxPool) Status(hash common.Hash) *privateTx {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	tx, ok := p.txs[hash]
+	if !ok {
+		return nil
+	}
+	record := *tx
+	return &record
+}
+
+// update records the inclusion of pending private transactions, removes the
+// expired ones from the mempool and forgets those resolved more than a TTL
+// ago.
+func (p *privateTxPool) update() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	now := p.now()
+	for hash, tx := range p.txs {
+		if tx.status != PrivateTxPending {
+			if now.Sub(tx.resolved) > p.ttl {
+				delete(p.txs, hash)
+			}
+			continue
+		}
+		if included, blockHash, blockNumber, _ := rawdb.ReadTransaction(p.db, hash); included != nil {
+			tx.status = PrivateTxIncluded
+			tx.blockHash = blockHash
+			tx.blockNumber = blockNumber
+			tx.resolved = now
+			continue
+		}
+		if now.Before(tx.expires) {
+			continue
+		}
+		if p.txPool.Remove(hash) {
+			tx.status = PrivateTxExpired
+			log.Debug("Expired private transaction", "hash", hash)
+		} else {
+			tx.status = PrivateTxDropped
+		}
+		tx.resolved = now
+	}
+}
+
+// startPrivateTxs tracks the private transactions in the background until
+// the VM shuts down.
+func (vm *VM) startPrivateTxs() {
+	if !vm.config.PrivateTxs.Enabled {
+		return
+	}
+	vm.privateTxs = newPrivateTxPool(vm.txPool, vm.chaindb, vm.config.PrivateTxs.TTL.Duration)
+
+	vm.shutdownWg.Add(1)
+	go vm.ctx.Log.RecoverAndPanic(func() {
+		defer vm.shutdownWg.Done()
+
+		ticker := time.NewTicker(privateTxsCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				vm.privateTxs.update()
+			case <-vm.shutdownChan:
+				return
+			}
+		}
+	})
+}
+
+// PrivateTxAPI submits transactions that are never gossiped, so that they
+// are only included in blocks built by this node and are not visible to
+// other nodes before then.
+type PrivateTxAPI struct {
+	vm *VM
+}
+
+// SendRawTransaction adds the signed transaction [input] to the mempool
+// without gossiping it, and returns its hash.
+func (api *PrivateTxAPI) SendRawTransaction(ctx context.Context, input hexutil.Bytes) (common.Hash, error) {
+	if api.vm.privateTxs == nil {
+		return common.Hash{}, errPrivateTxsDisabled
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(input); err != nil {
+		return common.Hash{}, err
+	}
+	if err := api.vm.privateTxs.Submit(tx); err != nil {
+		return common.Hash{}, err
+	}
+	return tx.Hash(), nil
+}
+
+// PrivateTxStatus is the status of a private transaction.
+type PrivateTxStatus struct {
+	Status string `json:"status"`
+	// InMempool is true if the transaction is in the mempool of this node.
+	InMempool bool            `json:"inMempool"`
+	Submitted *hexutil.Uint64 `json:"submitted,omitempty"`
+	Expires   *hexutil.Uint64 `json:"expires,omitempty"`
+	// BlockHash and BlockNumber are set once the transaction is included.
+	BlockHash   *common.Hash    `json:"blockHash,omitempty"`
+	BlockNumber *hexutil.Uint64 `json:"blockNumber,omitempty"`
+}
+
+// GetTransactionStatus returns the status of the private transaction [hash].
+// Submitted and Expires are unix timestamps.
+func (api *PrivateTxAPI) GetTransactionStatus(ctx context.Context, hash common.Hash) (*PrivateTxStatus, error) {
+	if api.vm.privateTxs == nil {
+		return nil, errPrivateTxsDisabled
+	}
+	tx := api.vm.privateTxs.Status(hash)
+	if tx == nil {
+		return &PrivateTxStatus{Status: PrivateTxUnknown}, nil
+	}
+	var (
+		submitted = hexutil.Uint64(tx.submitted.Unix())
+		expires   = hexutil.Uint64(tx.expires.Unix())
+		status    = &PrivateTxStatus{
+			Status:    tx.status,
+			InMempool: api.vm.txPool.Has(hash),
+			Submitted: &submitted,
+			Expires:   &expires,
+		}
+	)
+	if tx.status == PrivateTxIncluded {
+		number := hexutil.Uint64(tx.blockNumber)
+		status.BlockHash = &tx.blockHash
+		status.BlockNumber = &number
+	}
+	return status, nil
+}
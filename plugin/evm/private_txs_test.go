@@ -0,0 +1,81 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/params"
+)
+
+func TestPrivateTxs(t *testing.T) {
+	require := require.New(t)
+
+	h := NewTestHarness(t, TestHarnessConfig{
+		Config: `{"private-txs": {"enabled": true, "ttl": "1m"}}`,
+	})
+	var (
+		api  = &PrivateTxAPI{h.VM}
+		ctx  = context.Background()
+		now  = time.Now()
+		pool = h.VM.privateTxs
+	)
+	pool.now = func() time.Time { return now }
+
+	gossipPool, err := NewGossipEthTxPool(h.VM.txPool, prometheus.NewRegistry())
+	require.NoError(err)
+	gossipPool.private = pool
+
+	// The transaction with a nonce gap stays in the mempool until it expires.
+	included := h.SignTx(types.NewTransaction(0, common.Address{0xaa}, big.NewInt(1), params.TxGas, big.NewInt(params.LaunchMinGasPrice), nil), HarnessFunderKey)
+	gapped := h.SignTx(types.NewTransaction(2, common.Address{0xaa}, big.NewInt(1), params.TxGas, big.NewInt(params.LaunchMinGasPrice), nil), HarnessFunderKey)
+	for _, tx := range []*types.Transaction{included, gapped} {
+		input, err := tx.MarshalBinary()
+		require.NoError(err)
+		hash, err := api.SendRawTransaction(ctx, input)
+		require.NoError(err)
+		require.Equal(tx.Hash(), hash)
+	}
+	require.True(h.VM.txPool.Has(included.Hash()))
+	gossipPool.Iterate(func(tx *GossipEthTx) bool {
+		require.FailNow("private transaction offered for gossip", "hash", tx.Tx.Hash())
+		return false
+	})
+
+	status, err := api.GetTransactionStatus(ctx, included.Hash())
+	require.NoError(err)
+	require.Equal(PrivateTxPending, status.Status)
+	require.True(status.InMempool)
+
+	blk := h.BuildAndAccept()
+	pool.update()
+	status, err = api.GetTransactionStatus(ctx, included.Hash())
+	require.NoError(err)
+	require.Equal(PrivateTxIncluded, status.Status)
+	require.Equal(common.Hash(blk.ID()), *status.BlockHash)
+	require.EqualValues(blk.Height(), *status.BlockNumber)
+
+	now = now.Add(time.Minute)
+	pool.update()
+	require.False(h.VM.txPool.Has(gapped.Hash()))
+	status, err = api.GetTransactionStatus(ctx, gapped.Hash())
+	require.NoError(err)
+	require.Equal(PrivateTxExpired, status.Status)
+	require.False(status.InMempool)
+
+	// Resolved transactions are forgotten after another TTL.
+	now = now.Add(2 * time.Minute)
+	pool.update()
+	status, err = api.GetTransactionStatus(ctx, included.Hash())
+	require.NoError(err)
+	require.Equal(PrivateTxUnknown, status.Status)
+}
@@ -0,0 +1,49 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// receiptsFetchTimeout bounds the time spent fetching the receipts of a
+// block being verified from peers.
+const receiptsFetchTimeout = time.Minute
+
+var errReceiptsVerificationBuild = errors.New("cannot build blocks while verifying receipts instead of executing blocks")
+
+// verifyReceipts inserts the block with its receipts downloaded from peers
+// and verified against its header, instead of executing it. The atomic txs
+// of the block are inserted into the atomic backend as executing the block
+// would.
+func (b *Block) verifyReceipts(writes bool) error {
+	vm := b.vm
+	if !writes {
+		return nil
+	}
+	if vm.syncClient == nil {
+		return errors.New("no client to fetch receipts from peers")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), receiptsFetchTimeout)
+	defer cancel()
+	receipts, err := vm.syncClient.GetReceipts(ctx, b.ethBlock)
+	if err != nil {
+		return fmt.Errorf("failed to fetch receipts: %w", err)
+	}
+
+	if _, err := vm.atomicBackend.InsertTxs(b.ethBlock.Hash(), b.ethBlock.NumberU64(), b.ethBlock.ParentHash(), b.atomicTxs); err != nil {
+		return err
+	}
+	if err := vm.blockChain.InsertBlockWithReceipts(b.ethBlock, receipts); err != nil {
+		if atomicState, err := vm.atomicBackend.GetVerifiedAtomicState(b.ethBlock.Hash()); err == nil {
+			_ = atomicState.Reject() // ignore this error so we can return the original error instead.
+		}
+		return err
+	}
+	return nil
+}
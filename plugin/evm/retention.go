@@ -0,0 +1,337 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/shubhamdubey02/coreth/core/rawdb"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/eth/tracers"
+	"github.com/shubhamdubey02/coreth/params"
+	"github.com/shubhamdubey02/cryftgo/utils/json"
+)
+
+const (
+	retentionInterval = time.Minute
+
+	retentionBodies       = "bodies"
+	retentionReceipts     = rawdb.RetentionReceipts
+	retentionTxIndex      = "tx-index"
+	retentionLogsIndex    = rawdb.RetentionLogsIndex
+	retentionBlobSidecars = "blob-sidecars"
+	retentionTraces       = "traces"
+)
+
+// retentionClass is a class of block data deleted by the retention policy.
+type retentionClass struct {
+	name string
+	rule RetentionRule
+	// requiresBody is set for data that cannot be served once the block
+	// body is deleted, whose retention is bounded by that of bodies.
+	requiresBody bool
+	// first is the lowest height whose data may be deleted, and step the
+	// number of heights whose data is deleted together.
+	first, step uint64
+	// prune deletes the data of the [step] heights from [number].
+	prune func(batch ethdb.Batch, number uint64) error
+}
+
+// RetentionClassStatus reports the data of a class deleted by the retention
+// policy.
+type RetentionClassStatus struct {
+	// Tail is the first height whose data is retained.
+	Tail json.Uint64 `json:"tail"`
+	// Deleted is the number of heights whose data was deleted since startup.
+	Deleted json.Uint64 `json:"deleted"`
+}
+
+// retentionEngine deletes the block data of accepted blocks once it falls
+// outside of the retention declared for its class. Each class is deleted
+// from its tail upwards, and the tail is persisted with every batch so that
+// deletion resumes where it left off after a restart.
+type retentionEngine struct {
+	db             ethdb.Database
+	commitInterval uint64
+	bodies         RetentionRule
+	classes        []*retentionClass
+	now            func() time.Time
+	// compaction is notified of the heights deleted from each class, and is
+	// nil unless the compaction scheduler is enabled.
+	compaction *rawdb.CompactionScheduler
+	// consumerOffset returns the lowest offset of the accepted block
+	// consumers, whose unprocessed blocks keep their bodies. It is nil if
+	// there are no consumers.
+	consumerOffset func() (uint64, bool)
+
+	lock    sync.Mutex
+	status  map[string]*RetentionClassStatus
+	lastRun time.Time
+}
+
+func newRetentionEngine(db ethdb.Database, config RetentionConfig, commitInterval uint64) (*retentionEngine, error) {
+	e := &retentionEngine{
+		db:             db,
+		commitInterval: commitInterval,
+		bodies:         config.Bodies,
+		now:            time.Now,
+		status:         make(map[string]*RetentionClassStatus),
+	}
+	// Classes are deleted in order, so that the transaction index is deleted
+	// while the bodies it is derived from are still available.
+	e.classes = []*retentionClass{
+		{
+			name:         retentionTxIndex,
+			rule:         config.TxIndex,
+			requiresBody: true,
+			first:        1,
+			step:         1,
+			prune: func(batch ethdb.Batch, number uint64) error {
+				e.deleteTxLookupEntries(batch, number)
+				return nil
+			},
+		},
+		{
+			name: retentionLogsIndex,
+			rule: config.LogsIndex,
+			step: params.BloomBitsBlocks,
+			prune: func(batch ethdb.Batch, number uint64) error {
+				// Bloom bits are keyed by the hash of the last block of their
+				// section.
+				section := number / params.BloomBitsBlocks
+				head := rawdb.ReadCanonicalHash(db, (section+1)*params.BloomBitsBlocks-1)
+				for bit := uint(0); bit < types.BloomBitLength; bit++ {
+					rawdb.DeleteBloomBits(batch, bit, section, head)
+				}
+				return nil
+			},
+		},
+		{
+			name:         retentionReceipts,
+			rule:         config.Receipts,
+			requiresBody: true,
+			first:        1,
+			step:         1,
+			prune: func(batch ethdb.Batch, number uint64) error {
+				hash := rawdb.ReadCanonicalHash(db, number)
+				rawdb.DeleteReceipts(batch, hash, number)
+				rawdb.DeleteReceiptCosts(batch, hash, number)
+				rawdb.DeleteBlockAcceptance(batch, hash, number)
+				return nil
+			},
+		},
+		{
+			name:  retentionBlobSidecars,
+			rule:  config.BlobSidecars,
+			first: 1,
+			step:  1,
+			prune: func(batch ethdb.Batch, number uint64) error {
+				rawdb.DeleteBlobSidecars(batch, rawdb.ReadCanonicalHash(db, number), number)
+				return nil
+			},
+		},
+		{
+			name:  retentionTraces,
+			rule:  config.Traces,
+			first: 1,
+			step:  1,
+			prune: func(batch ethdb.Batch, number uint64) error {
+				return tracers.DeleteIndexedTraces(db, batch, number)
+			},
+		},
+		{
+			name:  retentionBodies,
+			rule:  config.Bodies,
+			first: 1,
+			step:  1,
+			prune: func(batch ethdb.Batch, number uint64) error {
+				e.deleteTxLookupEntries(batch, number)
+				rawdb.DeleteBody(batch, rawdb.ReadCanonicalHash(db, number), number)
+				return nil
+			},
+		},
+	}
+	for _, class := range e.classes {
+		tail, err := rawdb.ReadRetentionTail(db, class.name)
+		if err != nil {
+			return nil, err
+		}
+		status := &RetentionClassStatus{Tail: json.Uint64(class.first)}
+		if tail != nil {
+			status.Tail = json.Uint64(*tail)
+		}
+		e.status[class.name] = status
+	}
+	return e, nil
+}
+
+// deleteTxLookupEntries deletes the transaction index of the canonical block
+// at [number].
+func (e *retentionEngine) deleteTxLookupEntries(batch ethdb.Batch, number uint64) {
+	body := rawdb.ReadBody(e.db, rawdb.ReadCanonicalHash(e.db, number), number)
+	if body == nil {
+		return
+	}
+	hashes := make([]common.Hash, len(body.Transactions))
+	for i, tx := range body.Transactions {
+		hashes[i] = tx.Hash()
+	}
+	rawdb.DeleteTxLookupEntries(batch, hashes)
+}
+
+// cutoff returns the first height whose data is retained by [rule], given
+// that [head] is the last accepted height.
+func (e *retentionEngine) cutoff(rule RetentionRule, head uint64) uint64 {
+	if !rule.enabled() {
+		return 0
+	}
+	// The blocks since the last committed state are reprocessed on restart.
+	if head < e.commitInterval {
+		return 0
+	}
+	cutoff := head - e.commitInterval
+	if rule.Blocks > 0 {
+		cutoff = min(cutoff, head+1-min(rule.Blocks, head+1))
+	}
+	if rule.Duration.Duration > 0 {
+		oldest := uint64(e.now().Add(-rule.Duration.Duration).Unix())
+		cutoff = min(cutoff, uint64(sort.Search(int(cutoff), func(i int) bool {
+			number := uint64(i)
+			header := rawdb.ReadHeader(e.db, rawdb.ReadCanonicalHash(e.db, number), number)
+			return header == nil || header.Time >= oldest
+		})))
+	}
+	return cutoff
+}
+
+// run deletes the data of each class outside of its retention, given that
+// [head] is the last accepted height. It returns early once [quit] is closed.
+func (e *retentionEngine) run(head uint64, quit <-chan struct{}) error {
+	bodiesCutoff := e.cutoff(e.bodies, head)
+	if e.consumerOffset != nil {
+		// The blocks not yet processed by every consumer are read back from
+		// the chain as they are delivered.
+		if offset, ok := e.consumerOffset(); ok {
+			bodiesCutoff = min(bodiesCutoff, offset+1)
+		}
+	}
+	for _, class := range e.classes {
+		cutoff := e.cutoff(class.rule, head)
+		if class.name == retentionBodies {
+			cutoff = bodiesCutoff
+		}
+		if class.requiresBody {
+			cutoff = max(cutoff, bodiesCutoff)
+		}
+		cutoff -= cutoff % class.step
+		if err := e.prune(class, cutoff, quit); err != nil {
+			return err
+		}
+	}
+	e.lock.Lock()
+	e.lastRun = e.now()
+	e.lock.Unlock()
+	return nil
+}
+
+// prune deletes the data of [class] below [cutoff].
+func (e *retentionEngine) prune(class *retentionClass, cutoff uint64, quit <-chan struct{}) error {
+	e.lock.Lock()
+	tail := uint64(e.status[class.name].Tail)
+	e.lock.Unlock()
+	if tail >= cutoff {
+		return nil
+	}
+
+	var (
+		start = time.Now()
+		from  = tail
+		batch = e.db.NewBatch()
+	)
+	for number := tail; number < cutoff; {
+		if err := class.prune(batch, number); err != nil {
+			return err
+		}
+		number += class.step
+		if number < cutoff && batch.ValueSize() < ethdb.IdealBatchSize {
+			continue
+		}
+		if err := rawdb.WriteRetentionTail(batch, class.name, number); err != nil {
+			return err
+		}
+		if err := batch.Write(); err != nil {
+			return err
+		}
+		batch.Reset()
+
+		e.lock.Lock()
+		status := e.status[class.name]
+		status.Tail = json.Uint64(number)
+		status.Deleted += json.Uint64(number - tail)
+		e.lock.Unlock()
+		e.compaction.RecordDeletions(class.name, number-tail)
+		tail = number
+
+		select {
+		case <-quit:
+			return nil
+		default:
+		}
+	}
+	log.Info("Deleted block data outside of retention", "class", class.name, "from", from, "to", tail, "elapsed", time.Since(start))
+	return nil
+}
+
+// Status returns the status of each class and the time of the last
+// completed run.
+func (e *retentionEngine) Status() (map[string]RetentionClassStatus, time.Time) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	status := make(map[string]RetentionClassStatus, len(e.status))
+	for name, class := range e.status {
+		status[name] = *class
+	}
+	return status, e.lastRun
+}
+
+// startRetention enforces the retention policy in the background until the
+// VM shuts down.
+func (vm *VM) startRetention() error {
+	if !vm.config.Retention.enabled() {
+		return nil
+	}
+	engine, err := newRetentionEngine(vm.chaindb, vm.config.Retention, vm.config.CommitInterval)
+	if err != nil {
+		return err
+	}
+	engine.compaction = vm.compaction
+	engine.consumerOffset = vm.blockChain.MinAcceptedConsumerOffset
+	vm.retention = engine
+
+	vm.shutdownWg.Add(1)
+	go vm.ctx.Log.RecoverAndPanic(func() {
+		defer vm.shutdownWg.Done()
+
+		ticker := time.NewTicker(retentionInterval)
+		defer ticker.Stop()
+		for {
+			head := vm.blockChain.LastAcceptedBlock().NumberU64()
+			if err := engine.run(head, vm.shutdownChan); err != nil {
+				log.Error("Failed to enforce retention policy", "err", err)
+			}
+			select {
+			case <-ticker.C:
+			case <-vm.shutdownChan:
+				return
+			}
+		}
+	})
+	return nil
+}
@@ -0,0 +1,146 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shubhamdubey02/coreth/core/rawdb"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/trie"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetentionEngine(t *testing.T) {
+	require := require.New(t)
+
+	// Block i has timestamp 10*i and a single transaction.
+	const head = 20
+	db := rawdb.NewMemoryDatabase()
+	blocks := make([]*types.Block, head+1)
+	for i := uint64(0); i <= head; i++ {
+		tx := types.NewTransaction(i, common.Address{1}, big.NewInt(1), 21000, big.NewInt(1), nil)
+		receipts := types.Receipts{{Status: types.ReceiptStatusSuccessful, TxHash: tx.Hash()}}
+		header := &types.Header{Number: new(big.Int).SetUint64(i), Time: 10 * i}
+		blocks[i] = types.NewBlock(header, types.Transactions{tx}, nil, receipts, trie.NewStackTrie(nil))
+		rawdb.WriteBlock(db, blocks[i])
+		rawdb.WriteCanonicalHash(db, blocks[i].Hash(), i)
+		rawdb.WriteReceipts(db, blocks[i].Hash(), i, receipts)
+		rawdb.WriteTxLookupEntriesByBlock(db, blocks[i])
+	}
+
+	config := RetentionConfig{
+		// Bodies below 10 are deleted, which bounds the receipts.
+		Bodies: RetentionRule{Blocks: 11},
+		// The duration keeps the index from block 3 onwards, which the
+		// retention of bodies raises to 10.
+		TxIndex: RetentionRule{Blocks: 6, Duration: Duration{145 * time.Second}},
+	}
+	engine, err := newRetentionEngine(db, config, 4)
+	require.NoError(err)
+	now := time.Unix(175, 0)
+	engine.now = func() time.Time { return now }
+
+	require.NoError(engine.run(head, nil))
+	status, lastRun := engine.Status()
+	require.Equal(now, lastRun)
+	require.Equal(RetentionClassStatus{Tail: 10, Deleted: 9}, status[retentionBodies])
+	require.Equal(RetentionClassStatus{Tail: 10, Deleted: 9}, status[retentionReceipts])
+	require.Equal(RetentionClassStatus{Tail: 10, Deleted: 9}, status[retentionTxIndex])
+	require.Equal(RetentionClassStatus{Tail: 0}, status[retentionLogsIndex])
+
+	// The genesis block is never deleted.
+	for i, block := range blocks[1:] {
+		retained := i+1 >= 10
+		require.Equal(retained, rawdb.HasBody(db, block.Hash(), block.NumberU64()), "body %d", i+1)
+		require.Equal(retained, rawdb.HasReceipts(db, block.Hash(), block.NumberU64()), "receipts %d", i+1)
+		require.Equal(retained, rawdb.ReadTxLookupEntry(db, block.Transactions()[0].Hash()) != nil, "tx index %d", i+1)
+	}
+	require.True(rawdb.HasBody(db, blocks[0].Hash(), 0))
+
+	// The tails are persisted, so a new engine resumes from them.
+	tail, err := rawdb.ReadRetentionTail(db, retentionBodies)
+	require.NoError(err)
+	require.Equal(uint64(10), *tail)
+	engine, err = newRetentionEngine(db, config, 4)
+	require.NoError(err)
+	status, _ = engine.Status()
+	require.Equal(RetentionClassStatus{Tail: 10}, status[retentionBodies])
+}
+
+func TestRetentionEngineConsumerOffset(t *testing.T) {
+	require := require.New(t)
+
+	const head = 20
+	db := rawdb.NewMemoryDatabase()
+	blocks := make([]*types.Block, head+1)
+	for i := uint64(0); i <= head; i++ {
+		header := &types.Header{Number: new(big.Int).SetUint64(i)}
+		blocks[i] = types.NewBlockWithHeader(header)
+		rawdb.WriteBlock(db, blocks[i])
+		rawdb.WriteCanonicalHash(db, blocks[i].Hash(), i)
+	}
+
+	engine, err := newRetentionEngine(db, RetentionConfig{Bodies: RetentionRule{Blocks: 5}}, 1)
+	require.NoError(err)
+	// A consumer has processed the blocks up to 7, so the bodies of the
+	// blocks above it are kept for it.
+	offset := uint64(7)
+	engine.consumerOffset = func() (uint64, bool) { return offset, true }
+	require.NoError(engine.run(head, nil))
+	status, _ := engine.Status()
+	require.Equal(RetentionClassStatus{Tail: 8, Deleted: 7}, status[retentionBodies])
+	require.False(rawdb.HasBody(db, blocks[7].Hash(), 7))
+	require.True(rawdb.HasBody(db, blocks[8].Hash(), 8))
+
+	// Once the consumer catches up, the bodies outside of the retention are
+	// deleted.
+	offset = head
+	require.NoError(engine.run(head, nil))
+	status, _ = engine.Status()
+	require.Equal(RetentionClassStatus{Tail: 16, Deleted: 15}, status[retentionBodies])
+}
+
+func TestRetentionEngineTraces(t *testing.T) {
+	require := require.New(t)
+
+	const head = 10
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		from    = common.Address{1}
+		to      = common.Address{2}
+		indexed = func(number uint64) bool {
+			var found bool
+			require.NoError(rawdb.IterateTraceAddressIndex(db, to, number, number, func(uint64) bool {
+				found = true
+				return false
+			}))
+			return found
+		}
+	)
+	// The traces of the blocks from 3 onwards are indexed.
+	rawdb.WriteTraceIndexTail(db, 3)
+	for i := uint64(3); i <= head; i++ {
+		rawdb.WriteBlockTraces(db, i, []byte(`[{"action":{"from":"`+from.Hex()+`","to":"`+to.Hex()+`"}}]`))
+		rawdb.WriteTraceAddressIndex(db, from, i)
+		rawdb.WriteTraceAddressIndex(db, to, i)
+	}
+
+	engine, err := newRetentionEngine(db, RetentionConfig{Traces: RetentionRule{Blocks: 4}}, 1)
+	require.NoError(err)
+	require.NoError(engine.run(head, nil))
+	status, _ := engine.Status()
+	require.Equal(RetentionClassStatus{Tail: 7, Deleted: 6}, status[retentionTraces])
+
+	for i := uint64(3); i <= head; i++ {
+		retained := i >= 7
+		require.Equal(retained, rawdb.ReadBlockTraces(db, i) != nil, "traces %d", i)
+		require.Equal(retained, indexed(i), "address index %d", i)
+	}
+	// trace_filter rejects the ranges whose traces were deleted.
+	require.Equal(uint64(7), *rawdb.ReadTraceIndexTail(db))
+}
@@ -0,0 +1,81 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"net/http"
+
+	avalancheRPC "github.com/gorilla/rpc/v2"
+	"github.com/gorilla/rpc/v2/json2"
+	"github.com/shubhamdubey02/coreth/rpc/errcodes"
+	"github.com/shubhamdubey02/cryftgo/ids"
+)
+
+// atomicTxErrorCodes assigns codes to the errors of the atomic mempool and of
+// the verification of atomic transactions rejecting a transaction.
+var atomicTxErrorCodes = []errcodes.Rule{
+	{Err: errTxAlreadyKnown, Code: errcodes.TxAlreadyKnown},
+	{Err: errInsufficientAtomicTxFee, Code: errcodes.TxUnderpriced},
+	{Err: errConflictingAtomicTx, Code: errcodes.TxReplacementUnderpriced},
+	{Err: errTooManyAtomicTx, Code: errcodes.TxPoolFull},
+	{Err: errInsufficientFunds, Code: errcodes.TxInsufficientFunds},
+	{Err: errInsufficientFundsForFee, Code: errcodes.TxInsufficientFunds},
+	{Err: errInvalidNonce, Code: errcodes.TxInvalid},
+	{Err: errConflictingAtomicInputs, Code: errcodes.TxInvalid},
+	{Err: errWrongChainID, Code: errcodes.TxInvalid},
+	{Err: errWrongNetworkID, Code: errcodes.TxInvalid},
+	{Err: errWrongBlockchainID, Code: errcodes.TxInvalid},
+	{Err: errNoImportInputs, Code: errcodes.TxInvalid},
+	{Err: errNoExportOutputs, Code: errcodes.TxInvalid},
+	{Err: errNoEVMOutputs, Code: errcodes.TxInvalid},
+	{Err: errAssetIDMismatch, Code: errcodes.TxInvalid},
+	{Err: errPublicKeySignatureMismatch, Code: errcodes.TxInvalid},
+	{Err: errInputsNotSortedUnique, Code: errcodes.TxInvalid},
+	{Err: errOutputsNotSorted, Code: errcodes.TxInvalid},
+	{Err: errOutputsNotSortedUnique, Code: errcodes.TxInvalid},
+	{Err: errImportNonCRYFTInputBanff, Code: errcodes.TxInvalid},
+	{Err: errImportNonCRYFTOutputBanff, Code: errcodes.TxInvalid},
+	{Err: errExportNonCRYFTInputBanff, Code: errcodes.TxInvalid},
+	{Err: errExportNonCRYFTOutputBanff, Code: errcodes.TxInvalid},
+}
+
+// atomicTxError returns [err], rejecting the atomic transaction [txID], with
+// the code of its reason and [txID] as detail. Errors without a code are
+// returned unchanged.
+func atomicTxError(txID ids.ID, err error) error {
+	coded, ok := errcodes.Classify(err, atomicTxErrorCodes).(*errcodes.Error)
+	if !ok {
+		return err
+	}
+	if txID == ids.Empty {
+		return coded
+	}
+	return coded.WithDetail("txID", txID)
+}
+
+// errorCodec writes the errors of a gorilla RPC service carrying a code with
+// their code and data, as the eth APIs do, instead of the generic server
+// error code.
+type errorCodec struct {
+	avalancheRPC.Codec
+}
+
+func (c errorCodec) NewRequest(r *http.Request) avalancheRPC.CodecRequest {
+	return errorCodecRequest{c.Codec.NewRequest(r)}
+}
+
+type errorCodecRequest struct {
+	avalancheRPC.CodecRequest
+}
+
+func (r errorCodecRequest) WriteError(w http.ResponseWriter, status int, err error) {
+	if coded, ok := errcodes.As(err); ok {
+		err = &json2.Error{
+			Code:    json2.ErrorCode(coded.Code),
+			Message: err.Error(),
+			Data:    coded.ErrorData(),
+		}
+	}
+	r.CodecRequest.WriteError(w, status, err)
+}
@@ -0,0 +1,67 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shubhamdubey02/coreth/rpc/errcodes"
+	"github.com/shubhamdubey02/cryftgo/api"
+	"github.com/shubhamdubey02/cryftgo/ids"
+	"github.com/stretchr/testify/require"
+)
+
+type errorTestService struct{}
+
+func (errorTestService) Issue(_ *http.Request, _ *struct{}, _ *api.EmptyReply) error {
+	return atomicTxError(ids.ID{1}, fmt.Errorf("%w: mempool has 10 txs", errTooManyAtomicTx))
+}
+
+func (errorTestService) Fail(_ *http.Request, _ *struct{}, _ *api.EmptyReply) error {
+	return fmt.Errorf("unclassified")
+}
+
+func TestErrorCodec(t *testing.T) {
+	require := require.New(t)
+
+	handler, err := newHandler("test", errorTestService{})
+	require.NoError(err)
+
+	call := func(method string) (int, string, *errcodes.Data) {
+		body := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"test.%s","params":{}}`, method)
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		var res struct {
+			Error struct {
+				Code    int            `json:"code"`
+				Message string         `json:"message"`
+				Data    *errcodes.Data `json:"data"`
+			} `json:"error"`
+		}
+		require.NoError(json.Unmarshal(rec.Body.Bytes(), &res))
+		return res.Error.Code, res.Error.Message, res.Error.Data
+	}
+
+	code, message, data := call("issue")
+	require.Equal(int(errcodes.TxPoolFull), code)
+	require.Equal("too many atomic tx: mempool has 10 txs", message)
+	require.Equal(&errcodes.Data{
+		Reason:  "TX_POOL_FULL",
+		Details: map[string]interface{}{"txID": ids.ID{1}.String()},
+	}, data)
+
+	// Errors without a code keep the generic server error code.
+	code, message, data = call("fail")
+	require.Equal(-32000, code)
+	require.Equal("unclassified", message)
+	require.Nil(data)
+}
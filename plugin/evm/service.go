@@ -5,6 +5,7 @@ package evm
 
 import (
 	"context"
+	stdjson "encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
@@ -13,13 +14,17 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/shubhamdubey02/coreth/consensus/dummy"
 	"github.com/shubhamdubey02/coreth/params"
+	"github.com/shubhamdubey02/coreth/rpc/errcodes"
 	"github.com/shubhamdubey02/cryftgo/api"
 	"github.com/shubhamdubey02/cryftgo/ids"
 	"github.com/shubhamdubey02/cryftgo/utils/crypto/secp256k1"
 	"github.com/shubhamdubey02/cryftgo/utils/formatting"
 	"github.com/shubhamdubey02/cryftgo/utils/json"
+	"github.com/shubhamdubey02/cryftgo/utils/math"
 	"github.com/shubhamdubey02/cryftgo/utils/set"
+	"github.com/shubhamdubey02/cryftgo/vms/secp256k1fx"
 )
 
 // test constants
@@ -29,6 +34,9 @@ const (
 
 	// Max number of addresses that can be passed in as argument to GetUTXOs
 	maxGetUTXOsAddrs = 1024
+
+	// Max number of blocks ahead EstimateAtomicTxFee projects the base fee
+	maxAtomicTxFeeProjectionBlocks = 1024
 )
 
 var (
@@ -38,6 +46,10 @@ var (
 	errMissingPrivateKey = errors.New("argument 'privateKey' not given")
 
 	initialBaseFee = big.NewInt(params.ApricotPhase3InitialBaseFee)
+
+	// Blocks ahead EstimateAtomicTxFee projects the base fee by default,
+	// about 20 seconds and 2 minutes at the target block rate
+	defaultAtomicTxFeeProjectionBlocks = []json.Uint64{10, 60}
 )
 
 // SnowmanAPI introduces snowman specific functionality to the evm
@@ -221,12 +233,12 @@ func (service *CryftAPI) Import(_ *http.Request, args *ImportArgs, response *api
 
 	tx, err := service.vm.newImportTx(chainID, args.To, baseFee, privKeys)
 	if err != nil {
-		return err
+		return atomicTxError(ids.Empty, err)
 	}
 
 	response.TxID = tx.ID()
 	if err := service.vm.mempool.AddLocalTx(tx); err != nil {
-		return err
+		return atomicTxError(tx.ID(), err)
 	}
 	service.vm.atomicTxPushGossiper.Add(&GossipAtomicTx{tx})
 	return nil
@@ -331,17 +343,198 @@ func (service *CryftAPI) Export(_ *http.Request, args *ExportArgs, response *api
 		privKeys, // Private keys
 	)
 	if err != nil {
-		return fmt.Errorf("couldn't create tx: %w", err)
+		return atomicTxError(ids.Empty, fmt.Errorf("couldn't create tx: %w", err))
 	}
 
 	response.TxID = tx.ID()
 	if err := service.vm.mempool.AddLocalTx(tx); err != nil {
-		return err
+		return atomicTxError(tx.ID(), err)
 	}
 	service.vm.atomicTxPushGossiper.Add(&GossipAtomicTx{tx})
 	return nil
 }
 
+// BuildImportArgs are the arguments to BuildImport
+type BuildImportArgs struct {
+	// Fee that should be used when creating the tx
+	BaseFee *hexutil.Big `json:"baseFee"`
+
+	// Chain the funds are coming from
+	SourceChain string `json:"sourceChain"`
+
+	// Addresses that own the funds to import
+	From []string `json:"from"`
+
+	// The address that will receive the imported funds
+	To common.Address `json:"to"`
+
+	// Encoding of the returned transaction
+	Encoding formatting.Encoding `json:"encoding"`
+}
+
+// BuildTxReply is the response from BuildImport and BuildExport
+type BuildTxReply struct {
+	// UnsignedTx is the unsigned transaction. Its credentials sign the SHA256
+	// hash of these bytes.
+	UnsignedTx string              `json:"unsignedTx"`
+	Encoding   formatting.Encoding `json:"encoding"`
+	// Amount of CRYFT burned by the transaction
+	Fee json.Uint64 `json:"fee"`
+}
+
+// BuildImport returns an unsigned transaction importing all the funds on
+// [SourceChain] that the [From] addresses can spend, so that it can be signed
+// outside of the node.
+func (service *CryftAPI) BuildImport(_ *http.Request, args *BuildImportArgs, reply *BuildTxReply) error {
+	log.Info("EVM: BuildImport called")
+
+	if len(args.From) == 0 {
+		return errNoAddresses
+	}
+	if len(args.From) > maxGetUTXOsAddrs {
+		return fmt.Errorf("number of addresses given, %d, exceeds maximum, %d", len(args.From), maxGetUTXOsAddrs)
+	}
+	chainID, err := service.vm.ctx.BCLookup.Lookup(args.SourceChain)
+	if err != nil {
+		return fmt.Errorf("problem parsing chainID %q: %w", args.SourceChain, err)
+	}
+	addrs := set.NewSet[ids.ShortID](len(args.From))
+	for _, addrStr := range args.From {
+		addr, err := service.vm.ParseServiceAddress(addrStr)
+		if err != nil {
+			return fmt.Errorf("couldn't parse address %q: %w", addrStr, err)
+		}
+		addrs.Add(addr)
+	}
+
+	service.vm.ctx.Lock.Lock()
+	defer service.vm.ctx.Lock.Unlock()
+
+	var baseFee *big.Int
+	if args.BaseFee == nil {
+		// Get the base fee to use
+		baseFee, err = service.vm.estimateBaseFee(context.Background())
+		if err != nil {
+			return err
+		}
+	} else {
+		baseFee = args.BaseFee.ToInt()
+	}
+
+	utx, err := service.vm.newUnsignedImportTx(chainID, args.To, baseFee, addrs)
+	if err != nil {
+		return err
+	}
+	return service.buildTxReply(utx, args.Encoding, reply)
+}
+
+// BuildExportArgs are the arguments to BuildExport
+type BuildExportArgs struct {
+	// Fee that should be used when creating the tx
+	BaseFee *hexutil.Big `json:"baseFee"`
+
+	// Amount of asset to send
+	Amount json.Uint64 `json:"amount"`
+
+	// AssetID of the tokens. Defaults to CRYFT.
+	AssetID string `json:"assetID"`
+
+	// Chain the funds are going to. Optional. Used if To address does not
+	// include the chainID.
+	TargetChain string `json:"targetChain"`
+
+	// Addresses that pay the fee and provide the tokens
+	From []common.Address `json:"from"`
+
+	// ID of the address that will receive the funds. This address may include
+	// the chainID, which is used to determine what the destination chain is.
+	To string `json:"to"`
+
+	// Encoding of the returned transaction
+	Encoding formatting.Encoding `json:"encoding"`
+}
+
+// BuildExport returns an unsigned transaction exporting [Amount] of [AssetID]
+// from the [From] addresses, so that it can be signed outside of the node.
+func (service *CryftAPI) BuildExport(_ *http.Request, args *BuildExportArgs, reply *BuildTxReply) error {
+	log.Info("EVM: BuildExport called")
+
+	assetID := service.vm.ctx.CRYFTAssetID
+	if args.AssetID != "" {
+		var err error
+		assetID, err = service.parseAssetID(args.AssetID)
+		if err != nil {
+			return err
+		}
+	}
+	if args.Amount == 0 {
+		return errors.New("argument 'amount' must be > 0")
+	}
+	if len(args.From) == 0 {
+		return errNoAddresses
+	}
+	from := set.Of(args.From...)
+	if from.Len() != len(args.From) {
+		return errors.New("argument 'from' contains duplicate addresses")
+	}
+
+	// Get the chainID and parse the to address
+	chainID, to, err := service.vm.ParseAddress(args.To)
+	if err != nil {
+		chainID, err = service.vm.ctx.BCLookup.Lookup(args.TargetChain)
+		if err != nil {
+			return err
+		}
+		to, err = ids.ShortFromString(args.To)
+		if err != nil {
+			return err
+		}
+	}
+
+	service.vm.ctx.Lock.Lock()
+	defer service.vm.ctx.Lock.Unlock()
+
+	var baseFee *big.Int
+	if args.BaseFee == nil {
+		// Get the base fee to use
+		baseFee, err = service.vm.estimateBaseFee(context.Background())
+		if err != nil {
+			return err
+		}
+	} else {
+		baseFee = args.BaseFee.ToInt()
+	}
+
+	utx, err := service.vm.newUnsignedExportTx(assetID, uint64(args.Amount), chainID, to, baseFee, args.From)
+	if err != nil {
+		return fmt.Errorf("couldn't create tx: %w", err)
+	}
+	return service.buildTxReply(utx, args.Encoding, reply)
+}
+
+// buildTxReply verifies [utx] and writes it to [reply] in [encoding]
+func (service *CryftAPI) buildTxReply(utx UnsignedAtomicTx, encoding formatting.Encoding, reply *BuildTxReply) error {
+	tx := &Tx{UnsignedAtomicTx: utx}
+	if err := tx.Sign(service.vm.codec, nil); err != nil {
+		return err
+	}
+	if err := utx.Verify(service.vm.ctx, service.vm.currentRules()); err != nil {
+		return err
+	}
+	fee, err := utx.Burned(service.vm.ctx.CRYFTAssetID)
+	if err != nil {
+		return err
+	}
+	txStr, err := formatting.Encode(encoding, utx.Bytes())
+	if err != nil {
+		return fmt.Errorf("problem encoding tx: %w", err)
+	}
+	reply.UnsignedTx = txStr
+	reply.Encoding = encoding
+	reply.Fee = json.Uint64(fee)
+	return nil
+}
+
 // GetUTXOs gets all utxos for passed in addresses
 func (service *CryftAPI) GetUTXOs(r *http.Request, args *api.GetUTXOsArgs, reply *api.GetUTXOsReply) error {
 	log.Info("EVM: GetUTXOs called", "Addresses", args.Addresses)
@@ -429,15 +622,15 @@ func (service *CryftAPI) IssueTx(r *http.Request, args *api.FormattedTx, respons
 
 	txBytes, err := formatting.Decode(args.Encoding, args.Tx)
 	if err != nil {
-		return fmt.Errorf("problem decoding transaction: %w", err)
+		return errcodes.Wrap(errcodes.TxInvalid, fmt.Errorf("problem decoding transaction: %w", err))
 	}
 
 	tx := &Tx{}
 	if _, err := service.vm.codec.Unmarshal(txBytes, tx); err != nil {
-		return fmt.Errorf("problem parsing transaction: %w", err)
+		return errcodes.Wrap(errcodes.TxInvalid, fmt.Errorf("problem parsing transaction: %w", err))
 	}
 	if err := tx.Sign(service.vm.codec, nil); err != nil {
-		return fmt.Errorf("problem initializing transaction: %w", err)
+		return errcodes.Wrap(errcodes.TxInvalid, fmt.Errorf("problem initializing transaction: %w", err))
 	}
 
 	response.TxID = tx.ID()
@@ -446,12 +639,123 @@ func (service *CryftAPI) IssueTx(r *http.Request, args *api.FormattedTx, respons
 	defer service.vm.ctx.Lock.Unlock()
 
 	if err := service.vm.mempool.AddLocalTx(tx); err != nil {
-		return err
+		return atomicTxError(tx.ID(), err)
 	}
 	service.vm.atomicTxPushGossiper.Add(&GossipAtomicTx{tx})
 	return nil
 }
 
+// EstimateAtomicTxFeeArgs are the arguments to EstimateAtomicTxFee
+type EstimateAtomicTxFeeArgs struct {
+	// Size of the unsigned transaction in bytes
+	Size json.Uint64 `json:"size"`
+
+	// Number of signatures of the transaction, one per input
+	Signatures json.Uint64 `json:"signatures"`
+
+	// Numbers of blocks ahead to project the base fee. Defaults to 10 and 60.
+	ProjectionBlocks []json.Uint64 `json:"projectionBlocks"`
+}
+
+// AtomicTxFee is the fee of an atomic transaction at a base fee
+type AtomicTxFee struct {
+	// Number of blocks ahead the base fee is projected, zero for the next block
+	Blocks  json.Uint64  `json:"blocks"`
+	BaseFee *hexutil.Big `json:"baseFee"`
+	// Amount of CRYFT burned by the transaction
+	Fee json.Uint64 `json:"fee"`
+}
+
+// EstimateAtomicTxFeeReply is the response from EstimateAtomicTxFee
+type EstimateAtomicTxFeeReply struct {
+	// Gas consumed by the transaction
+	GasUsed json.Uint64 `json:"gasUsed"`
+	// Fee at the estimated base fee of the next block
+	Current AtomicTxFee `json:"current"`
+	// Fees at the base fees projected for each of the requested blocks
+	Projected []AtomicTxFee `json:"projected"`
+}
+
+// EstimateAtomicTxFee returns the fee an import or export transaction of
+// [Size] bytes and [Signatures] signatures must burn at the estimated base fee
+// of the next block, and at the base fees projected if demand stays above the
+// target for [ProjectionBlocks] blocks. Before ApricotPhase3, the fee is fixed
+// and no base fee is returned.
+func (service *CryftAPI) EstimateAtomicTxFee(r *http.Request, args *EstimateAtomicTxFeeArgs, reply *EstimateAtomicTxFeeReply) error {
+	log.Info("EVM: EstimateAtomicTxFee called")
+
+	projectionBlocks := args.ProjectionBlocks
+	if projectionBlocks == nil {
+		projectionBlocks = defaultAtomicTxFeeProjectionBlocks
+	}
+	for _, blocks := range projectionBlocks {
+		if blocks > maxAtomicTxFeeProjectionBlocks {
+			return fmt.Errorf("projection blocks, %d, exceeds maximum, %d", blocks, maxAtomicTxFeeProjectionBlocks)
+		}
+	}
+
+	service.vm.ctx.Lock.Lock()
+	defer service.vm.ctx.Lock.Unlock()
+
+	rules := service.vm.currentRules()
+	if !rules.IsApricotPhase3 {
+		reply.Current.Fee = json.Uint64(params.AvalancheAtomicTxFee)
+		reply.Projected = make([]AtomicTxFee, len(projectionBlocks))
+		for i, blocks := range projectionBlocks {
+			reply.Projected[i] = AtomicTxFee{Blocks: blocks, Fee: reply.Current.Fee}
+		}
+		return nil
+	}
+
+	gasUsed, err := math.Mul64(uint64(args.Signatures), secp256k1fx.CostPerSignature)
+	if err != nil {
+		return err
+	}
+	gasUsed, err = math.Add64(gasUsed, calcBytesCost(int(args.Size)))
+	if err != nil {
+		return err
+	}
+	if rules.IsApricotPhase5 {
+		gasUsed, err = math.Add64(gasUsed, params.AtomicTxBaseCost)
+		if err != nil {
+			return err
+		}
+	}
+	reply.GasUsed = json.Uint64(gasUsed)
+
+	baseFee, err := service.vm.eth.APIBackend.EstimateBaseFee(r.Context())
+	if err != nil {
+		return err
+	}
+	if baseFee == nil {
+		baseFee = initialBaseFee
+	}
+	atomicTxFee := func(blocks json.Uint64, baseFee *big.Int) (AtomicTxFee, error) {
+		fee, err := CalculateDynamicFee(gasUsed, baseFee)
+		if err != nil {
+			return AtomicTxFee{}, err
+		}
+		return AtomicTxFee{
+			Blocks:  blocks,
+			BaseFee: (*hexutil.Big)(baseFee),
+			Fee:     json.Uint64(fee),
+		}, nil
+	}
+	if reply.Current, err = atomicTxFee(0, baseFee); err != nil {
+		return err
+	}
+
+	timestamp := service.vm.blockChain.CurrentHeader().Time
+	reply.Projected = make([]AtomicTxFee, len(projectionBlocks))
+	for i, blocks := range projectionBlocks {
+		projected := dummy.ProjectBaseFee(service.vm.chainConfig, timestamp, baseFee, uint64(blocks))
+		if reply.Projected[i], err = atomicTxFee(blocks, projected); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // GetAtomicTxStatusReply defines the GetAtomicTxStatus replies returned from the API
 type GetAtomicTxStatusReply struct {
 	Status      Status       `json:"status"`
@@ -488,9 +792,13 @@ func (service *CryftAPI) GetAtomicTxStatus(r *http.Request, args *api.JSONTxID,
 	return nil
 }
 
+// FormattedTx is the reply of GetAtomicTx. [Tx] is a string holding the
+// encoded tx, or the JSON representation of the tx if the JSON encoding was
+// requested.
 type FormattedTx struct {
-	api.FormattedTx
-	BlockHeight *json.Uint64 `json:"blockHeight,omitempty"`
+	Tx          stdjson.RawMessage  `json:"tx"`
+	Encoding    formatting.Encoding `json:"encoding"`
+	BlockHeight *json.Uint64        `json:"blockHeight,omitempty"`
 }
 
 // GetAtomicTx returns the specified transaction
@@ -513,11 +821,18 @@ func (service *CryftAPI) GetAtomicTx(r *http.Request, args *api.GetTxArgs, reply
 		return fmt.Errorf("could not find tx %s", args.TxID)
 	}
 
-	txBytes, err := formatting.Encode(args.Encoding, tx.SignedBytes())
+	if args.Encoding == formatting.JSON {
+		reply.Tx, err = stdjson.Marshal(tx)
+	} else {
+		var txBytes string
+		txBytes, err = formatting.Encode(args.Encoding, tx.SignedBytes())
+		if err == nil {
+			reply.Tx, err = stdjson.Marshal(txBytes)
+		}
+	}
 	if err != nil {
 		return err
 	}
-	reply.Tx = txBytes
 	reply.Encoding = args.Encoding
 	if status == Accepted {
 		// Since chain state updates run asynchronously with VM block acceptance,
@@ -0,0 +1,88 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/shubhamdubey02/cryftgo/ids"
+	"github.com/shubhamdubey02/cryftgo/utils/crypto/secp256k1"
+	"github.com/shubhamdubey02/cryftgo/utils/formatting"
+	"github.com/shubhamdubey02/cryftgo/utils/json"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildImportExport(t *testing.T) {
+	require := require.New(t)
+
+	importAmount := uint64(50000000)
+	issuer, vm, _, _, _ := GenesisVMWithUTXOs(t, true, "", "", "", map[ids.ShortID]uint64{
+		testShortIDAddrs[0]: importAmount,
+	})
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+	service := &CryftAPI{vm: vm}
+	// The service acquires the context lock held by GenesisVMWithUTXOs.
+	vm.ctx.Lock.Unlock()
+	defer vm.ctx.Lock.Lock()
+
+	decode := func(reply *BuildTxReply) []byte {
+		txBytes, err := formatting.Decode(reply.Encoding, reply.UnsignedTx)
+		require.NoError(err)
+		return txBytes
+	}
+
+	from, err := vm.FormatLocalAddress(testShortIDAddrs[0])
+	require.NoError(err)
+	var importReply BuildTxReply
+	require.NoError(service.BuildImport(nil, &BuildImportArgs{
+		BaseFee:     (*hexutil.Big)(initialBaseFee),
+		SourceChain: "X",
+		From:        []string{from},
+		To:          testEthAddrs[0],
+	}, &importReply))
+
+	// The unsigned transaction matches the one built with the private key.
+	importTx, err := vm.newImportTx(vm.ctx.XChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]})
+	require.NoError(err)
+	require.Equal(importTx.Bytes(), decode(&importReply))
+	burned, err := importTx.Burned(vm.ctx.CRYFTAssetID)
+	require.NoError(err)
+	require.EqualValues(burned, importReply.Fee)
+
+	require.NoError(vm.mempool.AddLocalTx(importTx))
+	<-issuer
+	blk, err := vm.BuildBlock(context.Background())
+	require.NoError(err)
+	require.NoError(blk.Verify(context.Background()))
+	require.NoError(vm.SetPreference(context.Background(), blk.ID()))
+	require.NoError(blk.Accept(context.Background()))
+
+	var exportReply BuildTxReply
+	require.NoError(service.BuildExport(nil, &BuildExportArgs{
+		BaseFee:     (*hexutil.Big)(initialBaseFee),
+		Amount:      json.Uint64(5000000),
+		TargetChain: "X",
+		From:        []common.Address{testEthAddrs[0]},
+		To:          testShortIDAddrs[1].String(),
+	}, &exportReply))
+
+	exportTx, err := vm.newExportTx(vm.ctx.CRYFTAssetID, 5000000, vm.ctx.XChainID, testShortIDAddrs[1], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]})
+	require.NoError(err)
+	require.Equal(exportTx.Bytes(), decode(&exportReply))
+
+	// Addresses without funds cannot pay for the export.
+	err = service.BuildExport(nil, &BuildExportArgs{
+		BaseFee:     (*hexutil.Big)(initialBaseFee),
+		Amount:      json.Uint64(5000000),
+		TargetChain: "X",
+		From:        []common.Address{testEthAddrs[1]},
+		To:          testShortIDAddrs[1].String(),
+	}, &exportReply)
+	require.ErrorIs(err, errInsufficientFunds)
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/shubhamdubey02/coreth/plugin/evm/message"
 	syncclient "github.com/shubhamdubey02/coreth/sync/client"
 	"github.com/shubhamdubey02/coreth/sync/statesync"
+	"github.com/shubhamdubey02/coreth/sync/syncsim"
 	"github.com/shubhamdubey02/cryftgo/database"
 	"github.com/shubhamdubey02/cryftgo/database/versiondb"
 	"github.com/shubhamdubey02/cryftgo/ids"
@@ -44,6 +45,8 @@ type stateSyncClientConfig struct {
 	// algorithm.
 	stateSyncMinBlocks   uint64
 	stateSyncRequestSize uint16 // number of key/value pairs to ask peers for per request
+	// maximum number of bytes the state syncer buffers in memory, 0 for no limit
+	stateSyncMemoryBudget uint64
 
 	lastAcceptedHeight uint64
 
@@ -57,6 +60,23 @@ type stateSyncClientConfig struct {
 
 	client syncclient.Client
 
+	// [checkpoints] verifies proposed summaries against trusted checkpoints.
+	// Nil if no checkpoints are configured.
+	checkpoints *syncclient.CheckpointVerifier
+
+	// [trustedNodes] confirms proposed summaries with trusted nodes. Nil if
+	// no trusted nodes are configured.
+	trustedNodes *syncclient.TrustedNodeVerifier
+
+	// [availability] evaluates whether proposed summaries are served by
+	// enough peers. Nil if summaries are not evaluated.
+	availability *syncclient.AvailabilityEvaluator
+
+	// [recordFixtures] records the responses of peers, which are written to
+	// [recordFile] once the state trie is synced. Nil if not recording.
+	recordFixtures *syncsim.Fixtures
+	recordFile     string
+
 	toEngine chan<- commonEng.Message
 }
 
@@ -71,6 +91,10 @@ type stateSyncerClient struct {
 	// State Sync results
 	syncSummary  message.SyncSummary
 	stateSyncErr error
+
+	// evmSyncer is the ongoing sync of the state trie, or nil.
+	evmSyncerLock sync.Mutex
+	evmSyncer     interface{ MemoryUsage() uint64 }
 }
 
 func NewStateSyncClient(config *stateSyncClientConfig) StateSyncClient {
@@ -89,6 +113,9 @@ type StateSyncClient interface {
 	ClearOngoingSummary() error
 	Shutdown() error
 	Error() error
+	// MemoryUsage returns the bytes buffered by the ongoing sync of the
+	// state trie.
+	MemoryUsage() uint64
 }
 
 // Syncer represents a step in state sync,
@@ -140,7 +167,17 @@ func (client *stateSyncerClient) ClearOngoingSummary() error {
 
 // ParseStateSummary parses [summaryBytes] to [commonEng.Summary]
 func (client *stateSyncerClient) ParseStateSummary(_ context.Context, summaryBytes []byte) (block.StateSummary, error) {
-	return message.NewSyncSummaryFromBytes(summaryBytes, client.acceptSyncSummary)
+	summary, err := message.NewSyncSummaryFromBytes(summaryBytes, client.acceptSyncSummary)
+	if err != nil {
+		return nil, err
+	}
+	if client.checkpoints != nil {
+		client.checkpoints.Observe(summary.BlockNumber, summary.BlockHash, summary.BlockRoot)
+	}
+	if client.availability != nil {
+		client.availability.Observe(summary.BlockHash, summary.BlockRoot)
+	}
+	return summary, nil
 }
 
 // stateSync blockingly performs the state sync for the EVM state and the atomic state
@@ -162,6 +199,20 @@ func (client *stateSyncerClient) stateSync(ctx context.Context) error {
 // acceptSyncSummary returns true if sync will be performed and launches the state sync process
 // in a goroutine.
 func (client *stateSyncerClient) acceptSyncSummary(proposedSummary message.SyncSummary) (block.StateSyncMode, error) {
+	// Refuse to sync to a summary that conflicts with the trusted checkpoints,
+	// when most summaries advertised by peers conflicted with them, or when
+	// the trusted nodes do not confirm it, since each case indicates the node
+	// may be eclipsed.
+	if client.checkpoints != nil {
+		if err := client.checkpoints.Verify(proposedSummary.BlockNumber, proposedSummary.BlockHash, proposedSummary.BlockRoot); err != nil {
+			return block.StateSyncSkipped, err
+		}
+	}
+	if client.trustedNodes != nil {
+		if err := client.trustedNodes.Verify(context.Background(), proposedSummary.BlockNumber, proposedSummary.BlockHash, proposedSummary.BlockRoot); err != nil {
+			return block.StateSyncSkipped, err
+		}
+	}
 	isResume := proposedSummary.BlockHash == client.resumableSummary.BlockHash
 	if !isResume {
 		// Skip syncing if the blockchain is not significantly ahead of local state,
@@ -176,6 +227,19 @@ func (client *stateSyncerClient) acceptSyncSummary(proposedSummary message.SyncS
 			return block.StateSyncSkipped, nil
 		}
 
+		// Skip syncing to a summary whose state too few peers serve, since
+		// the sync would likely stall, and fall back to bootstrapping.
+		if client.availability != nil {
+			if err := client.availability.Verify(proposedSummary.BlockHash, proposedSummary.BlockRoot); err != nil {
+				log.Warn(
+					"state summary not available from enough peers, skipping state sync",
+					"summary", proposedSummary,
+					"err", err,
+				)
+				return block.StateSyncSkipped, nil
+			}
+		}
+
 		// Wipe the snapshot completely if we are not resuming from an existing sync, so that we do not
 		// use a corrupted snapshot.
 		// Note: this assumes that when the node is started with state sync disabled, the in-progress state
@@ -299,22 +363,55 @@ func (client *stateSyncerClient) syncStateTrie(ctx context.Context) error {
 		MaxOutstandingCodeHashes: statesync.DefaultMaxOutstandingCodeHashes,
 		NumCodeFetchingWorkers:   statesync.DefaultNumCodeFetchingWorkers,
 		RequestSize:              client.stateSyncRequestSize,
+		MemoryBudget:             client.stateSyncMemoryBudget,
+		MaxCodeSize:              client.chain.BlockChain().Config().LargestMaxCodeSize(),
 	})
 	if err != nil {
 		return err
 	}
+	client.setEVMSyncer(evmSyncer)
+	defer client.setEVMSyncer(nil)
+
 	if err := evmSyncer.Start(ctx); err != nil {
 		return err
 	}
 	err = <-evmSyncer.Done()
 	log.Info("state sync: sync finished", "root", client.syncSummary.BlockRoot, "err", err)
+	if err == nil && client.recordFixtures != nil {
+		client.recordFixtures.Root = client.syncSummary.BlockRoot
+		if err := client.recordFixtures.Save(client.recordFile); err != nil {
+			log.Warn("state sync: failed to write recorded responses", "path", client.recordFile, "err", err)
+		} else {
+			log.Info("state sync: wrote recorded responses", "path", client.recordFile, "count", client.recordFixtures.Len())
+		}
+	}
 	return err
 }
 
+func (client *stateSyncerClient) setEVMSyncer(syncer interface{ MemoryUsage() uint64 }) {
+	client.evmSyncerLock.Lock()
+	defer client.evmSyncerLock.Unlock()
+
+	client.evmSyncer = syncer
+}
+
+func (client *stateSyncerClient) MemoryUsage() uint64 {
+	client.evmSyncerLock.Lock()
+	defer client.evmSyncerLock.Unlock()
+
+	if client.evmSyncer == nil {
+		return 0
+	}
+	return client.evmSyncer.MemoryUsage()
+}
+
 func (client *stateSyncerClient) Shutdown() error {
 	if client.cancel != nil {
 		client.cancel()
 	}
+	if client.availability != nil {
+		client.availability.Close()
+	}
 	client.wg.Wait() // wait for the background goroutine to exit
 	return nil
 }
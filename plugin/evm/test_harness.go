@@ -0,0 +1,271 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/shubhamdubey02/cryftgo/chains/atomic"
+	"github.com/shubhamdubey02/cryftgo/database/memdb"
+	"github.com/shubhamdubey02/cryftgo/database/prefixdb"
+	"github.com/shubhamdubey02/cryftgo/ids"
+	"github.com/shubhamdubey02/cryftgo/snow"
+	"github.com/shubhamdubey02/cryftgo/snow/consensus/snowman"
+	commonEng "github.com/shubhamdubey02/cryftgo/snow/engine/common"
+	"github.com/shubhamdubey02/cryftgo/snow/validators"
+	"github.com/shubhamdubey02/cryftgo/utils/constants"
+	"github.com/shubhamdubey02/cryftgo/utils/crypto/bls"
+	avalancheWarp "github.com/shubhamdubey02/cryftgo/vms/platformvm/warp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shubhamdubey02/coreth/core"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/params"
+	"github.com/shubhamdubey02/coreth/utils"
+)
+
+// harnessTimeout bounds how long the harness waits for the VM to signal that
+// a block can be built.
+const harnessTimeout = 5 * time.Second
+
+var (
+	// HarnessFunderKey is prefunded in the genesis of every TestHarness and
+	// pays for the transfers made by [TestHarness.Fund].
+	HarnessFunderKey, _ = crypto.HexToECDSA("56289e99c94b6912bfc12adc093c9b51124f0dc54ac7a766b2bc5ccf558d8027")
+	// HarnessFunderAddress is the address of [HarnessFunderKey].
+	HarnessFunderAddress = crypto.PubkeyToAddress(HarnessFunderKey.PublicKey)
+	// HarnessFunderBalance is the genesis balance of [HarnessFunderAddress].
+	HarnessFunderBalance = new(big.Int).Mul(big.NewInt(1_000_000), big.NewInt(params.Ether))
+
+	harnessCChainID    = ids.ID{'c', 'h', 'a', 'r', 'n', 'e', 's', 's'}
+	harnessXChainID    = ids.ID{'x', 'h', 'a', 'r', 'n', 'e', 's', 's'}
+	harnessCryftAsset  = ids.ID{'c', 'r', 'y', 'f', 't'}
+	harnessNetworkID   = uint32(10)
+	harnessDefaultTime = time.Unix(0, 0)
+)
+
+// TestHarnessConfig configures a [TestHarness]. The zero value runs a chain
+// with every network upgrade activated at genesis.
+type TestHarnessConfig struct {
+	// Genesis is the genesis of the chain, defaulting to [params.TestChainConfig]
+	// with no allocations. [HarnessFunderAddress] is always prefunded.
+	// An upgrade can be triggered during a test by scheduling it in
+	// Genesis.Config and advancing the clock past its timestamp.
+	Genesis *core.Genesis
+	// Config and Upgrade are the config and upgrade bytes passed to the VM.
+	Config  string
+	Upgrade string
+	// StartTime is the initial time of the VM clock, defaulting to the
+	// genesis timestamp.
+	StartTime time.Time
+}
+
+// TestHarness runs a VM against an in-memory database with a mockable clock,
+// so that tests outside this package can issue transactions and build blocks
+// deterministically. The VM is shut down when the test completes.
+//
+// The harness acquires the snow context lock around each call into the VM, as
+// the consensus engine would.
+type TestHarness struct {
+	t testing.TB
+
+	VM           *VM
+	Ctx          *snow.Context
+	SharedMemory *atomic.Memory
+	ToEngine     chan commonEng.Message
+	Signer       types.Signer
+}
+
+// NewTestHarness initializes and bootstraps a VM configured by [config].
+func NewTestHarness(t testing.TB, config TestHarnessConfig) *TestHarness {
+	require := require.New(t)
+
+	genesis := config.Genesis
+	if genesis == nil {
+		genesis = &core.Genesis{
+			Config:     params.TestChainConfig,
+			Difficulty: big.NewInt(0),
+		}
+	}
+	alloc := make(core.GenesisAlloc, len(genesis.Alloc)+1)
+	for addr, account := range genesis.Alloc {
+		alloc[addr] = account
+	}
+	alloc[HarnessFunderAddress] = core.GenesisAccount{Balance: HarnessFunderBalance}
+	withFunder := *genesis
+	withFunder.Alloc = alloc
+	genesisBytes, err := json.Marshal(&withFunder)
+	require.NoError(err)
+
+	baseDB := memdb.New()
+	sharedMemory := atomic.NewMemory(prefixdb.New([]byte{0}, baseDB))
+	ctx := newHarnessContext()
+	ctx.SharedMemory = sharedMemory.NewSharedMemory(ctx.ChainID)
+
+	vm := &VM{}
+	vm.p2pSender = &commonEng.FakeSender{}
+	startTime := config.StartTime
+	if startTime.IsZero() {
+		startTime = time.Unix(int64(genesis.Timestamp), 0)
+	}
+	vm.clock.Set(startTime)
+
+	appSender := &commonEng.SenderTest{T: t}
+	appSender.CantSendAppGossip = true
+	appSender.SendAppGossipF = func(context.Context, commonEng.SendConfig, []byte) error { return nil }
+	toEngine := make(chan commonEng.Message, 1)
+
+	ctx.Lock.Lock()
+	defer ctx.Lock.Unlock()
+	require.NoError(vm.Initialize(
+		context.Background(),
+		ctx,
+		prefixdb.New([]byte{1}, baseDB),
+		genesisBytes,
+		[]byte(config.Upgrade),
+		[]byte(config.Config),
+		toEngine,
+		[]*commonEng.Fx{},
+		appSender,
+	))
+	require.NoError(vm.SetState(context.Background(), snow.Bootstrapping))
+	require.NoError(vm.SetState(context.Background(), snow.NormalOp))
+	t.Cleanup(func() {
+		ctx.Lock.Lock()
+		defer ctx.Lock.Unlock()
+		require.NoError(vm.Shutdown(context.Background()))
+	})
+
+	return &TestHarness{
+		t:            t,
+		VM:           vm,
+		Ctx:          ctx,
+		SharedMemory: sharedMemory,
+		ToEngine:     toEngine,
+		Signer:       types.LatestSigner(vm.chainConfig),
+	}
+}
+
+// newHarnessContext returns a snow context for the C-Chain of a primary
+// network containing only the C-Chain and X-Chain.
+func newHarnessContext() *snow.Context {
+	ctx := utils.TestSnowContext()
+	ctx.NodeID = ids.GenerateTestNodeID()
+	ctx.NetworkID = harnessNetworkID
+	ctx.ChainID = harnessCChainID
+	ctx.XChainID = harnessXChainID
+	ctx.CRYFTAssetID = harnessCryftAsset
+	aliaser := ctx.BCLookup.(ids.Aliaser)
+	_ = aliaser.Alias(harnessCChainID, "C")
+	_ = aliaser.Alias(harnessCChainID, harnessCChainID.String())
+	_ = aliaser.Alias(harnessXChainID, "X")
+	_ = aliaser.Alias(harnessXChainID, harnessXChainID.String())
+	ctx.ValidatorState = &validators.TestState{
+		GetSubnetIDF: func(context.Context, ids.ID) (ids.ID, error) {
+			return constants.PrimaryNetworkID, nil
+		},
+	}
+	blsSecretKey, err := bls.NewSecretKey()
+	if err != nil {
+		panic(err)
+	}
+	ctx.WarpSigner = avalancheWarp.NewSigner(blsSecretKey, ctx.NetworkID, ctx.ChainID)
+	ctx.PublicKey = bls.PublicFromSecretKey(blsSecretKey)
+	return ctx
+}
+
+// Time returns the current time of the VM clock.
+func (h *TestHarness) Time() time.Time {
+	return h.VM.clock.Time()
+}
+
+// SetTime sets the VM clock to [t]. Blocks built afterwards are timestamped
+// with [t], activating any upgrade scheduled at or before it.
+func (h *TestHarness) SetTime(t time.Time) {
+	h.VM.clock.Set(t)
+}
+
+// AdvanceTime moves the VM clock forward by [d].
+func (h *TestHarness) AdvanceTime(d time.Duration) {
+	h.SetTime(h.Time().Add(d))
+}
+
+// SignTx signs [tx] with [key] using the signer of the chain.
+func (h *TestHarness) SignTx(tx *types.Transaction, key *ecdsa.PrivateKey) *types.Transaction {
+	signed, err := types.SignTx(tx, h.Signer, key)
+	require.NoError(h.t, err)
+	return signed
+}
+
+// Fund issues a transfer of [amount] from [HarnessFunderAddress] to [to].
+// The transfer is included in the next block built.
+func (h *TestHarness) Fund(to common.Address, amount *big.Int) *types.Transaction {
+	tx := types.NewTransaction(
+		h.VM.txPool.Nonce(HarnessFunderAddress),
+		to,
+		amount,
+		params.TxGas,
+		big.NewInt(params.LaunchMinGasPrice),
+		nil,
+	)
+	tx = h.SignTx(tx, HarnessFunderKey)
+	h.IssueTx(tx)
+	return tx
+}
+
+// IssueTx adds the signed transactions [txs] to the mempool.
+func (h *TestHarness) IssueTx(txs ...*types.Transaction) {
+	for i, err := range h.VM.txPool.AddRemotesSync(txs) {
+		require.NoError(h.t, err, "failed to issue tx %s", txs[i].Hash())
+	}
+}
+
+// BuildBlock waits for the VM to request a block, then builds, verifies and
+// prefers it.
+func (h *TestHarness) BuildBlock() snowman.Block {
+	require := require.New(h.t)
+	select {
+	case msg := <-h.ToEngine:
+		require.Equal(commonEng.PendingTxs, msg)
+	case <-time.After(harnessTimeout):
+		require.FailNow("timed out waiting for the VM to request a block")
+	}
+
+	h.Ctx.Lock.Lock()
+	defer h.Ctx.Lock.Unlock()
+	blk, err := h.VM.BuildBlock(context.Background())
+	require.NoError(err)
+	require.NoError(blk.Verify(context.Background()))
+	require.NoError(h.VM.SetPreference(context.Background(), blk.ID()))
+	return blk
+}
+
+// Accept accepts [blk] and waits for the acceptor to process it.
+func (h *TestHarness) Accept(blk snowman.Block) {
+	h.Ctx.Lock.Lock()
+	defer h.Ctx.Lock.Unlock()
+	require.NoError(h.t, blk.Accept(context.Background()))
+	h.VM.blockChain.DrainAcceptorQueue()
+}
+
+// BuildAndAccept builds a block from the mempool and accepts it.
+func (h *TestHarness) BuildAndAccept() snowman.Block {
+	blk := h.BuildBlock()
+	h.Accept(blk)
+	return blk
+}
+
+// Balance returns the balance of [addr] as of the last accepted block.
+func (h *TestHarness) Balance(addr common.Address) *big.Int {
+	state, err := h.VM.blockChain.State()
+	require.NoError(h.t, err)
+	return state.GetBalance(addr)
+}
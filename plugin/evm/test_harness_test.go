@@ -0,0 +1,43 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shubhamdubey02/coreth/core"
+	"github.com/shubhamdubey02/coreth/params"
+	"github.com/shubhamdubey02/coreth/utils"
+)
+
+func TestHarnessFundAndUpgrade(t *testing.T) {
+	require := require.New(t)
+
+	const durangoTime = 100
+	config := *params.TestChainConfig
+	config.DurangoBlockTimestamp = utils.NewUint64(durangoTime)
+	h := NewTestHarness(t, TestHarnessConfig{
+		Genesis: &core.Genesis{Config: &config, Difficulty: big.NewInt(0)},
+	})
+
+	recipient := common.Address{0xaa}
+	amount := big.NewInt(params.Ether)
+	h.Fund(recipient, amount)
+	blk := h.BuildAndAccept()
+	require.Equal(uint64(1), blk.Height())
+	require.Equal(amount, h.Balance(recipient))
+	require.False(h.VM.currentRules().IsDurango)
+
+	h.AdvanceTime(durangoTime * time.Second)
+	h.Fund(recipient, amount)
+	blk = h.BuildAndAccept()
+	require.Equal(time.Unix(durangoTime, 0), blk.Timestamp())
+	require.Equal(new(big.Int).Mul(amount, big.NewInt(2)), h.Balance(recipient))
+	require.True(h.VM.currentRules().IsDurango)
+}
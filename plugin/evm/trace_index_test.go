@@ -0,0 +1,108 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shubhamdubey02/coreth/core"
+	"github.com/shubhamdubey02/coreth/core/rawdb"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/eth/tracers"
+	"github.com/shubhamdubey02/coreth/params"
+	"github.com/shubhamdubey02/coreth/rpc"
+)
+
+func TestTraceFilter(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		caller = common.Address{0xcc}
+		callee = common.Address{0xbb}
+	)
+	// The caller calls the callee: CALL(GAS, callee, 0, 0, 0, 0, 0) STOP.
+	callerCode := append(append(common.FromHex("0x6000600060006000600073"), callee.Bytes()...), common.FromHex("0x5af100")...)
+	h := NewTestHarness(t, TestHarnessConfig{
+		Config: `{"trace-index-enabled": true}`,
+		Genesis: &core.Genesis{
+			Config:     params.TestChainConfig,
+			Difficulty: big.NewInt(0),
+			Alloc:      core.GenesisAlloc{caller: {Balance: new(big.Int), Code: callerCode}},
+		},
+	})
+
+	h.IssueTx(h.SignTx(types.NewTransaction(0, caller, new(big.Int), 100_000, big.NewInt(params.LaunchMinGasPrice), nil), HarnessFunderKey))
+	h.BuildAndAccept()
+	h.AdvanceTime(10 * time.Second)
+	h.IssueTx(h.SignTx(types.NewTransaction(1, common.Address{0xaa}, big.NewInt(1), params.TxGas, big.NewInt(params.LaunchMinGasPrice), nil), HarnessFunderKey))
+	h.BuildAndAccept()
+
+	require.Eventually(func() bool {
+		offset, err := rawdb.ReadAcceptedConsumerOffset(h.VM.chaindb, tracers.TraceIndexConsumer)
+		return err == nil && offset != nil && *offset == 2
+	}, 10*time.Second, 10*time.Millisecond)
+
+	var api *tracers.TraceAPI
+	for _, service := range h.VM.eth.APIs() {
+		if service.Name == "trace" {
+			api = service.Service.(*tracers.TraceAPI)
+		}
+	}
+	require.NotNil(api)
+
+	type trace struct {
+		Action struct {
+			From common.Address `json:"from"`
+			To   common.Address `json:"to"`
+		} `json:"action"`
+		BlockNumber  uint64 `json:"blockNumber"`
+		TraceAddress []int  `json:"traceAddress"`
+	}
+	filter := func(args tracers.TraceFilterArgs) []trace {
+		results, err := api.Filter(context.Background(), args)
+		require.NoError(err)
+		traces := make([]trace, len(results))
+		for i, result := range results {
+			require.NoError(json.Unmarshal(result, &traces[i]))
+		}
+		return traces
+	}
+	blockNumber := func(number int64) *rpc.BlockNumber {
+		n := rpc.BlockNumber(number)
+		return &n
+	}
+	uint64Ptr := func(v uint64) *uint64 { return &v }
+
+	all := filter(tracers.TraceFilterArgs{FromBlock: blockNumber(1)})
+	require.Len(all, 3)
+	require.Equal(caller, all[0].Action.To)
+	require.Equal([]int{0}, all[1].TraceAddress)
+	require.Equal(callee, all[1].Action.To)
+	require.Equal(uint64(2), all[2].BlockNumber)
+
+	internal := filter(tracers.TraceFilterArgs{FromBlock: blockNumber(1), ToAddress: []common.Address{callee}})
+	require.Len(internal, 1)
+	require.Equal(caller, internal[0].Action.From)
+
+	page := filter(tracers.TraceFilterArgs{
+		FromBlock:   blockNumber(1),
+		FromAddress: []common.Address{HarnessFunderAddress},
+		After:       uint64Ptr(1),
+		Count:       uint64Ptr(1),
+	})
+	require.Len(page, 1)
+	require.Equal(common.Address{0xaa}, page[0].Action.To)
+
+	_, err := api.Filter(context.Background(), tracers.TraceFilterArgs{FromBlock: blockNumber(0)})
+	require.ErrorContains(err, "not indexed")
+	_, err = api.Filter(context.Background(), tracers.TraceFilterArgs{FromBlock: blockNumber(2), ToBlock: blockNumber(1)})
+	require.ErrorContains(err, "fromBlock is after toBlock")
+}
@@ -0,0 +1,281 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	stdjson "encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/shubhamdubey02/cryftgo/ids"
+	"github.com/shubhamdubey02/cryftgo/utils/crypto/secp256k1"
+	"github.com/shubhamdubey02/cryftgo/utils/json"
+	"github.com/shubhamdubey02/cryftgo/vms/components/cryft"
+	"github.com/shubhamdubey02/cryftgo/vms/components/verify"
+	"github.com/shubhamdubey02/cryftgo/vms/secp256k1fx"
+)
+
+// atomicTxJSONVersion is the version of the JSON representation of atomic
+// txs. It is incremented whenever a field is removed or changes meaning, so
+// that consumers of stored or exported txs can detect the change.
+const atomicTxJSONVersion = 1
+
+const (
+	atomicTxTypeImport = "import"
+	atomicTxTypeExport = "export"
+)
+
+var (
+	errUnknownAtomicTxType       = errors.New("unknown atomic tx type")
+	errUnsupportedAtomicTxJSON   = errors.New("unsupported atomic tx JSON version")
+	errAtomicTxJSONIDMismatch    = errors.New("atomic tx ID does not match its contents")
+	errUnsupportedAtomicTxFormat = errors.New("atomic tx component cannot be represented in JSON")
+)
+
+// atomicTxJSON is the JSON representation of an atomic tx, shared by the
+// APIs and the chain export. Amounts are encoded as decimal strings. The
+// fields of the other type of tx are omitted.
+type atomicTxJSON struct {
+	Version      int         `json:"version"`
+	ID           ids.ID      `json:"id"`
+	Type         string      `json:"type"`
+	NetworkID    json.Uint32 `json:"networkID"`
+	BlockchainID ids.ID      `json:"blockchainID"`
+
+	// Import txs
+	SourceChain    *ids.ID             `json:"sourceChain,omitempty"`
+	ImportedInputs []importedInputJSON `json:"importedInputs,omitempty"`
+	Outputs        []evmOutputJSON     `json:"outputs,omitempty"`
+
+	// Export txs
+	DestinationChain *ids.ID              `json:"destinationChain,omitempty"`
+	Inputs           []evmInputJSON       `json:"inputs,omitempty"`
+	ExportedOutputs  []exportedOutputJSON `json:"exportedOutputs,omitempty"`
+
+	Credentials []credentialJSON `json:"credentials"`
+}
+
+type importedInputJSON struct {
+	TxID             ids.ID        `json:"txID"`
+	OutputIndex      json.Uint32   `json:"outputIndex"`
+	AssetID          ids.ID        `json:"assetID"`
+	Amount           json.Uint64   `json:"amount"`
+	SignatureIndices []json.Uint32 `json:"signatureIndices"`
+}
+
+type evmOutputJSON struct {
+	Address common.Address `json:"address"`
+	Amount  json.Uint64    `json:"amount"`
+	AssetID ids.ID         `json:"assetID"`
+}
+
+type evmInputJSON struct {
+	Address common.Address `json:"address"`
+	Amount  json.Uint64    `json:"amount"`
+	AssetID ids.ID         `json:"assetID"`
+	Nonce   json.Uint64    `json:"nonce"`
+}
+
+type exportedOutputJSON struct {
+	AssetID   ids.ID        `json:"assetID"`
+	Amount    json.Uint64   `json:"amount"`
+	Locktime  json.Uint64   `json:"locktime"`
+	Threshold json.Uint32   `json:"threshold"`
+	Addresses []ids.ShortID `json:"addresses"`
+}
+
+type credentialJSON struct {
+	Signatures []hexutil.Bytes `json:"signatures"`
+}
+
+// MarshalJSON returns the versioned JSON representation of the tx.
+func (tx *Tx) MarshalJSON() ([]byte, error) {
+	res := atomicTxJSON{
+		Version:     atomicTxJSONVersion,
+		ID:          tx.ID(),
+		Credentials: make([]credentialJSON, len(tx.Creds)),
+	}
+	switch utx := tx.UnsignedAtomicTx.(type) {
+	case *UnsignedImportTx:
+		res.Type = atomicTxTypeImport
+		res.NetworkID = json.Uint32(utx.NetworkID)
+		res.BlockchainID = utx.BlockchainID
+		sourceChain := utx.SourceChain
+		res.SourceChain = &sourceChain
+		res.ImportedInputs = make([]importedInputJSON, len(utx.ImportedInputs))
+		for i, in := range utx.ImportedInputs {
+			transferIn, ok := in.In.(*secp256k1fx.TransferInput)
+			if !ok {
+				return nil, fmt.Errorf("%w: input %T", errUnsupportedAtomicTxFormat, in.In)
+			}
+			sigIndices := make([]json.Uint32, len(transferIn.SigIndices))
+			for j, index := range transferIn.SigIndices {
+				sigIndices[j] = json.Uint32(index)
+			}
+			res.ImportedInputs[i] = importedInputJSON{
+				TxID:             in.TxID,
+				OutputIndex:      json.Uint32(in.OutputIndex),
+				AssetID:          in.AssetID(),
+				Amount:           json.Uint64(transferIn.Amt),
+				SignatureIndices: sigIndices,
+			}
+		}
+		res.Outputs = make([]evmOutputJSON, len(utx.Outs))
+		for i, out := range utx.Outs {
+			res.Outputs[i] = evmOutputJSON{
+				Address: out.Address,
+				Amount:  json.Uint64(out.Amount),
+				AssetID: out.AssetID,
+			}
+		}
+	case *UnsignedExportTx:
+		res.Type = atomicTxTypeExport
+		res.NetworkID = json.Uint32(utx.NetworkID)
+		res.BlockchainID = utx.BlockchainID
+		destinationChain := utx.DestinationChain
+		res.DestinationChain = &destinationChain
+		res.Inputs = make([]evmInputJSON, len(utx.Ins))
+		for i, in := range utx.Ins {
+			res.Inputs[i] = evmInputJSON{
+				Address: in.Address,
+				Amount:  json.Uint64(in.Amount),
+				AssetID: in.AssetID,
+				Nonce:   json.Uint64(in.Nonce),
+			}
+		}
+		res.ExportedOutputs = make([]exportedOutputJSON, len(utx.ExportedOutputs))
+		for i, out := range utx.ExportedOutputs {
+			transferOut, ok := out.Out.(*secp256k1fx.TransferOutput)
+			if !ok {
+				return nil, fmt.Errorf("%w: output %T", errUnsupportedAtomicTxFormat, out.Out)
+			}
+			res.ExportedOutputs[i] = exportedOutputJSON{
+				AssetID:   out.AssetID(),
+				Amount:    json.Uint64(transferOut.Amt),
+				Locktime:  json.Uint64(transferOut.Locktime),
+				Threshold: json.Uint32(transferOut.Threshold),
+				Addresses: transferOut.Addrs,
+			}
+		}
+	default:
+		return nil, fmt.Errorf("%w: %T", errUnknownAtomicTxType, tx.UnsignedAtomicTx)
+	}
+	for i, cred := range tx.Creds {
+		secpCred, ok := cred.(*secp256k1fx.Credential)
+		if !ok {
+			return nil, fmt.Errorf("%w: credential %T", errUnsupportedAtomicTxFormat, cred)
+		}
+		sigs := make([]hexutil.Bytes, len(secpCred.Sigs))
+		for j, sig := range secpCred.Sigs {
+			sigs[j] = common.CopyBytes(sig[:])
+		}
+		res.Credentials[i] = credentialJSON{Signatures: sigs}
+	}
+	return stdjson.Marshal(res)
+}
+
+// UnmarshalJSON decodes the JSON representation of a tx, and initializes its
+// bytes with [Codec]. Returns an error if the ID of the decoded tx does not
+// match the ID in the JSON.
+func (tx *Tx) UnmarshalJSON(b []byte) error {
+	var dec atomicTxJSON
+	if err := stdjson.Unmarshal(b, &dec); err != nil {
+		return err
+	}
+	if dec.Version != atomicTxJSONVersion {
+		return fmt.Errorf("%w: %d", errUnsupportedAtomicTxJSON, dec.Version)
+	}
+
+	var decoded Tx
+	switch dec.Type {
+	case atomicTxTypeImport:
+		utx := &UnsignedImportTx{
+			NetworkID:      uint32(dec.NetworkID),
+			BlockchainID:   dec.BlockchainID,
+			ImportedInputs: make([]*cryft.TransferableInput, len(dec.ImportedInputs)),
+			Outs:           make([]EVMOutput, len(dec.Outputs)),
+		}
+		if dec.SourceChain != nil {
+			utx.SourceChain = *dec.SourceChain
+		}
+		for i, in := range dec.ImportedInputs {
+			sigIndices := make([]uint32, len(in.SignatureIndices))
+			for j, index := range in.SignatureIndices {
+				sigIndices[j] = uint32(index)
+			}
+			utx.ImportedInputs[i] = &cryft.TransferableInput{
+				UTXOID: cryft.UTXOID{TxID: in.TxID, OutputIndex: uint32(in.OutputIndex)},
+				Asset:  cryft.Asset{ID: in.AssetID},
+				In: &secp256k1fx.TransferInput{
+					Amt:   uint64(in.Amount),
+					Input: secp256k1fx.Input{SigIndices: sigIndices},
+				},
+			}
+		}
+		for i, out := range dec.Outputs {
+			utx.Outs[i] = EVMOutput{Address: out.Address, Amount: uint64(out.Amount), AssetID: out.AssetID}
+		}
+		decoded.UnsignedAtomicTx = utx
+	case atomicTxTypeExport:
+		utx := &UnsignedExportTx{
+			NetworkID:       uint32(dec.NetworkID),
+			BlockchainID:    dec.BlockchainID,
+			Ins:             make([]EVMInput, len(dec.Inputs)),
+			ExportedOutputs: make([]*cryft.TransferableOutput, len(dec.ExportedOutputs)),
+		}
+		if dec.DestinationChain != nil {
+			utx.DestinationChain = *dec.DestinationChain
+		}
+		for i, in := range dec.Inputs {
+			utx.Ins[i] = EVMInput{Address: in.Address, Amount: uint64(in.Amount), AssetID: in.AssetID, Nonce: uint64(in.Nonce)}
+		}
+		for i, out := range dec.ExportedOutputs {
+			utx.ExportedOutputs[i] = &cryft.TransferableOutput{
+				Asset: cryft.Asset{ID: out.AssetID},
+				Out: &secp256k1fx.TransferOutput{
+					Amt: uint64(out.Amount),
+					OutputOwners: secp256k1fx.OutputOwners{
+						Locktime:  uint64(out.Locktime),
+						Threshold: uint32(out.Threshold),
+						Addrs:     out.Addresses,
+					},
+				},
+			}
+		}
+		decoded.UnsignedAtomicTx = utx
+	default:
+		return fmt.Errorf("%w: %q", errUnknownAtomicTxType, dec.Type)
+	}
+
+	decoded.Creds = make([]verify.Verifiable, len(dec.Credentials))
+	for i, cred := range dec.Credentials {
+		secpCred := &secp256k1fx.Credential{
+			Sigs: make([][secp256k1.SignatureLen]byte, len(cred.Signatures)),
+		}
+		for j, sig := range cred.Signatures {
+			if len(sig) != secp256k1.SignatureLen {
+				return fmt.Errorf("invalid length %d of signature %d of credential %d", len(sig), j, i)
+			}
+			copy(secpCred.Sigs[j][:], sig)
+		}
+		decoded.Creds[i] = secpCred
+	}
+
+	unsignedBytes, err := Codec.Marshal(codecVersion, &decoded.UnsignedAtomicTx)
+	if err != nil {
+		return fmt.Errorf("couldn't marshal UnsignedAtomicTx: %w", err)
+	}
+	signedBytes, err := Codec.Marshal(codecVersion, &decoded)
+	if err != nil {
+		return fmt.Errorf("couldn't marshal Tx: %w", err)
+	}
+	decoded.Initialize(unsignedBytes, signedBytes)
+	if decoded.ID() != dec.ID {
+		return fmt.Errorf("%w: %s, expected %s", errAtomicTxJSONIDMismatch, decoded.ID(), dec.ID)
+	}
+	*tx = decoded
+	return nil
+}
@@ -0,0 +1,77 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/shubhamdubey02/cryftgo/ids"
+	"github.com/shubhamdubey02/cryftgo/utils/crypto/secp256k1"
+	"github.com/shubhamdubey02/cryftgo/vms/components/cryft"
+	"github.com/shubhamdubey02/cryftgo/vms/secp256k1fx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAtomicTxJSON(t *testing.T) {
+	importTx := &Tx{UnsignedAtomicTx: &UnsignedImportTx{
+		NetworkID:    1,
+		BlockchainID: ids.ID{1},
+		SourceChain:  ids.ID{2},
+		ImportedInputs: []*cryft.TransferableInput{{
+			UTXOID: cryft.UTXOID{TxID: ids.ID{3}, OutputIndex: 4},
+			Asset:  cryft.Asset{ID: testCryftAssetID},
+			In: &secp256k1fx.TransferInput{
+				Amt:   5_000_000,
+				Input: secp256k1fx.Input{SigIndices: []uint32{0}},
+			},
+		}},
+		Outs: []EVMOutput{{Address: testEthAddrs[0], Amount: 4_000_000, AssetID: testCryftAssetID}},
+	}}
+	exportTx := &Tx{UnsignedAtomicTx: &UnsignedExportTx{
+		NetworkID:        1,
+		BlockchainID:     ids.ID{1},
+		DestinationChain: ids.ID{2},
+		Ins:              []EVMInput{{Address: testEthAddrs[0], Amount: 5_000_000, AssetID: testCryftAssetID, Nonce: 6}},
+		ExportedOutputs: []*cryft.TransferableOutput{{
+			Asset: cryft.Asset{ID: testCryftAssetID},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: 4_000_000,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Locktime:  7,
+					Threshold: 1,
+					Addrs:     []ids.ShortID{testShortIDAddrs[0]},
+				},
+			},
+		}},
+	}}
+
+	for name, tx := range map[string]*Tx{"import": importTx, "export": exportTx} {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			require.NoError(tx.Sign(Codec, [][]*secp256k1.PrivateKey{{testKeys[0]}}))
+			txJSON, err := json.Marshal(tx)
+			require.NoError(err)
+
+			var fields map[string]interface{}
+			require.NoError(json.Unmarshal(txJSON, &fields))
+			require.Equal(float64(atomicTxJSONVersion), fields["version"])
+			require.Equal(name, fields["type"])
+			require.Equal(tx.ID().String(), fields["id"])
+
+			decoded := new(Tx)
+			require.NoError(json.Unmarshal(txJSON, decoded))
+			require.Equal(tx.ID(), decoded.ID())
+			require.Equal(tx.SignedBytes(), decoded.SignedBytes())
+			require.Equal(tx.Bytes(), decoded.Bytes())
+
+			// A tx whose contents do not match its ID is rejected.
+			fields["networkID"] = "2"
+			tampered, err := json.Marshal(fields)
+			require.NoError(err)
+			require.ErrorIs(json.Unmarshal(tampered, new(Tx)), errAtomicTxJSONIDMismatch)
+		})
+	}
+}
@@ -0,0 +1,210 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/shubhamdubey02/cryftgo/cache"
+
+	"github.com/shubhamdubey02/coreth/core"
+	"github.com/shubhamdubey02/coreth/core/types"
+)
+
+// txLifecycleConsumer is the name of the accepted block consumer recording
+// the accepted events of transactions.
+const txLifecycleConsumer = "tx-lifecycle"
+
+// Events of the lifecycle of a transaction.
+const (
+	// TxLifecycleRPC transactions were submitted over the RPC and added to
+	// the mempool.
+	TxLifecycleRPC = "rpc"
+	// TxLifecycleGossip transactions were received from gossip. Error is set
+	// if the mempool rejected them.
+	TxLifecycleGossip = "gossip"
+	// TxLifecyclePending transactions became executable in the mempool.
+	TxLifecyclePending = "pending"
+	// TxLifecycleGossiped transactions were pushed to peers for the first
+	// time.
+	TxLifecycleGossiped = "gossiped"
+	// TxLifecycleRegossiped transactions were pushed to peers again.
+	TxLifecycleRegossiped = "regossiped"
+	// TxLifecycleIncluded transactions are in a verified block, which may
+	// not be accepted.
+	TxLifecycleIncluded = "included"
+	// TxLifecycleAccepted transactions are in an accepted block.
+	TxLifecycleAccepted = "accepted"
+)
+
+// txLifecycleMaxEvents is the maximum number of events kept per transaction,
+// so that regossiping a transaction for long does not grow its record
+// without bound.
+const txLifecycleMaxEvents = 32
+
+var errTxLifecycleDisabled = errors.New("transaction lifecycle tracing is not enabled")
+
+// TxLifecycleEvent is an event of the lifecycle of a transaction.
+type TxLifecycleEvent struct {
+	Event string    `json:"event"`
+	Time  time.Time `json:"time"`
+	// BlockHash and BlockNumber are set for inclusion and acceptance.
+	BlockHash   *common.Hash    `json:"blockHash,omitempty"`
+	BlockNumber *hexutil.Uint64 `json:"blockNumber,omitempty"`
+	Error       string          `json:"error,omitempty"`
+}
+
+// txLifecycle records the events of the most recently seen transactions, from
+// the RPC or gossip through the mempool and gossip to their inclusion and
+// acceptance. Each event is also logged with the transaction hash, so that
+// the logs of a transaction can be correlated.
+type txLifecycle struct {
+	now func() time.Time
+
+	lock sync.Mutex
+	txs  *cache.LRU[common.Hash, []TxLifecycleEvent]
+}
+
+func newTxLifecycle(capacity int) *txLifecycle {
+	return &txLifecycle{
+		now: time.Now,
+		txs: &cache.LRU[common.Hash, []TxLifecycleEvent]{Size: capacity},
+	}
+}
+
+// record appends [event] to the events of [hash]. It is a no-op on a nil
+// tracker.
+func (l *txLifecycle) record(hash common.Hash, event TxLifecycleEvent) {
+	if l == nil {
+		return
+	}
+	event.Time = l.now()
+	log.Debug("Transaction lifecycle event", "hash", hash, "event", event.Event, "blockHash", event.BlockHash, "err", event.Error)
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	events, _ := l.txs.Get(hash)
+	if len(events) >= txLifecycleMaxEvents {
+		return
+	}
+	l.txs.Put(hash, append(events, event))
+}
+
+// recordGossiped records that [hash] was pushed to peers, which is a
+// regossip if it was pushed before.
+func (l *txLifecycle) recordGossiped(hash common.Hash) {
+	if l == nil {
+		return
+	}
+	l.lock.Lock()
+	events, _ := l.txs.Get(hash)
+	l.lock.Unlock()
+
+	event := TxLifecycleGossiped
+	for _, e := range events {
+		if e.Event == TxLifecycleGossiped {
+			event = TxLifecycleRegossiped
+			break
+		}
+	}
+	l.record(hash, TxLifecycleEvent{Event: event})
+}
+
+// recordBlock records [event] for the transactions of [block].
+func (l *txLifecycle) recordBlock(event string, block *types.Block) {
+	var (
+		hash   = block.Hash()
+		number = hexutil.Uint64(block.NumberU64())
+	)
+	for _, tx := range block.Transactions() {
+		l.record(tx.Hash(), TxLifecycleEvent{Event: event, BlockHash: &hash, BlockNumber: &number})
+	}
+}
+
+// events returns the events recorded for [hash], oldest first.
+func (l *txLifecycle) events(hash common.Hash) []TxLifecycleEvent {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	events, _ := l.txs.Get(hash)
+	return append([]TxLifecycleEvent{}, events...)
+}
+
+// startTxLifecycle records the mempool and block events of transactions in
+// the background until the VM shuts down.
+func (vm *VM) startTxLifecycle() error {
+	if !vm.config.TxLifecycle.Enabled {
+		return nil
+	}
+	vm.txLifecycle = newTxLifecycle(vm.config.TxLifecycle.Capacity)
+
+	err := vm.consumeAcceptedBlocks(txLifecycleConsumer, func(block *types.Block) error {
+		vm.txLifecycle.recordBlock(TxLifecycleAccepted, block)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var (
+		pendingCh  = make(chan core.NewTxsEvent, 64)
+		includedCh = make(chan core.ChainEvent, 64)
+		pendingSub = vm.txPool.SubscribeTransactions(pendingCh, false)
+		chainSub   = vm.blockChain.SubscribeChainEvent(includedCh)
+	)
+	vm.shutdownWg.Add(1)
+	go vm.ctx.Log.RecoverAndPanic(func() {
+		defer vm.shutdownWg.Done()
+		defer pendingSub.Unsubscribe()
+		defer chainSub.Unsubscribe()
+
+		for {
+			select {
+			case ev := <-pendingCh:
+				for _, tx := range ev.Txs {
+					vm.txLifecycle.record(tx.Hash(), TxLifecycleEvent{Event: TxLifecyclePending})
+				}
+			case ev := <-includedCh:
+				vm.txLifecycle.recordBlock(TxLifecycleIncluded, ev.Block)
+			case <-vm.shutdownChan:
+				return
+			}
+		}
+	})
+	return nil
+}
+
+// txLifecycleMarshaller records the transactions it marshals for the push
+// gossiper as gossiped.
+type txLifecycleMarshaller struct {
+	GossipEthTxMarshaller
+	lifecycle *txLifecycle
+}
+
+func (m txLifecycleMarshaller) MarshalGossip(tx *GossipEthTx) ([]byte, error) {
+	m.lifecycle.recordGossiped(tx.Tx.Hash())
+	return m.GossipEthTxMarshaller.MarshalGossip(tx)
+}
+
+// TxLifecycleAPI serves the events of the lifecycle of transactions, to debug
+// why a transaction was not included.
+type TxLifecycleAPI struct {
+	vm *VM
+}
+
+// TxLifecycle returns the events recorded for the transaction [hash], oldest
+// first. Events are only kept for the most recently seen transactions.
+func (api *TxLifecycleAPI) TxLifecycle(ctx context.Context, hash common.Hash) ([]TxLifecycleEvent, error) {
+	if api.vm.txLifecycle == nil {
+		return nil, errTxLifecycleDisabled
+	}
+	return api.vm.txLifecycle.events(hash), nil
+}
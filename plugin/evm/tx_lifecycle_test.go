@@ -0,0 +1,83 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/params"
+)
+
+func TestTxLifecycle(t *testing.T) {
+	require := require.New(t)
+
+	h := NewTestHarness(t, TestHarnessConfig{
+		Config: `{"tx-lifecycle": {"enabled": true, "capacity": 16}}`,
+	})
+	var (
+		api = &TxLifecycleAPI{h.VM}
+		ctx = context.Background()
+	)
+
+	gossipPool, err := NewGossipEthTxPool(h.VM.txPool, prometheus.NewRegistry())
+	require.NoError(err)
+	gossipPool.lifecycle = h.VM.txLifecycle
+
+	tx := h.SignTx(types.NewTransaction(0, common.Address{0xaa}, big.NewInt(1), params.TxGas, big.NewInt(params.LaunchMinGasPrice), nil), HarnessFunderKey)
+	require.NoError(gossipPool.Add(&GossipEthTx{Tx: tx}))
+	// Receiving the transaction again is not recorded.
+	require.Error(gossipPool.Add(&GossipEthTx{Tx: tx}))
+
+	marshaller := txLifecycleMarshaller{lifecycle: h.VM.txLifecycle}
+	for i := 0; i < 2; i++ {
+		_, err := marshaller.MarshalGossip(&GossipEthTx{Tx: tx})
+		require.NoError(err)
+	}
+	blk := h.BuildAndAccept()
+
+	names := func() []string {
+		events, err := api.TxLifecycle(ctx, tx.Hash())
+		require.NoError(err)
+		var names []string
+		for _, event := range events {
+			names = append(names, event.Event)
+		}
+		return names
+	}
+	require.Eventually(func() bool {
+		return len(names()) == 6
+	}, 5*time.Second, 10*time.Millisecond)
+	require.ElementsMatch(
+		[]string{TxLifecycleGossip, TxLifecyclePending, TxLifecycleGossiped, TxLifecycleRegossiped, TxLifecycleIncluded, TxLifecycleAccepted},
+		names(),
+	)
+
+	events, err := api.TxLifecycle(ctx, tx.Hash())
+	require.NoError(err)
+	for _, event := range events {
+		if event.Event == TxLifecycleAccepted {
+			require.Equal(common.Hash(blk.ID()), *event.BlockHash)
+			require.EqualValues(blk.Height(), *event.BlockNumber)
+		}
+	}
+
+	// Unknown transactions have no events.
+	events, err = api.TxLifecycle(ctx, common.Hash{0x01})
+	require.NoError(err)
+	require.Empty(events)
+}
+
+func TestTxLifecycleDisabled(t *testing.T) {
+	h := NewTestHarness(t, TestHarnessConfig{})
+	_, err := (&TxLifecycleAPI{h.VM}).TxLifecycle(context.Background(), common.Hash{})
+	require.ErrorIs(t, err, errTxLifecycleDisabled)
+}
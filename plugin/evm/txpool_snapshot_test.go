@@ -0,0 +1,92 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shubhamdubey02/coreth/core"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/eth"
+	"github.com/shubhamdubey02/coreth/internal/ethapi"
+	"github.com/shubhamdubey02/coreth/params"
+)
+
+func TestTxPoolSnapshotFailover(t *testing.T) {
+	require := require.New(t)
+
+	localKey, err := crypto.GenerateKey()
+	require.NoError(err)
+	localAddr := crypto.PubkeyToAddress(localKey.PublicKey)
+	config := TestHarnessConfig{
+		Config: `{"local-txs-enabled": true}`,
+		Genesis: &core.Genesis{
+			Config:     params.TestChainConfig,
+			Difficulty: big.NewInt(0),
+			Alloc:      core.GenesisAlloc{localAddr: {Balance: big.NewInt(params.Ether)}},
+		},
+	}
+	primary := NewTestHarness(t, config)
+	standby := NewTestHarness(t, config)
+
+	transfer := func(key *ecdsa.PrivateKey, nonce uint64) *types.Transaction {
+		tx := types.NewTransaction(nonce, common.Address{0xaa}, big.NewInt(1), params.TxGas, big.NewInt(params.LaunchMinGasPrice), nil)
+		return primary.SignTx(tx, key)
+	}
+	// Two executable remote transactions, one queued behind a nonce gap and
+	// one local transaction.
+	remotes := []*types.Transaction{
+		transfer(HarnessFunderKey, 0),
+		transfer(HarnessFunderKey, 1),
+		transfer(HarnessFunderKey, 3),
+	}
+	arrival := time.Unix(1_000, 0).UTC()
+	for i, tx := range remotes {
+		tx.SetTime(arrival.Add(time.Duration(i) * time.Second))
+	}
+	primary.IssueTx(remotes...)
+	local := transfer(localKey, 0)
+	local.SetTime(arrival)
+	require.NoError(primary.VM.txPool.Add([]*types.Transaction{local}, true, true)[0])
+
+	snapshot, err := eth.NewAdminAPI(primary.VM.eth).ExportTxPool()
+	require.NoError(err)
+	require.Len(snapshot.Transactions, 4)
+
+	// Send the snapshot over the wire as an RPC client would.
+	encoded, err := json.Marshal(snapshot)
+	require.NoError(err)
+	var received ethapi.PoolSnapshot
+	require.NoError(json.Unmarshal(encoded, &received))
+
+	standbyAPI := eth.NewAdminAPI(standby.VM.eth)
+	result, err := standbyAPI.ImportTxPool(received)
+	require.NoError(err)
+	require.Equal(4, int(result.Imported))
+	require.Empty(result.Errors)
+
+	pending, queued := standby.VM.txPool.Stats()
+	require.Equal(3, pending)
+	require.Equal(1, queued)
+	require.Equal([]common.Address{localAddr}, standby.VM.txPool.Locals())
+	for _, tx := range append(remotes, local) {
+		restored := standby.VM.txPool.Get(tx.Hash())
+		require.NotNil(restored)
+		require.True(tx.Time().Equal(restored.Time()))
+	}
+
+	// Importing the same snapshot again reports every transaction as known.
+	result, err = standbyAPI.ImportTxPool(received)
+	require.NoError(err)
+	require.Zero(result.Imported)
+	require.Len(result.Errors, 4)
+}
@@ -0,0 +1,120 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/shubhamdubey02/coreth/core/txpool/userops"
+	"github.com/shubhamdubey02/coreth/core/types"
+)
+
+// userOpsConsumer is the name of the accepted block consumer removing the
+// included user operations from the pool.
+const userOpsConsumer = "user-ops"
+
+var errUserOpsDisabled = errors.New("user operations are not enabled")
+
+// startUserOps creates the pool of user operations, bundles them into the
+// blocks built by the miner, and removes them from the pool once they are
+// included in accepted blocks.
+func (vm *VM) startUserOps() error {
+	if !vm.config.UserOps.Enabled {
+		return nil
+	}
+	key, err := crypto.LoadECDSA(vm.config.UserOps.BundlerKeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load bundler key: %w", err)
+	}
+	pool := userops.New(userops.Config{
+		EntryPoint:   vm.config.UserOps.EntryPoint,
+		MaxOps:       vm.config.UserOps.MaxOps,
+		MaxBundleOps: vm.config.UserOps.MaxBundleOps,
+	}, vm.chainConfig, vm.blockChain)
+	bundler := userops.NewBundler(pool, key)
+	vm.miner.SetBundler(bundler)
+	vm.userOps = pool
+	log.Info("Bundling user operations", "entryPoint", vm.config.UserOps.EntryPoint, "bundler", bundler.Address())
+
+	return vm.consumeAcceptedBlocks(userOpsConsumer, func(block *types.Block) error {
+		pool.Accept(types.FlattenLogs(vm.blockChain.GetLogs(block.Hash(), block.NumberU64())))
+		return nil
+	})
+}
+
+// UserOpAPI serves the ERC-4337 bundler methods of the eth namespace, so
+// that the node accepts user operations without an external bundler.
+type UserOpAPI struct {
+	vm *VM
+}
+
+// SendUserOperation validates [op] against [entryPoint] and adds it to the
+// pool of user operations, returning its hash.
+func (api *UserOpAPI) SendUserOperation(ctx context.Context, op userops.UserOperation, entryPoint common.Address) (common.Hash, error) {
+	if api.vm.userOps == nil {
+		return common.Hash{}, errUserOpsDisabled
+	}
+	return api.vm.userOps.Add(&op, entryPoint)
+}
+
+// SupportedEntryPoints returns the EntryPoints whose user operations are
+// accepted.
+func (api *UserOpAPI) SupportedEntryPoints() ([]common.Address, error) {
+	if api.vm.userOps == nil {
+		return nil, errUserOpsDisabled
+	}
+	return []common.Address{api.vm.userOps.EntryPoint()}, nil
+}
+
+// UserOperationByHash is a user operation and, once it is included, the
+// block and transaction including it.
+type UserOperationByHash struct {
+	UserOperation   *userops.UserOperation `json:"userOperation"`
+	EntryPoint      common.Address         `json:"entryPoint"`
+	TransactionHash *common.Hash           `json:"transactionHash"`
+	BlockHash       *common.Hash           `json:"blockHash"`
+	BlockNumber     *hexutil.Uint64        `json:"blockNumber"`
+}
+
+// GetUserOperationByHash returns the pending or recently included user
+// operation [hash] submitted to this node, or nil if it is unknown.
+func (api *UserOpAPI) GetUserOperationByHash(ctx context.Context, hash common.Hash) (*UserOperationByHash, error) {
+	if api.vm.userOps == nil {
+		return nil, errUserOpsDisabled
+	}
+	result := &UserOperationByHash{EntryPoint: api.vm.userOps.EntryPoint()}
+	if op := api.vm.userOps.Get(hash); op != nil {
+		result.UserOperation = op
+		return result, nil
+	}
+	included := api.vm.userOps.Included(hash)
+	if included == nil || included.Op == nil {
+		return nil, nil
+	}
+	result.UserOperation = included.Op
+	result.TransactionHash = &included.Receipt.TransactionHash
+	result.BlockHash = &included.Receipt.BlockHash
+	result.BlockNumber = &included.Receipt.BlockNumber
+	return result, nil
+}
+
+// GetUserOperationReceipt returns the receipt of the user operation [hash]
+// included in a recently accepted block, or nil if it is unknown.
+func (api *UserOpAPI) GetUserOperationReceipt(ctx context.Context, hash common.Hash) (*userops.Receipt, error) {
+	if api.vm.userOps == nil {
+		return nil, errUserOpsDisabled
+	}
+	included := api.vm.userOps.Included(hash)
+	if included == nil {
+		return nil, nil
+	}
+	return included.Receipt, nil
+}
@@ -23,15 +23,19 @@ import (
 	"github.com/shubhamdubey02/cryftgo/network/p2p/gossip"
 	cryftgoConstants "github.com/shubhamdubey02/cryftgo/utils/constants"
 
+	"github.com/shubhamdubey02/coreth/accounts"
+	"github.com/shubhamdubey02/coreth/accounts/abi"
 	"github.com/shubhamdubey02/coreth/consensus/dummy"
 	"github.com/shubhamdubey02/coreth/constants"
 	"github.com/shubhamdubey02/coreth/core"
 	"github.com/shubhamdubey02/coreth/core/rawdb"
 	"github.com/shubhamdubey02/coreth/core/state"
 	"github.com/shubhamdubey02/coreth/core/txpool"
+	"github.com/shubhamdubey02/coreth/core/txpool/userops"
 	"github.com/shubhamdubey02/coreth/core/types"
 	"github.com/shubhamdubey02/coreth/eth"
 	"github.com/shubhamdubey02/coreth/eth/ethconfig"
+	"github.com/shubhamdubey02/coreth/eth/filters"
 	"github.com/shubhamdubey02/coreth/metrics"
 	corethPrometheus "github.com/shubhamdubey02/coreth/metrics/prometheus"
 	"github.com/shubhamdubey02/coreth/miner"
@@ -45,6 +49,8 @@ import (
 	"github.com/shubhamdubey02/coreth/rpc"
 	statesyncclient "github.com/shubhamdubey02/coreth/sync/client"
 	"github.com/shubhamdubey02/coreth/sync/client/stats"
+	syncHandlers "github.com/shubhamdubey02/coreth/sync/handlers"
+	"github.com/shubhamdubey02/coreth/sync/syncsim"
 	"github.com/shubhamdubey02/coreth/trie"
 	"github.com/shubhamdubey02/coreth/utils"
 	"github.com/shubhamdubey02/coreth/warp"
@@ -64,6 +70,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rlp"
 
@@ -170,11 +177,13 @@ const (
 
 var (
 	// Set last accepted key to be longer than the keys used to store accepted block IDs.
-	lastAcceptedKey = []byte("last_accepted_key")
-	acceptedPrefix  = []byte("snowman_accepted")
-	metadataPrefix  = []byte("metadata")
-	warpPrefix      = []byte("warp")
-	ethDBPrefix     = []byte("ethdb")
+	lastAcceptedKey      = []byte("last_accepted_key")
+	acceptedPrefix       = []byte("snowman_accepted")
+	metadataPrefix       = []byte("metadata")
+	warpPrefix           = []byte("warp")
+	warpValidatorsPrefix = []byte("warp_validators")
+	peerPrefix           = []byte("peer")
+	ethDBPrefix          = []byte("ethdb")
 
 	// Prefixes for atomic trie
 	atomicTrieDBPrefix     = []byte("atomicTrieDB")
@@ -210,6 +219,7 @@ var (
 	errTooManyAtomicTx                = errors.New("too many atomic tx")
 	errMissingAtomicTxs               = errors.New("cannot build a block with non-empty extra data and zero atomic transactions")
 	errInvalidHeaderPredicateResults  = errors.New("invalid header predicate results")
+	errStateSyncStorageExpiry         = errors.New("state sync is not supported with storage expiry")
 )
 
 var originalStderr *os.File
@@ -224,7 +234,6 @@ var legacyApiNames = map[string]string{
 	"internal-public-debug":            "internal-debug",
 	"internal-private-debug":           "internal-debug",
 	"internal-public-account":          "internal-account",
-	"internal-private-personal":        "internal-personal",
 
 	"public-eth":        "eth",
 	"public-eth-filter": "eth-filter",
@@ -280,6 +289,10 @@ type VM struct {
 	// set to a prefixDB with the prefix [warpPrefix]
 	warpDB database.Database
 
+	// [peerDB] is used to store the reputation of peers
+	// set to a prefixDB with the prefix [peerPrefix]
+	peerDB database.Database
+
 	toEngine chan<- commonEng.Message
 
 	syntacticBlockValidator BlockValidator
@@ -292,6 +305,47 @@ type VM struct {
 	atomicTrie AtomicTrie
 	// [atomicBackend] abstracts verification and processing of atomic transactions
 	atomicBackend AtomicBackend
+	// [activityIndex] maintains per-address activity for accepted blocks.
+	// Nil unless enabled in the config.
+	activityIndex *accountActivityIndex
+	// atomicOpsFeed delivers the atomic operations of accepted blocks.
+	atomicOpsFeed event.Feed
+
+	// retention deletes block data outside of the configured retention, and
+	// is nil if none is configured.
+	retention *retentionEngine
+	// compaction compacts key ranges of the database during quiet hours and
+	// after large deletions, and is nil unless enabled in the config.
+	compaction *rawdb.CompactionScheduler
+	// privateTxs tracks the transactions submitted through the private API,
+	// and is nil unless enabled in the config.
+	privateTxs *privateTxPool
+	// txLifecycle records the lifecycle events of transactions, and is nil
+	// unless enabled in the config.
+	txLifecycle *txLifecycle
+	// userOps holds the submitted ERC-4337 user operations, and is nil
+	// unless enabled in the config.
+	userOps *userops.Pool
+	// faucet sends funds to the addresses requesting them on test networks,
+	// and is nil unless enabled in the config.
+	faucet *faucet
+	// chainExporter exports the accepted chain to Parquet files, and is nil
+	// if the export is disabled.
+	chainExporter *chainExporter
+	// admin is the admin service, created on first use.
+	admin *Admin
+	// adminGRPC serves the admin service over gRPC, and is nil unless an
+	// address is configured.
+	adminGRPC *adminGRPCServer
+	// watchdog monitors the liveness of critical loops, and is nil unless
+	// enabled.
+	watchdog *watchdog
+	// arbiter schedules RPC calls around consensus work, and is nil unless
+	// enabled.
+	arbiter *resourceArbiter
+	// memoryAccountant reports the memory used by subsystems and enforces
+	// their budgets, and is nil unless enabled.
+	memoryAccountant *memoryAccountant
 
 	builder *blockBuilder
 
@@ -312,6 +366,9 @@ type VM struct {
 	peer.Network
 	client       peer.NetworkClient
 	networkCodec codec.Manager
+	// syncClient fetches data from peers, such as the blob sidecars of
+	// accepted blocks missing locally.
+	syncClient statesyncclient.Client
 
 	validators *p2p.Validators
 
@@ -322,7 +379,19 @@ type VM struct {
 	bootstrapped bool
 	IsPlugin     bool
 
+	// AccountBackends are account backends, such as HSM or remote signers,
+	// used by the signing methods of the eth API alongside
+	// keystore-external-signer. If any are set, the local keystore is not used.
+	AccountBackends []accounts.Backend
+
+	// TrieNamespaces are additional tries, such as those of indexes, served
+	// to peers through leafs requests alongside the state and atomic tries.
+	// They must be set before Initialize.
+	TrieNamespaces []syncHandlers.TrieNamespace
+
 	logger CorethLogger
+	// logFile is the rotated log file, if configured.
+	logFile io.WriteCloser
 	// State sync server and client
 	StateSyncServer
 	StateSyncClient
@@ -403,11 +472,20 @@ func (vm *VM) Initialize(
 	if vm.IsPlugin {
 		writer = originalStderr
 	}
+	if vm.config.LogFile != "" {
+		vm.logFile = newLogFileWriter(&vm.config)
+		writer = io.MultiWriter(writer, vm.logFile)
+	}
 
 	corethLogger, err := InitLogger(alias, vm.config.LogLevel, vm.config.LogJSONFormat, writer)
 	if err != nil {
 		return fmt.Errorf("failed to initialize logger due to: %w ", err)
 	}
+	for module, level := range vm.config.LogModuleLevels {
+		if err := corethLogger.SetModuleLogLevel(module, level); err != nil {
+			return fmt.Errorf("failed to set log level of module %s: %w", module, err)
+		}
+	}
 	vm.logger = corethLogger
 
 	log.Info("Initializing Coreth VM", "Version", Version, "Config", vm.config)
@@ -425,16 +503,46 @@ func (vm *VM) Initialize(
 
 	vm.toEngine = toEngine
 	vm.shutdownChan = make(chan struct{}, 1)
+	if err := vm.restoreBackup(db); err != nil {
+		return fmt.Errorf("failed to restore backup: %w", err)
+	}
+	// Backups hold the entries of the underlying database, which remain
+	// encrypted if the database is.
+	backupDB := db
+	encryptionKey, err := loadDatabaseEncryptionKey(vm.config)
+	if err != nil {
+		return err
+	}
+	db, err = openDatabase(db, encryptionKey)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
 	// Use NewNested rather than New so that the structure of the database
 	// remains the same regardless of the provided baseDB type.
 	vm.chaindb = rawdb.NewDatabase(Database{prefixdb.NewNested(ethDBPrefix, db)})
 	vm.db = versiondb.New(db)
 	vm.acceptedBlockDB = prefixdb.New(acceptedPrefix, vm.db)
 	vm.metadataDB = prefixdb.New(metadataPrefix, vm.db)
+	if err := migrateDatabase(vm.db, databaseMigrations); err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
 	// Note warpDB is not part of versiondb because it is not necessary
 	// that warp signatures are committed to the database atomically with
 	// the last accepted block.
 	vm.warpDB = prefixdb.New(warpPrefix, db)
+	// Likewise, the reputation of peers is not part of versiondb.
+	vm.peerDB = prefixdb.New(peerPrefix, db)
+	// Validator sets are snapshotted on the chain context so that warp
+	// predicates, which only have access to the chain context, use them.
+	vm.ctx.ValidatorState = warpValidators.NewSnapshots(
+		vm.ctx.ValidatorState,
+		prefixdb.New(warpValidatorsPrefix, db),
+		vm.config.WarpValidatorSnapshots.CacheSize,
+		vm.config.WarpValidatorSnapshots.Retention,
+	)
+	if vm.config.AccountActivityIndexEnabled {
+		vm.activityIndex = newAccountActivityIndex(db)
+	}
 
 	if vm.config.InspectDatabase {
 		start := time.Now()
@@ -449,6 +557,12 @@ func (vm *VM) Initialize(
 	if err := json.Unmarshal(genesisBytes, g); err != nil {
 		return err
 	}
+	var upgradeConfig params.UpgradeConfig
+	if len(upgradeBytes) > 0 {
+		if err := json.Unmarshal(upgradeBytes, &upgradeConfig); err != nil {
+			return fmt.Errorf("failed to parse upgrade bytes: %w", err)
+		}
+	}
 
 	var extDataHashes map[common.Hash]common.Hash
 	// Set the chain config for mainnet/mustang chain IDs
@@ -471,6 +585,8 @@ func (vm *VM) Initialize(
 			Config: warpPrecompile.NewDefaultConfig(g.Config.DurangoBlockTimestamp),
 		})
 	}
+	// Custom transaction types are enabled and disabled by the upgrade bytes.
+	g.Config.TxTypeUpgrades = upgradeConfig.TxTypeUpgrades
 	// Set the Avalanche Context on the ChainConfig
 	g.Config.AvalancheContext = params.AvalancheContext{
 		SnowCtx: chainCtx,
@@ -509,6 +625,15 @@ func (vm *VM) Initialize(
 	vm.ethConfig.RPCGasCap = vm.config.RPCGasCap
 	vm.ethConfig.RPCEVMTimeout = vm.config.APIMaxDuration.Duration
 	vm.ethConfig.RPCTxFeeCap = vm.config.RPCTxFeeCap
+	vm.ethConfig.RPCEstimateGasTrace = vm.config.EstimateGasRevertTrace
+	vm.ethConfig.RPCAcceptanceMetadata = vm.config.RPCAcceptanceMetadata
+	if vm.config.RevertErrorABIFile != "" {
+		errorABI, err := loadErrorABI(vm.config.RevertErrorABIFile)
+		if err != nil {
+			return err
+		}
+		vm.ethConfig.RPCErrorABI = errorABI
+	}
 
 	vm.ethConfig.TxPool.NoLocals = !vm.config.LocalTxsEnabled
 	vm.ethConfig.TxPool.PriceLimit = vm.config.TxPoolPriceLimit
@@ -518,8 +643,13 @@ func (vm *VM) Initialize(
 	vm.ethConfig.TxPool.AccountQueue = vm.config.TxPoolAccountQueue
 	vm.ethConfig.TxPool.GlobalQueue = vm.config.TxPoolGlobalQueue
 	vm.ethConfig.TxPool.Lifetime = vm.config.TxPoolLifetime.Duration
+	vm.ethConfig.TxPool.SponsoredSlots = vm.config.TxPoolSponsoredSlots
+	vm.ethConfig.Miner.GasReservation = vm.config.BlockGasReservation
+	vm.ethConfig.Miner.OrderingSeed = vm.config.BlockBuildingSeed
+	vm.ethConfig.Miner.Aging = vm.config.BlockBuildingAging.policy()
 
 	vm.ethConfig.AllowUnfinalizedQueries = vm.config.AllowUnfinalizedQueries
+	vm.ethConfig.DurableFilterRetention = vm.config.DurableFilterRetention.Duration
 	vm.ethConfig.AllowUnprotectedTxs = vm.config.AllowUnprotectedTxs
 	vm.ethConfig.AllowUnprotectedTxHashes = vm.config.AllowUnprotectedTxHashes
 	vm.ethConfig.Preimages = vm.config.Preimages
@@ -528,22 +658,37 @@ func (vm *VM) Initialize(
 	vm.ethConfig.TrieDirtyCache = vm.config.TrieDirtyCache
 	vm.ethConfig.TrieDirtyCommitTarget = vm.config.TrieDirtyCommitTarget
 	vm.ethConfig.TriePrefetcherParallelism = vm.config.TriePrefetcherParallelism
+	vm.ethConfig.TriePrefetcherMaxPendingTasks = vm.config.TriePrefetcherMaxPendingTasks
+	vm.ethConfig.TriePrefetcherAdaptive = vm.config.TriePrefetcherAdaptive
+	vm.ethConfig.TriePrefetcherMaxParallelism = vm.config.TriePrefetcherMaxParallelism
 	vm.ethConfig.SnapshotCache = vm.config.SnapshotCache
+	vm.ethConfig.CacheTuner = vm.config.CacheTuner.tuner()
 	vm.ethConfig.AcceptorQueueLimit = vm.config.AcceptorQueueLimit
 	vm.ethConfig.PopulateMissingTries = vm.config.PopulateMissingTries
 	vm.ethConfig.PopulateMissingTriesParallelism = vm.config.PopulateMissingTriesParallelism
 	vm.ethConfig.AllowMissingTries = vm.config.AllowMissingTries
-	vm.ethConfig.SnapshotDelayInit = vm.stateSyncEnabled(lastAcceptedHeight)
 	vm.ethConfig.SnapshotWait = vm.config.SnapshotWait
 	vm.ethConfig.SnapshotVerify = vm.config.SnapshotVerify
 	vm.ethConfig.OfflinePruning = vm.config.OfflinePruning
 	vm.ethConfig.OfflinePruningBloomFilterSize = vm.config.OfflinePruningBloomFilterSize
 	vm.ethConfig.OfflinePruningDataDirectory = vm.config.OfflinePruningDataDirectory
 	vm.ethConfig.CommitInterval = vm.config.CommitInterval
+	vm.ethConfig.HistoricalStateReexec = vm.config.HistoricalStateMaxReexec
+	vm.ethConfig.HistoricalStateCache = vm.config.HistoricalStateCache
+	vm.ethConfig.ArchiveProxyURL = vm.config.ArchiveProxyURL
+	vm.ethConfig.ArchiveProxyTimeout = vm.config.ArchiveProxyTimeout.Duration
 	vm.ethConfig.SkipUpgradeCheck = vm.config.SkipUpgradeCheck
 	vm.ethConfig.AcceptedCacheSize = vm.config.AcceptedCacheSize
 	vm.ethConfig.TxLookupLimit = vm.config.TxLookupLimit
 	vm.ethConfig.SkipTxIndexing = vm.config.SkipTxIndexing
+	vm.ethConfig.ReceiptCosts = vm.config.ReceiptCostsEnabled
+	vm.ethConfig.ReceiptsVerification = vm.config.ReceiptsVerification
+	if vm.config.ReceiptsVerification {
+		// Snapshots are generated from the state, which is not kept.
+		vm.ethConfig.SnapshotCache = 0
+	}
+	vm.ethConfig.TraceIndex = vm.config.TraceIndexEnabled
+	vm.ethConfig.OpcodeStatsSampleRate = vm.config.OpcodeStatsSampleRate
 
 	// Create directory for offline pruning
 	if len(vm.ethConfig.OfflinePruningDataDirectory) != 0 {
@@ -564,6 +709,12 @@ func (vm *VM) Initialize(
 	if err := vm.chainConfig.Verify(); err != nil {
 		return fmt.Errorf("failed to verify chain config: %w", err)
 	}
+	// The storage expiry index is not synced, so a synced node would expire
+	// slots accessed before the summary.
+	if vm.chainConfig.StorageExpiry != nil && vm.config.StateSyncEnabled != nil && *vm.config.StateSyncEnabled {
+		return errStateSyncStorageExpiry
+	}
+	vm.ethConfig.SnapshotDelayInit = vm.stateSyncEnabled(lastAcceptedHeight)
 
 	vm.codec = Codec
 
@@ -588,8 +739,17 @@ func (vm *VM) Initialize(
 	}
 	vm.validators = p2p.NewValidators(p2pNetwork.Peers, vm.ctx.Log, vm.ctx.SubnetID, vm.ctx.ValidatorState, maxValidatorSetStaleness)
 	vm.networkCodec = message.Codec
-	vm.Network = peer.NewNetwork(p2pNetwork, appSender, vm.networkCodec, message.CrossChainCodec, chainCtx.NodeID, vm.config.MaxOutboundActiveRequests, vm.config.MaxOutboundActiveCrossChainRequests)
+	vm.Network = peer.NewNetwork(p2pNetwork, appSender, vm.networkCodec, message.CrossChainCodec, chainCtx.NodeID, vm.config.MaxOutboundActiveRequests, vm.config.MaxOutboundActiveCrossChainRequests, vm.config.GossipDedupCacheSize)
 	vm.client = peer.NewNetworkClient(vm.Network)
+	if probes := vm.config.PeerHealthProbes.probes(); probes != nil {
+		if err := vm.Network.StartHealthProbes(*probes); err != nil {
+			return fmt.Errorf("failed to start peer health probes: %w", err)
+		}
+	}
+	if err := vm.loadPeerReputation(); err != nil {
+		return fmt.Errorf("failed to load peer reputation: %w", err)
+	}
+	vm.startPeerReputationSaver()
 
 	// Initialize warp backend
 	offchainWarpMessages := make([][]byte, len(vm.config.WarpOffChainMessages))
@@ -611,6 +771,9 @@ func (vm *VM) Initialize(
 	if err := vm.initializeChain(lastAcceptedHash); err != nil {
 		return err
 	}
+	if err := vm.runBench(); err != nil {
+		return fmt.Errorf("failed to benchmark blocks: %w", err)
+	}
 	// initialize bonus blocks on mainnet
 	var (
 		bonusBlockHeights map[uint64]ids.ID
@@ -651,6 +814,36 @@ func (vm *VM) Initialize(
 
 	go vm.ctx.Log.RecoverAndPanic(vm.startContinuousProfiler)
 
+	vm.startWatchdog()
+	if vm.config.ResourceArbiter.Enabled {
+		vm.arbiter = newResourceArbiter(vm.config.ResourceArbiter)
+	}
+	if err := vm.startCompaction(); err != nil {
+		return fmt.Errorf("failed to start compaction scheduler: %w", err)
+	}
+	vm.startPrivateTxs()
+	if err := vm.startTxLifecycle(); err != nil {
+		return fmt.Errorf("failed to start transaction lifecycle tracing: %w", err)
+	}
+	if err := vm.startUserOps(); err != nil {
+		return fmt.Errorf("failed to start user operation pool: %w", err)
+	}
+	if err := vm.startAccountActivityIndex(); err != nil {
+		return fmt.Errorf("failed to start account activity index: %w", err)
+	}
+	if err := vm.startChainExport(); err != nil {
+		return fmt.Errorf("failed to start chain export: %w", err)
+	}
+	if err := vm.startBackups(backupDB); err != nil {
+		return fmt.Errorf("failed to start backups: %w", err)
+	}
+	if err := vm.startFaucet(); err != nil {
+		return fmt.Errorf("failed to start faucet: %w", err)
+	}
+	if err := vm.startAdminGRPC(); err != nil {
+		return fmt.Errorf("failed to start admin gRPC service: %w", err)
+	}
+
 	// The Codec explicitly registers the types it requires from the secp256k1fx
 	// so [vm.baseCodec] is a dummy codec use to fulfill the secp256k1fx VM
 	// interface. The fx will register all of its types, which can be safely
@@ -661,8 +854,23 @@ func (vm *VM) Initialize(
 		return err
 	}
 
-	vm.initializeStateSyncServer()
-	return vm.initializeStateSyncClient(lastAcceptedHeight)
+	if err := vm.initializeStateSyncServer(); err != nil {
+		return err
+	}
+	if err := vm.initializeStateSyncClient(lastAcceptedHeight); err != nil {
+		return err
+	}
+	if err := vm.startBlobSidecars(); err != nil {
+		return fmt.Errorf("failed to start blob sidecar fetching: %w", err)
+	}
+	// The retention policy holds the bodies of the blocks not yet processed
+	// by the accepted block consumers, so it starts once they are registered.
+	if err := vm.startRetention(); err != nil {
+		return fmt.Errorf("failed to start retention policy: %w", err)
+	}
+	// Memory accounting reports the usage of the state sync client.
+	vm.startMemoryAccounting()
+	return nil
 }
 
 func (vm *VM) initializeMetrics() error {
@@ -691,6 +899,7 @@ func (vm *VM) initializeChain(lastAcceptedHash common.Hash) error {
 		KeyStoreDir:           vm.config.KeystoreDirectory,
 		ExternalSigner:        vm.config.KeystoreExternalSigner,
 		InsecureUnlockAllowed: vm.config.KeystoreInsecureUnlockAllowed,
+		AccountBackends:       vm.AccountBackends,
 	}
 	node, err := node.New(nodecfg)
 	if err != nil {
@@ -713,58 +922,132 @@ func (vm *VM) initializeChain(lastAcceptedHash common.Hash) error {
 	vm.txPool = vm.eth.TxPool()
 	vm.blockChain = vm.eth.BlockChain()
 	vm.miner = vm.eth.Miner()
+	if vm.config.IncidentFilterFile != "" {
+		if err := vm.loadIncidentFilter(); err != nil {
+			return err
+		}
+	}
 
 	// Set the gas parameters for the tx pool to the minimum gas price for the
 	// latest upgrade.
 	vm.txPool.SetGasTip(big.NewInt(0))
 	vm.txPool.SetMinFee(big.NewInt(params.ApricotPhase4MinBaseFee))
+	if vm.chainConfig.GasSponsorship != nil {
+		vm.txPool.SetSponsor(txpool.NewContractSponsor(vm.chainConfig, vm.blockChain))
+	}
 
 	vm.eth.Start()
 	return vm.initChainState(vm.blockChain.LastAcceptedBlock())
 }
 
+// loadIncidentFilter reads the incident filter from the configured file and
+// applies it to block building.
+func (vm *VM) loadIncidentFilter() error {
+	filter, err := miner.ReadIncidentFilter(vm.config.IncidentFilterFile)
+	if err != nil {
+		return err
+	}
+	if err := vm.miner.SetIncidentFilter(filter); err != nil {
+		return fmt.Errorf("invalid incident filter %s: %w", vm.config.IncidentFilterFile, err)
+	}
+	log.Info("Loaded incident filter", "file", vm.config.IncidentFilterFile, "addresses", len(filter.Addresses), "selectors", len(filter.Selectors))
+	return nil
+}
+
 // initializeStateSyncClient initializes the client for performing state sync.
 // If state sync is disabled, this function will wipe any ongoing summary from
 // disk to ensure that we do not continue syncing from an invalid snapshot.
 func (vm *VM) initializeStateSyncClient(lastAcceptedHeight uint64) error {
 	stateSyncEnabled := vm.stateSyncEnabled(lastAcceptedHeight)
 	// parse nodeIDs from state sync IDs in vm config
-	var stateSyncIDs []ids.NodeID
-	if stateSyncEnabled && len(vm.config.StateSyncIDs) > 0 {
-		nodeIDs := strings.Split(vm.config.StateSyncIDs, ",")
-		stateSyncIDs = make([]ids.NodeID, len(nodeIDs))
-		for i, nodeIDString := range nodeIDs {
-			nodeID, err := ids.NodeIDFromString(nodeIDString)
+	var (
+		stateSyncIDs   []ids.NodeID
+		trustedNodeIDs []ids.NodeID
+	)
+	if stateSyncEnabled {
+		var err error
+		if stateSyncIDs, err = parseNodeIDs(vm.config.StateSyncIDs); err != nil {
+			return err
+		}
+		if trustedNodeIDs, err = parseNodeIDs(vm.config.StateSyncTrustedNodeIDs); err != nil {
+			return err
+		}
+	}
+
+	var checkpoints *statesyncclient.CheckpointVerifier
+	if stateSyncEnabled {
+		trusted := vm.config.StateSyncCheckpoints
+		if vm.config.StateSyncCheckpointFeed != "" {
+			signed, err := statesyncclient.ReadSignedCheckpoints(vm.config.StateSyncCheckpointFeed, vm.config.StateSyncCheckpointSigners)
 			if err != nil {
-				return fmt.Errorf("failed to parse %s as NodeID: %w", nodeIDString, err)
+				return err
 			}
-			stateSyncIDs[i] = nodeID
+			trusted = append(trusted, signed...)
+		}
+		if len(trusted) > 0 {
+			var err error
+			checkpoints, err = statesyncclient.NewCheckpointVerifier(trusted)
+			if err != nil {
+				return fmt.Errorf("failed to initialize state sync checkpoints: %w", err)
+			}
+			log.Info("Enforcing trusted state sync checkpoints", "count", checkpoints.Len())
 		}
 	}
 
+	var (
+		syncNetworkClient = vm.client
+		syncFixtures      *syncsim.Fixtures
+	)
+	if stateSyncEnabled && vm.config.StateSyncRecordFile != "" {
+		syncFixtures = syncsim.NewFixtures(common.Hash{})
+		syncNetworkClient = syncsim.Record(vm.client, syncFixtures)
+		log.Info("Recording state sync responses", "path", vm.config.StateSyncRecordFile)
+	}
+
+	vm.syncClient = statesyncclient.NewClient(
+		&statesyncclient.ClientConfig{
+			NetworkClient:    syncNetworkClient,
+			Codec:            vm.networkCodec,
+			Stats:            stats.NewClientSyncerStats(),
+			StateSyncNodeIDs: stateSyncIDs,
+			BlockParser:      vm,
+			MaxCodeSize:      vm.chainConfig.LargestMaxCodeSize(),
+		},
+	)
+	var trustedNodes *statesyncclient.TrustedNodeVerifier
+	if len(trustedNodeIDs) > 0 {
+		trustedNodes = statesyncclient.NewTrustedNodeVerifier(vm.syncClient, trustedNodeIDs)
+		log.Info("Confirming state summaries with trusted nodes", "count", len(trustedNodeIDs))
+	}
+	var availability *statesyncclient.AvailabilityEvaluator
+	if evaluator := vm.config.StateSyncAvailability.evaluator(); stateSyncEnabled && evaluator != nil {
+		var err error
+		availability, err = statesyncclient.NewAvailabilityEvaluator(vm.syncClient, *evaluator)
+		if err != nil {
+			return fmt.Errorf("failed to initialize state summary availability evaluator: %w", err)
+		}
+	}
 	vm.StateSyncClient = NewStateSyncClient(&stateSyncClientConfig{
-		chain: vm.eth,
-		state: vm.State,
-		client: statesyncclient.NewClient(
-			&statesyncclient.ClientConfig{
-				NetworkClient:    vm.client,
-				Codec:            vm.networkCodec,
-				Stats:            stats.NewClientSyncerStats(),
-				StateSyncNodeIDs: stateSyncIDs,
-				BlockParser:      vm,
-			},
-		),
-		enabled:              stateSyncEnabled,
-		skipResume:           vm.config.StateSyncSkipResume,
-		stateSyncMinBlocks:   vm.config.StateSyncMinBlocks,
-		stateSyncRequestSize: vm.config.StateSyncRequestSize,
-		lastAcceptedHeight:   lastAcceptedHeight, // TODO clean up how this is passed around
-		chaindb:              vm.chaindb,
-		metadataDB:           vm.metadataDB,
-		acceptedBlockDB:      vm.acceptedBlockDB,
-		db:                   vm.db,
-		atomicBackend:        vm.atomicBackend,
-		toEngine:             vm.toEngine,
+		chain:                 vm.eth,
+		state:                 vm.State,
+		client:                vm.syncClient,
+		enabled:               stateSyncEnabled,
+		skipResume:            vm.config.StateSyncSkipResume,
+		stateSyncMinBlocks:    vm.config.StateSyncMinBlocks,
+		stateSyncRequestSize:  vm.config.StateSyncRequestSize,
+		stateSyncMemoryBudget: vm.stateSyncMemoryBudget(),
+		lastAcceptedHeight:    lastAcceptedHeight, // TODO clean up how this is passed around
+		chaindb:               vm.chaindb,
+		metadataDB:            vm.metadataDB,
+		acceptedBlockDB:       vm.acceptedBlockDB,
+		db:                    vm.db,
+		atomicBackend:         vm.atomicBackend,
+		checkpoints:           checkpoints,
+		trustedNodes:          trustedNodes,
+		availability:          availability,
+		recordFixtures:        syncFixtures,
+		recordFile:            vm.config.StateSyncRecordFile,
+		toEngine:              vm.toEngine,
 	})
 
 	// If StateSync is disabled, clear any ongoing summary so that we will not attempt to resume
@@ -776,16 +1059,36 @@ func (vm *VM) initializeStateSyncClient(lastAcceptedHeight uint64) error {
 	return nil
 }
 
+// parseNodeIDs parses a comma separated list of node IDs.
+func parseNodeIDs(nodeIDsString string) ([]ids.NodeID, error) {
+	if len(nodeIDsString) == 0 {
+		return nil, nil
+	}
+	nodeIDStrings := strings.Split(nodeIDsString, ",")
+	nodeIDs := make([]ids.NodeID, len(nodeIDStrings))
+	for i, nodeIDString := range nodeIDStrings {
+		nodeID, err := ids.NodeIDFromString(nodeIDString)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s as NodeID: %w", nodeIDString, err)
+		}
+		nodeIDs[i] = nodeID
+	}
+	return nodeIDs, nil
+}
+
 // initializeStateSyncServer should be called after [vm.chain] is initialized.
-func (vm *VM) initializeStateSyncServer() {
+func (vm *VM) initializeStateSyncServer() error {
 	vm.StateSyncServer = NewStateSyncServer(&stateSyncServerConfig{
 		Chain:            vm.blockChain,
 		AtomicTrie:       vm.atomicTrie,
 		SyncableInterval: vm.config.StateSyncCommitInterval,
 	})
 
-	vm.setAppRequestHandlers()
+	if err := vm.setAppRequestHandlers(); err != nil {
+		return err
+	}
 	vm.setCrossChainAppRequestHandler()
+	return nil
 }
 
 func (vm *VM) initChainState(lastAcceptedBlock *types.Block) error {
@@ -823,6 +1126,8 @@ func (vm *VM) createConsensusCallbacks() dummy.ConsensusCallbacks {
 	return dummy.ConsensusCallbacks{
 		OnFinalizeAndAssemble: vm.onFinalizeAndAssemble,
 		OnExtraStateChange:    vm.onExtraStateChange,
+
+		OnReplayExtraStateChange: vm.onReplayExtraStateChange,
 	}
 }
 
@@ -981,6 +1286,18 @@ func (vm *VM) onFinalizeAndAssemble(header *types.Header, state *state.StateDB,
 }
 
 func (vm *VM) onExtraStateChange(block *types.Block, state *state.StateDB) (*big.Int, *big.Int, error) {
+	return vm.applyExtraStateChange(block, state, true)
+}
+
+// onReplayExtraStateChange applies the atomic txs of the accepted [block] to
+// [state] without verifying them or inserting them into the atomic backend.
+func (vm *VM) onReplayExtraStateChange(block *types.Block, state *state.StateDB) (*big.Int, *big.Int, error) {
+	return vm.applyExtraStateChange(block, state, false)
+}
+
+// applyExtraStateChange applies the atomic txs of [block] to [state]. If
+// [insert] is true, the txs are verified and inserted into the atomic backend.
+func (vm *VM) applyExtraStateChange(block *types.Block, state *state.StateDB, insert bool) (*big.Int, *big.Int, error) {
 	var (
 		batchContribution *big.Int = big.NewInt(0)
 		batchGasUsed      *big.Int = big.NewInt(0)
@@ -994,7 +1311,7 @@ func (vm *VM) onExtraStateChange(block *types.Block, state *state.StateDB) (*big
 	}
 
 	// If [atomicBackend] is nil, the VM is still initializing and is reprocessing accepted blocks.
-	if vm.atomicBackend != nil {
+	if insert && vm.atomicBackend != nil {
 		if vm.atomicBackend.IsBonus(block.NumberU64(), block.Hash()) {
 			log.Info("skipping atomic tx verification on bonus block", "block", block.Hash())
 		} else {
@@ -1090,6 +1407,8 @@ func (vm *VM) initBlockBuilding() error {
 	if err != nil {
 		return err
 	}
+	ethTxPool.private = vm.privateTxs
+	ethTxPool.lifecycle = vm.txLifecycle
 	vm.shutdownWg.Add(1)
 	go func() {
 		ethTxPool.Subscribe(ctx)
@@ -1116,7 +1435,7 @@ func (vm *VM) initBlockBuilding() error {
 	ethTxPushGossiper := vm.ethTxPushGossiper.Get()
 	if ethTxPushGossiper == nil {
 		ethTxPushGossiper, err = gossip.NewPushGossiper[*GossipEthTx](
-			ethTxGossipMarshaller,
+			txLifecycleMarshaller{lifecycle: vm.txLifecycle},
 			ethTxPool,
 			vm.validators,
 			ethTxGossipClient,
@@ -1134,17 +1453,28 @@ func (vm *VM) initBlockBuilding() error {
 	}
 
 	if vm.atomicTxPushGossiper == nil {
+		// Atomic txs are gossiped with their own parameters, as their volume
+		// differs greatly from that of eth txs.
+		atomicPushGossipParams := gossip.BranchingFactor{
+			StakePercentage: vm.config.AtomicPushGossipPercentStake,
+			Validators:      vm.config.AtomicPushGossipNumValidators,
+			Peers:           vm.config.AtomicPushGossipNumPeers,
+		}
+		atomicPushRegossipParams := gossip.BranchingFactor{
+			Validators: vm.config.AtomicPushRegossipNumValidators,
+			Peers:      vm.config.AtomicPushRegossipNumPeers,
+		}
 		vm.atomicTxPushGossiper, err = gossip.NewPushGossiper[*GossipAtomicTx](
 			atomicTxGossipMarshaller,
 			vm.mempool,
 			vm.validators,
 			atomicTxGossipClient,
 			atomicTxGossipMetrics,
-			pushGossipParams,
-			pushRegossipParams,
+			atomicPushGossipParams,
+			atomicPushRegossipParams,
 			pushGossipDiscardedElements,
 			txGossipTargetMessageSize,
-			vm.config.RegossipFrequency.Duration,
+			vm.config.AtomicRegossipFrequency.Duration,
 		)
 		if err != nil {
 			return fmt.Errorf("failed to initialize atomic tx push gossiper: %w", err)
@@ -1210,11 +1540,11 @@ func (vm *VM) initBlockBuilding() error {
 
 	vm.shutdownWg.Add(2)
 	go func() {
-		gossip.Every(ctx, vm.ctx.Log, ethTxPushGossiper, vm.config.PushGossipFrequency.Duration)
+		gossip.Every(ctx, vm.ctx.Log, vm.watchdog.gossiper(watchdogEthTxPushGossip, ethTxPushGossiper), vm.config.PushGossipFrequency.Duration)
 		vm.shutdownWg.Done()
 	}()
 	go func() {
-		gossip.Every(ctx, vm.ctx.Log, vm.ethTxPullGossiper, vm.config.PullGossipFrequency.Duration)
+		gossip.Every(ctx, vm.ctx.Log, vm.watchdog.gossiper(watchdogEthTxPullGossip, vm.ethTxPullGossiper), vm.config.PullGossipFrequency.Duration)
 		vm.shutdownWg.Done()
 	}()
 
@@ -1237,11 +1567,11 @@ func (vm *VM) initBlockBuilding() error {
 
 	vm.shutdownWg.Add(2)
 	go func() {
-		gossip.Every(ctx, vm.ctx.Log, vm.atomicTxPushGossiper, vm.config.PushGossipFrequency.Duration)
+		gossip.Every(ctx, vm.ctx.Log, vm.watchdog.gossiper(watchdogAtomicTxPushGossip, vm.atomicTxPushGossiper), vm.config.AtomicPushGossipFrequency.Duration)
 		vm.shutdownWg.Done()
 	}()
 	go func() {
-		gossip.Every(ctx, vm.ctx.Log, vm.atomicTxPullGossiper, vm.config.PullGossipFrequency.Duration)
+		gossip.Every(ctx, vm.ctx.Log, vm.watchdog.gossiper(watchdogAtomicTxPullGossip, vm.atomicTxPullGossiper), vm.config.AtomicPullGossipFrequency.Duration)
 		vm.shutdownWg.Done()
 	}()
 
@@ -1250,7 +1580,7 @@ func (vm *VM) initBlockBuilding() error {
 
 // setAppRequestHandlers sets the request handlers for the VM to serve state sync
 // requests.
-func (vm *VM) setAppRequestHandlers() {
+func (vm *VM) setAppRequestHandlers() error {
 	// Create separate EVM TrieDB (read only) for serving leafs requests.
 	// We create a separate TrieDB here, so that it has a separate cache from the one
 	// used by the node when processing blocks.
@@ -1262,15 +1592,21 @@ func (vm *VM) setAppRequestHandlers() {
 			},
 		},
 	)
-	networkHandler := newNetworkHandler(
+	networkHandler, err := newNetworkHandler(
 		vm.blockChain,
 		vm.chaindb,
 		evmTrieDB,
 		vm.atomicTrie.TrieDB(),
+		vm.TrieNamespaces,
 		vm.warpBackend,
 		vm.networkCodec,
+		vm.config.CommitInterval,
 	)
+	if err != nil {
+		return fmt.Errorf("failed to create network handler: %w", err)
+	}
 	vm.Network.SetRequestHandler(networkHandler)
+	return nil
 }
 
 // setCrossChainAppRequestHandler sets the request handlers for the VM to serve cross chain
@@ -1288,13 +1624,27 @@ func (vm *VM) Shutdown(context.Context) error {
 	if vm.cancel != nil {
 		vm.cancel()
 	}
+	if vm.adminGRPC != nil {
+		vm.adminGRPC.stop()
+	}
+	if err := vm.savePeerReputation(); err != nil {
+		log.Error("error saving peer reputation", "err", err)
+	}
 	vm.Network.Shutdown()
 	if err := vm.StateSyncClient.Shutdown(); err != nil {
 		log.Error("error stopping state syncer", "err", err)
 	}
 	close(vm.shutdownChan)
+	// The exporter writes its buffered blocks and checkpoint on close, so it
+	// must stop before the database is closed.
+	if vm.chainExporter != nil {
+		vm.chainExporter.close()
+	}
 	vm.eth.Stop()
 	vm.shutdownWg.Wait()
+	if vm.logFile != nil {
+		return vm.logFile.Close()
+	}
 	return nil
 }
 
@@ -1304,6 +1654,14 @@ func (vm *VM) buildBlock(ctx context.Context) (snowman.Block, error) {
 }
 
 func (vm *VM) buildBlockWithContext(ctx context.Context, proposerVMBlockCtx *block.Context) (snowman.Block, error) {
+	loop := vm.watchdog.loop(watchdogBuildBlock)
+	loop.begin()
+	defer loop.end()
+	defer vm.arbiter.beginConsensus()()
+
+	if vm.config.ReceiptsVerification {
+		return nil, errReceiptsVerificationBuild
+	}
 	if proposerVMBlockCtx != nil {
 		log.Debug("Building block with context", "pChainBlockHeight", proposerVMBlockCtx.PChainHeight)
 	} else {
@@ -1315,7 +1673,7 @@ func (vm *VM) buildBlockWithContext(ctx context.Context, proposerVMBlockCtx *blo
 	}
 
 	block, err := vm.miner.GenerateBlock(predicateCtx)
-	vm.builder.handleGenerateBlock()
+	vm.builder.handleGenerateBlock(err == nil)
 	if err != nil {
 		vm.mempool.CancelCurrentTxs()
 		return nil, err
@@ -1440,23 +1798,34 @@ func (vm *VM) Version(context.Context) (string, error) {
 //   - The name of the service is [name]
 func newHandler(name string, service interface{}) (http.Handler, error) {
 	server := avalancheRPC.NewServer()
-	server.RegisterCodec(avalancheJSON.NewCodec(), "application/json")
-	server.RegisterCodec(avalancheJSON.NewCodec(), "application/json;charset=UTF-8")
+	server.RegisterCodec(errorCodec{avalancheJSON.NewCodec()}, "application/json")
+	server.RegisterCodec(errorCodec{avalancheJSON.NewCodec()}, "application/json;charset=UTF-8")
 	return server, server.RegisterService(service, name)
 }
 
 // CreateHandlers makes new http handlers that can handle API calls
 func (vm *VM) CreateHandlers(context.Context) (map[string]http.Handler, error) {
 	handler := rpc.NewServer(vm.config.APIMaxDuration.Duration)
+	handler.SetSubscriptionLimits(vm.config.WSMaxSubscriptions, vm.config.WSNotificationBuffer, rpc.SlowConsumerPolicy(vm.config.WSSlowConsumerPolicy))
+	if err := handler.SetAccessPolicy(vm.config.APIAccessPolicy); err != nil {
+		return nil, err
+	}
+	if vm.arbiter != nil {
+		handler.SetCallScheduler(vm.arbiter)
+	}
+	if proxy := vm.eth.ArchiveProxy(); proxy != nil {
+		handler.SetCallFallback(proxy)
+	}
 	enabledAPIs := vm.config.EthAPIs()
-	if err := attachEthService(handler, vm.eth.APIs(), enabledAPIs); err != nil {
+	ethAPIs := append(vm.eth.APIs(), rpc.API{
+		Namespace: "eth",
+		Service:   filters.NewAtomicFilterAPI(&atomicFilterBackend{vm: vm}),
+		Name:      "atomic-filter",
+	})
+	if err := attachEthService(handler, ethAPIs, enabledAPIs); err != nil {
 		return nil, err
 	}
 
-	primaryAlias, err := vm.ctx.BCLookup.PrimaryAlias(vm.ctx.ChainID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get primary alias for chain due to %w", err)
-	}
 	apis := make(map[string]http.Handler)
 	cryftAPI, err := newHandler("cryft", &CryftAPI{vm})
 	if err != nil {
@@ -1466,7 +1835,11 @@ func (vm *VM) CreateHandlers(context.Context) (map[string]http.Handler, error) {
 	apis[cryftEndpoint] = cryftAPI
 
 	if vm.config.AdminAPIEnabled {
-		adminAPI, err := newHandler("admin", NewAdminService(vm, os.ExpandEnv(fmt.Sprintf("%s_coreth_performance_%s", vm.config.AdminAPIDir, primaryAlias))))
+		admin, err := vm.adminService()
+		if err != nil {
+			return nil, err
+		}
+		adminAPI, err := newHandler("admin", admin)
 		if err != nil {
 			return nil, fmt.Errorf("failed to register service for admin API due to %w", err)
 		}
@@ -1481,6 +1854,41 @@ func (vm *VM) CreateHandlers(context.Context) (map[string]http.Handler, error) {
 		enabledAPIs = append(enabledAPIs, "snowman")
 	}
 
+	if vm.config.AccountActivityIndexEnabled {
+		if err := handler.RegisterName("activity", &ActivityAPI{vm}); err != nil {
+			return nil, err
+		}
+		enabledAPIs = append(enabledAPIs, "activity")
+	}
+
+	if vm.config.PrivateTxs.Enabled {
+		if err := handler.RegisterName("private", &PrivateTxAPI{vm}); err != nil {
+			return nil, err
+		}
+		enabledAPIs = append(enabledAPIs, "private")
+	}
+
+	if vm.config.UserOps.Enabled {
+		if err := handler.RegisterName("eth", &UserOpAPI{vm}); err != nil {
+			return nil, err
+		}
+		enabledAPIs = append(enabledAPIs, "eth-user-ops")
+	}
+
+	if vm.config.TxLifecycle.Enabled {
+		if err := handler.RegisterName("debug", &TxLifecycleAPI{vm}); err != nil {
+			return nil, err
+		}
+		enabledAPIs = append(enabledAPIs, "debug-tx-lifecycle")
+	}
+
+	if vm.config.Faucet.Enabled {
+		if err := handler.RegisterName("faucet", &FaucetAPI{vm}); err != nil {
+			return nil, err
+		}
+		enabledAPIs = append(enabledAPIs, "faucet")
+	}
+
 	if vm.config.WarpAPIEnabled {
 		validatorsState := warpValidators.NewState(vm.ctx)
 		if err := handler.RegisterName("warp", warp.NewAPI(vm.ctx.NetworkID, vm.ctx.SubnetID, vm.ctx.ChainID, validatorsState, vm.warpBackend, vm.client)); err != nil {
@@ -1763,20 +2171,32 @@ func (vm *VM) GetSpendableFunds(
 	assetID ids.ID,
 	amount uint64,
 ) ([]EVMInput, [][]*secp256k1.PrivateKey, error) {
+	inputs, err := vm.getSpendableFunds(ethAddresses(keys), assetID, amount)
+	if err != nil {
+		return nil, nil, err
+	}
+	return inputs, inputSigners(inputs, keys), nil
+}
+
+// getSpendableFunds returns a list of EVMInputs to total [amount] of
+// [assetID] owned by [addrs].
+func (vm *VM) getSpendableFunds(
+	addrs []common.Address,
+	assetID ids.ID,
+	amount uint64,
+) ([]EVMInput, error) {
 	// Note: current state uses the state of the preferred block.
 	state, err := vm.blockChain.State()
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	inputs := []EVMInput{}
-	signers := [][]*secp256k1.PrivateKey{}
-	// Note: we assume that each key in [keys] is unique, so that iterating over
-	// the keys will not produce duplicated nonces in the returned EVMInput slice.
-	for _, key := range keys {
+	// Note: we assume that each address in [addrs] is unique, so that iterating over
+	// the addresses will not produce duplicated nonces in the returned EVMInput slice.
+	for _, addr := range addrs {
 		if amount == 0 {
 			break
 		}
-		addr := GetEthAddress(key)
 		var balance uint64
 		if assetID == vm.ctx.CRYFTAssetID {
 			// If the asset is CRYFT, we divide by the x2cRate to convert back to the correct
@@ -1793,7 +2213,7 @@ func (vm *VM) GetSpendableFunds(
 		}
 		nonce, err := vm.GetCurrentNonce(addr)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 		inputs = append(inputs, EVMInput{
 			Address: addr,
@@ -1801,15 +2221,14 @@ func (vm *VM) GetSpendableFunds(
 			AssetID: assetID,
 			Nonce:   nonce,
 		})
-		signers = append(signers, []*secp256k1.PrivateKey{key})
 		amount -= balance
 	}
 
 	if amount > 0 {
-		return nil, nil, errInsufficientFunds
+		return nil, errInsufficientFunds
 	}
 
-	return inputs, signers, nil
+	return inputs, nil
 }
 
 // GetSpendableCRYFTWithFee returns a list of EVMInputs and keys (in corresponding
@@ -1826,46 +2245,59 @@ func (vm *VM) GetSpendableCRYFTWithFee(
 	cost uint64,
 	baseFee *big.Int,
 ) ([]EVMInput, [][]*secp256k1.PrivateKey, error) {
+	inputs, err := vm.getSpendableCRYFTWithFee(ethAddresses(keys), amount, cost, baseFee)
+	if err != nil {
+		return nil, nil, err
+	}
+	return inputs, inputSigners(inputs, keys), nil
+}
+
+// getSpendableCRYFTWithFee returns a list of EVMInputs to total [amount] +
+// [fee] of [CRYFT] owned by [addrs]. See GetSpendableCRYFTWithFee.
+func (vm *VM) getSpendableCRYFTWithFee(
+	addrs []common.Address,
+	amount uint64,
+	cost uint64,
+	baseFee *big.Int,
+) ([]EVMInput, error) {
 	// Note: current state uses the state of the preferred block.
 	state, err := vm.blockChain.State()
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	initialFee, err := CalculateDynamicFee(cost, baseFee)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	newAmount, err := math.Add64(amount, initialFee)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	amount = newAmount
 
 	inputs := []EVMInput{}
-	signers := [][]*secp256k1.PrivateKey{}
-	// Note: we assume that each key in [keys] is unique, so that iterating over
-	// the keys will not produce duplicated nonces in the returned EVMInput slice.
-	for _, key := range keys {
+	// Note: we assume that each address in [addrs] is unique, so that iterating over
+	// the addresses will not produce duplicated nonces in the returned EVMInput slice.
+	for _, addr := range addrs {
 		if amount == 0 {
 			break
 		}
 
 		prevFee, err := CalculateDynamicFee(cost, baseFee)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 
 		newCost := cost + EVMInputGas
 		newFee, err := CalculateDynamicFee(newCost, baseFee)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 
 		additionalFee := newFee - prevFee
 
-		addr := GetEthAddress(key)
 		// Since the asset is CRYFT, we divide by the x2cRate to convert back to
 		// the correct denomination of CRYFT that can be exported.
 		balance := new(big.Int).Div(state.GetBalance(addr), x2cRate).Uint64()
@@ -1880,7 +2312,7 @@ func (vm *VM) GetSpendableCRYFTWithFee(
 
 		newAmount, err := math.Add64(amount, additionalFee)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 		amount = newAmount
 
@@ -1893,7 +2325,7 @@ func (vm *VM) GetSpendableCRYFTWithFee(
 		}
 		nonce, err := vm.GetCurrentNonce(addr)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 		inputs = append(inputs, EVMInput{
 			Address: addr,
@@ -1901,15 +2333,36 @@ func (vm *VM) GetSpendableCRYFTWithFee(
 			AssetID: vm.ctx.CRYFTAssetID,
 			Nonce:   nonce,
 		})
-		signers = append(signers, []*secp256k1.PrivateKey{key})
 		amount -= inputAmount
 	}
 
 	if amount > 0 {
-		return nil, nil, errInsufficientFunds
+		return nil, errInsufficientFunds
 	}
 
-	return inputs, signers, nil
+	return inputs, nil
+}
+
+// ethAddresses returns the ethereum addresses of [keys]
+func ethAddresses(keys []*secp256k1.PrivateKey) []common.Address {
+	addrs := make([]common.Address, len(keys))
+	for i, key := range keys {
+		addrs[i] = GetEthAddress(key)
+	}
+	return addrs
+}
+
+// inputSigners returns the key in [keys] that signs each of [inputs]
+func inputSigners(inputs []EVMInput, keys []*secp256k1.PrivateKey) [][]*secp256k1.PrivateKey {
+	keysByAddr := make(map[common.Address]*secp256k1.PrivateKey, len(keys))
+	for _, key := range keys {
+		keysByAddr[GetEthAddress(key)] = key
+	}
+	signers := make([][]*secp256k1.PrivateKey, len(inputs))
+	for i, input := range inputs {
+		signers[i] = []*secp256k1.PrivateKey{keysByAddr[input.Address]}
+	}
+	return signers
 }
 
 // GetCurrentNonce returns the nonce associated with the address at the
@@ -1929,6 +2382,46 @@ func (vm *VM) currentRules() params.Rules {
 	return vm.chainConfig.Rules(header.Number, header.Time)
 }
 
+// consumeAcceptedBlocks passes every accepted block, in order, to [fn] in the
+// background until the VM shuts down or [fn] fails. Blocks are delivered by
+// the accepted block consumer [name], so blocks accepted while the VM was down
+// are delivered after a restart, as is the block [fn] failed on.
+func (vm *VM) consumeAcceptedBlocks(name string, fn func(*types.Block) error) error {
+	consumer, err := vm.blockChain.NewAcceptedConsumer(name)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	vm.shutdownWg.Add(1)
+	go vm.ctx.Log.RecoverAndPanic(func() {
+		defer vm.shutdownWg.Done()
+		defer consumer.Close()
+
+		for {
+			block, err := consumer.Next(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Error("Accepted block consumer stopped", "name", name, "err", err)
+				}
+				return
+			}
+			if err := fn(block); err != nil {
+				log.Error("Accepted block consumer stopped", "name", name, "height", block.NumberU64(), "err", err)
+				return
+			}
+			if err := consumer.Ack(block.NumberU64()); err != nil {
+				log.Error("Failed to acknowledge accepted block", "name", name, "height", block.NumberU64(), "err", err)
+				return
+			}
+		}
+	})
+	go func() {
+		<-vm.shutdownChan
+		cancel()
+	}()
+	return nil
+}
+
 func (vm *VM) startContinuousProfiler() {
 	// If the profiler directory is empty, return immediately
 	// without creating or starting a continuous profiler.
@@ -2043,11 +2536,31 @@ func attachEthService(handler *rpc.Server, apis []rpc.API, names []string) error
 	return nil
 }
 
+// loadErrorABI reads the JSON ABI at [path], whose errors are used to decode
+// revert data.
+func loadErrorABI(path string) (*abi.ABI, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open revert error ABI: %w", err)
+	}
+	defer f.Close()
+
+	errorABI, err := abi.JSON(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse revert error ABI %s: %w", path, err)
+	}
+	return &errorABI, nil
+}
+
 func (vm *VM) stateSyncEnabled(lastAcceptedHeight uint64) bool {
 	if vm.config.StateSyncEnabled != nil {
 		// if the config is set, use that
 		return *vm.config.StateSyncEnabled
 	}
+	// state sync is not supported with storage expiry.
+	if vm.chainConfig.StorageExpiry != nil {
+		return false
+	}
 
 	// enable state sync by default if the chain is empty.
 	return lastAcceptedHeight == 0
@@ -0,0 +1,91 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+
+	commonEng "github.com/shubhamdubey02/cryftgo/snow/engine/common"
+
+	"github.com/shubhamdubey02/coreth/accounts"
+	"github.com/shubhamdubey02/coreth/accounts/keystore"
+	"github.com/shubhamdubey02/coreth/rpc"
+)
+
+func TestVMAccountBackends(t *testing.T) {
+	require := require.New(t)
+
+	// Without explicit configuration, the node holds no keys.
+	_, vm, _, _, _ := GenesisVM(t, false, "", "", "")
+	require.Empty(vm.eth.AccountManager().Accounts())
+	require.NoError(vm.Shutdown(context.Background()))
+
+	ks := keystore.NewKeyStore(t.TempDir(), keystore.LightScryptN, keystore.LightScryptP)
+	account, err := ks.NewAccount("password")
+	require.NoError(err)
+
+	vm = &VM{AccountBackends: []accounts.Backend{ks}}
+	ctx, db, genesisBytes, issuer, _ := setupGenesis(t, "")
+	require.NoError(vm.Initialize(context.Background(), ctx, db, genesisBytes, nil, nil, issuer, nil, &commonEng.SenderTest{}))
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+	require.Equal([]common.Address{account.Address}, vm.eth.AccountManager().Accounts())
+}
+
+func TestVMPersonalAPIIgnored(t *testing.T) {
+	require := require.New(t)
+
+	vm := &VM{}
+	ctx, db, genesisBytes, issuer, _ := setupGenesis(t, "")
+	require.NoError(vm.Initialize(context.Background(), ctx, db, genesisBytes, nil, []byte(`{"eth-apis": ["internal-eth", "internal-personal"]}`), issuer, nil, &commonEng.SenderTest{}))
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+	require.Equal([]string{"internal-eth"}, vm.config.EthAPIs())
+}
+
+func TestDeprecatePersonalAPI(t *testing.T) {
+	require := require.New(t)
+
+	config := Config{EnabledEthAPIs: []string{"internal-personal", "internal-transaction", "internal-private-personal"}}
+	msg := config.Deprecate()
+	require.Contains(msg, "internal-personal api has been removed and is ignored")
+	require.Contains(msg, "internal-private-personal api has been removed and is ignored")
+	require.Equal([]string{"internal-transaction"}, config.EnabledEthAPIs)
+}
+
+func TestTransactionSigningAPI(t *testing.T) {
+	require := require.New(t)
+
+	_, vm, _, _, _ := GenesisVM(t, false, "", "", "")
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	// The methods signing with the keys of the node are not served by the
+	// default APIs.
+	server := rpc.NewServer(0)
+	defer server.Stop()
+	require.NoError(attachEthService(server, vm.eth.APIs(), vm.config.EthAPIs()))
+	client := rpc.DialInProc(server)
+	defer client.Close()
+	for _, method := range []string{"eth_sign", "eth_signTransaction", "eth_sendTransaction"} {
+		err := client.Call(nil, method)
+		require.ErrorContains(err, "does not exist", method)
+	}
+
+	server = rpc.NewServer(0)
+	defer server.Stop()
+	require.NoError(attachEthService(server, vm.eth.APIs(), []string{"internal-transaction-signing"}))
+	client = rpc.DialInProc(server)
+	defer client.Close()
+	err := client.Call(nil, "eth_sign", common.Address{0x01}, hexutil.Bytes{0x01})
+	require.ErrorContains(err, accounts.ErrUnknownAccount.Error())
+}
@@ -3127,6 +3127,19 @@ func TestLastAcceptedBlockNumberAllow(t *testing.T) {
 		t.Fatalf("expected block at %d to have hash %s but got %s", blkHeight, blkHash.Hex(), b.Hash().Hex())
 	}
 
+	// safe and finalized refer to the last accepted block even if unfinalized
+	// queries are allowed.
+	lastAcceptedHash := vm.blockChain.LastAcceptedBlock().Hash()
+	for _, number := range []rpc.BlockNumber{rpc.SafeBlockNumber, rpc.FinalizedBlockNumber} {
+		b, err := vm.eth.APIBackend.BlockByNumber(ctx, number)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b.Hash() != lastAcceptedHash {
+			t.Fatalf("expected %s block to have hash %s but got %s", number, lastAcceptedHash.Hex(), b.Hash().Hex())
+		}
+	}
+
 	vm.eth.APIBackend.SetAllowUnfinalizedQueries(false)
 
 	_, err = vm.eth.APIBackend.BlockByNumber(ctx, rpc.BlockNumber(blkHeight))
@@ -0,0 +1,208 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/shubhamdubey02/cryftgo/network/p2p/gossip"
+)
+
+const (
+	watchdogInterval = time.Second
+
+	watchdogBuildBlock         = "build-block"
+	watchdogAcceptBlock        = "accept-block"
+	watchdogEthTxPushGossip    = "eth-tx-push-gossip"
+	watchdogEthTxPullGossip    = "eth-tx-pull-gossip"
+	watchdogAtomicTxPushGossip = "atomic-tx-push-gossip"
+	watchdogAtomicTxPullGossip = "atomic-tx-pull-gossip"
+)
+
+var errLoopsStalled = errors.New("loops stalled")
+
+// watchdogLoop tracks the liveness of a loop from heartbeats marking the
+// start and the end of each of its iterations. Its methods are no-ops on a
+// nil loop, so that loops need not check whether the watchdog is enabled.
+type watchdogLoop struct {
+	threshold time.Duration
+	// busySince is the time in nanoseconds the current iteration started,
+	// or zero if the loop is idle.
+	busySince atomic.Int64
+}
+
+// begin marks the start of an iteration.
+func (l *watchdogLoop) begin() {
+	if l != nil {
+		l.busySince.Store(time.Now().UnixNano())
+	}
+}
+
+// end marks the end of the current iteration.
+func (l *watchdogLoop) end() {
+	if l != nil {
+		l.busySince.Store(0)
+	}
+}
+
+// stalledFor returns how long the current iteration has run at [now] if it
+// exceeds the threshold of the loop, or zero otherwise.
+func (l *watchdogLoop) stalledFor(now time.Time) time.Duration {
+	busySince := l.busySince.Load()
+	if busySince == 0 {
+		return 0
+	}
+	if busy := now.Sub(time.Unix(0, busySince)); busy > l.threshold {
+		return busy
+	}
+	return 0
+}
+
+// watchedGossiper marks each gossip cycle of a gossiper as an iteration of
+// its loop.
+type watchedGossiper struct {
+	gossip.Gossiper
+	loop *watchdogLoop
+}
+
+func (g watchedGossiper) Gossip(ctx context.Context) error {
+	g.loop.begin()
+	defer g.loop.end()
+
+	return g.Gossiper.Gossip(ctx)
+}
+
+// watchdog monitors the liveness of the critical loops of the VM. A loop
+// whose iteration runs for longer than its threshold fails the health check,
+// and a goroutine dump is logged when it is first found stalled.
+type watchdog struct {
+	loops map[string]*watchdogLoop
+	now   func() time.Time
+
+	lock    sync.Mutex
+	stalled map[string]bool
+}
+
+func newWatchdog(config WatchdogConfig) *watchdog {
+	w := &watchdog{
+		loops:   make(map[string]*watchdogLoop),
+		now:     time.Now,
+		stalled: make(map[string]bool),
+	}
+	w.loops[watchdogBuildBlock] = &watchdogLoop{threshold: config.BuildBlockThreshold.Duration}
+	w.loops[watchdogAcceptBlock] = &watchdogLoop{threshold: config.AcceptBlockThreshold.Duration}
+	for _, name := range []string{watchdogEthTxPushGossip, watchdogEthTxPullGossip, watchdogAtomicTxPushGossip, watchdogAtomicTxPullGossip} {
+		w.loops[name] = &watchdogLoop{threshold: config.GossipThreshold.Duration}
+	}
+	return w
+}
+
+// loop returns the loop named [name], or nil if the watchdog is disabled.
+func (w *watchdog) loop(name string) *watchdogLoop {
+	if w == nil {
+		return nil
+	}
+	return w.loops[name]
+}
+
+// gossiper returns [gossiper] monitored as the loop named [name].
+func (w *watchdog) gossiper(name string, gossiper gossip.Gossiper) gossip.Gossiper {
+	if w == nil {
+		return gossiper
+	}
+	return watchedGossiper{Gossiper: gossiper, loop: w.loops[name]}
+}
+
+// stalledLoops returns how long each stalled loop has been stalled.
+func (w *watchdog) stalledLoops() map[string]time.Duration {
+	now := w.now()
+	stalled := make(map[string]time.Duration)
+	for name, loop := range w.loops {
+		if busy := loop.stalledFor(now); busy > 0 {
+			stalled[name] = busy
+		}
+	}
+	return stalled
+}
+
+// check logs the loops that stalled or recovered since the last check.
+func (w *watchdog) check() {
+	stalled := w.stalledLoops()
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	for name, busy := range stalled {
+		if w.stalled[name] {
+			continue
+		}
+		w.stalled[name] = true
+		log.Error("Loop stalled", "loop", name, "busy", busy, "threshold", w.loops[name].threshold, "goroutines", goroutineDump())
+	}
+	for name := range w.stalled {
+		if _, ok := stalled[name]; !ok {
+			delete(w.stalled, name)
+			log.Info("Loop recovered", "loop", name)
+		}
+	}
+}
+
+// health returns details on the stalled loops and an error if any loop is
+// stalled.
+func (w *watchdog) health() (map[string]string, error) {
+	stalled := w.stalledLoops()
+	if len(stalled) == 0 {
+		return nil, nil
+	}
+	details := make(map[string]string, len(stalled))
+	names := make([]string, 0, len(stalled))
+	for name, busy := range stalled {
+		details[name] = fmt.Sprintf("stalled for %s", busy)
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return details, fmt.Errorf("%w: %s", errLoopsStalled, strings.Join(names, ", "))
+}
+
+// goroutineDump returns the stack traces of all goroutines.
+func goroutineDump() string {
+	var buf bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 2); err != nil {
+		return fmt.Sprintf("failed to dump goroutines: %s", err)
+	}
+	return buf.String()
+}
+
+func (vm *VM) startWatchdog() {
+	if !vm.config.Watchdog.Enabled {
+		return
+	}
+	vm.watchdog = newWatchdog(vm.config.Watchdog)
+
+	vm.shutdownWg.Add(1)
+	go vm.ctx.Log.RecoverAndPanic(func() {
+		defer vm.shutdownWg.Done()
+
+		ticker := time.NewTicker(watchdogInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				vm.watchdog.check()
+			case <-vm.shutdownChan:
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,80 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type blockingGossiper struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (g blockingGossiper) Gossip(context.Context) error {
+	close(g.started)
+	<-g.release
+	return nil
+}
+
+func TestWatchdog(t *testing.T) {
+	require := require.New(t)
+
+	w := newWatchdog(WatchdogConfig{
+		BuildBlockThreshold:  Duration{10 * time.Second},
+		AcceptBlockThreshold: Duration{10 * time.Second},
+		GossipThreshold:      Duration{time.Minute},
+	})
+	start := time.Now()
+	w.now = func() time.Time { return start }
+
+	build := w.loop(watchdogBuildBlock)
+	build.begin()
+	details, err := w.health()
+	require.NoError(err)
+	require.Nil(details)
+
+	// The build stalls once it runs beyond its threshold.
+	w.now = func() time.Time { return start.Add(11 * time.Second) }
+	details, err = w.health()
+	require.ErrorIs(err, errLoopsStalled)
+	require.Contains(details, watchdogBuildBlock)
+	w.check()
+	require.True(w.stalled[watchdogBuildBlock])
+
+	// Gossip cycles are monitored against the gossip threshold.
+	gossiper := blockingGossiper{started: make(chan struct{}), release: make(chan struct{})}
+	done := make(chan error)
+	go func() {
+		done <- w.gossiper(watchdogEthTxPullGossip, gossiper).Gossip(context.Background())
+	}()
+	<-gossiper.started
+	w.now = func() time.Time { return start.Add(2 * time.Minute) }
+	details, err = w.health()
+	require.ErrorIs(err, errLoopsStalled)
+	require.Len(details, 2)
+
+	close(gossiper.release)
+	require.NoError(<-done)
+	build.end()
+	details, err = w.health()
+	require.NoError(err)
+	require.Nil(details)
+	w.check()
+	require.Empty(w.stalled)
+}
+
+func TestWatchdogDisabled(t *testing.T) {
+	var w *watchdog
+	loop := w.loop(watchdogAcceptBlock)
+	loop.begin()
+	loop.end()
+
+	gossiper := blockingGossiper{}
+	require.Equal(t, gossiper, w.gossiper(watchdogEthTxPushGossip, gossiper))
+}
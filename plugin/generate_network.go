@@ -0,0 +1,51 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/big"
+
+	"github.com/shubhamdubey02/coreth/plugin/evm/netgen"
+)
+
+// generateNetworkCommand is the subcommand writing the artifacts of a local
+// network, generated by [netgen.Generate].
+const generateNetworkCommand = "generate-network"
+
+// generateNetwork parses [args], the arguments of the generate-network
+// subcommand, and writes the generated network to the output directory.
+func generateNetwork(args []string) error {
+	config := netgen.DefaultConfig()
+	fs := flag.NewFlagSet(generateNetworkCommand, flag.ContinueOnError)
+	output := fs.String("output", "network", "Directory the network artifacts are written to")
+	networkID := fs.Uint("network-id", uint(config.NetworkID), "Network ID of the network")
+	chainID := fs.Uint64("chain-id", config.ChainID.Uint64(), "Chain ID of the C-Chain")
+	fs.IntVar(&config.Nodes, "nodes", config.Nodes, "Number of validators, typically 1 or 5")
+	fs.IntVar(&config.Accounts, "accounts", config.Accounts, "Number of funded accounts")
+	balance := fs.String("balance", config.Balance.String(), "C-Chain balance of each funded account, in wei")
+	fs.Uint64Var(&config.XBalance, "x-balance", config.XBalance, "X-Chain balance of each funded account, in nCRYFT")
+	fs.StringVar(&config.Seed, "seed", "", "Seed deriving the keys of the funded accounts, which are random if empty")
+	fs.StringVar(&config.Image, "image", config.Image, "Docker image running the nodes")
+	fs.StringVar(&config.Binary, "binary", config.Binary, "Path of the node binary in the docker image")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	config.NetworkID = uint32(*networkID)
+	config.ChainID = new(big.Int).SetUint64(*chainID)
+	if _, ok := config.Balance.SetString(*balance, 10); !ok {
+		return fmt.Errorf("invalid balance %q", *balance)
+	}
+	network, err := netgen.Generate(config)
+	if err != nil {
+		return err
+	}
+	if err := network.Write(*output); err != nil {
+		return err
+	}
+	fmt.Printf("wrote %d node network %d to %s\n", len(network.Nodes), config.NetworkID, *output)
+	return nil
+}
@@ -16,6 +16,13 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == generateNetworkCommand {
+		if err := generateNetwork(os.Args[2:]); err != nil {
+			fmt.Printf("failed to generate network: %s\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
 	version, err := PrintVersion()
 	if err != nil {
 		fmt.Printf("couldn't get config: %s", err)
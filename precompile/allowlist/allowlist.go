@@ -0,0 +1,239 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package allowlist implements role based allow lists stored in the state of
+// a stateful precompile. Precompiles embedding an allow list expose its
+// functions alongside their own, and may restrict their features, or the
+// admission of transactions, to the enabled addresses.
+package allowlist
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shubhamdubey02/coreth/accounts/abi"
+	"github.com/shubhamdubey02/coreth/precompile/contract"
+	"github.com/shubhamdubey02/coreth/vmerrs"
+
+	_ "embed"
+)
+
+const (
+	// ReadAllowListGasCost is the cost of reading the role of an address.
+	ReadAllowListGasCost uint64 = contract.ReadGasCostPerSlot
+	// RoleSetEventGasCost is the cost of emitting a RoleSet event, with its
+	// signature, three indexed arguments and the old role as data.
+	RoleSetEventGasCost uint64 = contract.LogGas + 4*contract.LogTopicGas + common.HashLength*contract.LogDataGas
+	// ModifyAllowListGasCost is the cost of setting the role of an address,
+	// including checking the role of the caller and emitting the event.
+	ModifyAllowListGasCost uint64 = ReadAllowListGasCost + contract.WriteGasCostPerSlot + RoleSetEventGasCost
+)
+
+var (
+	ErrCannotModifyAllowList = errors.New("non-admin cannot modify allow list")
+
+	errInvalidAddressInput = errors.New("invalid address input")
+)
+
+// Singleton StatefulPrecompiledContract and signatures.
+var (
+	// AllowListRawABI contains the raw ABI of the allow list functions and
+	// events.
+	//go:embed allowlist.abi
+	AllowListRawABI string
+
+	AllowListABI = contract.ParseABI(AllowListRawABI)
+)
+
+// GetAllowListStatus returns the role of [address] in the allow list stored
+// at [precompileAddr].
+func GetAllowListStatus(state contract.StateDB, precompileAddr common.Address, address common.Address) Role {
+	return roleFromHash(state.GetState(precompileAddr, common.BytesToHash(address.Bytes())))
+}
+
+// SetAllowListRole sets the role of [address] in the allow list stored at
+// [precompileAddr]. It does not emit an event and does not check the
+// permissions of the caller.
+func SetAllowListRole(state contract.StateDB, precompileAddr common.Address, address common.Address, role Role) {
+	state.SetState(precompileAddr, common.BytesToHash(address.Bytes()), role.Hash())
+}
+
+// PackReadAllowList packs [address] into the input of readAllowList,
+// including the selector.
+func PackReadAllowList(address common.Address) ([]byte, error) {
+	return AllowListABI.Pack("readAllowList", address)
+}
+
+// PackIsAdmin packs [address] into the input of isAdmin, including the
+// selector.
+func PackIsAdmin(address common.Address) ([]byte, error) {
+	return AllowListABI.Pack("isAdmin", address)
+}
+
+// PackIsEnabled packs [address] into the input of isEnabled, including the
+// selector.
+func PackIsEnabled(address common.Address) ([]byte, error) {
+	return AllowListABI.Pack("isEnabled", address)
+}
+
+// PackModifyAllowList packs [address] into the input of the function setting
+// its role to [role], including the selector.
+func PackModifyAllowList(address common.Address, role Role) ([]byte, error) {
+	name, ok := modifyFunctionNames[role]
+	if !ok {
+		return nil, fmt.Errorf("cannot set role %s", role)
+	}
+	return AllowListABI.Pack(name, address)
+}
+
+// UnpackReadAllowListOutput unpacks the role returned by readAllowList.
+func UnpackReadAllowListOutput(output []byte) (Role, error) {
+	res, err := AllowListABI.Unpack("readAllowList", output)
+	if err != nil {
+		return NoRole, err
+	}
+	return roleFromHash(common.BigToHash(res[0].(*big.Int))), nil
+}
+
+// UnpackIsAdminOutput unpacks the boolean returned by isAdmin.
+func UnpackIsAdminOutput(output []byte) (bool, error) {
+	return unpackBoolOutput("isAdmin", output)
+}
+
+// UnpackIsEnabledOutput unpacks the boolean returned by isEnabled.
+func UnpackIsEnabledOutput(output []byte) (bool, error) {
+	return unpackBoolOutput("isEnabled", output)
+}
+
+func unpackBoolOutput(function string, output []byte) (bool, error) {
+	res, err := AllowListABI.Unpack(function, output)
+	if err != nil {
+		return false, err
+	}
+	return *abi.ConvertType(res[0], new(bool)).(*bool), nil
+}
+
+// PackRoleSetEvent packs the topics and data of a RoleSet event emitted when
+// [sender] changes the role of [account] from [oldRole] to [role].
+func PackRoleSetEvent(role Role, account common.Address, sender common.Address, oldRole Role) ([]common.Hash, []byte, error) {
+	return AllowListABI.PackEvent("RoleSet", role.Big(), account, sender, oldRole.Big())
+}
+
+// modifyFunctionNames maps each role to the function setting it.
+var modifyFunctionNames = map[Role]string{
+	NoRole:      "setNone",
+	EnabledRole: "setEnabled",
+	AdminRole:   "setAdmin",
+}
+
+// unpackAddressInput unpacks the single address argument of [function].
+func unpackAddressInput(function string, input []byte) (common.Address, error) {
+	// Strict mode is not used since the allow list is activated after
+	// Durango, which disabled it.
+	res, err := AllowListABI.UnpackInput(function, input, false)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("%w: %w", errInvalidAddressInput, err)
+	}
+	return *abi.ConvertType(res[0], new(common.Address)).(*common.Address), nil
+}
+
+// createReadAllowList returns the readAllowList function of the allow list
+// stored at [precompileAddr].
+func createReadAllowList(precompileAddr common.Address) contract.RunStatefulPrecompileFunc {
+	return func(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+		if remainingGas, err = contract.DeductGas(suppliedGas, ReadAllowListGasCost); err != nil {
+			return nil, 0, err
+		}
+		address, err := unpackAddressInput("readAllowList", input)
+		if err != nil {
+			return nil, remainingGas, err
+		}
+		role := GetAllowListStatus(accessibleState.GetStateDB(), precompileAddr, address)
+		packed, err := AllowListABI.PackOutput("readAllowList", role.Big())
+		if err != nil {
+			return nil, remainingGas, err
+		}
+		return packed, remainingGas, nil
+	}
+}
+
+// createHasRole returns the [function] of the allow list stored at
+// [precompileAddr], returning whether an address has a role satisfying
+// [check].
+func createHasRole(precompileAddr common.Address, function string, check func(Role) bool) contract.RunStatefulPrecompileFunc {
+	return func(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+		if remainingGas, err = contract.DeductGas(suppliedGas, ReadAllowListGasCost); err != nil {
+			return nil, 0, err
+		}
+		address, err := unpackAddressInput(function, input)
+		if err != nil {
+			return nil, remainingGas, err
+		}
+		role := GetAllowListStatus(accessibleState.GetStateDB(), precompileAddr, address)
+		packed, err := AllowListABI.PackOutput(function, check(role))
+		if err != nil {
+			return nil, remainingGas, err
+		}
+		return packed, remainingGas, nil
+	}
+}
+
+// createModifyAllowList returns the function of the allow list stored at
+// [precompileAddr] setting the role of an address to [role]. Only admins may
+// call it.
+func createModifyAllowList(precompileAddr common.Address, role Role) contract.RunStatefulPrecompileFunc {
+	function := modifyFunctionNames[role]
+	return func(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+		if remainingGas, err = contract.DeductGas(suppliedGas, ModifyAllowListGasCost); err != nil {
+			return nil, 0, err
+		}
+		if readOnly {
+			return nil, remainingGas, vmerrs.ErrWriteProtection
+		}
+		address, err := unpackAddressInput(function, input)
+		if err != nil {
+			return nil, remainingGas, err
+		}
+
+		stateDB := accessibleState.GetStateDB()
+		if !GetAllowListStatus(stateDB, precompileAddr, caller).IsAdmin() {
+			return nil, remainingGas, fmt.Errorf("%w: %s", ErrCannotModifyAllowList, caller)
+		}
+		oldRole := GetAllowListStatus(stateDB, precompileAddr, address)
+		SetAllowListRole(stateDB, precompileAddr, address, role)
+
+		topics, data, err := PackRoleSetEvent(role, address, caller, oldRole)
+		if err != nil {
+			return nil, remainingGas, err
+		}
+		stateDB.AddLog(precompileAddr, topics, data, accessibleState.GetBlockContext().Number().Uint64())
+		return []byte{}, remainingGas, nil
+	}
+}
+
+// CreateAllowListFunctions returns the functions managing the allow list
+// stored at [precompileAddr], to be exposed by the precompile at that
+// address.
+func CreateAllowListFunctions(precompileAddr common.Address) []*contract.StatefulPrecompileFunction {
+	functions := []*contract.StatefulPrecompileFunction{
+		contract.NewStatefulPrecompileFunction(AllowListABI.Methods["readAllowList"].ID, createReadAllowList(precompileAddr)),
+		contract.NewStatefulPrecompileFunction(AllowListABI.Methods["isAdmin"].ID, createHasRole(precompileAddr, "isAdmin", Role.IsAdmin)),
+		contract.NewStatefulPrecompileFunction(AllowListABI.Methods["isEnabled"].ID, createHasRole(precompileAddr, "isEnabled", Role.IsEnabled)),
+	}
+	for _, role := range []Role{NoRole, EnabledRole, AdminRole} {
+		functions = append(functions, contract.NewStatefulPrecompileFunction(AllowListABI.Methods[modifyFunctionNames[role]].ID, createModifyAllowList(precompileAddr, role)))
+	}
+	return functions
+}
+
+// CreateAllowListPrecompile returns a precompile exposing only the functions
+// managing the allow list stored at [precompileAddr].
+func CreateAllowListPrecompile(precompileAddr common.Address) contract.StatefulPrecompiledContract {
+	precompile, err := contract.NewStatefulPrecompileContract(nil, CreateAllowListFunctions(precompileAddr))
+	if err != nil {
+		panic(err)
+	}
+	return precompile
+}
@@ -0,0 +1,65 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package allowlist
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shubhamdubey02/coreth/precompile/contract"
+)
+
+// AllowListConfig is the initial allow list of a precompile, embedded in its
+// config.
+type AllowListConfig struct {
+	AdminAddresses   []common.Address `json:"adminAddresses,omitempty"`
+	EnabledAddresses []common.Address `json:"enabledAddresses,omitempty"`
+}
+
+// Configure sets the roles of the configured addresses in the allow list
+// stored at [precompileAddr].
+func (c *AllowListConfig) Configure(state contract.StateDB, precompileAddr common.Address) error {
+	for _, address := range c.EnabledAddresses {
+		SetAllowListRole(state, precompileAddr, address, EnabledRole)
+	}
+	for _, address := range c.AdminAddresses {
+		SetAllowListRole(state, precompileAddr, address, AdminRole)
+	}
+	return nil
+}
+
+// Verify returns an error if an address is configured more than once.
+func (c *AllowListConfig) Verify() error {
+	seen := make(map[common.Address]struct{}, len(c.AdminAddresses)+len(c.EnabledAddresses))
+	for _, addresses := range [][]common.Address{c.AdminAddresses, c.EnabledAddresses} {
+		for _, address := range addresses {
+			if _, ok := seen[address]; ok {
+				return fmt.Errorf("address %s is configured more than once", address)
+			}
+			seen[address] = struct{}{}
+		}
+	}
+	return nil
+}
+
+// Equal returns true if [other] configures the same addresses in the same
+// order.
+func (c *AllowListConfig) Equal(other *AllowListConfig) bool {
+	if other == nil {
+		return false
+	}
+	return addressesEqual(c.AdminAddresses, other.AdminAddresses) && addressesEqual(c.EnabledAddresses, other.EnabledAddresses)
+}
+
+func addressesEqual(a, b []common.Address) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
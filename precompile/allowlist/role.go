@@ -0,0 +1,76 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package allowlist
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Role is the role of an address in an allow list.
+type Role uint64
+
+const (
+	// NoRole is the role of addresses that are not in the allow list.
+	NoRole Role = iota
+	// EnabledRole addresses may use the features guarded by the allow list.
+	EnabledRole
+	// AdminRole addresses may use the guarded features and modify the roles
+	// of any address, including other admins.
+	AdminRole
+)
+
+// IsEnabled returns true if [r] may use the features guarded by the allow
+// list.
+func (r Role) IsEnabled() bool {
+	return r == EnabledRole || r == AdminRole
+}
+
+// IsAdmin returns true if [r] may modify the allow list.
+func (r Role) IsAdmin() bool {
+	return r == AdminRole
+}
+
+// Valid returns true if [r] is a known role.
+func (r Role) Valid() bool {
+	return r <= AdminRole
+}
+
+// Big returns the ABI representation of [r].
+func (r Role) Big() *big.Int {
+	return new(big.Int).SetUint64(uint64(r))
+}
+
+// Hash returns the storage representation of [r].
+func (r Role) Hash() common.Hash {
+	return common.BigToHash(r.Big())
+}
+
+// roleFromHash returns the role stored as [h]. Unknown values are read as
+// [NoRole], so that corrupt storage never grants permissions.
+func roleFromHash(h common.Hash) Role {
+	v := h.Big()
+	if !v.IsUint64() {
+		return NoRole
+	}
+	if r := Role(v.Uint64()); r.Valid() {
+		return r
+	}
+	return NoRole
+}
+
+func (r Role) String() string {
+	switch r {
+	case NoRole:
+		return "None"
+	case EnabledRole:
+		return "Enabled"
+	case AdminRole:
+		return "Admin"
+	default:
+		return fmt.Sprintf("Unknown(%d)", uint64(r))
+	}
+}
@@ -0,0 +1,61 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txallowlist
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shubhamdubey02/coreth/precompile/allowlist"
+	"github.com/shubhamdubey02/coreth/precompile/precompileconfig"
+)
+
+var _ precompileconfig.Config = &Config{}
+
+// Config implements the precompileconfig.Config interface and
+// adds the initial allow list of transaction senders.
+type Config struct {
+	allowlist.AllowListConfig
+	precompileconfig.Upgrade
+}
+
+// NewConfig returns a config for a network upgrade at [blockTimestamp] that enables
+// the tx allow list with the given [admins] and [enableds].
+func NewConfig(blockTimestamp *uint64, admins []common.Address, enableds []common.Address) *Config {
+	return &Config{
+		AllowListConfig: allowlist.AllowListConfig{
+			AdminAddresses:   admins,
+			EnabledAddresses: enableds,
+		},
+		Upgrade: precompileconfig.Upgrade{BlockTimestamp: blockTimestamp},
+	}
+}
+
+// NewDisableConfig returns config for a network upgrade at [blockTimestamp]
+// that disables the tx allow list.
+func NewDisableConfig(blockTimestamp *uint64) *Config {
+	return &Config{
+		Upgrade: precompileconfig.Upgrade{
+			BlockTimestamp: blockTimestamp,
+			Disable:        true,
+		},
+	}
+}
+
+// Key returns the key for the tx allow list precompileconfig.
+// This should be the same key as used in the precompile module.
+func (*Config) Key() string { return ConfigKey }
+
+// Verify tries to verify Config and returns an error accordingly.
+func (c *Config) Verify(chainConfig precompileconfig.ChainConfig) error {
+	return c.AllowListConfig.Verify()
+}
+
+// Equal returns true if [s] is a [*Config] and it has been configured identical to [c].
+func (c *Config) Equal(s precompileconfig.Config) bool {
+	// typecast before comparison
+	other, ok := (s).(*Config)
+	if !ok {
+		return false
+	}
+	return c.Upgrade.Equal(&other.Upgrade) && c.AllowListConfig.Equal(&other.AllowListConfig)
+}
@@ -0,0 +1,92 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txallowlist_test
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shubhamdubey02/coreth/params"
+	"github.com/shubhamdubey02/coreth/precompile/allowlist"
+	"github.com/shubhamdubey02/coreth/precompile/contracts/txallowlist"
+	"github.com/shubhamdubey02/coreth/precompile/precompiletest"
+	"github.com/shubhamdubey02/coreth/utils"
+	"github.com/shubhamdubey02/coreth/vmerrs"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	admin   = common.Address{0xad}
+	enabled = common.Address{0xe0}
+	other   = common.Address{0x01}
+)
+
+func TestTxAllowList(t *testing.T) {
+	require := require.New(t)
+
+	env := precompiletest.NewEnv(t,
+		params.PrecompileUpgrade{Config: txallowlist.NewConfig(utils.NewUint64(10), []common.Address{admin}, []common.Address{enabled})},
+		params.PrecompileUpgrade{Config: txallowlist.NewDisableConfig(utils.NewUint64(20))},
+	)
+	env.RequireDisabled(txallowlist.Module)
+
+	require.NoError(env.AdvanceTo(10))
+	env.RequireEnabled(txallowlist.Module)
+	for address, role := range map[common.Address]allowlist.Role{admin: allowlist.AdminRole, enabled: allowlist.EnabledRole, other: allowlist.NoRole} {
+		require.Equal(role, txallowlist.GetTxAllowListStatus(env.StateDB, address))
+
+		input, err := allowlist.PackReadAllowList(address)
+		require.NoError(err)
+		res := env.StaticCall(other, txallowlist.ContractAddress, input, allowlist.ReadAllowListGasCost)
+		require.NoError(res.Err)
+		got, err := allowlist.UnpackReadAllowListOutput(res.Ret)
+		require.NoError(err)
+		require.Equal(role, got)
+
+		input, err = allowlist.PackIsEnabled(address)
+		require.NoError(err)
+		res = env.StaticCall(other, txallowlist.ContractAddress, input, allowlist.ReadAllowListGasCost)
+		require.NoError(res.Err)
+		isEnabled, err := allowlist.UnpackIsEnabledOutput(res.Ret)
+		require.NoError(err)
+		require.Equal(role.IsEnabled(), isEnabled)
+	}
+
+	// Only admins may modify the allow list, outside of static calls.
+	input, err := allowlist.PackModifyAllowList(other, allowlist.EnabledRole)
+	require.NoError(err)
+	res := env.Call(enabled, txallowlist.ContractAddress, input, allowlist.ModifyAllowListGasCost)
+	require.ErrorIs(res.Err, allowlist.ErrCannotModifyAllowList)
+	res = env.StaticCall(admin, txallowlist.ContractAddress, input, allowlist.ModifyAllowListGasCost)
+	require.ErrorIs(res.Err, vmerrs.ErrWriteProtection)
+	require.Equal(allowlist.NoRole, txallowlist.GetTxAllowListStatus(env.StateDB, other))
+
+	res = env.Call(admin, txallowlist.ContractAddress, input, allowlist.ModifyAllowListGasCost)
+	require.NoError(res.Err)
+	res.RequireGasUsed(t, allowlist.ModifyAllowListGasCost)
+	require.Equal(allowlist.EnabledRole, txallowlist.GetTxAllowListStatus(env.StateDB, other))
+
+	topics, data := env.StateDB.GetLogData()
+	require.Len(topics, 1)
+	expectedTopics, expectedData, err := allowlist.PackRoleSetEvent(allowlist.EnabledRole, other, admin, allowlist.NoRole)
+	require.NoError(err)
+	require.Equal(expectedTopics, topics[0])
+	require.Equal(expectedData, data[0])
+
+	require.NoError(env.AdvanceTo(20))
+	env.RequireDisabled(txallowlist.Module)
+	require.Equal(allowlist.NoRole, txallowlist.GetTxAllowListStatus(env.StateDB, admin))
+}
+
+func TestVerifyTxAllowListConfig(t *testing.T) {
+	require := require.New(t)
+
+	config := txallowlist.NewConfig(utils.NewUint64(10), []common.Address{admin}, []common.Address{enabled})
+	require.NoError(config.Verify(params.TestChainConfig))
+	require.True(config.Equal(txallowlist.NewConfig(utils.NewUint64(10), []common.Address{admin}, []common.Address{enabled})))
+	require.False(config.Equal(txallowlist.NewConfig(utils.NewUint64(10), []common.Address{admin}, nil)))
+
+	config = txallowlist.NewConfig(utils.NewUint64(10), []common.Address{admin}, []common.Address{admin})
+	require.ErrorContains(config.Verify(params.TestChainConfig), "configured more than once")
+}
@@ -0,0 +1,67 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txallowlist
+
+import (
+	"fmt"
+
+	"github.com/shubhamdubey02/coreth/precompile/allowlist"
+	"github.com/shubhamdubey02/coreth/precompile/contract"
+	"github.com/shubhamdubey02/coreth/precompile/modules"
+	"github.com/shubhamdubey02/coreth/precompile/precompileconfig"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var _ contract.Configurator = &configurator{}
+
+// ConfigKey is the key used in json config files to specify this precompile config.
+// must be unique across all precompiles.
+const ConfigKey = "txAllowListConfig"
+
+// ContractAddress is the address of the tx allow list precompile contract
+var ContractAddress = common.HexToAddress("0x0200000000000000000000000000000000000002")
+
+// TxAllowListPrecompile exposes the functions managing the tx allow list.
+var TxAllowListPrecompile = allowlist.CreateAllowListPrecompile(ContractAddress)
+
+// Module is the precompile module. It is used to register the precompile contract.
+var Module = modules.Module{
+	ConfigKey:    ConfigKey,
+	Address:      ContractAddress,
+	Contract:     TxAllowListPrecompile,
+	Configurator: &configurator{},
+}
+
+type configurator struct{}
+
+func init() {
+	// Register the precompile module.
+	// Each precompile contract registers itself through [RegisterModule] function.
+	if err := modules.RegisterModule(Module); err != nil {
+		panic(err)
+	}
+}
+
+// MakeConfig returns a new precompile config instance.
+// This is required to Marshal/Unmarshal the precompile config.
+func (*configurator) MakeConfig() precompileconfig.Config {
+	return new(Config)
+}
+
+// Configure stores the initial allow list in the state of the precompile.
+func (*configurator) Configure(chainConfig precompileconfig.ChainConfig, cfg precompileconfig.Config, state contract.StateDB, _ contract.ConfigurationBlockContext) error {
+	config, ok := cfg.(*Config)
+	if !ok {
+		return fmt.Errorf("expected config type %T, got %T: %v", &Config{}, cfg, cfg)
+	}
+	return config.AllowListConfig.Configure(state, ContractAddress)
+}
+
+// GetTxAllowListStatus returns the role of [address] in the tx allow list.
+// Only enabled addresses may issue transactions while the precompile is
+// enabled.
+func GetTxAllowListStatus(state contract.StateDB, address common.Address) allowlist.Role {
+	return allowlist.GetAllowListStatus(state, ContractAddress, address)
+}
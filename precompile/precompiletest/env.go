@@ -0,0 +1,193 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package precompiletest runs stateful precompiles through network upgrade
+// activation, predicate verification and execution against a real StateDB and
+// EVM, so that authors of custom precompiles can test them the way the chain
+// will run them.
+package precompiletest
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shubhamdubey02/coreth/core"
+	"github.com/shubhamdubey02/coreth/core/rawdb"
+	"github.com/shubhamdubey02/coreth/core/state"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/core/vm"
+	"github.com/shubhamdubey02/coreth/params"
+	"github.com/shubhamdubey02/coreth/precompile/modules"
+	"github.com/shubhamdubey02/coreth/precompile/precompileconfig"
+	"github.com/shubhamdubey02/coreth/predicate"
+	"github.com/stretchr/testify/require"
+)
+
+// Env is a chain at a given block, holding the StateDB that precompiles are
+// activated and called against. Precompiles must be registered with
+// [modules.RegisterModule] to be activated.
+type Env struct {
+	t testing.TB
+
+	ChainConfig *params.ChainConfig
+	StateDB     *state.StateDB
+	// Number and Time are the number and timestamp of the current block.
+	Number *big.Int
+	Time   uint64
+
+	predicateResults *predicate.Results
+}
+
+// NewEnv returns an Env at the genesis of a chain configured by
+// [params.TestChainConfig] with [upgrades] scheduled. Upgrades scheduled at
+// timestamp 0 are activated immediately.
+func NewEnv(t testing.TB, upgrades ...params.PrecompileUpgrade) *Env {
+	chainConfig := *params.TestChainConfig
+	chainConfig.UpgradeConfig = params.UpgradeConfig{PrecompileUpgrades: upgrades}
+	require.NoError(t, chainConfig.Verify())
+
+	statedb, err := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(t, err)
+	e := &Env{
+		t:                t,
+		ChainConfig:      &chainConfig,
+		StateDB:          statedb,
+		Number:           big.NewInt(0),
+		predicateResults: predicate.NewResults(),
+	}
+	require.NoError(t, core.ApplyPrecompileActivations(e.ChainConfig, nil, e.blockContext(), e.StateDB))
+	return e
+}
+
+// Rules returns the rules of the current block.
+func (e *Env) Rules() params.Rules {
+	return e.ChainConfig.Rules(e.Number, e.Time)
+}
+
+// AdvanceTo moves the chain to a new block with [timestamp], applying the
+// precompile upgrades activated by the transition as block processing would.
+// The error returned by the activation is returned.
+func (e *Env) AdvanceTo(timestamp uint64) error {
+	parentTime := e.Time
+	e.Number = new(big.Int).Add(e.Number, common.Big1)
+	e.Time = timestamp
+	e.predicateResults = predicate.NewResults()
+	return core.ApplyUpgrades(e.ChainConfig, &parentTime, e.blockContext(), e.StateDB)
+}
+
+// IsEnabled returns whether the precompile at [address] is enabled in the
+// current block.
+func (e *Env) IsEnabled(address common.Address) bool {
+	return e.ChainConfig.IsPrecompileEnabled(address, e.Time)
+}
+
+// RequireEnabled asserts that [module] is enabled in the current block and was
+// deployed to the StateDB on activation.
+func (e *Env) RequireEnabled(module modules.Module) {
+	e.t.Helper()
+	require.True(e.t, e.IsEnabled(module.Address), "precompile %s is not enabled", module.ConfigKey)
+	require.Equal(e.t, uint64(1), e.StateDB.GetNonce(module.Address))
+	require.NotEmpty(e.t, e.StateDB.GetCode(module.Address))
+}
+
+// RequireDisabled asserts that [module] is not enabled in the current block
+// and holds no state.
+func (e *Env) RequireDisabled(module modules.Module) {
+	e.t.Helper()
+	require.False(e.t, e.IsEnabled(module.Address), "precompile %s is enabled", module.ConfigKey)
+	require.Zero(e.t, e.StateDB.GetNonce(module.Address))
+	require.Empty(e.t, e.StateDB.GetCode(module.Address))
+}
+
+// CallResult is the outcome of calling a precompile.
+type CallResult struct {
+	Ret     []byte
+	GasUsed uint64
+	Err     error
+}
+
+// RequireGasUsed asserts that the call consumed exactly [gas].
+func (r *CallResult) RequireGasUsed(t testing.TB, gas uint64) {
+	t.Helper()
+	require.Equal(t, gas, r.GasUsed, "unexpected gas used")
+}
+
+// Call calls [address] from [caller] with [input] and [gas] in the current
+// block, as a contract or transaction without predicates would.
+func (e *Env) Call(caller common.Address, address common.Address, input []byte, gas uint64) *CallResult {
+	e.StateDB.SetTxContext(common.Hash{}, 0)
+	e.StateDB.Prepare(e.Rules(), caller, common.Address{}, &address, vm.ActivePrecompiles(e.Rules()), nil)
+	return e.call(caller, address, input, gas, false)
+}
+
+// StaticCall is like [Env.Call] but in read only mode.
+func (e *Env) StaticCall(caller common.Address, address common.Address, input []byte, gas uint64) *CallResult {
+	e.StateDB.SetTxContext(common.Hash{}, 0)
+	e.StateDB.Prepare(e.Rules(), caller, common.Address{}, &address, vm.ActivePrecompiles(e.Rules()), nil)
+	return e.call(caller, address, input, gas, true)
+}
+
+// CallTx verifies the predicates in the access list of the signed [tx] within
+// [predicateContext], as block verification would, and then executes its call
+// against the current block. The returned gas excludes intrinsic gas, which
+// includes the gas charged for predicates.
+func (e *Env) CallTx(tx *types.Transaction, predicateContext *precompileconfig.PredicateContext) *CallResult {
+	e.t.Helper()
+	require := require.New(e.t)
+	require.NotNil(tx.To(), "contract creation is not supported")
+
+	rules := e.Rules()
+	results, err := core.CheckPredicates(rules, predicateContext, tx)
+	require.NoError(err)
+	e.predicateResults.SetTxResults(tx.Hash(), results)
+
+	from, err := types.Sender(types.LatestSigner(e.ChainConfig), tx)
+	require.NoError(err)
+	intrinsicGas, err := core.IntrinsicGas(tx.Data(), tx.AccessList(), false, rules)
+	require.NoError(err)
+	require.GreaterOrEqual(tx.Gas(), intrinsicGas)
+
+	e.StateDB.SetTxContext(tx.Hash(), 0)
+	e.StateDB.Prepare(rules, from, common.Address{}, tx.To(), vm.ActivePrecompiles(rules), tx.AccessList())
+	return e.call(from, *tx.To(), tx.Data(), tx.Gas()-intrinsicGas, false)
+}
+
+// PredicateResults returns the results of the predicates of [txHash] for the
+// precompile at [address], as verified by [Env.CallTx] in the current block.
+func (e *Env) PredicateResults(txHash common.Hash, address common.Address) []byte {
+	return e.predicateResults.GetResults(txHash, address)
+}
+
+func (e *Env) call(caller common.Address, address common.Address, input []byte, gas uint64, readOnly bool) *CallResult {
+	blockContext := e.blockContext()
+	evm := vm.NewEVM(*blockContext, vm.TxContext{Origin: caller, GasPrice: common.Big0}, e.StateDB, e.ChainConfig, vm.Config{})
+	var (
+		ret       []byte
+		remaining uint64
+		err       error
+	)
+	if readOnly {
+		ret, remaining, err = evm.StaticCall(vm.AccountRef(caller), address, input, gas)
+	} else {
+		ret, remaining, err = evm.Call(vm.AccountRef(caller), address, input, gas, common.Big0)
+	}
+	e.StateDB.Finalise(true)
+	return &CallResult{Ret: ret, GasUsed: gas - remaining, Err: err}
+}
+
+func (e *Env) blockContext() *vm.BlockContext {
+	return &vm.BlockContext{
+		CanTransfer:       core.CanTransfer,
+		CanTransferMC:     core.CanTransferMC,
+		Transfer:          core.Transfer,
+		TransferMultiCoin: core.TransferMultiCoin,
+		GetHash:           func(uint64) common.Hash { return common.Hash{} },
+		PredicateResults:  e.predicateResults,
+		BlockNumber:       e.Number,
+		Time:              e.Time,
+		Difficulty:        common.Big0,
+		BaseFee:           big.NewInt(params.ApricotPhase3InitialBaseFee),
+		GasLimit:          params.CortinaGasLimit,
+	}
+}
@@ -0,0 +1,66 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package precompiletest_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/params"
+	"github.com/shubhamdubey02/coreth/precompile/contracts/warp"
+	"github.com/shubhamdubey02/coreth/precompile/precompileconfig"
+	"github.com/shubhamdubey02/coreth/precompile/precompiletest"
+	"github.com/shubhamdubey02/coreth/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvActivationAndCalls(t *testing.T) {
+	require := require.New(t)
+
+	env := precompiletest.NewEnv(t,
+		params.PrecompileUpgrade{Config: warp.NewDefaultConfig(utils.NewUint64(10))},
+		params.PrecompileUpgrade{Config: warp.NewDisableConfig(utils.NewUint64(20))},
+	)
+	env.RequireDisabled(warp.Module)
+
+	require.NoError(env.AdvanceTo(10))
+	env.RequireEnabled(warp.Module)
+
+	input, err := warp.PackGetBlockchainID()
+	require.NoError(err)
+	res := env.StaticCall(common.Address{1}, warp.ContractAddress, input, 100)
+	require.NoError(res.Err)
+	res.RequireGasUsed(t, warp.GetBlockchainIDGasCost)
+	expected, err := warp.PackGetBlockchainIDOutput(common.Hash(env.ChainConfig.AvalancheContext.SnowCtx.ChainID))
+	require.NoError(err)
+	require.Equal(expected, res.Ret)
+
+	// A transaction without a warp predicate has no verified message.
+	key, err := crypto.GenerateKey()
+	require.NoError(err)
+	input, err = warp.PackGetVerifiedWarpMessage(0)
+	require.NoError(err)
+	tx, err := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+		ChainID:   env.ChainConfig.ChainID,
+		To:        &warp.ContractAddress,
+		Gas:       100_000,
+		GasFeeCap: big.NewInt(params.LaunchMinGasPrice),
+		Data:      input,
+	}), types.LatestSigner(env.ChainConfig), key)
+	require.NoError(err)
+	res = env.CallTx(tx, &precompileconfig.PredicateContext{SnowCtx: env.ChainConfig.AvalancheContext.SnowCtx})
+	require.NoError(res.Err)
+	res.RequireGasUsed(t, warp.GetVerifiedWarpMessageBaseCost)
+	output, err := warp.UnpackGetVerifiedWarpMessageOutput(res.Ret)
+	require.NoError(err)
+	require.False(output.Valid)
+	require.Empty(env.PredicateResults(tx.Hash(), warp.ContractAddress))
+
+	require.NoError(env.AdvanceTo(20))
+	env.RequireDisabled(warp.Module)
+	require.False(env.IsEnabled(warp.ContractAddress))
+}
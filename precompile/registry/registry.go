@@ -7,5 +7,6 @@ package registry
 // Force imports of each precompile to ensure each precompile's init function runs and registers itself
 // with the registry.
 import (
+	_ "github.com/shubhamdubey02/coreth/precompile/contracts/txallowlist"
 	_ "github.com/shubhamdubey02/coreth/precompile/contracts/warp"
 )
@@ -17,6 +17,13 @@ import (
 const (
 	Version        = uint16(0)
 	MaxResultsSize = units.MiB
+
+	// Sizes of the components of the marshalled [Results], used to account
+	// for the header Extra space consumed by predicate results.
+	versionSize      = wrappers.ShortLen
+	mapLenSize       = wrappers.IntLen
+	bytesLenSize     = wrappers.IntLen
+	emptyResultsSize = versionSize + mapLenSize
 )
 
 var Codec codec.Manager
@@ -94,6 +101,35 @@ func (r *Results) DeleteTxResults(txHash common.Hash) {
 	delete(r.Results, txHash)
 }
 
+// TxResultsSize returns the number of bytes [txResults] adds to the marshalled
+// predicate results when set for a transaction with [SetTxResults].
+func TxResultsSize(txResults TxResults) int {
+	// Empty results are not stored by [SetTxResults]
+	if len(txResults) == 0 {
+		return 0
+	}
+	return txResultsSize(txResults)
+}
+
+// txResultsSize returns the marshalled size of the entry for [txResults].
+func txResultsSize(txResults TxResults) int {
+	size := common.HashLength + mapLenSize
+	for _, result := range txResults {
+		size += common.AddressLength + bytesLenSize + len(result)
+	}
+	return size
+}
+
+// Size returns the length of the marshalled predicate results without
+// marshalling them.
+func (r *Results) Size() int {
+	size := emptyResultsSize
+	for _, txResults := range r.Results {
+		size += txResultsSize(txResults)
+	}
+	return size
+}
+
 // Bytes marshals the current state of predicate results
 func (r *Results) Bytes() ([]byte, error) {
 	return Codec.Marshal(Version, r)
@@ -98,6 +98,7 @@ func TestPredicateResultsParsing(t *testing.T) {
 			require.NoError(err)
 			require.Equal(predicateResults, parsedPredicateResults)
 			require.Equal(test.expectedHex, common.Bytes2Hex(b))
+			require.Len(b, predicateResults.Size())
 		})
 	}
 }
@@ -0,0 +1,170 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpc
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+)
+
+// wildcardNamespace is the key of the [AccessPolicy] entry applied to
+// namespaces without an entry of their own.
+const wildcardNamespace = "*"
+
+// NamespaceAccess restricts the clients that may call the methods of a
+// namespace. Empty lists impose no restriction.
+type NamespaceAccess struct {
+	// AllowedIPs and DeniedIPs are IP addresses or CIDR ranges matched against
+	// the remote address of the connection. Denied addresses take precedence.
+	AllowedIPs []string `json:"allowedIPs"`
+	DeniedIPs  []string `json:"deniedIPs"`
+	// AllowedOrigins are the browser origins allowed to call the namespace.
+	// Requests without an Origin header are not restricted.
+	AllowedOrigins []string `json:"allowedOrigins"`
+	// AllowedHosts are the virtual hostnames the namespace is served on.
+	AllowedHosts []string `json:"allowedHosts"`
+}
+
+// AccessPolicy maps namespaces to their access restrictions. The entry for
+// namespace "*" applies to namespaces without an entry of their own.
+type AccessPolicy map[string]NamespaceAccess
+
+type namespaceAccess struct {
+	allowedIPs     []netip.Prefix
+	deniedIPs      []netip.Prefix
+	allowedOrigins map[string]struct{}
+	allowedHosts   map[string]struct{}
+}
+
+// accessPolicy is a parsed [AccessPolicy].
+type accessPolicy map[string]*namespaceAccess
+
+// Verify returns an error if [p] contains an invalid IP address or range.
+func (p AccessPolicy) Verify() error {
+	_, err := p.parse()
+	return err
+}
+
+func (p AccessPolicy) parse() (accessPolicy, error) {
+	if len(p) == 0 {
+		return nil, nil
+	}
+	parsed := make(accessPolicy, len(p))
+	for namespace, access := range p {
+		allowed, err := parsePrefixes(access.AllowedIPs)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed IPs for namespace %q: %w", namespace, err)
+		}
+		denied, err := parsePrefixes(access.DeniedIPs)
+		if err != nil {
+			return nil, fmt.Errorf("invalid denied IPs for namespace %q: %w", namespace, err)
+		}
+		parsed[namespace] = &namespaceAccess{
+			allowedIPs:     allowed,
+			deniedIPs:      denied,
+			allowedOrigins: lowerSet(access.AllowedOrigins),
+			allowedHosts:   lowerSet(access.AllowedHosts),
+		}
+	}
+	return parsed, nil
+}
+
+// check returns an error if [peer] may not call methods of [namespace].
+func (p accessPolicy) check(namespace string, peer PeerInfo) error {
+	access, ok := p[namespace]
+	if !ok {
+		access, ok = p[wildcardNamespace]
+	}
+	if !ok {
+		return nil
+	}
+
+	if len(access.allowedIPs) != 0 || len(access.deniedIPs) != 0 {
+		addr, ok := remoteIP(peer.RemoteAddr)
+		if !ok || containsAddr(access.deniedIPs, addr) {
+			return &accessDeniedError{namespace: namespace}
+		}
+		if len(access.allowedIPs) != 0 && !containsAddr(access.allowedIPs, addr) {
+			return &accessDeniedError{namespace: namespace}
+		}
+	}
+	if origin := peer.HTTP.Origin; origin != "" && !inSet(access.allowedOrigins, origin) {
+		return &accessDeniedError{namespace: namespace}
+	}
+	if host := peer.HTTP.Host; host != "" {
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if !inSet(access.allowedHosts, host) {
+			return &accessDeniedError{namespace: namespace}
+		}
+	}
+	return nil
+}
+
+func parsePrefixes(entries []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(entries))
+	for _, entry := range entries {
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, err
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+	}
+	return prefixes, nil
+}
+
+func remoteIP(remoteAddr string) (netip.Addr, bool) {
+	if addrPort, err := netip.ParseAddrPort(remoteAddr); err == nil {
+		return addrPort.Addr().Unmap(), true
+	}
+	if addr, err := netip.ParseAddr(remoteAddr); err == nil {
+		return addr.Unmap(), true
+	}
+	return netip.Addr{}, false
+}
+
+func containsAddr(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// lowerSet returns the lowercased [entries] as a set, or nil if [entries] is
+// empty or contains the wildcard "*".
+func lowerSet(entries []string) map[string]struct{} {
+	if len(entries) == 0 {
+		return nil
+	}
+	set := make(map[string]struct{}, len(entries))
+	for _, entry := range entries {
+		if entry == "*" {
+			return nil
+		}
+		set[strings.ToLower(entry)] = struct{}{}
+	}
+	return set
+}
+
+// inSet returns true if [set] is unrestricted or contains [value].
+func inSet(set map[string]struct{}, value string) bool {
+	if set == nil {
+		return true
+	}
+	_, ok := set[strings.ToLower(value)]
+	return ok
+}
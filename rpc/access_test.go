@@ -0,0 +1,92 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAccessPolicyCheck(t *testing.T) {
+	policy, err := AccessPolicy{
+		"*": {
+			AllowedHosts: []string{"localhost"},
+		},
+		"debug": {
+			AllowedIPs: []string{"10.0.0.0/8", "127.0.0.1"},
+			DeniedIPs:  []string{"10.1.0.0/16"},
+		},
+		"eth": {
+			AllowedOrigins: []string{"https://wallet.example"},
+		},
+	}.parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	peer := func(addr, origin, host string) PeerInfo {
+		info := PeerInfo{Transport: "http", RemoteAddr: addr}
+		info.HTTP.Origin = origin
+		info.HTTP.Host = host
+		return info
+	}
+	tests := []struct {
+		namespace string
+		peer      PeerInfo
+		allowed   bool
+	}{
+		{"debug", peer("127.0.0.1:1234", "", ""), true},
+		{"debug", peer("10.2.3.4:1234", "", ""), true},
+		{"debug", peer("10.1.3.4:1234", "", ""), false},
+		{"debug", peer("192.168.0.1:1234", "", ""), false},
+		{"debug", peer("", "", ""), false},
+		{"eth", peer("192.168.0.1:1234", "", ""), true},
+		{"eth", peer("192.168.0.1:1234", "https://wallet.example", ""), true},
+		{"eth", peer("192.168.0.1:1234", "https://evil.example", ""), false},
+		{"net", peer("192.168.0.1:1234", "", "localhost:9650"), true},
+		{"net", peer("192.168.0.1:1234", "", "node.example"), false},
+	}
+	for _, test := range tests {
+		err := policy.check(test.namespace, test.peer)
+		if allowed := err == nil; allowed != test.allowed {
+			t.Errorf("namespace %s, peer %+v: expected allowed=%t, got error %v", test.namespace, test.peer, test.allowed, err)
+		}
+	}
+
+	if err := (AccessPolicy{"eth": {AllowedIPs: []string{"not-an-ip"}}}).Verify(); err == nil {
+		t.Fatal("expected invalid IP to be rejected")
+	}
+}
+
+func TestHTTPAccessPolicy(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	if err := server.SetAccessPolicy(AccessPolicy{
+		"test": {AllowedOrigins: []string{"https://allowed.example"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	call := func(origin string) error {
+		client, err := DialOptions(context.Background(), ts.URL, WithHTTPClient(ts.Client()), WithHeader("Origin", origin))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer client.Close()
+		var result echoResult
+		return client.Call(&result, "test_echo", "hello", 10, &echoArgs{"world"})
+	}
+	if err := call("https://allowed.example"); err != nil {
+		t.Fatalf("allowed origin rejected: %v", err)
+	}
+	err := call("https://denied.example")
+	var rpcErr Error
+	if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != errcodeAccessDenied {
+		t.Fatalf("expected access denied error, got %v", err)
+	}
+}
@@ -97,6 +97,12 @@ type Client struct {
 	// config fields
 	batchItemLimit       int
 	batchResponseMaxSize int
+	subscriptionLimit    int
+	notificationBuffer   int
+	slowConsumerPolicy   SlowConsumerPolicy
+	access               accessPolicy
+	scheduler            CallScheduler
+	fallback             *callFallback
 
 	// writeConn is used for writing to the connection on the caller's goroutine. It should
 	// only be accessed outside of dispatch, with the write lock held. The write lock is
@@ -134,6 +140,13 @@ func (c *Client) newClientConn(conn ServerCodec, apiMaxDuration, refillRate, max
 	// all client invocations of this function), it is ignored.
 	handler.deadlineContext = apiMaxDuration
 	handler.addLimiter(refillRate, maxStored)
+	handler.maxSubscriptions = c.subscriptionLimit
+	handler.notificationBuffer = c.notificationBuffer
+	handler.slowConsumerPolicy = c.slowConsumerPolicy
+	handler.closeConn = conn.close
+	handler.access = c.access
+	handler.scheduler = c.scheduler
+	handler.fallback = c.fallback
 	return &clientConn{conn, handler}
 }
 
@@ -261,6 +274,12 @@ func initClient(conn ServerCodec, services *serviceRegistry, cfg *clientConfig,
 		idgen:                cfg.idgen,
 		batchItemLimit:       cfg.batchItemLimit,
 		batchResponseMaxSize: cfg.batchResponseLimit,
+		subscriptionLimit:    cfg.subscriptionLimit,
+		notificationBuffer:   cfg.notificationBuffer,
+		slowConsumerPolicy:   cfg.slowConsumerPolicy,
+		access:               cfg.access,
+		scheduler:            cfg.scheduler,
+		fallback:             cfg.fallback,
 		writeConn:            conn,
 		close:                make(chan struct{}),
 		closing:              make(chan struct{}),
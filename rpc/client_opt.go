@@ -51,6 +51,12 @@ type clientConfig struct {
 	idgen              func() ID
 	batchItemLimit     int
 	batchResponseLimit int
+	subscriptionLimit  int
+	notificationBuffer int
+	slowConsumerPolicy SlowConsumerPolicy
+	access             accessPolicy
+	scheduler          CallScheduler
+	fallback           *callFallback
 }
 
 func (cfg *clientConfig) initHeaders() {
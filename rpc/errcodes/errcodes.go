@@ -0,0 +1,160 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package errcodes defines the codes of the errors returned by the RPC APIs
+// of the VM. An error carrying a code is returned with the code and with a
+// data object naming its reason, so that clients can branch on them instead
+// of parsing error messages, which may change between releases.
+package errcodes
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Code is the JSON-RPC error code of an error. Codes are allocated from the
+// range reserved for implementation-defined server errors, and are never
+// reassigned once released.
+type Code int
+
+// Codes of the errors rejecting a transaction, returned when issuing
+// transactions with eth_sendRawTransaction, eth_sendTransaction and the
+// atomic transaction calls.
+const (
+	TxInvalid                Code = -32010 // Malformed or fails validation
+	TxAlreadyKnown           Code = -32011 // Already in the pool
+	TxNonceTooLow            Code = -32012 // Nonce already used by the sender
+	TxNonceTooHigh           Code = -32013 // Nonce beyond what the pool accepts
+	TxUnderpriced            Code = -32014 // Pays less than the pool or block requires
+	TxReplacementUnderpriced Code = -32015 // Does not pay enough to replace a conflicting transaction
+	TxInsufficientFunds      Code = -32016 // Sender cannot pay for the transaction
+	TxGasLimitExceeded       Code = -32017 // Gas above the block gas limit
+	TxPoolFull               Code = -32018 // Pool or account limit reached
+	TxFeeCapExceeded         Code = -32019 // Fee above the cap of the node
+	TxUnprotected            Code = -32020 // Not replay protected
+)
+
+// Codes of the errors returned by operations of the node.
+const (
+	InvalidArgument Code = -32030 // Argument out of range or malformed
+	FeatureDisabled Code = -32031 // Operation requires a feature not enabled in the config
+)
+
+var reasons = map[Code]string{
+	TxInvalid:                "TX_INVALID",
+	TxAlreadyKnown:           "TX_ALREADY_KNOWN",
+	TxNonceTooLow:            "TX_NONCE_TOO_LOW",
+	TxNonceTooHigh:           "TX_NONCE_TOO_HIGH",
+	TxUnderpriced:            "TX_UNDERPRICED",
+	TxReplacementUnderpriced: "TX_REPLACEMENT_UNDERPRICED",
+	TxInsufficientFunds:      "TX_INSUFFICIENT_FUNDS",
+	TxGasLimitExceeded:       "TX_GAS_LIMIT_EXCEEDED",
+	TxPoolFull:               "TX_POOL_FULL",
+	TxFeeCapExceeded:         "TX_FEE_CAP_EXCEEDED",
+	TxUnprotected:            "TX_UNPROTECTED",
+	InvalidArgument:          "INVALID_ARGUMENT",
+	FeatureDisabled:          "FEATURE_DISABLED",
+}
+
+// String returns the reason of the code, a stable name clients may match on.
+func (c Code) String() string {
+	if reason, ok := reasons[c]; ok {
+		return reason
+	}
+	return fmt.Sprintf("UNKNOWN(%d)", int(c))
+}
+
+// Data is the data of an error carrying a code.
+type Data struct {
+	Reason string `json:"reason"`
+	// Details holds values specific to the error, such as the ID of the
+	// rejected transaction.
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// Error is an error carrying a code. It implements the error interfaces of
+// the rpc package, so that its code and data are returned to clients.
+type Error struct {
+	Code    Code
+	Err     error
+	Details map[string]interface{}
+}
+
+// New returns an error with [code] and [message], to be used as a sentinel
+// error.
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Err: errors.New(message)}
+}
+
+// Wrap returns [err] with [code], or nil if [err] is nil.
+func Wrap(code Code, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Err: err}
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ErrorCode returns the code of the error.
+func (e *Error) ErrorCode() int {
+	return int(e.Code)
+}
+
+// ErrorData returns the reason and the details of the error.
+func (e *Error) ErrorData() interface{} {
+	return Data{Reason: e.Code.String(), Details: e.Details}
+}
+
+// WithDetail returns a copy of the error with the detail [key] set to
+// [value].
+func (e *Error) WithDetail(key string, value interface{}) *Error {
+	details := make(map[string]interface{}, len(e.Details)+1)
+	for k, v := range e.Details {
+		details[k] = v
+	}
+	details[key] = value
+	return &Error{Code: e.Code, Err: e.Err, Details: details}
+}
+
+// Rule assigns [Code] to the errors matching [Err].
+type Rule struct {
+	Err  error
+	Code Code
+}
+
+// Classify returns [err] as an [*Error], so that its code is returned by the
+// rpc package, which does not unwrap errors. An error wrapping an [*Error]
+// keeps its code, otherwise the code of the first rule matching [err] is
+// used. Errors matching no rule, and nil, are returned unchanged.
+func Classify(err error, rules []Rule) error {
+	if err == nil {
+		return nil
+	}
+	var coded *Error
+	if errors.As(err, &coded) {
+		if coded == err {
+			return err
+		}
+		return &Error{Code: coded.Code, Err: err, Details: coded.Details}
+	}
+	for _, rule := range rules {
+		if errors.Is(err, rule.Err) {
+			return &Error{Code: rule.Code, Err: err}
+		}
+	}
+	return err
+}
+
+// As returns the [*Error] which [err] is or wraps, and whether there is one.
+func As(err error) (*Error, bool) {
+	var coded *Error
+	ok := errors.As(err, &coded)
+	return coded, ok
+}
@@ -0,0 +1,63 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package errcodes
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassify(t *testing.T) {
+	var (
+		errNonce = errors.New("nonce too low")
+		errFull  = errors.New("pool is full")
+		rules    = []Rule{
+			{Err: errNonce, Code: TxNonceTooLow},
+			{Err: errFull, Code: TxPoolFull},
+		}
+	)
+
+	require.NoError(t, Classify(nil, rules))
+
+	unknown := errors.New("unknown")
+	require.Equal(t, unknown, Classify(unknown, rules))
+
+	err := Classify(fmt.Errorf("%w: have 1 want 2", errNonce), rules)
+	coded, ok := err.(*Error)
+	require.True(t, ok)
+	require.Equal(t, TxNonceTooLow, coded.Code)
+	require.ErrorIs(t, err, errNonce)
+	require.Equal(t, "nonce too low: have 1 want 2", err.Error())
+	require.Equal(t, -32012, coded.ErrorCode())
+	require.Equal(t, Data{Reason: "TX_NONCE_TOO_LOW"}, coded.ErrorData())
+
+	// Errors wrapping an error carrying a code keep its code and details.
+	errDisabled := New(FeatureDisabled, "disabled").WithDetail("feature", "retention")
+	err = Classify(fmt.Errorf("failed: %w", errDisabled), rules)
+	coded, ok = err.(*Error)
+	require.True(t, ok)
+	require.Equal(t, FeatureDisabled, coded.Code)
+	require.Equal(t, "failed: disabled", err.Error())
+	require.Equal(t, Data{Reason: "FEATURE_DISABLED", Details: map[string]interface{}{"feature": "retention"}}, coded.ErrorData())
+	require.Same(t, errDisabled, Classify(errDisabled, rules))
+}
+
+func TestWithDetail(t *testing.T) {
+	base := New(TxInvalid, "invalid")
+	detailed := base.WithDetail("hash", "0x01").WithDetail("index", 2)
+
+	require.Nil(t, base.Details)
+	require.Equal(t, map[string]interface{}{"hash": "0x01", "index": 2}, detailed.Details)
+	require.ErrorIs(t, detailed, base.Err)
+}
+
+func TestCodeString(t *testing.T) {
+	for code, reason := range reasons {
+		require.Equal(t, reason, code.String())
+	}
+	require.Equal(t, "UNKNOWN(-1)", Code(-1).String())
+}
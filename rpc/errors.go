@@ -65,12 +65,14 @@ var (
 	_ Error = new(invalidMessageError)
 	_ Error = new(invalidParamsError)
 	_ Error = new(internalServerError)
+	_ Error = new(accessDeniedError)
 )
 
 const (
 	errcodeDefault          = -32000
 	errcodeTimeout          = -32002
 	errcodeResponseTooLarge = -32003
+	errcodeAccessDenied     = -32004
 	errcodePanic            = -32603
 	errcodeMarshalError     = -32603
 
@@ -91,6 +93,14 @@ func (e *methodNotFoundError) Error() string {
 	return fmt.Sprintf("the method %s does not exist/is not available", e.method)
 }
 
+type accessDeniedError struct{ namespace string }
+
+func (e *accessDeniedError) ErrorCode() int { return errcodeAccessDenied }
+
+func (e *accessDeniedError) Error() string {
+	return fmt.Sprintf("access to the %s namespace is not allowed", e.namespace)
+}
+
 type notificationsUnsupportedError struct{}
 
 func (e notificationsUnsupportedError) Error() string {
@@ -0,0 +1,47 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// CallFallback replaces the local answer of method calls, see
+// [Server.SetCallFallback].
+type CallFallback interface {
+	// Methods returns the methods whose calls are passed to Fallback.
+	Methods() []string
+
+	// Fallback is called with the encoded [result] or the [err] of each call
+	// of [method] with [params] answered locally. It returns the encoded
+	// result replacing the local answer, or false to keep the local answer.
+	Fallback(ctx context.Context, method string, params json.RawMessage, result json.RawMessage, err error) (json.RawMessage, bool)
+}
+
+// callFallback restricts a [CallFallback] to the methods it declares.
+type callFallback struct {
+	CallFallback
+	methods map[string]struct{}
+}
+
+func newCallFallback(fallback CallFallback) *callFallback {
+	f := &callFallback{
+		CallFallback: fallback,
+		methods:      make(map[string]struct{}),
+	}
+	for _, method := range fallback.Methods() {
+		f.methods[method] = struct{}{}
+	}
+	return f
+}
+
+// handles returns whether the calls of [method] are passed to the fallback.
+func (f *callFallback) handles(method string) bool {
+	if f == nil {
+		return false
+	}
+	_, ok := f.methods[method]
+	return ok
+}
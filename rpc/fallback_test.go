@@ -0,0 +1,56 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+type nullFallback struct {
+	calls []string
+}
+
+func (f *nullFallback) Methods() []string {
+	return []string{"test_null"}
+}
+
+func (f *nullFallback) Fallback(_ context.Context, method string, _ json.RawMessage, _ json.RawMessage, _ error) (json.RawMessage, bool) {
+	f.calls = append(f.calls, method)
+	return json.RawMessage(`"fallback"`), true
+}
+
+func TestHTTPCallFallback(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	fallback := &nullFallback{}
+	server.SetCallFallback(fallback)
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	client, err := DialOptions(context.Background(), ts.URL, WithHTTPClient(ts.Client()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	var result string
+	if err := client.Call(&result, "test_null"); err != nil {
+		t.Fatal(err)
+	}
+	if result != "fallback" {
+		t.Fatalf("expected the answer of the fallback, got %q", result)
+	}
+
+	// Methods not declared by the fallback are answered locally.
+	var echo echoResult
+	if err := client.Call(&echo, "test_echo", "hello", 10, &echoArgs{"world"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(fallback.calls) != 1 || fallback.calls[0] != "test_null" {
+		t.Fatalf("expected the fallback to only see test_null, got %v", fallback.calls)
+	}
+}
@@ -77,6 +77,19 @@ type handler struct {
 
 	subLock    sync.Mutex
 	serverSubs map[ID]*Subscription
+	// reservedSubs counts the subscribe calls in progress, which hold a slot
+	// of [maxSubscriptions] until their subscription is added.
+	reservedSubs int
+
+	// Subscription limits of the connection, see [Server.SetSubscriptionLimits].
+	maxSubscriptions   int
+	notificationBuffer int
+	slowConsumerPolicy SlowConsumerPolicy
+	closeConn          func() // closes the connection of a slow consumer
+
+	access    accessPolicy  // restricts the clients allowed to call each namespace
+	scheduler CallScheduler // admits the execution of method calls, if set
+	fallback  *callFallback // replaces the local answer of the calls of its methods, if set
 
 	deadlineContext time.Duration // limits execution after some time.Duration
 	limiter         *rate.Limiter
@@ -388,6 +401,10 @@ func (h *handler) addSubscriptions(nn []*Notifier) {
 	defer h.subLock.Unlock()
 
 	for _, n := range nn {
+		if n.reserved {
+			n.reserved = false
+			h.reservedSubs--
+		}
 		if sub := n.takeSubscription(); sub != nil {
 			h.serverSubs[sub.ID] = sub
 		}
@@ -402,6 +419,7 @@ func (h *handler) cancelServerSubscriptions(err error) {
 	for id, s := range h.serverSubs {
 		s.err <- err
 		close(s.err)
+		close(s.done)
 		delete(h.serverSubs, id)
 	}
 }
@@ -589,6 +607,9 @@ func (h *handler) handleCallMsg(ctx *callProc, msg *jsonrpcMessage) *jsonrpcMess
 
 // handleCall processes method calls.
 func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage {
+	if err := h.access.check(msg.namespace(), PeerInfoFromContext(cp.ctx)); err != nil {
+		return msg.errorResponse(err)
+	}
 	if msg.isSubscribe() {
 		return h.handleSubscribe(cp, msg)
 	}
@@ -606,6 +627,13 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage
 	if err != nil {
 		return msg.errorResponse(&invalidParamsError{err.Error()})
 	}
+	if h.scheduler != nil && callb != h.unsubscribeCb {
+		release, err := h.scheduler.Admit(cp.ctx, msg.Method)
+		if err != nil {
+			return msg.errorResponse(err)
+		}
+		defer release()
+	}
 	start := time.Now()
 	answer := h.runMethod(cp.ctx, msg, callb, args)
 
@@ -632,7 +660,6 @@ func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMes
 	if !h.allowSubscribe {
 		return msg.errorResponse(ErrNotificationsUnsupported)
 	}
-
 	// Subscription method name is first argument.
 	name, err := parseSubscriptionName(msg.Params)
 	if err != nil {
@@ -652,8 +679,15 @@ func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMes
 	}
 	args = args[1:]
 
+	// Reserve a slot for the subscription before running the callback, so
+	// that concurrent subscribe calls cannot exceed the limit.
+	if !h.reserveSubscription() {
+		rejectedSubscriptionsCounter.Inc(1)
+		return msg.errorResponse(ErrSubscriptionLimit)
+	}
+
 	// Install notifier in context so the subscription handler can find it.
-	n := &Notifier{h: h, namespace: namespace}
+	n := &Notifier{h: h, namespace: namespace, reserved: true}
 	cp.notifiers = append(cp.notifiers, n)
 	ctx := context.WithValue(cp.ctx, notifierKey{}, n)
 
@@ -663,10 +697,18 @@ func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMes
 // runMethod runs the Go callback for an RPC method.
 func (h *handler) runMethod(ctx context.Context, msg *jsonrpcMessage, callb *callback, args []reflect.Value) *jsonrpcMessage {
 	result, err := callb.call(ctx, msg.Method, args)
+	var answer *jsonrpcMessage
 	if err != nil {
-		return msg.errorResponse(err)
+		answer = msg.errorResponse(err)
+	} else {
+		answer = msg.response(result)
 	}
-	return msg.response(result)
+	if h.fallback.handles(msg.Method) {
+		if enc, ok := h.fallback.Fallback(ctx, msg.Method, msg.Params, answer.Result, err); ok {
+			return &jsonrpcMessage{Version: vsn, ID: msg.ID, Result: enc}
+		}
+	}
+	return answer
 }
 
 // unsubscribe is the callback function for all *_unsubscribe calls.
@@ -679,10 +721,38 @@ func (h *handler) unsubscribe(ctx context.Context, id ID) (bool, error) {
 		return false, ErrSubscriptionNotFound
 	}
 	close(s.err)
+	close(s.done)
 	delete(h.serverSubs, id)
 	return true, nil
 }
 
+// reserveSubscription reserves a slot for a new subscription, checking the
+// limit and counting the reservation atomically. Returns false if the
+// connection has no slot left. The slot is released by [addSubscriptions].
+func (h *handler) reserveSubscription() bool {
+	h.subLock.Lock()
+	defer h.subLock.Unlock()
+
+	if h.maxSubscriptions > 0 && len(h.serverSubs)+h.reservedSubs >= h.maxSubscriptions {
+		return false
+	}
+	h.reservedSubs++
+	return true
+}
+
+// handleSlowConsumer applies the slow consumer policy to a notification that
+// does not fit in the notification buffer of [sub].
+func (h *handler) handleSlowConsumer(sub *Subscription) error {
+	if h.slowConsumerPolicy != DisconnectSlowConsumer || h.closeConn == nil {
+		droppedNotificationsCounter.Inc(1)
+		return nil
+	}
+	disconnectedSubscribersCounter.Inc(1)
+	h.log.Warn("Closing connection of slow subscriber", "id", sub.ID, "namespace", sub.namespace)
+	go h.closeConn()
+	return ErrSlowConsumer
+}
+
 type idForLog struct{ json.RawMessage }
 
 func (id idForLog) String() string {
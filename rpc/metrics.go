@@ -42,6 +42,10 @@ var (
 	serveTimeHistName = "rpc/duration"
 
 	rpcServingTimer = metrics.NewRegisteredTimer("rpc/duration/all", nil)
+
+	rejectedSubscriptionsCounter   = metrics.NewRegisteredCounter("rpc/subscriptions/rejected", nil)
+	droppedNotificationsCounter    = metrics.NewRegisteredCounter("rpc/subscriptions/dropped", nil)
+	disconnectedSubscribersCounter = metrics.NewRegisteredCounter("rpc/subscriptions/disconnected", nil)
 )
 
 // updateServeTimeHistogram tracks the serving time of a remote RPC call.
@@ -0,0 +1,14 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpc
+
+import "context"
+
+// CallScheduler admits the execution of method calls, see
+// [Server.SetCallScheduler].
+type CallScheduler interface {
+	// Admit blocks until a call of [method] may execute or [ctx] is done.
+	// The returned function must be called once the call completes.
+	Admit(ctx context.Context, method string) (release func(), err error)
+}
@@ -0,0 +1,53 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+type countingScheduler struct {
+	methods  []string
+	released int
+	err      error
+}
+
+func (s *countingScheduler) Admit(_ context.Context, method string) (func(), error) {
+	s.methods = append(s.methods, method)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return func() { s.released++ }, nil
+}
+
+func TestHTTPCallScheduler(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	scheduler := &countingScheduler{}
+	server.SetCallScheduler(scheduler)
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	client, err := DialOptions(context.Background(), ts.URL, WithHTTPClient(ts.Client()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	var result echoResult
+	if err := client.Call(&result, "test_echo", "hello", 10, &echoArgs{"world"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(scheduler.methods) != 1 || scheduler.methods[0] != "test_echo" || scheduler.released != 1 {
+		t.Fatalf("expected one admitted and released test_echo call, got %v and %d releases", scheduler.methods, scheduler.released)
+	}
+
+	scheduler.err = errors.New("overloaded")
+	if err := client.Call(&result, "test_echo", "hello", 10, &echoArgs{"world"}); err == nil || err.Error() != "overloaded" {
+		t.Fatalf("expected call rejected by the scheduler, got %v", err)
+	}
+}
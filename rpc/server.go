@@ -62,6 +62,13 @@ type Server struct {
 	run                atomic.Bool
 	batchItemLimit     int
 	batchResponseLimit int
+
+	subscriptionLimit  int
+	notificationBuffer int
+	slowConsumerPolicy SlowConsumerPolicy
+	access             accessPolicy
+	scheduler          CallScheduler
+	fallback           *callFallback
 }
 
 // NewServer creates a new server instance with no registered handlers.
@@ -94,6 +101,57 @@ func (s *Server) SetBatchLimits(itemLimit, maxResponseSize int) {
 	s.batchResponseLimit = maxResponseSize
 }
 
+// SetSubscriptionLimits sets limits applied to the subscriptions of each
+// connection. 'maxSubscriptions' is the maximum number of active subscriptions.
+// 'notificationBuffer' is the number of notifications buffered per subscription
+// while the client is reading slower than notifications are produced, and
+// 'policy' determines what happens once the buffer is full. Zero values disable
+// the respective limit; without a buffer, notifications are written directly
+// to the connection.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetSubscriptionLimits(maxSubscriptions, notificationBuffer int, policy SlowConsumerPolicy) {
+	s.subscriptionLimit = maxSubscriptions
+	s.notificationBuffer = notificationBuffer
+	s.slowConsumerPolicy = policy
+}
+
+// SetAccessPolicy restricts the clients that may call the methods of each
+// namespace. Restrictions are checked for every call, so namespaces served on
+// the same endpoint can be exposed to different clients.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetAccessPolicy(policy AccessPolicy) error {
+	access, err := policy.parse()
+	if err != nil {
+		return err
+	}
+	s.access = access
+	return nil
+}
+
+// SetCallScheduler admits the execution of every method call through
+// [scheduler], which may delay calls to prioritize other work sharing the
+// resources of the server.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetCallScheduler(scheduler CallScheduler) {
+	s.scheduler = scheduler
+}
+
+// SetCallFallback lets [fallback] replace the local answer of the calls of
+// the methods it declares, for instance to serve data missing locally from
+// another node.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetCallFallback(fallback CallFallback) {
+	s.fallback = newCallFallback(fallback)
+}
+
 // RegisterName creates a service for the given receiver type under the given name. When no
 // methods on the given receiver match the criteria to be either a RPC method or a
 // subscription an error is returned. Otherwise a new service is created and added to the
@@ -119,6 +177,12 @@ func (s *Server) ServeCodec(codec ServerCodec, options CodecOption, apiMaxDurati
 		idgen:              s.idgen,
 		batchItemLimit:     s.batchItemLimit,
 		batchResponseLimit: s.batchResponseLimit,
+		subscriptionLimit:  s.subscriptionLimit,
+		notificationBuffer: s.notificationBuffer,
+		slowConsumerPolicy: s.slowConsumerPolicy,
+		access:             s.access,
+		scheduler:          s.scheduler,
+		fallback:           s.fallback,
 	}
 	c := initClient(codec, &s.services, cfg, apiMaxDuration, refillRate, maxStored)
 	<-codec.closed()
@@ -154,6 +218,9 @@ func (s *Server) serveSingleRequest(ctx context.Context, codec ServerCodec) {
 
 	h := newHandler(ctx, codec, s.idgen, &s.services, s.batchItemLimit, s.batchResponseLimit)
 	h.deadlineContext = s.maximumDuration
+	h.access = s.access
+	h.scheduler = s.scheduler
+	h.fallback = s.fallback
 	h.allowSubscribe = false
 	defer h.close(io.EOF, nil)
 
@@ -34,6 +34,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"math/rand"
 	"reflect"
 	"strings"
@@ -55,8 +56,37 @@ var (
 
 	// ErrSubscriptionNotFound is returned when the notification for the given id is not found
 	ErrSubscriptionNotFound = errors.New("subscription not found")
+
+	// ErrSubscriptionLimit is returned when a connection exceeds its maximum
+	// number of subscriptions.
+	ErrSubscriptionLimit = errors.New("too many subscriptions")
+
+	// ErrSlowConsumer is returned by Notify when the connection is closed
+	// because the client did not keep up with its notifications.
+	ErrSlowConsumer = errors.New("subscriber not keeping up with notifications")
+)
+
+// SlowConsumerPolicy determines what happens to a notification sent to a
+// subscription whose notification buffer is full.
+type SlowConsumerPolicy string
+
+const (
+	// DropNotifications drops notifications until the client catches up.
+	DropNotifications SlowConsumerPolicy = "drop"
+	// DisconnectSlowConsumer closes the connection of the client.
+	DisconnectSlowConsumer SlowConsumerPolicy = "disconnect"
 )
 
+// Verify returns an error if [p] is not a known policy.
+func (p SlowConsumerPolicy) Verify() error {
+	switch p {
+	case DropNotifications, DisconnectSlowConsumer:
+		return nil
+	default:
+		return fmt.Errorf("unknown slow consumer policy %q", p)
+	}
+}
+
 var globalGen = randomIDGenerator()
 
 // ID defines a pseudo random number that is used to identify RPC subscriptions.
@@ -118,6 +148,13 @@ type Notifier struct {
 	buffer       []any
 	callReturned bool
 	activated    bool
+	// reserved is set while the notifier holds a subscription slot of the
+	// handler, guarded by the subscription lock of the handler.
+	reserved bool
+
+	// queue holds notifications waiting to be written to the connection if
+	// the handler limits the number of buffered notifications.
+	queue chan any
 }
 
 // CreateSubscription returns a new subscription that is coupled to the
@@ -133,7 +170,7 @@ func (n *Notifier) CreateSubscription() *Subscription {
 	} else if n.callReturned {
 		panic("can't create subscription after subscribe call has returned")
 	}
-	n.sub = &Subscription{ID: n.h.idgen(), namespace: n.namespace, err: make(chan error, 1)}
+	n.sub = &Subscription{ID: n.h.idgen(), namespace: n.namespace, err: make(chan error, 1), done: make(chan struct{})}
 	return n.sub
 }
 
@@ -148,11 +185,22 @@ func (n *Notifier) Notify(id ID, data any) error {
 	} else if n.sub.ID != id {
 		panic("Notify with wrong ID")
 	}
-	if n.activated {
+	if !n.activated {
+		n.buffer = append(n.buffer, data)
+		return nil
+	}
+	if n.queue == nil {
 		return n.send(n.sub, data)
 	}
-	n.buffer = append(n.buffer, data)
-	return nil
+	select {
+	case <-n.sub.done:
+		// The subscription was cancelled, so nothing is forwarding the queue.
+		return nil
+	case n.queue <- data:
+		return nil
+	default:
+		return n.h.handleSlowConsumer(n.sub)
+	}
 }
 
 // Closed returns a channel that is closed when the RPC connection is closed.
@@ -183,9 +231,30 @@ func (n *Notifier) activate() error {
 		}
 	}
 	n.activated = true
+	if n.sub != nil && n.h.notificationBuffer > 0 {
+		n.queue = make(chan any, n.h.notificationBuffer)
+		go n.forward(n.sub, n.queue)
+	}
 	return nil
 }
 
+// forward writes queued notifications to the connection until the
+// subscription is cancelled or the connection is closed.
+func (n *Notifier) forward(sub *Subscription, queue <-chan any) {
+	for {
+		select {
+		case data := <-queue:
+			if err := n.send(sub, data); err != nil {
+				return
+			}
+		case <-sub.done:
+			return
+		case <-n.h.conn.closed():
+			return
+		}
+	}
+}
+
 func (n *Notifier) send(sub *Subscription, data any) error {
 	msg := jsonrpcSubscriptionNotification{
 		Version: vsn,
@@ -203,7 +272,8 @@ func (n *Notifier) send(sub *Subscription, data any) error {
 type Subscription struct {
 	ID        ID
 	namespace string
-	err       chan error // closed on unsubscribe
+	err       chan error    // closed on unsubscribe
+	done      chan struct{} // closed on unsubscribe
 }
 
 // Err returns a channel that is closed when the client send an unsubscribe request.
@@ -40,6 +40,7 @@ import (
 
 	"github.com/shubhamdubey02/coreth/core/types"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
 )
 
 func TestNewID(t *testing.T) {
@@ -292,3 +293,126 @@ func TestNotify(t *testing.T) {
 		t.Errorf("have:\n%v\nwant:\n%v\n", have, want)
 	}
 }
+
+// This test checks that subscriptions beyond the connection limit are rejected.
+func TestSubscriptionLimit(t *testing.T) {
+	p1, p2 := net.Pipe()
+	defer p2.Close()
+
+	server := newTestServer()
+	server.SetSubscriptionLimits(1, 0, DropNotifications)
+	service := &notificationTestService{}
+	server.RegisterName("nftest", service)
+	go server.ServeCodec(NewCodec(p1), 0, 0, 0, 0)
+
+	p2.SetDeadline(time.Now().Add(10 * time.Second))
+	in := json.NewDecoder(p2)
+	p2.Write([]byte(`{"jsonrpc":"2.0","id":1,"method":"nftest_subscribe","params":["someSubscription",0,10]}`))
+	if _, _, err := readAndValidateMessage(in); err != nil {
+		t.Fatalf("first subscription failed: %v", err)
+	}
+	p2.Write([]byte(`{"jsonrpc":"2.0","id":2,"method":"nftest_subscribe","params":["someSubscription",0,10]}`))
+	_, _, err := readAndValidateMessage(in)
+	if err == nil || err.Error() != ErrSubscriptionLimit.Error() {
+		t.Fatalf("expected %v, got %v", ErrSubscriptionLimit, err)
+	}
+}
+
+// This test checks that subscriptions created by a batch count towards the
+// connection limit before the batch completes.
+func TestSubscriptionLimitBatch(t *testing.T) {
+	p1, p2 := net.Pipe()
+	defer p2.Close()
+
+	server := newTestServer()
+	server.SetSubscriptionLimits(1, 0, DropNotifications)
+	service := &notificationTestService{}
+	server.RegisterName("nftest", service)
+	go server.ServeCodec(NewCodec(p1), 0, 0, 0, 0)
+
+	p2.SetDeadline(time.Now().Add(10 * time.Second))
+	in := json.NewDecoder(p2)
+	p2.Write([]byte(`[{"jsonrpc":"2.0","id":1,"method":"nftest_subscribe","params":["someSubscription",0,10]},` +
+		`{"jsonrpc":"2.0","id":2,"method":"nftest_subscribe","params":["someSubscription",0,10]}]`))
+	var responses []*jsonrpcMessage
+	if err := in.Decode(&responses); err != nil {
+		t.Fatal(err)
+	}
+	if len(responses) != 2 {
+		t.Fatalf("expected 2 responses, got %d", len(responses))
+	}
+	if responses[0].Error != nil {
+		t.Fatalf("first subscription failed: %v", responses[0].Error)
+	}
+	if responses[1].Error == nil || responses[1].Error.Message != ErrSubscriptionLimit.Error() {
+		t.Fatalf("expected %v, got %v", ErrSubscriptionLimit, responses[1].Error)
+	}
+}
+
+// blockingConn is a jsonWriter whose writes block until unblocked.
+type blockingConn struct {
+	mockConn
+	writing chan struct{}
+	unblock chan struct{}
+}
+
+func (c *blockingConn) writeJSON(ctx context.Context, msg interface{}, isError bool) error {
+	select {
+	case c.writing <- struct{}{}:
+	default:
+	}
+	<-c.unblock
+	return nil
+}
+
+func TestNotifySlowConsumer(t *testing.T) {
+	for _, policy := range []SlowConsumerPolicy{DropNotifications, DisconnectSlowConsumer} {
+		t.Run(string(policy), func(t *testing.T) {
+			conn := &blockingConn{
+				writing: make(chan struct{}, 1),
+				unblock: make(chan struct{}),
+			}
+			defer close(conn.unblock)
+			disconnected := make(chan struct{})
+			h := &handler{
+				conn:               conn,
+				log:                log.Root(),
+				notificationBuffer: 1,
+				slowConsumerPolicy: policy,
+				closeConn:          func() { close(disconnected) },
+			}
+			id := ID("test")
+			sub := &Subscription{ID: id, done: make(chan struct{})}
+			defer close(sub.done)
+			notifier := &Notifier{h: h, sub: sub}
+			if err := notifier.activate(); err != nil {
+				t.Fatal(err)
+			}
+
+			// The first notification is being written, the second is buffered.
+			if err := notifier.Notify(id, 1); err != nil {
+				t.Fatal(err)
+			}
+			<-conn.writing
+			if err := notifier.Notify(id, 2); err != nil {
+				t.Fatal(err)
+			}
+
+			err := notifier.Notify(id, 3)
+			if policy == DropNotifications {
+				if err != nil {
+					t.Fatalf("expected dropped notification, got %v", err)
+				}
+				return
+			}
+			if err != ErrSlowConsumer {
+				t.Fatalf("expected %v, got %v", ErrSlowConsumer, err)
+			}
+			select {
+			case <-disconnected:
+			case <-time.After(10 * time.Second):
+				t.Fatal("slow consumer not disconnected")
+			}
+		})
+	}
+}
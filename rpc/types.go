@@ -158,6 +158,13 @@ func (bn BlockNumber) IsAccepted() bool {
 	return bn < EarliestBlockNumber && bn >= SafeBlockNumber
 }
 
+// IsFinalized returns true if this blockNumber is the "safe" or "finalized"
+// tag. Both always refer to the last accepted block, as accepted blocks are
+// final, even if unfinalized queries are allowed.
+func (bn BlockNumber) IsFinalized() bool {
+	return bn == SafeBlockNumber || bn == FinalizedBlockNumber
+}
+
 func (bn BlockNumber) IsLatest() bool {
 	return bn == LatestBlockNumber || bn == PendingBlockNumber
 }
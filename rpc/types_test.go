@@ -58,6 +58,9 @@ func TestBlockNumberJSONUnmarshal(t *testing.T) {
 		14: {`someString`, true, BlockNumber(0)},
 		15: {`""`, true, BlockNumber(0)},
 		16: {``, true, BlockNumber(0)},
+		17: {`"safe"`, false, SafeBlockNumber},
+		18: {`"finalized"`, false, FinalizedBlockNumber},
+		19: {`"accepted"`, false, FinalizedBlockNumber},
 	}
 
 	for i, test := range tests {
@@ -0,0 +1,245 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package statesyncclient
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	mrand "math/rand"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/shubhamdubey02/coreth/plugin/evm/message"
+	"github.com/shubhamdubey02/cryftgo/ids"
+	"github.com/shubhamdubey02/cryftgo/utils/set"
+)
+
+const (
+	// availabilitySampleLimit is the number of leafs requested by each sample,
+	// which only needs to be large enough to carry a range proof.
+	availabilitySampleLimit = 8
+	// availabilityMaxEvaluations bounds the number of summaries whose
+	// evaluation is kept, as every summary advertised by a peer is observed.
+	availabilityMaxEvaluations = 32
+)
+
+var (
+	errSummaryUnavailable           = errors.New("state summary served by too few peers")
+	errInvalidAvailabilityConfig    = errors.New("invalid state summary availability config")
+	errAvailabilityEvaluatorStopped = errors.New("state summary availability evaluator stopped")
+)
+
+// AvailabilityConfig configures the evaluation of the state summaries
+// advertised by peers.
+type AvailabilityConfig struct {
+	// Samples is the maximum number of leaf requests sent for each summary,
+	// each to a distinct peer.
+	Samples int
+	// MinPeers is the number of distinct peers which must serve valid proofs
+	// of the state root of a summary for it to be synced to.
+	MinPeers int
+	// Timeout bounds the evaluation of each summary.
+	Timeout time.Duration
+	// Parallelism is the number of summaries evaluated concurrently.
+	Parallelism int
+}
+
+// Verify returns an error if the config cannot be used.
+func (c AvailabilityConfig) Verify() error {
+	switch {
+	case c.MinPeers <= 0:
+		return fmt.Errorf("%w: min peers must be positive", errInvalidAvailabilityConfig)
+	case c.Samples < c.MinPeers:
+		return fmt.Errorf("%w: samples (%d) below min peers (%d)", errInvalidAvailabilityConfig, c.Samples, c.MinPeers)
+	case c.Timeout <= 0:
+		return fmt.Errorf("%w: timeout must be positive", errInvalidAvailabilityConfig)
+	case c.Parallelism <= 0:
+		return fmt.Errorf("%w: parallelism must be positive", errInvalidAvailabilityConfig)
+	}
+	return nil
+}
+
+// AvailabilityEvaluator evaluates the state summaries advertised by peers in
+// the background, sending sample leaf requests for the state root of each
+// summary to distinct peers and counting those serving valid proofs of it, so
+// that a summary served by too few peers is not synced to.
+//
+// At most [availabilityMaxEvaluations] summaries are tracked. Once the limit
+// is reached, the oldest completed evaluation is evicted to make room, and
+// observed summaries are not evaluated if all evaluations are in progress.
+type AvailabilityEvaluator struct {
+	client Client
+	config AvailabilityConfig
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	// [slots] bounds the number of summaries evaluated concurrently.
+	slots chan struct{}
+
+	lock        sync.Mutex
+	evaluations map[common.Hash]*evaluation // keyed by block hash
+	order       []common.Hash               // block hashes by insertion
+}
+
+type evaluation struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+	peers  set.Set[ids.NodeID] // set before [done] is closed
+}
+
+// NewAvailabilityEvaluator returns an evaluator sending the sample requests
+// of [config] with [client].
+func NewAvailabilityEvaluator(client Client, config AvailabilityConfig) (*AvailabilityEvaluator, error) {
+	if err := config.Verify(); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &AvailabilityEvaluator{
+		client:      client,
+		config:      config,
+		ctx:         ctx,
+		cancel:      cancel,
+		slots:       make(chan struct{}, config.Parallelism),
+		evaluations: make(map[common.Hash]*evaluation),
+	}, nil
+}
+
+// Observe starts evaluating the summary of [blockHash] with state [root],
+// unless it is already evaluated or too many evaluations are in progress.
+func (e *AvailabilityEvaluator) Observe(blockHash common.Hash, root common.Hash) {
+	if e.evaluation(blockHash, root, false) == nil {
+		log.Debug("too many state summary evaluations in progress, not evaluating", "hash", blockHash, "root", root)
+	}
+}
+
+// Verify waits for the evaluation of the summary of [blockHash] with state
+// [root], starting it if the summary was not observed, and returns an error
+// if fewer than the configured number of peers served valid proofs of it.
+func (e *AvailabilityEvaluator) Verify(blockHash common.Hash, root common.Hash) error {
+	ev := e.evaluation(blockHash, root, true)
+	select {
+	case <-ev.done:
+	case <-e.ctx.Done():
+		return errAvailabilityEvaluatorStopped
+	}
+	if served := ev.peers.Len(); served < e.config.MinPeers {
+		return fmt.Errorf("%w: root %s served by %d/%d peers", errSummaryUnavailable, root, served, e.config.MinPeers)
+	}
+	return nil
+}
+
+// Close stops the ongoing evaluations and waits for them to exit.
+func (e *AvailabilityEvaluator) Close() {
+	e.cancel()
+	e.wg.Wait()
+}
+
+// evaluation returns the evaluation of the summary of [blockHash], starting
+// it if needed. If the evaluations are at their limit and none of them is
+// complete, the oldest is cancelled and evicted if [force] is set, and nil is
+// returned otherwise.
+func (e *AvailabilityEvaluator) evaluation(blockHash common.Hash, root common.Hash, force bool) *evaluation {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if ev, ok := e.evaluations[blockHash]; ok {
+		return ev
+	}
+	if len(e.evaluations) >= availabilityMaxEvaluations && !e.evict(force) {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(e.ctx)
+	ev := &evaluation{cancel: cancel, done: make(chan struct{})}
+	e.evaluations[blockHash] = ev
+	e.order = append(e.order, blockHash)
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		defer cancel()
+		defer close(ev.done)
+
+		select {
+		case e.slots <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+		defer func() { <-e.slots }()
+
+		ev.peers = e.sample(ctx, root)
+		log.Debug("evaluated state summary availability", "hash", blockHash, "root", root, "peers", ev.peers.Len())
+	}()
+	return ev
+}
+
+// evict removes the oldest complete evaluation, or the oldest evaluation if
+// none is complete and [force] is set. Returns false if nothing was evicted.
+// Assumes [e.lock] is held.
+func (e *AvailabilityEvaluator) evict(force bool) bool {
+	victim := -1
+	for i, blockHash := range e.order {
+		select {
+		case <-e.evaluations[blockHash].done:
+			victim = i
+		default:
+		}
+		if victim != -1 {
+			break
+		}
+	}
+	if victim == -1 {
+		if !force {
+			return false
+		}
+		victim = 0
+	}
+	blockHash := e.order[victim]
+	e.evaluations[blockHash].cancel()
+	delete(e.evaluations, blockHash)
+	e.order = append(e.order[:victim], e.order[victim+1:]...)
+	return true
+}
+
+// sample sends sample leaf requests for [root], starting at random keys, to
+// distinct peers picked at random, until [MinPeers] peers served valid proofs
+// of it, [Samples] requests were sent, or [Timeout] expires. Returns the peers
+// which served valid proofs.
+func (e *AvailabilityEvaluator) sample(ctx context.Context, root common.Hash) set.Set[ids.NodeID] {
+	ctx, cancel := context.WithTimeout(ctx, e.config.Timeout)
+	defer cancel()
+
+	candidates := append([]ids.NodeID(nil), e.client.Peers()...)
+	mrand.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+	if len(candidates) > e.config.Samples {
+		candidates = candidates[:e.config.Samples]
+	}
+
+	peers := set.NewSet[ids.NodeID](e.config.MinPeers)
+	for _, nodeID := range candidates {
+		if peers.Len() >= e.config.MinPeers || ctx.Err() != nil {
+			break
+		}
+		start := make([]byte, common.HashLength)
+		_, _ = rand.Read(start)
+		err := e.client.ProbeLeafs(ctx, nodeID, message.LeafsRequest{
+			Root:     root,
+			Start:    start,
+			Limit:    availabilitySampleLimit,
+			NodeType: message.StateTrieNode,
+		})
+		if err != nil {
+			log.Debug("state summary availability sample failed", "root", root, "nodeID", nodeID, "err", err)
+			continue
+		}
+		peers.Add(nodeID)
+	}
+	return peers
+}
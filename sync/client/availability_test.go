@@ -0,0 +1,163 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package statesyncclient
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shubhamdubey02/coreth/plugin/evm/message"
+	"github.com/shubhamdubey02/cryftgo/ids"
+	"github.com/shubhamdubey02/cryftgo/utils/set"
+	"github.com/stretchr/testify/require"
+)
+
+// probeClient serves probes of each root from its list of peers.
+type probeClient struct {
+	Client
+
+	lock   sync.Mutex
+	peers  []ids.NodeID
+	served map[common.Hash]set.Set[ids.NodeID]
+	probed map[common.Hash][]ids.NodeID
+	block  chan struct{} // if set, probes block until it is closed
+}
+
+func (c *probeClient) Peers() []ids.NodeID { return c.peers }
+
+func (c *probeClient) ProbeLeafs(ctx context.Context, nodeID ids.NodeID, req message.LeafsRequest) error {
+	if c.block != nil {
+		select {
+		case <-c.block:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.probed[req.Root] = append(c.probed[req.Root], nodeID)
+	served := c.served[req.Root]
+	if !served.Contains(nodeID) {
+		return errors.New("root not served")
+	}
+	return nil
+}
+
+func (c *probeClient) probes(root common.Hash) []ids.NodeID {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.probed[root]
+}
+
+func TestAvailabilityConfigVerify(t *testing.T) {
+	config := AvailabilityConfig{Samples: 4, MinPeers: 2, Timeout: time.Second, Parallelism: 1}
+	require.NoError(t, config.Verify())
+
+	invalid := config
+	invalid.Samples = 1
+	require.ErrorIs(t, invalid.Verify(), errInvalidAvailabilityConfig)
+	invalid = config
+	invalid.Parallelism = 0
+	require.ErrorIs(t, invalid.Verify(), errInvalidAvailabilityConfig)
+}
+
+func TestAvailabilityEvaluator(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		wellServed   = common.Hash{1}
+		poorlyServed = common.Hash{2}
+		unserved     = common.Hash{3}
+		client       = &probeClient{
+			peers: []ids.NodeID{{1}, {2}, {3}, {4}, {5}, {6}},
+			served: map[common.Hash]set.Set[ids.NodeID]{
+				wellServed:   set.Of[ids.NodeID]([]ids.NodeID{{1}, {2}, {3}, {4}}...),
+				poorlyServed: set.Of[ids.NodeID]([]ids.NodeID{{1}}...),
+			},
+			probed: make(map[common.Hash][]ids.NodeID),
+		}
+	)
+	evaluator, err := NewAvailabilityEvaluator(client, AvailabilityConfig{
+		Samples:     5,
+		MinPeers:    3,
+		Timeout:     time.Second,
+		Parallelism: 2,
+	})
+	require.NoError(err)
+	defer evaluator.Close()
+
+	evaluator.Observe(common.Hash{11}, wellServed)
+	evaluator.Observe(common.Hash{12}, poorlyServed)
+	evaluator.Observe(common.Hash{13}, unserved)
+
+	require.NoError(evaluator.Verify(common.Hash{11}, wellServed))
+	require.ErrorIs(evaluator.Verify(common.Hash{12}, poorlyServed), errSummaryUnavailable)
+	require.ErrorIs(evaluator.Verify(common.Hash{13}, unserved), errSummaryUnavailable)
+
+	// Each summary is probed at distinct peers, at most [Samples] of them,
+	// and sampling stops once enough peers served the root.
+	for _, root := range []common.Hash{wellServed, poorlyServed, unserved} {
+		probed := client.probes(root)
+		require.LessOrEqual(len(probed), 5)
+		require.Len(set.Of(probed...), len(probed))
+	}
+	require.Len(client.probes(poorlyServed), 5)
+	wellServedProbes := len(client.probes(wellServed))
+	require.GreaterOrEqual(wellServedProbes, 3)
+
+	// Summaries are evaluated once, and those which were not observed are
+	// evaluated when verified.
+	require.NoError(evaluator.Verify(common.Hash{11}, wellServed))
+	require.Len(client.probes(wellServed), wellServedProbes)
+	require.NoError(evaluator.Verify(common.Hash{14}, wellServed))
+	require.Greater(len(client.probes(wellServed)), wellServedProbes)
+}
+
+func TestAvailabilityEvaluatorBounded(t *testing.T) {
+	require := require.New(t)
+
+	client := &probeClient{
+		peers:  []ids.NodeID{{1}},
+		served: make(map[common.Hash]set.Set[ids.NodeID]),
+		probed: make(map[common.Hash][]ids.NodeID),
+		block:  make(chan struct{}),
+	}
+	evaluator, err := NewAvailabilityEvaluator(client, AvailabilityConfig{
+		Samples:     1,
+		MinPeers:    1,
+		Timeout:     time.Minute,
+		Parallelism: 1,
+	})
+	require.NoError(err)
+	defer evaluator.Close()
+
+	// Summaries observed while every evaluation is in progress are dropped.
+	for i := 0; i < 2*availabilityMaxEvaluations; i++ {
+		evaluator.Observe(common.Hash{byte(i)}, common.Hash{byte(i)})
+	}
+	evaluator.lock.Lock()
+	require.Len(evaluator.evaluations, availabilityMaxEvaluations)
+	evaluator.lock.Unlock()
+
+	// Verifying a summary evicts the oldest evaluation in progress.
+	root := common.Hash{0xff}
+	require.NotNil(evaluator.evaluation(root, root, true))
+	evaluator.lock.Lock()
+	require.Len(evaluator.evaluations, availabilityMaxEvaluations)
+	require.NotContains(evaluator.evaluations, common.Hash{0})
+	require.Contains(evaluator.evaluations, root)
+	evaluator.lock.Unlock()
+
+	client.lock.Lock()
+	client.served[root] = set.Of(ids.NodeID{1})
+	client.lock.Unlock()
+	close(client.block)
+	require.NoError(evaluator.Verify(root, root))
+}
@@ -0,0 +1,246 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package statesyncclient
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/shubhamdubey02/cryftgo/ids"
+)
+
+const (
+	// maxObservedSummaries bounds the number of distinct summaries tallied by
+	// a [CheckpointVerifier].
+	maxObservedSummaries = 1024
+
+	// trustedNodeTimeout bounds the time trusted nodes have to serve the block
+	// of a summary.
+	trustedNodeTimeout = 10 * time.Second
+)
+
+var (
+	errCheckpointMismatch      = errors.New("summary conflicts with trusted checkpoint")
+	errCheckpointMajority      = errors.New("majority of observed summaries conflict with trusted checkpoints")
+	errUntrustedCheckpointSig  = errors.New("checkpoint signed by untrusted signer")
+	errNoCheckpointSigners     = errors.New("signed checkpoint feed requires at least one trusted signer")
+	errDuplicateCheckpointSlot = errors.New("conflicting checkpoints at the same height")
+	errTrustedNodesUnconfirmed = errors.New("majority of trusted nodes did not confirm summary")
+)
+
+// Checkpoint is a trusted (height, block hash) pair used to select and verify
+// the state summary to sync to. If Root is non-empty, the summary's state root
+// must match it as well.
+type Checkpoint struct {
+	Height    uint64      `json:"height"`
+	BlockHash common.Hash `json:"blockHash"`
+	Root      common.Hash `json:"root,omitempty"`
+}
+
+// Hash returns the digest signed by checkpoint feed publishers.
+func (c Checkpoint) Hash() common.Hash {
+	var height [8]byte
+	binary.BigEndian.PutUint64(height[:], c.Height)
+	return crypto.Keccak256Hash(height[:], c.BlockHash[:], c.Root[:])
+}
+
+// SignedCheckpoint is a [Checkpoint] as published in a signed checkpoint feed.
+type SignedCheckpoint struct {
+	Checkpoint
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// Signer returns the address that produced [c.Signature].
+func (c SignedCheckpoint) Signer() (common.Address, error) {
+	pub, err := crypto.SigToPub(c.Hash().Bytes(), c.Signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// ReadSignedCheckpoints reads a JSON list of [SignedCheckpoint] from [path] and
+// returns the checkpoints signed by one of [signers].
+func ReadSignedCheckpoints(path string, signers []common.Address) ([]Checkpoint, error) {
+	if len(signers) == 0 {
+		return nil, errNoCheckpointSigners
+	}
+	feedBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint feed %s: %w", path, err)
+	}
+	var signed []SignedCheckpoint
+	if err := json.Unmarshal(feedBytes, &signed); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint feed %s: %w", path, err)
+	}
+
+	trusted := make(map[common.Address]struct{}, len(signers))
+	for _, signer := range signers {
+		trusted[signer] = struct{}{}
+	}
+	checkpoints := make([]Checkpoint, 0, len(signed))
+	for _, checkpoint := range signed {
+		signer, err := checkpoint.Signer()
+		if err != nil {
+			return nil, fmt.Errorf("invalid signature on checkpoint at height %d: %w", checkpoint.Height, err)
+		}
+		if _, ok := trusted[signer]; !ok {
+			return nil, fmt.Errorf("%w: %s (height: %d)", errUntrustedCheckpointSig, signer, checkpoint.Height)
+		}
+		checkpoints = append(checkpoints, checkpoint.Checkpoint)
+	}
+	return checkpoints, nil
+}
+
+// CheckpointVerifier verifies proposed state summaries against a set of
+// trusted checkpoints. It also tallies the summaries advertised by peers so
+// that an eclipse attempt (most peers advertising summaries that conflict with
+// the checkpoints) fails explicitly rather than silently falling back.
+type CheckpointVerifier struct {
+	lock        sync.Mutex
+	checkpoints map[uint64]Checkpoint
+
+	// [observed] holds the distinct summaries seen at checkpointed heights, of
+	// which [conflicting] conflict with the checkpoints. Summaries are parsed
+	// repeatedly, so each is only counted once.
+	observed    map[common.Hash]struct{}
+	conflicting int
+}
+
+// NewCheckpointVerifier returns a verifier enforcing [checkpoints].
+func NewCheckpointVerifier(checkpoints []Checkpoint) (*CheckpointVerifier, error) {
+	v := &CheckpointVerifier{
+		checkpoints: make(map[uint64]Checkpoint, len(checkpoints)),
+		observed:    make(map[common.Hash]struct{}),
+	}
+	for _, checkpoint := range checkpoints {
+		if existing, ok := v.checkpoints[checkpoint.Height]; ok && existing != checkpoint {
+			return nil, fmt.Errorf("%w: height %d", errDuplicateCheckpointSlot, checkpoint.Height)
+		}
+		v.checkpoints[checkpoint.Height] = checkpoint
+	}
+	return v, nil
+}
+
+// Len returns the number of trusted checkpoints.
+func (v *CheckpointVerifier) Len() int {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	return len(v.checkpoints)
+}
+
+// Observe records a summary advertised by a peer. Summaries at heights without
+// a checkpoint, summaries already observed and summaries past the first
+// [maxObservedSummaries] are not counted.
+func (v *CheckpointVerifier) Observe(height uint64, blockHash common.Hash, root common.Hash) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	checkpoint, ok := v.checkpoints[height]
+	if !ok {
+		return
+	}
+	if _, ok := v.observed[blockHash]; ok {
+		return
+	}
+	if len(v.observed) >= maxObservedSummaries {
+		log.Debug("not tallying state summary, too many summaries observed", "height", height, "hash", blockHash)
+		return
+	}
+	v.observed[blockHash] = struct{}{}
+	if !checkpoint.matches(blockHash, root) {
+		v.conflicting++
+		log.Warn("observed state summary conflicting with trusted checkpoint", "height", height, "hash", blockHash, "expected", checkpoint.BlockHash)
+	}
+}
+
+// Verify returns an error if the summary at [height] conflicts with a trusted
+// checkpoint, or if the majority of summaries observed so far conflict with
+// the trusted checkpoints.
+func (v *CheckpointVerifier) Verify(height uint64, blockHash common.Hash, root common.Hash) error {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	if v.conflicting*2 > len(v.observed) {
+		return fmt.Errorf("%w (%d/%d)", errCheckpointMajority, v.conflicting, len(v.observed))
+	}
+	checkpoint, ok := v.checkpoints[height]
+	if !ok {
+		return nil
+	}
+	if !checkpoint.matches(blockHash, root) {
+		return fmt.Errorf("%w: height %d, hash %s, expected %s", errCheckpointMismatch, height, blockHash, checkpoint.BlockHash)
+	}
+	return nil
+}
+
+func (c Checkpoint) matches(blockHash common.Hash, root common.Hash) bool {
+	if c.BlockHash != blockHash {
+		return false
+	}
+	return c.Root == (common.Hash{}) || c.Root == root
+}
+
+// TrustedNodeVerifier verifies proposed state summaries with a set of trusted
+// nodes, the majority of which must serve the block of the summary.
+type TrustedNodeVerifier struct {
+	client Client
+	nodes  []ids.NodeID
+}
+
+// NewTrustedNodeVerifier returns a verifier requesting summary blocks from
+// [nodes] through [client].
+func NewTrustedNodeVerifier(client Client, nodes []ids.NodeID) *TrustedNodeVerifier {
+	return &TrustedNodeVerifier{
+		client: client,
+		nodes:  nodes,
+	}
+}
+
+// Verify returns an error unless the majority of the trusted nodes serve the
+// block [blockHash] at [height] with state root [root]. Nodes which do not
+// answer in time count as not confirming the summary.
+func (v *TrustedNodeVerifier) Verify(ctx context.Context, height uint64, blockHash common.Hash, root common.Hash) error {
+	ctx, cancel := context.WithTimeout(ctx, trustedNodeTimeout)
+	defer cancel()
+
+	confirmations := make(chan bool, len(v.nodes))
+	for _, nodeID := range v.nodes {
+		go func(nodeID ids.NodeID) {
+			block, err := v.client.ProbeBlock(ctx, nodeID, blockHash, height)
+			switch {
+			case err != nil:
+				log.Debug("trusted node did not serve state summary block", "nodeID", nodeID, "hash", blockHash, "err", err)
+				confirmations <- false
+			case block.NumberU64() != height || block.Root() != root:
+				log.Warn("trusted node served state summary block conflicting with summary", "nodeID", nodeID, "hash", blockHash, "height", block.NumberU64(), "root", block.Root())
+				confirmations <- false
+			default:
+				confirmations <- true
+			}
+		}(nodeID)
+	}
+
+	confirmed := 0
+	for range v.nodes {
+		if <-confirmations {
+			confirmed++
+		}
+	}
+	if confirmed*2 <= len(v.nodes) {
+		return fmt.Errorf("%w (%d/%d)", errTrustedNodesUnconfirmed, confirmed, len(v.nodes))
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package statesyncclient
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/cryftgo/ids"
+	"github.com/shubhamdubey02/cryftgo/utils/set"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckpointVerifier(t *testing.T) {
+	require := require.New(t)
+
+	trusted := Checkpoint{Height: 100, BlockHash: common.Hash{1}, Root: common.Hash{2}}
+	v, err := NewCheckpointVerifier([]Checkpoint{trusted})
+	require.NoError(err)
+
+	// Summaries at heights without a checkpoint are accepted.
+	require.NoError(v.Verify(50, common.Hash{9}, common.Hash{9}))
+	require.NoError(v.Verify(100, common.Hash{1}, common.Hash{2}))
+	require.ErrorIs(v.Verify(100, common.Hash{1}, common.Hash{3}), errCheckpointMismatch)
+	require.ErrorIs(v.Verify(100, common.Hash{3}, common.Hash{2}), errCheckpointMismatch)
+
+	// A minority of conflicting summaries does not prevent sync. Summaries
+	// observed repeatedly are counted once.
+	v.Observe(100, common.Hash{1}, common.Hash{2})
+	v.Observe(100, common.Hash{3}, common.Hash{2})
+	v.Observe(100, common.Hash{3}, common.Hash{2})
+	v.Observe(100, common.Hash{3}, common.Hash{2})
+	v.Observe(50, common.Hash{4}, common.Hash{2}) // not counted
+	require.NoError(v.Verify(100, common.Hash{1}, common.Hash{2}))
+
+	// Once the majority conflicts, every summary is refused.
+	v.Observe(100, common.Hash{4}, common.Hash{2})
+	require.ErrorIs(v.Verify(100, common.Hash{1}, common.Hash{2}), errCheckpointMajority)
+
+	_, err = NewCheckpointVerifier([]Checkpoint{trusted, {Height: 100, BlockHash: common.Hash{4}}})
+	require.ErrorIs(err, errDuplicateCheckpointSlot)
+}
+
+func TestCheckpointVerifierBounded(t *testing.T) {
+	require := require.New(t)
+
+	v, err := NewCheckpointVerifier([]Checkpoint{{Height: 100, BlockHash: common.Hash{1}}})
+	require.NoError(err)
+	for i := 0; i < maxObservedSummaries; i++ {
+		v.Observe(100, common.BigToHash(big.NewInt(int64(i+2))), common.Hash{})
+	}
+	require.Len(v.observed, maxObservedSummaries)
+
+	// Summaries past the bound are not counted.
+	v.Observe(100, common.Hash{1}, common.Hash{})
+	require.Len(v.observed, maxObservedSummaries)
+	require.ErrorIs(v.Verify(100, common.Hash{1}, common.Hash{}), errCheckpointMajority)
+}
+
+// blockClient serves [block] to the nodes of [serving].
+type blockClient struct {
+	Client
+
+	serving set.Set[ids.NodeID]
+	block   *types.Block
+}
+
+func (c *blockClient) ProbeBlock(_ context.Context, nodeID ids.NodeID, blockHash common.Hash, _ uint64) (*types.Block, error) {
+	if !c.serving.Contains(nodeID) || c.block.Hash() != blockHash {
+		return nil, errEmptyResponse
+	}
+	return c.block, nil
+}
+
+func TestTrustedNodeVerifier(t *testing.T) {
+	require := require.New(t)
+
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(100), Root: common.Hash{2}})
+	nodes := []ids.NodeID{{1}, {2}, {3}}
+	client := &blockClient{serving: set.Of[ids.NodeID](ids.NodeID{1}, ids.NodeID{2}), block: block}
+	v := NewTrustedNodeVerifier(client, nodes)
+
+	require.NoError(v.Verify(context.Background(), 100, block.Hash(), common.Hash{2}))
+
+	// Summaries conflicting with the block served by the trusted nodes, or
+	// which trusted nodes do not serve, are refused.
+	require.ErrorIs(v.Verify(context.Background(), 100, block.Hash(), common.Hash{3}), errTrustedNodesUnconfirmed)
+	require.ErrorIs(v.Verify(context.Background(), 101, block.Hash(), common.Hash{2}), errTrustedNodesUnconfirmed)
+	require.ErrorIs(v.Verify(context.Background(), 100, common.Hash{1}, common.Hash{2}), errTrustedNodesUnconfirmed)
+
+	// A minority of serving nodes does not confirm a summary.
+	client.serving = set.Of[ids.NodeID](ids.NodeID{1})
+	require.ErrorIs(v.Verify(context.Background(), 100, block.Hash(), common.Hash{2}), errTrustedNodesUnconfirmed)
+}
+
+func TestReadSignedCheckpoints(t *testing.T) {
+	require := require.New(t)
+
+	key, err := crypto.GenerateKey()
+	require.NoError(err)
+	signer := crypto.PubkeyToAddress(key.PublicKey)
+
+	checkpoint := Checkpoint{Height: 16384, BlockHash: common.Hash{1}}
+	sig, err := crypto.Sign(checkpoint.Hash().Bytes(), key)
+	require.NoError(err)
+
+	feedBytes, err := json.Marshal([]SignedCheckpoint{{Checkpoint: checkpoint, Signature: sig}})
+	require.NoError(err)
+	path := filepath.Join(t.TempDir(), "checkpoints.json")
+	require.NoError(os.WriteFile(path, feedBytes, 0o600))
+
+	checkpoints, err := ReadSignedCheckpoints(path, []common.Address{signer})
+	require.NoError(err)
+	require.Equal([]Checkpoint{checkpoint}, checkpoints)
+
+	_, err = ReadSignedCheckpoints(path, []common.Address{{1}})
+	require.ErrorIs(err, errUntrustedCheckpointSig)
+
+	_, err = ReadSignedCheckpoints(path, nil)
+	require.ErrorIs(err, errNoCheckpointSigners)
+}
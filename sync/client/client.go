@@ -8,6 +8,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -23,8 +24,10 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
 
-	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/shubhamdubey02/coreth/core"
 	"github.com/shubhamdubey02/coreth/core/rawdb"
+	"github.com/shubhamdubey02/coreth/core/txpool"
 	"github.com/shubhamdubey02/coreth/core/types"
 	"github.com/shubhamdubey02/coreth/peer"
 	"github.com/shubhamdubey02/coreth/plugin/evm/message"
@@ -51,6 +54,7 @@ var (
 	errUnmarshalResponse      = errors.New("failed to unmarshal response")
 	errInvalidCodeResponseLen = errors.New("number of code bytes in response does not match requested hashes")
 	errMaxCodeSizeExceeded    = errors.New("max code size exceeded")
+	errInvalidSidecarsLen     = errors.New("number of blob sidecars in response does not match blob transactions")
 )
 var _ Client = &client{}
 
@@ -67,6 +71,32 @@ type Client interface {
 
 	// GetCode synchronously retrieves code associated with the given hashes
 	GetCode(ctx context.Context, hashes []common.Hash) ([][]byte, error)
+
+	// GetCapabilities retrieves the sync features advertised by [nodeID], so
+	// that callers can pick peers and protocols without probing. Returns an
+	// error if the peer does not answer, as is the case for peers predating
+	// the handshake.
+	GetCapabilities(ctx context.Context, nodeID ids.NodeID) (message.CapabilitiesResponse, error)
+
+	// GetBlobSidecars synchronously retrieves the sidecars of the blob
+	// transactions of [block], in the order of the transactions
+	GetBlobSidecars(ctx context.Context, block *types.Block) ([]*types.BlobTxSidecar, error)
+
+	// GetReceipts synchronously retrieves the receipts of [block], verified
+	// against the receipt root of its header
+	GetReceipts(ctx context.Context, block *types.Block) (types.Receipts, error)
+
+	// ProbeLeafs sends the given request once to [nodeID], without retrying,
+	// and returns an error unless the response, including its range proof, is
+	// valid. Used to check which peers serve a state root.
+	ProbeLeafs(ctx context.Context, nodeID ids.NodeID, request message.LeafsRequest) error
+
+	// ProbeBlock requests the block [blockHash] at [height] once from
+	// [nodeID], without retrying. Used to confirm a block with a given peer.
+	ProbeBlock(ctx context.Context, nodeID ids.NodeID, blockHash common.Hash, height uint64) (*types.Block, error)
+
+	// Peers returns the peers state sync requests are sent to.
+	Peers() []ids.NodeID
 }
 
 // parseResponseFn parses given response bytes in context of specified request
@@ -82,6 +112,11 @@ type client struct {
 	stateSyncNodeIdx uint32
 	stats            stats.ClientSyncerStats
 	blockParser      EthBlockParser
+	maxCodeSize      int
+
+	// capabilities caches the capabilities advertised by each peer
+	capabilitiesLock sync.Mutex
+	capabilities     map[ids.NodeID]message.CapabilitiesResponse
 }
 
 type ClientConfig struct {
@@ -90,6 +125,9 @@ type ClientConfig struct {
 	Stats            stats.ClientSyncerStats
 	StateSyncNodeIDs []ids.NodeID
 	BlockParser      EthBlockParser
+	// MaxCodeSize is the largest contract code accepted from peers, which
+	// defaults to [params.MaxCodeSize].
+	MaxCodeSize int
 }
 
 type EthBlockParser interface {
@@ -97,12 +135,18 @@ type EthBlockParser interface {
 }
 
 func NewClient(config *ClientConfig) *client {
+	maxCodeSize := config.MaxCodeSize
+	if maxCodeSize == 0 {
+		maxCodeSize = params.MaxCodeSize
+	}
 	return &client{
+		maxCodeSize:    maxCodeSize,
 		networkClient:  config.NetworkClient,
 		codec:          config.Codec,
 		stats:          config.Stats,
 		stateSyncNodes: config.StateSyncNodeIDs,
 		blockParser:    config.BlockParser,
+		capabilities:   make(map[ids.NodeID]message.CapabilitiesResponse),
 	}
 }
 
@@ -120,6 +164,60 @@ func (c *client) GetLeafs(ctx context.Context, req message.LeafsRequest) (messag
 	return data.(message.LeafsResponse), nil
 }
 
+// ProbeLeafs sends [req] to [nodeID] and returns an error if its response is
+// invalid. Invalid responses are tracked against the peer as failed requests
+// are, but the request is not retried.
+func (c *client) ProbeLeafs(ctx context.Context, nodeID ids.NodeID, req message.LeafsRequest) error {
+	_, err := c.probe(ctx, nodeID, req, parseLeafsResponse)
+	return err
+}
+
+func (c *client) ProbeBlock(ctx context.Context, nodeID ids.NodeID, blockHash common.Hash, height uint64) (*types.Block, error) {
+	req := message.BlockRequest{
+		Hash:    blockHash,
+		Height:  height,
+		Parents: 1,
+	}
+	data, err := c.probe(ctx, nodeID, req, c.parseBlocks)
+	if err != nil {
+		return nil, err
+	}
+	return data.(types.Blocks)[0], nil
+}
+
+// probe sends [req] once to [nodeID] and returns the response parsed by
+// [parseFn], tracking the bandwidth of [nodeID].
+func (c *client) probe(ctx context.Context, nodeID ids.NodeID, req message.Request, parseFn parseResponseFn) (interface{}, error) {
+	requestBytes, err := message.RequestToBytes(c.codec, req)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	response, err := c.networkClient.SendAppRequest(ctx, nodeID, requestBytes)
+	if err != nil {
+		c.networkClient.TrackBandwidth(nodeID, 0)
+		return nil, err
+	}
+	data, _, err := parseFn(c.codec, req, response)
+	if err != nil {
+		c.networkClient.TrackBandwidth(nodeID, 0)
+		return nil, err
+	}
+	bandwidth := float64(len(response)) / (time.Since(start).Seconds() + epsilon)
+	c.networkClient.TrackBandwidth(nodeID, bandwidth)
+	return data, nil
+}
+
+// Peers returns the configured state sync nodes, or the connected peers
+// running at least [StateSyncVersion] if none are configured.
+func (c *client) Peers() []ids.NodeID {
+	if len(c.stateSyncNodes) != 0 {
+		return c.stateSyncNodes
+	}
+	return c.networkClient.Peers(StateSyncVersion)
+}
+
 // parseLeafsResponse validates given object as message.LeafsResponse
 // assumes reqIntf is of type message.LeafsRequest
 // returns a non-nil error if the request should be retried
@@ -147,17 +245,35 @@ func parseLeafsResponse(codec codec.Manager, reqIntf message.Request, data []byt
 		return nil, 0, fmt.Errorf("empty key response must include merkle proof")
 	}
 
-	var proof ethdb.Database
-	// Populate proof when ProofVals are present in the response. Its ok to pass it as nil to the trie.VerifyRangeProof
-	// function as it will assert that all the leaves belonging to the specified root are present.
-	if len(leafsResponse.ProofVals) > 0 {
-		proof = rawdb.NewMemoryDatabase()
-		defer proof.Close()
-		for _, proofVal := range leafsResponse.ProofVals {
-			proofKey := crypto.Keccak256(proofVal)
-			if err := proof.Put(proofKey, proofVal); err != nil {
-				return nil, 0, err
-			}
+	if len(leafsResponse.Keys) != len(leafsResponse.Vals) {
+		return nil, 0, fmt.Errorf("%s due to inconsistent proof data, keys: %d, values: %d", errInvalidRangeProof, len(leafsResponse.Keys), len(leafsResponse.Vals))
+	}
+
+	// Check the leafs as they are read, before the proof is assembled, so that
+	// malformed responses are rejected early. A response without a proof must
+	// hold every leaf of the trie, so its leafs are hashed as they are read and
+	// the root is checked without further processing.
+	noProof := len(leafsResponse.ProofVals) == 0
+	verifier := newLeafsVerifier(leafsRequest.Start, noProof)
+	for i, key := range leafsResponse.Keys {
+		if err := verifier.add(key, leafsResponse.Vals[i]); err != nil {
+			return nil, 0, fmt.Errorf("%s due to %w", errInvalidRangeProof, err)
+		}
+	}
+	if noProof {
+		if err := verifier.verifyRoot(leafsRequest.Root); err != nil {
+			return nil, 0, fmt.Errorf("%s due to %w", errInvalidRangeProof, err)
+		}
+		leafsResponse.More = false
+		return leafsResponse, len(leafsResponse.Keys), nil
+	}
+
+	proof := rawdb.NewMemoryDatabase()
+	defer proof.Close()
+	for _, proofVal := range leafsResponse.ProofVals {
+		proofKey := crypto.Keccak256(proofVal)
+		if err := proof.Put(proofKey, proofVal); err != nil {
+			return nil, 0, err
 		}
 	}
 
@@ -172,7 +288,6 @@ func parseLeafsResponse(codec codec.Manager, reqIntf message.Request, data []byt
 
 	// VerifyRangeProof verifies that the key-value pairs included in [leafResponse] are all of the keys within the range from start
 	// to the last key returned.
-	// Also ensures the keys are in monotonically increasing order
 	more, err := trie.VerifyRangeProof(leafsRequest.Root, firstKey, leafsResponse.Keys, leafsResponse.Vals, proof)
 	if err != nil {
 		return nil, 0, fmt.Errorf("%s due to %w", errInvalidRangeProof, err)
@@ -243,7 +358,7 @@ func (c *client) parseBlocks(codec codec.Manager, req message.Request, data []by
 func (c *client) GetCode(ctx context.Context, hashes []common.Hash) ([][]byte, error) {
 	req := message.NewCodeRequest(hashes)
 
-	data, err := c.get(ctx, req, parseCode)
+	data, err := c.get(ctx, req, parseCode(c.maxCodeSize))
 	if err != nil {
 		return nil, fmt.Errorf("could not get code (%s): %w", req, err)
 	}
@@ -251,34 +366,164 @@ func (c *client) GetCode(ctx context.Context, hashes []common.Hash) ([][]byte, e
 	return data.([][]byte), nil
 }
 
-// parseCode validates given object as a code object
+// GetCapabilities requests the capabilities of [nodeID] once, caching them
+// for later calls.
+func (c *client) GetCapabilities(ctx context.Context, nodeID ids.NodeID) (message.CapabilitiesResponse, error) {
+	c.capabilitiesLock.Lock()
+	capabilities, ok := c.capabilities[nodeID]
+	c.capabilitiesLock.Unlock()
+	if ok {
+		return capabilities, nil
+	}
+
+	requestBytes, err := message.RequestToBytes(c.codec, message.CapabilitiesRequest{})
+	if err != nil {
+		return message.CapabilitiesResponse{}, err
+	}
+	response, err := c.networkClient.SendAppRequest(ctx, nodeID, requestBytes)
+	if err != nil {
+		return message.CapabilitiesResponse{}, fmt.Errorf("could not get capabilities of %s: %w", nodeID, err)
+	}
+	if len(response) == 0 {
+		return message.CapabilitiesResponse{}, fmt.Errorf("could not get capabilities of %s: %w", nodeID, errEmptyResponse)
+	}
+	if _, err := c.codec.Unmarshal(response, &capabilities); err != nil {
+		return message.CapabilitiesResponse{}, fmt.Errorf("%s: %w", errUnmarshalResponse, err)
+	}
+
+	c.capabilitiesLock.Lock()
+	c.capabilities[nodeID] = capabilities
+	c.capabilitiesLock.Unlock()
+	return capabilities, nil
+}
+
+// parseCode returns a parser validating given object as a code object with
+// no code larger than [maxCodeSize]
 // assumes req is of type message.CodeRequest
-// returns a non-nil error if the request should be retried
-func parseCode(codec codec.Manager, req message.Request, data []byte) (interface{}, int, error) {
-	var response message.CodeResponse
-	if _, err := codec.Unmarshal(data, &response); err != nil {
-		return nil, 0, err
+// the parser returns a non-nil error if the request should be retried
+func parseCode(maxCodeSize int) parseResponseFn {
+	return func(codec codec.Manager, req message.Request, data []byte) (interface{}, int, error) {
+		var response message.CodeResponse
+		if _, err := codec.Unmarshal(data, &response); err != nil {
+			return nil, 0, err
+		}
+
+		codeRequest := req.(message.CodeRequest)
+		if len(response.Data) != len(codeRequest.Hashes) {
+			return nil, 0, fmt.Errorf("%w (got %d) (requested %d)", errInvalidCodeResponseLen, len(response.Data), len(codeRequest.Hashes))
+		}
+
+		totalBytes := 0
+		for i, code := range response.Data {
+			if len(code) > maxCodeSize {
+				return nil, 0, fmt.Errorf("%w: (hash %s) (size %d)", errMaxCodeSizeExceeded, codeRequest.Hashes[i], len(code))
+			}
+
+			hash := crypto.Keccak256Hash(code)
+			if hash != codeRequest.Hashes[i] {
+				return nil, 0, fmt.Errorf("%w for code at index %d: (got %v) (expected %v)", errHashMismatch, i, hash, codeRequest.Hashes[i])
+			}
+			totalBytes += len(code)
+		}
+
+		return response.Data, totalBytes, nil
+	}
+}
+
+func (c *client) GetBlobSidecars(ctx context.Context, block *types.Block) ([]*types.BlobTxSidecar, error) {
+	req := message.BlobSidecarsRequest{
+		Hash:   block.Hash(),
+		Height: block.NumberU64(),
 	}
 
-	codeRequest := req.(message.CodeRequest)
-	if len(response.Data) != len(codeRequest.Hashes) {
-		return nil, 0, fmt.Errorf("%w (got %d) (requested %d)", errInvalidCodeResponseLen, len(response.Data), len(codeRequest.Hashes))
+	data, err := c.get(ctx, req, parseBlobSidecars(block))
+	if err != nil {
+		return nil, fmt.Errorf("could not get blob sidecars (%s) due to %w", block.Hash(), err)
 	}
 
-	totalBytes := 0
-	for i, code := range response.Data {
-		if len(code) > params.MaxCodeSize {
-			return nil, 0, fmt.Errorf("%w: (hash %s) (size %d)", errMaxCodeSizeExceeded, codeRequest.Hashes[i], len(code))
+	return data.([]*types.BlobTxSidecar), nil
+}
+
+// parseBlobSidecars returns a parser validating given object as the blob
+// sidecars of the blob transactions of [block]
+// assumes req is of type message.BlobSidecarsRequest
+// the parser returns a non-nil error if the request should be retried
+func parseBlobSidecars(block *types.Block) parseResponseFn {
+	return func(codec codec.Manager, req message.Request, data []byte) (interface{}, int, error) {
+		var response message.BlobSidecarsResponse
+		if _, err := codec.Unmarshal(data, &response); err != nil {
+			return nil, 0, fmt.Errorf("%s: %w", errUnmarshalResponse, err)
 		}
 
-		hash := crypto.Keccak256Hash(code)
-		if hash != codeRequest.Hashes[i] {
-			return nil, 0, fmt.Errorf("%w for code at index %d: (got %v) (expected %v)", errHashMismatch, i, hash, codeRequest.Hashes[i])
+		var blobTxs types.Transactions
+		for _, tx := range block.Transactions() {
+			if tx.Type() == types.BlobTxType {
+				blobTxs = append(blobTxs, tx)
+			}
 		}
-		totalBytes += len(code)
+		if len(response.Sidecars) != len(blobTxs) {
+			return nil, 0, fmt.Errorf("%w (got %d) (expected %d)", errInvalidSidecarsLen, len(response.Sidecars), len(blobTxs))
+		}
+
+		sidecars := make([]*types.BlobTxSidecar, len(response.Sidecars))
+		for i, sidecarBytes := range response.Sidecars {
+			sidecar := new(types.BlobTxSidecar)
+			if err := rlp.DecodeBytes(sidecarBytes, sidecar); err != nil {
+				return nil, 0, fmt.Errorf("%s: %w", errUnmarshalResponse, err)
+			}
+			if err := txpool.ValidateBlobSidecar(blobTxs[i].BlobHashes(), sidecar); err != nil {
+				return nil, 0, fmt.Errorf("invalid sidecar of transaction %s: %w", blobTxs[i].Hash(), err)
+			}
+			sidecars[i] = sidecar
+		}
+
+		return sidecars, len(sidecars), nil
 	}
+}
+
+func (c *client) GetReceipts(ctx context.Context, block *types.Block) (types.Receipts, error) {
+	req := message.BlockDataRequest{
+		Hash:    block.Hash(),
+		Height:  block.NumberU64(),
+		Parents: 1,
+		Flags:   message.HeadersOnly | message.WithReceipts,
+	}
+
+	data, err := c.get(ctx, req, parseReceipts(block))
+	if err != nil {
+		return nil, fmt.Errorf("could not get receipts (%s) due to %w", block.Hash(), err)
+	}
+
+	return data.(types.Receipts), nil
+}
+
+// parseReceipts returns a parser validating given object as a
+// message.BlockDataResponse holding the receipts of [block]
+// assumes req is of type message.BlockDataRequest
+// the parser returns a non-nil error if the request should be retried
+func parseReceipts(block *types.Block) parseResponseFn {
+	return func(codec codec.Manager, req message.Request, data []byte) (interface{}, int, error) {
+		var response message.BlockDataResponse
+		if _, err := codec.Unmarshal(data, &response); err != nil {
+			return nil, 0, fmt.Errorf("%s: %w", errUnmarshalResponse, err)
+		}
+		if len(response.Receipts) == 0 {
+			return nil, 0, errEmptyResponse
+		}
+		if len(response.Receipts) > 1 {
+			return nil, 0, errTooManyBlocks
+		}
 
-	return response.Data, totalBytes, nil
+		var receipts types.Receipts
+		if err := rlp.DecodeBytes(response.Receipts[0], &receipts); err != nil {
+			return nil, 0, fmt.Errorf("%s: %w", errUnmarshalResponse, err)
+		}
+		if err := core.ValidateReceipts(block, receipts); err != nil {
+			return nil, 0, fmt.Errorf("invalid receipts of block %s: %w", block.Hash(), err)
+		}
+
+		return receipts, len(receipts), nil
+	}
 }
 
 // get submits given request and blockingly returns with either a parsed response object or an error
@@ -320,16 +565,7 @@ func (c *client) get(ctx context.Context, request message.Request, parseFn parse
 			nodeID   ids.NodeID
 			start    time.Time = time.Now()
 		)
-		if len(c.stateSyncNodes) == 0 {
-			response, nodeID, err = c.networkClient.SendAppRequestAny(ctx, StateSyncVersion, requestBytes)
-		} else {
-			// get the next nodeID using the nodeIdx offset. If we're out of nodes, loop back to 0
-			// we do this every attempt to ensure we get a different node each time if possible.
-			nodeIdx := atomic.AddUint32(&c.stateSyncNodeIdx, 1)
-			nodeID = c.stateSyncNodes[nodeIdx%uint32(len(c.stateSyncNodes))]
-
-			response, err = c.networkClient.SendAppRequest(ctx, nodeID, requestBytes)
-		}
+		response, nodeID, err = c.send(ctx, requestBytes)
 		metric.UpdateRequestLatency(time.Since(start))
 
 		if err != nil {
@@ -362,3 +598,20 @@ func (c *client) get(ctx context.Context, request message.Request, parseFn parse
 		}
 	}
 }
+
+// send sends [requestBytes] to an arbitrary peer, or to the next of the
+// configured state sync nodes if there are any, returning the response and
+// the peer it was sent to.
+func (c *client) send(ctx context.Context, requestBytes []byte) ([]byte, ids.NodeID, error) {
+	if len(c.stateSyncNodes) == 0 {
+		return c.networkClient.SendAppRequestAny(ctx, StateSyncVersion, requestBytes)
+	}
+
+	// get the next nodeID using the nodeIdx offset. If we're out of nodes, loop back to 0
+	// we do this every attempt to ensure we get a different node each time if possible.
+	nodeIdx := atomic.AddUint32(&c.stateSyncNodeIdx, 1)
+	nodeID := c.stateSyncNodes[nodeIdx%uint32(len(c.stateSyncNodes))]
+
+	response, err := c.networkClient.SendAppRequest(ctx, nodeID, requestBytes)
+	return response, nodeID, err
+}
@@ -163,7 +163,7 @@ func TestGetBlocks(t *testing.T) {
 		BlockParser:      mockBlockParser,
 	})
 
-	blocksRequestHandler := handlers.NewBlockRequestHandler(buildGetter(blocks), message.Codec, handlerstats.NewNoopHandlerStats())
+	blocksRequestHandler := handlers.NewBlockRequestHandler(buildGetter(blocks), nil, message.Codec, handlerstats.NewNoopHandlerStats())
 
 	// encodeBlockSlice takes a slice of blocks that are ordered in increasing height order
 	// and returns a slice of byte slices with those blocks encoded in reverse order
@@ -892,3 +892,42 @@ func TestStateSyncNodes(t *testing.T) {
 	assert.Contains(t, mockNetClient.nodesRequested, stateSyncNodes[2])
 	assert.Contains(t, mockNetClient.nodesRequested, stateSyncNodes[3])
 }
+
+func TestGetCapabilities(t *testing.T) {
+	mockNetClient := &mockNetwork{}
+	client := NewClient(&ClientConfig{
+		NetworkClient: mockNetClient,
+		Codec:         message.Codec,
+		Stats:         clientstats.NewNoOpStats(),
+		BlockParser:   mockBlockParser,
+	})
+
+	capabilities := message.CapabilitiesResponse{
+		Features:       message.FeatureAtomicProofs,
+		CommitInterval: 4096,
+		MaxCodeHashes:  message.MaxCodeHashesPerRequest,
+	}
+	handler := handlers.NewCapabilitiesRequestHandler(capabilities, message.Codec)
+	response, err := handler.OnCapabilitiesRequest(context.Background(), ids.GenerateTestNodeID(), 1, message.CapabilitiesRequest{})
+	assert.NoError(t, err)
+	mockNetClient.mockResponse(1, nil, response)
+
+	nodeID := ids.GenerateTestNodeID()
+	got, err := client.GetCapabilities(context.Background(), nodeID)
+	assert.NoError(t, err)
+	assert.Equal(t, capabilities, got)
+	assert.True(t, got.Supports(message.FeatureAtomicProofs))
+	assert.False(t, got.Supports(message.FeatureAtomicProofs|message.FeatureBlockData))
+	assert.Equal(t, []ids.NodeID{nodeID}, mockNetClient.nodesRequested)
+
+	// Capabilities are cached per peer.
+	got, err = client.GetCapabilities(context.Background(), nodeID)
+	assert.NoError(t, err)
+	assert.Equal(t, capabilities, got)
+	assert.Equal(t, uint(1), mockNetClient.numCalls)
+
+	// Peers predating the handshake do not answer.
+	mockNetClient.mockResponse(1, nil, nil)
+	_, err = client.GetCapabilities(context.Background(), ids.GenerateTestNodeID())
+	assert.ErrorIs(t, err, errEmptyResponse)
+}
@@ -16,6 +16,10 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
+// estimatedLeafSize is the number of bytes reserved from the memory budget
+// per leaf requested, covering the key, a typical value and proof overhead.
+const estimatedLeafSize = 128
+
 var (
 	errFailedToFetchLeafs = errors.New("failed to fetch leafs")
 )
@@ -41,6 +45,7 @@ type CallbackLeafSyncer struct {
 	done        chan error
 	tasks       <-chan LeafSyncTask
 	requestSize uint16
+	budget      *MemoryBudget
 }
 
 type LeafClient interface {
@@ -50,12 +55,16 @@ type LeafClient interface {
 }
 
 // NewCallbackLeafSyncer creates a new syncer object to perform leaf sync of tries.
-func NewCallbackLeafSyncer(client LeafClient, tasks <-chan LeafSyncTask, requestSize uint16) *CallbackLeafSyncer {
+// Memory for each response is reserved from [budget] before the request is
+// sent, so requests are held back while the budget is exhausted. [budget] may
+// be nil.
+func NewCallbackLeafSyncer(client LeafClient, tasks <-chan LeafSyncTask, requestSize uint16, budget *MemoryBudget) *CallbackLeafSyncer {
 	return &CallbackLeafSyncer{
 		client:      client,
 		done:        make(chan error),
 		tasks:       tasks,
 		requestSize: requestSize,
+		budget:      budget,
 	}
 }
 
@@ -97,6 +106,12 @@ func (c *CallbackLeafSyncer) syncTask(ctx context.Context, task LeafSyncTask) er
 			return err
 		}
 
+		// Reserve memory for the response while the request is in flight.
+		// Tasks account for any leafs they keep buffered after [OnLeafs].
+		reserved := int(c.requestSize) * estimatedLeafSize
+		if err := c.budget.Acquire(ctx, reserved); err != nil {
+			return err
+		}
 		leafsResponse, err := c.client.GetLeafs(ctx, message.LeafsRequest{
 			Root:     root,
 			Account:  task.Account(),
@@ -104,6 +119,7 @@ func (c *CallbackLeafSyncer) syncTask(ctx context.Context, task LeafSyncTask) er
 			Limit:    c.requestSize,
 			NodeType: task.NodeType(),
 		})
+		c.budget.Release(reserved)
 		if err != nil {
 			return fmt.Errorf("%s: %w", errFailedToFetchLeafs, err)
 		}
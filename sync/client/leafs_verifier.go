@@ -0,0 +1,76 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package statesyncclient
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shubhamdubey02/coreth/trie"
+)
+
+var (
+	errLeafsNotIncreasing = errors.New("leafs are not monotonically increasing")
+	errLeafBeforeStart    = errors.New("leaf precedes the requested start")
+	errLeafKeyLength      = errors.New("leaf key length differs from the first leaf")
+	errLeafDeletion       = errors.New("leaf has an empty value")
+)
+
+// leafsVerifier checks the leafs of a response one at a time, in the order
+// they were sent, so that malformed responses are rejected at the first
+// offending leaf rather than after the whole range is assembled for proof
+// verification.
+//
+// Leafs are also hashed into a stack trie, so that a response without a proof,
+// which must hold every leaf of the trie, is verified without buffering the
+// range a second time.
+type leafsVerifier struct {
+	start   []byte
+	hasher  *trie.StackTrie
+	lastKey []byte
+	count   int
+}
+
+// newLeafsVerifier returns a verifier of the leafs following [start]. Leafs
+// are hashed only if [hash] is set.
+func newLeafsVerifier(start []byte, hash bool) *leafsVerifier {
+	v := &leafsVerifier{start: start}
+	if hash {
+		v.hasher = trie.NewStackTrie(nil)
+	}
+	return v
+}
+
+// add verifies the leaf [key] with [val] follows the leafs added before it.
+func (v *leafsVerifier) add(key, val []byte) error {
+	switch {
+	case len(val) == 0:
+		return fmt.Errorf("%w: leaf %d", errLeafDeletion, v.count)
+	case v.lastKey != nil && len(key) != len(v.lastKey):
+		return fmt.Errorf("%w: leaf %d has length %d, expected %d", errLeafKeyLength, v.count, len(key), len(v.lastKey))
+	case v.lastKey != nil && bytes.Compare(key, v.lastKey) <= 0:
+		return fmt.Errorf("%w: leaf %d (%x) follows %x", errLeafsNotIncreasing, v.count, key, v.lastKey)
+	case v.lastKey == nil && len(v.start) > 0 && bytes.Compare(key, v.start) < 0:
+		return fmt.Errorf("%w: leaf %x precedes %x", errLeafBeforeStart, key, v.start)
+	}
+	if v.hasher != nil {
+		if err := v.hasher.Update(key, val); err != nil {
+			return err
+		}
+	}
+	v.lastKey = key
+	v.count++
+	return nil
+}
+
+// verifyRoot returns an error if the leafs added to a hashing verifier are
+// not every leaf of the trie [root].
+func (v *leafsVerifier) verifyRoot(root common.Hash) error {
+	if have := v.hasher.Hash(); have != root {
+		return fmt.Errorf("invalid proof, want hash %x, got %x", root, have)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package statesyncclient
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shubhamdubey02/coreth/trie"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeafsVerifier(t *testing.T) {
+	keys := [][]byte{{0x01, 0x00}, {0x01, 0x01}, {0x02, 0x00}}
+	vals := [][]byte{{0xaa}, {0xbb}, {0xcc}}
+
+	tests := map[string]struct {
+		start   []byte
+		keys    [][]byte
+		vals    [][]byte
+		wantErr error
+	}{
+		"valid": {
+			keys: keys,
+			vals: vals,
+		},
+		"decreasing": {
+			keys:    [][]byte{keys[1], keys[0]},
+			vals:    vals[:2],
+			wantErr: errLeafsNotIncreasing,
+		},
+		"duplicate": {
+			keys:    [][]byte{keys[0], keys[0]},
+			vals:    vals[:2],
+			wantErr: errLeafsNotIncreasing,
+		},
+		"before start": {
+			start:   keys[1],
+			keys:    keys,
+			vals:    vals,
+			wantErr: errLeafBeforeStart,
+		},
+		"key length": {
+			keys:    [][]byte{keys[0], {0x02}},
+			vals:    vals[:2],
+			wantErr: errLeafKeyLength,
+		},
+		"deletion": {
+			keys:    keys[:2],
+			vals:    [][]byte{vals[0], nil},
+			wantErr: errLeafDeletion,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			v := newLeafsVerifier(test.start, false)
+			var err error
+			for i, key := range test.keys {
+				if err = v.add(key, test.vals[i]); err != nil {
+					break
+				}
+			}
+			require.True(t, errors.Is(err, test.wantErr), "got error %v, want %v", err, test.wantErr)
+		})
+	}
+}
+
+func TestLeafsVerifierRoot(t *testing.T) {
+	require := require.New(t)
+
+	keys := [][]byte{common.HexToHash("0x01").Bytes(), common.HexToHash("0x02").Bytes()}
+	vals := [][]byte{{0xaa}, {0xbb}}
+	tr := trie.NewStackTrie(nil)
+	for i, key := range keys {
+		require.NoError(tr.Update(key, vals[i]))
+	}
+	root := tr.Hash()
+
+	v := newLeafsVerifier(nil, true)
+	for i, key := range keys {
+		require.NoError(v.add(key, vals[i]))
+	}
+	require.NoError(v.verifyRoot(root))
+
+	// A missing leaf changes the root.
+	v = newLeafsVerifier(nil, true)
+	require.NoError(v.add(keys[0], vals[0]))
+	require.Error(v.verifyRoot(root))
+}
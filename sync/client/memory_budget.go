@@ -0,0 +1,82 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package statesyncclient
+
+import (
+	"context"
+	"sync/atomic"
+
+	"golang.org/x/sync/semaphore"
+)
+
+// MemoryBudget bounds the number of bytes buffered in memory while syncing.
+// Reservations larger than the budget are capped to the budget, so a single
+// reservation can always be satisfied once all others are released.
+// A nil *MemoryBudget imposes no limit.
+type MemoryBudget struct {
+	limit int64
+	sem   *semaphore.Weighted
+	used  atomic.Int64
+}
+
+// NewMemoryBudget returns a budget of [limit] bytes, or nil if [limit] is 0.
+func NewMemoryBudget(limit uint64) *MemoryBudget {
+	if limit == 0 {
+		return nil
+	}
+	return &MemoryBudget{
+		limit: int64(limit),
+		sem:   semaphore.NewWeighted(int64(limit)),
+	}
+}
+
+// Acquire reserves [size] bytes, blocking until they are available or [ctx]
+// is done.
+func (b *MemoryBudget) Acquire(ctx context.Context, size int) error {
+	if b == nil || size <= 0 {
+		return nil
+	}
+	if err := b.sem.Acquire(ctx, b.cap(size)); err != nil {
+		return err
+	}
+	b.used.Add(b.cap(size))
+	return nil
+}
+
+// TryAcquire reserves [size] bytes without blocking and reports whether it
+// succeeded.
+func (b *MemoryBudget) TryAcquire(size int) bool {
+	if b == nil || size <= 0 {
+		return true
+	}
+	if !b.sem.TryAcquire(b.cap(size)) {
+		return false
+	}
+	b.used.Add(b.cap(size))
+	return true
+}
+
+// Release returns [size] bytes previously reserved with Acquire or TryAcquire.
+func (b *MemoryBudget) Release(size int) {
+	if b == nil || size <= 0 {
+		return
+	}
+	b.used.Add(-b.cap(size))
+	b.sem.Release(b.cap(size))
+}
+
+// Used returns the number of bytes currently reserved.
+func (b *MemoryBudget) Used() uint64 {
+	if b == nil {
+		return 0
+	}
+	return uint64(b.used.Load())
+}
+
+func (b *MemoryBudget) cap(size int) int64 {
+	if int64(size) > b.limit {
+		return b.limit
+	}
+	return int64(size)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/params"
 	"github.com/shubhamdubey02/coreth/plugin/evm/message"
 	"github.com/shubhamdubey02/coreth/sync/handlers"
 	"github.com/shubhamdubey02/cryftgo/codec"
@@ -40,6 +41,12 @@ type MockClient struct {
 	// GetBlocksIntercept is called on every GetBlocks request if set to a non-nil callback.
 	// The returned response will be returned by MockClient to the caller.
 	GetBlocksIntercept func(blockReq message.BlockRequest, blocks types.Blocks) (types.Blocks, error)
+	// Capabilities is returned by GetCapabilities if set to a non-nil value.
+	Capabilities *message.CapabilitiesResponse
+	// BlobSidecarsHandler serves GetBlobSidecars requests if set to a non-nil value.
+	BlobSidecarsHandler *handlers.BlobSidecarsRequestHandler
+	// PeerIDs is returned by Peers if set to a non-nil value.
+	PeerIDs []ids.NodeID
 }
 
 func NewMockClient(
@@ -89,7 +96,7 @@ func (ml *MockClient) GetCode(ctx context.Context, hashes []common.Hash) ([][]by
 		return nil, err
 	}
 
-	codeBytesIntf, lenCode, err := parseCode(ml.codec, request, response)
+	codeBytesIntf, lenCode, err := parseCode(params.MaxCodeSize)(ml.codec, request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -138,6 +145,94 @@ func (ml *MockClient) BlocksReceived() int32 {
 	return atomic.LoadInt32(&ml.blocksReceived)
 }
 
+func (ml *MockClient) GetCapabilities(ctx context.Context, nodeID ids.NodeID) (message.CapabilitiesResponse, error) {
+	if ml.Capabilities == nil {
+		return message.CapabilitiesResponse{}, fmt.Errorf("no capabilities advertised by %s", nodeID)
+	}
+	return *ml.Capabilities, nil
+}
+
+func (ml *MockClient) GetBlobSidecars(ctx context.Context, block *types.Block) ([]*types.BlobTxSidecar, error) {
+	if ml.BlobSidecarsHandler == nil {
+		panic("no blob sidecars handler for mock client")
+	}
+	request := message.BlobSidecarsRequest{
+		Hash:   block.Hash(),
+		Height: block.NumberU64(),
+	}
+	response, err := ml.BlobSidecarsHandler.OnBlobSidecarsRequest(ctx, ids.GenerateTestNodeID(), 1, request)
+	if err != nil {
+		return nil, err
+	}
+
+	sidecars, _, err := parseBlobSidecars(block)(ml.codec, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return sidecars.([]*types.BlobTxSidecar), nil
+}
+
+func (ml *MockClient) GetReceipts(ctx context.Context, block *types.Block) (types.Receipts, error) {
+	if ml.blocksHandler == nil {
+		panic("no blocks handler for mock client")
+	}
+	request := message.BlockDataRequest{
+		Hash:    block.Hash(),
+		Height:  block.NumberU64(),
+		Parents: 1,
+		Flags:   message.HeadersOnly | message.WithReceipts,
+	}
+	response, err := ml.blocksHandler.OnBlockDataRequest(ctx, ids.GenerateTestNodeID(), 1, request)
+	if err != nil {
+		return nil, err
+	}
+
+	receipts, _, err := parseReceipts(block)(ml.codec, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return receipts.(types.Receipts), nil
+}
+
+// ProbeLeafs serves [request] from the leafs handler, as if by [nodeID].
+func (ml *MockClient) ProbeLeafs(ctx context.Context, nodeID ids.NodeID, request message.LeafsRequest) error {
+	response, err := ml.leafsHandler.OnLeafsRequest(ctx, nodeID, 1, request)
+	if err != nil {
+		return err
+	}
+	_, _, err = parseLeafsResponse(ml.codec, request, response)
+	return err
+}
+
+// ProbeBlock serves the block request from the blocks handler, as if by
+// [nodeID].
+func (ml *MockClient) ProbeBlock(ctx context.Context, nodeID ids.NodeID, blockHash common.Hash, height uint64) (*types.Block, error) {
+	request := message.BlockRequest{
+		Hash:    blockHash,
+		Height:  height,
+		Parents: 1,
+	}
+	response, err := ml.blocksHandler.OnBlockRequest(ctx, nodeID, 1, request)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &client{blockParser: mockBlockParser}
+	blocks, _, err := client.parseBlocks(ml.codec, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return blocks.(types.Blocks)[0], nil
+}
+
+// Peers returns [MockClient.PeerIDs], or a single generated peer if unset.
+func (ml *MockClient) Peers() []ids.NodeID {
+	if ml.PeerIDs == nil {
+		return []ids.NodeID{ids.GenerateTestNodeID()}
+	}
+	return ml.PeerIDs
+}
+
 type testBlockParser struct{}
 
 func (t *testBlockParser) ParseEthBlock(b []byte) (*types.Block, error) {
@@ -98,3 +98,5 @@ func (t *mockNetwork) mockResponses(callback func(), responses ...[]byte) {
 }
 
 func (t *mockNetwork) TrackBandwidth(ids.NodeID, float64) {}
+
+func (t *mockNetwork) Peers(*version.Application) []ids.NodeID { return nil }
@@ -77,17 +77,23 @@ func (m *messageMetric) UpdateRequestLatency(duration time.Duration) {
 type clientSyncerStats struct {
 	atomicTrieLeavesMetric,
 	stateTrieLeavesMetric,
+	namespaceTrieLeavesMetric,
 	codeRequestMetric,
-	blockRequestMetric MessageMetric
+	blockRequestMetric,
+	blockDataRequestMetric,
+	blobSidecarsRequestMetric MessageMetric
 }
 
 // NewClientSyncerStats returns stats for the client syncer
 func NewClientSyncerStats() ClientSyncerStats {
 	return &clientSyncerStats{
-		atomicTrieLeavesMetric: NewMessageMetric("sync_atomic_trie_leaves"),
-		stateTrieLeavesMetric:  NewMessageMetric("sync_state_trie_leaves"),
-		codeRequestMetric:      NewMessageMetric("sync_code"),
-		blockRequestMetric:     NewMessageMetric("sync_blocks"),
+		atomicTrieLeavesMetric:    NewMessageMetric("sync_atomic_trie_leaves"),
+		stateTrieLeavesMetric:     NewMessageMetric("sync_state_trie_leaves"),
+		namespaceTrieLeavesMetric: NewMessageMetric("sync_namespace_trie_leaves"),
+		codeRequestMetric:         NewMessageMetric("sync_code"),
+		blockRequestMetric:        NewMessageMetric("sync_blocks"),
+		blockDataRequestMetric:    NewMessageMetric("sync_block_data"),
+		blobSidecarsRequestMetric: NewMessageMetric("sync_blob_sidecars"),
 	}
 }
 
@@ -96,14 +102,20 @@ func (c *clientSyncerStats) GetMetric(msgIntf message.Request) (MessageMetric, e
 	switch msg := msgIntf.(type) {
 	case message.BlockRequest:
 		return c.blockRequestMetric, nil
+	case message.BlockDataRequest:
+		return c.blockDataRequestMetric, nil
 	case message.CodeRequest:
 		return c.codeRequestMetric, nil
+	case message.BlobSidecarsRequest:
+		return c.blobSidecarsRequestMetric, nil
 	case message.LeafsRequest:
-		switch msg.NodeType {
-		case message.StateTrieNode:
+		switch {
+		case msg.NodeType == message.StateTrieNode:
 			return c.stateTrieLeavesMetric, nil
-		case message.AtomicTrieNode:
+		case msg.NodeType == message.AtomicTrieNode:
 			return c.atomicTrieLeavesMetric, nil
+		case msg.NodeType >= message.FirstNamespaceNode:
+			return c.namespaceTrieLeavesMetric, nil
 		default:
 			return nil, fmt.Errorf("invalid leafs request for node type: %T", msg.NodeType)
 		}
@@ -0,0 +1,104 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/shubhamdubey02/cryftgo/codec"
+	"github.com/shubhamdubey02/cryftgo/ids"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/plugin/evm/message"
+	"github.com/shubhamdubey02/coreth/sync/handlers/stats"
+	"github.com/shubhamdubey02/coreth/trie"
+)
+
+// AtomicProofRequestHandler is a peer.RequestHandler for message.AtomicProofRequest
+// serving Merkle proofs of entries in the atomic trie
+type AtomicProofRequestHandler struct {
+	trieDB *trie.Database
+	codec  codec.Manager
+	stats  stats.AtomicProofRequestHandlerStats
+}
+
+func NewAtomicProofRequestHandler(trieDB *trie.Database, codec codec.Manager, stats stats.AtomicProofRequestHandlerStats) *AtomicProofRequestHandler {
+	return &AtomicProofRequestHandler{
+		trieDB: trieDB,
+		codec:  codec,
+		stats:  stats,
+	}
+}
+
+// OnAtomicProofRequest handles a request for proofs of the keys in
+// message.AtomicProofRequest against the requested atomic trie root.
+// Never returns error
+// Returns nothing if the request is invalid or the requested root is not found
+// Expects returned errors to be treated as FATAL
+// Assumes ctx is active
+func (a *AtomicProofRequestHandler) OnAtomicProofRequest(_ context.Context, nodeID ids.NodeID, requestID uint32, request message.AtomicProofRequest) ([]byte, error) {
+	startTime := time.Now()
+	a.stats.IncAtomicProofRequest()
+
+	// always report processing time metric
+	defer func() {
+		a.stats.UpdateAtomicProofRequestProcessingTime(time.Since(startTime))
+	}()
+
+	if request.Root == (common.Hash{}) || request.Root == types.EmptyRootHash ||
+		len(request.Keys) == 0 || len(request.Keys) > message.MaxAtomicProofKeysPerRequest {
+		log.Debug("invalid atomic proof request, dropping request", "nodeID", nodeID, "requestID", requestID, "numKeys", len(request.Keys))
+		a.stats.IncInvalidAtomicProofRequest()
+		return nil, nil
+	}
+	keyLength, _ := getKeyLength(message.AtomicTrieNode)
+	for _, key := range request.Keys {
+		if len(key) != keyLength {
+			log.Debug("invalid key length for atomic proof request, dropping request", "nodeID", nodeID, "requestID", requestID, "keyLen", len(key), "expected", keyLength)
+			a.stats.IncInvalidAtomicProofRequest()
+			return nil, nil
+		}
+	}
+
+	t, err := trie.New(trie.TrieID(request.Root), a.trieDB)
+	if err != nil {
+		log.Debug("error opening trie when processing request, dropping request", "nodeID", nodeID, "requestID", requestID, "root", request.Root, "err", err)
+		a.stats.IncAtomicProofMissingRoot()
+		return nil, nil
+	}
+
+	// Proofs of different keys share the nodes near the root, so they are
+	// written to a single database to return each node once.
+	var (
+		proof    = memorydb.New()
+		response = message.AtomicProofResponse{Vals: make([][]byte, len(request.Keys))}
+	)
+	for i, key := range request.Keys {
+		if response.Vals[i], err = t.Get(key); err != nil {
+			log.Debug("error reading atomic trie, dropping request", "nodeID", nodeID, "requestID", requestID, "root", request.Root, "err", err)
+			a.stats.IncAtomicProofMissingRoot()
+			return nil, nil
+		}
+		if err := t.Prove(key, proof); err != nil {
+			log.Debug("error generating atomic trie proof, dropping request", "nodeID", nodeID, "requestID", requestID, "root", request.Root, "err", err)
+			a.stats.IncAtomicProofMissingRoot()
+			return nil, nil
+		}
+	}
+	if response.ProofVals, err = iterateVals(proof); err != nil {
+		log.Error("failed to iterate atomic proof values, dropping request", "nodeID", nodeID, "requestID", requestID, "err", err)
+		return nil, nil
+	}
+
+	responseBytes, err := a.codec.Marshal(message.Version, response)
+	if err != nil {
+		log.Error("could not marshal AtomicProofResponse, dropping request", "nodeID", nodeID, "requestID", requestID, "request", request, "err", err)
+		return nil, nil
+	}
+	return responseBytes, nil
+}
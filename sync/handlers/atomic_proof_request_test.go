@@ -0,0 +1,75 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/shubhamdubey02/coreth/core/rawdb"
+	"github.com/shubhamdubey02/coreth/plugin/evm/message"
+	"github.com/shubhamdubey02/coreth/sync/handlers/stats"
+	"github.com/shubhamdubey02/coreth/sync/syncutils"
+	"github.com/shubhamdubey02/coreth/trie"
+	"github.com/shubhamdubey02/cryftgo/ids"
+	"github.com/shubhamdubey02/cryftgo/utils/wrappers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAtomicProofRequestHandler(t *testing.T) {
+	trieDB := trie.NewDatabase(rawdb.NewMemoryDatabase(), nil)
+	keyLength := wrappers.LongLen + common.HashLength
+	root, keys, vals := syncutils.GenerateTrie(t, trieDB, 100, keyLength)
+
+	mockHandlerStats := &stats.MockHandlerStats{}
+	handler := NewAtomicProofRequestHandler(trieDB, message.Codec, mockHandlerStats)
+
+	missingKey := make([]byte, keyLength)
+	request := message.AtomicProofRequest{
+		Root: root,
+		Keys: [][]byte{keys[3], keys[42], missingKey},
+	}
+	responseBytes, err := handler.OnAtomicProofRequest(context.Background(), ids.GenerateTestNodeID(), 1, request)
+	assert.NoError(t, err)
+
+	var response message.AtomicProofResponse
+	_, err = message.Codec.Unmarshal(responseBytes, &response)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{vals[3], vals[42], {}}, response.Vals)
+
+	proof := memorydb.New()
+	for _, proofVal := range response.ProofVals {
+		assert.NoError(t, proof.Put(crypto.Keccak256(proofVal), proofVal))
+	}
+	for i, key := range request.Keys {
+		val, err := trie.VerifyProof(root, key, proof)
+		assert.NoError(t, err)
+		assert.True(t, bytes.Equal(response.Vals[i], val))
+	}
+	assert.EqualValues(t, 1, mockHandlerStats.AtomicProofRequestCount)
+
+	// Invalid requests are dropped
+	for _, request := range []message.AtomicProofRequest{
+		{Root: root},
+		{Root: root, Keys: [][]byte{keys[0][:common.HashLength]}},
+		{Root: root, Keys: make([][]byte, message.MaxAtomicProofKeysPerRequest+1)},
+	} {
+		responseBytes, err := handler.OnAtomicProofRequest(context.Background(), ids.GenerateTestNodeID(), 1, request)
+		assert.NoError(t, err)
+		assert.Nil(t, responseBytes)
+	}
+	assert.EqualValues(t, 3, mockHandlerStats.InvalidAtomicProofRequestCount)
+
+	responseBytes, err = handler.OnAtomicProofRequest(context.Background(), ids.GenerateTestNodeID(), 1, message.AtomicProofRequest{
+		Root: common.Hash{1},
+		Keys: [][]byte{keys[0]},
+	})
+	assert.NoError(t, err)
+	assert.Nil(t, responseBytes)
+	assert.EqualValues(t, 1, mockHandlerStats.AtomicProofMissingRootCount)
+}
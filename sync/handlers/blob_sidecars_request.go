@@ -0,0 +1,58 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package handlers
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/shubhamdubey02/cryftgo/codec"
+	"github.com/shubhamdubey02/cryftgo/ids"
+
+	"github.com/shubhamdubey02/coreth/core/rawdb"
+	"github.com/shubhamdubey02/coreth/plugin/evm/message"
+)
+
+// BlobSidecarsRequestHandler is a peer.RequestHandler for
+// message.BlobSidecarsRequest serving the blob sidecars of accepted blocks
+type BlobSidecarsRequestHandler struct {
+	db    ethdb.KeyValueReader
+	codec codec.Manager
+}
+
+func NewBlobSidecarsRequestHandler(db ethdb.KeyValueReader, codec codec.Manager) *BlobSidecarsRequestHandler {
+	return &BlobSidecarsRequestHandler{
+		db:    db,
+		codec: codec,
+	}
+}
+
+// OnBlobSidecarsRequest handles request to retrieve the blob sidecars of the
+// block in message.BlobSidecarsRequest
+// Never returns error
+// Returns nothing if the sidecars of the block are not stored
+func (b *BlobSidecarsRequestHandler) OnBlobSidecarsRequest(_ context.Context, nodeID ids.NodeID, requestID uint32, request message.BlobSidecarsRequest) ([]byte, error) {
+	sidecars := rawdb.ReadBlobSidecars(b.db, request.Hash, request.Height)
+	if sidecars == nil {
+		log.Debug("requested blob sidecars not found, dropping request", "nodeID", nodeID, "requestID", requestID, "hash", request.Hash, "height", request.Height)
+		return nil, nil
+	}
+	encoded := make([][]byte, len(sidecars))
+	for i, sidecar := range sidecars {
+		data, err := rlp.EncodeToBytes(sidecar)
+		if err != nil {
+			log.Error("failed to RLP encode blob sidecar, dropping request", "nodeID", nodeID, "requestID", requestID, "hash", request.Hash, "err", err)
+			return nil, nil
+		}
+		encoded[i] = data
+	}
+	responseBytes, err := b.codec.Marshal(message.Version, message.BlobSidecarsResponse{Sidecars: encoded})
+	if err != nil {
+		log.Error("failed to marshal BlobSidecarsResponse, dropping request", "nodeID", nodeID, "requestID", requestID, "request", request, "err", err)
+		return nil, nil
+	}
+	return responseBytes, nil
+}
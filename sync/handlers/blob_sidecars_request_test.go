@@ -0,0 +1,53 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/shubhamdubey02/coreth/core/rawdb"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/plugin/evm/message"
+	"github.com/shubhamdubey02/cryftgo/ids"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlobSidecarsRequestHandler(t *testing.T) {
+	database := memorydb.New()
+
+	hash := common.Hash{1}
+	sidecars := []*types.BlobTxSidecar{
+		{
+			Blobs:       []kzg4844.Blob{{1}},
+			Commitments: []kzg4844.Commitment{{2}},
+			Proofs:      []kzg4844.Proof{{3}},
+		},
+	}
+	rawdb.WriteBlobSidecars(database, hash, 1, sidecars)
+
+	handler := NewBlobSidecarsRequestHandler(database, message.Codec)
+
+	responseBytes, err := handler.OnBlobSidecarsRequest(context.Background(), ids.GenerateTestNodeID(), 1, message.BlobSidecarsRequest{Hash: hash, Height: 1})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, responseBytes)
+
+	var response message.BlobSidecarsResponse
+	_, err = message.Codec.Unmarshal(responseBytes, &response)
+	assert.NoError(t, err)
+	assert.Len(t, response.Sidecars, 1)
+
+	sidecar := new(types.BlobTxSidecar)
+	assert.NoError(t, rlp.DecodeBytes(response.Sidecars[0], sidecar))
+	assert.Equal(t, sidecars[0], sidecar)
+
+	// Requests for blocks whose sidecars are not stored are dropped.
+	responseBytes, err = handler.OnBlobSidecarsRequest(context.Background(), ids.GenerateTestNodeID(), 1, message.BlobSidecarsRequest{Hash: hash, Height: 2})
+	assert.NoError(t, err)
+	assert.Nil(t, responseBytes)
+}
@@ -14,6 +14,8 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/shubhamdubey02/coreth/core/types"
 	"github.com/shubhamdubey02/coreth/plugin/evm/message"
 	"github.com/shubhamdubey02/coreth/sync/handlers/stats"
 )
@@ -25,19 +27,23 @@ const (
 	targetMessageByteSize = units.MiB - units.KiB // Target total block bytes slightly under original network codec max size of 1MB
 )
 
-// BlockRequestHandler is a peer.RequestHandler for message.BlockRequest
-// serving requested blocks starting at specified hash
+// BlockRequestHandler is a peer.RequestHandler for message.BlockRequest and
+// message.BlockDataRequest serving requested blocks starting at specified hash
 type BlockRequestHandler struct {
-	stats         stats.BlockRequestHandlerStats
-	blockProvider BlockProvider
-	codec         codec.Manager
+	stats           stats.BlockRequestHandlerStats
+	blockProvider   BlockProvider
+	receiptProvider ReceiptProvider
+	codec           codec.Manager
 }
 
-func NewBlockRequestHandler(blockProvider BlockProvider, codec codec.Manager, handlerStats stats.BlockRequestHandlerStats) *BlockRequestHandler {
+// NewBlockRequestHandler returns a BlockRequestHandler. [receiptProvider] may
+// be nil, in which case requests for receipts are dropped.
+func NewBlockRequestHandler(blockProvider BlockProvider, receiptProvider ReceiptProvider, codec codec.Manager, handlerStats stats.BlockRequestHandlerStats) *BlockRequestHandler {
 	return &BlockRequestHandler{
-		blockProvider: blockProvider,
-		codec:         codec,
-		stats:         handlerStats,
+		blockProvider:   blockProvider,
+		receiptProvider: receiptProvider,
+		codec:           codec,
+		stats:           handlerStats,
 	}
 }
 
@@ -47,15 +53,76 @@ func NewBlockRequestHandler(blockProvider BlockProvider, codec codec.Manager, ha
 // Returns empty response or subset of requested blocks if ctx expires during fetch
 // Assumes ctx is active
 func (b *BlockRequestHandler) OnBlockRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, blockRequest message.BlockRequest) ([]byte, error) {
+	blocks, _, ok := b.readBlocks(ctx, blockRequest.Hash, blockRequest.Height, blockRequest.Parents, 0)
+	if !ok {
+		return nil, nil
+	}
+	if len(blocks) == 0 {
+		// drop this request
+		log.Debug("no requested blocks found, dropping request", "nodeID", nodeID, "requestID", requestID, "hash", blockRequest.Hash, "parents", blockRequest.Parents)
+		return nil, nil
+	}
+
+	response := message.BlockResponse{
+		Blocks: blocks,
+	}
+	responseBytes, err := b.codec.Marshal(message.Version, response)
+	if err != nil {
+		log.Error("failed to marshal BlockResponse, dropping request", "nodeID", nodeID, "requestID", requestID, "hash", blockRequest.Hash, "parents", blockRequest.Parents, "blocksLen", len(response.Blocks), "err", err)
+		return nil, nil
+	}
+
+	return responseBytes, nil
+}
+
+// OnBlockDataRequest handles incoming message.BlockDataRequest, returning blocks
+// or headers, and optionally their receipts, as requested
+// Never returns error
+// Expects returned errors to be treated as FATAL
+// Returns empty response or subset of requested blocks if ctx expires during fetch
+// Assumes ctx is active
+func (b *BlockRequestHandler) OnBlockDataRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, request message.BlockDataRequest) ([]byte, error) {
+	if request.Flags.Has(message.WithReceipts) && b.receiptProvider == nil {
+		log.Debug("receipts not available, dropping request", "nodeID", nodeID, "requestID", requestID, "request", request)
+		return nil, nil
+	}
+	blocks, receipts, ok := b.readBlocks(ctx, request.Hash, request.Height, request.Parents, request.Flags)
+	if !ok {
+		return nil, nil
+	}
+	if len(blocks) == 0 {
+		// drop this request
+		log.Debug("no requested blocks found, dropping request", "nodeID", nodeID, "requestID", requestID, "request", request)
+		return nil, nil
+	}
+
+	response := message.BlockDataResponse{
+		Blocks:   blocks,
+		Receipts: receipts,
+	}
+	responseBytes, err := b.codec.Marshal(message.Version, response)
+	if err != nil {
+		log.Error("failed to marshal BlockDataResponse, dropping request", "nodeID", nodeID, "requestID", requestID, "request", request, "blocksLen", len(response.Blocks), "err", err)
+		return nil, nil
+	}
+
+	return responseBytes, nil
+}
+
+// readBlocks returns up to [parents] RLP encoded blocks (or headers) starting at
+// [hash] and walking back through their parents, along with their receipts if
+// requested by [flags], stopping before the first block whose receipts are
+// unavailable. Returns false if the request should be dropped.
+func (b *BlockRequestHandler) readBlocks(ctx context.Context, hash common.Hash, height uint64, parents uint16, flags message.BlockDataFlags) ([][]byte, [][]byte, bool) {
 	startTime := time.Now()
 	b.stats.IncBlockRequest()
 
 	// override given Parents limit if it is greater than parentLimit
-	parents := blockRequest.Parents
 	if parents > parentLimit {
 		parents = parentLimit
 	}
 	blocks := make([][]byte, 0, parents)
+	var receipts [][]byte
 	totalBytes := 0
 
 	// ensure metrics are captured properly on all return paths
@@ -64,8 +131,6 @@ func (b *BlockRequestHandler) OnBlockRequest(ctx context.Context, nodeID ids.Nod
 		b.stats.UpdateBlocksReturned(uint16(len(blocks)))
 	}()
 
-	hash := blockRequest.Hash
-	height := blockRequest.Height
 	for i := 0; i < int(parents); i++ {
 		// we return whatever we have until ctx errors, limit is exceeded, or we reach the genesis block
 		// this will happen either when the ctx is cancelled or we hit the ctx deadline
@@ -84,36 +149,50 @@ func (b *BlockRequestHandler) OnBlockRequest(ctx context.Context, nodeID ids.Nod
 		}
 
 		buf := new(bytes.Buffer)
-		if err := block.EncodeRLP(buf); err != nil {
+		var err error
+		if flags.Has(message.HeadersOnly) {
+			err = block.Header().EncodeRLP(buf)
+		} else {
+			err = block.EncodeRLP(buf)
+		}
+		if err != nil {
 			log.Error("failed to RLP encode block", "hash", block.Hash(), "height", block.NumberU64(), "err", err)
-			return nil, nil
+			return nil, nil, false
+		}
+		size := buf.Len()
+
+		var receiptBytes []byte
+		if flags.Has(message.WithReceipts) {
+			blockReceipts := b.receiptProvider.GetReceiptsByHash(block.Hash())
+			// Receipts deleted by the retention policy are not encoded as an
+			// empty list, which the requester could not tell apart from the
+			// receipts of an empty block, so the response stops before the
+			// block.
+			if blockReceipts == nil && block.ReceiptHash() != types.EmptyReceiptsHash {
+				log.Debug("Receipts unavailable, truncating response", "hash", block.Hash(), "height", block.NumberU64())
+				break
+			}
+			receiptBytes, err = rlp.EncodeToBytes(blockReceipts)
+			if err != nil {
+				log.Error("failed to RLP encode receipts", "hash", block.Hash(), "height", block.NumberU64(), "err", err)
+				return nil, nil, false
+			}
+			size += len(receiptBytes)
 		}
 
-		if buf.Len()+totalBytes > targetMessageByteSize && len(blocks) > 0 {
-			log.Debug("Skipping block due to max total bytes size", "totalBlockDataSize", totalBytes, "blockSize", buf.Len(), "maxTotalBytesSize", targetMessageByteSize)
+		if size+totalBytes > targetMessageByteSize && len(blocks) > 0 {
+			log.Debug("Skipping block due to max total bytes size", "totalBlockDataSize", totalBytes, "blockSize", size, "maxTotalBytesSize", targetMessageByteSize)
 			break
 		}
 
 		blocks = append(blocks, buf.Bytes())
-		totalBytes += buf.Len()
+		if flags.Has(message.WithReceipts) {
+			receipts = append(receipts, receiptBytes)
+		}
+		totalBytes += size
 		hash = block.ParentHash()
 		height--
 	}
 
-	if len(blocks) == 0 {
-		// drop this request
-		log.Debug("no requested blocks found, dropping request", "nodeID", nodeID, "requestID", requestID, "hash", blockRequest.Hash, "parents", blockRequest.Parents)
-		return nil, nil
-	}
-
-	response := message.BlockResponse{
-		Blocks: blocks,
-	}
-	responseBytes, err := b.codec.Marshal(message.Version, response)
-	if err != nil {
-		log.Error("failed to marshal BlockResponse, dropping request", "nodeID", nodeID, "requestID", requestID, "hash", blockRequest.Hash, "parents", blockRequest.Parents, "blocksLen", len(response.Blocks), "err", err)
-		return nil, nil
-	}
-
-	return responseBytes, nil
+	return blocks, receipts, true
 }
@@ -55,7 +55,7 @@ func executeBlockRequestTest(t testing.TB, test blockRequestTest, blocks []*type
 			return blk
 		},
 	}
-	blockRequestHandler := NewBlockRequestHandler(blockProvider, message.Codec, mockHandlerStats)
+	blockRequestHandler := NewBlockRequestHandler(blockProvider, nil, message.Codec, mockHandlerStats)
 
 	var blockRequest message.BlockRequest
 	if test.startBlockHash != (common.Hash{}) {
@@ -252,7 +252,7 @@ func TestBlockRequestHandlerCtxExpires(t *testing.T) {
 			return blk
 		},
 	}
-	blockRequestHandler := NewBlockRequestHandler(blockProvider, message.Codec, stats.NewNoopHandlerStats())
+	blockRequestHandler := NewBlockRequestHandler(blockProvider, nil, message.Codec, stats.NewNoopHandlerStats())
 
 	responseBytes, err := blockRequestHandler.OnBlockRequest(ctx, ids.GenerateTestNodeID(), 1, message.BlockRequest{
 		Hash:    blocks[10].Hash(),
@@ -279,3 +279,161 @@ func TestBlockRequestHandlerCtxExpires(t *testing.T) {
 		assert.Equal(t, blocks[len(blocks)-i-1].Hash(), block.Hash())
 	}
 }
+
+func TestBlockDataRequestHandler(t *testing.T) {
+	var gspec = &core.Genesis{
+		Config: params.TestChainConfig,
+	}
+	memdb := rawdb.NewMemoryDatabase()
+	tdb := trie.NewDatabase(memdb, nil)
+	genesis := gspec.MustCommit(memdb, tdb)
+	engine := dummy.NewETHFaker()
+	blocks, receipts, err := core.GenerateChain(params.TestChainConfig, genesis, engine, memdb, 10, 0, func(i int, b *core.BlockGen) {})
+	if err != nil {
+		t.Fatal("unexpected error when generating test blockchain", err)
+	}
+
+	blocksDB := make(map[common.Hash]*types.Block, len(blocks))
+	receiptsDB := make(map[common.Hash]types.Receipts, len(blocks))
+	for i, blk := range blocks {
+		blocksDB[blk.Hash()] = blk
+		receiptsDB[blk.Hash()] = receipts[i]
+	}
+	blockProvider := &TestBlockProvider{
+		GetBlockFn: func(hash common.Hash, height uint64) *types.Block {
+			blk, ok := blocksDB[hash]
+			if !ok || blk.NumberU64() != height {
+				return nil
+			}
+			return blk
+		},
+	}
+	receiptProvider := &TestReceiptProvider{
+		GetReceiptsByHashFn: func(hash common.Hash) types.Receipts {
+			return receiptsDB[hash]
+		},
+	}
+	request := message.BlockDataRequest{
+		Hash:    blocks[9].Hash(),
+		Height:  blocks[9].NumberU64(),
+		Parents: 4,
+		Flags:   message.HeadersOnly | message.WithReceipts,
+	}
+
+	blockRequestHandler := NewBlockRequestHandler(blockProvider, receiptProvider, message.Codec, stats.NewNoopHandlerStats())
+	responseBytes, err := blockRequestHandler.OnBlockDataRequest(context.Background(), ids.GenerateTestNodeID(), 1, request)
+	if err != nil {
+		t.Fatal("unexpected error from BlockRequestHandler", err)
+	}
+	var response message.BlockDataResponse
+	if _, err = message.Codec.Unmarshal(responseBytes, &response); err != nil {
+		t.Fatal("error unmarshalling", err)
+	}
+	assert.Len(t, response.Blocks, 4)
+	assert.Len(t, response.Receipts, 4)
+	for i, headerBytes := range response.Blocks {
+		header := new(types.Header)
+		if err := rlp.DecodeBytes(headerBytes, header); err != nil {
+			t.Fatal("could not parse header", err)
+		}
+		assert.Equal(t, blocks[9-i].Hash(), header.Hash())
+
+		var blockReceipts types.Receipts
+		if err := rlp.DecodeBytes(response.Receipts[i], &blockReceipts); err != nil {
+			t.Fatal("could not parse receipts", err)
+		}
+		assert.Len(t, blockReceipts, len(receipts[9-i]))
+	}
+
+	// Requests for receipts are dropped if receipts are not available
+	blockRequestHandler = NewBlockRequestHandler(blockProvider, nil, message.Codec, stats.NewNoopHandlerStats())
+	responseBytes, err = blockRequestHandler.OnBlockDataRequest(context.Background(), ids.GenerateTestNodeID(), 1, request)
+	if err != nil {
+		t.Fatal("unexpected error from BlockRequestHandler", err)
+	}
+	assert.Nil(t, responseBytes)
+}
+
+func TestBlockDataRequestHandlerPrunedReceipts(t *testing.T) {
+	var (
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		gspec   = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  core.GenesisAlloc{addr1: {Balance: big.NewInt(params.Ether)}},
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	memdb := rawdb.NewMemoryDatabase()
+	tdb := trie.NewDatabase(memdb, nil)
+	genesis := gspec.MustCommit(memdb, tdb)
+	engine := dummy.NewETHFaker()
+	blocks, receipts, err := core.GenerateChain(gspec.Config, genesis, engine, memdb, 4, 0, func(i int, b *core.BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(b.TxNonce(addr1), addr1, big.NewInt(1), params.TxGas, b.BaseFee(), nil), signer, key1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.AddTx(tx)
+	})
+	if err != nil {
+		t.Fatal("unexpected error when generating test blockchain", err)
+	}
+
+	// The receipts of the first two blocks were deleted.
+	blocksDB := make(map[common.Hash]*types.Block, len(blocks))
+	receiptsDB := make(map[common.Hash]types.Receipts, len(blocks))
+	for i, blk := range blocks {
+		blocksDB[blk.Hash()] = blk
+		if i >= 2 {
+			receiptsDB[blk.Hash()] = receipts[i]
+		}
+	}
+	blockProvider := &TestBlockProvider{
+		GetBlockFn: func(hash common.Hash, height uint64) *types.Block {
+			blk, ok := blocksDB[hash]
+			if !ok || blk.NumberU64() != height {
+				return nil
+			}
+			return blk
+		},
+	}
+	receiptProvider := &TestReceiptProvider{
+		GetReceiptsByHashFn: func(hash common.Hash) types.Receipts {
+			return receiptsDB[hash]
+		},
+	}
+	blockRequestHandler := NewBlockRequestHandler(blockProvider, receiptProvider, message.Codec, stats.NewNoopHandlerStats())
+
+	// The response stops before the first block whose receipts are missing.
+	request := message.BlockDataRequest{
+		Hash:    blocks[3].Hash(),
+		Height:  blocks[3].NumberU64(),
+		Parents: 4,
+		Flags:   message.WithReceipts,
+	}
+	responseBytes, err := blockRequestHandler.OnBlockDataRequest(context.Background(), ids.GenerateTestNodeID(), 1, request)
+	if err != nil {
+		t.Fatal("unexpected error from BlockRequestHandler", err)
+	}
+	var response message.BlockDataResponse
+	if _, err = message.Codec.Unmarshal(responseBytes, &response); err != nil {
+		t.Fatal("error unmarshalling", err)
+	}
+	assert.Len(t, response.Blocks, 2)
+	assert.Len(t, response.Receipts, 2)
+	for i, receiptBytes := range response.Receipts {
+		var blockReceipts types.Receipts
+		if err := rlp.DecodeBytes(receiptBytes, &blockReceipts); err != nil {
+			t.Fatal("could not parse receipts", err)
+		}
+		assert.Len(t, blockReceipts, 1, "receipts of block %d", 3-i)
+	}
+
+	// Requests starting at a block whose receipts are missing are dropped.
+	request.Hash, request.Height = blocks[1].Hash(), blocks[1].NumberU64()
+	responseBytes, err = blockRequestHandler.OnBlockDataRequest(context.Background(), ids.GenerateTestNodeID(), 1, request)
+	if err != nil {
+		t.Fatal("unexpected error from BlockRequestHandler", err)
+	}
+	assert.Nil(t, responseBytes)
+}
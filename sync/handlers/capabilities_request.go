@@ -0,0 +1,41 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package handlers
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/shubhamdubey02/cryftgo/codec"
+	"github.com/shubhamdubey02/cryftgo/ids"
+
+	"github.com/shubhamdubey02/coreth/plugin/evm/message"
+)
+
+// CapabilitiesRequestHandler is a peer.RequestHandler for
+// message.CapabilitiesRequest advertising the sync features served by this
+// node
+type CapabilitiesRequestHandler struct {
+	capabilities message.CapabilitiesResponse
+	codec        codec.Manager
+}
+
+func NewCapabilitiesRequestHandler(capabilities message.CapabilitiesResponse, codec codec.Manager) *CapabilitiesRequestHandler {
+	return &CapabilitiesRequestHandler{
+		capabilities: capabilities,
+		codec:        codec,
+	}
+}
+
+// OnCapabilitiesRequest returns the advertised capabilities.
+// Never returns error
+// Returns nothing if the capabilities could not be marshalled
+func (c *CapabilitiesRequestHandler) OnCapabilitiesRequest(_ context.Context, nodeID ids.NodeID, requestID uint32, _ message.CapabilitiesRequest) ([]byte, error) {
+	responseBytes, err := c.codec.Marshal(message.Version, c.capabilities)
+	if err != nil {
+		log.Error("failed to marshal CapabilitiesResponse, dropping request", "nodeID", nodeID, "requestID", requestID, "err", err)
+		return nil, nil
+	}
+	return responseBytes, nil
+}
@@ -47,6 +47,9 @@ type LeafsRequestHandler struct {
 	codec            codec.Manager
 	stats            stats.LeafsRequestHandlerStats
 	pool             sync.Pool
+	// keyLength is the key length of the served trie if it is not derived
+	// from the node type of requests, as is the case for namespace tries.
+	keyLength int
 }
 
 func NewLeafsRequestHandler(trieDB *trie.Database, snapshotProvider SnapshotProvider, codec codec.Manager, syncerStats stats.LeafsRequestHandlerStats) *LeafsRequestHandler {
@@ -84,7 +87,7 @@ func (lrh *LeafsRequestHandler) OnLeafsRequest(ctx context.Context, nodeID ids.N
 		lrh.stats.IncInvalidLeafsRequest()
 		return nil, nil
 	}
-	keyLength, err := getKeyLength(leafsRequest.NodeType)
+	keyLength, err := lrh.getKeyLength(leafsRequest.NodeType)
 	if err != nil {
 		// Note: LeafsRequest.Handle checks NodeType's validity so clients cannot cause the server to spam this error
 		log.Error("Failed to get key length for leafs request", "err", err)
@@ -454,6 +457,14 @@ func (rb *responseBuilder) fillFromTrie(ctx context.Context, end []byte) (bool,
 	return more, it.Err
 }
 
+// getKeyLength returns the key length of the trie served by lrh for given nodeType
+func (lrh *LeafsRequestHandler) getKeyLength(nodeType message.NodeType) (int, error) {
+	if lrh.keyLength > 0 {
+		return lrh.keyLength, nil
+	}
+	return getKeyLength(nodeType)
+}
+
 // getKeyLength returns trie key length for given nodeType
 // expects nodeType to be one of message.AtomicTrieNode or message.StateTrieNode
 func getKeyLength(nodeType message.NodeType) (int, error) {
@@ -0,0 +1,67 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/shubhamdubey02/coreth/plugin/evm/message"
+	"github.com/shubhamdubey02/coreth/sync/handlers/stats"
+	"github.com/shubhamdubey02/coreth/trie"
+	"github.com/shubhamdubey02/cryftgo/codec"
+	"github.com/shubhamdubey02/cryftgo/ids"
+)
+
+// TrieNamespace is an additional trie served to peers through leafs requests
+// of its NodeType, beyond the state and atomic tries.
+type TrieNamespace struct {
+	Name string
+	// NodeType identifies the namespace in leafs requests, and must not be
+	// lower than message.FirstNamespaceNode.
+	NodeType message.NodeType
+	// KeyLength is the length of every key of the trie.
+	KeyLength int
+	TrieDB    *trie.Database
+}
+
+// NamespaceLeafsRequestHandler is a peer.RequestHandler for
+// message.LeafsRequest serving the leafs of the configured trie namespaces
+type NamespaceLeafsRequestHandler struct {
+	handlers map[message.NodeType]*LeafsRequestHandler
+}
+
+func NewNamespaceLeafsRequestHandler(namespaces []TrieNamespace, codec codec.Manager, syncerStats stats.LeafsRequestHandlerStats) (*NamespaceLeafsRequestHandler, error) {
+	handlers := make(map[message.NodeType]*LeafsRequestHandler, len(namespaces))
+	for _, namespace := range namespaces {
+		if namespace.NodeType < message.FirstNamespaceNode {
+			return nil, fmt.Errorf("node type %d of trie namespace %q is reserved", namespace.NodeType, namespace.Name)
+		}
+		if _, ok := handlers[namespace.NodeType]; ok {
+			return nil, fmt.Errorf("node type %d of trie namespace %q is already in use", namespace.NodeType, namespace.Name)
+		}
+		if namespace.KeyLength <= 0 {
+			return nil, fmt.Errorf("key length %d of trie namespace %q must be positive", namespace.KeyLength, namespace.Name)
+		}
+		handler := NewLeafsRequestHandler(namespace.TrieDB, nil, codec, syncerStats)
+		handler.keyLength = namespace.KeyLength
+		handlers[namespace.NodeType] = handler
+	}
+	return &NamespaceLeafsRequestHandler{handlers: handlers}, nil
+}
+
+// OnLeafsRequest returns encoded message.LeafsResponse for a given
+// message.LeafsRequest of a trie namespace, see
+// LeafsRequestHandler.OnLeafsRequest
+// Never returns errors
+// Returns nothing if no namespace is configured for the NodeType of the request
+func (n *NamespaceLeafsRequestHandler) OnLeafsRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, leafsRequest message.LeafsRequest) ([]byte, error) {
+	handler, ok := n.handlers[leafsRequest.NodeType]
+	if !ok {
+		log.Debug("trie namespace not served, dropping request", "nodeID", nodeID, "requestID", requestID, "nodeType", leafsRequest.NodeType)
+		return nil, nil
+	}
+	return handler.OnLeafsRequest(ctx, nodeID, requestID, leafsRequest)
+}
@@ -0,0 +1,80 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/shubhamdubey02/coreth/core/rawdb"
+	"github.com/shubhamdubey02/coreth/plugin/evm/message"
+	"github.com/shubhamdubey02/coreth/sync/handlers/stats"
+	"github.com/shubhamdubey02/coreth/sync/syncutils"
+	"github.com/shubhamdubey02/coreth/trie"
+	"github.com/shubhamdubey02/cryftgo/ids"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamespaceLeafsRequestHandler(t *testing.T) {
+	const keyLength = 20
+	trieDB := trie.NewDatabase(rawdb.NewMemoryDatabase(), nil)
+	root, keys, vals := syncutils.GenerateTrie(t, trieDB, 100, keyLength)
+
+	nodeType := message.FirstNamespaceNode
+	handler, err := NewNamespaceLeafsRequestHandler([]TrieNamespace{
+		{Name: "test", NodeType: nodeType, KeyLength: keyLength, TrieDB: trieDB},
+	}, message.Codec, &stats.MockHandlerStats{})
+	assert.NoError(t, err)
+
+	request := message.LeafsRequest{
+		Root:     root,
+		Start:    bytes.Repeat([]byte{0x00}, keyLength),
+		End:      bytes.Repeat([]byte{0xff}, keyLength),
+		Limit:    maxLeavesLimit,
+		NodeType: nodeType,
+	}
+	responseBytes, err := handler.OnLeafsRequest(context.Background(), ids.GenerateTestNodeID(), 1, request)
+	assert.NoError(t, err)
+
+	var response message.LeafsResponse
+	_, err = message.Codec.Unmarshal(responseBytes, &response)
+	assert.NoError(t, err)
+	assert.Equal(t, keys, response.Keys)
+	assert.Equal(t, vals, response.Vals)
+
+	// Ranges must use the key length of the namespace.
+	request.Start = bytes.Repeat([]byte{0x00}, keyLength+1)
+	responseBytes, err = handler.OnLeafsRequest(context.Background(), ids.GenerateTestNodeID(), 1, request)
+	assert.NoError(t, err)
+	assert.Nil(t, responseBytes)
+
+	// Requests of namespaces that are not served are dropped.
+	request.Start = nil
+	request.NodeType = nodeType + 1
+	responseBytes, err = handler.OnLeafsRequest(context.Background(), ids.GenerateTestNodeID(), 1, request)
+	assert.NoError(t, err)
+	assert.Nil(t, responseBytes)
+}
+
+func TestNamespaceLeafsRequestHandlerValidation(t *testing.T) {
+	tests := map[string][]TrieNamespace{
+		"reserved node type": {
+			{Name: "atomic", NodeType: message.AtomicTrieNode, KeyLength: 40},
+		},
+		"duplicate node type": {
+			{Name: "a", NodeType: message.FirstNamespaceNode, KeyLength: 32},
+			{Name: "b", NodeType: message.FirstNamespaceNode, KeyLength: 32},
+		},
+		"missing key length": {
+			{Name: "a", NodeType: message.FirstNamespaceNode},
+		},
+	}
+	for name, namespaces := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewNamespaceLeafsRequestHandler(namespaces, message.Codec, &stats.MockHandlerStats{})
+			assert.Error(t, err)
+		})
+	}
+}
@@ -42,6 +42,11 @@ type MockHandlerStats struct {
 	SnapshotReadTime,
 	GenerateRangeProofTime,
 	LeafRequestProcessingTimeSum time.Duration
+
+	AtomicProofRequestCount,
+	InvalidAtomicProofRequestCount,
+	AtomicProofMissingRootCount uint32
+	AtomicProofRequestProcessingTimeSum time.Duration
 }
 
 func (m *MockHandlerStats) Reset() {
@@ -73,6 +78,10 @@ func (m *MockHandlerStats) Reset() {
 	m.SnapshotReadTime = 0
 	m.GenerateRangeProofTime = 0
 	m.LeafRequestProcessingTimeSum = 0
+	m.AtomicProofRequestCount = 0
+	m.InvalidAtomicProofRequestCount = 0
+	m.AtomicProofMissingRootCount = 0
+	m.AtomicProofRequestProcessingTimeSum = 0
 }
 
 func (m *MockHandlerStats) IncBlockRequest() {
@@ -230,3 +239,27 @@ func (m *MockHandlerStats) IncSnapshotSegmentInvalid() {
 	defer m.lock.Unlock()
 	m.SnapshotSegmentInvalidCount++
 }
+
+func (m *MockHandlerStats) IncAtomicProofRequest() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.AtomicProofRequestCount++
+}
+
+func (m *MockHandlerStats) IncInvalidAtomicProofRequest() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.InvalidAtomicProofRequestCount++
+}
+
+func (m *MockHandlerStats) IncAtomicProofMissingRoot() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.AtomicProofMissingRootCount++
+}
+
+func (m *MockHandlerStats) UpdateAtomicProofRequestProcessingTime(duration time.Duration) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.AtomicProofRequestProcessingTimeSum += duration
+}
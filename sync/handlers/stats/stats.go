@@ -14,6 +14,7 @@ type HandlerStats interface {
 	BlockRequestHandlerStats
 	CodeRequestHandlerStats
 	LeafsRequestHandlerStats
+	AtomicProofRequestHandlerStats
 }
 
 type BlockRequestHandlerStats interface {
@@ -51,6 +52,13 @@ type LeafsRequestHandlerStats interface {
 	IncSnapshotSegmentInvalid()
 }
 
+type AtomicProofRequestHandlerStats interface {
+	IncAtomicProofRequest()
+	IncInvalidAtomicProofRequest()
+	IncAtomicProofMissingRoot()
+	UpdateAtomicProofRequestProcessingTime(duration time.Duration)
+}
+
 type handlerStats struct {
 	// BlockRequestHandler metrics
 	blockRequest               metrics.Counter
@@ -83,6 +91,12 @@ type handlerStats struct {
 	snapshotReadSuccess        metrics.Counter
 	snapshotSegmentValid       metrics.Counter
 	snapshotSegmentInvalid     metrics.Counter
+
+	// AtomicProofRequestHandler stats
+	atomicProofRequest               metrics.Counter
+	invalidAtomicProofRequest        metrics.Counter
+	atomicProofMissingRoot           metrics.Counter
+	atomicProofRequestProcessingTime metrics.Timer
 }
 
 func (h *handlerStats) IncBlockRequest() {
@@ -166,6 +180,22 @@ func (h *handlerStats) IncSnapshotReadSuccess()    { h.snapshotReadSuccess.Inc(1
 func (h *handlerStats) IncSnapshotSegmentValid()   { h.snapshotSegmentValid.Inc(1) }
 func (h *handlerStats) IncSnapshotSegmentInvalid() { h.snapshotSegmentInvalid.Inc(1) }
 
+func (h *handlerStats) IncAtomicProofRequest() {
+	h.atomicProofRequest.Inc(1)
+}
+
+func (h *handlerStats) IncInvalidAtomicProofRequest() {
+	h.invalidAtomicProofRequest.Inc(1)
+}
+
+func (h *handlerStats) IncAtomicProofMissingRoot() {
+	h.atomicProofMissingRoot.Inc(1)
+}
+
+func (h *handlerStats) UpdateAtomicProofRequestProcessingTime(duration time.Duration) {
+	h.atomicProofRequestProcessingTime.Update(duration)
+}
+
 func NewHandlerStats(enabled bool) HandlerStats {
 	if !enabled {
 		return NewNoopHandlerStats()
@@ -202,6 +232,12 @@ func NewHandlerStats(enabled bool) HandlerStats {
 		snapshotReadSuccess:        metrics.GetOrRegisterCounter("leafs_request_snapshot_read_success", nil),
 		snapshotSegmentValid:       metrics.GetOrRegisterCounter("leafs_request_snapshot_segment_valid", nil),
 		snapshotSegmentInvalid:     metrics.GetOrRegisterCounter("leafs_request_snapshot_segment_invalid", nil),
+
+		// initialize atomic proof request stats
+		atomicProofRequest:               metrics.GetOrRegisterCounter("atomic_proof_request_count", nil),
+		invalidAtomicProofRequest:        metrics.GetOrRegisterCounter("atomic_proof_request_invalid", nil),
+		atomicProofMissingRoot:           metrics.GetOrRegisterCounter("atomic_proof_request_missing_root", nil),
+		atomicProofRequestProcessingTime: metrics.GetOrRegisterTimer("atomic_proof_request_processing_time", nil),
 	}
 }
 
@@ -239,3 +275,8 @@ func (n *noopHandlerStats) IncSnapshotReadAttempt()
 func (n *noopHandlerStats) IncSnapshotReadSuccess()                             {}
 func (n *noopHandlerStats) IncSnapshotSegmentValid()                            {}
 func (n *noopHandlerStats) IncSnapshotSegmentInvalid()                          {}
+
+func (n *noopHandlerStats) IncAtomicProofRequest()                               {}
+func (n *noopHandlerStats) IncInvalidAtomicProofRequest()                        {}
+func (n *noopHandlerStats) IncAtomicProofMissingRoot()                           {}
+func (n *noopHandlerStats) UpdateAtomicProofRequestProcessingTime(time.Duration) {}
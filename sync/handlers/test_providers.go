@@ -11,6 +11,7 @@ import (
 
 var (
 	_ BlockProvider    = &TestBlockProvider{}
+	_ ReceiptProvider  = &TestReceiptProvider{}
 	_ SnapshotProvider = &TestSnapshotProvider{}
 )
 
@@ -22,6 +23,14 @@ func (t *TestBlockProvider) GetBlock(hash common.Hash, number uint64) *types.Blo
 	return t.GetBlockFn(hash, number)
 }
 
+type TestReceiptProvider struct {
+	GetReceiptsByHashFn func(common.Hash) types.Receipts
+}
+
+func (t *TestReceiptProvider) GetReceiptsByHash(hash common.Hash) types.Receipts {
+	return t.GetReceiptsByHashFn(hash)
+}
+
 type TestSnapshotProvider struct {
 	Snapshot *snapshot.Tree
 }
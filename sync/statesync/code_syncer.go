@@ -12,6 +12,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/shubhamdubey02/coreth/core/rawdb"
+	"github.com/shubhamdubey02/coreth/params"
 	"github.com/shubhamdubey02/coreth/plugin/evm/message"
 	statesyncclient "github.com/shubhamdubey02/coreth/sync/client"
 	"github.com/shubhamdubey02/cryftgo/ids"
@@ -37,6 +38,15 @@ type CodeSyncerConfig struct {
 
 	// Database for the code syncer to use.
 	DB ethdb.Database
+
+	// MemoryBudget bounds the code bytes fetched but not yet written to disk.
+	// Code requests are held back while the budget is exhausted. May be nil.
+	MemoryBudget *statesyncclient.MemoryBudget
+
+	// MaxCodeSize is the largest contract code size allowed by any upgrade,
+	// reserved from [MemoryBudget] for each requested code hash. Defaults to
+	// [params.MaxCodeSize].
+	MaxCodeSize int
 }
 
 // codeSyncer syncs code bytes from the network in a seprate thread.
@@ -60,6 +70,9 @@ type codeSyncer struct {
 
 // newCodeSyncer returns a code syncer that will sync code bytes from the network in a separate thread.
 func newCodeSyncer(config CodeSyncerConfig) *codeSyncer {
+	if config.MaxCodeSize == 0 {
+		config.MaxCodeSize = params.MaxCodeSize
+	}
 	return &codeSyncer{
 		CodeSyncerConfig:      config,
 		codeHashes:            make(chan common.Hash, config.MaxOutstandingCodeHashes),
@@ -177,6 +190,13 @@ func (c *codeSyncer) work(ctx context.Context) error {
 // codeHashes should not be empty or contain duplicate hashes.
 // Returns an error if one is encountered, signaling the worker thread to terminate.
 func (c *codeSyncer) fulfillCodeRequest(ctx context.Context, codeHashes []common.Hash) error {
+	// Reserve enough memory for the largest possible response.
+	reserved := len(codeHashes) * c.MaxCodeSize
+	if err := c.MemoryBudget.Acquire(ctx, reserved); err != nil {
+		return err
+	}
+	defer c.MemoryBudget.Release(reserved)
+
 	codeByteSlices, err := c.Client.GetCode(ctx, codeHashes)
 	if err != nil {
 		return err
@@ -31,6 +31,8 @@ type StateSyncerConfig struct {
 	MaxOutstandingCodeHashes int    // Maximum number of code hashes in the code syncer queue
 	NumCodeFetchingWorkers   int    // Number of code syncing threads
 	RequestSize              uint16 // Number of leafs to request from a peer at a time
+	MemoryBudget             uint64 // Maximum bytes of leafs, trie nodes and code buffered in memory, 0 for no limit
+	MaxCodeSize              int    // Largest contract code size allowed by any upgrade, defaults to [params.MaxCodeSize]
 }
 
 // stateSync keeps the state of the entire state sync operation.
@@ -42,6 +44,14 @@ type stateSync struct {
 	batchSize int               // write batches when they reach this size
 	client    syncclient.Client // used to contact peers over the network
 
+	// The memory budget is split between responses to requests in flight,
+	// which hold back new requests when exhausted, and batches not yet written
+	// to disk, which are written early when exhausted. Batches never wait on
+	// the budget, so requests cannot be blocked by memory held by a worker
+	// that is itself waiting. Both are nil if unlimited.
+	requestBudget *syncclient.MemoryBudget
+	batchBudget   *syncclient.MemoryBudget
+
 	segments   chan syncclient.LeafSyncTask   // channel of tasks to sync
 	syncer     *syncclient.CallbackLeafSyncer // performs the sync, looping over each task's range and invoking specified callbacks
 	codeSyncer *codeSyncer                    // manages the asynchronous download and batching of code hashes
@@ -66,6 +76,8 @@ func NewStateSyncer(config *StateSyncerConfig) (*stateSync, error) {
 		batchSize:       config.BatchSize,
 		db:              config.DB,
 		client:          config.Client,
+		requestBudget:   syncclient.NewMemoryBudget(config.MemoryBudget / 2),
+		batchBudget:     syncclient.NewMemoryBudget(config.MemoryBudget - config.MemoryBudget/2),
 		root:            config.Root,
 		trieDB:          trie.NewDatabase(config.DB, nil),
 		snapshot:        snapshot.NewDiskLayer(config.DB),
@@ -83,12 +95,14 @@ func NewStateSyncer(config *StateSyncerConfig) (*stateSync, error) {
 		mainTrieDone: make(chan struct{}),
 		done:         make(chan error, 1),
 	}
-	ss.syncer = syncclient.NewCallbackLeafSyncer(config.Client, ss.segments, config.RequestSize)
+	ss.syncer = syncclient.NewCallbackLeafSyncer(config.Client, ss.segments, config.RequestSize, ss.requestBudget)
 	ss.codeSyncer = newCodeSyncer(CodeSyncerConfig{
 		DB:                       config.DB,
 		Client:                   config.Client,
 		MaxOutstandingCodeHashes: config.MaxOutstandingCodeHashes,
 		NumCodeFetchingWorkers:   config.NumCodeFetchingWorkers,
+		MemoryBudget:             ss.requestBudget,
+		MaxCodeSize:              config.MaxCodeSize,
 	})
 
 	ss.trieQueue = NewTrieQueue(config.DB)
@@ -225,6 +239,12 @@ func (t *stateSync) Start(ctx context.Context) error {
 
 func (t *stateSync) Done() <-chan error { return t.done }
 
+// MemoryUsage returns the bytes of leafs, trie nodes and code buffered in
+// memory, which are only accounted if the syncer has a memory budget.
+func (t *stateSync) MemoryUsage() uint64 {
+	return t.requestBudget.Used() + t.batchBudget.Used()
+}
+
 // addTrieInProgress tracks the root as being currently synced.
 func (t *stateSync) addTrieInProgress(root common.Hash, trie *trieToSync) {
 	t.lock.Lock()
@@ -249,6 +269,24 @@ func (t *stateSync) removeTrieInProgress(root common.Hash) error {
 	return nil
 }
 
+// capBatch writes [batch] to disk if it is larger than [batchSize] or if its
+// growth since the last call cannot be reserved from the batch budget.
+// [buffered] tracks the bytes of [batch] reserved from the budget.
+func (t *stateSync) capBatch(batch ethdb.Batch, buffered *int) error {
+	size := batch.ValueSize()
+	if size <= t.batchSize && t.batchBudget.TryAcquire(size-*buffered) {
+		*buffered = size
+		return nil
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	batch.Reset()
+	t.batchBudget.Release(*buffered)
+	*buffered = 0
+	return nil
+}
+
 // onSyncFailure is called if the sync fails, this writes all
 // batches of in-progress trie segments to disk to have maximum
 // progress to restore.
@@ -39,6 +39,7 @@ type syncTest struct {
 	expectedError     error
 	GetLeafsIntercept func(message.LeafsRequest, message.LeafsResponse) (message.LeafsResponse, error)
 	GetCodeIntercept  func([]common.Hash, [][]byte) ([][]byte, error)
+	memoryBudget      uint64
 }
 
 func testSync(t *testing.T, test syncTest) {
@@ -63,6 +64,7 @@ func testSync(t *testing.T, test syncTest) {
 		NumCodeFetchingWorkers:   DefaultNumCodeFetchingWorkers,
 		MaxOutstandingCodeHashes: DefaultMaxOutstandingCodeHashes,
 		RequestSize:              1024,
+		MemoryBudget:             test.memoryBudget,
 	})
 	if err != nil {
 		t.Fatal(err)
@@ -154,6 +156,17 @@ func TestSimpleSyncCases(t *testing.T) {
 				return rawdb.NewMemoryDatabase(), serverDB, serverTrieDB, root
 			},
 		},
+		"accounts with code and storage under memory budget": {
+			prepareForTest: func(t *testing.T) (ethdb.Database, ethdb.Database, *trie.Database, common.Hash) {
+				serverDB := rawdb.NewMemoryDatabase()
+				serverTrieDB := trie.NewDatabase(serverDB, nil)
+				root := fillAccountsWithStorage(t, serverDB, serverTrieDB, common.Hash{}, numAccounts)
+				return rawdb.NewMemoryDatabase(), serverDB, serverTrieDB, root
+			},
+			// Smaller than a single leafs or code request, so requests are
+			// issued one at a time and batches are written early.
+			memoryBudget: 64 * 1024,
+		},
 		"accounts with storage": {
 			prepareForTest: func(t *testing.T) (ethdb.Database, ethdb.Database, *trie.Database, common.Hash) {
 				serverDB := rawdb.NewMemoryDatabase()
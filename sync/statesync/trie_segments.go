@@ -47,6 +47,7 @@ type trieToSync struct {
 	// We use a stack trie to hash the leafs and have
 	// a batch used for writing it to disk.
 	batch     ethdb.Batch
+	buffered  int // bytes of [batch] reserved from the batch budget
 	stackTrie *trie.StackTrie
 
 	// We keep a pointer to the overall sync operation,
@@ -181,6 +182,8 @@ func (t *trieToSync) segmentFinished(ctx context.Context, idx int) error {
 			return err
 		}
 		segment.batch.Reset() // reset the batch to free memory (even though it is no longer used)
+		t.sync.batchBudget.Release(segment.buffered)
+		segment.buffered = 0
 
 		// iterate all the items from the start of the segment (end is checked in the loop)
 		it := t.task.IterateLeafs(common.BytesToHash(segment.start))
@@ -200,11 +203,8 @@ func (t *trieToSync) segmentFinished(ctx context.Context, idx int) error {
 			if err := t.stackTrie.Update(it.Key(), value); err != nil {
 				return err
 			}
-			if t.batch.ValueSize() > t.sync.batchSize {
-				if err := t.batch.Write(); err != nil {
-					return err
-				}
-				t.batch.Reset()
+			if err := t.sync.capBatch(t.batch, &t.buffered); err != nil {
+				return err
 			}
 		}
 		if err := it.Error(); err != nil {
@@ -229,6 +229,8 @@ func (t *trieToSync) segmentFinished(ctx context.Context, idx int) error {
 		if err := t.batch.Write(); err != nil {
 			return err
 		}
+		t.sync.batchBudget.Release(t.buffered)
+		t.buffered = 0
 	}
 
 	// remove all segments for this root from persistent storage
@@ -326,10 +328,11 @@ type trieSegment struct {
 	pos   []byte
 	end   []byte
 
-	trie  *trieToSync // points back to the trie the segment belongs to
-	idx   int         // index of this segment in the trie's segment slice
-	batch ethdb.Batch // batch for writing leafs to
-	leafs uint64      // number of leafs added to the segment
+	trie     *trieToSync // points back to the trie the segment belongs to
+	idx      int         // index of this segment in the trie's segment slice
+	batch    ethdb.Batch // batch for writing leafs to
+	buffered int         // bytes of [batch] reserved from the batch budget
+	leafs    uint64      // number of leafs added to the segment
 }
 
 func (t *trieSegment) String() string {
@@ -362,11 +365,8 @@ func (t *trieSegment) OnLeafs(keys, vals [][]byte) error {
 		return err
 	}
 	// cap the segment's batch
-	if t.batch.ValueSize() > t.trie.sync.batchSize {
-		if err := t.batch.Write(); err != nil {
-			return err
-		}
-		t.batch.Reset()
+	if err := t.trie.sync.capBatch(t.batch, &t.buffered); err != nil {
+		return err
 	}
 	t.leafs += uint64(len(keys))
 	if len(keys) > 0 {
@@ -0,0 +1,159 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package syncsim simulates state sync against recorded or synthesized peer
+// responses, so that the performance of the state sync client can be
+// measured without a live network.
+package syncsim
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/shubhamdubey02/coreth/peer"
+	"github.com/shubhamdubey02/cryftgo/ids"
+	"github.com/shubhamdubey02/cryftgo/version"
+)
+
+var _ peer.NetworkClient = (*recorder)(nil)
+
+// Fixture is a request sent by the state sync client and the response of a
+// peer to it.
+type Fixture struct {
+	Request  hexutil.Bytes `json:"request"`
+	Response hexutil.Bytes `json:"response"`
+}
+
+// Fixtures are the responses of peers to the requests of a state sync to
+// [Root], keyed by the hash of their request. Fixtures are a [Source]
+// replaying the responses.
+type Fixtures struct {
+	Root common.Hash
+
+	lock      sync.RWMutex
+	responses map[common.Hash]Fixture
+}
+
+func NewFixtures(root common.Hash) *Fixtures {
+	return &Fixtures{
+		Root:      root,
+		responses: make(map[common.Hash]Fixture),
+	}
+}
+
+// Add records [response] as the response to [request], replacing any
+// previous response.
+func (f *Fixtures) Add(request, response []byte) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.responses[crypto.Keccak256Hash(request)] = Fixture{
+		Request:  common.CopyBytes(request),
+		Response: common.CopyBytes(response),
+	}
+}
+
+// Len returns the number of recorded requests.
+func (f *Fixtures) Len() int {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	return len(f.responses)
+}
+
+// Respond returns the recorded response to [request].
+func (f *Fixtures) Respond(_ context.Context, request []byte) ([]byte, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	fixture, ok := f.responses[crypto.Keccak256Hash(request)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %x", errMissingFixture, request)
+	}
+	return fixture.Response, nil
+}
+
+type fixturesJSON struct {
+	Root     common.Hash `json:"root"`
+	Fixtures []Fixture   `json:"fixtures"`
+}
+
+// Save writes the fixtures to [path] as JSON, ordered by request.
+func (f *Fixtures) Save(path string) error {
+	f.lock.RLock()
+	enc := fixturesJSON{
+		Root:     f.Root,
+		Fixtures: make([]Fixture, 0, len(f.responses)),
+	}
+	for _, fixture := range f.responses {
+		enc.Fixtures = append(enc.Fixtures, fixture)
+	}
+	f.lock.RUnlock()
+
+	sort.Slice(enc.Fixtures, func(i, j int) bool {
+		return bytes.Compare(enc.Fixtures[i].Request, enc.Fixtures[j].Request) < 0
+	})
+	b, err := json.Marshal(enc)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0o600)
+}
+
+// LoadFixtures reads the fixtures written to [path] by [Fixtures.Save].
+func LoadFixtures(path string) (*Fixtures, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var dec fixturesJSON
+	if err := json.Unmarshal(b, &dec); err != nil {
+		return nil, fmt.Errorf("failed to parse fixtures %s: %w", path, err)
+	}
+	f := NewFixtures(dec.Root)
+	for _, fixture := range dec.Fixtures {
+		f.responses[crypto.Keccak256Hash(fixture.Request)] = fixture
+	}
+	return f, nil
+}
+
+// recorder adds the responses received by the wrapped client to fixtures.
+type recorder struct {
+	peer.NetworkClient
+	fixtures *Fixtures
+}
+
+// Record returns a client sending requests with [client] and adding the
+// responses to [fixtures]. Responses are recorded before the state sync
+// client validates them, so an invalid response is only replaced by the
+// valid response to the retried request.
+func Record(client peer.NetworkClient, fixtures *Fixtures) peer.NetworkClient {
+	return &recorder{
+		NetworkClient: client,
+		fixtures:      fixtures,
+	}
+}
+
+func (r *recorder) SendAppRequestAny(ctx context.Context, minVersion *version.Application, request []byte) ([]byte, ids.NodeID, error) {
+	response, nodeID, err := r.NetworkClient.SendAppRequestAny(ctx, minVersion, request)
+	if err == nil {
+		r.fixtures.Add(request, response)
+	}
+	return response, nodeID, err
+}
+
+func (r *recorder) SendAppRequest(ctx context.Context, nodeID ids.NodeID, request []byte) ([]byte, error) {
+	response, err := r.NetworkClient.SendAppRequest(ctx, nodeID, request)
+	if err == nil {
+		r.fixtures.Add(request, response)
+	}
+	return response, err
+}
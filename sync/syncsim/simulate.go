@@ -0,0 +1,219 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package syncsim
+
+import (
+	"context"
+	"errors"
+	"runtime/metrics"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/shubhamdubey02/coreth/core/rawdb"
+	"github.com/shubhamdubey02/coreth/params"
+	"github.com/shubhamdubey02/coreth/peer"
+	"github.com/shubhamdubey02/coreth/plugin/evm/message"
+	statesyncclient "github.com/shubhamdubey02/coreth/sync/client"
+	"github.com/shubhamdubey02/coreth/sync/client/stats"
+	"github.com/shubhamdubey02/coreth/sync/statesync"
+)
+
+const (
+	DefaultRequestSize    = 1024
+	DefaultSampleInterval = 100 * time.Millisecond
+)
+
+// Runtime metrics sampled to measure the resources used by a simulation.
+const (
+	userCPUMetric   = "/cpu/classes/user:cpu-seconds"
+	gcCPUMetric     = "/cpu/classes/gc/total:cpu-seconds"
+	heapMetric      = "/memory/classes/heap/objects:bytes"
+	allocatedMetric = "/gc/heap/allocs:bytes"
+)
+
+var errNoSource = errors.New("simulation requires a source of responses")
+
+// Config describes a simulated state sync.
+type Config struct {
+	Root common.Hash
+	// Source responds to the requests of the client.
+	Source Source
+	// DB is the database synced into, which defaults to an empty in-memory
+	// database.
+	DB ethdb.Database
+	// Record, if not nil, records the responses of [Source].
+	Record *Fixtures
+
+	// Bandwidth is the throughput of the simulated link to the peers in
+	// bytes per second, or unlimited if 0. Latency is added to each
+	// response.
+	Bandwidth uint64
+	Latency   time.Duration
+
+	// RequestSize and MemoryBudget configure the state syncer as the
+	// state-sync-request-size and state-sync-memory-budget options of the
+	// VM do, with MemoryBudget in bytes.
+	RequestSize  uint16
+	MemoryBudget uint64
+
+	// SampleInterval is the interval at which the heap is sampled.
+	SampleInterval time.Duration
+}
+
+// Result is the performance of a simulated state sync.
+type Result struct {
+	Elapsed  time.Duration
+	Requests uint64
+	Bytes    uint64
+	// CPU is the CPU time spent by the process, including the time spent by
+	// [Config.Source] to respond, which is negligible when replaying
+	// [Fixtures].
+	CPU time.Duration
+	// PeakHeap is the largest size of the live and unswept heap objects
+	// sampled, and Allocated the bytes allocated during the sync.
+	PeakHeap  uint64
+	Allocated uint64
+}
+
+// Throughput returns the bytes received per second.
+func (r *Result) Throughput() float64 {
+	return float64(r.Bytes) / r.Elapsed.Seconds()
+}
+
+// Run syncs the state at [config.Root] from [config.Source] with the state
+// sync client, and measures its performance.
+func Run(ctx context.Context, config Config) (*Result, error) {
+	if config.Source == nil {
+		return nil, errNoSource
+	}
+	if config.DB == nil {
+		config.DB = rawdb.NewMemoryDatabase()
+	}
+	if config.RequestSize == 0 {
+		config.RequestSize = DefaultRequestSize
+	}
+	if config.SampleInterval == 0 {
+		config.SampleInterval = DefaultSampleInterval
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	net := &network{
+		source:    config.Source,
+		bandwidth: config.Bandwidth,
+		latency:   config.Latency,
+		onError:   cancel,
+	}
+	var networkClient peer.NetworkClient = net
+	if config.Record != nil {
+		networkClient = Record(net, config.Record)
+	}
+	client := statesyncclient.NewClient(&statesyncclient.ClientConfig{
+		NetworkClient: networkClient,
+		Codec:         message.Codec,
+		Stats:         stats.NewNoOpStats(),
+		MaxCodeSize:   params.MaxCodeSize,
+	})
+	syncer, err := statesync.NewStateSyncer(&statesync.StateSyncerConfig{
+		Client:                   client,
+		Root:                     config.Root,
+		DB:                       config.DB,
+		BatchSize:                ethdb.IdealBatchSize,
+		MaxOutstandingCodeHashes: statesync.DefaultMaxOutstandingCodeHashes,
+		NumCodeFetchingWorkers:   statesync.DefaultNumCodeFetchingWorkers,
+		RequestSize:              config.RequestSize,
+		MemoryBudget:             config.MemoryBudget,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sampler := newSampler(config.SampleInterval)
+	if err := syncer.Start(ctx); err != nil {
+		sampler.stop()
+		return nil, err
+	}
+	err = <-syncer.Done()
+	result := sampler.stop()
+	if cause := context.Cause(ctx); cause != nil && !errors.Is(cause, context.Canceled) {
+		// Prefer the error of the source to the cancellation it caused.
+		err = cause
+	}
+	if err != nil {
+		return nil, err
+	}
+	result.Requests = net.requests.Load()
+	result.Bytes = net.bytes.Load()
+	return result, nil
+}
+
+// sampler measures the resources used by the process until it is stopped.
+type sampler struct {
+	start   time.Time
+	samples []metrics.Sample
+
+	peakHeap uint64
+	quit     chan struct{}
+	wg       sync.WaitGroup
+}
+
+func newSampler(interval time.Duration) *sampler {
+	s := &sampler{
+		start: time.Now(),
+		samples: []metrics.Sample{
+			{Name: userCPUMetric},
+			{Name: gcCPUMetric},
+			{Name: heapMetric},
+			{Name: allocatedMetric},
+		},
+		quit: make(chan struct{}),
+	}
+	metrics.Read(s.samples)
+	s.peakHeap = s.samples[2].Value.Uint64()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		heap := []metrics.Sample{{Name: heapMetric}}
+		for {
+			select {
+			case <-ticker.C:
+				metrics.Read(heap)
+				if size := heap[0].Value.Uint64(); size > s.peakHeap {
+					s.peakHeap = size
+				}
+			case <-s.quit:
+				return
+			}
+		}
+	}()
+	return s
+}
+
+// stop stops sampling and returns the resources used since [newSampler].
+func (s *sampler) stop() *Result {
+	close(s.quit)
+	s.wg.Wait()
+
+	end := make([]metrics.Sample, len(s.samples))
+	copy(end, s.samples)
+	metrics.Read(end)
+	cpu := end[0].Value.Float64() + end[1].Value.Float64() - s.samples[0].Value.Float64() - s.samples[1].Value.Float64()
+	peakHeap := s.peakHeap
+	if size := end[2].Value.Uint64(); size > peakHeap {
+		peakHeap = size
+	}
+	return &Result{
+		Elapsed:   time.Since(s.start),
+		CPU:       time.Duration(cpu * float64(time.Second)),
+		PeakHeap:  peakHeap,
+		Allocated: end[3].Value.Uint64() - s.samples[3].Value.Uint64(),
+	}
+}
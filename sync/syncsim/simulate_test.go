@@ -0,0 +1,111 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package syncsim
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/shubhamdubey02/coreth/core/rawdb"
+	"github.com/shubhamdubey02/coreth/core/types"
+	"github.com/shubhamdubey02/coreth/sync/statesync"
+	"github.com/shubhamdubey02/coreth/trie"
+	"github.com/stretchr/testify/require"
+)
+
+// newServerState returns a database holding a state with storage, and the
+// root of the state.
+func newServerState(t *testing.T, numAccounts int) (ethdb.Database, *trie.Database, common.Hash) {
+	db := rawdb.NewMemoryDatabase()
+	trieDB := trie.NewDatabase(db, nil)
+	root, _ := statesync.FillAccountsWithOverlappingStorage(t, trieDB, types.EmptyRootHash, numAccounts, 4)
+	return db, trieDB, root
+}
+
+func requireSynced(t *testing.T, db ethdb.Database, root common.Hash) {
+	tr, err := trie.NewStateTrie(trie.TrieID(root), trie.NewDatabase(db, nil))
+	require.NoError(t, err)
+	it, err := tr.NodeIterator(nil)
+	require.NoError(t, err)
+	for it.Next(true) {
+	}
+	require.NoError(t, it.Error())
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	serverDB, serverTrieDB, root := newServerState(t, 500)
+
+	fixtures := NewFixtures(root)
+	synthesized, err := Run(context.Background(), Config{
+		Root:   root,
+		Source: NewDBSource(serverDB, serverTrieDB, nil),
+		Record: fixtures,
+	})
+	require.NoError(t, err)
+	require.NotZero(t, synthesized.Requests)
+	require.LessOrEqual(t, uint64(fixtures.Len()), synthesized.Requests)
+
+	path := filepath.Join(t.TempDir(), "fixtures.json")
+	require.NoError(t, fixtures.Save(path))
+	loaded, err := LoadFixtures(path)
+	require.NoError(t, err)
+	require.Equal(t, root, loaded.Root)
+	require.Equal(t, fixtures.Len(), loaded.Len())
+
+	clientDB := rawdb.NewMemoryDatabase()
+	replayed, err := Run(context.Background(), Config{
+		Root:      loaded.Root,
+		Source:    loaded,
+		DB:        clientDB,
+		Bandwidth: 64 * 1024 * 1024,
+	})
+	require.NoError(t, err)
+	require.Equal(t, synthesized.Requests, replayed.Requests)
+	require.Equal(t, synthesized.Bytes, replayed.Bytes)
+	require.NotZero(t, replayed.Allocated)
+	requireSynced(t, clientDB, root)
+}
+
+func TestReplayMissingFixture(t *testing.T) {
+	serverDB, serverTrieDB, root := newServerState(t, 500)
+
+	fixtures := NewFixtures(root)
+	_, err := Run(context.Background(), Config{
+		Root:   root,
+		Source: NewDBSource(serverDB, serverTrieDB, nil),
+		Record: fixtures,
+	})
+	require.NoError(t, err)
+
+	// Requests for fewer leafs than recorded have no recorded response.
+	_, err = Run(context.Background(), Config{
+		Root:        root,
+		Source:      fixtures,
+		RequestSize: DefaultRequestSize / 2,
+	})
+	require.ErrorIs(t, err, errMissingFixture)
+}
+
+func TestThrottledBandwidth(t *testing.T) {
+	serverDB, serverTrieDB, root := newServerState(t, 500)
+
+	unlimited, err := Run(context.Background(), Config{
+		Root:   root,
+		Source: NewDBSource(serverDB, serverTrieDB, nil),
+	})
+	require.NoError(t, err)
+
+	// Deliver the responses over half a second.
+	bandwidth := unlimited.Bytes * 2
+	throttled, err := Run(context.Background(), Config{
+		Root:      root,
+		Source:    NewDBSource(serverDB, serverTrieDB, nil),
+		Bandwidth: bandwidth,
+	})
+	require.NoError(t, err)
+	require.LessOrEqual(t, throttled.Throughput(), float64(bandwidth))
+}
@@ -0,0 +1,150 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package syncsim
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/shubhamdubey02/coreth/peer"
+	"github.com/shubhamdubey02/coreth/plugin/evm/message"
+	"github.com/shubhamdubey02/coreth/sync/handlers"
+	handlerstats "github.com/shubhamdubey02/coreth/sync/handlers/stats"
+	"github.com/shubhamdubey02/coreth/trie"
+	"github.com/shubhamdubey02/cryftgo/ids"
+	"github.com/shubhamdubey02/cryftgo/version"
+)
+
+var (
+	_ Source             = (*Fixtures)(nil)
+	_ Source             = (*dbSource)(nil)
+	_ peer.NetworkClient = (*network)(nil)
+
+	errMissingFixture        = errors.New("no recorded response to request")
+	errCrossChainUnsupported = errors.New("cross chain requests are not simulated")
+)
+
+// Source responds to the requests of the state sync client in place of its
+// peers.
+type Source interface {
+	Respond(ctx context.Context, request []byte) ([]byte, error)
+}
+
+// dbSource synthesizes responses from a local database, as a peer serving
+// its state would.
+type dbSource struct {
+	message.NoopRequestHandler
+
+	leafsHandler *handlers.LeafsRequestHandler
+	codeHandler  *handlers.CodeRequestHandler
+}
+
+// NewDBSource returns a [Source] serving the state tries of [trieDB] and the
+// code of [db]. [snapshots] may be nil, in which case leafs are read from the
+// tries.
+func NewDBSource(db ethdb.KeyValueReader, trieDB *trie.Database, snapshots handlers.SnapshotProvider) Source {
+	stats := handlerstats.NewNoopHandlerStats()
+	return &dbSource{
+		leafsHandler: handlers.NewLeafsRequestHandler(trieDB, snapshots, message.Codec, stats),
+		codeHandler:  handlers.NewCodeRequestHandler(db, message.Codec, stats),
+	}
+}
+
+func (s *dbSource) Respond(ctx context.Context, request []byte) ([]byte, error) {
+	req, err := message.BytesToRequest(message.Codec, request)
+	if err != nil {
+		return nil, err
+	}
+	return req.Handle(ctx, ids.EmptyNodeID, 0, s)
+}
+
+func (s *dbSource) HandleStateTrieLeafsRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, leafsRequest message.LeafsRequest) ([]byte, error) {
+	return s.leafsHandler.OnLeafsRequest(ctx, nodeID, requestID, leafsRequest)
+}
+
+func (s *dbSource) HandleCodeRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, codeRequest message.CodeRequest) ([]byte, error) {
+	return s.codeHandler.OnCodeRequest(ctx, nodeID, requestID, codeRequest)
+}
+
+// network is a [peer.NetworkClient] delivering the responses of a [Source]
+// over a simulated link of limited bandwidth and fixed latency.
+type network struct {
+	source Source
+	// [bandwidth] is in bytes per second, and unlimited if 0.
+	bandwidth uint64
+	latency   time.Duration
+	// [onError] is called with the errors of [source], which would otherwise
+	// be retried by the state sync client forever.
+	onError func(error)
+
+	lock sync.Mutex
+	// [linkFree] is when the link finishes delivering the responses sent so
+	// far.
+	linkFree time.Time
+
+	requests atomic.Uint64
+	bytes    atomic.Uint64
+}
+
+func (n *network) SendAppRequestAny(ctx context.Context, _ *version.Application, request []byte) ([]byte, ids.NodeID, error) {
+	response, err := n.SendAppRequest(ctx, ids.EmptyNodeID, request)
+	return response, ids.EmptyNodeID, err
+}
+
+func (n *network) SendAppRequest(ctx context.Context, _ ids.NodeID, request []byte) ([]byte, error) {
+	response, err := n.source.Respond(ctx, request)
+	if err != nil {
+		n.onError(err)
+		return nil, err
+	}
+	if err := n.deliver(ctx, len(response)); err != nil {
+		return nil, err
+	}
+	n.requests.Add(1)
+	n.bytes.Add(uint64(len(response)))
+	return response, nil
+}
+
+func (*network) SendCrossChainRequest(context.Context, ids.ID, []byte) ([]byte, error) {
+	return nil, errCrossChainUnsupported
+}
+
+func (*network) TrackBandwidth(ids.NodeID, float64) {}
+
+// Peers returns no peers, as requests are all served by the simulated source.
+func (*network) Peers(*version.Application) []ids.NodeID { return nil }
+
+// deliver blocks until a response of [size] bytes is delivered, after the
+// responses sent before it, since concurrent requests share the link.
+func (n *network) deliver(ctx context.Context, size int) error {
+	delay := n.latency
+	if n.bandwidth > 0 {
+		transfer := time.Duration(float64(size) / float64(n.bandwidth) * float64(time.Second))
+
+		n.lock.Lock()
+		now := time.Now()
+		if n.linkFree.Before(now) {
+			n.linkFree = now
+		}
+		n.linkFree = n.linkFree.Add(transfer)
+		delay += n.linkFree.Sub(now)
+		n.lock.Unlock()
+	}
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -60,6 +60,13 @@ type backend interface {
 	// and dirty disk layer nodes, so both are merged into the second return.
 	Size() (common.StorageSize, common.StorageSize)
 
+	// CleanCacheSize returns the memory held by the cache of clean nodes,
+	// ResetCleanCache drops the cached nodes and ResizeCleanCache resizes the
+	// cache to the given size in bytes.
+	CleanCacheSize() common.StorageSize
+	ResetCleanCache()
+	ResizeCleanCache(size int) error
+
 	// Update performs a state transition by committing dirty nodes contained
 	// in the given set in order to update state from the specified parent to
 	// the specified root.
@@ -175,6 +182,23 @@ func (db *Database) Size() (common.StorageSize, common.StorageSize, common.Stora
 	return diffs, nodes, preimages
 }
 
+// CleanCacheSize returns the memory held by the cache of clean trie nodes.
+func (db *Database) CleanCacheSize() common.StorageSize {
+	return db.backend.CleanCacheSize()
+}
+
+// ResetCleanCache drops the clean trie nodes cached in memory, which are read
+// from disk again when next accessed.
+func (db *Database) ResetCleanCache() {
+	db.backend.ResetCleanCache()
+}
+
+// ResizeCleanCache resizes the clean trie node cache to [size] bytes, keeping
+// the cached nodes readable while the cache fills up.
+func (db *Database) ResizeCleanCache(size int) error {
+	return db.backend.ResizeCleanCache(size)
+}
+
 // Initialized returns an indicator if the state data is already initialized
 // according to the state scheme.
 func (db *Database) Initialized(genesisRoot common.Hash) bool {
@@ -92,6 +92,8 @@ type cache interface {
 	Del([]byte)
 	Set([]byte, []byte)
 	Reset()
+	Size() uint64
+	Resize(int)
 	SaveToFileConcurrent(string, int) error
 }
 
@@ -718,6 +720,31 @@ func (db *Database) Size() (common.StorageSize, common.StorageSize) {
 	return 0, db.dirtiesSize + db.childrenSize + metadataSize
 }
 
+// CleanCacheSize returns the bytes of memory held by the clean cache.
+func (db *Database) CleanCacheSize() common.StorageSize {
+	if db.cleans == nil {
+		return 0
+	}
+	return common.StorageSize(db.cleans.Size())
+}
+
+// ResetCleanCache drops the nodes of the clean cache.
+func (db *Database) ResetCleanCache() {
+	if db.cleans != nil {
+		db.cleans.Reset()
+	}
+}
+
+// ResizeCleanCache resizes the clean cache to [size] bytes, keeping the
+// cached nodes readable while the cache fills up. A database created without a clean cache cannot be resized.
+func (db *Database) ResizeCleanCache(size int) error {
+	if db.cleans == nil {
+		return errors.New("clean cache is disabled")
+	}
+	db.cleans.Resize(size)
+	return nil
+}
+
 // Close closes the trie database and releases all held resources.
 func (db *Database) Close() error {
 	if db.cleans != nil {
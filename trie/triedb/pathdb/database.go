@@ -32,6 +32,7 @@ import (
 	"io"
 	"sync"
 
+	"github.com/VictoriaMetrics/fastcache"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
@@ -417,6 +418,32 @@ func (db *Database) Size() (diffs common.StorageSize, nodes common.StorageSize)
 	return diffs, nodes
 }
 
+// CleanCacheSize returns the bytes of memory held by the clean cache of the
+// disk layer.
+func (db *Database) CleanCacheSize() common.StorageSize {
+	dl := db.tree.bottom()
+	if dl == nil || dl.cleans == nil {
+		return 0
+	}
+	var stats fastcache.Stats
+	dl.cleans.UpdateStats(&stats)
+	return common.StorageSize(stats.BytesSize)
+}
+
+// ResetCleanCache drops the nodes of the clean cache of the disk layer.
+func (db *Database) ResetCleanCache() {
+	dl := db.tree.bottom()
+	if dl != nil && dl.cleans != nil {
+		dl.cleans.Reset()
+	}
+}
+
+// ResizeCleanCache is not supported, as the clean cache is shared by the
+// disk layers and read without holding the database lock.
+func (db *Database) ResizeCleanCache(size int) error {
+	return errors.New("clean cache resizing is non-supported")
+}
+
 // Initialized returns an indicator if the state data is already
 // initialized in path-based scheme.
 func (db *Database) Initialized(genesisRoot common.Hash) bool {
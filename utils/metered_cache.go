@@ -14,8 +14,14 @@ import (
 
 // MeteredCache wraps *fastcache.Cache and periodically pulls stats from it.
 type MeteredCache struct {
-	*fastcache.Cache
-	namespace string
+	// cache is swapped out when the cache is resized.
+	cache atomic.Pointer[fastcache.Cache]
+	// prev is the cache replaced by the last resize. It is read on misses of
+	// [cache] until [prevPending] bytes have been written to [cache], so that
+	// resizing does not empty the cache all at once.
+	prev        atomic.Pointer[fastcache.Cache]
+	prevPending atomic.Int64
+	namespace   string
 
 	// stats to be surfaced
 	entriesCount metrics.Gauge
@@ -39,10 +45,10 @@ func NewMeteredCache(size int, namespace string, updateFrequency uint64) *Metere
 		updateFrequency = 1 // avoid division by zero
 	}
 	mc := &MeteredCache{
-		Cache:           fastcache.New(size),
 		namespace:       namespace,
 		updateFrequency: updateFrequency,
 	}
+	mc.cache.Store(fastcache.New(size))
 	if namespace != "" {
 		// only register stats if a namespace is provided.
 		mc.entriesCount = metrics.GetOrRegisterGauge(fmt.Sprintf("%s/entriesCount", namespace), nil)
@@ -78,37 +84,127 @@ func (mc *MeteredCache) updateStatsIfNeeded() {
 	mc.statsTime.Inc(int64(time.Since(start))) // cumulative metric
 }
 
+// Size returns the bytes of memory held by the cache.
+func (mc *MeteredCache) Size() uint64 {
+	var s fastcache.Stats
+	mc.UpdateStats(&s)
+	if prev := mc.prev.Load(); prev != nil {
+		prev.UpdateStats(&s)
+	}
+	return s.BytesSize
+}
+
+// Resize replaces the cache with an empty cache of [size] bytes, as fastcache
+// cannot be resized in place. The previous cache remains readable until as
+// many bytes as it held, up to [size], have been written to the new one.
+func (mc *MeteredCache) Resize(size int) {
+	var s fastcache.Stats
+	old := mc.cache.Load()
+	old.UpdateStats(&s)
+	mc.prevPending.Store(int64(min(s.BytesSize, uint64(size))))
+	// The previous cache must be in place before the new cache is, so that
+	// writes to the new cache always invalidate the previous one.
+	if prev := mc.prev.Swap(old); prev != nil {
+		prev.Reset()
+	}
+	mc.cache.Store(fastcache.New(size))
+}
+
+// written drops the previous cache once enough bytes have been written to the
+// current one.
+func (mc *MeteredCache) written(prev *fastcache.Cache, n int) {
+	if prev == nil || mc.prevPending.Add(-int64(n)) > 0 {
+		return
+	}
+	if mc.prev.CompareAndSwap(prev, nil) {
+		prev.Reset()
+	}
+}
+
+func (mc *MeteredCache) Reset() {
+	if prev := mc.prev.Swap(nil); prev != nil {
+		prev.Reset()
+	}
+	mc.cache.Load().Reset()
+}
+
+func (mc *MeteredCache) UpdateStats(s *fastcache.Stats) {
+	mc.cache.Load().UpdateStats(s)
+}
+
+func (mc *MeteredCache) SaveToFileConcurrent(filePath string, concurrency int) error {
+	return mc.cache.Load().SaveToFileConcurrent(filePath, concurrency)
+}
+
 func (mc *MeteredCache) Del(k []byte) {
 	mc.updateStatsIfNeeded()
-	mc.Cache.Del(k)
+	mc.cache.Load().Del(k)
+	if prev := mc.prev.Load(); prev != nil {
+		prev.Del(k)
+	}
 }
 
 func (mc *MeteredCache) Get(dst, k []byte) []byte {
 	mc.updateStatsIfNeeded()
-	return mc.Cache.Get(dst, k)
+	dst, _ = mc.hasGet(dst, k)
+	return dst
 }
 
 func (mc *MeteredCache) GetBig(dst, k []byte) []byte {
 	mc.updateStatsIfNeeded()
-	return mc.Cache.GetBig(dst, k)
+	n := len(dst)
+	if dst = mc.cache.Load().GetBig(dst, k); len(dst) > n {
+		return dst
+	}
+	if prev := mc.prev.Load(); prev != nil {
+		return prev.GetBig(dst, k)
+	}
+	return dst
 }
 
 func (mc *MeteredCache) Has(k []byte) bool {
 	mc.updateStatsIfNeeded()
-	return mc.Cache.Has(k)
+	if mc.cache.Load().Has(k) {
+		return true
+	}
+	prev := mc.prev.Load()
+	return prev != nil && prev.Has(k)
 }
 
 func (mc *MeteredCache) HasGet(dst, k []byte) ([]byte, bool) {
 	mc.updateStatsIfNeeded()
-	return mc.Cache.HasGet(dst, k)
+	return mc.hasGet(dst, k)
 }
 
+func (mc *MeteredCache) hasGet(dst, k []byte) ([]byte, bool) {
+	if dst, ok := mc.cache.Load().HasGet(dst, k); ok {
+		return dst, true
+	}
+	if prev := mc.prev.Load(); prev != nil {
+		return prev.HasGet(dst, k)
+	}
+	return dst, false
+}
+
+// Set and SetBig load the current cache before the previous one and
+// invalidate the entry in the previous cache before writing it, so that a
+// stale value is never read from the previous cache.
 func (mc *MeteredCache) Set(k, v []byte) {
 	mc.updateStatsIfNeeded()
-	mc.Cache.Set(k, v)
+	cache, prev := mc.cache.Load(), mc.prev.Load()
+	if prev != nil {
+		prev.Del(k)
+	}
+	cache.Set(k, v)
+	mc.written(prev, len(k)+len(v))
 }
 
 func (mc *MeteredCache) SetBig(k, v []byte) {
 	mc.updateStatsIfNeeded()
-	mc.Cache.SetBig(k, v)
+	cache, prev := mc.cache.Load(), mc.prev.Load()
+	if prev != nil {
+		prev.Del(k)
+	}
+	cache.SetBig(k, v)
+	mc.written(prev, len(k)+len(v))
 }
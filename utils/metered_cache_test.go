@@ -0,0 +1,44 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeteredCacheResize(t *testing.T) {
+	require := require.New(t)
+
+	cache := NewMeteredCache(32*1024*1024, "", 0)
+	cache.Set([]byte("a"), []byte("1"))
+	cache.Set([]byte("b"), []byte("2"))
+	cache.SetBig([]byte("big"), []byte("3"))
+
+	// Entries of the previous cache remain readable after a resize.
+	cache.Resize(64 * 1024 * 1024)
+	require.Equal([]byte("1"), cache.Get(nil, []byte("a")))
+	value, ok := cache.HasGet(nil, []byte("b"))
+	require.True(ok)
+	require.Equal([]byte("2"), value)
+	require.True(cache.Has([]byte("a")))
+	require.Equal([]byte("3"), cache.GetBig(nil, []byte("big")))
+
+	// Writes and deletions invalidate the entries of the previous cache.
+	cache.Set([]byte("a"), []byte("4"))
+	require.Equal([]byte("4"), cache.Get(nil, []byte("a")))
+	cache.Del([]byte("b"))
+	require.False(cache.Has([]byte("b")))
+	require.NotNil(cache.prev.Load())
+
+	// The previous cache is dropped once as many bytes as it held have been
+	// written to the new one.
+	for i := 0; cache.prev.Load() != nil; i++ {
+		require.Less(i, 1024*1024)
+		cache.Set([]byte{byte(i), byte(i >> 8), byte(i >> 16)}, []byte("value"))
+	}
+	require.False(cache.Has([]byte("big")))
+	require.Equal([]byte("4"), cache.Get(nil, []byte("a")))
+}
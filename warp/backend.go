@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/shubhamdubey02/cryftgo/cache"
@@ -178,13 +179,9 @@ func (b *backend) GetBlockSignature(blockID ids.ID) ([bls.SignatureLen]byte, err
 	}
 
 	var signature [bls.SignatureLen]byte
-	blockHashPayload, err := payload.NewHash(blockID)
+	unsignedMessage, err := NewBlockHashMessage(b.networkID, b.sourceChainID, common.Hash(blockID))
 	if err != nil {
-		return [bls.SignatureLen]byte{}, fmt.Errorf("failed to create new block hash payload: %w", err)
-	}
-	unsignedMessage, err := avalancheWarp.NewUnsignedMessage(b.networkID, b.sourceChainID, blockHashPayload.Bytes())
-	if err != nil {
-		return [bls.SignatureLen]byte{}, fmt.Errorf("failed to create new unsigned warp message: %w", err)
+		return [bls.SignatureLen]byte{}, err
 	}
 	sig, err := b.warpSigner.Sign(unsignedMessage)
 	if err != nil {
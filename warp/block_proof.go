@@ -0,0 +1,120 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package warp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shubhamdubey02/coreth/precompile/contracts/warp"
+	"github.com/shubhamdubey02/cryftgo/ids"
+	"github.com/shubhamdubey02/cryftgo/snow/validators"
+	"github.com/shubhamdubey02/cryftgo/utils/crypto/bls"
+	avalancheWarp "github.com/shubhamdubey02/cryftgo/vms/platformvm/warp"
+	"github.com/shubhamdubey02/cryftgo/vms/platformvm/warp/payload"
+)
+
+var (
+	errNotBlockHashMessage  = errors.New("warp message payload is not a block hash")
+	errWrongSourceChain     = errors.New("warp message was not sent by the expected chain")
+	errWrongBlockHash       = errors.New("warp message does not attest to the expected block hash")
+	errInvalidBlockHashSig  = errors.New("invalid block hash signature")
+	errQuorumNumOutOfBounds = errors.New("quorum numerator out of bounds")
+)
+
+// NewBlockHashMessage returns the unsigned warp message a validator of
+// [sourceChainID] signs to attest that the block [blockHash] was accepted.
+func NewBlockHashMessage(networkID uint32, sourceChainID ids.ID, blockHash common.Hash) (*avalancheWarp.UnsignedMessage, error) {
+	blockHashPayload, err := payload.NewHash(ids.ID(blockHash))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new block hash payload: %w", err)
+	}
+	unsignedMessage, err := avalancheWarp.NewUnsignedMessage(networkID, sourceChainID, blockHashPayload.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new unsigned warp message: %w", err)
+	}
+	return unsignedMessage, nil
+}
+
+// ParseBlockHashMessage returns the block hash attested to by [unsignedMessage].
+func ParseBlockHashMessage(unsignedMessage *avalancheWarp.UnsignedMessage) (common.Hash, error) {
+	parsed, err := payload.Parse(unsignedMessage.Payload)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to parse warp message payload: %w", err)
+	}
+	blockHashPayload, ok := parsed.(*payload.Hash)
+	if !ok {
+		return common.Hash{}, fmt.Errorf("%w: %T", errNotBlockHashMessage, parsed)
+	}
+	return common.Hash(blockHashPayload.Hash), nil
+}
+
+// VerifyBlockHashSignature verifies that [signature] is the signature of the
+// validator with [publicKey] attesting that [blockHash] was accepted by
+// [sourceChainID].
+func VerifyBlockHashSignature(
+	networkID uint32,
+	sourceChainID ids.ID,
+	blockHash common.Hash,
+	publicKey *bls.PublicKey,
+	signature []byte,
+) error {
+	unsignedMessage, err := NewBlockHashMessage(networkID, sourceChainID, blockHash)
+	if err != nil {
+		return err
+	}
+	sig, err := bls.SignatureFromBytes(signature)
+	if err != nil {
+		return fmt.Errorf("failed to parse signature: %w", err)
+	}
+	if !bls.Verify(publicKey, sig, unsignedMessage.Bytes()) {
+		return errInvalidBlockHashSig
+	}
+	return nil
+}
+
+// VerifyBlockHashProof verifies that [signedMessageBytes], as returned by
+// warp_getBlockHashAggregateSignature, proves that [blockHash] was accepted by
+// [sourceChainID]. The aggregate signature must carry at least [quorumNum]
+// percent of the stake of the validators of the source chain's subnet at
+// [pChainHeight].
+func VerifyBlockHashProof(
+	ctx context.Context,
+	signedMessageBytes []byte,
+	networkID uint32,
+	sourceChainID ids.ID,
+	blockHash common.Hash,
+	pChainState validators.State,
+	pChainHeight uint64,
+	quorumNum uint64,
+) error {
+	if quorumNum == 0 || quorumNum > warp.WarpQuorumDenominator {
+		return fmt.Errorf("%w: %d", errQuorumNumOutOfBounds, quorumNum)
+	}
+	message, err := avalancheWarp.ParseMessage(signedMessageBytes)
+	if err != nil {
+		return fmt.Errorf("failed to parse warp message: %w", err)
+	}
+	if message.SourceChainID != sourceChainID {
+		return fmt.Errorf("%w: %s", errWrongSourceChain, message.SourceChainID)
+	}
+	attested, err := ParseBlockHashMessage(&message.UnsignedMessage)
+	if err != nil {
+		return err
+	}
+	if attested != blockHash {
+		return fmt.Errorf("%w: %s", errWrongBlockHash, attested)
+	}
+	return message.Signature.Verify(
+		ctx,
+		&message.UnsignedMessage,
+		networkID,
+		pChainState,
+		pChainHeight,
+		quorumNum,
+		warp.WarpQuorumDenominator,
+	)
+}
@@ -0,0 +1,94 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package warp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shubhamdubey02/cryftgo/database/memdb"
+	"github.com/shubhamdubey02/cryftgo/ids"
+	"github.com/shubhamdubey02/cryftgo/snow/choices"
+	"github.com/shubhamdubey02/cryftgo/snow/consensus/snowman"
+	"github.com/shubhamdubey02/cryftgo/snow/consensus/snowman/snowmantest"
+	commonEng "github.com/shubhamdubey02/cryftgo/snow/engine/common"
+	"github.com/shubhamdubey02/cryftgo/snow/engine/snowman/block"
+	"github.com/shubhamdubey02/cryftgo/snow/validators"
+	"github.com/shubhamdubey02/cryftgo/utils/crypto/bls"
+	"github.com/shubhamdubey02/cryftgo/utils/set"
+	avalancheWarp "github.com/shubhamdubey02/cryftgo/vms/platformvm/warp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockHashProof(t *testing.T) {
+	require := require.New(t)
+
+	blockHash := common.Hash{'b', 'l', 'o', 'c', 'k'}
+	testVM := &block.TestVM{
+		TestVM: commonEng.TestVM{T: t},
+		GetBlockF: func(ctx context.Context, blkID ids.ID) (snowman.Block, error) {
+			return &snowmantest.Block{
+				TestDecidable: choices.TestDecidable{
+					IDV:     blkID,
+					StatusV: choices.Accepted,
+				},
+			}, nil
+		},
+	}
+	sk, err := bls.NewSecretKey()
+	require.NoError(err)
+	pk := bls.PublicFromSecretKey(sk)
+	warpSigner := avalancheWarp.NewSigner(sk, networkID, sourceChainID)
+	backend, err := NewBackend(networkID, sourceChainID, warpSigner, testVM, memdb.New(), 500, nil)
+	require.NoError(err)
+
+	// A single validator signature can be checked against its public key.
+	signature, err := backend.GetBlockSignature(ids.ID(blockHash))
+	require.NoError(err)
+	require.NoError(VerifyBlockHashSignature(networkID, sourceChainID, blockHash, pk, signature[:]))
+	require.ErrorIs(VerifyBlockHashSignature(networkID, sourceChainID, common.Hash{1}, pk, signature[:]), errInvalidBlockHashSig)
+
+	// Build the message an aggregator would return for a subnet in which the
+	// signer holds half of the stake.
+	unsignedMessage, err := NewBlockHashMessage(networkID, sourceChainID, blockHash)
+	require.NoError(err)
+	parsedHash, err := ParseBlockHashMessage(unsignedMessage)
+	require.NoError(err)
+	require.Equal(blockHash, parsedHash)
+
+	otherSK, err := bls.NewSecretKey()
+	require.NoError(err)
+	signer, other := ids.GenerateTestNodeID(), ids.GenerateTestNodeID()
+	pChainState := &validators.TestState{
+		GetSubnetIDF: func(context.Context, ids.ID) (ids.ID, error) {
+			return ids.Empty, nil
+		},
+		GetValidatorSetF: func(context.Context, uint64, ids.ID) (map[ids.NodeID]*validators.GetValidatorOutput, error) {
+			return map[ids.NodeID]*validators.GetValidatorOutput{
+				signer: {NodeID: signer, PublicKey: pk, Weight: 50},
+				other:  {NodeID: other, PublicKey: bls.PublicFromSecretKey(otherSK), Weight: 50},
+			}, nil
+		},
+	}
+	vdrs, _, err := avalancheWarp.GetCanonicalValidatorSet(context.Background(), pChainState, 0, ids.Empty)
+	require.NoError(err)
+	signers := set.NewBits()
+	for i, vdr := range vdrs {
+		if vdr.PublicKey == pk {
+			signers.Add(i)
+		}
+	}
+	warpSignature := &avalancheWarp.BitSetSignature{Signers: signers.Bytes()}
+	copy(warpSignature.Signature[:], signature[:])
+	signedMessage, err := avalancheWarp.NewMessage(unsignedMessage, warpSignature)
+	require.NoError(err)
+
+	ctx := context.Background()
+	require.NoError(VerifyBlockHashProof(ctx, signedMessage.Bytes(), networkID, sourceChainID, blockHash, pChainState, 0, 50))
+	require.ErrorIs(VerifyBlockHashProof(ctx, signedMessage.Bytes(), networkID, sourceChainID, blockHash, pChainState, 0, 67), avalancheWarp.ErrInsufficientWeight)
+	require.ErrorIs(VerifyBlockHashProof(ctx, signedMessage.Bytes(), networkID, sourceChainID, common.Hash{1}, pChainState, 0, 50), errWrongBlockHash)
+	require.ErrorIs(VerifyBlockHashProof(ctx, signedMessage.Bytes(), networkID, ids.GenerateTestID(), blockHash, pChainState, 0, 50), errWrongSourceChain)
+	require.ErrorIs(VerifyBlockHashProof(ctx, signedMessage.Bytes(), networkID, sourceChainID, blockHash, pChainState, 0, 0), errQuorumNumOutOfBounds)
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/shubhamdubey02/cryftgo/ids"
 	"github.com/shubhamdubey02/coreth/rpc"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 )
 
@@ -20,6 +21,9 @@ type Client interface {
 	GetMessageAggregateSignature(ctx context.Context, messageID ids.ID, quorumNum uint64, subnetIDStr string) ([]byte, error)
 	GetBlockSignature(ctx context.Context, blockID ids.ID) ([]byte, error)
 	GetBlockAggregateSignature(ctx context.Context, blockID ids.ID, quorumNum uint64, subnetIDStr string) ([]byte, error)
+	GetBlockHashSignature(ctx context.Context, blockHash common.Hash) ([]byte, error)
+	GetBlockHashAggregateSignature(ctx context.Context, blockHash common.Hash, quorumNum uint64, subnetIDStr string) ([]byte, error)
+	GetValidatorSet(ctx context.Context, pChainHeight uint64, subnetIDStr string) (*ValidatorSet, error)
 }
 
 // client implementation for interacting with EVM [chain]
@@ -77,3 +81,27 @@ func (c *client) GetBlockAggregateSignature(ctx context.Context, blockID ids.ID,
 	}
 	return res, nil
 }
+
+func (c *client) GetBlockHashSignature(ctx context.Context, blockHash common.Hash) ([]byte, error) {
+	var res hexutil.Bytes
+	if err := c.client.CallContext(ctx, &res, "warp_getBlockHashSignature", blockHash); err != nil {
+		return nil, fmt.Errorf("call to warp_getBlockHashSignature failed. err: %w", err)
+	}
+	return res, nil
+}
+
+func (c *client) GetBlockHashAggregateSignature(ctx context.Context, blockHash common.Hash, quorumNum uint64, subnetIDStr string) ([]byte, error) {
+	var res hexutil.Bytes
+	if err := c.client.CallContext(ctx, &res, "warp_getBlockHashAggregateSignature", blockHash, quorumNum, subnetIDStr); err != nil {
+		return nil, fmt.Errorf("call to warp_getBlockHashAggregateSignature failed. err: %w", err)
+	}
+	return res, nil
+}
+
+func (c *client) GetValidatorSet(ctx context.Context, pChainHeight uint64, subnetIDStr string) (*ValidatorSet, error) {
+	var res ValidatorSet
+	if err := c.client.CallContext(ctx, &res, "warp_getValidatorSet", pChainHeight, subnetIDStr); err != nil {
+		return nil, fmt.Errorf("call to warp_getValidatorSet failed. err: %w", err)
+	}
+	return &res, nil
+}
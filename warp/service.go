@@ -8,14 +8,15 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/shubhamdubey02/coreth/peer"
 	"github.com/shubhamdubey02/coreth/warp/aggregator"
 	"github.com/shubhamdubey02/coreth/warp/validators"
 	"github.com/shubhamdubey02/cryftgo/ids"
+	"github.com/shubhamdubey02/cryftgo/utils/crypto/bls"
 	"github.com/shubhamdubey02/cryftgo/vms/platformvm/warp"
-	"github.com/shubhamdubey02/cryftgo/vms/platformvm/warp/payload"
 )
 
 var errNoValidators = errors.New("cannot aggregate signatures from subnet with no validators")
@@ -78,26 +79,88 @@ func (a *API) GetMessageAggregateSignature(ctx context.Context, messageID ids.ID
 
 // GetBlockAggregateSignature fetches the aggregate signature for the requested [blockID]
 func (a *API) GetBlockAggregateSignature(ctx context.Context, blockID ids.ID, quorumNum uint64, subnetIDStr string) (signedMessageBytes hexutil.Bytes, err error) {
-	blockHashPayload, err := payload.NewHash(blockID)
+	unsignedMessage, err := NewBlockHashMessage(a.networkID, a.sourceChainID, common.Hash(blockID))
 	if err != nil {
 		return nil, err
 	}
-	unsignedMessage, err := warp.NewUnsignedMessage(a.networkID, a.sourceChainID, blockHashPayload.Bytes())
+
+	return a.aggregateSignatures(ctx, unsignedMessage, quorumNum, subnetIDStr)
+}
+
+// GetBlockHashSignature returns the BLS signature of this node attesting that
+// the block with [blockHash] was accepted.
+func (a *API) GetBlockHashSignature(ctx context.Context, blockHash common.Hash) (hexutil.Bytes, error) {
+	return a.GetBlockSignature(ctx, ids.ID(blockHash))
+}
+
+// GetBlockHashAggregateSignature fetches a signed warp message attesting that
+// the block with [blockHash] was accepted, which can be checked with
+// [VerifyBlockHashProof].
+func (a *API) GetBlockHashAggregateSignature(ctx context.Context, blockHash common.Hash, quorumNum uint64, subnetIDStr string) (signedMessageBytes hexutil.Bytes, err error) {
+	return a.GetBlockAggregateSignature(ctx, ids.ID(blockHash), quorumNum, subnetIDStr)
+}
+
+// ValidatorSet is the canonical validator set of a subnet at a P-chain height,
+// as used to verify warp messages.
+type ValidatorSet struct {
+	Height      uint64      `json:"height"`
+	SubnetID    ids.ID      `json:"subnetID"`
+	TotalWeight uint64      `json:"totalWeight"`
+	Validators  []Validator `json:"validators"`
+}
+
+// Validator is a BLS key of a validator set and the validators registered
+// with it.
+type Validator struct {
+	PublicKey hexutil.Bytes `json:"publicKey"`
+	Weight    uint64        `json:"weight"`
+	NodeIDs   []ids.NodeID  `json:"nodeIDs"`
+}
+
+// GetValidatorSet returns the validator set of [subnetIDStr] at [pChainHeight]
+// used to verify warp messages, which is served from the snapshots of
+// validator sets when available. Defaults to the subnet of this chain if
+// [subnetIDStr] is empty.
+func (a *API) GetValidatorSet(ctx context.Context, pChainHeight uint64, subnetIDStr string) (*ValidatorSet, error) {
+	subnetID, err := a.parseSubnetID(subnetIDStr)
 	if err != nil {
 		return nil, err
 	}
+	validators, totalWeight, err := warp.GetCanonicalValidatorSet(ctx, a.state, pChainHeight, subnetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get validator set: %w", err)
+	}
+	set := &ValidatorSet{
+		Height:      pChainHeight,
+		SubnetID:    subnetID,
+		TotalWeight: totalWeight,
+		Validators:  make([]Validator, len(validators)),
+	}
+	for i, validator := range validators {
+		set.Validators[i] = Validator{
+			PublicKey: bls.PublicKeyToCompressedBytes(validator.PublicKey),
+			Weight:    validator.Weight,
+			NodeIDs:   validator.NodeIDs,
+		}
+	}
+	return set, nil
+}
 
-	return a.aggregateSignatures(ctx, unsignedMessage, quorumNum, subnetIDStr)
+func (a *API) parseSubnetID(subnetIDStr string) (ids.ID, error) {
+	if len(subnetIDStr) == 0 {
+		return a.sourceSubnetID, nil
+	}
+	subnetID, err := ids.FromString(subnetIDStr)
+	if err != nil {
+		return ids.Empty, fmt.Errorf("failed to parse subnetID: %q", subnetIDStr)
+	}
+	return subnetID, nil
 }
 
 func (a *API) aggregateSignatures(ctx context.Context, unsignedMessage *warp.UnsignedMessage, quorumNum uint64, subnetIDStr string) (hexutil.Bytes, error) {
-	subnetID := a.sourceSubnetID
-	if len(subnetIDStr) > 0 {
-		sid, err := ids.FromString(subnetIDStr)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse subnetID: %q", subnetIDStr)
-		}
-		subnetID = sid
+	subnetID, err := a.parseSubnetID(subnetIDStr)
+	if err != nil {
+		return nil, err
 	}
 	pChainHeight, err := a.state.GetCurrentHeight(ctx)
 	if err != nil {
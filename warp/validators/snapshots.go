@@ -0,0 +1,215 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package validators
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/shubhamdubey02/cryftgo/cache"
+	"github.com/shubhamdubey02/cryftgo/database"
+	"github.com/shubhamdubey02/cryftgo/ids"
+	"github.com/shubhamdubey02/cryftgo/snow/validators"
+	"github.com/shubhamdubey02/cryftgo/utils/crypto/bls"
+	"github.com/shubhamdubey02/cryftgo/utils/wrappers"
+)
+
+var (
+	_ validators.State = (*Snapshots)(nil)
+
+	errMalformedSnapshot = errors.New("malformed validator set snapshot")
+)
+
+const (
+	snapshotKeyLen = wrappers.LongLen + ids.IDLen
+	// Each validator is encoded as its node ID, weight and the length of its
+	// public key, followed by the public key if it has one.
+	snapshotValidatorLen = ids.NodeIDLen + wrappers.LongLen + wrappers.ByteLen
+)
+
+type snapshotKey struct {
+	height   uint64
+	subnetID ids.ID
+}
+
+// Snapshots wraps a [validators.State], caching and persisting the validator
+// sets it returns for each P-chain height, so that repeated verifications of
+// warp messages at the same height do not query the validator state again.
+// Validator sets are immutable once the P-chain has reached their height,
+// so snapshots never need to be invalidated, only pruned.
+type Snapshots struct {
+	validators.State
+
+	db        database.Database
+	retention uint64
+
+	lock   sync.Mutex
+	cache  *cache.LRU[snapshotKey, map[ids.NodeID]*validators.GetValidatorOutput]
+	latest uint64 // highest height a snapshot was stored for
+}
+
+// NewSnapshots returns a wrapper of [state] keeping the [cacheSize] most
+// recently used validator sets in memory and persisting them to [db].
+// Snapshots more than [retention] P-chain heights below the most recent
+// snapshot are pruned from [db], or kept indefinitely if [retention] is 0.
+func NewSnapshots(state validators.State, db database.Database, cacheSize int, retention uint64) *Snapshots {
+	return &Snapshots{
+		State:     state,
+		db:        db,
+		retention: retention,
+		cache:     &cache.LRU[snapshotKey, map[ids.NodeID]*validators.GetValidatorOutput]{Size: cacheSize},
+	}
+}
+
+func (s *Snapshots) GetValidatorSet(
+	ctx context.Context,
+	height uint64,
+	subnetID ids.ID,
+) (map[ids.NodeID]*validators.GetValidatorOutput, error) {
+	vdrs, ok, err := s.Snapshot(height, subnetID)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return vdrs, nil
+	}
+
+	// The validator set is fetched without holding the lock, since the
+	// underlying state may need to wait on the P-chain.
+	vdrs, err = s.State.GetValidatorSet(ctx, height, subnetID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	key := snapshotKey{height: height, subnetID: subnetID}
+	s.cache.Put(key, vdrs)
+	if err := s.db.Put(key.bytes(), encodeSnapshot(vdrs)); err != nil {
+		return nil, fmt.Errorf("failed to persist validator set snapshot at height %d: %w", height, err)
+	}
+	if height > s.latest {
+		s.latest = height
+		if err := s.prune(); err != nil {
+			// Pruning is retried when the next snapshot is stored.
+			log.Warn("Failed to prune validator set snapshots", "height", height, "err", err)
+		}
+	}
+	return vdrs, nil
+}
+
+// Snapshot returns the validator set of [subnetID] at [height] if a snapshot
+// of it is stored, without querying the underlying state.
+func (s *Snapshots) Snapshot(height uint64, subnetID ids.ID) (map[ids.NodeID]*validators.GetValidatorOutput, bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	key := snapshotKey{height: height, subnetID: subnetID}
+	if vdrs, ok := s.cache.Get(key); ok {
+		return vdrs, true, nil
+	}
+	snapshotBytes, err := s.db.Get(key.bytes())
+	if err == database.ErrNotFound {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	vdrs, err := decodeSnapshot(snapshotBytes)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to decode validator set snapshot at height %d: %w", height, err)
+	}
+	s.cache.Put(key, vdrs)
+	return vdrs, true, nil
+}
+
+// prune deletes the snapshots more than [s.retention] heights below
+// [s.latest]. Assumes [s.lock] is held.
+func (s *Snapshots) prune() error {
+	if s.retention == 0 || s.latest <= s.retention {
+		return nil
+	}
+	cutoff := s.latest - s.retention
+
+	it := s.db.NewIterator()
+	defer it.Release()
+
+	batch := s.db.NewBatch()
+	for it.Next() {
+		key := it.Key()
+		if len(key) != snapshotKeyLen {
+			continue
+		}
+		// Keys are ordered by height, so there are no more snapshots to
+		// prune once the cutoff is reached.
+		if binary.BigEndian.Uint64(key) >= cutoff {
+			break
+		}
+		if err := batch.Delete(key); err != nil {
+			return err
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+func (k snapshotKey) bytes() []byte {
+	key := make([]byte, snapshotKeyLen)
+	binary.BigEndian.PutUint64(key, k.height)
+	copy(key[wrappers.LongLen:], k.subnetID[:])
+	return key
+}
+
+func encodeSnapshot(vdrs map[ids.NodeID]*validators.GetValidatorOutput) []byte {
+	var buf bytes.Buffer
+	for _, vdr := range vdrs {
+		var header [snapshotValidatorLen]byte
+		copy(header[:], vdr.NodeID[:])
+		binary.BigEndian.PutUint64(header[ids.NodeIDLen:], vdr.Weight)
+		var pkBytes []byte
+		if vdr.PublicKey != nil {
+			pkBytes = bls.PublicKeyToCompressedBytes(vdr.PublicKey)
+		}
+		header[snapshotValidatorLen-1] = byte(len(pkBytes))
+		buf.Write(header[:])
+		buf.Write(pkBytes)
+	}
+	return buf.Bytes()
+}
+
+func decodeSnapshot(b []byte) (map[ids.NodeID]*validators.GetValidatorOutput, error) {
+	vdrs := make(map[ids.NodeID]*validators.GetValidatorOutput)
+	for len(b) > 0 {
+		if len(b) < snapshotValidatorLen {
+			return nil, errMalformedSnapshot
+		}
+		vdr := &validators.GetValidatorOutput{
+			Weight: binary.BigEndian.Uint64(b[ids.NodeIDLen:]),
+		}
+		copy(vdr.NodeID[:], b[:ids.NodeIDLen])
+		pkLen := int(b[snapshotValidatorLen-1])
+		b = b[snapshotValidatorLen:]
+		if len(b) < pkLen {
+			return nil, errMalformedSnapshot
+		}
+		if pkLen > 0 {
+			pk, err := bls.PublicKeyFromCompressedBytes(b[:pkLen])
+			if err != nil {
+				return nil, err
+			}
+			vdr.PublicKey = pk
+		}
+		b = b[pkLen:]
+		vdrs[vdr.NodeID] = vdr
+	}
+	return vdrs, nil
+}
@@ -0,0 +1,78 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package validators
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shubhamdubey02/cryftgo/database/memdb"
+	"github.com/shubhamdubey02/cryftgo/ids"
+	"github.com/shubhamdubey02/cryftgo/snow/validators"
+	"github.com/shubhamdubey02/cryftgo/utils/crypto/bls"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshots(t *testing.T) {
+	require := require.New(t)
+
+	sk, err := bls.NewSecretKey()
+	require.NoError(err)
+	subnetID := ids.GenerateTestID()
+	withKey := ids.GenerateTestNodeID()
+	withoutKey := ids.GenerateTestNodeID()
+
+	calls := 0
+	state := &validators.TestState{
+		GetValidatorSetF: func(_ context.Context, height uint64, _ ids.ID) (map[ids.NodeID]*validators.GetValidatorOutput, error) {
+			calls++
+			return map[ids.NodeID]*validators.GetValidatorOutput{
+				withKey: {
+					NodeID:    withKey,
+					PublicKey: bls.PublicFromSecretKey(sk),
+					Weight:    height,
+				},
+				withoutKey: {
+					NodeID: withoutKey,
+					Weight: 1,
+				},
+			}, nil
+		},
+	}
+	db := memdb.New()
+	snapshots := NewSnapshots(state, db, 1, 10)
+
+	// Validator sets are only fetched from the state once per height.
+	expected, err := snapshots.GetValidatorSet(context.Background(), 5, subnetID)
+	require.NoError(err)
+	_, err = snapshots.GetValidatorSet(context.Background(), 5, subnetID)
+	require.NoError(err)
+	require.Equal(1, calls)
+
+	_, ok, err := snapshots.Snapshot(6, subnetID)
+	require.NoError(err)
+	require.False(ok)
+
+	// Snapshots are persisted across restarts.
+	snapshots = NewSnapshots(state, db, 1, 10)
+	vdrs, ok, err := snapshots.Snapshot(5, subnetID)
+	require.NoError(err)
+	require.True(ok)
+	require.Len(vdrs, 2)
+	require.Equal(expected[withKey].Weight, vdrs[withKey].Weight)
+	require.Equal(bls.PublicKeyToCompressedBytes(expected[withKey].PublicKey), bls.PublicKeyToCompressedBytes(vdrs[withKey].PublicKey))
+	require.Equal(expected[withoutKey], vdrs[withoutKey])
+
+	// Snapshots beyond the retention are pruned.
+	_, err = snapshots.GetValidatorSet(context.Background(), 16, subnetID)
+	require.NoError(err)
+	snapshots = NewSnapshots(state, db, 1, 10)
+	_, ok, err = snapshots.Snapshot(5, subnetID)
+	require.NoError(err)
+	require.False(ok)
+	_, ok, err = snapshots.Snapshot(16, subnetID)
+	require.NoError(err)
+	require.True(ok)
+	require.Equal(2, calls)
+}